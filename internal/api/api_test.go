@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -54,7 +55,7 @@ func newTestServer(t *testing.T) (*Server, func()) {
 
 func setupModel(t *testing.T, mgr *registry.Manager, name string) {
 	t.Helper()
-	if err := mgr.Pull(name, nil); err != nil {
+	if err := mgr.Pull(context.Background(), name, nil); err != nil {
 		t.Fatalf("Pull(%s): %v", name, err)
 	}
 }
@@ -226,6 +227,34 @@ func TestAPI_ChatCompletions_MissingModel(t *testing.T) {
 	}
 }
 
+func TestAPI_Chat_InvalidConversation(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	bodies := map[string]string{
+		"empty":          `"messages": []`,
+		"unknown role":   `"messages": [{"role": "robot", "content": "Hello"}]`,
+		"ends assistant": `"messages": [{"role": "user", "content": "Hi"}, {"role": "assistant", "content": "Hello"}]`,
+	}
+	for _, path := range []string{"/v1/chat/completions", "/api/chat"} {
+		for name, msgs := range bodies {
+			body := `{"model": "test-model", "stream": false, ` + msgs + `}`
+			req := httptest.NewRequest("POST", path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			srv.Handler().ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s %s: status = %d, want %d", path, name, w.Code, http.StatusBadRequest)
+			}
+			if !strings.Contains(w.Body.String(), "invalid chat conversation") {
+				t.Errorf("%s %s: body = %s, want a conversation error", path, name, w.Body.String())
+			}
+		}
+	}
+}
+
 func TestAPI_ChatCompletions_Streaming(t *testing.T) {
 	mgr, db := newTestMgr(t)
 	defer db.Close()
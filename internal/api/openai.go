@@ -1,14 +1,15 @@
 package api
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/tutu-network/tutu/internal/domain"
 	"github.com/tutu-network/tutu/internal/infra/engine"
 )
 
@@ -47,6 +48,7 @@ type chatRequest struct {
 	MaxTokens   *int          `json:"max_tokens,omitempty"`
 	Stream      bool          `json:"stream"`
 	Stop        []string      `json:"stop,omitempty"`
+	Seed        *int64        `json:"seed,omitempty"`
 }
 
 type chatMessage struct {
@@ -54,6 +56,16 @@ type chatMessage struct {
 	Content string `json:"content"`
 }
 
+// validateChat checks message roles and turn order before the request
+// reaches the engine.
+func validateChat(msgs []chatMessage) error {
+	conv := make([]domain.ChatMessage, len(msgs))
+	for i, m := range msgs {
+		conv[i] = domain.ChatMessage{Role: domain.ChatRole(m.Role), Content: m.Content}
+	}
+	return domain.ValidateConversation(conv)
+}
+
 func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 	var req chatRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -65,6 +77,10 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "model is required")
 		return
 	}
+	if err := validateChat(req.Messages); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// Acquire model from pool
 	handle, err := s.pool.Acquire(req.Model, defaultLoadOpts())
@@ -94,13 +110,16 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 	if len(req.Stop) > 0 {
 		params.Stop = req.Stop
 	}
+	if req.Seed != nil {
+		params.Seed = *req.Seed
+	}
 
 	completionID := "chatcmpl-" + uuid.New().String()[:8]
 
 	if req.Stream {
-		s.streamChatResponse(w, r.Context(), handle, chatMsgs, params, req.Model, completionID)
+		s.streamChatResponse(w, r.Context(), handle, chatMsgs, params, handle.Name(), completionID)
 	} else {
-		s.nonStreamChatResponse(w, r.Context(), handle, chatMsgs, params, req.Model, completionID)
+		s.nonStreamChatResponse(w, r.Context(), handle, chatMsgs, params, handle.Name(), completionID)
 	}
 }
 
@@ -120,10 +139,18 @@ func (s *Server) nonStreamChatResponse(w http.ResponseWriter, ctx context.Contex
 	}
 	promptTokens := promptChars / 4
 	completionTokens := 0
+	finishReason := domain.FinishStop
+	var stats *domain.StreamStats
 
 	for tok := range tokenCh {
 		content += tok.Text
 		completionTokens++
+		if tok.FinishReason != "" {
+			finishReason = tok.FinishReason
+		}
+		if tok.Stats != nil {
+			stats = tok.Stats
+		}
 	}
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
@@ -138,17 +165,28 @@ func (s *Server) nonStreamChatResponse(w http.ResponseWriter, ctx context.Contex
 					"role":    "assistant",
 					"content": content,
 				},
-				"finish_reason": "stop",
+				"finish_reason": finishReason,
 			},
 		},
-		"usage": map[string]interface{}{
-			"prompt_tokens":     promptTokens,
-			"completion_tokens": completionTokens,
-			"total_tokens":      promptTokens + completionTokens,
-		},
+		"usage": chatUsage(stats, promptTokens, completionTokens),
 	})
 }
 
+// chatUsage builds an OpenAI usage object. The engine's stream stats are
+// preferred; the estimates are used when the stream carried none.
+func chatUsage(stats *domain.StreamStats, promptTokens, completionTokens int) map[string]interface{} {
+	usage := map[string]interface{}{}
+	if stats != nil {
+		promptTokens, completionTokens = stats.PromptTokens, stats.CompletionTokens
+		usage["time_to_first_token_ms"] = stats.TimeToFirstToken.Milliseconds()
+		usage["tokens_per_second"] = stats.TokensPerSec
+	}
+	usage["prompt_tokens"] = promptTokens
+	usage["completion_tokens"] = completionTokens
+	usage["total_tokens"] = promptTokens + completionTokens
+	return usage
+}
+
 func (s *Server) streamChatResponse(w http.ResponseWriter, ctx context.Context, handle *engine.PoolHandle, messages []engine.ChatMessage, params engine.GenerateParams, model, completionID string) {
 	tokenCh, err := handle.Model().Chat(ctx, messages, params)
 	if err != nil {
@@ -162,15 +200,15 @@ func (s *Server) streamChatResponse(w http.ResponseWriter, ctx context.Context,
 	w.Header().Set("Connection", "keep-alive")
 	w.WriteHeader(http.StatusOK)
 
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		writeError(w, http.StatusInternalServerError, "streaming not supported")
-		return
-	}
-
-	writer := bufio.NewWriter(w)
-
-	for tok := range tokenCh {
+	finishReason := domain.FinishStop
+	var stats *domain.StreamStats
+	flusher := s.pumpTokens(w, tokenCh, func(tok domain.Token) {
+		if tok.FinishReason != "" {
+			finishReason = tok.FinishReason
+		}
+		if tok.Stats != nil {
+			stats = tok.Stats
+		}
 		chunk := map[string]interface{}{
 			"id":      completionID,
 			"object":  "chat.completion.chunk",
@@ -188,12 +226,11 @@ func (s *Server) streamChatResponse(w http.ResponseWriter, ctx context.Context,
 		}
 
 		data, _ := json.Marshal(chunk)
-		fmt.Fprintf(writer, "data: %s\n\n", data)
-		writer.Flush()
-		flusher.Flush()
-	}
+		fmt.Fprintf(w, "data: %s\n\n", data)
+	})
 
-	// Send final chunk with finish_reason
+	// Send final chunk with finish_reason, and usage when the engine
+	// measured the stream
 	finalChunk := map[string]interface{}{
 		"id":      completionID,
 		"object":  "chat.completion.chunk",
@@ -203,15 +240,17 @@ func (s *Server) streamChatResponse(w http.ResponseWriter, ctx context.Context,
 			{
 				"index":         0,
 				"delta":         map[string]interface{}{},
-				"finish_reason": "stop",
+				"finish_reason": finishReason,
 			},
 		},
 	}
+	if stats != nil {
+		finalChunk["usage"] = chatUsage(stats, 0, 0)
+	}
 
 	data, _ := json.Marshal(finalChunk)
-	fmt.Fprintf(writer, "data: %s\n\n", data)
-	fmt.Fprintf(writer, "data: [DONE]\n\n")
-	writer.Flush()
+	fmt.Fprintf(w, "data: %s\n\n", data)
+	fmt.Fprintf(w, "data: [DONE]\n\n")
 	flusher.Flush()
 }
 
@@ -250,7 +289,9 @@ func (s *Server) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	handle, err := s.pool.Acquire(req.Model, defaultLoadOpts())
+	opts := defaultLoadOpts()
+	opts.Embedding = true
+	handle, err := s.pool.Acquire(req.Model, opts)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, "model error: "+err.Error())
 		return
@@ -258,6 +299,10 @@ func (s *Server) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
 	defer handle.Release()
 
 	embeddings, err := handle.Model().Embed(r.Context(), inputs)
+	if errors.Is(err, domain.ErrNotEmbeddingModel) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -275,7 +320,7 @@ func (s *Server) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"object": "list",
 		"data":   data,
-		"model":  req.Model,
+		"model":  handle.Name(),
 		"usage": map[string]interface{}{
 			"prompt_tokens": len(inputs),
 			"total_tokens":  len(inputs),
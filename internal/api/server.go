@@ -26,6 +26,9 @@ type Server struct {
 	mcpHandler     http.Handler   // Phase 2: MCP transport handler (nil if not set)
 	engagement     *EngagementAPI // Phase 2: Engagement REST API
 	earningsHub    *EarningsHub   // Phase 2: Live earnings SSE feed
+
+	// streamFlushInterval coalesces streamed tokens; 0 flushes every token.
+	streamFlushInterval time.Duration
 }
 
 // NewServer creates a new API server.
@@ -45,6 +48,10 @@ func (s *Server) SetEngagement(e *EngagementAPI) { s.engagement = e }
 // SetEarningsHub sets the live earnings SSE hub.
 func (s *Server) SetEarningsHub(h *EarningsHub) { s.earningsHub = h }
 
+// SetStreamFlushInterval sets the minimum interval between flushes when
+// streaming tokens. Zero (the default) flushes after every token.
+func (s *Server) SetStreamFlushInterval(d time.Duration) { s.streamFlushInterval = d }
+
 // EarningsHub returns the live earnings hub (for broadcasting events).
 func (s *Server) EarningsHub() *EarningsHub { return s.earningsHub }
 
@@ -260,18 +267,11 @@ func corsMiddleware(next http.Handler) http.Handler {
 // We keep these unexported since they are only used within the api package.
 
 func defaultLoadOpts() engine.LoadOptions {
-	return engine.LoadOptions{
-		NumGPULayers: -1,
-		NumCtx:       4096,
-	}
+	return engine.DefaultLoadOptions()
 }
 
 func defaultGenParams() engine.GenerateParams {
-	return engine.GenerateParams{
-		Temperature: 0.7,
-		TopP:        0.9,
-		MaxTokens:   2048,
-	}
+	return engine.DefaultGenerateParams()
 }
 
 // modelToOpenAI converts a domain.ModelInfo to OpenAI model list entry.
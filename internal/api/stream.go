@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Token Stream Flushing ──────────────────────────────────────────────────
+// Streaming endpoints flush after every token for the lowest time-to-first-
+// byte. Under high throughput, a minimum flush interval coalesces tiny tokens
+// into fewer writes (fewer syscalls, fewer TCP segments). Pending bytes are
+// never held longer than the interval, even if the model stalls.
+
+// tokenFlusher flushes an http.ResponseWriter at most once per interval.
+// A zero interval flushes on every token. Writers that do not implement
+// http.Flusher are tolerated: output is delivered when the handler returns.
+type tokenFlusher struct {
+	flusher  http.Flusher // nil if the writer cannot flush
+	interval time.Duration
+	last     time.Time
+	pending  bool
+	timer    *time.Timer
+}
+
+func newTokenFlusher(w http.ResponseWriter, interval time.Duration) *tokenFlusher {
+	flusher, _ := w.(http.Flusher)
+	return &tokenFlusher{flusher: flusher, interval: interval}
+}
+
+// Wrote records that a token was written and flushes if the interval elapsed.
+func (f *tokenFlusher) Wrote() {
+	f.pending = true
+	if f.interval <= 0 || time.Since(f.last) >= f.interval {
+		f.Flush()
+	}
+}
+
+// Flush pushes any buffered bytes to the client immediately.
+func (f *tokenFlusher) Flush() {
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+	f.pending = false
+	f.last = time.Now()
+	if f.flusher != nil {
+		f.flusher.Flush()
+	}
+}
+
+// Deadline returns a channel that fires when pending bytes must be flushed,
+// or nil (blocks forever) when nothing is pending.
+func (f *tokenFlusher) Deadline() <-chan time.Time {
+	if !f.pending || f.flusher == nil {
+		return nil
+	}
+	if f.timer == nil {
+		wait := f.interval - time.Since(f.last)
+		if wait < 0 {
+			wait = 0
+		}
+		f.timer = time.NewTimer(wait)
+	}
+	return f.timer.C
+}
+
+// pumpTokens writes every token via emit, flushing per the server's
+// stream flush interval. Returns once tokenCh is closed, with all
+// emitted bytes flushed.
+func (s *Server) pumpTokens(w http.ResponseWriter, tokenCh <-chan domain.Token, emit func(domain.Token)) *tokenFlusher {
+	f := newTokenFlusher(w, s.streamFlushInterval)
+	for {
+		select {
+		case tok, ok := <-tokenCh:
+			if !ok {
+				f.Flush()
+				return f
+			}
+			emit(tok)
+			f.Wrote()
+		case <-f.Deadline():
+			f.timer = nil
+			f.Flush()
+		}
+	}
+}
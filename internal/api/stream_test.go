@@ -0,0 +1,189 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// flushRecorder captures the body as seen by the client at each Flush.
+type flushRecorder struct {
+	mu      sync.Mutex
+	header  http.Header
+	buf     bytes.Buffer
+	flushed []string
+}
+
+func newFlushRecorder() *flushRecorder {
+	return &flushRecorder{header: make(http.Header)}
+}
+
+func (r *flushRecorder) Header() http.Header { return r.header }
+func (r *flushRecorder) WriteHeader(int)     {}
+
+func (r *flushRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(p)
+}
+
+func (r *flushRecorder) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushed = append(r.flushed, r.buf.String())
+}
+
+func (r *flushRecorder) Flushes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.flushed...)
+}
+
+// plainWriter is a ResponseWriter that does not implement http.Flusher.
+type plainWriter struct {
+	header http.Header
+	buf    bytes.Buffer
+}
+
+func (p *plainWriter) Header() http.Header         { return p.header }
+func (p *plainWriter) WriteHeader(int)             {}
+func (p *plainWriter) Write(b []byte) (int, error) { return p.buf.Write(b) }
+
+func tokensOf(texts ...string) <-chan domain.Token {
+	ch := make(chan domain.Token, len(texts))
+	for _, t := range texts {
+		ch <- domain.Token{Text: t}
+	}
+	close(ch)
+	return ch
+}
+
+func emitText(w http.ResponseWriter) func(domain.Token) {
+	return func(tok domain.Token) { fmt.Fprint(w, tok.Text) }
+}
+
+func TestPumpTokens_FlushesEveryToken(t *testing.T) {
+	srv := &Server{}
+	rec := newFlushRecorder()
+
+	srv.pumpTokens(rec, tokensOf("a", "b", "c"), emitText(rec))
+
+	flushes := rec.Flushes()
+	if len(flushes) < 3 {
+		t.Fatalf("flushes = %d, want at least 3 (one per token)", len(flushes))
+	}
+	for i, want := range []string{"a", "ab", "abc"} {
+		if flushes[i] != want {
+			t.Errorf("flush[%d] = %q, want %q", i, flushes[i], want)
+		}
+	}
+}
+
+func TestPumpTokens_CoalescesWithinInterval(t *testing.T) {
+	srv := &Server{}
+	srv.SetStreamFlushInterval(time.Hour)
+	rec := newFlushRecorder()
+
+	srv.pumpTokens(rec, tokensOf("a", "b", "c", "d", "e"), emitText(rec))
+
+	flushes := rec.Flushes()
+	// First token flushes immediately; the rest coalesce until the stream closes.
+	if len(flushes) != 2 {
+		t.Fatalf("flushes = %v, want 2", flushes)
+	}
+	if flushes[0] != "a" || flushes[1] != "abcde" {
+		t.Errorf("flushes = %v, want [a abcde]", flushes)
+	}
+}
+
+func TestPumpTokens_FlushesPendingAfterInterval(t *testing.T) {
+	srv := &Server{}
+	srv.SetStreamFlushInterval(20 * time.Millisecond)
+	rec := newFlushRecorder()
+
+	ch := make(chan domain.Token)
+	done := make(chan struct{})
+	go func() {
+		srv.pumpTokens(rec, ch, emitText(rec))
+		close(done)
+	}()
+
+	ch <- domain.Token{Text: "a"}
+	ch <- domain.Token{Text: "b"} // within the interval — held back
+
+	// The model stalls; the pending token must still reach the client.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		f := rec.Flushes()
+		if len(f) > 0 && f[len(f)-1] == "ab" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pending token never flushed, flushes = %v", f)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	close(ch)
+	<-done
+}
+
+func TestPumpTokens_NonFlushableWriter(t *testing.T) {
+	srv := &Server{}
+	w := &plainWriter{header: make(http.Header)}
+
+	f := srv.pumpTokens(w, tokensOf("x", "y"), emitText(w))
+	f.Flush() // must not panic
+
+	if w.buf.String() != "xy" {
+		t.Errorf("body = %q, want %q", w.buf.String(), "xy")
+	}
+}
+
+func TestOllamaGenerateStream_FlushesIncrementally(t *testing.T) {
+	srv := &Server{}
+	rec := newFlushRecorder()
+
+	srv.streamOllamaGenerate(rec, tokensOf("Hel", "lo"), "test-model")
+
+	flushes := rec.Flushes()
+	if len(flushes) < 3 {
+		t.Fatalf("flushes = %d, want at least 3", len(flushes))
+	}
+	if strings.Contains(flushes[0], `"lo"`) {
+		t.Error("first flush should only contain the first token")
+	}
+	if !strings.Contains(flushes[len(flushes)-1], `"done":true`) {
+		t.Error("final flush should include the done message")
+	}
+}
+
+func TestOllamaGenerateStream_FinalCarriesStats(t *testing.T) {
+	ch := make(chan domain.Token, 2)
+	ch <- domain.Token{Text: "Hi"}
+	ch <- domain.Token{Done: true, Stats: &domain.StreamStats{
+		PromptTokens:     12,
+		CompletionTokens: 3,
+		TimeToFirstToken: 40 * time.Millisecond,
+		Duration:         100 * time.Millisecond,
+	}}
+	close(ch)
+
+	srv := &Server{}
+	rec := newFlushRecorder()
+	srv.streamOllamaGenerate(rec, ch, "test-model")
+
+	flushes := rec.Flushes()
+	final := flushes[len(flushes)-1]
+	for _, want := range []string{`"prompt_eval_count":12`, `"eval_count":3`, `"total_duration":100000000`, `"eval_duration":60000000`} {
+		if !strings.Contains(final, want) {
+			t.Errorf("final message %s missing %s", final, want)
+		}
+	}
+}
@@ -79,9 +79,43 @@ func (s *Server) handleOllamaShow(w http.ResponseWriter, r *http.Request) {
 // --- /api/generate (text generation) ---
 
 type ollamaGenerateRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream *bool  `json:"stream,omitempty"`
+	Model   string         `json:"model"`
+	Prompt  string         `json:"prompt"`
+	Stream  *bool          `json:"stream,omitempty"`
+	Options *ollamaOptions `json:"options,omitempty"`
+}
+
+// ollamaOptions are the Ollama sampling options; unset fields keep the
+// defaults.
+type ollamaOptions struct {
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"top_p,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Seed        int64    `json:"seed,omitempty"`
+}
+
+// apply overrides params with the options that are set.
+func (o *ollamaOptions) apply(params engine.GenerateParams) engine.GenerateParams {
+	if o == nil {
+		return params
+	}
+	if o.Temperature != nil {
+		params.Temperature = *o.Temperature
+	}
+	if o.TopP != nil {
+		params.TopP = *o.TopP
+	}
+	if o.NumPredict > 0 {
+		params.MaxTokens = o.NumPredict
+	}
+	if len(o.Stop) > 0 {
+		params.Stop = o.Stop
+	}
+	if o.Seed != 0 {
+		params.Seed = o.Seed
+	}
+	return params
 }
 
 func (s *Server) handleOllamaGenerate(w http.ResponseWriter, r *http.Request) {
@@ -98,7 +132,7 @@ func (s *Server) handleOllamaGenerate(w http.ResponseWriter, r *http.Request) {
 	}
 	defer handle.Release()
 
-	params := defaultGenParams()
+	params := req.Options.apply(defaultGenParams())
 	tokenCh, err := handle.Model().Generate(r.Context(), req.Prompt, params)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
@@ -108,53 +142,69 @@ func (s *Server) handleOllamaGenerate(w http.ResponseWriter, r *http.Request) {
 	stream := req.Stream == nil || *req.Stream
 
 	if stream {
-		s.streamOllamaGenerate(w, tokenCh, req.Model)
+		s.streamOllamaGenerate(w, tokenCh, handle.Name())
 	} else {
-		s.nonStreamOllamaGenerate(w, tokenCh, req.Model)
+		s.nonStreamOllamaGenerate(w, tokenCh, handle.Name())
 	}
 }
 
 func (s *Server) streamOllamaGenerate(w http.ResponseWriter, tokenCh <-chan domain.Token, model string) {
 	w.Header().Set("Content-Type", "application/x-ndjson")
 	w.WriteHeader(http.StatusOK)
-	flusher, _ := w.(http.Flusher)
 
 	enc := json.NewEncoder(w)
-	for tok := range tokenCh {
+	var stats *domain.StreamStats
+	flusher := s.pumpTokens(w, tokenCh, func(tok domain.Token) {
+		if tok.Stats != nil {
+			stats = tok.Stats
+		}
 		enc.Encode(map[string]interface{}{
 			"model":      model,
 			"created_at": time.Now().Format(time.RFC3339Nano),
 			"response":   tok.Text,
 			"done":       false,
 		})
-		if flusher != nil {
-			flusher.Flush()
-		}
-	}
+	})
 
 	// Final
-	enc.Encode(map[string]interface{}{
+	enc.Encode(withOllamaStats(map[string]interface{}{
 		"model":      model,
 		"created_at": time.Now().Format(time.RFC3339Nano),
 		"response":   "",
 		"done":       true,
-	})
-	if flusher != nil {
-		flusher.Flush()
-	}
+	}, stats))
+	flusher.Flush()
 }
 
 func (s *Server) nonStreamOllamaGenerate(w http.ResponseWriter, tokenCh <-chan domain.Token, model string) {
 	var response string
+	var stats *domain.StreamStats
 	for tok := range tokenCh {
 		response += tok.Text
+		if tok.Stats != nil {
+			stats = tok.Stats
+		}
 	}
-	writeJSON(w, http.StatusOK, map[string]interface{}{
+	writeJSON(w, http.StatusOK, withOllamaStats(map[string]interface{}{
 		"model":      model,
 		"created_at": time.Now().Format(time.RFC3339Nano),
 		"response":   response,
 		"done":       true,
-	})
+	}, stats))
+}
+
+// withOllamaStats adds Ollama's final-response metrics (counts and
+// nanosecond durations) to resp when the engine measured the stream.
+func withOllamaStats(resp map[string]interface{}, stats *domain.StreamStats) map[string]interface{} {
+	if stats == nil {
+		return resp
+	}
+	resp["prompt_eval_count"] = stats.PromptTokens
+	resp["eval_count"] = stats.CompletionTokens
+	resp["total_duration"] = stats.Duration.Nanoseconds()
+	resp["prompt_eval_duration"] = stats.TimeToFirstToken.Nanoseconds()
+	resp["eval_duration"] = (stats.Duration - stats.TimeToFirstToken).Nanoseconds()
+	return resp
 }
 
 // --- /api/chat (chat generation) ---
@@ -171,6 +221,10 @@ func (s *Server) handleOllamaChat(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := validateChat(req.Messages); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	handle, err := s.pool.Acquire(req.Model, defaultLoadOpts())
 	if err != nil {
@@ -193,19 +247,22 @@ func (s *Server) handleOllamaChat(w http.ResponseWriter, r *http.Request) {
 	stream := req.Stream == nil || *req.Stream
 
 	if stream {
-		s.streamOllamaChat(w, tokenCh, req.Model)
+		s.streamOllamaChat(w, tokenCh, handle.Name())
 	} else {
-		s.nonStreamOllamaChat(w, tokenCh, req.Model)
+		s.nonStreamOllamaChat(w, tokenCh, handle.Name())
 	}
 }
 
 func (s *Server) streamOllamaChat(w http.ResponseWriter, tokenCh <-chan domain.Token, model string) {
 	w.Header().Set("Content-Type", "application/x-ndjson")
 	w.WriteHeader(http.StatusOK)
-	flusher, _ := w.(http.Flusher)
 
 	enc := json.NewEncoder(w)
-	for tok := range tokenCh {
+	var stats *domain.StreamStats
+	flusher := s.pumpTokens(w, tokenCh, func(tok domain.Token) {
+		if tok.Stats != nil {
+			stats = tok.Stats
+		}
 		enc.Encode(map[string]interface{}{
 			"model":      model,
 			"created_at": time.Now().Format(time.RFC3339Nano),
@@ -215,12 +272,9 @@ func (s *Server) streamOllamaChat(w http.ResponseWriter, tokenCh <-chan domain.T
 			},
 			"done": false,
 		})
-		if flusher != nil {
-			flusher.Flush()
-		}
-	}
+	})
 
-	enc.Encode(map[string]interface{}{
+	enc.Encode(withOllamaStats(map[string]interface{}{
 		"model":      model,
 		"created_at": time.Now().Format(time.RFC3339Nano),
 		"message": map[string]interface{}{
@@ -228,18 +282,20 @@ func (s *Server) streamOllamaChat(w http.ResponseWriter, tokenCh <-chan domain.T
 			"content": "",
 		},
 		"done": true,
-	})
-	if flusher != nil {
-		flusher.Flush()
-	}
+	}, stats))
+	flusher.Flush()
 }
 
 func (s *Server) nonStreamOllamaChat(w http.ResponseWriter, tokenCh <-chan domain.Token, model string) {
 	var content string
+	var stats *domain.StreamStats
 	for tok := range tokenCh {
 		content += tok.Text
+		if tok.Stats != nil {
+			stats = tok.Stats
+		}
 	}
-	writeJSON(w, http.StatusOK, map[string]interface{}{
+	writeJSON(w, http.StatusOK, withOllamaStats(map[string]interface{}{
 		"model":      model,
 		"created_at": time.Now().Format(time.RFC3339Nano),
 		"message": map[string]interface{}{
@@ -247,7 +303,7 @@ func (s *Server) nonStreamOllamaChat(w http.ResponseWriter, tokenCh <-chan domai
 			"content": content,
 		},
 		"done": true,
-	})
+	}, stats))
 }
 
 // --- /api/pull ---
@@ -264,7 +320,7 @@ func (s *Server) handleOllamaPull(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := s.models.Pull(req.Name, func(status string, pct float64) {
+	err := s.models.Pull(r.Context(), req.Name, func(status string, pct float64) {
 		// For non-streaming, we just wait
 	})
 	if err != nil {
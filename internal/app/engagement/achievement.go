@@ -1,6 +1,13 @@
 package engagement
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
@@ -13,6 +20,7 @@ import (
 type AchievementService struct {
 	db          *sqlite.DB
 	definitions []domain.AchievementDef
+	notifier    *NotificationService // nil = no unlock notifications
 }
 
 // NewAchievementService creates an achievement service with all definitions.
@@ -23,9 +31,23 @@ func NewAchievementService(db *sqlite.DB) *AchievementService {
 	}
 }
 
+// SetNotifier routes unlock notifications through n. Achievements unlocked
+// by the same check are announced together in one notification.
+func (a *AchievementService) SetNotifier(n *NotificationService) {
+	a.notifier = n
+}
+
 // CheckAndUnlock evaluates all achievements against current stats.
 // Returns newly unlocked achievements (idempotent — already-unlocked are skipped).
 func (a *AchievementService) CheckAndUnlock(stats domain.UserStats) ([]domain.AchievementDef, error) {
+	return a.CheckAndUnlockAt(stats, time.Now())
+}
+
+// CheckAndUnlockAt is CheckAndUnlock with an explicit unlock time.
+// If a notifier is set, every achievement unlocked by this call is
+// announced in a single batched notification. A notification failure is
+// returned alongside the (already recorded) unlocks.
+func (a *AchievementService) CheckAndUnlockAt(stats domain.UserStats, now time.Time) ([]domain.AchievementDef, error) {
 	var newlyUnlocked []domain.AchievementDef
 
 	for _, def := range a.definitions {
@@ -40,7 +62,7 @@ func (a *AchievementService) CheckAndUnlock(stats domain.UserStats) ([]domain.Ac
 
 		// Check predicate
 		if def.Predicate != nil && def.Predicate(stats) {
-			isNew, err := a.db.UnlockAchievement(def.ID, time.Now())
+			isNew, err := a.db.UnlockAchievement(def.ID, now)
 			if err != nil {
 				return nil, err
 			}
@@ -50,9 +72,35 @@ func (a *AchievementService) CheckAndUnlock(stats domain.UserStats) ([]domain.Ac
 		}
 	}
 
+	if a.notifier != nil && len(newlyUnlocked) > 0 {
+		if _, err := a.notifier.Create(unlockNotification(newlyUnlocked, now)); err != nil {
+			return newlyUnlocked, fmt.Errorf("notify achievements: %w", err)
+		}
+	}
+
 	return newlyUnlocked, nil
 }
 
+// unlockNotification builds one notification announcing every achievement
+// in defs.
+func unlockNotification(defs []domain.AchievementDef, now time.Time) domain.Notification {
+	names := make([]string, len(defs))
+	for i, def := range defs {
+		names[i] = def.Icon + " " + def.Name
+	}
+
+	title := "Achievement Unlocked!"
+	if len(defs) > 1 {
+		title = fmt.Sprintf("You unlocked %d achievements!", len(defs))
+	}
+	return domain.Notification{
+		Type:      domain.NotifyAchievement,
+		Title:     title,
+		Body:      strings.Join(names, ", "),
+		CreatedAt: now,
+	}
+}
+
 // ListUnlocked returns all achievements the user has earned.
 func (a *AchievementService) ListUnlocked() ([]domain.UnlockedAchievement, error) {
 	return a.db.ListUnlockedAchievements()
@@ -73,6 +121,185 @@ func (a *AchievementService) Definitions() []domain.AchievementDef {
 	return a.definitions
 }
 
+// ─── Configurable Definitions ───────────────────────────────────────────────
+// Communities can ship their own catalog as JSON. Each achievement's trigger
+// is a structured rule over UserStats instead of Go code:
+//
+//	[{"id": "streak_3", "name": "Warming Up", "category": "streaks",
+//	  "icon": "🔥", "reward_xp": 50, "reward_cr": 5,
+//	  "rule": {"stat": "current_streak", "op": ">=", "value": 3}}]
+//
+// A rule is either a comparison (stat, op, value) or {"all": [rules...]},
+// which holds when every sub-rule does.
+
+// achievementSpec is one JSON achievement definition.
+type achievementSpec struct {
+	ID       string                     `json:"id"`
+	Name     string                     `json:"name"`
+	Category domain.AchievementCategory `json:"category"`
+	Icon     string                     `json:"icon"`
+	RewardXP int64                      `json:"reward_xp"`
+	RewardCr int64                      `json:"reward_cr"`
+	Rule     *achievementRule           `json:"rule"`
+}
+
+// achievementRule is a trigger condition over UserStats.
+type achievementRule struct {
+	Stat  string             `json:"stat,omitempty"`
+	Op    string             `json:"op,omitempty"`
+	Value float64            `json:"value,omitempty"`
+	All   []*achievementRule `json:"all,omitempty"`
+}
+
+// statFields maps rule stat names (UserStats JSON keys) to their values.
+var statFields = map[string]func(domain.UserStats) float64{
+	"total_inferences":   func(s domain.UserStats) float64 { return float64(s.TotalInferences) },
+	"models_pulled":      func(s domain.UserStats) float64 { return float64(s.ModelsPulled) },
+	"models_created":     func(s domain.UserStats) float64 { return float64(s.ModelsCreated) },
+	"models_installed":   func(s domain.UserStats) float64 { return float64(s.ModelsInstalled) },
+	"current_streak":     func(s domain.UserStats) float64 { return float64(s.CurrentStreak) },
+	"longest_streak":     func(s domain.UserStats) float64 { return float64(s.LongestStreak) },
+	"lifetime_credits":   func(s domain.UserStats) float64 { return float64(s.LifetimeCredits) },
+	"overnight_earnings": func(s domain.UserStats) float64 { return float64(s.OvernightEarnings) },
+	"referrals":          func(s domain.UserStats) float64 { return float64(s.Referrals) },
+	"agent_runs":         func(s domain.UserStats) float64 { return float64(s.AgentRuns) },
+	"tasks_completed":    func(s domain.UserStats) float64 { return float64(s.TasksCompleted) },
+	"gpu_hours":          func(s domain.UserStats) float64 { return s.GPUHours },
+	"uptime_hours":       func(s domain.UserStats) float64 { return s.UptimeHours },
+	"level":              func(s domain.UserStats) float64 { return float64(s.Level) },
+}
+
+// ruleOps are the supported comparison operators.
+var ruleOps = map[string]func(a, b float64) bool{
+	">=": func(a, b float64) bool { return a >= b },
+	">":  func(a, b float64) bool { return a > b },
+	"==": func(a, b float64) bool { return a == b },
+	"<=": func(a, b float64) bool { return a <= b },
+	"<":  func(a, b float64) bool { return a < b },
+}
+
+// predicate validates the rule and compiles it to a predicate.
+func (r *achievementRule) predicate() (func(domain.UserStats) bool, error) {
+	if r == nil {
+		return nil, errors.New("rule is required")
+	}
+	if len(r.All) > 0 {
+		if r.Stat != "" || r.Op != "" {
+			return nil, errors.New("rule cannot combine \"all\" with a comparison")
+		}
+		preds := make([]func(domain.UserStats) bool, len(r.All))
+		for i, sub := range r.All {
+			p, err := sub.predicate()
+			if err != nil {
+				return nil, fmt.Errorf("all[%d]: %w", i, err)
+			}
+			preds[i] = p
+		}
+		return func(s domain.UserStats) bool {
+			for _, p := range preds {
+				if !p(s) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	}
+
+	field, ok := statFields[r.Stat]
+	if !ok {
+		return nil, fmt.Errorf("unknown stat %q", r.Stat)
+	}
+	cmp, ok := ruleOps[r.Op]
+	if !ok {
+		return nil, fmt.Errorf("unknown operator %q", r.Op)
+	}
+	if math.IsNaN(r.Value) || math.IsInf(r.Value, 0) {
+		return nil, fmt.Errorf("invalid value %v", r.Value)
+	}
+	value := r.Value
+	return func(s domain.UserStats) bool { return cmp(field(s), value) }, nil
+}
+
+// validAchievementCategories lists the categories a definition may use.
+var validAchievementCategories = map[domain.AchievementCategory]bool{
+	domain.CatGettingStarted: true,
+	domain.CatStreaks:        true,
+	domain.CatContribution:   true,
+	domain.CatSocial:         true,
+	domain.CatMastery:        true,
+}
+
+// ParseAchievements decodes and validates a JSON achievement catalog.
+// IDs must be unique and non-empty, categories known, rewards non-negative,
+// and every rule well-formed.
+func ParseAchievements(r io.Reader) ([]domain.AchievementDef, error) {
+	var specs []achievementSpec
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&specs); err != nil {
+		return nil, fmt.Errorf("decode achievements: %w", err)
+	}
+
+	defs := make([]domain.AchievementDef, 0, len(specs))
+	seen := make(map[string]bool, len(specs))
+	for i, spec := range specs {
+		switch {
+		case spec.ID == "":
+			return nil, fmt.Errorf("achievement %d: id is required", i)
+		case seen[spec.ID]:
+			return nil, fmt.Errorf("achievement %q: duplicate id", spec.ID)
+		case spec.Name == "":
+			return nil, fmt.Errorf("achievement %q: name is required", spec.ID)
+		case !validAchievementCategories[spec.Category]:
+			return nil, fmt.Errorf("achievement %q: unknown category %q", spec.ID, spec.Category)
+		case spec.RewardXP < 0 || spec.RewardCr < 0:
+			return nil, fmt.Errorf("achievement %q: rewards must be non-negative", spec.ID)
+		}
+		seen[spec.ID] = true
+
+		pred, err := spec.Rule.predicate()
+		if err != nil {
+			return nil, fmt.Errorf("achievement %q: %w", spec.ID, err)
+		}
+		defs = append(defs, domain.AchievementDef{
+			ID:        spec.ID,
+			Name:      spec.Name,
+			Category:  spec.Category,
+			Icon:      spec.Icon,
+			RewardXP:  spec.RewardXP,
+			RewardCr:  spec.RewardCr,
+			Predicate: pred,
+		})
+	}
+	return defs, nil
+}
+
+// NewAchievementServiceFromJSON creates an achievement service whose
+// catalog is loaded from JSON (see ParseAchievements). A nil reader, empty
+// input, or empty list falls back to the built-in AllAchievements set.
+func NewAchievementServiceFromJSON(db *sqlite.DB, r io.Reader) (*AchievementService, error) {
+	svc := NewAchievementService(db)
+	if r == nil {
+		return svc, nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read achievements: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return svc, nil
+	}
+
+	defs, err := ParseAchievements(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(defs) > 0 {
+		svc.definitions = defs
+	}
+	return svc, nil
+}
+
 // ─── Achievement Definitions (Architecture Part XIII) ───────────────────────
 // 25 achievements across 5 categories. Each has a stat-based predicate.
 
@@ -2,9 +2,11 @@ package engagement_test
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/tutu-network/tutu/internal/app/credit"
 	"github.com/tutu-network/tutu/internal/app/engagement"
 	"github.com/tutu-network/tutu/internal/domain"
 	"github.com/tutu-network/tutu/internal/infra/sqlite"
@@ -177,6 +179,215 @@ func TestStreak_MultiplierCappedAt50Pct(t *testing.T) {
 	}
 }
 
+func TestStreak_RaisedBonusCap(t *testing.T) {
+	db := testDB(t)
+	svc := engagement.NewStreakService(db)
+	svc.SetBonusCap(func() float64 { return 0.75 })
+
+	base := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 20; i++ {
+		_ = svc.RecordContribution(base.AddDate(0, 0, i))
+	}
+
+	if mult := svc.CreditMultiplier(); mult != 1.75 {
+		t.Errorf("expected raised cap at 1.75, got %.2f", mult)
+	}
+}
+
+func TestStreak_DefaultBonusCapSource(t *testing.T) {
+	db := testDB(t)
+	svc := engagement.NewStreakService(db)
+	svc.SetBonusCap(func() float64 { return domain.DefaultStreakBonusCap })
+
+	base := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 20; i++ {
+		_ = svc.RecordContribution(base.AddDate(0, 0, i))
+	}
+
+	if mult := svc.CreditMultiplier(); mult != 1.50 {
+		t.Errorf("expected default cap at 1.50, got %.2f", mult)
+	}
+}
+
+func TestParseBonusCap(t *testing.T) {
+	if c, err := engagement.ParseBonusCap("0.50"); err != nil || c != 0.50 {
+		t.Errorf("ParseBonusCap(0.50) = %v, %v", c, err)
+	}
+	if c, err := engagement.ParseBonusCap("0"); err != nil || c != 0 {
+		t.Errorf("ParseBonusCap(0) = %v, %v", c, err)
+	}
+	for _, bad := range []string{"-0.1", "abc", "", "NaN"} {
+		if _, err := engagement.ParseBonusCap(bad); err == nil {
+			t.Errorf("ParseBonusCap(%q) should fail", bad)
+		}
+	}
+}
+
+func TestStreak_TimezoneDayBoundaries(t *testing.T) {
+	// Two contributions two hours apart, straddling 12:00 UTC.
+	// In UTC both fall on Jul 1; in UTC+12 they are 23:00 Jul 1 and 01:00 Jul 2.
+	first := time.Date(2025, 7, 1, 11, 0, 0, 0, time.UTC)
+	second := time.Date(2025, 7, 1, 13, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		timezone string
+		wantDays int
+	}{
+		{"UTC same day", "", 1},
+		{"UTC+12 consecutive days", "Etc/GMT-12", 2}, // POSIX sign is inverted
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := engagement.NewStreakService(testDB(t))
+			if err := svc.SetTimezone(tt.timezone); err != nil {
+				t.Fatalf("SetTimezone(%q): %v", tt.timezone, err)
+			}
+			_ = svc.RecordContribution(first)
+			_ = svc.RecordContribution(second)
+
+			streak, err := svc.CurrentStreak()
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			if streak.CurrentDays != tt.wantDays {
+				t.Errorf("expected %d days, got %d", tt.wantDays, streak.CurrentDays)
+			}
+		})
+	}
+}
+
+func TestStreak_TimezoneKeepsLocalDailyStreak(t *testing.T) {
+	// A UTC+12 user contributes every local day around noon. Those instants
+	// straddle midnight UTC, so in UTC Jul 2 is skipped.
+	contributions := []time.Time{
+		time.Date(2025, 7, 1, 0, 30, 0, 0, time.UTC),  // Jul 1 12:30 local
+		time.Date(2025, 7, 1, 23, 30, 0, 0, time.UTC), // Jul 2 11:30 local
+		time.Date(2025, 7, 3, 0, 30, 0, 0, time.UTC),  // Jul 3 12:30 local
+		time.Date(2025, 7, 4, 0, 30, 0, 0, time.UTC),  // Jul 4 12:30 local
+	}
+
+	utc := engagement.NewStreakService(testDB(t))
+	local := engagement.NewStreakService(testDB(t))
+	if err := local.SetTimezone("Etc/GMT-12"); err != nil {
+		t.Fatalf("SetTimezone: %v", err)
+	}
+	for _, c := range contributions {
+		_ = utc.RecordContribution(c)
+		_ = local.RecordContribution(c)
+	}
+
+	u, _ := utc.CurrentStreak()
+	l, _ := local.CurrentStreak()
+	if l.CurrentDays != 4 || l.FreezeUsed {
+		t.Errorf("local: expected unbroken 4-day streak without freeze, got %d (freeze=%v)", l.CurrentDays, l.FreezeUsed)
+	}
+	if !u.FreezeUsed {
+		t.Error("UTC: expected the skipped UTC day to consume the weekly freeze")
+	}
+}
+
+func TestStreak_InvalidTimezone(t *testing.T) {
+	svc := engagement.NewStreakService(testDB(t))
+	if err := svc.SetTimezone("Mars/Olympus_Mons"); err == nil {
+		t.Fatal("expected error for invalid timezone")
+	}
+	loc, err := svc.Timezone()
+	if err != nil {
+		t.Fatalf("Timezone: %v", err)
+	}
+	if loc != time.UTC {
+		t.Errorf("expected default UTC, got %v", loc)
+	}
+}
+
+func TestStreak_GraceWindowAfterMidnight(t *testing.T) {
+	// A UTC+12 user contributes on Jul 1, then their Jul 2 contribution is
+	// logged at 00:30 on Jul 3 local — a skewed clock, or a flight.
+	first := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)  // Jul 1 12:00 local
+	late := time.Date(2025, 7, 2, 12, 30, 0, 0, time.UTC) // Jul 3 00:30 local
+	next := time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC)   // Jul 3 12:00 local
+
+	tests := []struct {
+		name       string
+		grace      time.Duration
+		wantDays   int
+		wantFreeze bool
+	}{
+		{"within grace", 2 * time.Hour, 3, false},
+		{"outside grace", 15 * time.Minute, 2, true},
+		{"no grace", 0, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := engagement.NewStreakService(testDB(t))
+			if err := svc.SetTimezone("Etc/GMT-12"); err != nil {
+				t.Fatalf("SetTimezone: %v", err)
+			}
+			if err := svc.SetGraceWindow(tt.grace); err != nil {
+				t.Fatalf("SetGraceWindow(%v): %v", tt.grace, err)
+			}
+			for _, c := range []time.Time{first, late, next} {
+				if err := svc.RecordContribution(c); err != nil {
+					t.Fatalf("record %v: %v", c, err)
+				}
+			}
+
+			streak, _ := svc.CurrentStreak()
+			if streak.CurrentDays != tt.wantDays || streak.FreezeUsed != tt.wantFreeze {
+				t.Errorf("streak = %d days (freeze=%v), want %d (freeze=%v)",
+					streak.CurrentDays, streak.FreezeUsed, tt.wantDays, tt.wantFreeze)
+			}
+		})
+	}
+}
+
+func TestStreak_GraceWindowRepeatedEveningContributions(t *testing.T) {
+	svc := engagement.NewStreakService(testDB(t))
+	if err := svc.SetGraceWindow(2 * time.Hour); err != nil {
+		t.Fatalf("SetGraceWindow: %v", err)
+	}
+
+	// Five contributions on Jul 1, the later ones close to midnight, count
+	// once: nothing is credited to Jul 2 in advance.
+	for _, c := range []time.Time{
+		time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC),
+		time.Date(2025, 7, 1, 22, 10, 0, 0, time.UTC),
+		time.Date(2025, 7, 1, 22, 50, 0, 0, time.UTC),
+		time.Date(2025, 7, 1, 23, 20, 0, 0, time.UTC),
+		time.Date(2025, 7, 1, 23, 40, 0, 0, time.UTC),
+	} {
+		if err := svc.RecordContribution(c); err != nil {
+			t.Fatalf("record %v: %v", c, err)
+		}
+	}
+	streak, _ := svc.CurrentStreak()
+	jul1 := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	if streak.CurrentDays != 1 || !streak.LastDate.Equal(jul1) {
+		t.Errorf("streak = %d days ending %v, want 1 day ending Jul 1", streak.CurrentDays, streak.LastDate)
+	}
+
+	// A late-logged contribution for an earlier day never rewinds LastDate.
+	_ = svc.RecordContribution(time.Date(2025, 7, 2, 12, 0, 0, 0, time.UTC))
+	_ = svc.RecordContribution(time.Date(2025, 7, 1, 18, 0, 0, 0, time.UTC))
+	streak, _ = svc.CurrentStreak()
+	if streak.CurrentDays != 2 || !streak.LastDate.Equal(jul1.AddDate(0, 0, 1)) {
+		t.Errorf("streak = %d days ending %v, want 2 days ending Jul 2", streak.CurrentDays, streak.LastDate)
+	}
+}
+
+func TestStreak_InvalidGraceWindow(t *testing.T) {
+	svc := engagement.NewStreakService(testDB(t))
+	for _, d := range []time.Duration{-time.Hour, engagement.MaxStreakGraceWindow + time.Minute} {
+		if err := svc.SetGraceWindow(d); err == nil {
+			t.Errorf("SetGraceWindow(%v) should fail", d)
+		}
+	}
+	if d, err := svc.GraceWindow(); err != nil || d != 0 {
+		t.Errorf("GraceWindow() = %v, %v; want the default 0", d, err)
+	}
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // Level & XP Tests
 // ═══════════════════════════════════════════════════════════════════════════
@@ -399,6 +610,80 @@ func TestAchievement_StreakAchievement(t *testing.T) {
 	}
 }
 
+func TestAchievement_BatchedNotification(t *testing.T) {
+	db := testDB(t)
+	notifier := engagement.NewNotificationServiceWithPolicy(db, domain.NotificationPolicy{
+		MaxPerDay:  1,
+		QuietStart: "22:00",
+		QuietEnd:   "08:00",
+	})
+	svc := engagement.NewAchievementService(db)
+	svc.SetNotifier(notifier)
+
+	noon := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	stats := domain.UserStats{TotalInferences: 1, ModelsPulled: 1}
+	unlocked, err := svc.CheckAndUnlockAt(stats, noon)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if len(unlocked) != 2 {
+		t.Fatalf("unlocked = %d, want 2 (first_run, first_pull)", len(unlocked))
+	}
+
+	// Each unlock is still recorded individually.
+	if n, _ := svc.UnlockedCount(); n != 2 {
+		t.Errorf("UnlockedCount() = %d, want 2", n)
+	}
+
+	pending, err := notifier.Pending(10)
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("notifications = %d, want 1 batched", len(pending))
+	}
+	got := pending[0]
+	if got.Type != domain.NotifyAchievement {
+		t.Errorf("type = %q, want %q", got.Type, domain.NotifyAchievement)
+	}
+	if got.Title != "You unlocked 2 achievements!" {
+		t.Errorf("title = %q", got.Title)
+	}
+	for _, name := range []string{"First Contact", "Collector"} {
+		if !strings.Contains(got.Body, name) {
+			t.Errorf("body %q missing %q", got.Body, name)
+		}
+	}
+}
+
+func TestAchievement_SingleUnlockNotification(t *testing.T) {
+	db := testDB(t)
+	notifier := engagement.NewNotificationServiceWithPolicy(db, domain.NotificationPolicy{
+		MaxPerDay:  5,
+		QuietStart: "22:00",
+		QuietEnd:   "08:00",
+	})
+	svc := engagement.NewAchievementService(db)
+	svc.SetNotifier(notifier)
+
+	noon := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := svc.CheckAndUnlockAt(domain.UserStats{TotalInferences: 1}, noon); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	// Nothing new — no notification.
+	if _, err := svc.CheckAndUnlockAt(domain.UserStats{TotalInferences: 1}, noon); err != nil {
+		t.Fatalf("recheck: %v", err)
+	}
+
+	pending, _ := notifier.Pending(10)
+	if len(pending) != 1 {
+		t.Fatalf("notifications = %d, want 1", len(pending))
+	}
+	if pending[0].Title != "Achievement Unlocked!" || !strings.Contains(pending[0].Body, "First Contact") {
+		t.Errorf("notification = %q / %q", pending[0].Title, pending[0].Body)
+	}
+}
+
 func TestAchievement_TotalCount(t *testing.T) {
 	db := testDB(t)
 	svc := engagement.NewAchievementService(db)
@@ -436,6 +721,86 @@ func TestAchievement_UnlockedCount(t *testing.T) {
 	}
 }
 
+const customAchievements = `[
+	{"id": "streak_3", "name": "Warming Up", "category": "streaks", "icon": "🔥",
+	 "reward_xp": 50, "reward_cr": 5,
+	 "rule": {"stat": "current_streak", "op": ">=", "value": 3}},
+	{"id": "gpu_marathon", "name": "GPU Marathon", "category": "contribution", "icon": "🏃",
+	 "reward_xp": 500,
+	 "rule": {"all": [
+		{"stat": "gpu_hours", "op": ">=", "value": 24.5},
+		{"stat": "tasks_completed", "op": ">", "value": 10}
+	 ]}}
+]`
+
+func TestAchievement_FromJSON(t *testing.T) {
+	db := testDB(t)
+	svc, err := engagement.NewAchievementServiceFromJSON(db, strings.NewReader(customAchievements))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if svc.TotalCount() != 2 {
+		t.Fatalf("expected 2 custom achievements, got %d", svc.TotalCount())
+	}
+
+	// Stats that would unlock built-ins (first_run) unlock nothing here.
+	unlocked, err := svc.CheckAndUnlock(domain.UserStats{TotalInferences: 5, CurrentStreak: 2, GPUHours: 30, TasksCompleted: 10})
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if len(unlocked) != 0 {
+		t.Fatalf("expected nothing unlocked, got %v", unlocked)
+	}
+
+	unlocked, err = svc.CheckAndUnlock(domain.UserStats{CurrentStreak: 3, GPUHours: 30, TasksCompleted: 11})
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if len(unlocked) != 2 || unlocked[0].ID != "streak_3" || unlocked[1].ID != "gpu_marathon" {
+		t.Fatalf("expected streak_3 and gpu_marathon unlocked, got %v", unlocked)
+	}
+	if unlocked[0].Name != "Warming Up" || unlocked[0].RewardXP != 50 || unlocked[0].Category != domain.CatStreaks {
+		t.Errorf("definition not loaded faithfully: %+v", unlocked[0])
+	}
+}
+
+func TestAchievement_FromJSON_DefaultsToBuiltIn(t *testing.T) {
+	for name, input := range map[string]string{"empty input": "  ", "empty list": "[]"} {
+		svc, err := engagement.NewAchievementServiceFromJSON(testDB(t), strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if svc.TotalCount() != 25 {
+			t.Errorf("%s: expected built-in 25 achievements, got %d", name, svc.TotalCount())
+		}
+	}
+	svc, err := engagement.NewAchievementServiceFromJSON(testDB(t), nil)
+	if err != nil || svc.TotalCount() != 25 {
+		t.Errorf("nil reader: count %d, err %v; want built-in set", svc.TotalCount(), err)
+	}
+}
+
+func TestAchievement_FromJSON_Invalid(t *testing.T) {
+	rule := `"rule": {"stat": "level", "op": ">=", "value": 5}`
+	tests := map[string]string{
+		"malformed":        `[{"id": "x"`,
+		"missing id":       `[{"name": "X", "category": "social", ` + rule + `}]`,
+		"duplicate id":     `[{"id": "x", "name": "X", "category": "social", ` + rule + `}, {"id": "x", "name": "Y", "category": "social", ` + rule + `}]`,
+		"unknown category": `[{"id": "x", "name": "X", "category": "cooking", ` + rule + `}]`,
+		"negative reward":  `[{"id": "x", "name": "X", "category": "social", "reward_xp": -1, ` + rule + `}]`,
+		"missing rule":     `[{"id": "x", "name": "X", "category": "social"}]`,
+		"unknown stat":     `[{"id": "x", "name": "X", "category": "social", "rule": {"stat": "karma", "op": ">=", "value": 1}}]`,
+		"unknown op":       `[{"id": "x", "name": "X", "category": "social", "rule": {"stat": "level", "op": "~", "value": 1}}]`,
+		"bad nested rule":  `[{"id": "x", "name": "X", "category": "social", "rule": {"all": [{"stat": "level", "op": "!!", "value": 1}]}}]`,
+		"unknown field":    `[{"id": "x", "name": "X", "category": "social", "points": 3, ` + rule + `}]`,
+	}
+	for name, input := range tests {
+		if _, err := engagement.NewAchievementServiceFromJSON(testDB(t), strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // Quest Tests
 // ═══════════════════════════════════════════════════════════════════════════
@@ -512,6 +877,106 @@ func TestQuest_RecordProgress(t *testing.T) {
 	}
 }
 
+// insertQuest stores a quest with known rewards, expiring in a week.
+func insertQuest(t *testing.T, db *sqlite.DB, id string, target int) domain.Quest {
+	t.Helper()
+	quest := domain.Quest{
+		ID: id, Type: domain.QuestRAG, Description: "Index documents",
+		Target: target, RewardXP: 150, RewardCredits: 20,
+		ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
+	}
+	if err := db.InsertQuest(quest); err != nil {
+		t.Fatalf("insert quest: %v", err)
+	}
+	return quest
+}
+
+// assertRewards checks the node's total XP and credit balance.
+func assertRewards(t *testing.T, db *sqlite.DB, wantXP, wantCredits int64) {
+	t.Helper()
+	lvl, err := engagement.NewLevelService(db).CurrentLevel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bal, err := credit.NewService(db).Balance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lvl.CurrentXP != wantXP || bal != wantCredits {
+		t.Errorf("xp = %d, credits = %d; want %d, %d", lvl.CurrentXP, bal, wantXP, wantCredits)
+	}
+}
+
+func TestQuest_RecordProgress_GrantsRewardsOnce(t *testing.T) {
+	db := testDB(t)
+	svc := engagement.NewQuestService(db)
+	insertQuest(t, db, "q-rag", 5)
+
+	if _, err := svc.RecordProgress(domain.QuestRAG, 3); err != nil {
+		t.Fatal(err)
+	}
+	assertRewards(t, db, 0, 0)
+
+	completed, err := svc.RecordProgress(domain.QuestRAG, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(completed) != 1 || !completed[0].Completed {
+		t.Fatalf("completed = %+v, want q-rag", completed)
+	}
+	assertRewards(t, db, 150, 20)
+
+	// Retries must not pay out again.
+	for i := 0; i < 3; i++ {
+		if completed, err := svc.RecordProgress(domain.QuestRAG, 2); err != nil || len(completed) != 0 {
+			t.Fatalf("retry %d: completed = %v, err = %v", i, completed, err)
+		}
+	}
+	if pending, err := svc.ClaimPendingRewards(); err != nil || len(pending) != 0 {
+		t.Errorf("ClaimPendingRewards = %v, %v; want nothing pending", pending, err)
+	}
+	assertRewards(t, db, 150, 20)
+}
+
+func TestQuest_RecordProgress_RecoversInterruptedCompletion(t *testing.T) {
+	db := testDB(t)
+	svc := engagement.NewQuestService(db)
+	insertQuest(t, db, "q-rag", 5)
+
+	// A crash after the progress write but before the completion
+	// transaction leaves the quest at its target, still active.
+	if _, err := db.UpdateQuestProgress("q-rag", 5); err != nil {
+		t.Fatal(err)
+	}
+	assertRewards(t, db, 0, 0)
+
+	completed, err := svc.RecordProgress(domain.QuestRAG, 1)
+	if err != nil || len(completed) != 1 {
+		t.Fatalf("completed = %v, err = %v; want q-rag", completed, err)
+	}
+	assertRewards(t, db, 150, 20)
+}
+
+func TestQuest_ClaimPendingRewards(t *testing.T) {
+	db := testDB(t)
+	svc := engagement.NewQuestService(db)
+	insertQuest(t, db, "q-old", 5)
+	if err := db.CompleteQuest("q-old"); err != nil { // completed without rewards
+		t.Fatal(err)
+	}
+
+	rewarded, err := svc.ClaimPendingRewards()
+	if err != nil || len(rewarded) != 1 || rewarded[0].ID != "q-old" {
+		t.Fatalf("ClaimPendingRewards = %v, %v; want q-old", rewarded, err)
+	}
+	assertRewards(t, db, 150, 20)
+
+	if rewarded, _ := svc.ClaimPendingRewards(); len(rewarded) != 0 {
+		t.Errorf("second claim rewarded %v, want nothing", rewarded)
+	}
+	assertRewards(t, db, 150, 20)
+}
+
 func TestQuest_ActiveQuests(t *testing.T) {
 	db := testDB(t)
 	svc := engagement.NewQuestService(db)
@@ -763,6 +1228,26 @@ func TestStreak_Multiplier(t *testing.T) {
 	}
 }
 
+func TestStreak_MultiplierCapped(t *testing.T) {
+	tests := []struct {
+		days int
+		cap  float64
+		want float64
+	}{
+		{20, 0.50, 1.50},
+		{20, 1.00, 2.00},
+		{5, 1.00, 1.25}, // Below a raised cap, per-day rate still applies
+		{5, 0, 1.0},
+		{5, -1, 1.0}, // Negative cap treated as zero
+	}
+	for _, tt := range tests {
+		s := domain.Streak{CurrentDays: tt.days}
+		if got := s.MultiplierCapped(tt.cap); got != tt.want {
+			t.Errorf("MultiplierCapped(%d days, %.2f) = %.2f, want %.2f", tt.days, tt.cap, got, tt.want)
+		}
+	}
+}
+
 func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/tutu-network/tutu/internal/app/credit"
 	"github.com/tutu-network/tutu/internal/domain"
 	"github.com/tutu-network/tutu/internal/infra/sqlite"
 )
@@ -90,7 +91,10 @@ func (q *QuestService) ActiveQuests() ([]domain.Quest, error) {
 }
 
 // RecordProgress increments progress for quests matching the given type.
-// Returns any quests that were completed by this progress.
+// Returns any quests that were completed by this progress. A quest is
+// marked complete and its XP and credits granted in one transaction, so a
+// crash leaves it at its target but incomplete and the next call (with any
+// delta) completes and rewards it.
 func (q *QuestService) RecordProgress(questType domain.QuestType, delta int) ([]domain.Quest, error) {
 	active, err := q.db.ListActiveQuests()
 	if err != nil {
@@ -108,8 +112,14 @@ func (q *QuestService) RecordProgress(questType domain.QuestType, delta int) ([]
 			return nil, err
 		}
 		if updated != nil && updated.Progress >= updated.Target && !updated.Completed {
-			if err := q.db.CompleteQuest(quest.ID); err != nil {
-				return nil, err
+			err := q.db.Tx(func(tx *sqlite.DB) error {
+				if err := tx.CompleteQuest(quest.ID); err != nil {
+					return err
+				}
+				return grantReward(tx, *updated)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("complete quest %s: %w", quest.ID, err)
 			}
 			updated.Completed = true
 			completed = append(completed, *updated)
@@ -119,6 +129,44 @@ func (q *QuestService) RecordProgress(questType domain.QuestType, delta int) ([]
 	return completed, nil
 }
 
+// ClaimPendingRewards grants the rewards of completed quests that have not
+// received them (e.g. completed before rewards were granted automatically).
+// Returns the quests rewarded.
+func (q *QuestService) ClaimPendingRewards() ([]domain.Quest, error) {
+	pending, err := q.db.ListUnrewardedQuests()
+	if err != nil {
+		return nil, err
+	}
+	var rewarded []domain.Quest
+	for _, quest := range pending {
+		if err := q.db.Tx(func(tx *sqlite.DB) error { return grantReward(tx, quest) }); err != nil {
+			return rewarded, fmt.Errorf("reward quest %s: %w", quest.ID, err)
+		}
+		rewarded = append(rewarded, quest)
+	}
+	return rewarded, nil
+}
+
+// grantReward credits quest's XP and credits through tx, unless they were
+// already granted.
+func grantReward(tx *sqlite.DB, quest domain.Quest) error {
+	first, err := tx.MarkQuestRewarded(quest.ID, time.Now())
+	if err != nil || !first {
+		return err
+	}
+	if quest.RewardXP > 0 {
+		if _, _, err := NewLevelService(tx).AddXP(quest.RewardXP, domain.XPQuestCompleted); err != nil {
+			return err
+		}
+	}
+	if quest.RewardCredits > 0 {
+		if err := credit.NewService(tx).Earn(quest.RewardCredits, "quest:"+quest.ID, "Quest reward: "+quest.Description); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CleanupExpired removes quests that expired before now.
 func (q *QuestService) CleanupExpired() (int64, error) {
 	return q.db.DeleteExpiredQuests(time.Now())
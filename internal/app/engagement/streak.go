@@ -5,8 +5,10 @@ package engagement
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"time"
+	_ "time/tzdata" // IANA zones on hosts without a system tz database (Windows)
 
 	"github.com/tutu-network/tutu/internal/domain"
 	"github.com/tutu-network/tutu/internal/infra/sqlite"
@@ -16,8 +18,14 @@ import (
 // A "day" counts if the node contributed ≥1 hour of compute.
 // Bonus: +5% per consecutive day, capped at +50% (10-day max).
 // v3.0: Streaks break SILENTLY — no "streak at risk!" notifications.
+//
+// Day boundaries fall at the user's local midnight (see SetTimezone),
+// defaulting to UTC, with an optional grace window after midnight (see
+// SetGraceWindow).
 type StreakService struct {
 	db *sqlite.DB
+	// bonusCap supplies the current streak bonus ceiling (see SetBonusCap).
+	bonusCap func() float64
 }
 
 // NewStreakService creates a streak service.
@@ -25,10 +33,101 @@ func NewStreakService(db *sqlite.DB) *StreakService {
 	return &StreakService{db: db}
 }
 
+// SetBonusCap sets the source of the streak bonus ceiling, typically the
+// governable "streak_bonus_cap" parameter, read on every multiplier lookup
+// so governance changes apply immediately. Without a source the ceiling is
+// domain.DefaultStreakBonusCap.
+func (s *StreakService) SetBonusCap(fn func() float64) {
+	s.bonusCap = fn
+}
+
+// ParseBonusCap parses a streak bonus cap value (e.g. "0.50"), rejecting
+// anything that is not a non-negative number.
+func ParseBonusCap(value string) (float64, error) {
+	c, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid streak bonus cap %q: %w", value, err)
+	}
+	if c < 0 || math.IsNaN(c) || math.IsInf(c, 0) {
+		return 0, fmt.Errorf("invalid streak bonus cap %q: must be a non-negative number", value)
+	}
+	return c, nil
+}
+
+// SetTimezone sets the user's IANA timezone (e.g. "Pacific/Auckland") used
+// for streak day boundaries. An empty name resets to UTC.
+func (s *StreakService) SetTimezone(name string) error {
+	if name != "" {
+		if _, err := time.LoadLocation(name); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", name, err)
+		}
+	}
+	if err := s.db.SetEngagement("streak_timezone", name); err != nil {
+		return fmt.Errorf("save streak_timezone: %w", err)
+	}
+	return nil
+}
+
+// Timezone returns the user's streak timezone (UTC if unset).
+func (s *StreakService) Timezone() (*time.Location, error) {
+	name, err := s.db.GetEngagement("streak_timezone")
+	if err != nil {
+		return nil, fmt.Errorf("get streak_timezone: %w", err)
+	}
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// MaxStreakGraceWindow bounds the grace window, so a contribution can only
+// ever be credited to its own day or the one before.
+const MaxStreakGraceWindow = 12 * time.Hour
+
+// SetGraceWindow sets how long after local midnight a contribution may
+// still be credited to the previous day, for clock skew and travel: one
+// within d after midnight that would otherwise leave that day missing
+// counts for it. 0 turns the window off.
+func (s *StreakService) SetGraceWindow(d time.Duration) error {
+	if d < 0 || d > MaxStreakGraceWindow {
+		return fmt.Errorf("invalid streak grace window %v: must be between 0 and %v", d, MaxStreakGraceWindow)
+	}
+	if err := s.db.SetEngagement("streak_grace_window", strconv.FormatInt(int64(d), 10)); err != nil {
+		return fmt.Errorf("save streak_grace_window: %w", err)
+	}
+	return nil
+}
+
+// GraceWindow returns the streak grace window (0 if unset).
+func (s *StreakService) GraceWindow() (time.Duration, error) {
+	v, err := s.db.GetEngagement("streak_grace_window")
+	if err != nil {
+		return 0, fmt.Errorf("get streak_grace_window: %w", err)
+	}
+	if v == "" {
+		return 0, nil
+	}
+	ns, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid streak grace window %q: %w", v, err)
+	}
+	return time.Duration(ns), nil
+}
+
 // CurrentStreak loads the current streak state from the database.
+// LastDate is the user's local midnight of the last contribution day.
 func (s *StreakService) CurrentStreak() (domain.Streak, error) {
 	var streak domain.Streak
 
+	loc, err := s.Timezone()
+	if err != nil {
+		return streak, err
+	}
+
 	days, err := s.db.GetEngagement("streak_current")
 	if err != nil {
 		return streak, fmt.Errorf("get streak_current: %w", err)
@@ -51,7 +150,7 @@ func (s *StreakService) CurrentStreak() (domain.Streak, error) {
 	}
 	if lastDate != "" {
 		ts, _ := strconv.ParseInt(lastDate, 10, 64)
-		streak.LastDate = time.Unix(ts, 0)
+		streak.LastDate = localDay(time.Unix(ts, 0), loc)
 	}
 
 	freezeUsed, err := s.db.GetEngagement("streak_freeze_used")
@@ -77,11 +176,20 @@ func (s *StreakService) RecordContribution(day time.Time) error {
 	if err != nil {
 		return err
 	}
+	loc, err := s.Timezone()
+	if err != nil {
+		return err
+	}
+	grace, err := s.GraceWindow()
+	if err != nil {
+		return err
+	}
 
-	today := day.Truncate(24 * time.Hour)
+	today := creditedDay(day, loc, streak.LastDate, grace)
 
-	// Same day — already counted
-	if !streak.LastDate.IsZero() && today.Equal(streak.LastDate.Truncate(24*time.Hour)) {
+	// Same day (or an earlier one) — already counted. LastDate never moves
+	// backwards, so out-of-order contributions cannot extend the streak.
+	if !streak.LastDate.IsZero() && daysBetween(streak.LastDate, today) <= 0 {
 		return nil
 	}
 
@@ -89,14 +197,14 @@ func (s *StreakService) RecordContribution(day time.Time) error {
 		// First contribution ever
 		streak.CurrentDays = 1
 	} else {
-		gap := today.Sub(streak.LastDate.Truncate(24 * time.Hour))
+		gap := daysBetween(streak.LastDate, today)
 
 		switch {
-		case gap <= 24*time.Hour:
+		case gap == 1:
 			// Consecutive day — extend streak
 			streak.CurrentDays++
 
-		case gap <= 48*time.Hour:
+		case gap <= 2:
 			// Missed exactly 1 day — try freeze
 			currentWeek := isoWeek(today)
 			if !streak.FreezeUsed || streak.FreezeWeekISO != currentWeek {
@@ -124,10 +232,13 @@ func (s *StreakService) RecordContribution(day time.Time) error {
 }
 
 // CreditMultiplier returns the streak credit multiplier.
-// +5% per day, capped at +50%.
+// +5% per day, capped at the configured bonus cap (+50% by default).
 func (s *StreakService) CreditMultiplier() float64 {
 	streak, _ := s.CurrentStreak()
-	return streak.Multiplier()
+	if s.bonusCap == nil {
+		return streak.Multiplier()
+	}
+	return streak.MultiplierCapped(s.bonusCap())
 }
 
 // saveStreak persists streak state to the engagement KV table.
@@ -147,6 +258,38 @@ func (s *StreakService) saveStreak(streak domain.Streak) error {
 	return nil
 }
 
+// localDay returns midnight of t's calendar day in loc.
+func localDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// creditedDay returns the local day t counts for. Normally that is t's own
+// calendar day, but a contribution within grace after midnight that would
+// leave a day missing since last counts for the missing day instead. A
+// contribution is never moved forward: crediting tomorrow early would let
+// one evening's work count twice.
+func creditedDay(t time.Time, loc *time.Location, last time.Time, grace time.Duration) time.Time {
+	today := localDay(t, loc)
+	if grace <= 0 || last.IsZero() {
+		return today
+	}
+	if daysBetween(last, today) == 2 && t.Sub(today) < grace {
+		return localDay(today.AddDate(0, 0, -1), loc)
+	}
+	return today
+}
+
+// daysBetween counts calendar days from a to b (both local midnights).
+// Computed on dates, not durations, so DST transitions don't skew it.
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	ua := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	ub := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
+
 // isoWeek returns "YYYY-Www" for the given time.
 func isoWeek(t time.Time) string {
 	year, week := t.ISOWeek()
@@ -32,7 +32,7 @@ func runPull(cmd *cobra.Command, args []string) error {
 
 	fmt.Fprintf(os.Stderr, "pulling %s...\n", modelName)
 	pb := newProgressBar()
-	err = d.Models.Pull(modelName, pb.callback)
+	err = d.Models.Pull(cmd.Context(), modelName, pb.callback)
 	if err != nil {
 		fmt.Fprintln(os.Stderr)
 		return err
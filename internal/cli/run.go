@@ -47,7 +47,7 @@ func runRun(cmd *cobra.Command, args []string) error {
 	if !exists {
 		fmt.Fprintf(os.Stderr, "  Pulling %s...\n", modelName)
 		pb := newProgressBar()
-		if err := d.Models.Pull(modelName, pb.callback); err != nil {
+		if err := d.Models.Pull(cmd.Context(), modelName, pb.callback); err != nil {
 			fmt.Fprintln(os.Stderr)
 			return fmt.Errorf("pull model: %w", err)
 		}
@@ -56,10 +56,7 @@ func runRun(cmd *cobra.Command, args []string) error {
 
 	// Acquire model — this starts llama-server and loads the model into memory
 	fmt.Fprintf(os.Stderr, "  Loading %s...\n", modelName)
-	handle, err := d.Pool.Acquire(modelName, engine.LoadOptions{
-		NumGPULayers: -1,
-		NumCtx:       4096,
-	})
+	handle, err := d.Pool.Acquire(modelName, engine.DefaultLoadOptions())
 	if err != nil {
 		return fmt.Errorf("load model: %w", err)
 	}
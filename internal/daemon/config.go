@@ -23,20 +23,26 @@ type Config struct {
 	Telemetry TelemetryConfig `toml:"telemetry"`
 	MCP       MCPConfig       `toml:"mcp"`
 	Agent     AgentConfig     `toml:"agent"`
+
+	Engagement EngagementConfig `toml:"engagement"`
+	Scheduler  SchedulerConfig  `toml:"scheduler"`
 }
 
 // NodeConfig identifies this node.
 type NodeConfig struct {
-	ID     string `toml:"id"`
-	Region string `toml:"region"`
+	ID      string `toml:"id"`
+	Region  string `toml:"region"`  // "auto" = nearest to Country or the system timezone
+	Country string `toml:"country"` // ISO 3166-1 alpha-2; optional hint for "auto"
+	Email   string `toml:"email"`   // Operator contact; an academic address is pointed to the education tier
 }
 
 // APIConfig controls the HTTP API server.
 type APIConfig struct {
-	Host          string   `toml:"host"`
-	Port          int      `toml:"port"`
-	CORSOrigins   []string `toml:"cors_origins"`
-	MaxConcurrent int      `toml:"max_concurrent"`
+	Host                string   `toml:"host"`
+	Port                int      `toml:"port"`
+	CORSOrigins         []string `toml:"cors_origins"`
+	MaxConcurrent       int      `toml:"max_concurrent"`
+	StreamFlushInterval string   `toml:"stream_flush_interval"` // min gap between token flushes ("" or "0s" = every token)
 }
 
 // ModelsConfig controls model storage.
@@ -45,14 +51,41 @@ type ModelsConfig struct {
 	MaxStorage string `toml:"max_storage"`
 	Default    string `toml:"default"`
 	AutoPull   bool   `toml:"auto_pull"`
+	Registry   string `toml:"registry"` // base URL of a model registry; empty = HuggingFace
+
+	// SystemPrompts are default chat system prompts by model name, used when
+	// a request carries no system message.
+	SystemPrompts map[string]string `toml:"system_prompts"`
 }
 
 // InferenceConfig controls the inference engine.
 type InferenceConfig struct {
-	GPULayers     int `toml:"gpu_layers"`
-	ContextLength int `toml:"context_length"`
-	BatchSize     int `toml:"batch_size"`
-	Threads       int `toml:"threads"`
+	GPULayers     int    `toml:"gpu_layers"`
+	ContextLength int    `toml:"context_length"`
+	BatchSize     int    `toml:"batch_size"`
+	Threads       int    `toml:"threads"`
+	MemoryLimitMB int    `toml:"memory_limit_mb"` // Per-llama-server memory cap (0 = unlimited, Linux only)
+	CPUTimeLimit  string `toml:"cpu_time_limit"`  // Per-llama-server CPU time budget (e.g. "24h", "" = unlimited, Linux only)
+	Warmup        bool   `toml:"warmup"`          // Run a throwaway generation after each model load
+	UnixSocket    bool   `toml:"unix_socket"`     // Serve llama-server on a Unix socket instead of a TCP port (not Windows)
+	ResultCache   int    `toml:"result_cache"`    // Cache up to N temperature-0 results for identical requests (0 = off)
+	KeepAlive     string `toml:"keep_alive"`      // How long a model with no active sessions stays loaded (e.g. "5m")
+	QuantFallback bool   `toml:"quant_fallback"`  // Retry a load that runs out of memory with a smaller installed quantization
+	VerifyModels  bool   `toml:"verify_models"`   // Check a model file's checksum before loading it (hashed once per file change)
+
+	EmbedConcurrency   int    `toml:"embed_concurrency"`    // Max embedding requests in flight per model; ramps up while fast (0 = default 4)
+	EmbedLatencyTarget string `toml:"embed_latency_target"` // Per-request latency above which embedding concurrency backs off (e.g. "1s", "" = default)
+
+	ServerLogLevel string `toml:"server_log_level"` // llama-server log level: error, warn, info, debug, verbose ("" = default)
+	ServerLogFile  string `toml:"server_log_file"`  // Append live llama-server logs to this file ("" = only shown on load failure)
+
+	RemoteNode   string   `toml:"remote_node"`   // Base URL of a TuTu node to proxy RemoteModels to (e.g. "http://10.0.0.5:11434")
+	RemoteModels []string `toml:"remote_models"` // Models served by RemoteNode instead of locally
+
+	// Replicas are how many copies of a model may be loaded at once, by
+	// model name, so concurrent requests for a hot model spread across them
+	// instead of queueing on one server. Unlisted models run one copy.
+	Replicas map[string]int `toml:"replicas"`
 }
 
 // LoggingConfig controls logging behavior.
@@ -99,6 +132,47 @@ type MCPConfig struct {
 	DefaultTier    string `toml:"default_tier"`     // "standard"
 	RateLimitRPM   int    `toml:"rate_limit_rpm"`   // Global rate limit
 	MaxRequestSize string `toml:"max_request_size"` // e.g. "1MB"
+	SlowRequest    string `toml:"slow_request"`     // log requests slower than this (e.g. "10s"; "0s" = off)
+	MaxSessions    int    `toml:"max_sessions"`     // open session cap; initialize gets 503 beyond it (0 = unlimited)
+
+	// White-label branding reported in the initialize result.
+	// Empty name/version keep the built-in defaults.
+	ServerName    string `toml:"server_name"`
+	ServerVersion string `toml:"server_version"`
+	Instructions  string `toml:"instructions"`
+
+	// API key → client ID, for metering. Empty disables authentication.
+	APIKeys        map[string]string `toml:"api_keys"`
+	AllowAnonymous bool              `toml:"allow_anonymous"` // admit requests without a key when APIKeys is set
+
+	// ClientTools restricts clients, by client ID, to the listed tools.
+	// Unlisted clients may call every tool.
+	ClientTools map[string][]string `toml:"client_tools"`
+
+	// ClientTiers assigns clients, by client ID, an SLA tier ("realtime",
+	// "standard", "batch", "spot"); its rate limit is advertised at
+	// initialize. Unlisted clients get DefaultTier.
+	ClientTiers map[string]string `toml:"client_tiers"`
+
+	// ToolArgsLimits overrides the maximum tools/call arguments size per
+	// tool (e.g. "tutu_batch_process" = "768KB").
+	ToolArgsLimits map[string]string `toml:"tool_args_limits"`
+}
+
+// EngagementConfig controls streaks and other engagement features. Empty
+// values keep whatever was last saved in the database.
+type EngagementConfig struct {
+	StreakTimezone    string `toml:"streak_timezone"`     // IANA zone whose midnight ends a streak day (e.g. "Europe/Berlin")
+	StreakGraceWindow string `toml:"streak_grace_window"` // How long after midnight a contribution still counts for the day before (e.g. "2h", "0s" = off)
+}
+
+// SchedulerConfig controls the task queue.
+type SchedulerConfig struct {
+	MaxTaskAge   string `toml:"max_task_age"`   // Longest a task may wait in queue (e.g. "10m"; "" = no cap)
+	MaxAgePolicy string `toml:"max_age_policy"` // What happens past max_task_age: "promote" (run next) or "drop"
+
+	ReputationBoost    int     `toml:"reputation_boost"`     // Priority classes gained by trusted submitters (0 = off)
+	ReputationBoostMin float64 `toml:"reputation_boost_min"` // Reputation (0.0–1.0) that earns the boost
 }
 
 // AgentConfig controls the Python agent runtime (Phase 2).
@@ -134,6 +208,8 @@ func DefaultConfig() Config {
 			ContextLength: 4096,
 			BatchSize:     512,
 			Threads:       0, // auto = runtime.NumCPU() - 2
+			KeepAlive:     "5m",
+			QuantFallback: true,
 		},
 		Logging: LoggingConfig{
 			Level:     "info",
@@ -168,6 +244,7 @@ func DefaultConfig() Config {
 			DefaultTier:    "standard",
 			RateLimitRPM:   300,
 			MaxRequestSize: "1MB",
+			MaxSessions:    1000,
 		},
 		Agent: AgentConfig{
 			Enabled:     false, // Opt-in: Python agent runtime
@@ -176,6 +253,10 @@ func DefaultConfig() Config {
 			MaxAgents:   4,
 			AgentsDir:   filepath.Join(homeDir, "agents"),
 		},
+		Scheduler: SchedulerConfig{
+			MaxAgePolicy:       "promote",
+			ReputationBoostMin: 0.8,
+		},
 	}
 }
 
@@ -2,6 +2,8 @@ package daemon
 
 import (
 	"testing"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -63,3 +65,83 @@ func TestParseStorageSize(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeRegion(t *testing.T) {
+	tests := []struct {
+		name string
+		node NodeConfig
+		tz   string
+		want domain.RegionID
+	}{
+		{"configured region", NodeConfig{Region: "ap-south", Country: "DE"}, "Europe/Berlin", domain.RegionAPSouth},
+		{"EU timezone", NodeConfig{Region: "auto"}, "Europe/Berlin", domain.RegionEUWest},
+		{"EU country", NodeConfig{Region: "auto", Country: "NL"}, "", domain.RegionEUWest},
+		{"country beats timezone", NodeConfig{Region: "auto", Country: "IN"}, "Europe/London", domain.RegionAPSouth},
+		{"asian timezone", NodeConfig{Region: "bogus"}, "Asia/Tokyo", domain.RegionAPSouth},
+		{"undetected", NodeConfig{Region: "auto"}, "UTC", domain.RegionUSEast},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nodeRegion(tt.node, tt.tz); got != tt.want {
+				t.Errorf("nodeRegion(%+v, %q) = %q, want %q", tt.node, tt.tz, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	valid := map[string]int64{
+		"512":    512,
+		"512B":   512,
+		"64KB":   64 << 10,
+		"4MB":    4 << 20,
+		" 2 GB ": 2 << 30,
+	}
+	for in, want := range valid {
+		if got, err := parseByteSize(in); err != nil || got != want {
+			t.Errorf("parseByteSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "0", "-1MB", "1.5MB", "10XB", "MB", "1TB"} {
+		if got, err := parseByteSize(in); err == nil {
+			t.Errorf("parseByteSize(%q) = %d, want an error", in, got)
+		}
+	}
+}
+
+func TestParseRequestSize(t *testing.T) {
+	if got, err := parseRequestSize(""); err != nil || got != 0 {
+		t.Errorf("empty = %d, %v; want the transport default (0)", got, err)
+	}
+	if got, err := parseRequestSize("1GB"); err != nil || got != maxRequestSize {
+		t.Errorf("1GB = %d, %v; want it clamped to %d", got, err, maxRequestSize)
+	}
+	if _, err := parseRequestSize("0"); err == nil {
+		t.Error("0 should be rejected, not read as a default")
+	}
+}
+
+func TestValidateClientTiers(t *testing.T) {
+	cfg := DefaultConfig().MCP
+	cfg.ClientTiers = map[string]string{"acme": "realtime"}
+	if err := validateClientTiers(cfg); err != nil {
+		t.Errorf("valid tiers: %v", err)
+	}
+	cfg.ClientTiers["globex"] = "premium"
+	if err := validateClientTiers(cfg); err == nil {
+		t.Error("unknown client tier should be rejected")
+	}
+}
+
+func TestParseToolArgsLimits(t *testing.T) {
+	got, err := parseToolArgsLimits(map[string]string{"tutu_batch_process": "768KB", "tutu_embed": "1GB"})
+	if err != nil {
+		t.Fatalf("parseToolArgsLimits: %v", err)
+	}
+	if got["tutu_batch_process"] != 768<<10 || got["tutu_embed"] != maxRequestSize {
+		t.Errorf("limits = %v, want 768KB and the embed limit clamped to %d", got, maxRequestSize)
+	}
+	if _, err := parseToolArgsLimits(map[string]string{"tutu_embed": "0"}); err == nil {
+		t.Error("a zero limit should be rejected, not read as 50GB")
+	}
+}
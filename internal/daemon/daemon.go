@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -29,7 +32,7 @@ import (
 	"github.com/tutu-network/tutu/internal/infra/healing"
 	"github.com/tutu-network/tutu/internal/infra/intelligence"
 	"github.com/tutu-network/tutu/internal/infra/marketplace"
-	_ "github.com/tutu-network/tutu/internal/infra/metrics" // Register Prometheus metrics
+	"github.com/tutu-network/tutu/internal/infra/metrics" // Registers Prometheus metrics
 	"github.com/tutu-network/tutu/internal/infra/mlscheduler"
 	"github.com/tutu-network/tutu/internal/infra/network"
 	"github.com/tutu-network/tutu/internal/infra/observability"
@@ -56,6 +59,8 @@ type Daemon struct {
 	Server *api.Server
 	cancel context.CancelFunc
 
+	serverLog *os.File // live llama-server log sink, if configured
+
 	// Phase 1 components
 	Idle     *resource.IdleDetector
 	Governor *resource.Governor
@@ -121,6 +126,18 @@ func New() (*Daemon, error) {
 
 // NewWithConfig creates a Daemon with the given configuration.
 func NewWithConfig(cfg Config) (*Daemon, error) {
+	maxBody, err := parseRequestSize(cfg.MCP.MaxRequestSize)
+	if err != nil {
+		return nil, fmt.Errorf("mcp.max_request_size: %w", err)
+	}
+	if err := validateClientTiers(cfg.MCP); err != nil {
+		return nil, err
+	}
+	argLimits, err := parseToolArgsLimits(cfg.MCP.ToolArgsLimits)
+	if err != nil {
+		return nil, err
+	}
+
 	// Open SQLite
 	db, err := sqlite.Open(tutuHome())
 	if err != nil {
@@ -133,11 +150,15 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 		modelsDir = filepath.Join(tutuHome(), "models")
 	}
 	mgr := registry.NewManager(modelsDir, db)
+	if cfg.Models.Registry != "" {
+		mgr.SetRegistryURL(cfg.Models.Registry)
+	}
 
 	// Initialize inference engine
 	// Try real llama-server subprocess backend first
 	// If not found, auto-download it from llama.cpp releases
 	var backend engine.InferenceBackend
+	var serverLog *os.File
 	realBackend, err := engine.NewSubprocessBackend(tutuHome())
 	if err != nil {
 		// llama-server not found — try to auto-download it
@@ -170,15 +191,51 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 
 	// Wire up progress callback for model loading feedback
 	if sb, ok := backend.(*engine.SubprocessBackend); ok {
+		sb.SetLimits(engine.ProcessLimits{
+			MemoryBytes: uint64(max(cfg.Inference.MemoryLimitMB, 0)) << 20,
+			CPUSeconds:  uint64(parseDuration(cfg.Inference.CPUTimeLimit, 0).Seconds()),
+		})
+		if cfg.Inference.Warmup {
+			sb.SetWarmup(engine.DefaultWarmupTimeout)
+		}
+		sb.SetUnixSocket(cfg.Inference.UnixSocket)
+		sb.SetEmbedConcurrency(cfg.Inference.EmbedConcurrency, parseDuration(cfg.Inference.EmbedLatencyTarget, 0))
+		sb.SetLogLevel(cfg.Inference.ServerLogLevel)
+		if cfg.Inference.ServerLogFile != "" {
+			f, err := os.OpenFile(cfg.Inference.ServerLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				log.Printf("[daemon] WARNING: cannot open llama-server log file: %v", err)
+			} else {
+				serverLog = f
+				sb.SetLogOutput(f)
+			}
+		}
 		sb.SetProgress(func(msg string) {
 			fmt.Fprintf(os.Stderr, "\r  %-70s", msg)
 		})
 	}
 
 	pool := engine.NewPool(backend, parseStorageSize(cfg.Models.MaxStorage), mgr.Resolve)
+	for name, prompt := range cfg.Models.SystemPrompts {
+		pool.SetSystemPrompt(name, prompt)
+	}
+	pool.SetResultCache(cfg.Inference.ResultCache)
+	pool.SetIdleTimeout(parseDuration(cfg.Inference.KeepAlive, 0))
+	for name, count := range cfg.Inference.Replicas {
+		pool.SetReplicas(name, count)
+	}
+	if cfg.Inference.QuantFallback {
+		pool.SetQuantFallback(mgr.SmallerVariants, func(name, variant string) {
+			fmt.Fprintf(os.Stderr, "\n  Not enough memory for %s — using %s instead\n", name, variant)
+		})
+	}
+	if cfg.Inference.RemoteNode != "" && len(cfg.Inference.RemoteModels) > 0 {
+		pool.SetRoutePolicy(engine.RouteModels(engine.NewRemoteBackend(cfg.Inference.RemoteNode), cfg.Inference.RemoteModels...))
+	}
 
 	// Initialize API server
 	srv := api.NewServer(pool, mgr)
+	srv.SetStreamFlushInterval(parseDuration(cfg.API.StreamFlushInterval, 0))
 
 	// Enable Prometheus /metrics if configured
 	if cfg.Telemetry.Prometheus {
@@ -191,6 +248,8 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 		Models: mgr,
 		Pool:   pool,
 		Server: srv,
+
+		serverLog: serverLog,
 	}
 
 	// ─── Phase 1 components ────────────────────────────────────────────
@@ -260,16 +319,60 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 
 	// Engagement engine
 	d.Streak = engagement.NewStreakService(db)
+	if tz := cfg.Engagement.StreakTimezone; tz != "" {
+		if err := d.Streak.SetTimezone(tz); err != nil {
+			log.Printf("[daemon] WARNING: streak timezone: %v", err)
+		}
+	}
+	if g := cfg.Engagement.StreakGraceWindow; g != "" {
+		if err := d.Streak.SetGraceWindow(parseDuration(g, -1)); err != nil {
+			log.Printf("[daemon] WARNING: streak grace window: %v", err)
+		}
+	}
 	d.Level = engagement.NewLevelService(db)
-	d.Achievement = engagement.NewAchievementService(db)
+	d.Achievement = loadAchievements(db)
 	d.Quest = engagement.NewQuestService(db)
+	if rewarded, err := d.Quest.ClaimPendingRewards(); err != nil {
+		log.Printf("[daemon] WARNING: quest reward recovery failed: %v", err)
+	} else if len(rewarded) > 0 {
+		log.Printf("[daemon] granted rewards for %d completed quests", len(rewarded))
+	}
 	d.Notification = engagement.NewNotificationService(db)
+	d.Achievement.SetNotifier(d.Notification)
 
 	// MCP Gateway
 	slaEngine := mcp.NewSLAEngine()
 	d.MCPMeter = mcp.NewMeter(slaEngine)
 	d.MCPGateway = mcp.NewGateway(slaEngine, d.MCPMeter)
+	d.MCPGateway.SetIdentity(mcp.ServerIdentity{
+		Name:         cfg.MCP.ServerName,
+		Version:      cfg.MCP.ServerVersion,
+		Instructions: cfg.MCP.Instructions,
+	})
+	d.MCPGateway.SetModelCatalog(d.Models.Catalog)
+	d.MCPGateway.SetSlowThreshold(parseDuration(cfg.MCP.SlowRequest, mcp.DefaultSlowMethodThreshold))
+	d.MCPGateway.SetSessionLimits(func(clientID string) mcp.SessionLimits {
+		tier, ok := cfg.MCP.ClientTiers[clientID]
+		if !ok {
+			tier = cfg.MCP.DefaultTier
+		}
+		return mcp.SessionLimits{
+			Tier:         domain.SLATier(tier),
+			RateLimitRPM: slaEngine.ConfigFor(domain.SLATier(tier)).RateLimitRPM,
+			MaxBodyBytes: maxBody,
+			AllowedTools: cfg.MCP.ClientTools[clientID],
+		}
+	})
+	for tool, n := range argLimits {
+		d.MCPGateway.SetToolArgsLimit(tool, n)
+	}
+	d.MCPGateway.SetInferenceStreamer(poolStreamer(d.Pool))
 	d.MCPTransport = mcp.NewTransport(d.MCPGateway)
+	d.MCPTransport.SetMaxSessions(cfg.MCP.MaxSessions)
+	if len(cfg.MCP.APIKeys) > 0 {
+		d.MCPTransport.SetKeyStore(mcp.StaticKeyStore(cfg.MCP.APIKeys))
+		d.MCPTransport.SetAllowAnonymous(cfg.MCP.AllowAnonymous)
+	}
 
 	// Mount MCP endpoint on the API server
 	srv.SetMCPHandler(d.MCPTransport)
@@ -291,19 +394,41 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 	// ─── Phase 3 components ────────────────────────────────────────────
 
 	// Multi-region router — routes tasks to optimal region
-	localRegion := domain.RegionID(cfg.Node.Region)
-	if !localRegion.IsValid() {
-		localRegion = domain.RegionUSEast // default
-	}
+	localRegion := nodeRegion(cfg.Node, localTimezone())
 	routerCfg := region.DefaultConfig()
 	routerCfg.LocalRegion = localRegion
 	d.Router = region.NewRouter(routerCfg)
 
 	// Advanced scheduler — work stealing, back-pressure, preemption
-	d.Scheduler = scheduler.NewScheduler(scheduler.DefaultConfig())
+	schedCfg := scheduler.DefaultConfig()
+	schedCfg.TierSlots = make(map[domain.SLATier]int)
+	schedCfg.SLATargets = make(map[domain.SLATier]time.Duration)
+	for _, tierCfg := range slaEngine.AllTiers() {
+		schedCfg.TierSlots[tierCfg.Tier] = tierCfg.MaxConcurrent
+		if tierCfg.MaxLatencyP99 > 0 {
+			schedCfg.SLATargets[tierCfg.Tier] = tierCfg.MaxLatencyP99
+		}
+	}
+	schedCfg.MaxTaskAge = parseDuration(cfg.Scheduler.MaxTaskAge, 0)
+	if policy, err := scheduler.ParseMaxAgePolicy(cfg.Scheduler.MaxAgePolicy); err != nil {
+		log.Printf("[daemon] WARNING: scheduler: %v (using %s)", err, policy)
+	} else {
+		schedCfg.MaxAgePolicy = policy
+	}
+	schedCfg.ReputationBoost = cfg.Scheduler.ReputationBoost
+	if cfg.Scheduler.ReputationBoostMin > 0 {
+		schedCfg.ReputationBoostMin = cfg.Scheduler.ReputationBoostMin
+	}
+	d.Scheduler = scheduler.NewScheduler(schedCfg)
+	if n, err := d.Scheduler.Recover(db); err != nil {
+		log.Printf("[daemon] WARNING: task queue recovery failed: %v", err)
+	} else if n > 0 {
+		log.Printf("[daemon] recovered %d queued tasks", n)
+	}
 
 	// Distributed tracing (ring buffer)
 	d.Tracer = observability.NewTracer(observability.DefaultTracerConfig())
+	d.Scheduler.SetTracer(d.Tracer)
 
 	// Self-healing — circuit breaker for Cloud Core calls
 	d.Breaker = healing.NewCircuitBreaker("cloud-core", healing.DefaultCircuitBreakerConfig())
@@ -326,12 +451,44 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 
 	// Federation registry — private sub-networks for organizations
 	d.Federation = federation.NewRegistry(federation.DefaultRegistryConfig())
+	d.Scheduler.SetNodeFilter(func(task domain.Task, nodeID string) bool {
+		return d.Federation.CanExecute(task.FederationID, nodeID)
+	})
 
 	// Governance engine — credit-weighted voting on network parameters
 	d.Governance = governance.NewEngine(governance.DefaultEngineConfig())
 
 	// Reputation tracker — EMA-based trust scoring for nodes
 	d.Reputation = reputation.NewTracker(reputation.DefaultTrackerConfig())
+	reputationScore := func(nodeID string) (float64, bool) {
+		rep := d.Reputation.Get(nodeID)
+		if rep == nil {
+			return 0, false
+		}
+		return rep.Overall(), true
+	}
+	d.Governance.SetReputationProvider(reputationScore)
+	// The local ledger only holds this node's balance; delegations from
+	// other nodes carry no weight until their balances are known here.
+	d.Governance.SetCreditProvider(func(id string) int64 {
+		if id != nodeID {
+			return 0
+		}
+		balance, err := d.Credit.Balance()
+		if err != nil {
+			log.Printf("[governance] credit balance: %v", err)
+			return 0
+		}
+		return balance
+	})
+	d.Scheduler.SetReputationSource(reputationScore)
+	d.Scheduler.SetLocalNode(nodeID)
+	if d.Fabric != nil {
+		// Tasks assigned by the network keep their submitter for the boost.
+		d.Fabric.OnTaskAssigned(func(task domain.Task) error {
+			return d.Scheduler.EnqueueRemote(task, domain.TaskRouting{})
+		})
+	}
 
 	// Anomaly detector — behavioral profiling + statistical outlier detection
 	d.Anomaly = anomaly.NewDetector(anomaly.DefaultDetectorConfig())
@@ -345,7 +502,33 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 	d.AutoScaler = autoscale.NewScaler(autoscale.DefaultConfig())
 
 	// Self-healing mesh — autonomous incident response with runbooks
-	d.SelfHeal = selfheal.NewMesh(selfheal.DefaultConfig())
+	healCfg := selfheal.DefaultConfig()
+	healCfg.Recorder = metrics.SelfHealRecorder{}
+	healCfg.Quarantine = d.Quarantine
+	d.SelfHeal = selfheal.NewMesh(healCfg)
+
+	if cfg.Inference.VerifyModels {
+		d.Pool.SetIntegrityCheck(d.Models.VerifyBlob, func(name string, err error) {
+			d.SelfHeal.Detect(nodeID, selfheal.FailModelCorrupt)
+		})
+	}
+
+	// tutu_status reads live figures from the components above
+	d.MCPGateway.SetStatusProviders(mcp.StatusProviders{
+		Sessions: d.MCPTransport.SessionCount,
+		MaxSessions: func() int {
+			return d.MCPTransport.Stats().MaxSessions
+		},
+		ActiveModels: func() []string {
+			var names []string
+			for _, m := range d.Pool.LoadedModels() {
+				names = append(names, m.Name)
+			}
+			return names
+		},
+		QueueDepth:      d.Scheduler.QueueDepth,
+		ActiveIncidents: func() int { return len(d.SelfHeal.ActiveIncidents()) },
+	})
 
 	// Network intelligence — model placement optimization + retirement
 	d.Intelligence = intelligence.NewOptimizer(intelligence.DefaultConfig())
@@ -357,12 +540,69 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 
 	// Universal access — free/education/pro/enterprise tier enforcement
 	d.Access = universal.NewAccessManager(universal.DefaultConfig())
+	d.Access.SetReminder(func(ev domain.EducationVerification) {
+		log.Printf("[access] education verification for %s (%s) expires %s; re-verify to keep the education tier",
+			ev.UserID, ev.Email, ev.ExpiresAt.Format("2006-01-02"))
+	})
+	d.Access.SetUpgradeNotifier(func(userID string, suggested domain.AccessTier, reason string) {
+		log.Printf("[access] %s keeps exhausting the daily quota; suggest the %s tier: %s", userID, suggested, reason)
+	})
+	if cfg.Node.Email != "" {
+		d.Access.SetUserEmail(nodeID, cfg.Node.Email)
+	}
 
 	// Economic flywheel — self-sustaining economy health monitoring
 	d.Flywheel = flywheel.NewTracker(flywheel.DefaultConfig())
 
 	// AI democracy — community governance for all network parameters
 	d.Democracy = democracy.NewEngine(democracy.DefaultConfig())
+	d.Democracy.SetGovernanceActivity(d.Governance.Participation)
+	d.Democracy.SetParamValidator("streak_bonus_cap", func(v string) error {
+		_, err := engagement.ParseBonusCap(v)
+		return err
+	})
+	d.Streak.SetBonusCap(func() float64 {
+		p, err := d.Democracy.GetParam("streak_bonus_cap")
+		if err != nil {
+			return domain.DefaultStreakBonusCap
+		}
+		c, err := engagement.ParseBonusCap(p.CurrentValue)
+		if err != nil {
+			return domain.DefaultStreakBonusCap
+		}
+		return c
+	})
+
+	d.Democracy.SetParamValidator("sla_downgrade_policy", func(v string) error {
+		_, err := domain.ParseDowngradePolicy(v)
+		return err
+	})
+	d.MCPGateway.SetDowngradePolicy(func() domain.DowngradePolicy {
+		p, err := d.Democracy.GetParam("sla_downgrade_policy")
+		if err != nil {
+			return domain.DowngradePolicy{}
+		}
+		policy, err := domain.ParseDowngradePolicy(p.CurrentValue)
+		if err != nil {
+			return domain.DowngradePolicy{}
+		}
+		return policy
+	})
+	// A tier is full once scheduler back-pressure would reject its tasks.
+	d.MCPGateway.SetCapacityCheck(d.Scheduler.HasCapacity)
+
+	d.Democracy.OnParamChange(func(key, oldValue, newValue string) {
+		log.Printf("[daemon] governable param %s changed: %s → %s", key, oldValue, newValue)
+	})
+
+	// Critical params get a council veto window before governance applies them.
+	d.Governance.SetVetoPolicy(func(key string) bool {
+		p, err := d.Democracy.GetParam(key)
+		return err == nil && p.Protection == domain.ProtectionCritical
+	}, d.Democracy.IsCouncilMember)
+	d.Governance.SetExecutor(func(p governance.Proposal, approvalPct float64) error {
+		return d.Democracy.ChangeParam(p.ParamKey, p.ParamValue, p.ID, approvalPct/100)
+	})
 
 	return d, nil
 }
@@ -380,6 +620,21 @@ func (d *Daemon) Serve(ctx context.Context) error {
 	// Health checker (always runs)
 	go d.Health.Run(ctx)
 
+	// MCP: close sessions abandoned without a DELETE
+	go d.MCPTransport.IdleReaper(ctx)
+
+	// Governance: close expired proposals, settle conflicts, drop stale drafts
+	go d.Governance.Run(ctx, governance.ResolveInterval)
+
+	// Democracy: revert emergency changes left unratified, vacate ended terms
+	go d.Democracy.Run(ctx, democracy.SweepInterval)
+
+	// Universal access: remind before education verifications lapse, downgrade after
+	go d.Access.Run(ctx, universal.SweepInterval)
+
+	// Scheduler: release dispatched tasks that never completed
+	go d.Scheduler.Run(ctx, scheduler.SweepInterval)
+
 	// Network fabric (if enabled)
 	if d.Config.Network.Enabled {
 		go func() {
@@ -450,6 +705,51 @@ func (d *Daemon) Close() {
 	if d.DB != nil {
 		_ = d.DB.Close()
 	}
+	if d.serverLog != nil {
+		_ = d.serverLog.Close()
+	}
+}
+
+// poolStreamer streams MCP tutu_inference calls from the local model pool.
+// The model stays acquired until the stream ends; ctx cancels generation.
+func poolStreamer(pool *engine.Pool) mcp.InferenceStreamer {
+	return func(ctx context.Context, p domain.InferenceParams) (<-chan domain.Token, error) {
+		handle, err := pool.Acquire(p.Model, engine.DefaultLoadOptions())
+		if err != nil {
+			return nil, err
+		}
+		params := engine.DefaultGenerateParams()
+		if p.MaxToks > 0 {
+			params.MaxTokens = p.MaxToks
+		}
+		var tokens <-chan domain.Token
+		if len(p.Messages) > 0 {
+			msgs := make([]engine.ChatMessage, len(p.Messages))
+			for i, m := range p.Messages {
+				msgs[i] = engine.ChatMessage{Role: string(m.Role), Content: m.Content}
+			}
+			tokens, err = handle.Model().Chat(ctx, msgs, params)
+		} else {
+			tokens, err = handle.Model().Generate(ctx, p.Prompt, params)
+		}
+		if err != nil {
+			handle.Release()
+			return nil, err
+		}
+
+		out := make(chan domain.Token)
+		go func() {
+			defer handle.Release()
+			defer close(out)
+			for tok := range tokens {
+				if tok.Done && handle.Name() != p.Model {
+					tok.Model = handle.Name()
+				}
+				out <- tok
+			}
+		}()
+		return out, nil
+	}
 }
 
 // parseStorageSize converts "50GB" to bytes. Simple parser for config.
@@ -472,6 +772,80 @@ func parseStorageSize(s string) uint64 {
 	}
 }
 
+// validateClientTiers rejects unknown tier names: a misspelt tier would
+// otherwise silently meter and rate-limit the client as spot.
+func validateClientTiers(cfg MCPConfig) error {
+	if domain.SLATier(cfg.DefaultTier).Rank() == 0 {
+		return fmt.Errorf("mcp.default_tier: unknown tier %q", cfg.DefaultTier)
+	}
+	for client, tier := range cfg.ClientTiers {
+		if domain.SLATier(tier).Rank() == 0 {
+			return fmt.Errorf("mcp.client_tiers.%s: unknown tier %q", client, tier)
+		}
+	}
+	return nil
+}
+
+// maxRequestSize caps mcp.max_request_size: the transport buffers whole
+// request bodies, so a larger limit would let one client exhaust memory.
+const maxRequestSize = 64 << 20
+
+// parseRequestSize parses an MCP request size limit. Empty means the
+// transport default (0); larger values are clamped to maxRequestSize.
+func parseRequestSize(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := parseByteSize(s)
+	if err != nil {
+		return 0, err
+	}
+	if n > maxRequestSize {
+		log.Printf("[daemon] WARNING: mcp.max_request_size %s exceeds %dMB, clamping", s, maxRequestSize>>20)
+		n = maxRequestSize
+	}
+	return n, nil
+}
+
+// parseToolArgsLimits parses mcp.tool_args_limits into byte counts, each
+// clamped like max_request_size.
+func parseToolArgsLimits(limits map[string]string) (map[string]int, error) {
+	out := make(map[string]int, len(limits))
+	for tool, size := range limits {
+		n, err := parseByteSize(size)
+		if err != nil {
+			return nil, fmt.Errorf("mcp.tool_args_limits.%s: %w", tool, err)
+		}
+		out[tool] = int(min(n, maxRequestSize))
+	}
+	return out, nil
+}
+
+// parseByteSize strictly parses a positive byte size such as "512KB" or
+// "4MB". A bare number is bytes; units are binary (1KB = 1024 bytes).
+// Unlike parseStorageSize it never substitutes a default.
+func parseByteSize(s string) (int64, error) {
+	num := strings.TrimSpace(s)
+	unit := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(num, u.suffix) {
+			num, unit = strings.TrimSpace(strings.TrimSuffix(num, u.suffix)), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q: want a positive number of bytes, KB, MB or GB", s)
+	}
+	if n > math.MaxInt64/unit {
+		return 0, fmt.Errorf("invalid size %q: too large", s)
+	}
+	return n * unit, nil
+}
+
 // parseDuration parses a duration string, returning a fallback on error.
 func parseDuration(s string, fallback time.Duration) time.Duration {
 	if s == "" {
@@ -483,3 +857,70 @@ func parseDuration(s string, fallback time.Duration) time.Duration {
 	}
 	return d
 }
+
+// nodeRegion resolves the node's region: the configured one when valid,
+// else the region nearest the configured country or, failing that, the
+// continent of the IANA timezone tz.
+func nodeRegion(node NodeConfig, tz string) domain.RegionID {
+	if r := domain.RegionID(node.Region); r.IsValid() {
+		return r
+	}
+	r := domain.DefaultRegionFor(node.Country, timezoneContinent(tz))
+	log.Printf("[daemon] region %q not set, defaulting to %s (country %q, timezone %q)", node.Region, r, node.Country, tz)
+	return r
+}
+
+// timezoneContinent maps an IANA timezone ("Europe/Berlin") to its
+// continent by area, or "" when the area names no continent. "America/"
+// covers both Americas; either way the node lands in RegionUSEast.
+func timezoneContinent(tz string) domain.ContinentID {
+	area, _, _ := strings.Cut(tz, "/")
+	switch area {
+	case "America", "US", "Canada":
+		return domain.ContinentNorthAmerica
+	case "Europe":
+		return domain.ContinentEurope
+	case "Africa":
+		return domain.ContinentAfrica
+	case "Asia", "Indian":
+		return domain.ContinentAsia
+	case "Australia", "Pacific":
+		return domain.ContinentOceania
+	}
+	return ""
+}
+
+// localTimezone returns the system's IANA timezone name from $TZ or the
+// /etc/localtime symlink, or "" when neither names one.
+func localTimezone() string {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		return tz
+	}
+	target, err := os.Readlink("/etc/localtime")
+	if err != nil {
+		return ""
+	}
+	if _, name, ok := strings.Cut(target, "zoneinfo/"); ok {
+		return name
+	}
+	return ""
+}
+
+// loadAchievements builds the achievement service from
+// $TUTU_HOME/achievements.json when present, else the built-in catalog.
+// An invalid file is reported and ignored.
+func loadAchievements(db *sqlite.DB) *engagement.AchievementService {
+	path := filepath.Join(tutuHome(), "achievements.json")
+	f, err := os.Open(path)
+	if err != nil {
+		return engagement.NewAchievementService(db)
+	}
+	defer f.Close()
+
+	svc, err := engagement.NewAchievementServiceFromJSON(db, f)
+	if err != nil {
+		log.Printf("[daemon] WARNING: %s: %v (using built-in achievements)", path, err)
+		return engagement.NewAchievementService(db)
+	}
+	return svc
+}
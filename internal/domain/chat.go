@@ -0,0 +1,81 @@
+package domain
+
+import "fmt"
+
+// ─── Chat Conversations ─────────────────────────────────────────────────────
+// Chat requests are validated here, at the domain boundary, so the API and
+// MCP layers reject malformed conversations before they reach the engine.
+
+// ChatRole is the author of a chat message.
+type ChatRole string
+
+const (
+	RoleSystem    ChatRole = "system"
+	RoleUser      ChatRole = "user"
+	RoleAssistant ChatRole = "assistant"
+	RoleTool      ChatRole = "tool" // result of a tool call requested by the assistant
+)
+
+// IsValid reports whether r is a recognized role.
+func (r ChatRole) IsValid() bool {
+	switch r {
+	case RoleSystem, RoleUser, RoleAssistant, RoleTool:
+		return true
+	}
+	return false
+}
+
+// ChatMessage is one turn of a chat conversation.
+type ChatMessage struct {
+	Role    ChatRole `json:"role"`
+	Content string   `json:"content"`
+}
+
+// ValidateConversation checks that msgs form a well-ordered conversation:
+//
+//   - system messages appear only at the start;
+//   - the first message after them is from the user;
+//   - user and assistant turns alternate, with tool results only after an
+//     assistant turn (and before the next assistant turn);
+//   - the conversation ends with a user or tool message for the model to
+//     answer, and user messages are not empty.
+//
+// Errors wrap ErrInvalidConversation and name the offending message.
+func ValidateConversation(msgs []ChatMessage) error {
+	if len(msgs) == 0 {
+		return fmt.Errorf("%w: no messages", ErrInvalidConversation)
+	}
+
+	var prev ChatRole // role of the previous message; "" at the start
+	for i, m := range msgs {
+		if !m.Role.IsValid() {
+			return fmt.Errorf("%w: message %d has unknown role %q", ErrInvalidConversation, i, m.Role)
+		}
+		var ok bool
+		switch m.Role {
+		case RoleSystem:
+			ok = prev == "" || prev == RoleSystem
+		case RoleUser:
+			ok = prev != RoleUser
+		case RoleAssistant:
+			ok = prev == RoleUser || prev == RoleTool
+		case RoleTool:
+			ok = prev == RoleAssistant || prev == RoleTool
+		}
+		if !ok {
+			if prev == "" {
+				return fmt.Errorf("%w: message %d: conversation cannot start with %s", ErrInvalidConversation, i, m.Role)
+			}
+			return fmt.Errorf("%w: message %d: %s message cannot follow %s", ErrInvalidConversation, i, m.Role, prev)
+		}
+		if m.Role == RoleUser && m.Content == "" {
+			return fmt.Errorf("%w: message %d: empty user message", ErrInvalidConversation, i)
+		}
+		prev = m.Role
+	}
+
+	if prev != RoleUser && prev != RoleTool {
+		return fmt.Errorf("%w: conversation must end with a user or tool message, not %s", ErrInvalidConversation, prev)
+	}
+	return nil
+}
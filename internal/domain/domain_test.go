@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -50,6 +51,116 @@ func TestModelRef_String(t *testing.T) {
 	}
 }
 
+func TestParseModelRef(t *testing.T) {
+	tests := []struct {
+		in        string
+		wantName  string
+		wantQuant string
+		wantErr   error
+	}{
+		{in: "llama-3.2-7b:Q4_K_M", wantName: "llama-3.2-7b", wantQuant: "Q4_K_M"},
+		{in: "llama-3.2-1b:q8_0", wantName: "llama-3.2-1b", wantQuant: "Q8_0"},
+		{in: "llama-3.2-70b", wantName: "llama-3.2-70b"},
+		{in: "llama-3.2-7b:Q9_X", wantErr: ErrUnknownQuantization},
+		{in: "llama-3.2-7b:latest", wantErr: ErrUnknownQuantization},
+		{in: ":Q4_K_M", wantErr: ErrInvalidModelRef},
+		{in: "", wantErr: ErrInvalidModelRef},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			name, quant, err := ParseModelRef(tt.in)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseModelRef(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseModelRef(%q) error: %v", tt.in, err)
+			}
+			if name != tt.wantName || quant != tt.wantQuant {
+				t.Errorf("ParseModelRef(%q) = (%q, %q), want (%q, %q)",
+					tt.in, name, quant, tt.wantName, tt.wantQuant)
+			}
+		})
+	}
+}
+
+func TestModelRef_Quant(t *testing.T) {
+	ref := ModelRef{Name: "llama-3.2-7b", Tag: "q5_k_m"}
+	if got := ref.Quant(); got != "Q5_K_M" {
+		t.Errorf("Quant() = %q, want %q", got, "Q5_K_M")
+	}
+	if got := (ModelRef{Name: "llama3", Tag: "7b"}).Quant(); got != "" {
+		t.Errorf("Quant() for non-quant tag = %q, want empty", got)
+	}
+	if got := (ModelRef{Name: "llama-3.2-7b", Tag: "Q4_K_M"}).String(); got != "llama-3.2-7b:Q4_K_M" {
+		t.Errorf("String() = %q, want name:quant", got)
+	}
+}
+
+func TestQuantizationBits(t *testing.T) {
+	if QuantizationBits("q4_k_m") >= QuantizationBits("Q8_0") {
+		t.Error("Q4_K_M should be smaller than Q8_0")
+	}
+	if QuantizationBits("Q2_K") >= QuantizationBits("Q4_K_M") {
+		t.Error("Q2_K should be smaller than Q4_K_M")
+	}
+	for q := range knownQuantizations {
+		if QuantizationBits(q) == 0 {
+			t.Errorf("QuantizationBits(%s) = 0, want a size for every known quantization", q)
+		}
+	}
+	if got := QuantizationBits("latest"); got != 0 {
+		t.Errorf("QuantizationBits(latest) = %v, want 0", got)
+	}
+}
+
+func TestParseParameterCount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"70B", 70_000_000_000},
+		{"1.1b", 1_100_000_000},
+		{"360M", 360_000_000},
+	}
+	for _, tt := range tests {
+		if got, err := ParseParameterCount(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParseParameterCount(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "unknown", "B", "-1B"} {
+		if _, err := ParseParameterCount(bad); !errors.Is(err, ErrInvalidParameterCount) {
+			t.Errorf("ParseParameterCount(%q) error = %v, want ErrInvalidParameterCount", bad, err)
+		}
+	}
+}
+
+func TestEstimateRequirements(t *testing.T) {
+	big, err := EstimateRequirements("70B", "Q4_K_M")
+	if err != nil {
+		t.Fatal(err)
+	}
+	small, err := EstimateRequirements("1B", "Q8_0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if big.VRAMBytes <= small.VRAMBytes || big.MinRAMBytes <= small.MinRAMBytes {
+		t.Errorf("70B Q4 = %+v, want higher requirements than 1B Q8 = %+v", big, small)
+	}
+	if big.RecommendedTier != HardwareTierHigh || small.RecommendedTier != HardwareTierLow {
+		t.Errorf("tiers = %s, %s; want high, low", big.RecommendedTier, small.RecommendedTier)
+	}
+	if !small.Fits(8<<30, 0) || big.Fits(24<<30, 16<<30) {
+		t.Error("Fits() should accept 1B Q8 on an 8 GB GPU and reject 70B Q4 on a 24 GB node")
+	}
+	if _, err := EstimateRequirements("7B", "latest"); !errors.Is(err, ErrUnknownQuantization) {
+		t.Errorf("unknown quantization error = %v, want ErrUnknownQuantization", err)
+	}
+}
+
 func TestModelRef_FullPath(t *testing.T) {
 	ref := ModelRef{Registry: "registry.tutu.ai", Namespace: "library", Name: "llama3"}
 	got := ref.FullPath()
@@ -152,6 +263,7 @@ func TestSentinelErrors(t *testing.T) {
 		{"ErrInferenceTimeout", ErrInferenceTimeout},
 		{"ErrNoFromDirective", ErrNoFromDirective},
 		{"ErrPoolExhausted", ErrPoolExhausted},
+		{"ErrInvalidConversation", ErrInvalidConversation},
 	}
 
 	for _, tt := range errors {
@@ -166,6 +278,79 @@ func TestSentinelErrors(t *testing.T) {
 	}
 }
 
+// ─── Chat Conversation Tests ────────────────────────────────────────────────
+
+func TestValidateConversation_Valid(t *testing.T) {
+	tests := map[string][]ChatMessage{
+		"single user turn": {{Role: RoleUser, Content: "hi"}},
+		"system then user": {
+			{Role: RoleSystem, Content: "be brief"},
+			{Role: RoleUser, Content: "hi"},
+		},
+		"multi-turn": {
+			{Role: RoleUser, Content: "hi"},
+			{Role: RoleAssistant, Content: "hello"},
+			{Role: RoleUser, Content: "how are you?"},
+		},
+		"tool results": {
+			{Role: RoleUser, Content: "weather?"},
+			{Role: RoleAssistant, Content: ""},
+			{Role: RoleTool, Content: `{"temp":21}`},
+			{Role: RoleTool, Content: `{"wind":5}`},
+		},
+		"tool result answered": {
+			{Role: RoleUser, Content: "weather?"},
+			{Role: RoleAssistant},
+			{Role: RoleTool, Content: `{"temp":21}`},
+			{Role: RoleAssistant, Content: "21 degrees"},
+			{Role: RoleUser, Content: "thanks"},
+		},
+	}
+	for name, msgs := range tests {
+		if err := ValidateConversation(msgs); err != nil {
+			t.Errorf("%s: ValidateConversation() = %v, want nil", name, err)
+		}
+	}
+}
+
+func TestValidateConversation_Rejects(t *testing.T) {
+	tests := map[string][]ChatMessage{
+		"empty":            nil,
+		"unknown role":     {{Role: "robot", Content: "hi"}},
+		"starts assistant": {{Role: RoleAssistant, Content: "hi"}, {Role: RoleUser, Content: "hi"}},
+		"late system": {
+			{Role: RoleUser, Content: "hi"},
+			{Role: RoleSystem, Content: "be brief"},
+			{Role: RoleUser, Content: "hi"},
+		},
+		"user twice":      {{Role: RoleUser, Content: "a"}, {Role: RoleUser, Content: "b"}},
+		"assistant twice": {{Role: RoleUser, Content: "a"}, {Role: RoleAssistant, Content: "b"}, {Role: RoleAssistant, Content: "c"}, {Role: RoleUser, Content: "d"}},
+		"orphan tool":     {{Role: RoleUser, Content: "a"}, {Role: RoleTool, Content: "{}"}},
+		"ends assistant":  {{Role: RoleUser, Content: "a"}, {Role: RoleAssistant, Content: "b"}},
+		"only system":     {{Role: RoleSystem, Content: "be brief"}},
+		"empty final user": {
+			{Role: RoleUser, Content: "a"},
+			{Role: RoleAssistant, Content: "b"},
+			{Role: RoleUser, Content: ""},
+		},
+	}
+	for name, msgs := range tests {
+		if err := ValidateConversation(msgs); !errors.Is(err, ErrInvalidConversation) {
+			t.Errorf("%s: ValidateConversation() = %v, want ErrInvalidConversation", name, err)
+		}
+	}
+}
+
+func TestValidateConversation_NamesOffendingMessage(t *testing.T) {
+	err := ValidateConversation([]ChatMessage{
+		{Role: RoleUser, Content: "a"},
+		{Role: RoleUser, Content: "b"},
+	})
+	if want := "invalid chat conversation: message 1: user message cannot follow user"; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %q", err, want)
+	}
+}
+
 // ─── Credit Type Tests (Phase 1 prep) ───────────────────────────────────────
 
 func TestEntryTypes(t *testing.T) {
@@ -211,3 +396,79 @@ func TestLedgerEntry(t *testing.T) {
 		t.Errorf("expected TxEarn, got %s", entry.Type)
 	}
 }
+
+// ─── SLA Tier Transition Tests ──────────────────────────────────────────────
+
+func TestSLATier_CanUpgradeTo(t *testing.T) {
+	tests := []struct {
+		from, to SLATier
+		want     bool
+	}{
+		{SLASpot, SLARealtime, true},
+		{SLABatch, SLAStandard, true},
+		{SLAStandard, SLARealtime, true},
+		{SLARealtime, SLASpot, false}, // no downgrade while realtime is in flight
+		{SLARealtime, SLAStandard, false},
+		{SLAStandard, SLAStandard, false},
+		{SLASpot, "platinum", false},
+		{"platinum", SLARealtime, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanUpgradeTo(tt.to); got != tt.want {
+			t.Errorf("%s.CanUpgradeTo(%s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestParseDowngradePolicy(t *testing.T) {
+	for _, s := range []string{"deny", "allow:standard", "allow:batch:notify"} {
+		p, err := ParseDowngradePolicy(s)
+		if err != nil {
+			t.Errorf("ParseDowngradePolicy(%q) error: %v", s, err)
+			continue
+		}
+		if p.String() != s {
+			t.Errorf("ParseDowngradePolicy(%q).String() = %q", s, p.String())
+		}
+	}
+	for _, s := range []string{"", "allow", "allow:platinum", "allow:standard:loudly", "permit:standard"} {
+		if _, err := ParseDowngradePolicy(s); !errors.Is(err, ErrInvalidDowngradePolicy) {
+			t.Errorf("ParseDowngradePolicy(%q) error = %v, want ErrInvalidDowngradePolicy", s, err)
+		}
+	}
+}
+
+func TestDowngradePolicy_Resolve(t *testing.T) {
+	toStandard := DowngradePolicy{Allow: true, Target: SLAStandard}
+	if got, err := toStandard.Resolve(SLARealtime); err != nil || got != SLAStandard {
+		t.Errorf("Resolve(realtime) = %q, %v; want standard", got, err)
+	}
+	// The target must be a real downgrade.
+	if _, err := toStandard.Resolve(SLABatch); !errors.Is(err, ErrSLACapacity) {
+		t.Errorf("Resolve(batch) error = %v, want ErrSLACapacity", err)
+	}
+	if _, err := (DowngradePolicy{}).Resolve(SLARealtime); !errors.Is(err, ErrSLACapacity) {
+		t.Errorf("deny policy Resolve(realtime) error = %v, want ErrSLACapacity", err)
+	}
+}
+
+func TestUsageRecord_SplitAt(t *testing.T) {
+	rec := UsageRecord{ClientID: "c1", InputToks: 100, OutputToks: 50, Tier: SLASpot, CostMicro: 3}
+
+	before, after := rec.SplitAt(20, SLARealtime)
+	if before.InputToks != 100 || before.OutputToks != 20 || before.Tier != SLASpot {
+		t.Errorf("before = %+v, want 100 in / 20 out at spot", before)
+	}
+	if after.InputToks != 0 || after.OutputToks != 30 || after.Tier != SLARealtime {
+		t.Errorf("after = %+v, want 0 in / 30 out at realtime", after)
+	}
+	if before.CostMicro != 0 || after.CostMicro != 0 {
+		t.Error("split parts should be left for repricing")
+	}
+
+	// Out-of-range split points clamp to the record.
+	_, after = rec.SplitAt(500, SLARealtime)
+	if after.OutputToks != 0 {
+		t.Errorf("after.OutputToks = %d, want 0 when splitting past the end", after.OutputToks)
+	}
+}
@@ -18,12 +18,25 @@ type Streak struct {
 	FreezeWeekISO string    `json:"freeze_week_iso"` // "2025-W28" — tracks when freeze was used
 }
 
+// DefaultStreakBonusCap is the default ceiling on the streak bonus (+50%).
+// Governable via the "streak_bonus_cap" democracy parameter.
+const DefaultStreakBonusCap = 0.50
+
 // Multiplier returns the credit multiplier for this streak.
 // +5% per consecutive day, capped at +50%.
 func (s Streak) Multiplier() float64 {
+	return s.MultiplierCapped(DefaultStreakBonusCap)
+}
+
+// MultiplierCapped returns the credit multiplier with the bonus capped at
+// bonusCap (e.g. 0.50 → at most 1.50). A negative cap is treated as zero.
+func (s Streak) MultiplierCapped(bonusCap float64) float64 {
 	bonus := float64(s.CurrentDays) * 0.05
-	if bonus > 0.50 {
-		bonus = 0.50
+	if bonus > bonusCap {
+		bonus = bonusCap
+	}
+	if bonus < 0 {
+		bonus = 0
 	}
 	return 1.0 + bonus
 }
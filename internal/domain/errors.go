@@ -12,10 +12,23 @@ var (
 	ErrModelCorrupted = errors.New("model integrity check failed")
 	ErrModelTooLarge  = errors.New("insufficient storage for model")
 
+	// Download errors
+	ErrInsufficientDisk = errors.New("insufficient disk space")
+
+	// Model reference errors
+	ErrInvalidModelRef       = errors.New("invalid model reference")
+	ErrUnknownQuantization   = errors.New("unknown model quantization")
+	ErrInvalidParameterCount = errors.New("invalid model parameter count")
+
 	// Inference errors
-	ErrInferenceTimeout = errors.New("inference request timed out")
-	ErrModelNotLoaded   = errors.New("model not loaded in memory")
-	ErrContextExceeded  = errors.New("context length exceeded")
+	ErrInferenceTimeout    = errors.New("inference request timed out")
+	ErrModelNotLoaded      = errors.New("model not loaded in memory")
+	ErrContextExceeded     = errors.New("context length exceeded")
+	ErrInvalidSampling     = errors.New("sampling parameter out of range")
+	ErrUnsupported         = errors.New("not supported by this inference server build")
+	ErrInvalidConversation = errors.New("invalid chat conversation")
+	ErrNotEmbeddingModel   = errors.New("model not loaded for embeddings")
+	ErrInsufficientMemory  = errors.New("not enough memory to load model")
 
 	// TuTufile errors
 	ErrNoFromDirective  = errors.New("TuTufile must include FROM directive")
@@ -29,10 +42,20 @@ var (
 	// Pool errors
 	ErrPoolExhausted = errors.New("model pool memory exhausted — all models in use")
 
+	// MCP SLA errors
+	ErrSLADowngrade           = errors.New("SLA tier cannot be downgraded during an active session")
+	ErrSLACapacity            = errors.New("SLA tier is at capacity")
+	ErrInvalidDowngradePolicy = errors.New("invalid SLA downgrade policy")
+
 	// Phase 3: Scheduler back-pressure errors
 	ErrBackPressureSoft   = errors.New("back-pressure: soft limit — spot tasks rejected")
 	ErrBackPressureMedium = errors.New("back-pressure: medium limit — only realtime accepted")
 	ErrBackPressureHard   = errors.New("back-pressure: hard limit — all tasks rejected")
+	ErrRealtimeReserved   = errors.New("back-pressure: remaining capacity reserved for realtime")
+	ErrTaskCancelled      = errors.New("task was cancelled")
+	ErrTaskDeadLettered   = errors.New("task failed too many times — moved to dead letters")
+	ErrTaskAgedOut        = errors.New("task waited longer than the maximum task age — dropped")
+	ErrTaskTimedOut       = errors.New("task ran past the dispatch timeout — released")
 
 	// Phase 3: Circuit breaker errors
 	ErrCircuitOpen     = errors.New("circuit breaker is open — service unavailable")
@@ -118,6 +141,7 @@ var (
 	ErrGlobalQuorumLost     = errors.New("global quorum lost — majority of continents unreachable")
 	ErrExabyteStorageFull   = errors.New("planetary model distribution storage at capacity")
 	ErrRoutingLoopDetected  = errors.New("routing loop detected in continental mesh")
+	ErrInvalidContinentLink = errors.New("invalid continent link")
 
 	// Phase 7: Universal access tier errors
 	ErrFreeTierExhausted = errors.New("free tier daily quota exhausted — resets at midnight UTC")
@@ -128,6 +152,7 @@ var (
 	// Phase 7: Economic flywheel errors
 	ErrEconomyUnsustainable = errors.New("economic flywheel health below sustainability threshold")
 	ErrNetworkEffectStalled = errors.New("network effect growth has stalled below minimum rate")
+	ErrEmptyCohort          = errors.New("no contributors joined in the requested cohort week")
 	ErrContributionDeficit  = errors.New("global contribution deficit — more consumption than supply")
 
 	// Phase 7: AI democracy errors
@@ -135,4 +160,5 @@ var (
 	ErrCouncilElectionInvalid = errors.New("council election invalid — insufficient voter turnout")
 	ErrParameterProtected     = errors.New("parameter is protected — requires supermajority (67%+)")
 	ErrOpenSourceViolation    = errors.New("proposed change violates open-source compliance policy")
+	ErrEmergencySignatures    = errors.New("emergency change requires signatures from council members on more continents")
 )
@@ -34,7 +34,7 @@ type ModelStore interface {
 // Implemented by infra/registry.Manager.
 type ModelManager interface {
 	// Pull downloads a model by name with progress reporting.
-	Pull(name string, progress func(status string, pct float64)) error
+	Pull(ctx context.Context, name string, progress func(status string, pct float64)) error
 
 	// Resolve returns the local file path for a model's weights.
 	Resolve(name string) (string, error)
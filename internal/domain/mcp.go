@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // ─── MCP Domain Types ───────────────────────────────────────────────────────
 // Architecture Part XII: MCP Server Gateway — Enterprise & AI-Giant Integration
@@ -18,6 +22,29 @@ const (
 	SLASpot     SLATier = "spot"     // best-effort, $0.02/M tokens
 )
 
+// Rank orders tiers by service level: spot=1 … realtime=4, unknown=0.
+func (t SLATier) Rank() int {
+	switch t {
+	case SLARealtime:
+		return 4
+	case SLAStandard:
+		return 3
+	case SLABatch:
+		return 2
+	case SLASpot:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// CanUpgradeTo reports whether a running session on tier t may move to
+// next. Only strict upgrades are allowed mid-session: a downgrade would
+// release capacity reserved for the higher tier while work is in flight.
+func (t SLATier) CanUpgradeTo(next SLATier) bool {
+	return t.Rank() > 0 && next.Rank() > t.Rank()
+}
+
 // SLAConfig holds the pricing and performance guarantees for a tier.
 type SLAConfig struct {
 	Tier            SLATier       `json:"tier"`
@@ -28,6 +55,66 @@ type SLAConfig struct {
 	Priority        int           `json:"priority"` // Task queue priority (1-255)
 	MaxConcurrent   int           `json:"max_concurrent"`
 	RateLimitRPM    int           `json:"rate_limit_rpm"` // Requests per minute
+
+	// LatencyCreditMicro is the credit owed for a call that takes twice
+	// MaxLatencyP99; smaller misses earn proportionally less. 0 = no credits.
+	LatencyCreditMicro int64 `json:"latency_credit_micro"`
+}
+
+// ─── Downgrade Policy ───────────────────────────────────────────────────────
+
+// DowngradePolicy decides what the gateway does with a call whose SLA tier
+// is at capacity: serve it on a lower tier, or reject it. The network
+// governs it, so paid realtime is never downgraded without the community
+// agreeing to it.
+type DowngradePolicy struct {
+	Allow  bool    `json:"allow"`            // serve on Target instead of rejecting
+	Target SLATier `json:"target,omitempty"` // tier to serve on; must rank below the requested tier
+	Notify bool    `json:"notify"`           // tell the client its call was downgraded
+}
+
+// Resolve returns the tier a call requested on tier is served on when tier
+// is at capacity, or ErrSLACapacity if it must be rejected.
+func (p DowngradePolicy) Resolve(tier SLATier) (SLATier, error) {
+	if !p.Allow || p.Target.Rank() == 0 || p.Target.Rank() >= tier.Rank() {
+		return "", fmt.Errorf("%s: %w", tier, ErrSLACapacity)
+	}
+	return p.Target, nil
+}
+
+// String formats the policy as ParseDowngradePolicy accepts it.
+func (p DowngradePolicy) String() string {
+	if !p.Allow {
+		return "deny"
+	}
+	s := "allow:" + string(p.Target)
+	if p.Notify {
+		s += ":notify"
+	}
+	return s
+}
+
+// ParseDowngradePolicy parses a policy written as "deny" or
+// "allow:<tier>[:notify]", e.g. "allow:standard:notify".
+func ParseDowngradePolicy(s string) (DowngradePolicy, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	switch {
+	case len(parts) == 1 && parts[0] == "deny":
+		return DowngradePolicy{}, nil
+	case parts[0] != "allow" || len(parts) < 2 || len(parts) > 3:
+		return DowngradePolicy{}, fmt.Errorf("%w: %q", ErrInvalidDowngradePolicy, s)
+	}
+	p := DowngradePolicy{Allow: true, Target: SLATier(parts[1])}
+	if p.Target.Rank() == 0 {
+		return DowngradePolicy{}, fmt.Errorf("%w: unknown tier %q", ErrInvalidDowngradePolicy, parts[1])
+	}
+	if len(parts) == 3 {
+		if parts[2] != "notify" {
+			return DowngradePolicy{}, fmt.Errorf("%w: %q", ErrInvalidDowngradePolicy, s)
+		}
+		p.Notify = true
+	}
+	return p, nil
 }
 
 // ─── MCP Client ─────────────────────────────────────────────────────────────
@@ -46,16 +133,16 @@ type MCPClient struct {
 
 // MCPTool represents an MCP tool definition exposed to clients.
 type MCPTool struct {
-	Name        string              `json:"name"`
-	Description string              `json:"description"`
-	InputSchema MCPToolInputSchema  `json:"inputSchema"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	InputSchema MCPToolInputSchema `json:"inputSchema"`
 }
 
 // MCPToolInputSchema is the JSON Schema for tool inputs.
 type MCPToolInputSchema struct {
-	Type       string                     `json:"type"` // always "object"
+	Type       string                       `json:"type"` // always "object"
 	Properties map[string]MCPSchemaProperty `json:"properties"`
-	Required   []string                   `json:"required"`
+	Required   []string                     `json:"required"`
 }
 
 // MCPSchemaProperty defines a single property in a JSON Schema.
@@ -83,15 +170,33 @@ type MCPResourceContent struct {
 	Text     string `json:"text,omitempty"`
 }
 
+// MCPResourceTemplate describes a family of resources addressed by an
+// RFC 6570 URI template, e.g. tutu://regions/{id}.
+type MCPResourceTemplate struct {
+	URITemplate string                 `json:"uriTemplate"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	MimeType    string                 `json:"mimeType"`
+	Parameters  []MCPTemplateParameter `json:"parameters,omitempty"`
+}
+
+// MCPTemplateParameter documents one variable of a resource URI template.
+type MCPTemplateParameter struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Values      []string `json:"values,omitempty"` // accepted values, when the set is closed
+}
+
 // ─── Tool Call Types ────────────────────────────────────────────────────────
 
 // InferenceParams are the arguments for the tutu_inference tool.
 type InferenceParams struct {
-	Model    string  `json:"model"`
-	Prompt   string  `json:"prompt"`
-	Stream   bool    `json:"stream"`
-	Priority SLATier `json:"priority"`
-	MaxToks  int     `json:"max_tokens"`
+	Model    string        `json:"model"`
+	Prompt   string        `json:"prompt"`
+	Messages []ChatMessage `json:"messages,omitempty"` // chat alternative to Prompt
+	Stream   bool          `json:"stream"`
+	Priority SLATier       `json:"priority"`
+	MaxToks  int           `json:"max_tokens"`
 }
 
 // EmbedParams are the arguments for the tutu_embed tool.
@@ -107,6 +212,46 @@ type BatchParams struct {
 	Tier    SLATier  `json:"tier"`
 }
 
+// BatchItemStatus is the outcome of one prompt in a batch.
+type BatchItemStatus string
+
+const (
+	BatchItemOK    BatchItemStatus = "ok"
+	BatchItemError BatchItemStatus = "error"
+)
+
+// BatchItemResult is the outcome of BatchParams.Prompts[Index].
+type BatchItemResult struct {
+	Index        int             `json:"index"`
+	Status       BatchItemStatus `json:"status"`
+	Output       string          `json:"output,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	InputTokens  int             `json:"input_tokens"`
+	OutputTokens int             `json:"output_tokens"`
+}
+
+// BatchResult is the tutu_batch_process result: one entry per prompt, in
+// input order. A failed prompt does not fail the batch — clients retry
+// only the prompts listed by FailedIndices.
+type BatchResult struct {
+	Model     string            `json:"model"`
+	Tier      SLATier           `json:"tier"`
+	Results   []BatchItemResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+}
+
+// FailedIndices returns the input indices of the prompts that failed.
+func (r BatchResult) FailedIndices() []int {
+	var idx []int
+	for _, item := range r.Results {
+		if item.Status != BatchItemOK {
+			idx = append(idx, item.Index)
+		}
+	}
+	return idx
+}
+
 // FineTuneParams are the arguments for the tutu_fine_tune tool.
 type FineTuneParams struct {
 	BaseModel  string `json:"base_model"`
@@ -115,6 +260,19 @@ type FineTuneParams struct {
 	LoRA       bool   `json:"lora"`
 }
 
+// ─── Gateway Status ─────────────────────────────────────────────────────────
+
+// GatewayStatus is the tutu_status result: a live health snapshot of the
+// gateway and the node behind it.
+type GatewayStatus struct {
+	Sessions        int      `json:"sessions"`         // open MCP sessions
+	MaxSessions     int      `json:"max_sessions"`     // session cap; 0 = unlimited
+	ActiveModels    []string `json:"active_models"`    // models loaded for inference, sorted
+	QueueDepth      int      `json:"queue_depth"`      // tasks waiting in the scheduler
+	SLACompliance   float64  `json:"sla_compliance"`   // fraction of metered calls within their tier's latency target
+	ActiveIncidents int      `json:"active_incidents"` // unresolved self-heal incidents
+}
+
 // ─── Usage Metering ─────────────────────────────────────────────────────────
 
 // UsageRecord captures a single metered API call.
@@ -126,8 +284,33 @@ type UsageRecord struct {
 	OutputToks int       `json:"output_tokens"`
 	LatencyMs  int64     `json:"latency_ms"`
 	Tier       SLATier   `json:"tier"`
-	CostMicro  int64     `json:"cost_micro"` // Cost in microdollars (1e-6 USD)
+	CostMicro  int64     `json:"cost_micro"` // Cost in microdollars (1e-6 USD), net of PenaltyMicro
 	Timestamp  time.Time `json:"timestamp"`
+
+	// PenaltyMicro is the SLA latency credit applied to this call (≤ 0).
+	PenaltyMicro int64 `json:"penalty_micro,omitempty"`
+}
+
+// SplitAt divides a record at a tier transition that happened after
+// outputToks output tokens. Input tokens stay with the first part, which
+// keeps the original tier; the second part carries the remaining output at
+// tier next. Costs are cleared — callers reprice each part for its tier.
+func (r UsageRecord) SplitAt(outputToks int, next SLATier) (before, after UsageRecord) {
+	if outputToks < 0 {
+		outputToks = 0
+	}
+	if outputToks > r.OutputToks {
+		outputToks = r.OutputToks
+	}
+
+	before, after = r, r
+	before.OutputToks = outputToks
+	before.CostMicro = 0
+	after.InputToks = 0
+	after.OutputToks = r.OutputToks - outputToks
+	after.Tier = next
+	after.CostMicro = 0
+	return before, after
 }
 
 // ClientUsageSummary aggregates usage over a time period.
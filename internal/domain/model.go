@@ -6,6 +6,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -25,6 +27,13 @@ type ModelInfo struct {
 	Pinned       bool      `json:"pinned"`
 }
 
+// ModelMetadata describes a model as recorded in its GGUF header.
+type ModelMetadata struct {
+	Architecture   string `json:"architecture"`             // e.g. "llama", "qwen2"
+	ParameterCount int64  `json:"parameter_count"`          // total weights across all tensors
+	ContextLength  int    `json:"context_length,omitempty"` // trained context; 0 if not recorded
+}
+
 // Manifest describes a model's layers in OCI-like content-addressed format.
 type Manifest struct {
 	SchemaVersion int    `json:"schemaVersion"`
@@ -74,6 +83,160 @@ func (r ModelRef) FullPath() string {
 	return fmt.Sprintf("%s/%s/%s", r.Registry, r.Namespace, r.Name)
 }
 
+// Quant returns the quantization carried in the tag (e.g. "Q4_K_M"),
+// or "" if the tag is not a known quantization.
+func (r ModelRef) Quant() string {
+	q := strings.ToUpper(r.Tag)
+	if IsKnownQuantization(q) {
+		return q
+	}
+	return ""
+}
+
+// knownQuantizations lists the GGUF quantization types served on the network.
+var knownQuantizations = map[string]bool{
+	"Q2_K": true, "Q3_K_S": true, "Q3_K_M": true, "Q3_K_L": true,
+	"Q4_0": true, "Q4_1": true, "Q4_K_S": true, "Q4_K_M": true,
+	"Q5_0": true, "Q5_1": true, "Q5_K_S": true, "Q5_K_M": true,
+	"Q6_K": true, "Q8_0": true,
+	"F16": true, "BF16": true, "F32": true,
+}
+
+// IsKnownQuantization reports whether q is a recognised GGUF quantization.
+// Matching is case-insensitive.
+func IsKnownQuantization(q string) bool {
+	return knownQuantizations[strings.ToUpper(q)]
+}
+
+// quantizationBits is the approximate storage cost in bits per weight of each
+// known quantization; lower means a smaller, less precise model.
+var quantizationBits = map[string]float64{
+	"Q2_K": 2.6, "Q3_K_S": 3.5, "Q3_K_M": 3.9, "Q3_K_L": 4.3,
+	"Q4_0": 4.5, "Q4_1": 5.0, "Q4_K_S": 4.6, "Q4_K_M": 4.8,
+	"Q5_0": 5.5, "Q5_1": 6.0, "Q5_K_S": 5.5, "Q5_K_M": 5.7,
+	"Q6_K": 6.6, "Q8_0": 8.5,
+	"F16": 16, "BF16": 16, "F32": 32,
+}
+
+// QuantizationBits returns the approximate bits per weight of q, or 0 if q
+// is not a known quantization. Matching is case-insensitive.
+func QuantizationBits(q string) float64 {
+	return quantizationBits[strings.ToUpper(q)]
+}
+
+// ParseModelRef splits a "name[:quant]" reference (e.g. "llama-3.2-7b:Q4_K_M").
+// The quantization is normalised to upper case and validated; quant is ""
+// when the reference carries none.
+func ParseModelRef(s string) (name, quant string, err error) {
+	name, quant, hasQuant := strings.Cut(strings.TrimSpace(s), ":")
+	if name == "" {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidModelRef, s)
+	}
+	if !hasQuant {
+		return name, "", nil
+	}
+	quant = strings.ToUpper(quant)
+	if !knownQuantizations[quant] {
+		return "", "", fmt.Errorf("%w: %q", ErrUnknownQuantization, quant)
+	}
+	return name, quant, nil
+}
+
+// ─── Model Catalog ──────────────────────────────────────────────────────────
+
+// Hardware tiers, by GPU memory: low < 8 GB ≤ mid < 16 GB ≤ high.
+const (
+	HardwareTierLow  = "low"
+	HardwareTierMid  = "mid"
+	HardwareTierHigh = "high"
+)
+
+// ModelRequirements estimates what a node needs to serve one model variant.
+type ModelRequirements struct {
+	VRAMBytes       int64  `json:"vram_bytes"`       // GPU memory to fully offload the model
+	MinRAMBytes     int64  `json:"min_ram_bytes"`    // system memory to run it on CPU
+	RecommendedTier string `json:"recommended_tier"` // hardware tier that fits VRAMBytes
+}
+
+// Fits reports whether a node with the given GPU and system memory can
+// serve the model: fully on the GPU, or else on the CPU.
+func (r ModelRequirements) Fits(vramBytes, ramBytes int64) bool {
+	return vramBytes >= r.VRAMBytes || ramBytes >= r.MinRAMBytes
+}
+
+// Fixed memory on top of the weights: KV cache and compute buffers on the
+// GPU, runtime and OS headroom on the CPU.
+const (
+	vramOverheadBytes = 512 << 20
+	ramOverheadBytes  = 1 << 30
+)
+
+// RequirementsForWeights estimates requirements from the size of a model's
+// weights in bytes, e.g. its GGUF file size.
+func RequirementsForWeights(weightBytes int64) ModelRequirements {
+	vram := weightBytes + weightBytes/10 + vramOverheadBytes
+	tier := HardwareTierHigh
+	switch {
+	case vram < 8<<30:
+		tier = HardwareTierLow
+	case vram < 16<<30:
+		tier = HardwareTierMid
+	}
+	return ModelRequirements{
+		VRAMBytes:       vram,
+		MinRAMBytes:     weightBytes + ramOverheadBytes,
+		RecommendedTier: tier,
+	}
+}
+
+// EstimateRequirements estimates requirements from a parameter count such
+// as "70B" and a quantization such as "Q4_K_M".
+func EstimateRequirements(parameters, quant string) (ModelRequirements, error) {
+	params, err := ParseParameterCount(parameters)
+	if err != nil {
+		return ModelRequirements{}, err
+	}
+	bits := QuantizationBits(quant)
+	if bits == 0 {
+		return ModelRequirements{}, fmt.Errorf("%w: %q", ErrUnknownQuantization, quant)
+	}
+	return RequirementsForWeights(int64(float64(params) * bits / 8)), nil
+}
+
+// ParseParameterCount parses a parameter count with a K, M, B or T suffix,
+// e.g. "1.1B" or "360M". Matching is case-insensitive.
+func ParseParameterCount(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return 0, fmt.Errorf("%w: empty", ErrInvalidParameterCount)
+	}
+	scale := map[byte]float64{'K': 1e3, 'M': 1e6, 'B': 1e9, 'T': 1e12}[s[len(s)-1]]
+	if scale == 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidParameterCount, s)
+	}
+	n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidParameterCount, s)
+	}
+	return int64(n * scale), nil
+}
+
+// CatalogVariant is one quantization of a catalog model.
+type CatalogVariant struct {
+	Name         string `json:"name"` // reference to load, e.g. "llama3:8b"
+	Quantization string `json:"quantization"`
+	SizeBytes    int64  `json:"size_bytes,omitempty"`
+	ModelRequirements
+}
+
+// CatalogModel groups the variants of one model at one parameter count.
+type CatalogModel struct {
+	Name       string           `json:"name"`
+	Family     string           `json:"family,omitempty"`
+	Parameters string           `json:"parameters,omitempty"`
+	Variants   []CatalogVariant `json:"variants"` // smallest first
+}
+
 // ─── Message Types ──────────────────────────────────────────────────────────
 
 // Message represents a chat message.
@@ -103,8 +266,33 @@ type InferenceRequest struct {
 type Token struct {
 	Text string `json:"text"`
 	Done bool   `json:"done"`
+	// FinishReason says why generation stopped; set on the terminal token only.
+	FinishReason string `json:"finish_reason,omitempty"`
+	// Stats summarizes the whole stream; set on the terminal token only, and
+	// only by engines that measure it.
+	Stats *StreamStats `json:"stats,omitempty"`
+	// Model names the model that served the stream when it differs from the
+	// one requested, e.g. a smaller quantization loaded for lack of memory;
+	// set on the terminal token only.
+	Model string `json:"model,omitempty"`
+}
+
+// StreamStats aggregates one completed token stream, for metering and
+// client-visible usage.
+type StreamStats struct {
+	PromptTokens     int           `json:"prompt_tokens"`
+	CompletionTokens int           `json:"completion_tokens"`
+	TimeToFirstToken time.Duration `json:"time_to_first_token"` // request start to first text token
+	Duration         time.Duration `json:"duration"`            // request start to end of stream
+	TokensPerSec     float64       `json:"tokens_per_sec"`      // completion tokens over Duration
 }
 
+// Finish reasons reported on the terminal Token (OpenAI-compatible values).
+const (
+	FinishStop   = "stop"   // EOS or a stop sequence — a natural completion
+	FinishLength = "length" // hit the max token limit — output is truncated
+)
+
 // EmbeddingRequest holds parameters for an embedding request.
 type EmbeddingRequest struct {
 	Model string   `json:"model"`
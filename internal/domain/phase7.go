@@ -12,7 +12,12 @@
 // All types are pure — zero infrastructure imports.
 package domain
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
 
 // ═══════════════════════════════════════════════════════════════════════════
 // Section 1: Planetary-Scale Infrastructure Types
@@ -129,6 +134,23 @@ type ContinentLink struct {
 	Healthy   bool        `json:"healthy"`
 }
 
+// Validate checks the link on its own: both ends must be known continents,
+// distinct, and its latency and bandwidth must not be negative. Errors wrap
+// ErrInvalidContinentLink.
+func (l ContinentLink) Validate() error {
+	switch {
+	case !l.From.IsValid() || !l.To.IsValid():
+		return fmt.Errorf("%w: %q → %q: unknown continent", ErrInvalidContinentLink, l.From, l.To)
+	case l.From == l.To:
+		return fmt.Errorf("%w: %s links to itself", ErrInvalidContinentLink, l.From)
+	case l.LatencyMs < 0:
+		return fmt.Errorf("%w: %s → %s: negative latency %dms", ErrInvalidContinentLink, l.From, l.To, l.LatencyMs)
+	case l.Bandwidth < 0 || math.IsNaN(l.Bandwidth):
+		return fmt.Errorf("%w: %s → %s: invalid bandwidth %v Gbps", ErrInvalidContinentLink, l.From, l.To, l.Bandwidth)
+	}
+	return nil
+}
+
 // PlanetaryTopology is the full global view of the network.
 type PlanetaryTopology struct {
 	Continents   map[ContinentID]*ContinentMesh `json:"continents"`
@@ -151,6 +173,43 @@ func (pt PlanetaryTopology) IsQuorumHealthy() bool {
 	return healthy > total/2
 }
 
+// ValidateLinks checks every continent's links: each must pass Validate,
+// start at the continent that lists it, and be matched by a reverse link
+// B → A somewhere in the topology. Returns one error per problem, in
+// continent order; nil means the links are consistent.
+func (pt PlanetaryTopology) ValidateLinks() []error {
+	ids := make([]ContinentID, 0, len(pt.Continents))
+	for id := range pt.Continents {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	type pair struct{ from, to ContinentID }
+	linked := make(map[pair]bool)
+	for _, id := range ids {
+		for _, l := range pt.Continents[id].Links {
+			linked[pair{l.From, l.To}] = true
+		}
+	}
+
+	var errs []error
+	for _, id := range ids {
+		for _, l := range pt.Continents[id].Links {
+			if err := l.Validate(); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if l.From != id {
+				errs = append(errs, fmt.Errorf("%w: %s → %s is listed under %s", ErrInvalidContinentLink, l.From, l.To, id))
+			}
+			if !linked[pair{l.To, l.From}] {
+				errs = append(errs, fmt.Errorf("%w: %s → %s has no reverse link", ErrInvalidContinentLink, l.From, l.To))
+			}
+		}
+	}
+	return errs
+}
+
 // ModelDistributionStats tracks exabyte-scale model distribution.
 type ModelDistributionStats struct {
 	TotalModelsDistributed int64                   `json:"total_models_distributed"`
@@ -271,19 +330,62 @@ func (u TierUsage) IsExhausted(quota TierQuota) bool {
 	return u.InferencesToday >= quota.MaxInferencesPerDay
 }
 
+// ProrateQuota moves usage from one tier's quota to another's mid-day. The
+// day's allowance is blended by time: the old tier's limit covers the part
+// of the day already elapsed and the new tier's limit the part remaining
+// until ResetAt. What is left of that blended allowance after today's usage
+// — capped at the new tier's daily limit — becomes the remaining quota.
+//
+// Moving to an unlimited tier simply lifts the limit. Moving from an
+// unlimited tier grants the new tier's limit for the rest of the day,
+// since unlimited usage has no allowance to charge against.
+//
+// The returned usage carries the new tier; InferencesToday is rewritten so
+// that RemainingInferences(to) reports the prorated figure.
+func ProrateQuota(usage TierUsage, from, to TierQuota, now time.Time) TierUsage {
+	usage.Tier = to.Tier
+	if to.MaxInferencesPerDay < 0 || from == to {
+		return usage
+	}
+
+	dayLeft := 1.0
+	if !usage.ResetAt.IsZero() {
+		dayLeft = float64(usage.ResetAt.Sub(now)) / float64(24*time.Hour)
+		dayLeft = min(max(dayLeft, 0), 1)
+	}
+
+	restOfDay := float64(to.MaxInferencesPerDay) * dayLeft
+	var remaining int64
+	if from.MaxInferencesPerDay < 0 {
+		remaining = int64(restOfDay)
+	} else {
+		allowance := float64(from.MaxInferencesPerDay)*(1-dayLeft) + restOfDay
+		remaining = int64(allowance) - usage.InferencesToday
+	}
+	remaining = min(max(remaining, 0), to.MaxInferencesPerDay)
+
+	usage.InferencesToday = to.MaxInferencesPerDay - remaining
+	return usage
+}
+
 // EducationVerification represents a student/researcher verification request.
 type EducationVerification struct {
 	UserID      string    `json:"user_id"`
 	Institution string    `json:"institution"`
 	Email       string    `json:"email"`  // Must be .edu or recognized academic domain
-	Status      string    `json:"status"` // "pending", "verified", "rejected"
+	Status      string    `json:"status"` // "pending", "verified", "rejected", "expired"
 	VerifiedAt  time.Time `json:"verified_at,omitempty"`
 	ExpiresAt   time.Time `json:"expires_at,omitempty"` // Yearly re-verification
 }
 
 // IsVerified reports whether the education verification is currently active.
 func (ev EducationVerification) IsVerified() bool {
-	return ev.Status == "verified" && time.Now().Before(ev.ExpiresAt)
+	return ev.IsVerifiedAt(time.Now())
+}
+
+// IsVerifiedAt reports whether the education verification is active at now.
+func (ev EducationVerification) IsVerifiedAt(now time.Time) bool {
+	return ev.Status == "verified" && now.Before(ev.ExpiresAt)
 }
 
 // ═══════════════════════════════════════════════════════════════════════════
@@ -355,6 +457,8 @@ type FlywheelSnapshot struct {
 	Nodes       int64     `json:"nodes"`
 	Inferences  int64     `json:"inferences"`
 	Credits     int64     `json:"credits"`
+	Earned      int64     `json:"earned"` // CreditsEarnedToday at snapshot time
+	Spent       int64     `json:"spent"`  // CreditsSpentToday at snapshot time
 	Revenue     int64     `json:"revenue"`
 	HealthIndex float64   `json:"health_index"`
 }
@@ -434,7 +538,12 @@ type CouncilMember struct {
 
 // IsTermActive reports whether the council member's term is still valid.
 func (cm CouncilMember) IsTermActive() bool {
-	return time.Now().Before(cm.TermExpires)
+	return cm.IsTermActiveAt(time.Now())
+}
+
+// IsTermActiveAt reports whether the council member's term is valid at t.
+func (cm CouncilMember) IsTermActiveAt(t time.Time) bool {
+	return t.Before(cm.TermExpires)
 }
 
 // CouncilElection tracks a community council election.
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -53,6 +55,53 @@ func TestAllContinents_Count(t *testing.T) {
 	}
 }
 
+func TestDefaultRegionForContinent(t *testing.T) {
+	tests := []struct {
+		continent ContinentID
+		want      RegionID
+	}{
+		{ContinentEurope, RegionEUWest},
+		{ContinentAfrica, RegionEUWest},
+		{ContinentAsia, RegionAPSouth},
+		{ContinentOceania, RegionAPSouth},
+		{ContinentNorthAmerica, RegionUSEast},
+		{ContinentSouthAmerica, RegionUSEast},
+		{"", RegionUSEast},
+	}
+	for _, tt := range tests {
+		if got := DefaultRegionForContinent(tt.continent); got != tt.want {
+			t.Errorf("DefaultRegionForContinent(%q) = %q, want %q", tt.continent, got, tt.want)
+		}
+	}
+	for _, c := range AllContinents() {
+		if !DefaultRegionForContinent(c).IsValid() {
+			t.Errorf("DefaultRegionForContinent(%q) is not a valid region", c)
+		}
+	}
+}
+
+func TestDefaultRegionFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		country   string
+		continent ContinentID
+		want      RegionID
+	}{
+		{"EU country", "DE", "", RegionEUWest},
+		{"lower-case country", " fr ", "", RegionEUWest},
+		{"country beats continent", "JP", ContinentEurope, RegionAPSouth},
+		{"unknown country uses continent", "ZZ", ContinentEurope, RegionEUWest},
+		{"nothing detected", "", "", RegionUSEast},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultRegionFor(tt.country, tt.continent); got != tt.want {
+				t.Errorf("DefaultRegionFor(%q, %q) = %q, want %q", tt.country, tt.continent, got, tt.want)
+			}
+		})
+	}
+}
+
 // ─── PlanetaryRegion Tests ──────────────────────────────────────────────────
 
 func TestPlanetaryRegion_Load(t *testing.T) {
@@ -154,6 +203,87 @@ func TestPlanetaryTopology_IsQuorumHealthy(t *testing.T) {
 	}
 }
 
+// ─── ContinentLink Tests ────────────────────────────────────────────────────
+
+func TestContinentLink_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		link ContinentLink
+		want string // substring of the error; "" = valid
+	}{
+		{"valid", ContinentLink{From: ContinentEurope, To: ContinentAsia, LatencyMs: 120, Bandwidth: 40}, ""},
+		{"negative latency", ContinentLink{From: ContinentEurope, To: ContinentAsia, LatencyMs: -5}, "negative latency"},
+		{"negative bandwidth", ContinentLink{From: ContinentEurope, To: ContinentAsia, Bandwidth: -1}, "bandwidth"},
+		{"self-link", ContinentLink{From: ContinentEurope, To: ContinentEurope}, "itself"},
+		{"unknown continent", ContinentLink{From: ContinentEurope, To: "XX"}, "unknown continent"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.link.Validate()
+			if tc.want == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidContinentLink) || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Validate() = %v, want ErrInvalidContinentLink mentioning %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestPlanetaryTopology_ValidateLinks(t *testing.T) {
+	link := func(from, to ContinentID, latency int) ContinentLink {
+		return ContinentLink{From: from, To: to, LatencyMs: latency, Bandwidth: 10}
+	}
+	topo := func(links ...ContinentLink) PlanetaryTopology {
+		pt := PlanetaryTopology{Continents: map[ContinentID]*ContinentMesh{
+			ContinentNorthAmerica: {Continent: ContinentNorthAmerica},
+			ContinentEurope:       {Continent: ContinentEurope},
+			ContinentAsia:         {Continent: ContinentAsia},
+		}}
+		for _, l := range links {
+			if mesh, ok := pt.Continents[l.From]; ok {
+				mesh.Links = append(mesh.Links, l)
+			}
+		}
+		return pt
+	}
+
+	if errs := topo(link(ContinentNorthAmerica, ContinentEurope, 80), link(ContinentEurope, ContinentNorthAmerica, 80)).ValidateLinks(); len(errs) != 0 {
+		t.Errorf("symmetric topology: ValidateLinks() = %v, want none", errs)
+	}
+
+	tests := []struct {
+		name  string
+		links []ContinentLink
+		want  string
+	}{
+		{"negative latency", []ContinentLink{link(ContinentNorthAmerica, ContinentEurope, -80), link(ContinentEurope, ContinentNorthAmerica, 80)}, "negative latency"},
+		{"self-link", []ContinentLink{link(ContinentAsia, ContinentAsia, 0)}, "itself"},
+		{"missing reverse link", []ContinentLink{link(ContinentNorthAmerica, ContinentEurope, 80), link(ContinentEurope, ContinentNorthAmerica, 80), link(ContinentEurope, ContinentAsia, 150)}, "Europe → Asia has no reverse link"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := topo(tc.links...).ValidateLinks()
+			if len(errs) != 1 {
+				t.Fatalf("ValidateLinks() = %v, want exactly one error", errs)
+			}
+			if !errors.Is(errs[0], ErrInvalidContinentLink) || !strings.Contains(errs[0].Error(), tc.want) {
+				t.Errorf("ValidateLinks() = %v, want ErrInvalidContinentLink mentioning %q", errs[0], tc.want)
+			}
+		})
+	}
+
+	// A link listed under the wrong continent is reported too.
+	pt := topo(link(ContinentEurope, ContinentNorthAmerica, 80))
+	pt.Continents[ContinentAsia].Links = []ContinentLink{link(ContinentNorthAmerica, ContinentEurope, 80)}
+	if errs := pt.ValidateLinks(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "listed under Asia") {
+		t.Errorf("misplaced link: ValidateLinks() = %v, want one error naming Asia", errs)
+	}
+}
+
 // ─── Access Tier Tests ──────────────────────────────────────────────────────
 
 func TestAccessTier_IsValid(t *testing.T) {
@@ -250,6 +380,48 @@ func TestTierUsage_IsExhausted(t *testing.T) {
 	}
 }
 
+func TestProrateQuota(t *testing.T) {
+	quotas := DefaultTierQuotas()
+	free, pro, edu := quotas[AccessTierFree], quotas[AccessTierPro], quotas[AccessTierEducation]
+	reset := time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC)
+	noon := reset.Add(-12 * time.Hour)
+	evening := reset.Add(-6 * time.Hour)
+
+	tests := []struct {
+		name      string
+		used      int64
+		from, to  TierQuota
+		now       time.Time
+		remaining int64
+	}{
+		// Half a day of free (50) plus half a day of pro (5000), less 40 used.
+		{"free to pro at noon", 40, free, pro, noon, 5010},
+		{"free to pro, free exhausted", 100, free, pro, noon, 4950},
+		// 3/4 day of pro (7500) plus 1/4 day of free (25), less 7480 used.
+		{"pro to free, partial usage", 7480, pro, free, evening, 45},
+		{"pro to free, light usage capped at free limit", 200, pro, free, evening, 100},
+		{"pro to free, over blended allowance", 9000, pro, free, evening, 0},
+		{"free to education", 100, free, edu, noon, -1},
+		{"education to free", 5000, edu, free, evening, 25},
+		{"same tier", 30, pro, pro, noon, 9970},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			usage := TierUsage{UserID: "u", Tier: tc.from.Tier, InferencesToday: tc.used, TokensToday: 1234, ResetAt: reset}
+			got := ProrateQuota(usage, tc.from, tc.to, tc.now)
+			if got.Tier != tc.to.Tier {
+				t.Errorf("Tier = %q, want %q", got.Tier, tc.to.Tier)
+			}
+			if r := got.RemainingInferences(tc.to); r != tc.remaining {
+				t.Errorf("RemainingInferences() = %d, want %d", r, tc.remaining)
+			}
+			if got.TokensToday != 1234 || !got.ResetAt.Equal(reset) {
+				t.Errorf("tokens/reset changed: %+v", got)
+			}
+		})
+	}
+}
+
 func TestEducationVerification_IsVerified(t *testing.T) {
 	now := time.Now()
 
@@ -404,6 +576,9 @@ func TestCouncilMember_IsTermActive(t *testing.T) {
 	if expired.IsTermActive() {
 		t.Error("expected expired council member to not have active term")
 	}
+	if active.IsTermActiveAt(active.TermExpires) {
+		t.Error("term should end exactly at TermExpires")
+	}
 }
 
 func TestCouncilElection_TurnoutPct(t *testing.T) {
@@ -3,7 +3,10 @@
 // Architecture Part IX (Advanced Scheduling) + Part XXI (Multi-Region Deployment).
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // ─── Region Types ───────────────────────────────────────────────────────────
 
@@ -33,6 +36,61 @@ func (r RegionID) IsValid() bool {
 // String returns the region as a human-readable string.
 func (r RegionID) String() string { return string(r) }
 
+// ─── Default Region Assignment ──────────────────────────────────────────────
+// A node without a configured region joins the deployment region nearest its
+// detected geography; RegionUSEast is the last resort, not the default.
+
+// DefaultRegionForContinent returns the deployment region serving c.
+// Unknown continents (and Antarctica) get RegionUSEast.
+func DefaultRegionForContinent(c ContinentID) RegionID {
+	switch c {
+	case ContinentEurope, ContinentAfrica:
+		return RegionEUWest
+	case ContinentAsia, ContinentOceania:
+		return RegionAPSouth
+	default: // North and South America
+		return RegionUSEast
+	}
+}
+
+// ContinentForCountry returns the continent of an ISO 3166-1 alpha-2 code
+// (case-insensitive), or "" if the code is unknown.
+func ContinentForCountry(country string) ContinentID {
+	return countryContinents[strings.ToUpper(strings.TrimSpace(country))]
+}
+
+// DefaultRegionFor picks a node's default region from its detected country,
+// falling back to its detected continent when the country is unknown.
+func DefaultRegionFor(country string, continent ContinentID) RegionID {
+	if c := ContinentForCountry(country); c != "" {
+		continent = c
+	}
+	return DefaultRegionForContinent(continent)
+}
+
+// countryContinents maps ISO 3166-1 alpha-2 codes to continents. Russia and
+// Turkey are listed under their more populous side.
+var countryContinents = func() map[string]ContinentID {
+	byContinent := map[ContinentID]string{
+		ContinentNorthAmerica: "US CA MX GT BZ SV HN NI CR PA CU JM HT DO BS BB TT AG DM GD KN LC VC PR GL BM",
+		ContinentSouthAmerica: "BR AR CL CO PE VE EC BO PY UY GY SR GF FK",
+		ContinentEurope: "GB IE FR DE NL BE LU CH AT IT ES PT DK NO SE FI IS PL CZ SK HU SI HR BA RS ME MK AL " +
+			"GR BG RO MD UA BY LT LV EE RU MT CY AD MC SM VA LI XK",
+		ContinentAfrica: "ZA NG KE EG MA DZ TN LY ET GH CI SN CM UG TZ RW AO MZ ZM ZW BW NA MW MG SD SS SO DJ ER " +
+			"ML NE TD BF BJ TG GN GW SL LR MR GM CV CG CD GA GQ CF ST BI KM MU SC LS SZ",
+		ContinentAsia: "CN JP KR KP TW HK MO MN IN PK BD LK NP BT MV AF IR IQ SY LB JO IL PS SA AE QA BH KW OM " +
+			"YE TR GE AM AZ KZ UZ TM KG TJ TH VN MY SG ID PH MM KH LA BN TL",
+		ContinentOceania: "AU NZ PG FJ SB VU NC PF WS TO KI TV NR FM MH PW",
+	}
+	m := make(map[string]ContinentID)
+	for c, codes := range byContinent {
+		for _, code := range strings.Fields(codes) {
+			m[code] = c
+		}
+	}
+	return m
+}()
+
 // ─── Cross-Region Latency Map ───────────────────────────────────────────────
 // Known inter-region latencies in milliseconds.
 // Used by the scheduler to add latency penalties for cross-region routing.
@@ -117,3 +175,22 @@ func (tr TaskRouting) PreferredRegion() RegionID {
 func (tr TaskRouting) RequiresRegion() bool {
 	return tr.DataResidency != ""
 }
+
+// AllowsNode returns true if the node passes the whitelist (when set) and is
+// not blacklisted.
+func (tr TaskRouting) AllowsNode(nodeID string) bool {
+	for _, n := range tr.NodeBlacklist {
+		if n == nodeID {
+			return false
+		}
+	}
+	if len(tr.NodeWhitelist) == 0 {
+		return true
+	}
+	for _, n := range tr.NodeWhitelist {
+		if n == nodeID {
+			return true
+		}
+	}
+	return false
+}
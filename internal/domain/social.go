@@ -147,11 +147,11 @@ func EstimateOvernightEarnings(vramGB int, cpuCores int, demandMultiplier float6
 	baseCredits := float64(vramGB)*3.0 + float64(cpuCores)*0.5
 	estimated := baseCredits * demandMultiplier * 8.0 // 8 hours overnight
 
-	tier := "low"
+	tier := HardwareTierLow
 	if vramGB >= 16 {
-		tier = "high"
+		tier = HardwareTierHigh
 	} else if vramGB >= 8 {
-		tier = "mid"
+		tier = HardwareTierMid
 	}
 
 	demand := "normal"
@@ -39,6 +39,21 @@ type Task struct {
 	Credits     int64      `json:"credits,omitempty"`
 	ResultHash  string     `json:"result_hash,omitempty"`
 	Error       string     `json:"error,omitempty"`
+
+	// FederationID scopes the task to a private federation; empty = public.
+	FederationID string `json:"federation_id,omitempty"`
+
+	// SubmitterID is the node that submitted the task; empty = local.
+	SubmitterID string `json:"submitter_id,omitempty"`
+
+	// EstimatedCost and Deadline are scheduling hints used to order tasks
+	// of equal priority; zero means unknown.
+	EstimatedCost int64     `json:"estimated_cost,omitempty"`
+	Deadline      time.Time `json:"deadline,omitempty"`
+
+	// Requeues counts how often the scheduler put the task back in queue
+	// after a failed run.
+	Requeues int `json:"requeues,omitempty"`
 }
 
 // IsTerminal returns true if the task has reached a final state.
@@ -30,6 +30,7 @@
 package autoscale
 
 import (
+	"fmt"
 	"sync"
 	"time"
 )
@@ -58,11 +59,13 @@ type Config struct {
 	// E.g., 0.3 means "scale down when forecast is below 30% of capacity."
 	ScaleDownThreshold float64
 
-	// MinCapacity is the floor — never scale below this.
-	MinCapacity int
+	// MinNodes is the node floor — never scale below this, even when
+	// demand drops to zero. Keeps a safe minimum of warm nodes.
+	MinNodes int
 
-	// MaxCapacity is the ceiling — never scale above this.
-	MaxCapacity int
+	// MaxNodes is the node ceiling — never scale above this, however
+	// high the forecast. Keeps scaling within budget.
+	MaxNodes int
 
 	// PreWarmLeadTime is how far ahead to pre-warm nodes before a predicted spike.
 	PreWarmLeadTime time.Duration
@@ -82,8 +85,8 @@ func DefaultConfig() Config {
 		SeasonalAlpha:      0.1,
 		ScaleUpThreshold:   0.8,
 		ScaleDownThreshold: 0.3,
-		MinCapacity:        1,
-		MaxCapacity:        1000,
+		MinNodes:           1,
+		MaxNodes:           1000,
 		PreWarmLeadTime:    10 * time.Minute,
 		CooldownPeriod:     5 * time.Minute,
 		Now:                time.Now,
@@ -128,6 +131,12 @@ type Decision struct {
 	Reason          string    // human-readable explanation
 	DecidedAt       time.Time // when the decision was made
 	Proactive       bool      // true if decided BEFORE the spike (vs reactive)
+
+	// Clamping: set when the forecast-derived target fell outside
+	// [MinNodes, MaxNodes] and TargetCapacity was bounded.
+	Clamped         bool   // true if TargetCapacity differs from the raw recommendation
+	UnclampedTarget int    // raw recommendation before bounds were applied
+	ClampReason     string // which bound applied and why
 }
 
 // ─── Demand Sample ──────────────────────────────────────────────────────────
@@ -169,6 +178,9 @@ type Scaler struct {
 
 	// Observation count for confidence calculation.
 	observationCount int
+
+	// Decisions whose target was bounded by Min/MaxNodes.
+	clampedDecisions int64
 }
 
 // NewScaler creates a new predictive auto-scaler.
@@ -182,14 +194,14 @@ func NewScaler(cfg Config) *Scaler {
 	if cfg.SeasonalAlpha <= 0 || cfg.SeasonalAlpha > 1 {
 		cfg.SeasonalAlpha = 0.1
 	}
-	if cfg.MinCapacity <= 0 {
-		cfg.MinCapacity = 1
+	if cfg.MinNodes <= 0 {
+		cfg.MinNodes = 1
 	}
-	if cfg.MaxCapacity <= 0 {
-		cfg.MaxCapacity = 1000
+	if cfg.MaxNodes <= 0 {
+		cfg.MaxNodes = 1000
 	}
-	if cfg.MaxCapacity < cfg.MinCapacity {
-		cfg.MaxCapacity = cfg.MinCapacity
+	if cfg.MaxNodes < cfg.MinNodes {
+		cfg.MaxNodes = cfg.MinNodes
 	}
 	if cfg.PreWarmLeadTime <= 0 {
 		cfg.PreWarmLeadTime = 10 * time.Minute
@@ -209,7 +221,7 @@ func NewScaler(cfg Config) *Scaler {
 	return &Scaler{
 		cfg:          cfg,
 		seasonal:     seasonal,
-		capacity:     cfg.MinCapacity,
+		capacity:     cfg.MinNodes,
 		maxDecisions: 10_000,
 		decisions:    make([]Decision, 10_000),
 	}
@@ -339,7 +351,12 @@ func (s *Scaler) Evaluate() Decision {
 
 	// Check if pre-warm is needed: forecast shows upcoming spike.
 	if forecastAhead > capFloat*s.cfg.ScaleUpThreshold && forecast <= capFloat*s.cfg.ScaleUpThreshold {
-		target := s.clampCapacity(int(forecastAhead/s.cfg.ScaleUpThreshold) + 1)
+		want := int(forecastAhead/s.cfg.ScaleUpThreshold) + 1
+		target := s.clampNodes(want)
+		if target <= s.capacity {
+			return s.holdAtBoundLocked(decision, want)
+		}
+		s.markClampedLocked(&decision, want, target)
 		decision.Direction = PreWarm
 		decision.TargetCapacity = target
 		decision.Proactive = true
@@ -354,7 +371,12 @@ func (s *Scaler) Evaluate() Decision {
 
 	// Scale up: current demand exceeds threshold.
 	if forecast > capFloat*s.cfg.ScaleUpThreshold {
-		target := s.clampCapacity(int(forecast/s.cfg.ScaleUpThreshold) + 1)
+		want := int(forecast/s.cfg.ScaleUpThreshold) + 1
+		target := s.clampNodes(want)
+		if target <= s.capacity {
+			return s.holdAtBoundLocked(decision, want)
+		}
+		s.markClampedLocked(&decision, want, target)
 		decision.Direction = ScaleUp
 		decision.TargetCapacity = target
 		decision.Proactive = false // reactive — spike already here
@@ -367,9 +389,10 @@ func (s *Scaler) Evaluate() Decision {
 	}
 
 	// Scale down: demand well below capacity.
-	if forecast < capFloat*s.cfg.ScaleDownThreshold && s.capacity > s.cfg.MinCapacity {
-		target := s.clampCapacity(int(forecast/s.cfg.ScaleDownThreshold) + 1)
-		if target < s.capacity {
+	if forecast < capFloat*s.cfg.ScaleDownThreshold && s.capacity > s.cfg.MinNodes {
+		want := int(forecast/s.cfg.ScaleDownThreshold) + 1
+		if target := s.clampNodes(want); target < s.capacity {
+			s.markClampedLocked(&decision, want, target)
 			decision.Direction = ScaleDown
 			decision.TargetCapacity = target
 			decision.Reason = "demand below threshold — scaling down"
@@ -394,17 +417,43 @@ func (s *Scaler) forecastLocked(at time.Time) float64 {
 	return s.smoothed * s.seasonal[bucket]
 }
 
-// clampCapacity keeps capacity within configured bounds.
-func (s *Scaler) clampCapacity(target int) int {
-	if target < s.cfg.MinCapacity {
-		return s.cfg.MinCapacity
+// clampNodes keeps a node count within [MinNodes, MaxNodes].
+func (s *Scaler) clampNodes(target int) int {
+	if target < s.cfg.MinNodes {
+		return s.cfg.MinNodes
 	}
-	if target > s.cfg.MaxCapacity {
-		return s.cfg.MaxCapacity
+	if target > s.cfg.MaxNodes {
+		return s.cfg.MaxNodes
 	}
 	return target
 }
 
+// markClampedLocked records on a scaling decision that its target was
+// bounded: want is the raw recommendation and target the one applied. A
+// target within bounds leaves the decision unmarked.
+func (s *Scaler) markClampedLocked(d *Decision, want, target int) {
+	if target == want {
+		return
+	}
+	d.Clamped = true
+	d.UnclampedTarget = want
+	if want < s.cfg.MinNodes {
+		d.ClampReason = fmt.Sprintf("recommended %d nodes, raised to MinNodes %d (safe floor)", want, target)
+	} else {
+		d.ClampReason = fmt.Sprintf("recommended %d nodes, capped at MaxNodes %d (budget ceiling)", want, target)
+	}
+	s.clampedDecisions++
+}
+
+// holdAtBoundLocked records a hold for a scale-up that the MaxNodes ceiling
+// leaves with nothing to do. The target never changed, so it is not marked
+// clamped.
+func (s *Scaler) holdAtBoundLocked(d Decision, want int) Decision {
+	d.Reason = fmt.Sprintf("demand calls for %d nodes but capacity is at MaxNodes %d — holding", want, s.cfg.MaxNodes)
+	s.recordDecisionLocked(d)
+	return d
+}
+
 // recordDecisionLocked appends a decision to the ring buffer.
 func (s *Scaler) recordDecisionLocked(d Decision) {
 	s.decisions[s.dIdx] = d
@@ -435,7 +484,7 @@ func (s *Scaler) RecordSpike(proactive bool) {
 func (s *Scaler) SetCapacity(n int) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.capacity = s.clampCapacity(n)
+	s.capacity = s.clampNodes(n)
 }
 
 // Capacity returns the current capacity.
@@ -449,15 +498,16 @@ func (s *Scaler) Capacity() int {
 
 // ScalerStats exposes auto-scaler metrics.
 type ScalerStats struct {
-	SmoothedLevel   float64   // current smoothed demand level
-	SeasonalIndices []float64 // one per season bucket
-	CurrentCapacity int       // current capacity
-	Observations    int       // total observations recorded
-	TotalDecisions  int       // total decisions made
-	TotalSpikes     int64     // total spikes observed
-	ProactiveSpikes int64     // spikes handled proactively
-	ProactivePct    float64   // proactive / total × 100
-	Confidence      float64   // data maturity confidence 0..1
+	SmoothedLevel    float64   // current smoothed demand level
+	SeasonalIndices  []float64 // one per season bucket
+	CurrentCapacity  int       // current capacity
+	Observations     int       // total observations recorded
+	TotalDecisions   int       // total decisions made
+	TotalSpikes      int64     // total spikes observed
+	ProactiveSpikes  int64     // spikes handled proactively
+	ProactivePct     float64   // proactive / total × 100
+	Confidence       float64   // data maturity confidence 0..1
+	ClampedDecisions int64     // decisions bounded by Min/MaxNodes
 }
 
 // Stats returns current scaler statistics.
@@ -486,15 +536,16 @@ func (s *Scaler) Stats() ScalerStats {
 	}
 
 	return ScalerStats{
-		SmoothedLevel:   s.smoothed,
-		SeasonalIndices: indices,
-		CurrentCapacity: s.capacity,
-		Observations:    s.observationCount,
-		TotalDecisions:  totalDecisions,
-		TotalSpikes:     s.totalSpikes,
-		ProactiveSpikes: s.proactiveSpikes,
-		ProactivePct:    proactivePct,
-		Confidence:      confidence,
+		SmoothedLevel:    s.smoothed,
+		SeasonalIndices:  indices,
+		CurrentCapacity:  s.capacity,
+		Observations:     s.observationCount,
+		TotalDecisions:   totalDecisions,
+		TotalSpikes:      s.totalSpikes,
+		ProactiveSpikes:  s.proactiveSpikes,
+		ProactivePct:     proactivePct,
+		Confidence:       confidence,
+		ClampedDecisions: s.clampedDecisions,
 	}
 }
 
@@ -589,11 +640,12 @@ func (s *Scaler) Reset() {
 	for i := range s.seasonal {
 		s.seasonal[i] = 1.0
 	}
-	s.capacity = s.cfg.MinCapacity
+	s.capacity = s.cfg.MinNodes
 	s.lastDecision = time.Time{}
 	s.decisions = make([]Decision, s.maxDecisions)
 	s.dIdx = 0
 	s.dFull = false
 	s.totalSpikes = 0
 	s.proactiveSpikes = 0
+	s.clampedDecisions = 0
 }
@@ -2,6 +2,7 @@ package autoscale
 
 import (
 	"math"
+	"strings"
 	"testing"
 	"time"
 )
@@ -25,7 +26,7 @@ func TestNewScaler_DefaultConfig(t *testing.T) {
 		t.Fatal("NewScaler returned nil")
 	}
 	if s.Capacity() != 1 {
-		t.Errorf("initial capacity = %d, want 1 (MinCapacity)", s.Capacity())
+		t.Errorf("initial capacity = %d, want 1 (MinNodes)", s.Capacity())
 	}
 	if len(s.seasonal) != 24 {
 		t.Errorf("seasonal buckets = %d, want 24", len(s.seasonal))
@@ -37,8 +38,8 @@ func TestNewScaler_InvalidConfig(t *testing.T) {
 		Alpha:          -1,
 		SeasonalPeriod: -1,
 		SeasonalAlpha:  0,
-		MinCapacity:    -5,
-		MaxCapacity:    -1,
+		MinNodes:       -5,
+		MaxNodes:       -1,
 	}
 	s := NewScaler(cfg)
 	if s.cfg.Alpha != 0.3 {
@@ -110,8 +111,8 @@ func TestEvaluate_ScaleUp(t *testing.T) {
 	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	cfg := DefaultConfig()
 	cfg.ScaleUpThreshold = 0.8
-	cfg.MinCapacity = 1
-	cfg.MaxCapacity = 100
+	cfg.MinNodes = 1
+	cfg.MaxNodes = 100
 	cfg.CooldownPeriod = 0 // no cooldown for test
 	cfg.Now = fixedClock(base, time.Minute)
 	s := NewScaler(cfg)
@@ -136,8 +137,8 @@ func TestEvaluate_ScaleDown(t *testing.T) {
 	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	cfg := DefaultConfig()
 	cfg.ScaleDownThreshold = 0.3
-	cfg.MinCapacity = 1
-	cfg.MaxCapacity = 100
+	cfg.MinNodes = 1
+	cfg.MaxNodes = 100
 	cfg.CooldownPeriod = 0
 	cfg.PreWarmLeadTime = time.Millisecond // minimize pre-warm influence
 	cfg.Now = fixedClock(base, time.Minute)
@@ -156,13 +157,120 @@ func TestEvaluate_ScaleDown(t *testing.T) {
 	}
 }
 
+func TestEvaluate_ScaleDownClampedToMin(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := DefaultConfig()
+	cfg.ScaleDownThreshold = 0.3
+	cfg.MinNodes = 5
+	cfg.MaxNodes = 100
+	cfg.CooldownPeriod = 0
+	cfg.PreWarmLeadTime = time.Millisecond
+	cfg.Now = fixedClock(base, time.Minute)
+	s := NewScaler(cfg)
+	s.SetCapacity(50)
+
+	// Near-zero demand would recommend ~1 node.
+	for i := 0; i < 10; i++ {
+		s.RecordDemand(Sample{Demand: 0.1, Timestamp: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	d := s.Evaluate()
+	if d.Direction != ScaleDown {
+		t.Fatalf("expected ScaleDown, got %s", d.Direction)
+	}
+	if d.TargetCapacity != 5 {
+		t.Errorf("target = %d, want MinNodes 5", d.TargetCapacity)
+	}
+	if !d.Clamped || d.UnclampedTarget >= 5 {
+		t.Errorf("expected clamp from below 5, got clamped=%v unclamped=%d", d.Clamped, d.UnclampedTarget)
+	}
+	if !strings.Contains(d.ClampReason, "MinNodes") {
+		t.Errorf("clamp reason should name MinNodes, got %q", d.ClampReason)
+	}
+	if s.Stats().ClampedDecisions != 1 {
+		t.Errorf("ClampedDecisions = %d, want 1", s.Stats().ClampedDecisions)
+	}
+}
+
+func TestEvaluate_ScaleUpClampedToMax(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := DefaultConfig()
+	cfg.ScaleUpThreshold = 0.8
+	cfg.MinNodes = 1
+	cfg.MaxNodes = 20
+	cfg.CooldownPeriod = 0
+	cfg.Now = fixedClock(base, time.Minute)
+	s := NewScaler(cfg)
+	s.SetCapacity(5)
+
+	// Demand ~500 would recommend ~626 nodes.
+	for i := 0; i < 10; i++ {
+		s.RecordDemand(Sample{Demand: 500, Timestamp: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	d := s.Evaluate()
+	if d.Direction != ScaleUp && d.Direction != PreWarm {
+		t.Fatalf("expected ScaleUp or PreWarm, got %s", d.Direction)
+	}
+	if d.TargetCapacity != 20 {
+		t.Errorf("target = %d, want MaxNodes 20", d.TargetCapacity)
+	}
+	if !d.Clamped || d.UnclampedTarget <= 20 {
+		t.Errorf("expected clamp from above 20, got clamped=%v unclamped=%d", d.Clamped, d.UnclampedTarget)
+	}
+	if !strings.Contains(d.ClampReason, "MaxNodes") {
+		t.Errorf("clamp reason should name MaxNodes, got %q", d.ClampReason)
+	}
+}
+
+func TestEvaluate_AtMaxHoldsUnclamped(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := DefaultConfig()
+	cfg.MaxNodes = 20
+	cfg.CooldownPeriod = 0
+	cfg.Now = fixedClock(base, time.Minute)
+	s := NewScaler(cfg)
+	s.SetCapacity(20)
+
+	for i := 0; i < 10; i++ {
+		s.RecordDemand(Sample{Demand: 500, Timestamp: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	d := s.Evaluate()
+	if d.Direction != Hold || d.TargetCapacity != 20 {
+		t.Errorf("at MaxNodes: got %s to %d, want Hold at 20", d.Direction, d.TargetCapacity)
+	}
+	if d.Clamped || s.Stats().ClampedDecisions != 0 {
+		t.Errorf("a hold that changed nothing should not count as clamped: %+v", d)
+	}
+}
+
+func TestEvaluate_WithinBoundsNotClamped(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := DefaultConfig()
+	cfg.MaxNodes = 1000
+	cfg.CooldownPeriod = 0
+	cfg.Now = fixedClock(base, time.Minute)
+	s := NewScaler(cfg)
+	s.SetCapacity(5)
+
+	for i := 0; i < 10; i++ {
+		s.RecordDemand(Sample{Demand: 50, Timestamp: base.Add(time.Duration(i) * time.Minute)})
+	}
+
+	d := s.Evaluate()
+	if d.Clamped || d.ClampReason != "" {
+		t.Errorf("unexpected clamp: %+v", d)
+	}
+}
+
 func TestEvaluate_Hold(t *testing.T) {
 	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	cfg := DefaultConfig()
 	cfg.ScaleUpThreshold = 0.8
 	cfg.ScaleDownThreshold = 0.3
-	cfg.MinCapacity = 1
-	cfg.MaxCapacity = 100
+	cfg.MinNodes = 1
+	cfg.MaxNodes = 100
 	cfg.CooldownPeriod = 0
 	cfg.PreWarmLeadTime = time.Millisecond
 	cfg.Now = fixedClock(base, time.Minute)
@@ -290,8 +398,8 @@ func TestRecentDecisions(t *testing.T) {
 
 func TestSetCapacity_Clamped(t *testing.T) {
 	cfg := DefaultConfig()
-	cfg.MinCapacity = 5
-	cfg.MaxCapacity = 50
+	cfg.MinNodes = 5
+	cfg.MaxNodes = 50
 	s := NewScaler(cfg)
 
 	s.SetCapacity(3) // below min
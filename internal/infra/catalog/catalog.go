@@ -18,6 +18,7 @@ type ModelEntry struct {
 	Tags         []string // Searchable tags: ["llama3", "llama3:latest", "llama3:8b"]
 	ContextSize  int      // Default context window
 	ChatTemplate string   // Chat template style: "llama3", "chatml", "phi3"
+	SHA256       string   // Expected hex digest of HFFile; empty skips verification
 }
 
 // Catalog is the built-in list of downloadable models.
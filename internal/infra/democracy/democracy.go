@@ -8,6 +8,8 @@
 //   - Community council: elected representatives per continent (6-month terms)
 //   - Open-source compliance: automated checks ensure code stays MIT licensed
 //   - No single point of control: network operates without any single entity
+//   - Emergency brake: geographically-diverse council members can jointly apply
+//     a temporary security-param change that auto-reverts unless ratified
 //
 // This is what makes TuTu a true public good — not controlled by any company.
 //
@@ -15,7 +17,9 @@
 package democracy
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"sort"
 	"sync"
 	"time"
@@ -43,6 +47,14 @@ type Config struct {
 
 	// ComplianceCheckInterval: how often to run open-source compliance checks.
 	ComplianceCheckInterval time.Duration
+
+	// EmergencyMinContinents: council members from at least this many
+	// distinct continents must sign an emergency change (default: 3).
+	EmergencyMinContinents int
+
+	// EmergencyWindow: how long an emergency change stays in effect before
+	// auto-reverting unless ratified by a normal vote (default: 72h).
+	EmergencyWindow time.Duration
 }
 
 // DefaultConfig returns sensible defaults for the democracy engine.
@@ -53,6 +65,8 @@ func DefaultConfig() Config {
 		ElectionDurationDays:    14,   // 2 weeks
 		ParameterChangeQuorum:   30.0, // 30% of credit weight
 		ComplianceCheckInterval: 24 * time.Hour,
+		EmergencyMinContinents:  3,
+		EmergencyWindow:         72 * time.Hour,
 	}
 }
 
@@ -68,15 +82,33 @@ type Engine struct {
 	// Governable parameters registry
 	params map[string]*domain.GovernableParam
 
+	// Value validators by param key (see SetParamValidator)
+	validators map[string]ParamValidator
+
+	// Param change subscribers (see OnParamChange)
+	listeners []ParamChangeFunc
+
 	// Council members per continent
 	council map[domain.ContinentID]*domain.CouncilMember
 
 	// Active elections
 	elections map[string]*domain.CouncilElection
 
+	// Identified ballots: electionID → voter nodeIDs (see CastVoteAs)
+	ballots map[string]map[string]bool
+
+	// Phase 5 governance activity, for participation metrics
+	governance GovernanceActivity
+
 	// Open-source compliance state
 	compliance domain.OpenSourceCompliance
 
+	// Pending emergency changes by param key (awaiting ratification or revert)
+	emergencies map[string]*EmergencyAction
+
+	// Append-only audit trail of parameter changes
+	audit []AuditEntry
+
 	// Injectable clock
 	now func() time.Time
 }
@@ -88,7 +120,17 @@ func NewEngine(cfg Config) *Engine {
 		params:    make(map[string]*domain.GovernableParam),
 		council:   make(map[domain.ContinentID]*domain.CouncilMember),
 		elections: make(map[string]*domain.CouncilElection),
+		ballots:   make(map[string]map[string]bool),
 		now:       time.Now,
+
+		emergencies: make(map[string]*EmergencyAction),
+		validators:  make(map[string]ParamValidator),
+	}
+	if e.config.EmergencyMinContinents <= 0 {
+		e.config.EmergencyMinContinents = 3
+	}
+	if e.config.EmergencyWindow <= 0 {
+		e.config.EmergencyWindow = 72 * time.Hour
 	}
 
 	// Register default governable parameters
@@ -115,6 +157,27 @@ func (e *Engine) RegisterParam(param domain.GovernableParam) error {
 	return nil
 }
 
+// ParamValidator checks a proposed value for a governable parameter.
+type ParamValidator func(value string) error
+
+// SetParamValidator installs a validator that ChangeParam and EmergencyChange
+// run before accepting a new value for key.
+func (e *Engine) SetParamValidator(key string, v ParamValidator) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.validators[key] = v
+}
+
+// validateLocked runs key's validator, if any (caller must hold lock).
+func (e *Engine) validateLocked(key, value string) error {
+	if v, ok := e.validators[key]; ok {
+		if err := v(value); err != nil {
+			return fmt.Errorf("parameter %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
 // GetParam returns a governable parameter by key.
 func (e *Engine) GetParam(key string) (domain.GovernableParam, error) {
 	e.mu.RLock()
@@ -165,28 +228,83 @@ func (e *Engine) ListParamsByCategory(cat domain.ParamCategory) []domain.Governa
 // This validates the protection level and records who changed it.
 func (e *Engine) ChangeParam(key, newValue, proposalID string, votePercentage float64) error {
 	e.mu.Lock()
-	defer e.mu.Unlock()
+	old, err := e.changeParamLocked(key, newValue, proposalID, votePercentage)
+	e.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	e.notifyParamChange(paramChange{key, old, newValue})
+	return nil
+}
 
+// changeParamLocked applies a voted change and returns the previous value
+// (caller must hold lock).
+func (e *Engine) changeParamLocked(key, newValue, proposalID string, votePercentage float64) (string, error) {
 	p, ok := e.params[key]
 	if !ok {
-		return fmt.Errorf("parameter %q not found", key)
+		return "", fmt.Errorf("parameter %q not found", key)
 	}
 
 	// Check protection level
 	if p.Protection == domain.ProtectionImmutable {
-		return domain.ErrParameterProtected
+		return "", domain.ErrParameterProtected
 	}
 
 	requiredMajority := p.Protection.RequiredMajority()
 	if votePercentage < requiredMajority {
-		return domain.ErrDemocracyQuorumFailed
+		return "", domain.ErrDemocracyQuorumFailed
+	}
+	if err := e.validateLocked(key, newValue); err != nil {
+		return "", err
 	}
 
+	old := p.CurrentValue
 	p.CurrentValue = newValue
 	p.LastChanged = e.now()
 	p.ChangedBy = proposalID
 
-	return nil
+	// A normal vote settles the value — any pending emergency no longer reverts.
+	delete(e.emergencies, key)
+
+	e.auditLocked(AuditParamChange, key, old, newValue, proposalID, "")
+	return old, nil
+}
+
+// ParamChangeFunc is notified after a parameter's value changes.
+type ParamChangeFunc func(key, oldValue, newValue string)
+
+// OnParamChange subscribes fn to every value change made by ChangeParam,
+// EmergencyChange, or RevertExpiredEmergencies, so dependents can
+// reconfigure without polling. fn runs synchronously after the engine lock
+// is released, so it may call back into the Engine.
+func (e *Engine) OnParamChange(fn ParamChangeFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.listeners = append(e.listeners, fn)
+}
+
+// paramChange is a value change awaiting notification.
+type paramChange struct {
+	key, oldValue, newValue string
+}
+
+// notifyParamChange delivers changes to a snapshot of the subscribers.
+// Must be called without the lock held. Changes that leave the value as it
+// was are skipped.
+func (e *Engine) notifyParamChange(changes ...paramChange) {
+	e.mu.RLock()
+	listeners := append([]ParamChangeFunc(nil), e.listeners...)
+	e.mu.RUnlock()
+
+	for _, c := range changes {
+		if c.oldValue == c.newValue {
+			continue
+		}
+		for _, fn := range listeners {
+			fn(c.key, c.oldValue, c.newValue)
+		}
+	}
 }
 
 // ParamCount returns the total number of registered parameters.
@@ -196,6 +314,236 @@ func (e *Engine) ParamCount() int {
 	return len(e.params)
 }
 
+// ═══════════════════════════════════════════════════════════════════════════
+// Emergency Brake
+// ═══════════════════════════════════════════════════════════════════════════
+
+// EmergencyAction is a temporary security-param change awaiting ratification.
+type EmergencyAction struct {
+	Key           string    `json:"key"`
+	PreviousValue string    `json:"previous_value"`
+	NewValue      string    `json:"new_value"`
+	Signers       []string  `json:"signers"` // council member node IDs
+	InvokedAt     time.Time `json:"invoked_at"`
+	RevertsAt     time.Time `json:"reverts_at"`
+}
+
+// EmergencyChange applies an immediate change to a security parameter,
+// bypassing the election cycle. signatures are node IDs of active council
+// members; they must span at least EmergencyMinContinents continents.
+// The change auto-reverts after EmergencyWindow unless ratified via
+// RatifyEmergency (or superseded by ChangeParam).
+func (e *Engine) EmergencyChange(key, newValue string, signatures []string) error {
+	e.mu.Lock()
+	old, err := e.emergencyChangeLocked(key, newValue, signatures)
+	e.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	e.notifyParamChange(paramChange{key, old, newValue})
+	return nil
+}
+
+// emergencyChangeLocked applies an emergency change and returns the previous
+// value (caller must hold lock).
+func (e *Engine) emergencyChangeLocked(key, newValue string, signatures []string) (string, error) {
+	p, ok := e.params[key]
+	if !ok {
+		return "", fmt.Errorf("parameter %q not found", key)
+	}
+	if p.Protection == domain.ProtectionImmutable {
+		return "", domain.ErrParameterProtected
+	}
+	if p.Category != domain.ParamCategorySecurity {
+		return "", fmt.Errorf("emergency changes are limited to security parameters, %q is %s", key, p.Category)
+	}
+	if _, pending := e.emergencies[key]; pending {
+		return "", fmt.Errorf("emergency change already pending for %q", key)
+	}
+	if err := e.validateLocked(key, newValue); err != nil {
+		return "", err
+	}
+
+	// Count distinct continents among valid, active council signers.
+	now := e.now()
+	continents := make(map[domain.ContinentID]bool)
+	var signers []string
+	for _, sig := range signatures {
+		for c, m := range e.council {
+			if m.NodeID == sig && now.Before(m.TermExpires) && !continents[c] {
+				continents[c] = true
+				signers = append(signers, sig)
+			}
+		}
+	}
+	if len(continents) < e.config.EmergencyMinContinents {
+		return "", domain.ErrEmergencySignatures
+	}
+
+	action := &EmergencyAction{
+		Key:           key,
+		PreviousValue: p.CurrentValue,
+		NewValue:      newValue,
+		Signers:       signers,
+		InvokedAt:     now,
+		RevertsAt:     now.Add(e.config.EmergencyWindow),
+	}
+	e.emergencies[key] = action
+
+	p.CurrentValue = newValue
+	p.LastChanged = now
+	p.ChangedBy = "emergency"
+
+	e.auditLocked(AuditEmergencyChange, key, action.PreviousValue, newValue, "emergency",
+		fmt.Sprintf("EMERGENCY BRAKE — signed by %v, auto-reverts at %s unless ratified",
+			signers, action.RevertsAt.Format(time.RFC3339)))
+	return action.PreviousValue, nil
+}
+
+// RatifyEmergency makes a pending emergency change permanent via a normal
+// vote. votePercentage must meet the parameter's protection threshold.
+func (e *Engine) RatifyEmergency(key, proposalID string, votePercentage float64) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	action, ok := e.emergencies[key]
+	if !ok {
+		return fmt.Errorf("no pending emergency change for %q", key)
+	}
+	p := e.params[key]
+	if votePercentage < p.Protection.RequiredMajority() {
+		return domain.ErrDemocracyQuorumFailed
+	}
+
+	delete(e.emergencies, key)
+	p.ChangedBy = proposalID
+	e.auditLocked(AuditEmergencyRatified, key, action.PreviousValue, action.NewValue, proposalID, "")
+	return nil
+}
+
+// RevertExpiredEmergencies restores the previous value of every emergency
+// change whose window has elapsed without ratification. Call periodically.
+// Returns the keys that were reverted.
+func (e *Engine) RevertExpiredEmergencies() []string {
+	e.mu.Lock()
+	now := e.now()
+	var reverted []string
+	var changes []paramChange
+	for key, action := range e.emergencies {
+		if now.Before(action.RevertsAt) {
+			continue
+		}
+		if p, ok := e.params[key]; ok {
+			changes = append(changes, paramChange{key, p.CurrentValue, action.PreviousValue})
+			p.CurrentValue = action.PreviousValue
+			p.LastChanged = now
+			p.ChangedBy = "emergency-revert"
+		}
+		delete(e.emergencies, key)
+		e.auditLocked(AuditEmergencyRevert, key, action.NewValue, action.PreviousValue, "emergency-revert",
+			"emergency window elapsed without ratification")
+		reverted = append(reverted, key)
+	}
+	e.mu.Unlock()
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].key < changes[j].key })
+	e.notifyParamChange(changes...)
+	sort.Strings(reverted)
+	return reverted
+}
+
+// SweepInterval is how often Run looks for lapsed emergency changes and
+// council terms.
+const SweepInterval = time.Minute
+
+// Run reverts emergency changes whose window has elapsed and vacates the
+// seats of council members whose terms have ended, every interval until
+// ctx is done. Call in a goroutine.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.sweep()
+		}
+	}
+}
+
+// sweep runs one RevertExpiredEmergencies and ExpireTerms pass.
+func (e *Engine) sweep() {
+	for _, key := range e.RevertExpiredEmergencies() {
+		log.Printf("[democracy] emergency change to %s expired unratified; reverted", key)
+	}
+	for _, c := range e.ExpireTerms(time.Time{}) {
+		log.Printf("[democracy] council term for %s ended; seat vacant until an election is certified", c)
+	}
+}
+
+// PendingEmergencies returns all emergency changes awaiting ratification.
+func (e *Engine) PendingEmergencies() []EmergencyAction {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	result := make([]EmergencyAction, 0, len(e.emergencies))
+	for _, a := range e.emergencies {
+		result = append(result, *a)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Audit Trail
+// ═══════════════════════════════════════════════════════════════════════════
+
+// AuditAction identifies the kind of audited parameter change.
+type AuditAction string
+
+const (
+	AuditParamChange       AuditAction = "param_change"
+	AuditEmergencyChange   AuditAction = "EMERGENCY_CHANGE"
+	AuditEmergencyRatified AuditAction = "emergency_ratified"
+	AuditEmergencyRevert   AuditAction = "emergency_revert"
+)
+
+// AuditEntry is one record in the democracy audit trail.
+type AuditEntry struct {
+	At       time.Time   `json:"at"`
+	Action   AuditAction `json:"action"`
+	Key      string      `json:"key"`
+	OldValue string      `json:"old_value"`
+	NewValue string      `json:"new_value"`
+	Actor    string      `json:"actor"` // proposal ID or "emergency"
+	Detail   string      `json:"detail,omitempty"`
+}
+
+// AuditLog returns the full audit trail, oldest first.
+func (e *Engine) AuditLog() []AuditEntry {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]AuditEntry(nil), e.audit...)
+}
+
+// auditLocked appends an audit entry. Caller must hold e.mu.
+func (e *Engine) auditLocked(action AuditAction, key, oldValue, newValue, actor, detail string) {
+	e.audit = append(e.audit, AuditEntry{
+		At:       e.now(),
+		Action:   action,
+		Key:      key,
+		OldValue: oldValue,
+		NewValue: newValue,
+		Actor:    actor,
+		Detail:   detail,
+	})
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // Council Elections
 // ═══════════════════════════════════════════════════════════════════════════
@@ -262,11 +610,29 @@ func (e *Engine) AddCandidate(electionID, nodeID, platform string) error {
 	return nil
 }
 
-// CastVote records a vote for a candidate in an election.
+// CastVote records an anonymous vote for a candidate in an election.
 func (e *Engine) CastVote(electionID, candidateNodeID string) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	return e.castVoteLocked(electionID, "", candidateNodeID)
+}
 
+// CastVoteAs records voterNodeID's vote for a candidate. Each voter may
+// vote once per election; identified ballots count toward the voter's
+// ParticipationStats.
+func (e *Engine) CastVoteAs(electionID, voterNodeID, candidateNodeID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.ballots[electionID][voterNodeID] {
+		return domain.ErrAlreadyVoted
+	}
+	return e.castVoteLocked(electionID, voterNodeID, candidateNodeID)
+}
+
+// castVoteLocked tallies a vote. An empty voterNodeID is anonymous.
+// Caller must hold e.mu.
+func (e *Engine) castVoteLocked(electionID, voterNodeID, candidateNodeID string) error {
 	el, ok := e.elections[electionID]
 	if !ok {
 		return fmt.Errorf("election %q not found", electionID)
@@ -287,6 +653,12 @@ func (e *Engine) CastVote(electionID, candidateNodeID string) error {
 		if el.Candidates[i].NodeID == candidateNodeID {
 			el.Candidates[i].VotesFor++
 			el.TotalVotes++
+			if voterNodeID != "" {
+				if e.ballots[electionID] == nil {
+					e.ballots[electionID] = make(map[string]bool)
+				}
+				e.ballots[electionID][voterNodeID] = true
+			}
 			return nil
 		}
 	}
@@ -351,6 +723,21 @@ func (e *Engine) GetCouncil() []domain.CouncilMember {
 	return result
 }
 
+// IsCouncilMember reports whether nodeID holds a council seat with an
+// active term.
+func (e *Engine) IsCouncilMember(nodeID string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	now := e.now()
+	for _, m := range e.council {
+		if m.NodeID == nodeID && now.Before(m.TermExpires) {
+			return true
+		}
+	}
+	return false
+}
+
 // ActiveCouncilCount returns how many council members have active terms.
 func (e *Engine) ActiveCouncilCount() int {
 	e.mu.RLock()
@@ -366,6 +753,27 @@ func (e *Engine) ActiveCouncilCount() int {
 	return count
 }
 
+// ExpireTerms removes council members whose terms have ended by now and
+// returns their continents, sorted, so the caller can open elections for the
+// vacant seats. A zero now uses the engine clock.
+func (e *Engine) ExpireTerms(now time.Time) []domain.ContinentID {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if now.IsZero() {
+		now = e.now()
+	}
+	var vacant []domain.ContinentID
+	for c, m := range e.council {
+		if !m.IsTermActiveAt(now) {
+			delete(e.council, c)
+			vacant = append(vacant, c)
+		}
+	}
+	sort.Slice(vacant, func(i, j int) bool { return vacant[i] < vacant[j] })
+	return vacant
+}
+
 // GetElection returns an election by ID.
 func (e *Engine) GetElection(id string) (domain.CouncilElection, error) {
 	e.mu.RLock()
@@ -378,6 +786,45 @@ func (e *Engine) GetElection(id string) (domain.CouncilElection, error) {
 	return *el, nil
 }
 
+// ═══════════════════════════════════════════════════════════════════════════
+// Participation Metrics
+// ═══════════════════════════════════════════════════════════════════════════
+
+// GovernanceActivity reports a node's Phase 5 governance activity: votes
+// cast on proposals and proposals authored.
+type GovernanceActivity func(nodeID string) (votesCast, proposalsAuthored int)
+
+// SetGovernanceActivity injects the source of proposal activity folded into
+// ParticipationStats. Without it, only council elections are counted.
+func (e *Engine) SetGovernanceActivity(fn GovernanceActivity) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.governance = fn
+}
+
+// ParticipationStats aggregates a node's civic participation across
+// governance proposals and council elections. votesCast counts proposal
+// votes plus election ballots; electionsVoted counts elections in which the
+// node cast an identified ballot.
+func (e *Engine) ParticipationStats(nodeID string) (votesCast int, electionsVoted int, proposalsAuthored int) {
+	e.mu.RLock()
+	for _, voters := range e.ballots {
+		if voters[nodeID] {
+			electionsVoted++
+		}
+	}
+	gov := e.governance
+	e.mu.RUnlock()
+
+	votesCast = electionsVoted
+	if gov != nil {
+		proposalVotes, authored := gov(nodeID)
+		votesCast += proposalVotes
+		proposalsAuthored = authored
+	}
+	return votesCast, electionsVoted, proposalsAuthored
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // Open-Source Compliance
 // ═══════════════════════════════════════════════════════════════════════════
@@ -441,6 +888,7 @@ func (e *Engine) registerDefaultParams() {
 		{Key: "free_tier_daily_limit", Category: domain.ParamCategoryAccess, CurrentValue: "100", Description: "Free tier daily inference limit", Protection: domain.ProtectionElevated},
 		{Key: "education_tier_enabled", Category: domain.ParamCategoryAccess, CurrentValue: "true", Description: "Whether education tier is available", Protection: domain.ProtectionCritical},
 		{Key: "pro_tier_daily_limit", Category: domain.ParamCategoryAccess, CurrentValue: "10000", Description: "Pro tier daily inference limit", Protection: domain.ProtectionNormal},
+		{Key: "sla_downgrade_policy", Category: domain.ParamCategoryAccess, CurrentValue: "deny", Description: "Whether calls on a full SLA tier may be served on a lower one: deny, or allow:<tier>[:notify]", Protection: domain.ProtectionElevated},
 
 		// Technical parameters
 		{Key: "task_timeout_seconds", Category: domain.ParamCategoryTechnical, CurrentValue: "300", Description: "Maximum task execution time", Protection: domain.ProtectionNormal},
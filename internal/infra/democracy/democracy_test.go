@@ -1,6 +1,9 @@
 package democracy
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,10 +25,10 @@ func TestNewEngine(t *testing.T) {
 		t.Fatal("expected non-nil Engine")
 	}
 
-	// Should have default params pre-registered (15 params total)
+	// Should have default params pre-registered (16 params total)
 	count := e.ParamCount()
-	if count != 15 {
-		t.Fatalf("expected 15 default params, got %d", count)
+	if count != 16 {
+		t.Fatalf("expected 16 default params, got %d", count)
 	}
 }
 
@@ -93,8 +96,36 @@ func TestListParamsByCategory(t *testing.T) {
 	}
 
 	access := e.ListParamsByCategory(domain.ParamCategoryAccess)
-	if len(access) != 3 {
-		t.Fatalf("expected 3 access params, got %d", len(access))
+	if len(access) != 4 {
+		t.Fatalf("expected 4 access params, got %d", len(access))
+	}
+}
+
+func TestSLADowngradePolicy_Governable(t *testing.T) {
+	e := NewEngine(DefaultConfig())
+	e.now = fixedTime
+	e.SetParamValidator("sla_downgrade_policy", func(v string) error {
+		_, err := domain.ParseDowngradePolicy(v)
+		return err
+	})
+
+	p, err := e.GetParam("sla_downgrade_policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy, err := domain.ParseDowngradePolicy(p.CurrentValue); err != nil || policy.Allow {
+		t.Fatalf("default policy = %q (%v), want downgrades denied", p.CurrentValue, err)
+	}
+
+	if err := e.ChangeParam("sla_downgrade_policy", "allow:standard:notify", "prop-1", 0.70); err != nil {
+		t.Fatalf("ChangeParam() error: %v", err)
+	}
+	if err := e.ChangeParam("sla_downgrade_policy", "allow:gold", "prop-2", 0.70); !errors.Is(err, domain.ErrInvalidDowngradePolicy) {
+		t.Errorf("invalid policy error = %v, want ErrInvalidDowngradePolicy", err)
+	}
+	p, _ = e.GetParam("sla_downgrade_policy")
+	if p.CurrentValue != "allow:standard:notify" {
+		t.Errorf("policy = %q, want the voted value kept", p.CurrentValue)
 	}
 }
 
@@ -185,6 +216,257 @@ func TestChangeParam_NotFound(t *testing.T) {
 	}
 }
 
+func TestChangeParam_ValidatorRejects(t *testing.T) {
+	e := NewEngine(DefaultConfig())
+	e.now = fixedTime
+	e.SetParamValidator("streak_bonus_cap", func(v string) error {
+		if strings.HasPrefix(v, "-") {
+			return errors.New("must be non-negative")
+		}
+		return nil
+	})
+
+	if err := e.ChangeParam("streak_bonus_cap", "-0.10", "proposal-1", 0.9); err == nil {
+		t.Fatal("expected validator to reject negative cap")
+	}
+	p, _ := e.GetParam("streak_bonus_cap")
+	if p.CurrentValue != "0.50" {
+		t.Fatalf("rejected change should leave value, got %q", p.CurrentValue)
+	}
+
+	if err := e.ChangeParam("streak_bonus_cap", "0.75", "proposal-2", 0.9); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestOnParamChange(t *testing.T) {
+	e := NewEngine(DefaultConfig())
+	e.now = fixedTime
+
+	type change struct{ key, old, new string }
+	var got []change
+	e.OnParamChange(func(key, oldValue, newValue string) {
+		// Runs outside the lock — reading back must not deadlock.
+		p, _ := e.GetParam(key)
+		if p.CurrentValue != newValue {
+			t.Errorf("GetParam in callback = %q, want %q", p.CurrentValue, newValue)
+		}
+		got = append(got, change{key, oldValue, newValue})
+	})
+
+	if err := e.ChangeParam("gossip_interval_ms", "2000", "proposal-1", 0.55); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != (change{"gossip_interval_ms", "1000", "2000"}) {
+		t.Fatalf("expected one change 1000→2000, got %+v", got)
+	}
+
+	// Rejected and no-op changes are not broadcast.
+	_ = e.ChangeParam("gossip_interval_ms", "3000", "proposal-2", 0.40)
+	_ = e.ChangeParam("gossip_interval_ms", "2000", "proposal-3", 0.55)
+	if len(got) != 1 {
+		t.Fatalf("expected no further notifications, got %+v", got)
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Emergency Brake Tests
+// ═══════════════════════════════════════════════════════════════════════════
+
+// seatCouncil elects one council member per continent via a minimal election.
+func seatCouncil(t *testing.T, e *Engine, members map[domain.ContinentID]string) {
+	t.Helper()
+	for continent, nodeID := range members {
+		id, err := e.StartElection(continent, 100)
+		if err != nil {
+			t.Fatalf("StartElection(%s): %v", continent, err)
+		}
+		_ = e.AddCandidate(id, nodeID, "Platform")
+		for i := 0; i < 10; i++ {
+			_ = e.CastVote(id, nodeID)
+		}
+		if _, err := e.CertifyElection(id); err != nil {
+			t.Fatalf("CertifyElection(%s): %v", continent, err)
+		}
+	}
+}
+
+func newEmergencyEngine(t *testing.T) *Engine {
+	e := NewEngine(DefaultConfig())
+	e.now = fixedTime
+	seatCouncil(t, e, map[domain.ContinentID]string{
+		domain.ContinentEurope:       "node-eu",
+		domain.ContinentAsia:         "node-as",
+		domain.ContinentNorthAmerica: "node-na",
+	})
+	return e
+}
+
+func TestEmergencyChange_Valid(t *testing.T) {
+	e := newEmergencyEngine(t)
+
+	err := e.EmergencyChange("min_reputation_threshold", "0.8", []string{"node-eu", "node-as", "node-na"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p, _ := e.GetParam("min_reputation_threshold")
+	if p.CurrentValue != "0.8" {
+		t.Fatalf("expected 0.8, got %q", p.CurrentValue)
+	}
+	if p.ChangedBy != "emergency" {
+		t.Fatalf("expected ChangedBy emergency, got %q", p.ChangedBy)
+	}
+
+	pending := e.PendingEmergencies()
+	if len(pending) != 1 || pending[0].PreviousValue != "0.3" {
+		t.Fatalf("expected one pending emergency from 0.3, got %+v", pending)
+	}
+
+	log := e.AuditLog()
+	if len(log) != 1 || log[0].Action != AuditEmergencyChange {
+		t.Fatalf("expected emergency audit entry, got %+v", log)
+	}
+}
+
+func TestEmergencyChange_AutoRevert(t *testing.T) {
+	e := newEmergencyEngine(t)
+
+	_ = e.EmergencyChange("quarantine_duration_hours", "72", []string{"node-eu", "node-as", "node-na"})
+
+	// Still inside the window — nothing reverts.
+	e.now = func() time.Time { return fixedTime().Add(71 * time.Hour) }
+	if reverted := e.RevertExpiredEmergencies(); len(reverted) != 0 {
+		t.Fatalf("expected no reverts inside window, got %v", reverted)
+	}
+
+	e.now = func() time.Time { return fixedTime().Add(72 * time.Hour) }
+	reverted := e.RevertExpiredEmergencies()
+	if len(reverted) != 1 || reverted[0] != "quarantine_duration_hours" {
+		t.Fatalf("expected quarantine_duration_hours reverted, got %v", reverted)
+	}
+
+	p, _ := e.GetParam("quarantine_duration_hours")
+	if p.CurrentValue != "1" {
+		t.Fatalf("expected revert to 1, got %q", p.CurrentValue)
+	}
+	if len(e.PendingEmergencies()) != 0 {
+		t.Fatal("expected no pending emergencies after revert")
+	}
+	log := e.AuditLog()
+	if log[len(log)-1].Action != AuditEmergencyRevert {
+		t.Fatalf("expected revert audit entry, got %+v", log[len(log)-1])
+	}
+}
+
+func TestRun_RevertsExpiredEmergencies(t *testing.T) {
+	e := newEmergencyEngine(t)
+	_ = e.EmergencyChange("quarantine_duration_hours", "72", []string{"node-eu", "node-as", "node-na"})
+	e.now = func() time.Time { return fixedTime().Add(72 * time.Hour) }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Run(ctx, 5*time.Millisecond)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(e.PendingEmergencies()) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Run did not revert the lapsed emergency change")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if p, _ := e.GetParam("quarantine_duration_hours"); p.CurrentValue != "1" {
+		t.Fatalf("expected revert to 1, got %q", p.CurrentValue)
+	}
+}
+
+func TestSweep_VacatesEndedTerms(t *testing.T) {
+	e := NewEngine(DefaultConfig())
+	e.now = fixedTime
+	seatCouncil(t, e, map[domain.ContinentID]string{domain.ContinentEurope: "node-eu"})
+
+	e.now = func() time.Time { return fixedTime().AddDate(0, 6, 1) }
+	e.sweep()
+	if got := e.GetCouncil(); len(got) != 0 {
+		t.Fatalf("council after sweep = %+v, want the ended seat vacated", got)
+	}
+}
+
+func TestOnParamChange_EmergencyAndRevert(t *testing.T) {
+	e := newEmergencyEngine(t)
+	var got []string
+	e.OnParamChange(func(key, oldValue, newValue string) {
+		got = append(got, key+":"+oldValue+"→"+newValue)
+	})
+
+	_ = e.EmergencyChange("quarantine_duration_hours", "72", []string{"node-eu", "node-as", "node-na"})
+	e.now = func() time.Time { return fixedTime().Add(72 * time.Hour) }
+	e.RevertExpiredEmergencies()
+
+	want := []string{"quarantine_duration_hours:1→72", "quarantine_duration_hours:72→1"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("notifications = %v, want %v", got, want)
+	}
+}
+
+func TestEmergencyChange_Ratified(t *testing.T) {
+	e := newEmergencyEngine(t)
+
+	_ = e.EmergencyChange("quarantine_duration_hours", "72", []string{"node-eu", "node-as", "node-na"})
+
+	// Elevated protection requires 60%.
+	if err := e.RatifyEmergency("quarantine_duration_hours", "prop-1", 0.55); err != domain.ErrDemocracyQuorumFailed {
+		t.Fatalf("expected ErrDemocracyQuorumFailed, got %v", err)
+	}
+	if err := e.RatifyEmergency("quarantine_duration_hours", "prop-1", 0.65); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	e.now = func() time.Time { return fixedTime().Add(100 * time.Hour) }
+	if reverted := e.RevertExpiredEmergencies(); len(reverted) != 0 {
+		t.Fatalf("ratified change should not revert, got %v", reverted)
+	}
+	p, _ := e.GetParam("quarantine_duration_hours")
+	if p.CurrentValue != "72" {
+		t.Fatalf("expected 72 to stick, got %q", p.CurrentValue)
+	}
+}
+
+func TestEmergencyChange_InsufficientSignatures(t *testing.T) {
+	e := newEmergencyEngine(t)
+
+	tests := []struct {
+		name string
+		sigs []string
+	}{
+		{"too few", []string{"node-eu", "node-as"}},
+		{"duplicate signer", []string{"node-eu", "node-eu", "node-as"}},
+		{"non-council signer", []string{"node-eu", "node-as", "node-mallory"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.EmergencyChange("min_reputation_threshold", "0.9", tt.sigs)
+			if err != domain.ErrEmergencySignatures {
+				t.Fatalf("expected ErrEmergencySignatures, got %v", err)
+			}
+		})
+	}
+
+	p, _ := e.GetParam("min_reputation_threshold")
+	if p.CurrentValue != "0.3" {
+		t.Fatalf("value should be unchanged, got %q", p.CurrentValue)
+	}
+}
+
+func TestEmergencyChange_Immutable(t *testing.T) {
+	e := newEmergencyEngine(t)
+
+	err := e.EmergencyChange("open_source_license", "proprietary", []string{"node-eu", "node-as", "node-na"})
+	if err != domain.ErrParameterProtected {
+		t.Fatalf("expected ErrParameterProtected, got %v", err)
+	}
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // Council Election Tests
 // ═══════════════════════════════════════════════════════════════════════════
@@ -348,6 +630,77 @@ func TestCertifyElection_Success(t *testing.T) {
 	}
 }
 
+func TestIsCouncilMember(t *testing.T) {
+	e := NewEngine(DefaultConfig())
+	e.now = fixedTime
+
+	id, _ := e.StartElection(domain.ContinentEurope, 100)
+	_ = e.AddCandidate(id, "node-alice", "Platform A")
+	for i := 0; i < 12; i++ {
+		_ = e.CastVote(id, "node-alice")
+	}
+	member, err := e.CertifyElection(id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !e.IsCouncilMember("node-alice") {
+		t.Fatal("seated winner should be a council member")
+	}
+	if e.IsCouncilMember("node-bob") {
+		t.Fatal("non-member reported as council member")
+	}
+
+	e.now = func() time.Time { return member.TermExpires }
+	if e.IsCouncilMember("node-alice") {
+		t.Fatal("expired term should not count as council membership")
+	}
+}
+
+func TestExpireTerms(t *testing.T) {
+	e := NewEngine(DefaultConfig())
+	e.now = fixedTime
+	seatCouncil(t, e, map[domain.ContinentID]string{
+		domain.ContinentEurope: "node-eu",
+		domain.ContinentAsia:   "node-as",
+	})
+	// Africa's member is elected two months later, so serves longer.
+	e.now = func() time.Time { return fixedTime().AddDate(0, 2, 0) }
+	seatCouncil(t, e, map[domain.ContinentID]string{domain.ContinentAfrica: "node-af"})
+
+	if got := e.ExpireTerms(fixedTime().AddDate(0, 5, 0)); len(got) != 0 {
+		t.Fatalf("ExpireTerms mid-term = %v, want none", got)
+	}
+
+	// Past the 6-month term of the first two seats.
+	e.now = func() time.Time { return fixedTime().AddDate(0, 6, 1) }
+	got := e.ExpireTerms(time.Time{})
+	want := []domain.ContinentID{domain.ContinentAsia, domain.ContinentEurope}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("ExpireTerms() = %v, want %v", got, want)
+	}
+
+	if e.IsCouncilMember("node-eu") {
+		t.Error("node-eu should no longer hold a seat")
+	}
+	if council := e.GetCouncil(); len(council) != 1 || council[0].NodeID != "node-af" {
+		t.Errorf("council = %+v, want only node-af", council)
+	}
+	if again := e.ExpireTerms(time.Time{}); len(again) != 0 {
+		t.Errorf("second ExpireTerms() = %v, want none", again)
+	}
+
+	// The vacant seat can be re-elected.
+	seatCouncil(t, e, map[domain.ContinentID]string{domain.ContinentEurope: "node-eu2"})
+	if !e.IsCouncilMember("node-eu2") {
+		t.Error("re-elected member should hold the seat")
+	}
+
+	if got := e.ExpireTerms(fixedTime().AddDate(0, 8, 1)); len(got) != 1 || got[0] != domain.ContinentAfrica {
+		t.Errorf("ExpireTerms(explicit time) = %v, want [%s]", got, domain.ContinentAfrica)
+	}
+}
+
 func TestCertifyElection_InsufficientTurnout(t *testing.T) {
 	e := NewEngine(DefaultConfig())
 	e.now = fixedTime
@@ -385,6 +738,74 @@ func TestCertifyElection_NotFound(t *testing.T) {
 	}
 }
 
+// ═══════════════════════════════════════════════════════════════════════════
+// Participation Metrics Tests
+// ═══════════════════════════════════════════════════════════════════════════
+
+func TestCastVoteAs_RejectsDuplicate(t *testing.T) {
+	e := NewEngine(DefaultConfig())
+	e.now = fixedTime
+
+	id, _ := e.StartElection(domain.ContinentEurope, 100)
+	_ = e.AddCandidate(id, "node-alice", "Platform A")
+
+	if err := e.CastVoteAs(id, "node-voter", "node-alice"); err != nil {
+		t.Fatalf("first vote: %v", err)
+	}
+	if err := e.CastVoteAs(id, "node-voter", "node-alice"); !errors.Is(err, domain.ErrAlreadyVoted) {
+		t.Fatalf("expected ErrAlreadyVoted, got %v", err)
+	}
+
+	el, _ := e.GetElection(id)
+	if el.TotalVotes != 1 {
+		t.Fatalf("expected 1 total vote, got %d", el.TotalVotes)
+	}
+}
+
+func TestParticipationStats(t *testing.T) {
+	e := NewEngine(DefaultConfig())
+	e.now = fixedTime
+	e.SetGovernanceActivity(func(nodeID string) (int, int) {
+		if nodeID == "node-civic" {
+			return 4, 2
+		}
+		return 0, 0
+	})
+
+	for _, c := range []domain.ContinentID{domain.ContinentEurope, domain.ContinentAsia} {
+		id, _ := e.StartElection(c, 100)
+		_ = e.AddCandidate(id, "node-alice", "Platform A")
+		if err := e.CastVoteAs(id, "node-civic", "node-alice"); err != nil {
+			t.Fatalf("vote in %s: %v", c, err)
+		}
+		_ = e.CastVote(id, "node-alice") // anonymous ballots are not attributed
+	}
+
+	votes, elections, authored := e.ParticipationStats("node-civic")
+	if votes != 6 || elections != 2 || authored != 2 {
+		t.Fatalf("ParticipationStats(node-civic) = %d, %d, %d; want 6, 2, 2", votes, elections, authored)
+	}
+
+	votes, elections, authored = e.ParticipationStats("node-idle")
+	if votes != 0 || elections != 0 || authored != 0 {
+		t.Fatalf("ParticipationStats(node-idle) = %d, %d, %d; want 0, 0, 0", votes, elections, authored)
+	}
+}
+
+func TestParticipationStats_NoGovernance(t *testing.T) {
+	e := NewEngine(DefaultConfig())
+	e.now = fixedTime
+
+	id, _ := e.StartElection(domain.ContinentAfrica, 100)
+	_ = e.AddCandidate(id, "node-alice", "Platform A")
+	_ = e.CastVoteAs(id, "node-civic", "node-alice")
+
+	votes, elections, authored := e.ParticipationStats("node-civic")
+	if votes != 1 || elections != 1 || authored != 0 {
+		t.Fatalf("ParticipationStats = %d, %d, %d; want 1, 1, 0", votes, elections, authored)
+	}
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // Open-Source Compliance Tests
 // ═══════════════════════════════════════════════════════════════════════════
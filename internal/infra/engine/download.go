@@ -14,11 +14,20 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+
+	"github.com/tutu-network/tutu/internal/infra/resource"
 )
 
 // llamaCppReleasesAPI is the GitHub API endpoint for llama.cpp releases.
 const llamaCppReleasesAPI = "https://api.github.com/repos/ggml-org/llama.cpp/releases/latest"
 
+// extractedSizeFactor estimates a llama.cpp archive's extracted size as a
+// multiple of its compressed size, for the pre-download disk-space check.
+const extractedSizeFactor = 3
+
+// diskSpace reports free space for the pre-download check; tests replace it.
+var diskSpace resource.DiskSpaceFunc = resource.FreeDiskSpace
+
 // DownloadLlamaServer downloads the llama-server binary from the latest
 // llama.cpp release and places it in tutuHome/bin/.
 // Returns the path to the downloaded binary on success.
@@ -53,20 +62,42 @@ func DownloadLlamaServer(tutuHome string, progress func(status string, pct float
 	}
 
 	// Get latest release info from GitHub
-	assetURL, assetName, err := findLlamaServerAsset()
+	asset, err := findLlamaServerAsset()
 	if err != nil {
 		return "", fmt.Errorf("find llama-server release: %w", err)
 	}
+	if err := installLlamaServer(asset, targetPath, progress); err != nil {
+		return "", err
+	}
+	return targetPath, nil
+}
+
+// releaseAsset is a downloadable file of a llama.cpp release.
+type releaseAsset struct {
+	URL  string
+	Name string
+	Size int64 // archive size in bytes; 0 if unreported
+}
+
+// installLlamaServer downloads asset next to targetPath and extracts it
+// there. It refuses to start if the disk cannot hold the archive plus its
+// extracted contents.
+func installLlamaServer(asset releaseAsset, targetPath string, progress func(status string, pct float64)) error {
+	binDir := filepath.Dir(targetPath)
+	need := uint64(max(asset.Size, 0)) * (1 + extractedSizeFactor)
+	if err := diskSpace.Require(binDir, need); err != nil {
+		return fmt.Errorf("download llama-server: %w", err)
+	}
 
 	if progress != nil {
-		progress(fmt.Sprintf("downloading %s...", assetName), 5)
+		progress(fmt.Sprintf("downloading %s...", asset.Name), 5)
 	}
 
 	// Download the asset
 	tmpPath := filepath.Join(binDir, ".download-llama-server.tmp")
-	if err := downloadFile(assetURL, tmpPath, progress); err != nil {
+	if err := downloadFile(asset.URL, tmpPath, progress); err != nil {
 		os.Remove(tmpPath)
-		return "", fmt.Errorf("download llama-server: %w", err)
+		return fmt.Errorf("download llama-server: %w", err)
 	}
 
 	if progress != nil {
@@ -74,9 +105,9 @@ func DownloadLlamaServer(tutuHome string, progress func(status string, pct float
 	}
 
 	// Extract the binary from the archive
-	if err := extractLlamaServer(tmpPath, targetPath, assetName); err != nil {
+	if err := extractLlamaServer(tmpPath, targetPath, asset.Name); err != nil {
 		os.Remove(tmpPath)
-		return "", fmt.Errorf("extract llama-server: %w", err)
+		return fmt.Errorf("extract llama-server: %w", err)
 	}
 	os.Remove(tmpPath)
 
@@ -88,8 +119,7 @@ func DownloadLlamaServer(tutuHome string, progress func(status string, pct float
 	if progress != nil {
 		progress("llama-server ready!", 100)
 	}
-
-	return targetPath, nil
+	return nil
 }
 
 // missingCompanionLibs checks whether required companion libraries are present
@@ -151,25 +181,25 @@ func missingCompanionLibs(binDir string) bool {
 }
 
 // findLlamaServerAsset queries the GitHub API for the latest llama.cpp release
-// and returns the download URL and filename for the current platform.
-func findLlamaServerAsset() (url, name string, err error) {
+// and returns the asset for the current platform.
+func findLlamaServerAsset() (releaseAsset, error) {
 	client := &http.Client{}
 	req, err := http.NewRequest("GET", llamaCppReleasesAPI, nil)
 	if err != nil {
-		return "", "", err
+		return releaseAsset{}, err
 	}
 	req.Header.Set("User-Agent", "TuTu/0.1.0")
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", "", fmt.Errorf("GitHub API request failed: %w", err)
+		return releaseAsset{}, fmt.Errorf("GitHub API request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", "", fmt.Errorf("GitHub API returned %d: %s", resp.StatusCode, string(body))
+		return releaseAsset{}, fmt.Errorf("GitHub API returned %d: %s", resp.StatusCode, string(body))
 	}
 
 	var release struct {
@@ -181,7 +211,7 @@ func findLlamaServerAsset() (url, name string, err error) {
 		} `json:"assets"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return "", "", fmt.Errorf("parse release JSON: %w", err)
+		return releaseAsset{}, fmt.Errorf("parse release JSON: %w", err)
 	}
 
 	// Build the pattern we're looking for based on OS/arch
@@ -192,7 +222,7 @@ func findLlamaServerAsset() (url, name string, err error) {
 		for _, asset := range release.Assets {
 			nameLower := strings.ToLower(asset.Name)
 			if matchesAsset(nameLower, pattern) {
-				return asset.BrowserDownloadURL, asset.Name, nil
+				return releaseAsset{URL: asset.BrowserDownloadURL, Name: asset.Name, Size: asset.Size}, nil
 			}
 		}
 	}
@@ -202,7 +232,7 @@ func findLlamaServerAsset() (url, name string, err error) {
 	for _, a := range release.Assets {
 		available = append(available, a.Name)
 	}
-	return "", "", fmt.Errorf(
+	return releaseAsset{}, fmt.Errorf(
 		"no llama-server binary found for %s/%s in release %s\nAvailable assets: %s",
 		runtime.GOOS, runtime.GOARCH, release.TagName,
 		strings.Join(available, ", "),
@@ -359,14 +389,46 @@ func extractLlamaServer(archivePath, targetPath, archiveName string) error {
 	destDir := filepath.Dir(targetPath)
 
 	if strings.HasSuffix(strings.ToLower(archiveName), ".zip") {
-		return extractAllFromZip(archivePath, destDir)
+		return extractStaged(destDir, func(dir string) error {
+			return extractAllFromZip(archivePath, dir)
+		})
 	}
 	if strings.HasSuffix(strings.ToLower(archiveName), ".tar.gz") {
-		return extractAllFromTarGz(archivePath, destDir)
+		return extractStaged(destDir, func(dir string) error {
+			return extractAllFromTarGz(archivePath, dir)
+		})
 	}
 	return fmt.Errorf("unsupported archive format: %s", archiveName)
 }
 
+// extractStaged runs extract into a temporary subdirectory of destDir and
+// moves the files into destDir only if it succeeds. A failed extraction
+// never leaves a half-installed set of binaries and libraries behind for
+// missingCompanionLibs to trip over. The staging directory lives inside
+// destDir so the final moves are same-filesystem renames.
+func extractStaged(destDir string, extract func(dir string) error) error {
+	stageDir, err := os.MkdirTemp(destDir, ".extract-")
+	if err != nil {
+		return fmt.Errorf("create staging dir: %w", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	if err := extract(stageDir); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(stageDir)
+	if err != nil {
+		return fmt.Errorf("read staging dir: %w", err)
+	}
+	for _, e := range entries {
+		if err := os.Rename(filepath.Join(stageDir, e.Name()), filepath.Join(destDir, e.Name())); err != nil {
+			return fmt.Errorf("install %s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
+
 // extractAllFromZip extracts all files from a zip archive into destDir.
 // Only regular files are extracted (no directories). Files inside nested
 // directories within the zip are flattened into destDir.
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// writeTarGz builds a .tar.gz archive holding the given files.
+func writeTarGz(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "llama.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for name, body := range files {
+		hdr := &tar.Header{Name: "build/bin/" + name, Mode: 0o755, Size: int64(len(body)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func serverBinaryName() string {
+	if runtime.GOOS == "windows" {
+		return "llama-server.exe"
+	}
+	return "llama-server"
+}
+
+func TestExtractLlamaServer_Success(t *testing.T) {
+	archive := writeTarGz(t, map[string]string{
+		serverBinaryName(): "server",
+		"libggml.so":       "lib",
+	})
+	binDir := t.TempDir()
+
+	if err := extractLlamaServer(archive, filepath.Join(binDir, serverBinaryName()), "llama.tar.gz"); err != nil {
+		t.Fatalf("extractLlamaServer: %v", err)
+	}
+
+	entries, _ := os.ReadDir(binDir)
+	if len(entries) != 2 {
+		t.Fatalf("bin dir has %d entries, want 2 (no staging dir left): %v", len(entries), entries)
+	}
+	for _, name := range []string{serverBinaryName(), "libggml.so"} {
+		if _, err := os.Stat(filepath.Join(binDir, name)); err != nil {
+			t.Errorf("%s not installed: %v", name, err)
+		}
+	}
+}
+
+func TestExtractLlamaServer_FailureLeavesNoPartialFiles(t *testing.T) {
+	// Companion libs extract fine, but the archive has no server binary —
+	// extraction fails after some files were already written.
+	archive := writeTarGz(t, map[string]string{
+		"libggml.so":  "lib",
+		"libllama.so": "lib",
+	})
+	binDir := t.TempDir()
+
+	if err := extractLlamaServer(archive, filepath.Join(binDir, serverBinaryName()), "llama.tar.gz"); err == nil {
+		t.Fatal("expected error for archive without llama-server")
+	}
+
+	entries, _ := os.ReadDir(binDir)
+	if len(entries) != 0 {
+		t.Fatalf("bin dir should be untouched after failed extraction, found %v", entries)
+	}
+}
+
+func TestExtractLlamaServer_CorruptArchive(t *testing.T) {
+	archive := writeTarGz(t, map[string]string{
+		"libggml.so":       string(make([]byte, 4096)),
+		serverBinaryName(): "server",
+	})
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(archive, data[:len(data)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	binDir := t.TempDir()
+
+	if err := extractLlamaServer(archive, filepath.Join(binDir, serverBinaryName()), "llama.tar.gz"); err == nil {
+		t.Fatal("expected error for truncated archive")
+	}
+	if entries, _ := os.ReadDir(binDir); len(entries) != 0 {
+		t.Fatalf("bin dir should be untouched after failed extraction, found %v", entries)
+	}
+}
+
+func TestInstallLlamaServer_InsufficientDiskAbortsBeforeDownload(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write(make([]byte, 1000))
+	}))
+	defer srv.Close()
+
+	orig := diskSpace
+	defer func() { diskSpace = orig }()
+	// Room for the archive but not for archive + extracted files.
+	diskSpace = func(string) (uint64, error) { return 2000, nil }
+
+	binDir := t.TempDir()
+	asset := releaseAsset{URL: srv.URL, Name: "llama.tar.gz", Size: 1000}
+	err := installLlamaServer(asset, filepath.Join(binDir, serverBinaryName()), nil)
+	if !errors.Is(err, domain.ErrInsufficientDisk) {
+		t.Fatalf("installLlamaServer() error = %v, want ErrInsufficientDisk", err)
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("download started despite low disk space (%d requests)", n)
+	}
+	if entries, _ := os.ReadDir(binDir); len(entries) != 0 {
+		t.Fatalf("bin dir should be untouched, found %v", entries)
+	}
+}
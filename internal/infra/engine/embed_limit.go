@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// ─── Adaptive Embedding Concurrency ─────────────────────────────────────────
+// Embed sends llama-server one request per input. A fixed number in flight
+// either leaves server slots idle or queues work behind them, so each handle
+// adapts its concurrency AIMD-style: a request answered within the latency
+// target adds 1/limit (about one more worker per round of requests), and a
+// slow or failed one (a timeout or server error is congestion too) halves
+// the limit. Only requests started since the last cut can cut again, so one
+// slow round halves the limit once, not once per request.
+
+// Embedding concurrency defaults, used when the backend sets none.
+const (
+	DefaultEmbedMaxConcurrency = 4
+	DefaultEmbedLatencyTarget  = time.Second
+)
+
+// aimdLimiter bounds in-flight requests with an adaptive limit between 1
+// and max. The zero value is not usable; see newAIMDLimiter.
+type aimdLimiter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	limit    float64
+	max      int
+	target   time.Duration
+	inflight int
+	epoch    uint64 // bumped on each cut
+}
+
+// newAIMDLimiter starts at one request in flight. Non-positive arguments
+// use the defaults.
+func newAIMDLimiter(max int, target time.Duration) *aimdLimiter {
+	if max <= 0 {
+		max = DefaultEmbedMaxConcurrency
+	}
+	if target <= 0 {
+		target = DefaultEmbedLatencyTarget
+	}
+	l := &aimdLimiter{limit: 1, max: max, target: target}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire blocks until a request may start, and returns a token to pass
+// to release.
+func (l *aimdLimiter) acquire() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.inflight >= int(l.limit) {
+		l.cond.Wait()
+	}
+	l.inflight++
+	return l.epoch
+}
+
+// release ends a request that took latency and adapts the limit.
+func (l *aimdLimiter) release(token uint64, latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	if latency <= l.target {
+		l.limit = min(l.limit+1/l.limit, float64(l.max))
+	} else {
+		l.cutLocked(token)
+	}
+	l.cond.Broadcast()
+}
+
+// fail ends a request that failed — timed out or refused by an overloaded
+// server — and cuts the limit as for a slow one.
+func (l *aimdLimiter) fail(token uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	l.cutLocked(token)
+	l.cond.Broadcast()
+}
+
+// cutLocked halves the limit unless a request started after the last cut
+// already did. Caller holds l.mu.
+func (l *aimdLimiter) cutLocked(token uint64) {
+	if token == l.epoch {
+		l.limit = max(l.limit/2, 1)
+		l.epoch++
+	}
+}
+
+// abandon ends a request that produced no congestion signal (canceled by
+// the caller) without adapting the limit.
+func (l *aimdLimiter) abandon() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	l.cond.Broadcast()
+}
+
+// Limit returns the current number of requests allowed in flight.
+func (l *aimdLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
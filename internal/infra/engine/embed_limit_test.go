@@ -0,0 +1,182 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAIMDLimiter_RampsUpWhileFast(t *testing.T) {
+	l := newAIMDLimiter(8, 10*time.Millisecond)
+	if got := l.Limit(); got != 1 {
+		t.Fatalf("initial Limit() = %d, want 1", got)
+	}
+	for range 50 {
+		l.release(l.acquire(), time.Millisecond)
+	}
+	if got := l.Limit(); got != 8 {
+		t.Errorf("Limit() after fast requests = %d, want the cap of 8", got)
+	}
+}
+
+func TestAIMDLimiter_HalvesOncePerSlowRound(t *testing.T) {
+	l := newAIMDLimiter(8, 10*time.Millisecond)
+	for range 50 {
+		l.release(l.acquire(), time.Millisecond)
+	}
+
+	// A whole round of concurrent requests comes back slow: one cut, not eight.
+	tokens := make([]uint64, 8)
+	for i := range tokens {
+		tokens[i] = l.acquire()
+	}
+	for _, tok := range tokens {
+		l.release(tok, time.Second)
+	}
+	if got := l.Limit(); got != 4 {
+		t.Errorf("Limit() after one slow round = %d, want 4", got)
+	}
+
+	// Requests started after the cut can cut again.
+	l.release(l.acquire(), time.Second)
+	if got := l.Limit(); got != 2 {
+		t.Errorf("Limit() after a second slow round = %d, want 2", got)
+	}
+	for range 5 {
+		l.release(l.acquire(), time.Second)
+	}
+	if got := l.Limit(); got != 1 {
+		t.Errorf("Limit() = %d, want a floor of 1", got)
+	}
+}
+
+func TestAIMDLimiter_AbandonDoesNotAdapt(t *testing.T) {
+	l := newAIMDLimiter(8, 10*time.Millisecond)
+	for range 4 {
+		l.acquire()
+		l.abandon()
+	}
+	if got := l.Limit(); got != 1 {
+		t.Errorf("Limit() after abandoned requests = %d, want it unchanged at 1", got)
+	}
+	l.release(l.acquire(), time.Millisecond) // the slot was freed
+	if got := l.Limit(); got != 2 {
+		t.Errorf("Limit() after a fast request = %d, want 2", got)
+	}
+}
+
+func TestAIMDLimiter_FailureCuts(t *testing.T) {
+	l := newAIMDLimiter(8, 10*time.Millisecond)
+	for range 50 {
+		l.release(l.acquire(), time.Millisecond)
+	}
+	l.fail(l.acquire())
+	if got := l.Limit(); got != 4 {
+		t.Errorf("Limit() after a failed request = %d, want it halved to 4", got)
+	}
+}
+
+// indexServer serves /embedding, echoing the input's index as the vector.
+func indexServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Content string `json:"content"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		idx, _ := strconv.Atoi(body.Content)
+		json.NewEncoder(w).Encode(map[string]any{"embedding": []float32{float32(idx)}})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSubprocessHandle_EmbedAdaptsConcurrency(t *testing.T) {
+	var latency atomic.Int64 // reported for every request, in nanoseconds
+	h := stubHandle(indexServer(t))
+	h.embedLimit = newAIMDLimiter(6, 20*time.Millisecond)
+	h.embedSince = func(time.Time) time.Duration { return time.Duration(latency.Load()) }
+
+	inputs := make([]string, 60)
+	for i := range inputs {
+		inputs[i] = fmt.Sprint(i)
+	}
+	embed := func(n int) {
+		t.Helper()
+		vecs, err := h.Embed(context.Background(), inputs[:n])
+		if err != nil {
+			t.Fatalf("Embed() error: %v", err)
+		}
+		for i, v := range vecs {
+			if len(v) != 1 || v[0] != float32(i) {
+				t.Fatalf("vecs[%d] = %v, want it placed by index", i, v)
+			}
+		}
+	}
+
+	// Low latency: the handle ramps up from a single request in flight.
+	latency.Store(int64(5 * time.Millisecond))
+	embed(60)
+	ramped := h.embedLimit.Limit()
+	if ramped < 2 {
+		t.Errorf("Limit() under low latency = %d, want it raised from 1", ramped)
+	}
+
+	// Latency climbs past the target: the handle backs off.
+	latency.Store(int64(40 * time.Millisecond))
+	embed(12)
+	if got := h.embedLimit.Limit(); got >= ramped {
+		t.Errorf("Limit() under high latency = %d, want it reduced from %d", got, ramped)
+	}
+}
+
+func TestSubprocessHandle_EmbedStopsOnFailure(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte("not json"))
+	}))
+	defer srv.Close()
+
+	inputs := make([]string, 20)
+	if _, err := stubHandle(srv).Embed(context.Background(), inputs); err == nil {
+		t.Fatal("Embed() should fail when the server returns garbage")
+	}
+	if n := calls.Load(); n >= 20 {
+		t.Errorf("server saw %d requests, want the failure to stop the batch early", n)
+	}
+}
+
+func TestSubprocessHandle_EmbedBacksOffOnServerErrors(t *testing.T) {
+	var overloaded atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if overloaded.Load() {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"embedding": []float32{1}})
+	}))
+	defer srv.Close()
+	h := stubHandle(srv)
+	h.embedLimit = newAIMDLimiter(6, time.Hour)
+
+	inputs := make([]string, 40)
+	if _, err := h.Embed(context.Background(), inputs); err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+	ramped := h.embedLimit.Limit()
+
+	overloaded.Store(true)
+	if _, err := h.Embed(context.Background(), inputs); err == nil {
+		t.Fatal("Embed() should fail on 503s")
+	}
+	if got := h.embedLimit.Limit(); got >= ramped {
+		t.Errorf("Limit() after 503s = %d, want it cut from %d", got, ramped)
+	}
+}
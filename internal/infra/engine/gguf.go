@@ -0,0 +1,346 @@
+package engine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── GGUF Header Parsing ────────────────────────────────────────────────────
+// Reads the metadata key/values and tensor descriptors at the start of a
+// GGUF file — no tensor data is touched. Format reference:
+// https://github.com/ggerganov/ggml/blob/master/docs/gguf.md
+
+const (
+	ggufMagic = 0x46554747 // "GGUF" little-endian
+
+	// maxGGUFString bounds a single string (keys, chat templates) so a
+	// corrupt length cannot trigger a huge allocation.
+	maxGGUFString = 16 << 20
+	// maxGGUFDims is the most dimensions a ggml tensor can have.
+	maxGGUFDims = 4
+)
+
+// GGUF metadata value types.
+const (
+	ggufUint8 uint32 = iota
+	ggufInt8
+	ggufUint16
+	ggufInt16
+	ggufUint32
+	ggufInt32
+	ggufFloat32
+	ggufBool
+	ggufString
+	ggufArray
+	ggufUint64
+	ggufInt64
+	ggufFloat64
+)
+
+// ggufScalarSize returns the encoded size of a fixed-width value type,
+// or 0 for strings, arrays, and unknown types.
+func ggufScalarSize(typ uint32) int {
+	switch typ {
+	case ggufUint8, ggufInt8, ggufBool:
+		return 1
+	case ggufUint16, ggufInt16:
+		return 2
+	case ggufUint32, ggufInt32, ggufFloat32:
+		return 4
+	case ggufUint64, ggufInt64, ggufFloat64:
+		return 8
+	default:
+		return 0
+	}
+}
+
+// ReadGGUFMetadata extracts architecture, parameter count, and trained
+// context length from the GGUF file at path.
+func ReadGGUFMetadata(path string) (domain.ModelMetadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return domain.ModelMetadata{}, err
+	}
+	defer f.Close()
+
+	meta, err := parseGGUF(f)
+	if err != nil {
+		return domain.ModelMetadata{}, fmt.Errorf("parse GGUF %s: %w", path, err)
+	}
+	return meta, nil
+}
+
+// ggufReader decodes little-endian GGUF primitives. Version 1 files use
+// 32-bit lengths and counts; later versions use 64-bit.
+type ggufReader struct {
+	r       *bufio.Reader
+	version uint32
+	buf     [8]byte
+}
+
+func (g *ggufReader) read(n int) ([]byte, error) {
+	if _, err := io.ReadFull(g.r, g.buf[:n]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return g.buf[:n], nil
+}
+
+func (g *ggufReader) readU32() (uint32, error) {
+	b, err := g.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func (g *ggufReader) readU64() (uint64, error) {
+	b, err := g.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+// readCount reads a length or element count in the file's version width.
+func (g *ggufReader) readCount() (uint64, error) {
+	if g.version == 1 {
+		n, err := g.readU32()
+		return uint64(n), err
+	}
+	return g.readU64()
+}
+
+func (g *ggufReader) skip(n uint64) error {
+	for n > 0 {
+		step := n
+		if step > math.MaxInt32 {
+			step = math.MaxInt32
+		}
+		if _, err := g.r.Discard(int(step)); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		n -= step
+	}
+	return nil
+}
+
+func (g *ggufReader) readString() (string, error) {
+	n, err := g.readCount()
+	if err != nil {
+		return "", err
+	}
+	if n > maxGGUFString {
+		return "", fmt.Errorf("string length %d exceeds limit", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(g.r, b); err != nil {
+		return "", io.ErrUnexpectedEOF
+	}
+	return string(b), nil
+}
+
+func (g *ggufReader) skipString() error {
+	n, err := g.readCount()
+	if err != nil {
+		return err
+	}
+	return g.skip(n)
+}
+
+// integer reads an integer-typed value, reporting ok=false for other types
+// (which are skipped).
+func (g *ggufReader) integer(typ uint32) (v int64, ok bool, err error) {
+	size := ggufScalarSize(typ)
+	if size == 0 || typ == ggufFloat32 || typ == ggufFloat64 || typ == ggufBool {
+		return 0, false, g.skipValue(typ)
+	}
+	b, err := g.read(size)
+	if err != nil {
+		return 0, false, err
+	}
+	switch typ {
+	case ggufUint8:
+		v = int64(b[0])
+	case ggufInt8:
+		v = int64(int8(b[0]))
+	case ggufUint16:
+		v = int64(binary.LittleEndian.Uint16(b))
+	case ggufInt16:
+		v = int64(int16(binary.LittleEndian.Uint16(b)))
+	case ggufUint32:
+		v = int64(binary.LittleEndian.Uint32(b))
+	case ggufInt32:
+		v = int64(int32(binary.LittleEndian.Uint32(b)))
+	case ggufUint64, ggufInt64:
+		v = int64(binary.LittleEndian.Uint64(b))
+	}
+	return v, true, nil
+}
+
+// skipValue discards a value of the given type, including nested arrays.
+func (g *ggufReader) skipValue(typ uint32) error {
+	if size := ggufScalarSize(typ); size > 0 {
+		return g.skip(uint64(size))
+	}
+	switch typ {
+	case ggufString:
+		return g.skipString()
+	case ggufArray:
+		elemType, err := g.readU32()
+		if err != nil {
+			return err
+		}
+		n, err := g.readCount()
+		if err != nil {
+			return err
+		}
+		if size := ggufScalarSize(elemType); size > 0 {
+			if n > math.MaxUint64/uint64(size) {
+				return fmt.Errorf("array of %d elements is too large", n)
+			}
+			return g.skip(n * uint64(size))
+		}
+		for i := uint64(0); i < n; i++ {
+			if err := g.skipValue(elemType); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown metadata value type %d", typ)
+	}
+}
+
+// parseGGUF reads the GGUF header from r. The parameter count comes from
+// general.parameter_count when present, otherwise from summing the element
+// counts of every tensor descriptor.
+func parseGGUF(r io.Reader) (domain.ModelMetadata, error) {
+	var meta domain.ModelMetadata
+	g := &ggufReader{r: bufio.NewReaderSize(r, 64<<10)}
+
+	magic, err := g.readU32()
+	if err != nil {
+		return meta, err
+	}
+	if magic != ggufMagic {
+		return meta, errors.New("not a GGUF file")
+	}
+	if g.version, err = g.readU32(); err != nil {
+		return meta, err
+	}
+	if g.version < 1 || g.version > 3 {
+		return meta, fmt.Errorf("unsupported GGUF version %d", g.version)
+	}
+	tensorCount, err := g.readCount()
+	if err != nil {
+		return meta, err
+	}
+	kvCount, err := g.readCount()
+	if err != nil {
+		return meta, err
+	}
+
+	// Context length is keyed by architecture ("llama.context_length"),
+	// which may appear before general.architecture — collect all candidates.
+	contextLengths := make(map[string]int64)
+	var paramCount int64
+	for i := uint64(0); i < kvCount; i++ {
+		key, err := g.readString()
+		if err != nil {
+			return meta, fmt.Errorf("metadata key %d: %w", i, err)
+		}
+		typ, err := g.readU32()
+		if err != nil {
+			return meta, err
+		}
+
+		switch {
+		case key == "general.architecture" && typ == ggufString:
+			if meta.Architecture, err = g.readString(); err != nil {
+				return meta, err
+			}
+		case key == "general.parameter_count":
+			v, ok, err := g.integer(typ)
+			if err != nil {
+				return meta, err
+			}
+			if ok {
+				paramCount = v
+			}
+		case strings.HasSuffix(key, ".context_length"):
+			v, ok, err := g.integer(typ)
+			if err != nil {
+				return meta, err
+			}
+			if ok {
+				contextLengths[strings.TrimSuffix(key, ".context_length")] = v
+			}
+		default:
+			if err := g.skipValue(typ); err != nil {
+				return meta, fmt.Errorf("metadata %q: %w", key, err)
+			}
+		}
+	}
+
+	if meta.Architecture == "" {
+		return meta, errors.New("missing general.architecture")
+	}
+	meta.ContextLength = int(contextLengths[meta.Architecture])
+
+	if paramCount > 0 {
+		meta.ParameterCount = paramCount
+		return meta, nil
+	}
+	for i := uint64(0); i < tensorCount; i++ {
+		n, err := g.tensorElements()
+		if err != nil {
+			return meta, fmt.Errorf("tensor %d: %w", i, err)
+		}
+		meta.ParameterCount += n
+	}
+	return meta, nil
+}
+
+// tensorElements reads one tensor descriptor and returns its element count.
+func (g *ggufReader) tensorElements() (int64, error) {
+	if err := g.skipString(); err != nil { // name
+		return 0, err
+	}
+	nDims, err := g.readU32()
+	if err != nil {
+		return 0, err
+	}
+	if nDims > maxGGUFDims {
+		return 0, fmt.Errorf("%d dimensions exceeds limit", nDims)
+	}
+	elems := int64(1)
+	for d := uint32(0); d < nDims; d++ {
+		dim, err := g.readCount()
+		if err != nil {
+			return 0, err
+		}
+		if elems > 0 && dim > math.MaxInt64/uint64(elems) {
+			return 0, errors.New("tensor size overflows")
+		}
+		elems *= int64(dim)
+	}
+	// ggml type + data offset
+	if err := g.skip(4 + 8); err != nil {
+		return 0, err
+	}
+	return elems, nil
+}
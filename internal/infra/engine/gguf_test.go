@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ggufBuilder writes a minimal GGUF v3 header: metadata key/values followed
+// by tensor descriptors (no tensor data).
+type ggufBuilder struct {
+	kv      bytes.Buffer
+	tensors bytes.Buffer
+	nKV     uint64
+	nTensor uint64
+}
+
+func putString(w *bytes.Buffer, s string) {
+	binary.Write(w, binary.LittleEndian, uint64(len(s)))
+	w.WriteString(s)
+}
+
+func (b *ggufBuilder) str(key, val string) *ggufBuilder {
+	putString(&b.kv, key)
+	binary.Write(&b.kv, binary.LittleEndian, ggufString)
+	putString(&b.kv, val)
+	b.nKV++
+	return b
+}
+
+func (b *ggufBuilder) u32(key string, val uint32) *ggufBuilder {
+	putString(&b.kv, key)
+	binary.Write(&b.kv, binary.LittleEndian, ggufUint32)
+	binary.Write(&b.kv, binary.LittleEndian, val)
+	b.nKV++
+	return b
+}
+
+func (b *ggufBuilder) u64(key string, val uint64) *ggufBuilder {
+	putString(&b.kv, key)
+	binary.Write(&b.kv, binary.LittleEndian, ggufUint64)
+	binary.Write(&b.kv, binary.LittleEndian, val)
+	b.nKV++
+	return b
+}
+
+func (b *ggufBuilder) strArray(key string, vals ...string) *ggufBuilder {
+	putString(&b.kv, key)
+	binary.Write(&b.kv, binary.LittleEndian, ggufArray)
+	binary.Write(&b.kv, binary.LittleEndian, ggufString)
+	binary.Write(&b.kv, binary.LittleEndian, uint64(len(vals)))
+	for _, v := range vals {
+		putString(&b.kv, v)
+	}
+	b.nKV++
+	return b
+}
+
+func (b *ggufBuilder) tensor(name string, dims ...uint64) *ggufBuilder {
+	putString(&b.tensors, name)
+	binary.Write(&b.tensors, binary.LittleEndian, uint32(len(dims)))
+	for _, d := range dims {
+		binary.Write(&b.tensors, binary.LittleEndian, d)
+	}
+	binary.Write(&b.tensors, binary.LittleEndian, uint32(2)) // Q4_0
+	binary.Write(&b.tensors, binary.LittleEndian, uint64(0)) // offset
+	b.nTensor++
+	return b
+}
+
+func (b *ggufBuilder) bytes() []byte {
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, uint32(ggufMagic))
+	binary.Write(&out, binary.LittleEndian, uint32(3))
+	binary.Write(&out, binary.LittleEndian, b.nTensor)
+	binary.Write(&out, binary.LittleEndian, b.nKV)
+	out.Write(b.kv.Bytes())
+	out.Write(b.tensors.Bytes())
+	return out.Bytes()
+}
+
+func TestParseGGUF_SumsTensorElements(t *testing.T) {
+	data := (&ggufBuilder{}).
+		u32("llama.context_length", 8192). // before general.architecture
+		str("general.architecture", "llama").
+		strArray("tokenizer.ggml.tokens", "<s>", "</s>", "hello").
+		u32("llama.block_count", 2).
+		tensor("token_embd.weight", 4096, 32000).
+		tensor("blk.0.attn_q.weight", 4096, 4096).
+		tensor("output_norm.weight", 4096).
+		bytes()
+
+	meta, err := parseGGUF(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("parseGGUF: %v", err)
+	}
+	if meta.Architecture != "llama" {
+		t.Errorf("Architecture = %q, want llama", meta.Architecture)
+	}
+	if meta.ContextLength != 8192 {
+		t.Errorf("ContextLength = %d, want 8192", meta.ContextLength)
+	}
+	if want := int64(4096*32000 + 4096*4096 + 4096); meta.ParameterCount != want {
+		t.Errorf("ParameterCount = %d, want %d", meta.ParameterCount, want)
+	}
+}
+
+func TestParseGGUF_PrefersRecordedParameterCount(t *testing.T) {
+	data := (&ggufBuilder{}).
+		str("general.architecture", "qwen2").
+		u64("general.parameter_count", 1_543_714_304).
+		u32("qwen2.context_length", 32768).
+		u32("llama.context_length", 4096). // other architecture — ignored
+		tensor("token_embd.weight", 10, 10).
+		bytes()
+
+	meta, err := parseGGUF(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("parseGGUF: %v", err)
+	}
+	if meta.ParameterCount != 1_543_714_304 {
+		t.Errorf("ParameterCount = %d, want 1543714304", meta.ParameterCount)
+	}
+	if meta.ContextLength != 32768 {
+		t.Errorf("ContextLength = %d, want 32768", meta.ContextLength)
+	}
+}
+
+func TestParseGGUF_Invalid(t *testing.T) {
+	valid := (&ggufBuilder{}).str("general.architecture", "llama").tensor("w", 8, 8).bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad magic", append([]byte("GGML"), valid[4:]...)},
+		{"truncated", valid[:len(valid)-6]},
+		{"no architecture", (&ggufBuilder{}).u32("llama.context_length", 2048).bytes()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseGGUF(bytes.NewReader(tt.data)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	if _, err := parseGGUF(bytes.NewReader(valid[:len(valid)-6])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated header: err = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestReadGGUFMetadata_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.gguf")
+	data := (&ggufBuilder{}).str("general.architecture", "phi3").u32("phi3.context_length", 4096).tensor("w", 3, 5).bytes()
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := ReadGGUFMetadata(path)
+	if err != nil {
+		t.Fatalf("ReadGGUFMetadata: %v", err)
+	}
+	if meta.Architecture != "phi3" || meta.ContextLength != 4096 || meta.ParameterCount != 15 {
+		t.Errorf("meta = %+v, want phi3/4096/15", meta)
+	}
+}
@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+// applyProcessLimits sets RLIMIT_DATA / RLIMIT_CPU on a started process via
+// prlimit(2). RLIMIT_DATA (not RLIMIT_AS) is used so GPU drivers that reserve
+// large virtual ranges still work: it bounds private writable memory, which is
+// where --no-mmap model weights and the KV cache live. Exceeding it makes
+// allocations fail and llama-server abort; exceeding the CPU budget delivers
+// SIGXCPU/SIGKILL. Either way the OS stops the process, not the host.
+//
+// Applied right after Start — the window before it is spent in exec and
+// argument parsing, long before the model is allocated.
+func applyProcessLimits(cmd *exec.Cmd, l ProcessLimits) error {
+	if cmd.Process == nil || l.IsZero() {
+		return nil
+	}
+	pid := cmd.Process.Pid
+	if l.MemoryBytes > 0 {
+		if err := prlimit(pid, syscall.RLIMIT_DATA, l.MemoryBytes); err != nil {
+			return fmt.Errorf("set memory limit: %w", err)
+		}
+	}
+	if l.CPUSeconds > 0 {
+		if err := prlimit(pid, syscall.RLIMIT_CPU, l.CPUSeconds); err != nil {
+			return fmt.Errorf("set CPU limit: %w", err)
+		}
+	}
+	return nil
+}
+
+// prlimit sets both the soft and hard limit of resource for pid.
+func prlimit(pid, resource int, limit uint64) error {
+	rl := syscall.Rlimit{Cur: limit, Max: limit}
+	_, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64,
+		uintptr(pid), uintptr(resource), uintptr(unsafe.Pointer(&rl)), 0, 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
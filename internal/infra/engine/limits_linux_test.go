@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// procLimit returns the soft limit column of a /proc/<pid>/limits row.
+func procLimit(t *testing.T, pid int, row string) string {
+	t.Helper()
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/limits", pid))
+	if err != nil {
+		t.Fatalf("read limits: %v", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, row) {
+			fields := strings.Fields(strings.TrimPrefix(line, row))
+			if len(fields) > 0 {
+				return fields[0]
+			}
+		}
+	}
+	t.Fatalf("row %q not found in:\n%s", row, data)
+	return ""
+}
+
+func startSleeper(t *testing.T) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command("sleep", "30")
+	configureProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start sleep: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	return cmd
+}
+
+func TestApplyProcessLimits_SetsRlimits(t *testing.T) {
+	cmd := startSleeper(t)
+
+	limits := ProcessLimits{MemoryBytes: 512 << 20, CPUSeconds: 3600}
+	if err := applyProcessLimits(cmd, limits); err != nil {
+		t.Fatalf("applyProcessLimits() error: %v", err)
+	}
+
+	if got := procLimit(t, cmd.Process.Pid, "Max data size"); got != fmt.Sprint(512<<20) {
+		t.Errorf("Max data size = %s, want %d", got, 512<<20)
+	}
+	if got := procLimit(t, cmd.Process.Pid, "Max cpu time"); got != "3600" {
+		t.Errorf("Max cpu time = %s, want 3600", got)
+	}
+}
+
+func TestApplyProcessLimits_ZeroLeavesDefaults(t *testing.T) {
+	cmd := startSleeper(t)
+	before := procLimit(t, cmd.Process.Pid, "Max data size")
+
+	if err := applyProcessLimits(cmd, ProcessLimits{}); err != nil {
+		t.Fatalf("applyProcessLimits() error: %v", err)
+	}
+	if got := procLimit(t, cmd.Process.Pid, "Max data size"); got != before {
+		t.Errorf("Max data size changed from %s to %s", before, got)
+	}
+}
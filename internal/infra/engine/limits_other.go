@@ -0,0 +1,22 @@
+//go:build !linux
+
+package engine
+
+import (
+	"log"
+	"os/exec"
+	"sync"
+)
+
+var limitsUnsupportedOnce sync.Once
+
+// applyProcessLimits is a no-op outside Linux; configured limits are logged
+// once as unsupported.
+func applyProcessLimits(_ *exec.Cmd, l ProcessLimits) error {
+	if !l.IsZero() {
+		limitsUnsupportedOnce.Do(func() {
+			log.Printf("[engine] subprocess resource limits are not supported on this platform — ignoring")
+		})
+	}
+	return nil
+}
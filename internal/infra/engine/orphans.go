@@ -0,0 +1,199 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ─── Orphan Cleanup ─────────────────────────────────────────────────────────
+// A crashed TuTu leaves its llama-servers running. Every server we start is
+// recorded with our PID in <tutu home>/llama-servers.json; on the next load,
+// servers whose owner is gone are killed. Servers started by other users or
+// by other live TuTu instances are never touched. Both PIDs are recorded
+// with the process's executable and start time, so a PID the OS has since
+// handed to another process is never mistaken for the original.
+
+// serverStateFile is the registry file name inside the TuTu home dir.
+const serverStateFile = "llama-servers.json"
+
+// processInfo identifies a running process beyond its (reusable) PID.
+type processInfo struct {
+	Name    string // executable base name
+	Started string // opaque start-time token; equal only for the same process
+}
+
+// processTable is the slice of the OS process table orphan cleanup needs.
+type processTable interface {
+	// Lookup returns pid's identity, or ok=false if it is not running.
+	Lookup(pid int) (info processInfo, ok bool)
+	Kill(pid int) error
+}
+
+// osProcesses is the real process table.
+type osProcesses struct{}
+
+func (osProcesses) Lookup(pid int) (processInfo, bool) { return lookupProcess(pid) }
+
+func (osProcesses) Kill(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
+
+// serverRecord is one llama-server started by a TuTu instance.
+type serverRecord struct {
+	PID        int    `json:"pid"`
+	Owner      int    `json:"owner"`                 // PID of the TuTu process that started it
+	Exe        string `json:"exe"`                   // executable base name, to detect PID reuse
+	Started    string `json:"started,omitempty"`     // server start time, to detect PID reuse
+	OwnerExe   string `json:"owner_exe,omitempty"`   // owner's executable base name
+	OwnerStart string `json:"owner_start,omitempty"` // owner's start time
+}
+
+// matches reports whether a running process is the one recorded as exe and
+// started. Fields that are unknown on either side (older records, or a
+// start time the OS would not reveal) are not compared.
+func (info processInfo) matches(exe, started string) bool {
+	if exe != "" && !sameExecutable(info.Name, exe) {
+		return false
+	}
+	return started == "" || info.Started == "" || info.Started == started
+}
+
+// serverRegistry tracks this instance's llama-servers in a state file.
+type serverRegistry struct {
+	mu    sync.Mutex
+	path  string
+	self  int
+	procs processTable
+}
+
+func newServerRegistry(tutuHome string) *serverRegistry {
+	return &serverRegistry{
+		path:  filepath.Join(tutuHome, serverStateFile),
+		self:  os.Getpid(),
+		procs: osProcesses{},
+	}
+}
+
+// add records a llama-server started by this instance.
+func (r *serverRegistry) add(pid int, exe string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec := serverRecord{PID: pid, Owner: r.self, Exe: filepath.Base(exe)}
+	if info, ok := r.procs.Lookup(pid); ok {
+		rec.Started = info.Started
+	}
+	if info, ok := r.procs.Lookup(r.self); ok {
+		rec.OwnerExe, rec.OwnerStart = info.Name, info.Started
+	}
+	r.save(append(r.load(), rec))
+}
+
+// remove forgets a llama-server that has been shut down.
+func (r *serverRegistry) remove(pid int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recs := r.load()
+	kept := recs[:0]
+	for _, rec := range recs {
+		if !(rec.PID == pid && rec.Owner == r.self) {
+			kept = append(kept, rec)
+		}
+	}
+	r.save(kept)
+}
+
+// killOrphans kills recorded llama-servers whose owning TuTu process has
+// exited, and returns how many were killed. An owner PID now held by a
+// different process counts as exited; a server PID now held by a different
+// process is dropped without being killed.
+func (r *serverRegistry) killOrphans() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recs := r.load()
+	kept := recs[:0]
+	killed := 0
+	for _, rec := range recs {
+		if rec.Owner == r.self {
+			kept = append(kept, rec)
+			continue
+		}
+		if owner, alive := r.procs.Lookup(rec.Owner); alive && owner.matches(rec.OwnerExe, rec.OwnerStart) {
+			kept = append(kept, rec) // another running instance's server
+			continue
+		}
+		server, running := r.procs.Lookup(rec.PID)
+		if !running || !server.matches(rec.Exe, rec.Started) {
+			continue
+		}
+		if err := r.procs.Kill(rec.PID); err != nil {
+			log.Printf("[engine] could not kill orphaned llama-server (pid %d): %v", rec.PID, err)
+			kept = append(kept, rec)
+			continue
+		}
+		log.Printf("[engine] killed orphaned llama-server (pid %d)", rec.PID)
+		killed++
+	}
+	r.save(kept)
+	return killed
+}
+
+// load reads the state file. A missing or corrupt file is an empty registry.
+func (r *serverRegistry) load() []serverRecord {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("[engine] read %s: %v", r.path, err)
+		}
+		return nil
+	}
+	var recs []serverRecord
+	if err := json.Unmarshal(data, &recs); err != nil {
+		log.Printf("[engine] ignoring corrupt %s: %v", r.path, err)
+		return nil
+	}
+	return recs
+}
+
+// save replaces the state file atomically; an empty registry removes it.
+func (r *serverRegistry) save(recs []serverRecord) {
+	if len(recs) == 0 {
+		if err := os.Remove(r.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("[engine] remove %s: %v", r.path, err)
+		}
+		return
+	}
+	data, err := json.Marshal(recs)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+		log.Printf("[engine] write %s: %v", r.path, err)
+		return
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err == nil {
+		err = os.Rename(tmp, r.path)
+	}
+	if err != nil {
+		log.Printf("[engine] write %s: %v", r.path, err)
+	}
+}
+
+// sameExecutable compares process names, ignoring case and a ".exe" suffix.
+func sameExecutable(a, b string) bool {
+	trim := func(s string) string {
+		return strings.TrimSuffix(strings.ToLower(filepath.Base(s)), ".exe")
+	}
+	return trim(a) == trim(b)
+}
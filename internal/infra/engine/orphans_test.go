@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// stubProcesses is a fake process table: PID → executable name. Every
+// process's start time is "t0" unless overridden in started.
+type stubProcesses struct {
+	running map[int]string
+	started map[int]string
+	killed  []int
+}
+
+func (p *stubProcesses) Lookup(pid int) (processInfo, bool) {
+	name, ok := p.running[pid]
+	if !ok {
+		return processInfo{}, false
+	}
+	started, set := p.started[pid]
+	if !set {
+		started = "t0"
+	}
+	return processInfo{Name: name, Started: started}, true
+}
+
+func (p *stubProcesses) Kill(pid int) error {
+	p.killed = append(p.killed, pid)
+	delete(p.running, pid)
+	return nil
+}
+
+func newStubRegistry(t *testing.T, self int, procs *stubProcesses) *serverRegistry {
+	t.Helper()
+	return &serverRegistry{path: filepath.Join(t.TempDir(), serverStateFile), self: self, procs: procs}
+}
+
+func TestServerRegistry_KillsOnlyThisInstancesOrphans(t *testing.T) {
+	procs := &stubProcesses{running: map[int]string{
+		200: "tutu",             // another live TuTu instance
+		101: "llama-server",     // orphan of crashed instance 100
+		102: "llama-server.exe", // orphan of crashed instance 100
+		201: "llama-server",     // owned by live instance 200
+		301: "bash",             // recorded orphan PID since reused
+		400: "llama-server",     // another user's server — never recorded
+		501: "llama-server",     // started by us (self = 500)
+	}}
+	reg := newStubRegistry(t, 500, procs)
+	reg.save([]serverRecord{
+		{PID: 101, Owner: 100, Exe: "llama-server"},
+		{PID: 102, Owner: 100, Exe: "llama-server.exe"},
+		{PID: 103, Owner: 100, Exe: "llama-server"}, // already gone
+		{PID: 201, Owner: 200, Exe: "llama-server"},
+		{PID: 301, Owner: 300, Exe: "llama-server"},
+		{PID: 501, Owner: 500, Exe: "llama-server"},
+	})
+
+	if n := reg.killOrphans(); n != 2 {
+		t.Errorf("killOrphans() = %d, want 2", n)
+	}
+	sort.Ints(procs.killed)
+	if want := []int{101, 102}; !reflect.DeepEqual(procs.killed, want) {
+		t.Errorf("killed %v, want %v", procs.killed, want)
+	}
+
+	var left []int
+	for _, rec := range reg.load() {
+		left = append(left, rec.PID)
+	}
+	if want := []int{201, 501}; !reflect.DeepEqual(left, want) {
+		t.Errorf("registry after cleanup = %v, want %v", left, want)
+	}
+}
+
+func TestServerRegistry_ReusedPIDs(t *testing.T) {
+	procs := &stubProcesses{
+		running: map[int]string{
+			100: "bash",         // dead instance's PID, reused by another program
+			101: "llama-server", // its orphan
+			200: "tutu",         // dead instance's PID, reused by a newer TuTu
+			201: "llama-server", // its orphan
+			300: "tutu",         // live instance
+			301: "llama-server", // PID reused by a newer llama-server since
+		},
+		started: map[int]string{200: "t1", 301: "t1"},
+	}
+	reg := newStubRegistry(t, 500, procs)
+	reg.save([]serverRecord{
+		{PID: 101, Owner: 100, Exe: "llama-server", Started: "t0", OwnerExe: "tutu", OwnerStart: "t0"},
+		{PID: 201, Owner: 200, Exe: "llama-server", Started: "t0", OwnerExe: "tutu", OwnerStart: "t0"},
+		{PID: 301, Owner: 100, Exe: "llama-server", Started: "t0", OwnerExe: "tutu", OwnerStart: "t0"},
+	})
+
+	if n := reg.killOrphans(); n != 2 {
+		t.Errorf("killOrphans() = %d, want 2", n)
+	}
+	sort.Ints(procs.killed)
+	if want := []int{101, 201}; !reflect.DeepEqual(procs.killed, want) {
+		t.Errorf("killed %v, want %v", procs.killed, want)
+	}
+	if left := reg.load(); len(left) != 0 {
+		t.Errorf("registry after cleanup = %+v, want empty", left)
+	}
+}
+
+func TestServerRegistry_AddRemove(t *testing.T) {
+	procs := &stubProcesses{running: map[int]string{500: "tutu", 501: "llama-server", 502: "llama-server"}}
+	reg := newStubRegistry(t, 500, procs)
+
+	reg.add(501, "/opt/tutu/bin/llama-server")
+	reg.add(502, "/opt/tutu/bin/llama-server")
+	reg.remove(501)
+
+	got := reg.load()
+	want := serverRecord{PID: 502, Owner: 500, Exe: "llama-server", Started: "t0", OwnerExe: "tutu", OwnerStart: "t0"}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("registry = %+v, want only pid 502", got)
+	}
+
+	reg.remove(502)
+	if _, err := os.Stat(reg.path); !os.IsNotExist(err) {
+		t.Errorf("empty registry should remove the state file, stat err = %v", err)
+	}
+	if n := reg.killOrphans(); n != 0 || len(procs.killed) != 0 {
+		t.Errorf("killOrphans on empty registry killed %v", procs.killed)
+	}
+}
+
+func TestServerRegistry_CorruptFileIgnored(t *testing.T) {
+	procs := &stubProcesses{running: map[int]string{1: "llama-server"}}
+	reg := newStubRegistry(t, 500, procs)
+	if err := os.WriteFile(reg.path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if n := reg.killOrphans(); n != 0 {
+		t.Errorf("killOrphans() = %d, want 0 for a corrupt file", n)
+	}
+	reg.add(501, "llama-server")
+	if got := reg.load(); len(got) != 1 {
+		t.Errorf("registry = %+v, want the new record", got)
+	}
+}
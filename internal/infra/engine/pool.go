@@ -6,7 +6,13 @@ package engine
 import (
 	"container/list"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -33,17 +39,99 @@ type ModelHandle interface {
 	Close()
 }
 
+// NameLoader is implemented by backends whose LoadModel takes a model name
+// rather than a local file path (e.g. RemoteBackend). The pool skips path
+// resolution for them.
+type NameLoader interface {
+	LoadsByName() bool
+}
+
+// RoutePolicy picks the backend that serves a model. Returning nil selects
+// the pool's default backend.
+type RoutePolicy func(model string) InferenceBackend
+
+// RouteModels returns a policy that sends the named models to backend and
+// every other model to the pool's default backend.
+func RouteModels(backend InferenceBackend, models ...string) RoutePolicy {
+	routed := make(map[string]bool, len(models))
+	for _, m := range models {
+		routed[m] = true
+	}
+	return func(model string) InferenceBackend {
+		if routed[model] {
+			return backend
+		}
+		return nil
+	}
+}
+
+// CacheReporter is implemented by handles that can report KV cache slot
+// occupancy (e.g. SubprocessHandle).
+type CacheReporter interface {
+	CacheStats() (usedSlots, totalSlots int, err error)
+}
+
+// CapabilityReporter is implemented by handles that know which optional
+// features their inference server supports (e.g. SubprocessHandle).
+type CapabilityReporter interface {
+	Capabilities() (caps ServerCapabilities, known bool)
+}
+
+// EmbeddingReporter is implemented by handles that know whether their
+// inference server was started to serve embeddings (e.g. SubprocessHandle).
+type EmbeddingReporter interface {
+	EmbeddingMode() bool
+}
+
+// ServerCapabilities describes what an inference server build supports.
+type ServerCapabilities struct {
+	Build  string // build identifier reported by the server ("" if unreported)
+	Chat   bool   // OpenAI-compatible chat completions
+	Vision bool   // image inputs in chat messages
+}
+
 // ChatMessage represents a single message in a chat conversation.
 type ChatMessage struct {
-	Role    string `json:"role"`    // "system", "user", "assistant"
-	Content string `json:"content"` // message text
+	Role    string   `json:"role"`    // "system", "user", "assistant"
+	Content string   `json:"content"` // message text
+	Images  []string `json:"-"`       // image URLs or data: URIs; needs a vision-capable server
+}
+
+// MarshalJSON encodes the message in the OpenAI chat format. Messages with
+// images use the content-part array form; plain messages stay a string.
+func (m ChatMessage) MarshalJSON() ([]byte, error) {
+	if len(m.Images) == 0 {
+		type plain ChatMessage
+		return json.Marshal(plain(m))
+	}
+	type imageURL struct {
+		URL string `json:"url"`
+	}
+	type part struct {
+		Type     string    `json:"type"`
+		Text     string    `json:"text,omitempty"`
+		ImageURL *imageURL `json:"image_url,omitempty"`
+	}
+	parts := make([]part, 0, len(m.Images)+1)
+	if m.Content != "" {
+		parts = append(parts, part{Type: "text", Text: m.Content})
+	}
+	for _, img := range m.Images {
+		parts = append(parts, part{Type: "image_url", ImageURL: &imageURL{URL: img}})
+	}
+	return json.Marshal(struct {
+		Role    string `json:"role"`
+		Content []part `json:"content"`
+	}{m.Role, parts})
 }
 
 // LoadOptions configures model loading.
 type LoadOptions struct {
-	NumGPULayers int // -1 = auto, 0 = CPU only, N = specific
-	NumCtx       int // Context window size (default 4096)
-	NumThreads   int // 0 = auto (runtime.NumCPU())
+	NumGPULayers int    // -1 = auto, 0 = CPU only, N = specific
+	NumCtx       int    // Context window size (default 4096)
+	NumThreads   int    // 0 = auto (runtime.NumCPU())
+	LogLevel     string // llama-server log level (see LogLevels); "" = backend default
+	Embedding    bool   // serve embeddings (llama-server --embedding); such servers only embed
 }
 
 // GenerateParams holds sampling parameters.
@@ -52,79 +140,370 @@ type GenerateParams struct {
 	TopP        float32
 	MaxTokens   int
 	Stop        []string
+	Seed        int64 // sampling RNG seed; 0 = server default (random)
+	Strict      bool  // reject out-of-range sampling values instead of clamping
+
+	// System overrides the model's default system prompt for one Chat call;
+	// NoSystem skips system prompt injection entirely.
+	System   string
+	NoSystem bool
+
+	// Timeout bounds the whole request, including the token stream. 0
+	// applies the call's default idle timeout instead: DefaultChatTimeout
+	// for Chat, DefaultGenerateTimeout for Generate.
+	Timeout time.Duration
+}
+
+// DefaultLoadOptions returns the load options for callers without
+// model-specific settings: automatic GPU offload and a 4096-token context.
+func DefaultLoadOptions() LoadOptions {
+	return LoadOptions{NumGPULayers: -1, NumCtx: 4096}
+}
+
+// DefaultGenerateParams returns the sampling defaults shared by every
+// front end: temperature 0.7, top-p 0.9 and at most 2048 new tokens.
+func DefaultGenerateParams() GenerateParams {
+	return GenerateParams{Temperature: 0.7, TopP: 0.9, MaxTokens: 2048}
+}
+
+// Default idle timeouts, applied when GenerateParams.Timeout is 0. A call
+// fails once the server sends nothing for this long — while processing the
+// prompt or between two tokens — but a reply that keeps streaming may run
+// as long as it needs, however slow the hardware.
+const (
+	DefaultChatTimeout     = 2 * time.Minute  // interactive: fail fast on a wedged server
+	DefaultGenerateTimeout = 10 * time.Minute // raw completions, often long batch jobs
+)
+
+// deadline applies p's timeout to a streamed call on ctx: an explicit
+// Timeout bounds the whole call, otherwise idle bounds each wait for the
+// server. touch records server activity, restarting the idle wait; cancel
+// must be called once the call ends.
+func (p GenerateParams) deadline(ctx context.Context, idle time.Duration) (_ context.Context, touch func(), cancel context.CancelFunc) {
+	if p.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+		return ctx, func() {}, cancel
+	}
+	ctx, cancelCause := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(idle, func() {
+		cancelCause(fmt.Errorf("no response for %v: %w", idle, context.DeadlineExceeded))
+	})
+	return ctx, func() { timer.Reset(idle) }, func() {
+		timer.Stop()
+		cancelCause(context.Canceled)
+	}
+}
+
+// touchBody is a response body that reports every read that returns data,
+// so a streamed call's idle timeout restarts with each chunk.
+type touchBody struct {
+	io.ReadCloser
+	touch func()
+}
+
+func (b touchBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.touch()
+	}
+	return n, err
+}
+
+// Valid sampling ranges, matching what OpenAI-compatible clients expect.
+const (
+	MaxTemperature = 2.0
+	MaxTopP        = 1.0
+)
+
+// Sanitize returns params with Temperature clamped to [0, MaxTemperature]
+// and TopP to [0, MaxTopP], logging each adjustment. NaN temperature
+// becomes 0 (greedy) and NaN top-p becomes 1 (disabled). In Strict mode any
+// out-of-range value is rejected with domain.ErrInvalidSampling instead.
+func (p GenerateParams) Sanitize() (GenerateParams, error) {
+	temp, tempOK := clampSampling(p.Temperature, 0, MaxTemperature, 0)
+	if !tempOK {
+		if p.Strict {
+			return p, fmt.Errorf("temperature %v not in [0, %v]: %w", p.Temperature, MaxTemperature, domain.ErrInvalidSampling)
+		}
+		log.Printf("[engine] temperature %v out of range — clamped to %v", p.Temperature, temp)
+		p.Temperature = temp
+	}
+	topP, topPOK := clampSampling(p.TopP, 0, MaxTopP, MaxTopP)
+	if !topPOK {
+		if p.Strict {
+			return p, fmt.Errorf("top_p %v not in [0, %v]: %w", p.TopP, MaxTopP, domain.ErrInvalidSampling)
+		}
+		log.Printf("[engine] top_p %v out of range — clamped to %v", p.TopP, topP)
+		p.TopP = topP
+	}
+	return p, nil
+}
+
+// clampSampling clamps v to [lo, hi], substituting nan for NaN. ok reports
+// whether v was already in range.
+func clampSampling(v, lo, hi, nan float32) (clamped float32, ok bool) {
+	switch {
+	case v != v:
+		return nan, false
+	case v < lo:
+		return lo, false
+	case v > hi:
+		return hi, false
+	default:
+		return v, true
+	}
 }
 
 // ─── Model Pool (LRU + Reference Counting) ──────────────────────────────────
 // Architecture Part V: Hash map + doubly-linked list.
 // All operations O(1). Zero-leak via defer handle.Release().
+//
+// A hot model can run as several replicas (see SetReplicas): Acquire hands
+// out the replica with the fewest active sessions. While every loaded
+// replica is busy and the model is below its count, Acquire still shares
+// the least-loaded one and loads another replica in the background, so no
+// caller waits on a replica load.
+//
+// A replica serves the mode it was loaded in (LoadOptions.Embedding), so a
+// model used both to embed and to chat gets a replica for each; the replica
+// count applies per mode.
 
 // Pool manages loaded models with LRU eviction and reference counting.
 type Pool struct {
 	mu           sync.Mutex
-	models       map[string]*poolEntry
+	models       map[string][]*poolEntry // model name → loaded replicas
 	lru          *list.List
 	maxMem       uint64
 	usedMem      uint64
 	backend      InferenceBackend
+	route        RoutePolicy                       // nil = every model uses backend
 	resolver     func(name string) (string, error) // name → file path
 	idleTimeout  time.Duration
 	reapInterval time.Duration
+
+	variants    func(name string) ([]string, error) // smaller quantizations to retry; nil = no fallback
+	onDowngrade func(name, variant string)
+	downgrades  map[string]string // model name → smaller quantization loaded in its place
+
+	verify    func(name, path string) error // weights integrity check; nil = off
+	onCorrupt func(name string, err error)
+
+	metaMu sync.Mutex
+	meta   map[string]cachedMetadata // resolved path → parsed GGUF header
+
+	promptMu sync.RWMutex
+	prompts  map[string]string // model name → default system prompt
+
+	replicas     map[string]int      // model name → replica count; absent = 1
+	replicaLoads map[replicaKey]bool // replicas being loaded in the background
+
+	results atomic.Pointer[resultCache] // nil = result caching off
+}
+
+// cachedMetadata is a parsed GGUF header, valid while the file is unchanged.
+type cachedMetadata struct {
+	meta    domain.ModelMetadata
+	size    int64
+	modTime time.Time
+}
+
+// replicaKey identifies the replicas of a model in one embedding mode.
+type replicaKey struct {
+	name      string
+	embedding bool
 }
 
 type poolEntry struct {
 	handle   ModelHandle
 	name     string
 	memBytes uint64
-	refCount int32
+	refCount int32 // active sessions: unreleased PoolHandles
 	element  *list.Element
-	lastUsed time.Time
+	lastUsed atomic.Int64 // UnixNano of the last Acquire or Release
+	remote   bool         // loaded by a NameLoader backend, not from a local file
+
+	embedding bool // loaded with LoadOptions.Embedding
 }
 
 // PoolHandle is returned by Acquire. Caller MUST call Release() (use defer).
 type PoolHandle struct {
-	entry *poolEntry
-	pool  *Pool
+	entry    *poolEntry
+	pool     *Pool
+	released atomic.Bool
 }
 
 // NewPool creates a model pool with bounded memory.
 func NewPool(backend InferenceBackend, maxMemBytes uint64, resolver func(string) (string, error)) *Pool {
 	return &Pool{
-		models:       make(map[string]*poolEntry),
+		models:       make(map[string][]*poolEntry),
 		lru:          list.New(),
 		maxMem:       maxMemBytes,
 		backend:      backend,
 		resolver:     resolver,
 		idleTimeout:  5 * time.Minute,
 		reapInterval: 30 * time.Second,
+		meta:         make(map[string]cachedMetadata),
+		prompts:      make(map[string]string),
+		replicas:     make(map[string]int),
+		replicaLoads: make(map[replicaKey]bool),
+		downgrades:   make(map[string]string),
 	}
 }
 
 // Acquire loads or retrieves a cached model. Returns a handle with ref count.
 // Caller MUST call handle.Release() when done (use defer).
 func (p *Pool) Acquire(name string, opts LoadOptions) (*PoolHandle, error) {
+	p.mu.Lock()
+	if h := p.acquireCached(name, opts); h != nil {
+		p.mu.Unlock()
+		return h, nil
+	}
+	var check func(name string) (string, error)
+	if p.verify != nil && !loadsByName(p.backendFor(name)) {
+		check = p.pathChecker()
+	}
+	p.mu.Unlock()
+
+	// Verify the weights before locking the pool for the load, so hashing
+	// a multi-gigabyte file does not stall every other Acquire. A clean
+	// result is cached by the check, so load repeats it cheaply.
+	if check != nil {
+		if _, err := check(name); err != nil {
+			return nil, fmt.Errorf("resolve model %q: %w", name, err)
+		}
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	if h := p.acquireCached(name, opts); h != nil {
+		return h, nil // loaded by another caller meanwhile
+	}
+	entry, err := p.load(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &PoolHandle{entry: entry, pool: p}, nil
+}
+
+// acquireCached returns a handle on a loaded replica of name or, if name
+// was downgraded for lack of memory, of the smaller quantization loaded in
+// its place. nil if neither is loaded; once the quantization is unloaded,
+// the next load tries the full model again. Caller holds p.mu.
+func (p *Pool) acquireCached(name string, opts LoadOptions) *PoolHandle {
+	if h := p.acquireLoaded(name, opts); h != nil {
+		return h
+	}
+	if v, ok := p.downgrades[name]; ok {
+		if h := p.acquireLoaded(v, opts); h != nil {
+			return h
+		}
+		delete(p.downgrades, name)
+	}
+	return nil
+}
 
-	// Cache hit — O(1)
-	if entry, ok := p.models[name]; ok {
-		atomic.AddInt32(&entry.refCount, 1)
-		entry.lastUsed = time.Now()
-		p.lru.MoveToFront(entry.element)
-		return &PoolHandle{entry: entry, pool: p}, nil
+// acquireLoaded returns a handle on the least-loaded replica of name, or
+// nil if name is not loaded. When every loaded replica is busy and the
+// replica count allows, it starts loading one more in the background.
+// Caller holds p.mu.
+func (p *Pool) acquireLoaded(name string, opts LoadOptions) *PoolHandle {
+	entry := p.leastLoaded(name, opts.Embedding)
+	if entry == nil {
+		return nil
 	}
+	key := replicaKey{name: name, embedding: opts.Embedding}
+	if atomic.LoadInt32(&entry.refCount) > 0 && !p.replicaLoads[key] &&
+		p.loadedReplicas(name, opts.Embedding) < p.replicaCount(name) {
+		p.replicaLoads[key] = true
+		go p.loadReplica(name, opts, p.backendFor(name), p.pathChecker())
+	}
+	atomic.AddInt32(&entry.refCount, 1)
+	entry.lastUsed.Store(time.Now().UnixNano())
+	p.lru.MoveToFront(entry.element)
+	return &PoolHandle{entry: entry, pool: p}
+}
 
-	// Resolve name → file path
-	path, err := p.resolver(name)
+// loadReplica loads one more replica of name without holding p.mu and adds
+// it to the pool idle, ready for the next Acquire. The replica is dropped
+// if the model was unloaded or its replica count lowered meanwhile.
+func (p *Pool) loadReplica(name string, opts LoadOptions, backend InferenceBackend, check func(name string) (string, error)) {
+	ref := name
+	remote := loadsByName(backend)
+	var handle ModelHandle
+	var err error
+	if !remote {
+		ref, err = check(name)
+	}
+	if err == nil {
+		handle, err = backend.LoadModel(ref, opts)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.replicaLoads, replicaKey{name: name, embedding: opts.Embedding})
 	if err != nil {
-		return nil, fmt.Errorf("resolve model %q: %w", name, err)
+		log.Printf("[engine] load replica of %s: %v", name, err)
+		return
+	}
+	if n := p.loadedReplicas(name, opts.Embedding); n == 0 || n >= p.replicaCount(name) {
+		handle.Close()
+		return
+	}
+	if _, err := p.insert(name, handle, remote, opts.Embedding, 0); err != nil {
+		log.Printf("[engine] load replica of %s: %v", name, err)
+	}
+}
+
+// backendFor returns the backend that serves name. Caller holds p.mu.
+func (p *Pool) backendFor(name string) InferenceBackend {
+	if p.route != nil {
+		if b := p.route(name); b != nil {
+			return b
+		}
+	}
+	return p.backend
+}
+
+// load loads a new replica of name with one active session. Caller holds
+// p.mu.
+func (p *Pool) load(name string, opts LoadOptions) (*poolEntry, error) {
+	backend := p.backendFor(name)
+
+	// Resolve name → file path, unless the backend loads by name
+	ref := name
+	remote := loadsByName(backend)
+	if !remote {
+		path, err := p.resolvePath(name)
+		if err != nil {
+			return nil, fmt.Errorf("resolve model %q: %w", name, err)
+		}
+		ref = path
 	}
 
-	// Load model
-	handle, err := p.backend.LoadModel(path, opts)
+	// Load model. If it does not fit, unload idle models to make room,
+	// then fall back to a smaller quantization.
+	handle, err := backend.LoadModel(ref, opts)
+	if err != nil && !remote && errors.Is(err, domain.ErrInsufficientMemory) {
+		handle, err = p.retryEvicting(backend, ref, opts, err)
+		if err != nil && errors.Is(err, domain.ErrInsufficientMemory) {
+			entry, err := p.loadSmallerQuant(backend, name, opts, err)
+			if err != nil {
+				return nil, fmt.Errorf("load model %q: %w", name, err)
+			}
+			return entry, nil
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("load model %q: %w", name, err)
 	}
 
+	return p.insert(name, handle, remote, opts.Embedding, 1)
+}
+
+// insert adds a loaded handle to the pool as a replica of name with refs
+// active sessions, evicting idle models to fit. The handle is closed if it
+// cannot fit. Caller holds p.mu.
+func (p *Pool) insert(name string, handle ModelHandle, remote, embedding bool, refs int32) (*poolEntry, error) {
 	memNeeded := handle.MemoryBytes()
 
 	// Evict LRU models if needed to fit
@@ -139,14 +518,250 @@ func (p *Pool) Acquire(name string, opts LoadOptions) (*PoolHandle, error) {
 		handle:   handle,
 		name:     name,
 		memBytes: memNeeded,
-		refCount: 1,
-		lastUsed: time.Now(),
+		refCount: refs,
+		remote:   remote,
+
+		embedding: embedding,
 	}
+	entry.lastUsed.Store(time.Now().UnixNano())
 	entry.element = p.lru.PushFront(entry)
-	p.models[name] = entry
+	p.models[name] = append(p.models[name], entry)
 	p.usedMem += memNeeded
 
-	return &PoolHandle{entry: entry, pool: p}, nil
+	return entry, nil
+}
+
+// leastLoaded returns the replica of name loaded in the given embedding mode
+// with the fewest active sessions, preferring the one used longest ago so
+// equally loaded replicas take turns. nil if no such replica is loaded.
+// Caller holds p.mu.
+func (p *Pool) leastLoaded(name string, embedding bool) *poolEntry {
+	var best *poolEntry
+	for _, entry := range p.models[name] {
+		if entry.embedding != embedding {
+			continue
+		}
+		if best == nil {
+			best = entry
+			continue
+		}
+		refs, bestRefs := atomic.LoadInt32(&entry.refCount), atomic.LoadInt32(&best.refCount)
+		if refs < bestRefs || refs == bestRefs && entry.lastUsed.Load() < best.lastUsed.Load() {
+			best = entry
+		}
+	}
+	return best
+}
+
+// SetReplicas sets how many copies of a model the pool may load to serve
+// concurrent sessions. Replicas are loaded on demand in the background, one
+// at a time while every loaded copy is busy, and count against the pool's memory limit like any
+// model. Lowering the count unloads idle surplus replicas at once and busy
+// ones once their sessions end and the idle reaper runs. count <= 1 means a
+// single copy.
+func (p *Pool) SetReplicas(ref string, count int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if count <= 1 {
+		delete(p.replicas, ref)
+	} else {
+		p.replicas[ref] = count
+	}
+	p.trimReplicas(ref)
+}
+
+// loadedReplicas returns how many replicas of name are loaded in the given
+// embedding mode. Caller holds p.mu.
+func (p *Pool) loadedReplicas(name string, embedding bool) int {
+	n := 0
+	for _, entry := range p.models[name] {
+		if entry.embedding == embedding {
+			n++
+		}
+	}
+	return n
+}
+
+// replicaCount returns the number of replicas allowed for name in each
+// embedding mode. Caller holds p.mu.
+func (p *Pool) replicaCount(name string) int {
+	if n, ok := p.replicas[name]; ok {
+		return n
+	}
+	return 1
+}
+
+// trimReplicas unloads idle replicas of name beyond its replica count.
+// Caller holds p.mu.
+func (p *Pool) trimReplicas(name string) {
+	for _, entry := range slices.Clone(p.models[name]) {
+		if p.loadedReplicas(name, entry.embedding) <= p.replicaCount(name) {
+			continue
+		}
+		if atomic.LoadInt32(&entry.refCount) == 0 {
+			p.unload(entry)
+		}
+	}
+}
+
+// unload closes a replica and removes it from the pool. Caller holds p.mu.
+func (p *Pool) unload(entry *poolEntry) {
+	entry.handle.Close()
+	p.lru.Remove(entry.element)
+	replicas := slices.DeleteFunc(p.models[entry.name], func(e *poolEntry) bool { return e == entry })
+	if len(replicas) == 0 {
+		delete(p.models, entry.name)
+	} else {
+		p.models[entry.name] = replicas
+	}
+	p.usedMem -= entry.memBytes
+}
+
+// SetRoutePolicy chooses a backend per model, e.g. to run some models on a
+// remote node. Models already loaded keep their backend until unloaded.
+// nil routes every model to the default backend.
+func (p *Pool) SetRoutePolicy(policy RoutePolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.route = policy
+}
+
+// SetIdleTimeout sets how long a model with no active sessions stays loaded
+// before IdleReaper unloads it. d <= 0 keeps the current timeout.
+func (p *Pool) SetIdleTimeout(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.idleTimeout = d
+}
+
+// SetQuantFallback enables retrying a load that fails for lack of memory
+// (domain.ErrInsufficientMemory) with a smaller quantization of the same
+// model, once unloading idle models has not made room. variants lists the
+// installed candidates for a model name, best first, as names the resolver
+// accepts. The variant is pooled under its own name and serves later
+// requests for the model while it stays loaded; PoolHandle.Name reports it
+// to callers. onDowngrade, if non-nil, is told which variant was loaded.
+// It runs while the pool is locked and must not call back into the pool.
+// nil variants disables the fallback.
+func (p *Pool) SetQuantFallback(variants func(name string) ([]string, error), onDowngrade func(name, variant string)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.variants = variants
+	p.onDowngrade = onDowngrade
+}
+
+// retryEvicting retries a load that failed with cause, a memory failure,
+// unloading one idle model before each attempt until the load succeeds,
+// fails for another reason, or no idle model is left. Caller holds p.mu.
+func (p *Pool) retryEvicting(backend InferenceBackend, ref string, opts LoadOptions, cause error) (ModelHandle, error) {
+	for p.evictOne() {
+		handle, err := backend.LoadModel(ref, opts)
+		if err == nil || !errors.Is(err, domain.ErrInsufficientMemory) {
+			return handle, err
+		}
+		cause = err
+	}
+	return nil, cause
+}
+
+// loadSmallerQuant tries each smaller quantization of name in turn after
+// cause, a memory failure, reusing one that is already loaded. It stops at
+// the first variant that loads or fails for another reason, and returns
+// the variant's replica with one active session. Caller holds p.mu; it is
+// released while each variant's file is verified.
+func (p *Pool) loadSmallerQuant(backend InferenceBackend, name string, opts LoadOptions, cause error) (*poolEntry, error) {
+	if p.variants == nil {
+		return nil, cause
+	}
+	variants, err := p.variants(name)
+	if err != nil {
+		log.Printf("[engine] list quantizations of %s: %v", name, err)
+		return nil, cause
+	}
+	for _, v := range variants {
+		if h := p.acquireLoaded(v, opts); h != nil {
+			p.downgrades[name] = v
+			return h.entry, nil
+		}
+		check := p.pathChecker()
+		p.mu.Unlock()
+		path, err := check(v)
+		p.mu.Lock()
+		if err != nil {
+			continue
+		}
+		handle, err := backend.LoadModel(path, opts)
+		if err != nil {
+			if !errors.Is(err, domain.ErrInsufficientMemory) {
+				return nil, fmt.Errorf("fall back to %s: %w", v, err)
+			}
+			cause = err
+			continue
+		}
+		entry, err := p.insert(v, handle, false, opts.Embedding, 1)
+		if err != nil {
+			return nil, fmt.Errorf("fall back to %s: %w", v, err)
+		}
+		log.Printf("[engine] %s does not fit in memory, loaded %s instead", name, v)
+		p.downgrades[name] = v
+		if p.onDowngrade != nil {
+			p.onDowngrade(name, v)
+		}
+		return entry, nil
+	}
+	return nil, cause
+}
+
+// SetIntegrityCheck verifies each model file before it is loaded. verify
+// receives the model name and resolved path and returns an error wrapping
+// domain.ErrModelCorrupted if the file no longer matches its recorded
+// checksum; the load is then refused, and onCorrupt, if non-nil, is told so
+// it can raise a self-heal incident. verify runs without the pool lock and
+// may hash the whole file, so it should cache clean results; it is called
+// again, under the lock, right before the load. nil verify disables the
+// check.
+func (p *Pool) SetIntegrityCheck(verify func(name, path string) error, onCorrupt func(name string, err error)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.verify = verify
+	p.onCorrupt = onCorrupt
+}
+
+// resolvePath resolves name to its file and runs the integrity check, if
+// any. Caller holds p.mu.
+func (p *Pool) resolvePath(name string) (string, error) {
+	return p.pathChecker()(name)
+}
+
+// pathChecker returns resolvePath bound to the current resolver and
+// integrity check. The returned function does not touch the pool, so it
+// may run without p.mu. Caller holds p.mu.
+func (p *Pool) pathChecker() func(name string) (string, error) {
+	resolver, verify, onCorrupt := p.resolver, p.verify, p.onCorrupt
+	return func(name string) (string, error) {
+		path, err := resolver(name)
+		if err != nil || verify == nil {
+			return path, err
+		}
+		if err := verify(name, path); err != nil {
+			if errors.Is(err, domain.ErrModelCorrupted) {
+				log.Printf("[engine] %s failed its integrity check: %v", name, err)
+				if onCorrupt != nil {
+					onCorrupt(name, err)
+				}
+			}
+			return "", err
+		}
+		return path, nil
+	}
+}
+
+func loadsByName(b InferenceBackend) bool {
+	nl, ok := b.(NameLoader)
+	return ok && nl.LoadsByName()
 }
 
 // evictOne removes the least-recently-used model with refCount == 0.
@@ -154,57 +769,237 @@ func (p *Pool) evictOne() bool {
 	for e := p.lru.Back(); e != nil; e = e.Prev() {
 		entry := e.Value.(*poolEntry)
 		if atomic.LoadInt32(&entry.refCount) == 0 {
-			entry.handle.Close()
-			p.lru.Remove(e)
-			delete(p.models, entry.name)
-			p.usedMem -= entry.memBytes
+			p.unload(entry)
 			return true
 		}
 	}
 	return false
 }
 
+// Name returns the model serving the handle: the name it was acquired by,
+// or the smaller quantization loaded in its place for lack of memory.
+func (h *PoolHandle) Name() string {
+	return h.entry.name
+}
+
 // Model returns the underlying model handle.
-func (h *PoolHandle) Model() ModelHandle { return h.entry.handle }
+func (h *PoolHandle) Model() ModelHandle {
+	return pooledHandle{ModelHandle: h.entry.handle, pool: h.pool, name: h.entry.name}
+}
 
 // Release decrements the reference count. Must be called when done.
+// Releasing the last active session starts the model's idle timeout, so a
+// model shared by several sessions stays loaded until all of them finish
+// and it then sits unused for the full timeout. Extra calls are no-ops.
+//
+// Release never takes the pool lock, so it cannot stall behind an Acquire
+// that is loading a model. The use time is stamped before the count drops,
+// so the idle reaper never sees a released entry with a stale time.
 func (h *PoolHandle) Release() {
+	if h.released.Swap(true) {
+		return
+	}
+	h.entry.lastUsed.Store(time.Now().UnixNano())
 	atomic.AddInt32(&h.entry.refCount, -1)
 }
 
-// LoadedModels returns info about all models currently in the pool.
+// LoadedModels returns info about all models currently in the pool. A
+// model with several replicas is listed once, with their combined size and
+// the latest expiry.
 func (p *Pool) LoadedModels() []domain.LoadedModel {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	result := make([]domain.LoadedModel, 0, len(p.models))
-	for name, entry := range p.models {
+	for name, replicas := range p.models {
 		processor := "CPU"
-		result = append(result, domain.LoadedModel{
-			Name:      name,
-			SizeBytes: int64(entry.memBytes),
-			Processor: processor,
-			ExpiresAt: entry.lastUsed.Add(p.idleTimeout),
-		})
+		if replicas[0].remote {
+			processor = "remote"
+		}
+		lm := domain.LoadedModel{Name: name, Processor: processor}
+		for _, entry := range replicas {
+			lm.SizeBytes += int64(entry.memBytes)
+			if exp := time.Unix(0, entry.lastUsed.Load()).Add(p.idleTimeout); exp.After(lm.ExpiresAt) {
+				lm.ExpiresAt = exp
+			}
+		}
+		result = append(result, lm)
 	}
 	return result
 }
 
+// CacheStats reports KV cache slot occupancy for a loaded model, summed over
+// its replicas, so callers can avoid routing new sessions to a model with no
+// free slots. Returns domain.ErrModelNotLoaded if the model is not in the
+// pool.
+func (p *Pool) CacheStats(name string) (usedSlots, totalSlots int, err error) {
+	p.mu.Lock()
+	replicas := slices.Clone(p.models[name])
+	p.mu.Unlock()
+	if len(replicas) == 0 {
+		return 0, 0, fmt.Errorf("cache stats for %q: %w", name, domain.ErrModelNotLoaded)
+	}
+
+	for _, entry := range replicas {
+		reporter, ok := entry.handle.(CacheReporter)
+		if !ok {
+			return 0, 0, fmt.Errorf("cache stats for %q: not supported by backend", name)
+		}
+		used, total, err := reporter.CacheStats()
+		if err != nil {
+			return 0, 0, err
+		}
+		usedSlots += used
+		totalSlots += total
+	}
+	return usedSlots, totalSlots, nil
+}
+
+// Capabilities reports what the inference server behind a loaded model
+// supports. known is false when the backend cannot tell (callers should then
+// assume the default feature set). Returns domain.ErrModelNotLoaded if the
+// model is not in the pool.
+func (p *Pool) Capabilities(name string) (caps ServerCapabilities, known bool, err error) {
+	p.mu.Lock()
+	entry := p.leastLoaded(name, false)
+	if entry == nil {
+		entry = p.leastLoaded(name, true)
+	}
+	p.mu.Unlock()
+	if entry == nil {
+		return ServerCapabilities{}, false, fmt.Errorf("capabilities for %q: %w", name, domain.ErrModelNotLoaded)
+	}
+
+	reporter, ok := entry.handle.(CapabilityReporter)
+	if !ok {
+		return ServerCapabilities{}, false, nil
+	}
+	caps, known = reporter.Capabilities()
+	return caps, known, nil
+}
+
+// ─── System Prompts ─────────────────────────────────────────────────────────
+
+// SetSystemPrompt sets a model's default system prompt, which Chat prepends
+// when the caller supplies no system message. An empty prompt removes it.
+// Takes effect for already-loaded models too.
+func (p *Pool) SetSystemPrompt(name, prompt string) {
+	p.promptMu.Lock()
+	defer p.promptMu.Unlock()
+	if prompt == "" {
+		delete(p.prompts, name)
+		return
+	}
+	p.prompts[name] = prompt
+}
+
+// SystemPrompt returns a model's default system prompt ("" if none).
+func (p *Pool) SystemPrompt(name string) string {
+	p.promptMu.RLock()
+	defer p.promptMu.RUnlock()
+	return p.prompts[name]
+}
+
+// pooledHandle is the ModelHandle handed out by PoolHandle.Model. Chat
+// applies the model's default system prompt, Generate and Chat go through
+// the result cache, and Embed rejects models not loaded for embeddings;
+// everything else passes through.
+type pooledHandle struct {
+	ModelHandle
+	pool *Pool
+	name string
+}
+
+func (h pooledHandle) Generate(ctx context.Context, prompt string, params GenerateParams) (<-chan domain.Token, error) {
+	return h.pool.cachedRun(ctx, h.name, "generate", prompt, params, func() (<-chan domain.Token, error) {
+		return h.ModelHandle.Generate(ctx, prompt, params)
+	})
+}
+
+func (h pooledHandle) Chat(ctx context.Context, messages []ChatMessage, params GenerateParams) (<-chan domain.Token, error) {
+	messages = withSystemPrompt(messages, h.pool.SystemPrompt(h.name), params)
+	return h.pool.cachedRun(ctx, h.name, "chat", messages, params, func() (<-chan domain.Token, error) {
+		return h.ModelHandle.Chat(ctx, messages, params)
+	})
+}
+
+// Embed fails with domain.ErrNotEmbeddingModel when the model's server was
+// started for generation: llama-server only embeds with --embedding.
+func (h pooledHandle) Embed(ctx context.Context, input []string) ([][]float32, error) {
+	if r, ok := h.ModelHandle.(EmbeddingReporter); ok && !r.EmbeddingMode() {
+		return nil, fmt.Errorf("%q is loaded for generation; unload it and reload with LoadOptions.Embedding, or use a dedicated embedding model: %w",
+			h.name, domain.ErrNotEmbeddingModel)
+	}
+	return h.ModelHandle.Embed(ctx, input)
+}
+
+// withSystemPrompt prepends a system message unless messages already carry
+// one. params.System takes precedence over def; params.NoSystem disables
+// injection.
+func withSystemPrompt(messages []ChatMessage, def string, params GenerateParams) []ChatMessage {
+	if params.NoSystem {
+		return messages
+	}
+	prompt := def
+	if params.System != "" {
+		prompt = params.System
+	}
+	if prompt == "" {
+		return messages
+	}
+	for _, m := range messages {
+		if m.Role == "system" {
+			return messages
+		}
+	}
+	return append([]ChatMessage{{Role: "system", Content: prompt}}, messages...)
+}
+
+// ModelInfo returns the architecture, parameter count, and trained context
+// length recorded in a model's GGUF header. The model need not be loaded;
+// results are cached until the file changes.
+func (p *Pool) ModelInfo(ref string) (domain.ModelMetadata, error) {
+	path, err := p.resolver(ref)
+	if err != nil {
+		return domain.ModelMetadata{}, fmt.Errorf("resolve model %q: %w", ref, err)
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return domain.ModelMetadata{}, fmt.Errorf("model info %q: %w", ref, err)
+	}
+
+	p.metaMu.Lock()
+	defer p.metaMu.Unlock()
+	if c, ok := p.meta[path]; ok && c.size == stat.Size() && c.modTime.Equal(stat.ModTime()) {
+		return c.meta, nil
+	}
+
+	meta, err := ReadGGUFMetadata(path)
+	if err != nil {
+		return domain.ModelMetadata{}, fmt.Errorf("model info %q: %w", ref, err)
+	}
+	p.meta[path] = cachedMetadata{meta: meta, size: stat.Size(), modTime: stat.ModTime()}
+	return meta, nil
+}
+
 // UnloadAll releases all models from the pool.
 func (p *Pool) UnloadAll() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	for name, entry := range p.models {
-		entry.handle.Close()
-		p.lru.Remove(entry.element)
+	for name, replicas := range p.models {
+		for _, entry := range replicas {
+			entry.handle.Close()
+			p.lru.Remove(entry.element)
+		}
 		delete(p.models, name)
 	}
 	p.usedMem = 0
 	return nil
 }
 
-// IdleReaper runs in background, unloading models idle > timeout.
+// IdleReaper runs in background, unloading models with no active sessions
+// that have been idle longer than the idle timeout.
 func (p *Pool) IdleReaper(ctx context.Context) {
 	ticker := time.NewTicker(p.reapInterval)
 	defer ticker.Stop()
@@ -214,17 +1009,22 @@ func (p *Pool) IdleReaper(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			p.mu.Lock()
-			now := time.Now()
-			for name, entry := range p.models {
-				if now.Sub(entry.lastUsed) > p.idleTimeout && atomic.LoadInt32(&entry.refCount) == 0 {
-					entry.handle.Close()
-					p.lru.Remove(entry.element)
-					delete(p.models, name)
-					p.usedMem -= entry.memBytes
-				}
+			p.reapIdle(time.Now())
+		}
+	}
+}
+
+// reapIdle unloads every replica without active sessions whose idle
+// timeout has passed at now, and idle replicas beyond a model's count.
+func (p *Pool) reapIdle(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for name, replicas := range p.models {
+		for _, entry := range slices.Clone(replicas) {
+			if now.Sub(time.Unix(0, entry.lastUsed.Load())) > p.idleTimeout && atomic.LoadInt32(&entry.refCount) == 0 {
+				p.unload(entry)
 			}
-			p.mu.Unlock()
 		}
+		p.trimReplicas(name)
 	}
 }
@@ -2,9 +2,18 @@ package engine
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // ─── Mock Backend Tests ─────────────────────────────────────────────────────
@@ -220,6 +229,147 @@ func TestPool_ConcurrentAcquire(t *testing.T) {
 	}
 }
 
+func TestPool_ReplicasShareLoad(t *testing.T) {
+	pool := newTestPool()
+	pool.SetReplicas("hot-model", 2)
+
+	// A second session on the busy model starts loading a replica.
+	first, _ := pool.Acquire("hot-model", LoadOptions{})
+	second, _ := pool.Acquire("hot-model", LoadOptions{})
+	waitReplicas(t, pool, "hot-model", 2)
+	first.Release()
+	second.Release()
+
+	// Hold sessions open so every Acquire sees the others as busy.
+	count := make(map[ModelHandle]int)
+	var held []*PoolHandle
+	for range 8 {
+		h, err := pool.Acquire("hot-model", LoadOptions{})
+		if err != nil {
+			t.Fatalf("Acquire() error: %v", err)
+		}
+		held = append(held, h)
+		count[h.entry.handle]++
+	}
+	for _, h := range held {
+		h.Release()
+	}
+
+	if len(count) != 2 {
+		t.Fatalf("requests used %d handles, want 2 replicas", len(count))
+	}
+	for handle, n := range count {
+		if n != 4 {
+			t.Errorf("replica %p served %d of 8 requests, want the load spread", handle, n)
+		}
+	}
+	loaded := pool.LoadedModels()
+	if len(loaded) != 1 || loaded[0].SizeBytes != 2*100*1024*1024 {
+		t.Errorf("LoadedModels() = %+v, want one entry sized for two replicas", loaded)
+	}
+}
+
+func TestPool_ReplicasLoadOnlyWhenBusy(t *testing.T) {
+	pool := newTestPool()
+	pool.SetReplicas("hot-model", 2)
+
+	h1, _ := pool.Acquire("hot-model", LoadOptions{})
+	h1.Release()
+	h2, _ := pool.Acquire("hot-model", LoadOptions{})
+	if h1.entry != h2.entry {
+		t.Error("an idle replica should be reused before loading another")
+	}
+
+	h3, _ := pool.Acquire("hot-model", LoadOptions{})
+	if h3.entry != h2.entry {
+		t.Error("a busy model should share its replica while another loads")
+	}
+	waitReplicas(t, pool, "hot-model", 2)
+	h4, _ := pool.Acquire("hot-model", LoadOptions{})
+	if h4.entry == h2.entry {
+		t.Error("the loaded replica should take the next session")
+	}
+	h5, _ := pool.Acquire("hot-model", LoadOptions{})
+	waitReplicas(t, pool, "hot-model", 2)
+	h2.Release()
+	h3.Release()
+	h4.Release()
+	h5.Release()
+
+	pool.SetReplicas("hot-model", 1)
+	if n := len(pool.models["hot-model"]); n != 1 {
+		t.Errorf("loaded replicas after SetReplicas(1) = %d, want idle surplus unloaded", n)
+	}
+}
+
+// gatedBackend blocks every load after the first until gate is closed.
+type gatedBackend struct {
+	*MockBackend
+	mu    sync.Mutex
+	loads int
+	gate  chan struct{}
+}
+
+func (b *gatedBackend) LoadModel(path string, opts LoadOptions) (ModelHandle, error) {
+	b.mu.Lock()
+	b.loads++
+	n := b.loads
+	b.mu.Unlock()
+	if n > 1 {
+		<-b.gate
+	}
+	return b.MockBackend.LoadModel(path, opts)
+}
+
+func TestPool_ReplicaLoadsInBackground(t *testing.T) {
+	backend := &gatedBackend{MockBackend: NewMockBackend(), gate: make(chan struct{})}
+	pool := NewPool(backend, 1<<30, func(name string) (string, error) { return "/fake/path/" + name, nil })
+	pool.SetReplicas("hot-model", 2)
+
+	h1, err := pool.Acquire("hot-model", LoadOptions{})
+	if err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+	defer h1.Release()
+
+	done := make(chan *PoolHandle)
+	go func() {
+		h, _ := pool.Acquire("hot-model", LoadOptions{})
+		done <- h
+	}()
+	select {
+	case h2 := <-done:
+		defer h2.Release()
+		if h2.entry != h1.entry {
+			t.Error("Acquire should share the loaded replica while another loads")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Acquire waited for the replica load")
+	}
+
+	close(backend.gate)
+	waitReplicas(t, pool, "hot-model", 2)
+}
+
+// waitReplicas waits until no replica of name is loading and want replicas
+// are loaded.
+func waitReplicas(t *testing.T, pool *Pool, name string, want int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		pool.mu.Lock()
+		n, loading := len(pool.models[name]), len(pool.replicaLoads)
+		pool.mu.Unlock()
+		if n == want && loading == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("loaded replicas of %s = %d (%d loading), want %d", name, n, loading, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
 func TestPool_IdleReaper(t *testing.T) {
 	backend := NewMockBackend()
 	resolver := func(name string) (string, error) {
@@ -249,6 +399,91 @@ func TestPool_IdleReaper(t *testing.T) {
 	}
 }
 
+// slowBackend blocks loading slowPath until release is closed.
+type slowBackend struct {
+	MockBackend
+	slowPath string
+	loading  chan struct{}
+	release  chan struct{}
+}
+
+func (b *slowBackend) LoadModel(path string, opts LoadOptions) (ModelHandle, error) {
+	if path == b.slowPath {
+		close(b.loading)
+		<-b.release
+	}
+	return b.MockBackend.LoadModel(path, opts)
+}
+
+func TestPool_ReleaseDuringLoad(t *testing.T) {
+	backend := &slowBackend{
+		slowPath: "/fake/path/slow-model",
+		loading:  make(chan struct{}),
+		release:  make(chan struct{}),
+	}
+	pool := NewPool(backend, 1<<30, func(name string) (string, error) { return "/fake/path/" + name, nil })
+
+	h, err := pool.Acquire("loaded-model", LoadOptions{})
+	if err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+
+	loaded := make(chan struct{})
+	go func() {
+		defer close(loaded)
+		if other, err := pool.Acquire("slow-model", LoadOptions{}); err == nil {
+			other.Release()
+		}
+	}()
+	<-backend.loading // the second Acquire now holds the pool lock
+
+	released := make(chan struct{})
+	go func() {
+		h.Release()
+		close(released)
+	}()
+	select {
+	case <-released:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Release blocked behind a model load")
+	}
+	close(backend.release)
+	<-loaded
+}
+
+func TestPool_IdleReaperWaitsForSharedSessions(t *testing.T) {
+	pool := newTestPool()
+	pool.SetIdleTimeout(time.Minute)
+
+	a, err := pool.Acquire("shared-model", LoadOptions{})
+	if err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+	b, err := pool.Acquire("shared-model", LoadOptions{})
+	if err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+
+	a.Release()
+	a.Release() // a double release must not drop b's reference
+	pool.reapIdle(time.Now().Add(time.Hour))
+	if len(pool.LoadedModels()) != 1 {
+		t.Fatal("model unloaded while a session was still active")
+	}
+
+	// The last release starts the idle timeout; it is not measured from
+	// the last Acquire.
+	b.Release()
+	pool.reapIdle(time.Now().Add(30 * time.Second))
+	if len(pool.LoadedModels()) != 1 {
+		t.Fatal("model unloaded before the idle timeout elapsed")
+	}
+	pool.reapIdle(time.Now().Add(2 * time.Minute))
+	if len(pool.LoadedModels()) != 0 {
+		t.Error("model should be unloaded once both sessions finished and the timeout elapsed")
+	}
+}
+
 func TestPool_GenerateThroughHandle(t *testing.T) {
 	pool := newTestPool()
 
@@ -275,3 +510,553 @@ func TestPool_GenerateThroughHandle(t *testing.T) {
 		t.Error("should generate at least one token")
 	}
 }
+
+// ─── System Prompt Tests ────────────────────────────────────────────────────
+
+// recordingHandle captures the messages each Chat call receives.
+type recordingHandle struct {
+	*MockModelHandle
+	mu   sync.Mutex
+	sent [][]ChatMessage
+}
+
+func (h *recordingHandle) Chat(ctx context.Context, messages []ChatMessage, params GenerateParams) (<-chan domain.Token, error) {
+	h.mu.Lock()
+	h.sent = append(h.sent, messages)
+	h.mu.Unlock()
+	return h.MockModelHandle.Chat(ctx, messages, params)
+}
+
+func (h *recordingHandle) last() []ChatMessage {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sent[len(h.sent)-1]
+}
+
+// chatThroughPool runs one Chat call via the pool and drains the stream.
+func chatThroughPool(t *testing.T, pool *Pool, messages []ChatMessage, params GenerateParams) {
+	t.Helper()
+	h, err := pool.Acquire("llama3", LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Release()
+	ch, err := h.Model().Chat(context.Background(), messages, params)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	for range ch {
+	}
+}
+
+func TestPool_SystemPromptInjected(t *testing.T) {
+	rec := &recordingHandle{MockModelHandle: &MockModelHandle{memSize: 1}}
+	pool := NewPool(stubBackend{handle: rec}, 1<<30, func(name string) (string, error) { return name, nil })
+	pool.SetSystemPrompt("llama3", "You are a helpful assistant.")
+
+	chatThroughPool(t, pool, []ChatMessage{{Role: "user", Content: "hi"}}, GenerateParams{})
+	got := rec.last()
+	if len(got) != 2 || got[0].Role != "system" || got[0].Content != "You are a helpful assistant." || got[1].Content != "hi" {
+		t.Errorf("messages = %+v, want default system prompt then user message", got)
+	}
+}
+
+func TestPool_SystemPromptCallerWins(t *testing.T) {
+	rec := &recordingHandle{MockModelHandle: &MockModelHandle{memSize: 1}}
+	pool := NewPool(stubBackend{handle: rec}, 1<<30, func(name string) (string, error) { return name, nil })
+	pool.SetSystemPrompt("llama3", "default")
+
+	msgs := []ChatMessage{{Role: "system", Content: "Answer in French."}, {Role: "user", Content: "hi"}}
+	chatThroughPool(t, pool, msgs, GenerateParams{System: "override"})
+	if got := rec.last(); len(got) != 2 || got[0].Content != "Answer in French." {
+		t.Errorf("messages = %+v, want caller's system message untouched", got)
+	}
+}
+
+func TestPool_SystemPromptOverrideAndSkip(t *testing.T) {
+	rec := &recordingHandle{MockModelHandle: &MockModelHandle{memSize: 1}}
+	pool := NewPool(stubBackend{handle: rec}, 1<<30, func(name string) (string, error) { return name, nil })
+	pool.SetSystemPrompt("llama3", "default")
+	user := []ChatMessage{{Role: "user", Content: "hi"}}
+
+	chatThroughPool(t, pool, user, GenerateParams{System: "Be terse."})
+	if got := rec.last(); len(got) != 2 || got[0].Content != "Be terse." {
+		t.Errorf("override: messages = %+v, want per-request system prompt", got)
+	}
+
+	chatThroughPool(t, pool, user, GenerateParams{NoSystem: true})
+	if got := rec.last(); len(got) != 1 {
+		t.Errorf("skip: messages = %+v, want no system prompt", got)
+	}
+
+	pool.SetSystemPrompt("llama3", "")
+	chatThroughPool(t, pool, user, GenerateParams{})
+	if got := rec.last(); len(got) != 1 {
+		t.Errorf("removed: messages = %+v, want no system prompt", got)
+	}
+	if len(user) != 1 {
+		t.Error("caller's slice was modified")
+	}
+}
+
+func TestPool_ModelInfo(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "llama3.gguf")
+	write := func(ctx uint32) {
+		data := (&ggufBuilder{}).str("general.architecture", "llama").u32("llama.context_length", ctx).tensor("w", 64, 64).bytes()
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(8192)
+	pool := NewPool(NewMockBackend(), 1<<30, func(name string) (string, error) {
+		return filepath.Join(dir, name+".gguf"), nil
+	})
+
+	meta, err := pool.ModelInfo("llama3")
+	if err != nil {
+		t.Fatalf("ModelInfo: %v", err)
+	}
+	if meta.Architecture != "llama" || meta.ContextLength != 8192 || meta.ParameterCount != 64*64 {
+		t.Errorf("meta = %+v", meta)
+	}
+
+	// A re-pulled file with a different header is re-read.
+	write(131072)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+	if meta, _ = pool.ModelInfo("llama3"); meta.ContextLength != 131072 {
+		t.Errorf("ContextLength after rewrite = %d, want 131072", meta.ContextLength)
+	}
+
+	if _, err := pool.ModelInfo("missing"); err == nil {
+		t.Error("expected error for missing model file")
+	}
+}
+
+func TestGenerateParams_Sanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       GenerateParams
+		wantTemp float32
+		wantTopP float32
+	}{
+		{"in range", GenerateParams{Temperature: 0.7, TopP: 0.9}, 0.7, 0.9},
+		{"negative temperature", GenerateParams{Temperature: -0.5, TopP: 0.9}, 0, 0.9},
+		{"temperature too high", GenerateParams{Temperature: 5, TopP: 0.9}, MaxTemperature, 0.9},
+		{"top-p above 1", GenerateParams{Temperature: 0.7, TopP: 1.5}, 0.7, 1},
+		{"negative top-p", GenerateParams{Temperature: 0.7, TopP: -1}, 0.7, 0},
+		{"NaN", GenerateParams{Temperature: float32(math.NaN()), TopP: float32(math.NaN())}, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.in.Sanitize()
+			if err != nil {
+				t.Fatalf("Sanitize() error: %v", err)
+			}
+			if got.Temperature != tt.wantTemp || got.TopP != tt.wantTopP {
+				t.Errorf("Sanitize() = temp %v top_p %v, want %v / %v", got.Temperature, got.TopP, tt.wantTemp, tt.wantTopP)
+			}
+		})
+	}
+}
+
+func TestGenerateParams_SanitizeStrict(t *testing.T) {
+	for _, p := range []GenerateParams{
+		{Temperature: -0.1, TopP: 0.9, Strict: true},
+		{Temperature: 0.7, TopP: 1.01, Strict: true},
+	} {
+		if _, err := p.Sanitize(); !errors.Is(err, domain.ErrInvalidSampling) {
+			t.Errorf("Sanitize(%+v) error = %v, want ErrInvalidSampling", p, err)
+		}
+	}
+
+	ok := GenerateParams{Temperature: 1, TopP: 1, Strict: true}
+	if got, err := ok.Sanitize(); err != nil || got.Temperature != 1 || got.TopP != 1 {
+		t.Errorf("Sanitize(in range, strict) = %+v, %v", got, err)
+	}
+}
+
+// ─── Result Cache Tests ─────────────────────────────────────────────────────
+
+func newCachingPool(rec *recordingHandle, maxEntries int) *Pool {
+	pool := NewPool(stubBackend{handle: rec}, 1<<30, func(name string) (string, error) { return name, nil })
+	pool.SetResultCache(maxEntries)
+	return pool
+}
+
+func (h *recordingHandle) calls() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.sent)
+}
+
+func TestPool_ResultCacheDeterministic(t *testing.T) {
+	rec := &recordingHandle{MockModelHandle: &MockModelHandle{memSize: 1}}
+	pool := newCachingPool(rec, 8)
+	msgs := []ChatMessage{{Role: "user", Content: "what is two plus two"}}
+	params := GenerateParams{Temperature: 0, Seed: 42}
+
+	chatThroughPool(t, pool, msgs, params)
+	chatThroughPool(t, pool, msgs, params)
+	if got := rec.calls(); got != 1 {
+		t.Errorf("backend calls = %d, want 1 (second served from cache)", got)
+	}
+	if s := pool.ResultCacheStats(); s.Hits != 1 || s.Misses != 1 || s.Entries != 1 {
+		t.Errorf("stats = %+v, want 1 hit, 1 miss, 1 entry", s)
+	}
+
+	// Changing the seed or the prompt is a different request.
+	chatThroughPool(t, pool, msgs, GenerateParams{Seed: 7})
+	chatThroughPool(t, pool, []ChatMessage{{Role: "user", Content: "other"}}, params)
+	if got := rec.calls(); got != 3 {
+		t.Errorf("backend calls = %d, want 3", got)
+	}
+}
+
+func TestPool_ResultCacheSkipsSampled(t *testing.T) {
+	rec := &recordingHandle{MockModelHandle: &MockModelHandle{memSize: 1}}
+	pool := newCachingPool(rec, 8)
+	msgs := []ChatMessage{{Role: "user", Content: "tell me a story"}}
+
+	chatThroughPool(t, pool, msgs, GenerateParams{Temperature: 0.8})
+	chatThroughPool(t, pool, msgs, GenerateParams{Temperature: 0.8})
+	if got := rec.calls(); got != 2 {
+		t.Errorf("backend calls = %d, want 2", got)
+	}
+	if s := pool.ResultCacheStats(); s != (ResultCacheStats{}) {
+		t.Errorf("stats = %+v, want untouched", s)
+	}
+}
+
+func TestPool_ResultCacheReplaysStream(t *testing.T) {
+	pool := newTestPool()
+	pool.SetResultCache(4)
+	h, err := pool.Acquire("llama3", LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Release()
+
+	collect := func() string {
+		ch, err := h.Model().Generate(context.Background(), "repeat me", GenerateParams{})
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		var text string
+		var done bool
+		for tok := range ch {
+			text += tok.Text
+			done = tok.Done
+		}
+		if !done {
+			t.Error("stream did not end with a Done token")
+		}
+		return text
+	}
+	first, second := collect(), collect()
+	if first != second {
+		t.Errorf("replayed %q, want %q", second, first)
+	}
+	if s := pool.ResultCacheStats(); s.Hits != 1 {
+		t.Errorf("hits = %d, want 1", s.Hits)
+	}
+}
+
+func TestResultCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newResultCache(2)
+	tok := []domain.Token{{Text: "x", Done: true}}
+	c.put("a", tok)
+	c.put("b", tok)
+	c.get("a") // a is now most recent
+	c.put("c", tok)
+
+	if _, ok := c.get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Error("a should still be cached")
+	}
+	if s := c.stats(); s.Entries != 2 {
+		t.Errorf("entries = %d, want 2", s.Entries)
+	}
+}
+
+// ─── Routing Tests ──────────────────────────────────────────────────────────
+
+func TestPool_RoutesModelsToRemote(t *testing.T) {
+	remote := newStubRemote(t, "llama-70b")
+	resolver := func(name string) (string, error) {
+		if name == "llama-70b" {
+			return "", fmt.Errorf("%s: %w", name, domain.ErrModelNotFound) // not installed locally
+		}
+		return "/fake/path/" + name, nil
+	}
+	pool := NewPool(NewMockBackend(), 1<<30, resolver)
+	pool.SetRoutePolicy(RouteModels(NewRemoteBackend(remote.URL), "llama-70b"))
+
+	generate := func(model string) string {
+		t.Helper()
+		h, err := pool.Acquire(model, LoadOptions{})
+		if err != nil {
+			t.Fatalf("Acquire(%s) error: %v", model, err)
+		}
+		defer h.Release()
+		ch, err := h.Model().Generate(context.Background(), "ping", GenerateParams{})
+		if err != nil {
+			t.Fatalf("Generate(%s) error: %v", model, err)
+		}
+		text, _ := drainText(t, ch)
+		return text
+	}
+
+	if got := generate("llama-70b"); got != "remote hello" {
+		t.Errorf("remote model answered %q, want the remote node's reply", got)
+	}
+	if got := generate("llama-1b"); !strings.HasPrefix(got, "Hello! I received your prompt") {
+		t.Errorf("local model answered %q, want the mock backend's reply", got)
+	}
+
+	processors := map[string]string{}
+	for _, m := range pool.LoadedModels() {
+		processors[m.Name] = m.Processor
+	}
+	if processors["llama-70b"] != "remote" || processors["llama-1b"] != "CPU" {
+		t.Errorf("LoadedModels processors = %v", processors)
+	}
+
+	if err := pool.UnloadAll(); err != nil {
+		t.Fatalf("UnloadAll() error: %v", err)
+	}
+	if n := len(pool.LoadedModels()); n != 0 {
+		t.Errorf("LoadedModels() after UnloadAll = %d, want 0", n)
+	}
+}
+
+// ─── Quantization Fallback Tests ────────────────────────────────────────────
+
+// oomBackend fails to load any path in tooBig with a memory error.
+type oomBackend struct {
+	*MockBackend
+	tooBig map[string]bool
+	loaded []string
+}
+
+func (b *oomBackend) LoadModel(path string, opts LoadOptions) (ModelHandle, error) {
+	b.loaded = append(b.loaded, path)
+	if b.tooBig[path] {
+		return nil, fmt.Errorf("llama-server failed to start (model: %s): %w", path, domain.ErrInsufficientMemory)
+	}
+	return b.MockBackend.LoadModel(path, opts)
+}
+
+func TestPool_FallsBackToSmallerQuant(t *testing.T) {
+	backend := &oomBackend{MockBackend: NewMockBackend(), tooBig: map[string]bool{"/models/llama3:Q8_0": true}}
+	pool := NewPool(backend, 1<<30, func(name string) (string, error) { return "/models/" + name, nil })
+	var downgrades []string
+	pool.SetQuantFallback(func(name string) ([]string, error) {
+		if name != "llama3:Q8_0" {
+			t.Errorf("variants requested for %q", name)
+		}
+		return []string{"llama3:Q4_K_M", "llama3:Q2_K"}, nil
+	}, func(name, variant string) {
+		downgrades = append(downgrades, name+" -> "+variant)
+	})
+
+	h, err := pool.Acquire("llama3:Q8_0", LoadOptions{})
+	if err != nil {
+		t.Fatalf("Acquire() error: %v", err)
+	}
+	if got := h.Name(); got != "llama3:Q4_K_M" {
+		t.Errorf("Name() = %q, want the variant that served the request", got)
+	}
+	h.Release()
+
+	if want := []string{"/models/llama3:Q8_0", "/models/llama3:Q4_K_M"}; !slices.Equal(backend.loaded, want) {
+		t.Errorf("loaded %v, want %v", backend.loaded, want)
+	}
+	if want := []string{"llama3:Q8_0 -> llama3:Q4_K_M"}; !slices.Equal(downgrades, want) {
+		t.Errorf("downgrades = %v, want %v", downgrades, want)
+	}
+
+	// The fallback is pooled under its own name and serves later requests.
+	if loaded := pool.LoadedModels(); len(loaded) != 1 || loaded[0].Name != "llama3:Q4_K_M" {
+		t.Errorf("LoadedModels() = %+v, want the variant listed by its name", loaded)
+	}
+	h, err = pool.Acquire("llama3:Q8_0", LoadOptions{})
+	if err != nil {
+		t.Fatalf("second Acquire() error: %v", err)
+	}
+	h.Release()
+	if len(backend.loaded) != 2 {
+		t.Errorf("second Acquire reloaded: %v", backend.loaded)
+	}
+}
+
+// roomBackend runs out of memory once slots models are open.
+type roomBackend struct {
+	*MockBackend
+	slots   int
+	handles []*MockModelHandle
+}
+
+func (b *roomBackend) LoadModel(path string, opts LoadOptions) (ModelHandle, error) {
+	open := 0
+	for _, h := range b.handles {
+		if !h.closed {
+			open++
+		}
+	}
+	if open >= b.slots {
+		return nil, fmt.Errorf("llama-server failed to start (model: %s): %w", path, domain.ErrInsufficientMemory)
+	}
+	h, err := b.MockBackend.LoadModel(path, opts)
+	if err == nil {
+		b.handles = append(b.handles, h.(*MockModelHandle))
+	}
+	return h, err
+}
+
+func TestPool_EvictsIdleBeforeQuantFallback(t *testing.T) {
+	backend := &roomBackend{MockBackend: NewMockBackend(), slots: 1}
+	pool := NewPool(backend, 1<<30, func(name string) (string, error) { return "/models/" + name, nil })
+	pool.SetQuantFallback(func(string) ([]string, error) {
+		t.Error("variants consulted while an idle model could be unloaded")
+		return nil, nil
+	}, nil)
+
+	h, err := pool.Acquire("phi3", LoadOptions{})
+	if err != nil {
+		t.Fatalf("Acquire(phi3) error: %v", err)
+	}
+	h.Release()
+
+	h, err = pool.Acquire("llama3:Q8_0", LoadOptions{})
+	if err != nil {
+		t.Fatalf("Acquire(llama3) error: %v", err)
+	}
+	defer h.Release()
+	if got := h.Name(); got != "llama3:Q8_0" {
+		t.Errorf("Name() = %q, want the full model once the idle one is unloaded", got)
+	}
+	if loaded := pool.LoadedModels(); len(loaded) != 1 || loaded[0].Name != "llama3:Q8_0" {
+		t.Errorf("LoadedModels() = %+v, want only llama3:Q8_0", loaded)
+	}
+}
+
+func TestPool_QuantFallbackOnlyOnMemoryErrors(t *testing.T) {
+	backend := &oomBackend{MockBackend: NewMockBackend(), tooBig: map[string]bool{"/models/llama3:Q8_0": true}}
+	pool := NewPool(backend, 1<<30, func(name string) (string, error) { return "/models/" + name, nil })
+
+	// Without a fallback configured the memory error surfaces.
+	_, err := pool.Acquire("llama3:Q8_0", LoadOptions{})
+	if !errors.Is(err, domain.ErrInsufficientMemory) {
+		t.Fatalf("Acquire() without fallback = %v, want ErrInsufficientMemory", err)
+	}
+
+	// Every variant too big: the last memory error surfaces.
+	backend.tooBig["/models/llama3:Q4_K_M"] = true
+	pool.SetQuantFallback(func(string) ([]string, error) { return []string{"llama3:Q4_K_M"}, nil }, nil)
+	_, err = pool.Acquire("llama3:Q8_0", LoadOptions{})
+	if !errors.Is(err, domain.ErrInsufficientMemory) || !strings.Contains(err.Error(), "Q4_K_M") {
+		t.Errorf("Acquire() with no variant fitting = %v, want the variant's memory error", err)
+	}
+
+	// A load failing for another reason is not retried.
+	pool = NewPool(stubLoadError{NewMockBackend(), errors.New("model file not found")}, 1<<30, func(name string) (string, error) { return name, nil })
+	pool.SetQuantFallback(func(string) ([]string, error) {
+		t.Error("variants consulted for a non-memory failure")
+		return nil, nil
+	}, nil)
+	if _, err := pool.Acquire("llama3:Q8_0", LoadOptions{}); err == nil {
+		t.Error("Acquire() should fail")
+	}
+}
+
+// stubLoadError fails every load with err.
+type stubLoadError struct {
+	InferenceBackend
+	err error
+}
+
+func (b stubLoadError) LoadModel(string, LoadOptions) (ModelHandle, error) { return nil, b.err }
+
+// ─── Integrity Check Tests ──────────────────────────────────────────────────
+
+func TestPool_IntegrityCheck(t *testing.T) {
+	backend := &oomBackend{MockBackend: NewMockBackend()}
+	pool := NewPool(backend, 1<<30, func(name string) (string, error) { return "/models/" + name, nil })
+	var corrupt []string
+	pool.SetIntegrityCheck(func(name, path string) error {
+		if name == "bad" {
+			return fmt.Errorf("%w: %s has sha256 00, want ff", domain.ErrModelCorrupted, path)
+		}
+		return nil
+	}, func(name string, err error) {
+		corrupt = append(corrupt, name)
+	})
+
+	h, err := pool.Acquire("good", LoadOptions{})
+	if err != nil {
+		t.Fatalf("Acquire(matching hash) error: %v", err)
+	}
+	h.Release()
+
+	_, err = pool.Acquire("bad", LoadOptions{})
+	if !errors.Is(err, domain.ErrModelCorrupted) {
+		t.Fatalf("Acquire(corrupted) = %v, want ErrModelCorrupted", err)
+	}
+	if !slices.Equal(backend.loaded, []string{"/models/good"}) {
+		t.Errorf("loaded %v, want the corrupted file never launched", backend.loaded)
+	}
+	if !slices.Equal(corrupt, []string{"bad"}) {
+		t.Errorf("onCorrupt calls = %v, want [bad]", corrupt)
+	}
+}
+
+func TestPool_IntegrityCheckRunsUnlocked(t *testing.T) {
+	pool := NewPool(NewMockBackend(), 1<<30, func(name string) (string, error) { return "/models/" + name, nil })
+	h, err := pool.Acquire("small", LoadOptions{})
+	if err != nil {
+		t.Fatalf("Acquire(small) error: %v", err)
+	}
+	h.Release()
+
+	hashing, done := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	pool.SetIntegrityCheck(func(name, path string) error {
+		if name == "large" {
+			once.Do(func() { close(hashing) })
+			<-done
+		}
+		return nil
+	}, nil)
+
+	loaded := make(chan error, 1)
+	go func() {
+		h, err := pool.Acquire("large", LoadOptions{})
+		if err == nil {
+			h.Release()
+		}
+		loaded <- err
+	}()
+	<-hashing
+
+	acquired := make(chan struct{})
+	go func() {
+		if h, err := pool.Acquire("small", LoadOptions{}); err == nil {
+			h.Release()
+		}
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Acquire of a loaded model blocked behind another model's integrity check")
+	}
+
+	close(done)
+	if err := <-loaded; err != nil {
+		t.Errorf("Acquire(large) error: %v", err)
+	}
+}
@@ -2,7 +2,30 @@
 
 package engine
 
-import "os/exec"
+import (
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
 
 // configureProcess is a no-op on non-Windows platforms.
 func configureProcess(_ *exec.Cmd) {}
+
+// lookupProcess returns the executable name and start time of pid via ps,
+// or ok=false if no such process is running.
+func lookupProcess(pid int) (processInfo, bool) {
+	out, err := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "lstart=", "-o", "comm=").Output()
+	if err != nil {
+		return processInfo{}, false
+	}
+	// lstart is always five fields: "Fri Oct 16 14:52:26 2026".
+	fields := strings.Fields(string(out))
+	if len(fields) < 6 {
+		return processInfo{}, false
+	}
+	return processInfo{
+		Name:    filepath.Base(strings.Join(fields[5:], " ")),
+		Started: strings.Join(fields[:5], " "),
+	}, true
+}
@@ -1,7 +1,10 @@
 package engine
 
 import (
+	"encoding/csv"
 	"os/exec"
+	"strconv"
+	"strings"
 	"syscall"
 )
 
@@ -13,3 +16,40 @@ func configureProcess(cmd *exec.Cmd) {
 		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
 	}
 }
+
+// processQueryLimitedInformation is PROCESS_QUERY_LIMITED_INFORMATION,
+// enough to read another process's start time.
+const processQueryLimitedInformation = 0x1000
+
+// lookupProcess returns the image name of pid via tasklist and its creation
+// time, or ok=false if no such process is running.
+func lookupProcess(pid int) (processInfo, bool) {
+	cmd := exec.Command("tasklist", "/FI", "PID eq "+strconv.Itoa(pid), "/FO", "CSV", "/NH")
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	out, err := cmd.Output()
+	if err != nil {
+		return processInfo{}, false
+	}
+	// A match is a CSV row: "llama-server.exe","1234",...; otherwise tasklist
+	// prints an INFO line.
+	fields, err := csv.NewReader(strings.NewReader(string(out))).Read()
+	if err != nil || len(fields) < 2 || fields[1] != strconv.Itoa(pid) {
+		return processInfo{}, false
+	}
+	return processInfo{Name: fields[0], Started: processStart(pid)}, true
+}
+
+// processStart returns pid's creation time, or "" if it cannot be read
+// (e.g. another user's elevated process).
+func processStart(pid int) string {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return ""
+	}
+	defer syscall.CloseHandle(h)
+	var created, exited, kernel, user syscall.Filetime
+	if err := syscall.GetProcessTimes(h, &created, &exited, &kernel, &user); err != nil {
+		return ""
+	}
+	return strconv.FormatInt(created.Nanoseconds(), 10)
+}
@@ -0,0 +1,264 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Remote Backend ─────────────────────────────────────────────────────────
+// RemoteBackend runs models on another TuTu node through its HTTP API, so a
+// Pool can serve some models locally and proxy others (see RoutePolicy).
+
+// remoteLoadTimeout bounds the model check done by RemoteBackend.LoadModel.
+const remoteLoadTimeout = 10 * time.Second
+
+// RemoteBackend implements InferenceBackend by proxying to a remote node.
+type RemoteBackend struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewRemoteBackend returns a backend for the TuTu node at baseURL
+// (e.g. "http://10.0.0.5:11434").
+func NewRemoteBackend(baseURL string) *RemoteBackend {
+	return &RemoteBackend{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{},
+	}
+}
+
+// LoadsByName reports that LoadModel takes a model name rather than a local
+// file path: the remote node resolves and loads the model itself.
+func (b *RemoteBackend) LoadsByName() bool { return true }
+
+// LoadModel checks that the remote node has the model and returns a handle
+// proxying to it. Load options are left to the remote node's configuration.
+func (b *RemoteBackend) LoadModel(name string, _ LoadOptions) (ModelHandle, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteLoadTimeout)
+	defer cancel()
+
+	resp, err := b.post(ctx, "/api/show", map[string]any{"name": name})
+	if err != nil {
+		return nil, err
+	}
+	drainClose(resp.Body)
+	return &RemoteHandle{backend: b, model: name}, nil
+}
+
+// Close releases idle connections to the remote node.
+func (b *RemoteBackend) Close() { b.client.CloseIdleConnections() }
+
+// post sends a JSON request and returns the response if it is 200 OK.
+// A 404 maps to domain.ErrModelNotFound.
+func (b *RemoteBackend) post(ctx context.Context, path string, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", b.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("remote %s: %w", path, err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	msg := remoteErrorMessage(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("remote %s: %s: %w", path, msg, domain.ErrModelNotFound)
+	}
+	return nil, fmt.Errorf("remote %s error %d: %s", path, resp.StatusCode, msg)
+}
+
+// remoteErrorMessage extracts the message from a TuTu API error body,
+// falling back to the raw body.
+func remoteErrorMessage(r io.Reader) string {
+	raw, _ := io.ReadAll(io.LimitReader(r, 4096))
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(raw, &body) == nil && body.Error.Message != "" {
+		return body.Error.Message
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+// RemoteHandle is a model served by a remote node.
+type RemoteHandle struct {
+	backend *RemoteBackend
+	model   string
+}
+
+// Generate streams a completion from the remote /api/generate endpoint,
+// passing the sampling params as Ollama options.
+func (h *RemoteHandle) Generate(ctx context.Context, prompt string, params GenerateParams) (<-chan domain.Token, error) {
+	params, err := params.Sanitize()
+	if err != nil {
+		return nil, err
+	}
+	options := map[string]any{
+		"temperature": params.Temperature,
+		"top_p":       params.TopP,
+	}
+	if params.MaxTokens > 0 {
+		options["num_predict"] = params.MaxTokens
+	}
+	if len(params.Stop) > 0 {
+		options["stop"] = params.Stop
+	}
+	if params.Seed != 0 {
+		options["seed"] = params.Seed
+	}
+
+	ctx, touch, cancel := params.deadline(ctx, DefaultGenerateTimeout)
+	timer := newStreamTimer()
+	resp, err := h.backend.post(ctx, "/api/generate", map[string]any{
+		"model":   h.model,
+		"prompt":  prompt,
+		"stream":  true,
+		"options": options,
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = touchBody{resp.Body, touch}
+
+	ch := make(chan domain.Token, 64)
+	go func() {
+		defer cancel()
+		defer close(ch)
+		defer drainClose(resp.Body)
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var chunk struct {
+				Response        string `json:"response"`
+				Done            bool   `json:"done"`
+				PromptEvalCount int    `json:"prompt_eval_count"` // final chunk only
+				EvalCount       int    `json:"eval_count"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &chunk); err != nil {
+				continue
+			}
+			if chunk.Response == "" && !chunk.Done {
+				continue
+			}
+			timer.observe(chunk.Response)
+			tok := domain.Token{Text: chunk.Response, Done: chunk.Done}
+			if chunk.Done {
+				tok.Stats = timer.stats(chunk.PromptEvalCount, chunk.EvalCount)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- tok:
+			}
+			if chunk.Done {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// Chat streams a chat completion from the remote /v1/chat/completions.
+func (h *RemoteHandle) Chat(ctx context.Context, messages []ChatMessage, params GenerateParams) (<-chan domain.Token, error) {
+	params, err := params.Sanitize()
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{
+		"model":          h.model,
+		"messages":       messages,
+		"stream":         true,
+		"stream_options": map[string]bool{"include_usage": true},
+		"temperature":    params.Temperature,
+		"top_p":          params.TopP,
+	}
+	if params.MaxTokens > 0 {
+		body["max_tokens"] = params.MaxTokens
+	}
+	if len(params.Stop) > 0 {
+		body["stop"] = params.Stop
+	}
+	if params.Seed != 0 {
+		body["seed"] = params.Seed
+	}
+
+	ctx, touch, cancel := params.deadline(ctx, DefaultChatTimeout)
+	timer := newStreamTimer()
+	resp, err := h.backend.post(ctx, "/v1/chat/completions", body)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = touchBody{resp.Body, touch}
+
+	ch := make(chan domain.Token, 64)
+	go func() {
+		defer cancel()
+		defer close(ch)
+		defer drainClose(resp.Body)
+		readChatStream(ctx, resp.Body, ch, timer)
+	}()
+	return ch, nil
+}
+
+// Embed returns embeddings from the remote /v1/embeddings endpoint.
+func (h *RemoteHandle) Embed(ctx context.Context, input []string) ([][]float32, error) {
+	resp, err := h.backend.post(ctx, "/v1/embeddings", map[string]any{
+		"model": h.model,
+		"input": input,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer drainClose(resp.Body)
+
+	var result struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+			Index     int       `json:"index"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("remote embeddings: %w", err)
+	}
+	if len(result.Data) != len(input) {
+		return nil, fmt.Errorf("remote embeddings: got %d vectors for %d inputs", len(result.Data), len(input))
+	}
+	out := make([][]float32, len(input))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(out) {
+			return nil, fmt.Errorf("remote embeddings: index %d out of range", d.Index)
+		}
+		out[d.Index] = d.Embedding
+	}
+	return out, nil
+}
+
+// MemoryBytes is zero: remote models use no local memory, so they never
+// force local models out of the pool.
+func (h *RemoteHandle) MemoryBytes() uint64 { return 0 }
+
+// Close is a no-op; the remote node manages the model's lifetime.
+func (h *RemoteHandle) Close() {}
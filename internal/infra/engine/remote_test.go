@@ -0,0 +1,185 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// stubRemote is a fake TuTu node serving one model over the HTTP API.
+type stubRemote struct {
+	*httptest.Server
+	model string
+
+	mu       sync.Mutex
+	requests []map[string]any // decoded request bodies, in order
+}
+
+func newStubRemote(t *testing.T, model string) *stubRemote {
+	t.Helper()
+	s := &stubRemote{model: model}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/show", func(w http.ResponseWriter, r *http.Request) {
+		if s.decode(r)["name"] != s.model {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"message":"model not found","type":"error"}}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("/api/generate", func(w http.ResponseWriter, r *http.Request) {
+		s.decode(r)
+		fmt.Fprintln(w, `{"model":"m","response":"remote ","done":false}`)
+		fmt.Fprintln(w, `{"model":"m","response":"hello","done":false}`)
+		fmt.Fprintln(w, `{"model":"m","response":"","done":true}`)
+	})
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		s.decode(r)
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"remote chat\"},\"finish_reason\":null}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"length\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+	mux.HandleFunc("/v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		n := len(s.decode(r)["input"].([]any))
+		data := make([]map[string]any, n)
+		for i := range data {
+			// Reverse order: the handle must place vectors by index.
+			idx := n - 1 - i
+			data[i] = map[string]any{"index": idx, "embedding": []float32{float32(idx), 1}}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *stubRemote) decode(r *http.Request) map[string]any {
+	var body map[string]any
+	json.NewDecoder(r.Body).Decode(&body)
+	s.mu.Lock()
+	s.requests = append(s.requests, body)
+	s.mu.Unlock()
+	return body
+}
+
+func (s *stubRemote) last() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[len(s.requests)-1]
+}
+
+func drainText(t *testing.T, ch <-chan domain.Token) (text string, last domain.Token) {
+	t.Helper()
+	var sb strings.Builder
+	for tok := range ch {
+		sb.WriteString(tok.Text)
+		last = tok
+	}
+	return sb.String(), last
+}
+
+func TestRemoteBackend_LoadModel(t *testing.T) {
+	remote := newStubRemote(t, "llama-70b")
+	b := NewRemoteBackend(remote.URL + "/")
+	defer b.Close()
+
+	if _, err := b.LoadModel("llama-70b", LoadOptions{}); err != nil {
+		t.Fatalf("LoadModel() error: %v", err)
+	}
+	_, err := b.LoadModel("missing", LoadOptions{})
+	if !errors.Is(err, domain.ErrModelNotFound) {
+		t.Errorf("LoadModel(missing) error = %v, want ErrModelNotFound", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("error %q should carry the remote message", err)
+	}
+}
+
+func TestRemoteHandle_GenerateChatEmbed(t *testing.T) {
+	remote := newStubRemote(t, "llama-70b")
+	h, err := NewRemoteBackend(remote.URL).LoadModel("llama-70b", LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	ch, err := h.Generate(ctx, "hi", GenerateParams{Temperature: 0, MaxTokens: 16, Seed: 3})
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if text, last := drainText(t, ch); text != "remote hello" || !last.Done {
+		t.Errorf("Generate() = %q (last %+v), want %q ending Done", text, last, "remote hello")
+	}
+	got := remote.last()
+	if got["model"] != "llama-70b" || got["prompt"] != "hi" {
+		t.Errorf("generate request = %v", got)
+	}
+	opts, _ := got["options"].(map[string]any)
+	if opts["temperature"] != float64(0) || opts["num_predict"] != float64(16) || opts["seed"] != float64(3) {
+		t.Errorf("generate options = %v, want the sampling params forwarded", opts)
+	}
+
+	ch, err = h.Chat(ctx, []ChatMessage{{Role: "user", Content: "hi"}}, GenerateParams{MaxTokens: 8, Seed: 7})
+	if err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+	if text, last := drainText(t, ch); text != "remote chat" || last.FinishReason != domain.FinishLength {
+		t.Errorf("Chat() = %q (last %+v), want %q finishing %q", text, last, "remote chat", domain.FinishLength)
+	}
+	if got := remote.last(); got["max_tokens"] != float64(8) || got["seed"] != float64(7) {
+		t.Errorf("chat request = %v, want max_tokens and seed forwarded", got)
+	}
+
+	vecs, err := h.Embed(ctx, []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("Embed() error: %v", err)
+	}
+	for i, v := range vecs {
+		if len(v) != 2 || v[0] != float32(i) {
+			t.Errorf("vecs[%d] = %v, want it placed by index", i, v)
+		}
+	}
+	if h.MemoryBytes() != 0 {
+		t.Errorf("MemoryBytes() = %d, want 0 for a remote model", h.MemoryBytes())
+	}
+}
+
+func TestRemoteHandle_Timeout(t *testing.T) {
+	// The remote node sends one chunk and then stalls.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/show" {
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		fmt.Fprintln(w, `{"model":"m","response":"slow","done":false}`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	h, err := NewRemoteBackend(srv.URL).LoadModel("m", LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	ch, err := h.Generate(context.Background(), "hi", GenerateParams{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if _, last := drainText(t, ch); last.Done {
+		t.Error("stalled stream finished; want it cut off")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("stalled remote request took %v, want the timeout honoured", elapsed)
+	}
+}
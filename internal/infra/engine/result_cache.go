@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Result Cache ───────────────────────────────────────────────────────────
+// Greedy (temperature 0) generation is deterministic, so a repeated request
+// can be answered from memory without touching llama-server. The cache is an
+// LRU of complete token streams keyed by a hash of everything that shapes the
+// output. It is off until Pool.SetResultCache is called.
+
+// ResultCacheStats reports result cache effectiveness. Only cacheable
+// (deterministic) requests count as hits or misses.
+type ResultCacheStats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Entries int   `json:"entries"`
+}
+
+// resultCache is a bounded LRU of finished token streams.
+type resultCache struct {
+	mu      sync.Mutex
+	max     int
+	lru     *list.List // front = most recently used
+	entries map[string]*list.Element
+	hits    int64
+	misses  int64
+}
+
+type resultEntry struct {
+	key    string
+	tokens []domain.Token
+}
+
+func newResultCache(maxEntries int) *resultCache {
+	return &resultCache{
+		max:     maxEntries,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached stream for key, counting the hit or miss.
+func (c *resultCache) get(key string) ([]domain.Token, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.lru.MoveToFront(el)
+	return el.Value.(*resultEntry).tokens, true
+}
+
+func (c *resultCache) put(key string, tokens []domain.Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*resultEntry).tokens = tokens
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&resultEntry{key: key, tokens: tokens})
+	for c.lru.Len() > c.max {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*resultEntry).key)
+	}
+}
+
+func (c *resultCache) stats() ResultCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ResultCacheStats{Hits: c.hits, Misses: c.misses, Entries: c.lru.Len()}
+}
+
+// tee forwards src to the returned channel and caches the stream under key
+// once it finishes normally. Cancelled or truncated streams are not cached.
+func (c *resultCache) tee(ctx context.Context, key string, src <-chan domain.Token) <-chan domain.Token {
+	out := make(chan domain.Token, 64)
+	go func() {
+		defer close(out)
+		var tokens []domain.Token
+		for tok := range src {
+			tokens = append(tokens, tok)
+			select {
+			case out <- tok:
+			case <-ctx.Done():
+				for range src { // let the producer wind down
+				}
+				return
+			}
+		}
+		if n := len(tokens); n > 0 && tokens[n-1].Done && ctx.Err() == nil {
+			c.put(key, tokens)
+		}
+	}()
+	return out
+}
+
+// cacheable reports whether params yield deterministic output.
+func cacheable(params GenerateParams) bool {
+	return params.Temperature == 0
+}
+
+// resultKey hashes the model, request kind, input (prompt or messages),
+// and every sampling parameter that affects the output.
+func resultKey(model, kind string, input any, params GenerateParams) (string, error) {
+	data, err := json.Marshal(struct {
+		Model     string
+		Kind      string
+		Input     any
+		TopP      float32
+		MaxTokens int
+		Stop      []string
+		Seed      int64
+	}{model, kind, input, params.TopP, params.MaxTokens, params.Stop, params.Seed})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replay streams a cached result.
+func replay(tokens []domain.Token) <-chan domain.Token {
+	ch := make(chan domain.Token, len(tokens))
+	for _, tok := range tokens {
+		ch <- tok
+	}
+	close(ch)
+	return ch
+}
+
+// SetResultCache enables caching of deterministic results, keeping up to
+// maxEntries streams. Zero or less disables the cache and drops its contents.
+func (p *Pool) SetResultCache(maxEntries int) {
+	if maxEntries <= 0 {
+		p.results.Store(nil)
+		return
+	}
+	p.results.Store(newResultCache(maxEntries))
+}
+
+// ResultCacheStats reports hits, misses, and size of the result cache
+// (all zero when it is disabled).
+func (p *Pool) ResultCacheStats() ResultCacheStats {
+	c := p.results.Load()
+	if c == nil {
+		return ResultCacheStats{}
+	}
+	return c.stats()
+}
+
+// cachedRun serves a deterministic request from the result cache, or runs
+// it and caches the finished stream. Other requests go straight to run.
+func (p *Pool) cachedRun(ctx context.Context, model, kind string, input any, params GenerateParams, run func() (<-chan domain.Token, error)) (<-chan domain.Token, error) {
+	c := p.results.Load()
+	if c == nil || !cacheable(params) {
+		return run()
+	}
+	key, err := resultKey(model, kind, input, params)
+	if err != nil {
+		return run()
+	}
+	if tokens, ok := c.get(key); ok {
+		return replay(tokens), nil
+	}
+	ch, err := run()
+	if err != nil {
+		return nil, err
+	}
+	return c.tee(ctx, key, ch), nil
+}
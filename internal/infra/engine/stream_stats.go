@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Stream Statistics ──────────────────────────────────────────────────────
+// Subprocess and remote streams end with a terminal token carrying
+// domain.StreamStats.
+// Token counts reported by the server win; when a server reports none, the
+// completion count falls back to the number of streamed text chunks, which
+// llama-server emits one per token.
+
+// streamTimer measures one stream from request start to its terminal token.
+type streamTimer struct {
+	start  time.Time
+	first  time.Time // first text token; zero until one arrives
+	chunks int
+}
+
+// newStreamTimer starts timing a stream. Call it before sending the request,
+// so time-to-first-token includes prompt processing.
+func newStreamTimer() *streamTimer {
+	return &streamTimer{start: time.Now()}
+}
+
+// observe records a streamed chunk of text.
+func (t *streamTimer) observe(text string) {
+	if text == "" {
+		return
+	}
+	if t.chunks == 0 {
+		t.first = time.Now()
+	}
+	t.chunks++
+}
+
+// stats closes the stream. Non-positive counts are treated as unreported.
+func (t *streamTimer) stats(promptTokens, completionTokens int) *domain.StreamStats {
+	end := time.Now()
+	if completionTokens <= 0 {
+		completionTokens = t.chunks
+	}
+	st := &domain.StreamStats{
+		PromptTokens:     max(promptTokens, 0),
+		CompletionTokens: completionTokens,
+		Duration:         end.Sub(t.start),
+	}
+	if !t.first.IsZero() {
+		st.TimeToFirstToken = t.first.Sub(t.start)
+	}
+	if st.Duration > 0 {
+		st.TokensPerSec = float64(completionTokens) / st.Duration.Seconds()
+	}
+	return st
+}
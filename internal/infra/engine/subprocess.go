@@ -9,6 +9,7 @@
 //	  → returns SubprocessHandle (proxy to llama-server HTTP API)
 //	    → Generate() calls POST /completion on llama-server
 //	    → Embed()     calls POST /embedding  on llama-server
+//	    → CacheStats() calls GET /slots       on llama-server
 //	  → Close() kills the subprocess
 package engine
 
@@ -17,8 +18,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net"
 	"net/http"
 	"os"
@@ -43,6 +46,79 @@ type SubprocessBackend struct {
 	// ProgressFunc is called during model loading to show feedback.
 	// Set by the daemon before Pool.Acquire is called.
 	ProgressFunc func(status string)
+	// EventFunc receives the same updates as structured ProgressEvents.
+	EventFunc func(ev ProgressEvent)
+	// progressMu serializes callbacks so concurrent loads never invoke
+	// them in parallel.
+	progressMu sync.Mutex
+	// Limits caps each llama-server's resources (Linux only).
+	Limits ProcessLimits
+	// WarmupTimeout bounds a throwaway generation issued right after load
+	// so the first real request hits warm caches. 0 disables warmup.
+	WarmupTimeout time.Duration
+	// UnixSocket serves each llama-server on a private Unix domain socket
+	// instead of a localhost TCP port. Ignored on Windows.
+	UnixSocket bool
+	// LogLevel is the llama-server log level for loads that do not set
+	// LoadOptions.LogLevel. "" leaves llama-server's default.
+	LogLevel string
+	// LogOutput receives every llama-server's log lines as they are
+	// written, prefixed with the model file name. nil = logs are only kept
+	// for error reports.
+	LogOutput io.Writer
+	logMu     sync.Mutex // serializes writes to LogOutput across servers
+	// EmbedMaxConcurrency caps the embedding requests each handle keeps in
+	// flight, and EmbedLatencyTarget is the per-request latency below which
+	// it ramps up toward that cap. Zero uses the defaults.
+	EmbedMaxConcurrency int
+	EmbedLatencyTarget  time.Duration
+
+	servers *serverRegistry // llama-servers we started; nil = not tracked
+	cleanup sync.Once       // orphan cleanup runs before the first load
+}
+
+// DefaultWarmupTimeout is the warmup bound used when warmup is enabled.
+const DefaultWarmupTimeout = 10 * time.Second
+
+// LoadStage identifies a phase of model loading.
+type LoadStage string
+
+const (
+	StageStarting LoadStage = "starting" // llama-server is being launched
+	StageLoading  LoadStage = "loading"  // waiting for the model to load
+	StageWarmup   LoadStage = "warmup"   // throwaway generation in progress
+	StageReady    LoadStage = "ready"    // model is serving requests
+	StageFailed   LoadStage = "failed"   // llama-server did not come up
+)
+
+// ProgressEvent is a structured model load status update.
+type ProgressEvent struct {
+	Stage   LoadStage
+	Model   string        // model file name (base of the GGUF path)
+	Percent int           // coarse 0–100 estimate derived from the stage
+	Elapsed time.Duration // time since LoadModel began
+	Message string        // human-readable status, as sent to ProgressFunc
+}
+
+// stagePercent maps each stage to its coarse completion estimate.
+var stagePercent = map[LoadStage]int{
+	StageStarting: 0,
+	StageLoading:  10,
+	StageWarmup:   90,
+	StageReady:    100,
+	StageFailed:   100,
+}
+
+// ProcessLimits bounds a llama-server subprocess so a runaway model is
+// killed by the OS instead of exhausting the host. Zero fields are unlimited.
+type ProcessLimits struct {
+	MemoryBytes uint64 // Max private writable memory (RLIMIT_DATA)
+	CPUSeconds  uint64 // Max total CPU time (RLIMIT_CPU)
+}
+
+// IsZero reports whether no limits are configured.
+func (l ProcessLimits) IsZero() bool {
+	return l.MemoryBytes == 0 && l.CPUSeconds == 0
 }
 
 // NewSubprocessBackend creates a backend that uses llama-server.
@@ -53,21 +129,93 @@ func NewSubprocessBackend(tutuHome string) (*SubprocessBackend, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &SubprocessBackend{llamaServerPath: path}, nil
+	return &SubprocessBackend{llamaServerPath: path, servers: newServerRegistry(tutuHome)}, nil
 }
 
 // SetProgress sets the progress callback for model loading status.
+// It receives the Message of every ProgressEvent.
 func (b *SubprocessBackend) SetProgress(fn func(string)) {
 	b.ProgressFunc = fn
 }
 
-// progress emits a status message if a callback is set.
-func (b *SubprocessBackend) progress(msg string) {
+// SetProgressEvents sets a structured progress callback for model loading.
+// Callbacks are never invoked concurrently, even across parallel loads.
+func (b *SubprocessBackend) SetProgressEvents(fn func(ProgressEvent)) {
+	b.EventFunc = fn
+}
+
+// SetLimits sets resource limits applied to every llama-server launched.
+func (b *SubprocessBackend) SetLimits(l ProcessLimits) {
+	b.Limits = l
+}
+
+// SetUnixSocket makes llama-server listen on a Unix domain socket in a
+// private temp directory, avoiding TCP port conflicts and exposure on shared
+// hosts. Windows always uses TCP.
+func (b *SubprocessBackend) SetUnixSocket(enabled bool) {
+	b.UnixSocket = enabled
+}
+
+// SetLogLevel sets the default llama-server log level (see LogLevels).
+func (b *SubprocessBackend) SetLogLevel(level string) {
+	b.LogLevel = level
+}
+
+// SetLogOutput streams llama-server logs to w for live debugging, in
+// addition to the buffer used for error reports. nil stops streaming.
+func (b *SubprocessBackend) SetLogOutput(w io.Writer) {
+	b.LogOutput = w
+}
+
+// SetWarmup enables a post-load warmup generation bounded by timeout.
+// A zero timeout disables warmup (the default).
+func (b *SubprocessBackend) SetWarmup(timeout time.Duration) {
+	b.WarmupTimeout = timeout
+}
+
+// SetEmbedConcurrency bounds adaptive embedding concurrency: each handle
+// ramps toward max requests in flight while they finish within target,
+// and backs off when they do not. Zero values use the defaults.
+func (b *SubprocessBackend) SetEmbedConcurrency(max int, target time.Duration) {
+	b.EmbedMaxConcurrency = max
+	b.EmbedLatencyTarget = target
+}
+
+// emit delivers ev to whichever callbacks are set.
+func (b *SubprocessBackend) emit(ev ProgressEvent) {
+	b.progressMu.Lock()
+	defer b.progressMu.Unlock()
+	if b.EventFunc != nil {
+		b.EventFunc(ev)
+	}
 	if b.ProgressFunc != nil {
-		b.ProgressFunc(msg)
+		b.ProgressFunc(ev.Message)
 	}
 }
 
+// loadProgress stamps progress events for a single LoadModel call.
+type loadProgress struct {
+	b     *SubprocessBackend
+	model string
+	start time.Time
+}
+
+// newLoadProgress starts tracking progress for loading the model at path.
+func (b *SubprocessBackend) newLoadProgress(path string) *loadProgress {
+	return &loadProgress{b: b, model: filepath.Base(path), start: time.Now()}
+}
+
+// progress emits a status message for the given stage.
+func (p *loadProgress) progress(stage LoadStage, msg string) {
+	p.b.emit(ProgressEvent{
+		Stage:   stage,
+		Model:   p.model,
+		Percent: stagePercent[stage],
+		Elapsed: time.Since(p.start),
+		Message: msg,
+	})
+}
+
 // findLlamaServer searches for the llama-server binary.
 func findLlamaServer(tutuHome string) (string, error) {
 	exe := "llama-server"
@@ -125,34 +273,26 @@ Alternative: Install via package manager:
 `, filepath.Join(tutuHome, "bin"))
 }
 
-// LoadModel starts a llama-server subprocess for the given GGUF file.
-func (b *SubprocessBackend) LoadModel(path string, opts LoadOptions) (ModelHandle, error) {
-	if path == "" {
-		return nil, fmt.Errorf("empty model path")
-	}
-
-	// Verify file exists and is a GGUF
-	stat, err := os.Stat(path)
-	if err != nil {
-		return nil, fmt.Errorf("model file not found: %w", err)
-	}
-
-	// Kill any orphaned llama-server processes from previous crashed runs
-	killOrphanLlamaServers()
-
-	// Find a free port
-	port, err := findFreePort()
-	if err != nil {
-		return nil, fmt.Errorf("find free port: %w", err)
-	}
+// LogLevels maps LoadOptions.LogLevel values to llama-server flags. Named
+// levels set --log-verbosity (messages above the level are dropped);
+// "verbose" logs everything.
+var LogLevels = map[string][]string{
+	"error":   {"--log-verbosity", "1"},
+	"warn":    {"--log-verbosity", "2"},
+	"info":    {"--log-verbosity", "3"},
+	"debug":   {"--log-verbosity", "4"},
+	"verbose": {"--verbose"},
+}
 
-	// Build llama-server arguments
-	args := []string{
-		"--model", path,
-		"--host", "127.0.0.1",
-		"--port", fmt.Sprintf("%d", port),
+// serverArgs builds the llama-server command line for model path.
+func serverArgs(path string, listen []string, opts LoadOptions) ([]string, error) {
+	args := append([]string{"--model", path}, listen...)
+	args = append(args,
 		"--ctx-size", fmt.Sprintf("%d", coalesce(opts.NumCtx, 4096)),
 		"--no-mmap", // Safer on Windows
+	)
+	if opts.Embedding {
+		args = append(args, "--embedding")
 	}
 
 	// GPU layers
@@ -168,7 +308,78 @@ func (b *SubprocessBackend) LoadModel(path string, opts LoadOptions) (ModelHandl
 		args = append(args, "--threads", fmt.Sprintf("%d", opts.NumThreads))
 	}
 
-	b.progress("Starting llama-server...")
+	// Log level
+	if opts.LogLevel != "" {
+		flags, ok := LogLevels[strings.ToLower(opts.LogLevel)]
+		if !ok {
+			return nil, fmt.Errorf("unknown llama-server log level %q", opts.LogLevel)
+		}
+		args = append(args, flags...)
+	}
+	return args, nil
+}
+
+// LoadModel starts a llama-server subprocess for the given GGUF file.
+func (b *SubprocessBackend) LoadModel(path string, opts LoadOptions) (ModelHandle, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty model path")
+	}
+
+	// Verify file exists and is a GGUF
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("model file not found: %w", err)
+	}
+
+	lp := b.newLoadProgress(path)
+
+	// Kill llama-servers left behind by a previous crashed run of TuTu
+	b.cleanup.Do(b.killOrphans)
+
+	// Pick a listen address: a private Unix socket if enabled, else a free
+	// localhost port.
+	var (
+		port      int
+		addr      string
+		transport *http.Transport
+		sockDir   string
+		listen    []string
+	)
+	if b.UnixSocket && runtime.GOOS != "windows" {
+		sockDir, err = os.MkdirTemp("", "tutu-llama-")
+		if err != nil {
+			return nil, fmt.Errorf("create socket dir: %w", err)
+		}
+		// llama-server binds a Unix socket when --host ends in ".sock".
+		sockPath := filepath.Join(sockDir, "llama-server.sock")
+		listen = []string{"--host", sockPath}
+		addr = "http://llama-server" // host is unused — the dialer always targets sockPath
+		transport = newLlamaTransport(sockPath)
+	} else {
+		port, err = findFreePort()
+		if err != nil {
+			return nil, fmt.Errorf("find free port: %w", err)
+		}
+		listen = []string{"--host", "127.0.0.1", "--port", fmt.Sprintf("%d", port)}
+		transport = newLlamaTransport("")
+		addr = fmt.Sprintf("http://127.0.0.1:%d", port)
+	}
+	loaded := false
+	defer func() {
+		if !loaded && sockDir != "" {
+			os.RemoveAll(sockDir)
+		}
+	}()
+
+	if opts.LogLevel == "" {
+		opts.LogLevel = b.LogLevel
+	}
+	args, err := serverArgs(path, listen, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	lp.progress(StageStarting, "Starting llama-server...")
 
 	// Capture stderr in a ring buffer for diagnostics
 	stderrBuf := &limitedBuffer{max: 8192}
@@ -177,6 +388,11 @@ func (b *SubprocessBackend) LoadModel(path string, opts LoadOptions) (ModelHandl
 	cmd := exec.Command(b.llamaServerPath, args...)
 	cmd.Stdout = io.Discard
 	cmd.Stderr = stderrBuf
+	var logs *lineWriter
+	if b.LogOutput != nil {
+		logs = &lineWriter{prefix: "[llama-server " + filepath.Base(path) + "] ", out: b.LogOutput, mu: &b.logMu}
+		cmd.Stderr = io.MultiWriter(stderrBuf, logs)
+	}
 
 	// On Windows, don't show console window + allow clean kill
 	configureProcess(cmd)
@@ -184,24 +400,43 @@ func (b *SubprocessBackend) LoadModel(path string, opts LoadOptions) (ModelHandl
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("start llama-server: %w", err)
 	}
-
-	addr := fmt.Sprintf("http://127.0.0.1:%d", port)
+	if b.servers != nil {
+		b.servers.add(cmd.Process.Pid, b.llamaServerPath)
+		defer func() {
+			if !loaded {
+				b.servers.remove(cmd.Process.Pid)
+			}
+		}()
+	}
+	if err := applyProcessLimits(cmd, b.Limits); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, fmt.Errorf("limit llama-server resources: %w", err)
+	}
 
 	// Monitor for early exit in the background
 	earlyExit := make(chan error, 1)
 	go func() {
 		err := cmd.Wait()
+		if logs != nil {
+			logs.Flush()
+		}
 		earlyExit <- err
 	}()
 
 	// Wait for server to become ready with progress feedback
 	modelSize := float64(stat.Size()) / (1024 * 1024)
-	b.progress(fmt.Sprintf("Loading model (%.0f MB) — this may take a minute...", modelSize))
+	lp.progress(StageLoading, fmt.Sprintf("Loading model (%.0f MB) — this may take a minute...", modelSize))
 
-	if err := waitForServerWithFeedback(addr, 5*time.Minute, earlyExit, stderrBuf, b.ProgressFunc); err != nil {
+	loadingFn := func(msg string) { lp.progress(StageLoading, msg) }
+	if err := waitForServerWithFeedback(addr, transport, 5*time.Minute, earlyExit, stderrBuf, loadingFn); err != nil {
 		cmd.Process.Kill()
+		lp.progress(StageFailed, fmt.Sprintf("llama-server failed to start: %v", err))
 		// Include llama-server stderr in error for diagnostics
 		stderr := strings.TrimSpace(stderrBuf.String())
+		if isMemoryError(stderr) {
+			err = fmt.Errorf("%w: %w", domain.ErrInsufficientMemory, err)
+		}
 		if stderr != "" {
 			// Extract last few lines of stderr for the most useful info
 			lines := strings.Split(stderr, "\n")
@@ -214,18 +449,64 @@ func (b *SubprocessBackend) LoadModel(path string, opts LoadOptions) (ModelHandl
 		return nil, fmt.Errorf("llama-server failed to start (model: %s): %w", filepath.Base(path), err)
 	}
 
-	b.progress("Model loaded — ready!")
-
-	return &SubprocessHandle{
+	h := &SubprocessHandle{
 		cmd:     cmd,
 		addr:    addr,
 		port:    port,
+		sockDir: sockDir,
 		path:    path,
 		memSize: uint64(stat.Size()), // Approximate — model file size
-		client: &http.Client{
-			Timeout: 10 * time.Minute, // Long timeout for generation
-		},
-	}, nil
+		embed:   opts.Embedding,
+		client:  &http.Client{Transport: transport},
+		health:  &http.Client{Timeout: healthCheckTimeout, Transport: transport},
+		servers: b.servers,
+	}
+	h.embedLimit = newAIMDLimiter(b.EmbedMaxConcurrency, b.EmbedLatencyTarget)
+	h.caps = probeCapabilities(h.health, addr)
+	if h.caps != nil {
+		log.Printf("[engine] %s: llama-server build %q (chat=%t vision=%t)",
+			filepath.Base(path), h.caps.Build, h.caps.Chat, h.caps.Vision)
+	}
+	loaded = true
+	if !opts.Embedding { // embedding servers cannot generate
+		b.warmup(lp, h)
+	}
+
+	lp.progress(StageReady, "Model loaded — ready!")
+	return h, nil
+}
+
+// warmup runs a tiny throwaway generation so the first user request does
+// not pay for cold caches. Failures are reported but never fail the load.
+func (b *SubprocessBackend) warmup(lp *loadProgress, h ModelHandle) {
+	if b.WarmupTimeout <= 0 {
+		return
+	}
+	lp.progress(StageWarmup, "Warming up model...")
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), b.WarmupTimeout)
+	defer cancel()
+
+	tokens, err := h.Generate(ctx, "Hello", GenerateParams{MaxTokens: 1})
+	if err == nil {
+		for range tokens {
+		}
+		err = ctx.Err()
+	}
+	if err != nil {
+		lp.progress(StageWarmup, fmt.Sprintf("Warmup skipped after %s: %v", time.Since(start).Round(time.Millisecond), err))
+		return
+	}
+	lp.progress(StageWarmup, fmt.Sprintf("Warmup complete in %s", time.Since(start).Round(time.Millisecond)))
+}
+
+// killOrphans kills llama-servers recorded by TuTu processes that have
+// since exited, then gives the OS a moment to release their ports.
+func (b *SubprocessBackend) killOrphans() {
+	if b.servers != nil && b.servers.killOrphans() > 0 {
+		time.Sleep(500 * time.Millisecond)
+	}
 }
 
 // Close releases the backend (noop — handles close individually).
@@ -238,20 +519,168 @@ type SubprocessHandle struct {
 	cmd     *exec.Cmd
 	addr    string
 	port    int
+	sockDir string // private Unix socket directory, removed on Close ("" for TCP)
 	path    string
 	memSize uint64
-	client  *http.Client
-	mu      sync.Mutex // protects closed
+	embed   bool         // started with --embedding
+	client  *http.Client // generation requests (no timeout; bounded per request by acquire)
+	health  *http.Client // health, slot, and shutdown probes (short timeout); shares client's transport
+	mu      sync.Mutex   // protects closed, abort, caps, and embedLimit
 	closed  bool
+	caps    *ServerCapabilities // probed once after launch; nil if unknown
+
+	// refs counts in-flight Generate/Chat/Embed calls (including open
+	// streams). Close waits up to drainTimeout (default closeDrainTimeout)
+	// for them, then closes abort to cancel the stragglers.
+	refs         sync.WaitGroup
+	abort        chan struct{}
+	drainTimeout time.Duration
+
+	servers *serverRegistry // forgets this server's PID on Close
+
+	// embedLimit adapts Embed concurrency; see embedLimiter.
+	embedLimit *aimdLimiter
+	// embedSince measures an Embed request's latency from its start;
+	// nil means time.Since. Injectable for testing.
+	embedSince func(time.Time) time.Duration
+}
+
+// errModelClosed is returned by calls made after Close.
+var errModelClosed = errors.New("model is closed")
+
+// acquire registers an in-flight call, or fails once Close has been
+// requested. The returned context expires after timeout (never if timeout
+// is 0) and is cancelled if Close gives up waiting; release must be called
+// exactly once, after any stream has finished.
+func (h *SubprocessHandle) acquire(ctx context.Context, timeout time.Duration) (context.Context, func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, nil, errModelClosed
+	}
+	if h.abort == nil {
+		h.abort = make(chan struct{})
+	}
+	h.refs.Add(1)
+
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	go func(abort <-chan struct{}) {
+		select {
+		case <-abort:
+			cancel()
+		case <-ctx.Done():
+		}
+	}(h.abort)
+	return ctx, func() {
+		cancel()
+		h.refs.Done()
+	}, nil
+}
+
+// ─── Capabilities ───────────────────────────────────────────────────────────
+// llama-server builds differ in what they serve: old builds lack the
+// OpenAI-compatible API, and image inputs need a multimodal projector. The
+// handle probes once after launch so unsupported calls fail with
+// domain.ErrUnsupported instead of an opaque 404.
+
+// probeCapabilities queries /props (build info, modalities) and /v1/models
+// (OpenAI-compatible API). Returns nil if the server answered neither.
+func probeCapabilities(c *http.Client, addr string) *ServerCapabilities {
+	var caps ServerCapabilities
+	answered := false
+
+	if resp, err := c.Get(addr + "/props"); err == nil {
+		answered = true
+		if resp.StatusCode == http.StatusOK {
+			var props struct {
+				BuildInfo  string `json:"build_info"`
+				Modalities struct {
+					Vision bool `json:"vision"`
+				} `json:"modalities"`
+			}
+			if json.NewDecoder(resp.Body).Decode(&props) == nil {
+				caps.Build = props.BuildInfo
+				caps.Vision = props.Modalities.Vision
+			}
+		}
+		drainClose(resp.Body)
+	}
+
+	if resp, err := c.Get(addr + "/v1/models"); err == nil {
+		answered = true
+		caps.Chat = resp.StatusCode != http.StatusNotFound
+		drainClose(resp.Body)
+	}
+
+	if !answered {
+		return nil
+	}
+	return &caps
+}
+
+// Capabilities implements CapabilityReporter. known is false if the probe
+// failed, in which case every call is attempted.
+func (h *SubprocessHandle) Capabilities() (ServerCapabilities, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.caps == nil {
+		return ServerCapabilities{}, false
+	}
+	return *h.caps, true
+}
+
+// checkChat refuses a chat request the server is known not to support.
+func (h *SubprocessHandle) checkChat(messages []ChatMessage) error {
+	caps, known := h.Capabilities()
+	if !known {
+		return nil
+	}
+	if !caps.Chat {
+		return fmt.Errorf("chat completions: %w", domain.ErrUnsupported)
+	}
+	if !caps.Vision {
+		for _, m := range messages {
+			if len(m.Images) > 0 {
+				return fmt.Errorf("image inputs: %w", domain.ErrUnsupported)
+			}
+		}
+	}
+	return nil
+}
+
+// markChatUnsupported records that the chat endpoint is missing, for
+// servers whose probe could not tell.
+func (h *SubprocessHandle) markChatUnsupported() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.caps == nil {
+		h.caps = &ServerCapabilities{}
+	}
+	h.caps.Chat = false
 }
 
 // Generate sends a completion request to llama-server and streams tokens back.
 func (h *SubprocessHandle) Generate(ctx context.Context, prompt string, params GenerateParams) (<-chan domain.Token, error) {
-	h.mu.Lock()
-	closed := h.closed
-	h.mu.Unlock()
-	if closed {
-		return nil, fmt.Errorf("model is closed")
+	ctx, release, err := h.acquire(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	ctx, touch, cancel := params.deadline(ctx, DefaultGenerateTimeout)
+	streaming := false
+	defer func() {
+		if !streaming {
+			cancel()
+			release()
+		}
+	}()
+	params, err = params.Sanitize()
+	if err != nil {
+		return nil, err
 	}
 
 	// Build request body for llama-server /completion endpoint
@@ -270,6 +699,9 @@ func (h *SubprocessHandle) Generate(ctx context.Context, prompt string, params G
 	if len(params.Stop) > 0 {
 		body["stop"] = params.Stop
 	}
+	if params.Seed != 0 {
+		body["seed"] = params.Seed
+	}
 
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
@@ -282,6 +714,7 @@ func (h *SubprocessHandle) Generate(ctx context.Context, prompt string, params G
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	timer := newStreamTimer()
 	resp, err := h.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("llama-server request failed: %w", err)
@@ -293,10 +726,14 @@ func (h *SubprocessHandle) Generate(ctx context.Context, prompt string, params G
 		return nil, fmt.Errorf("llama-server error %d: %s", resp.StatusCode, string(body))
 	}
 
+	resp.Body = touchBody{resp.Body, touch}
 	ch := make(chan domain.Token, 64)
+	streaming = true
 	go func() {
+		defer release()
+		defer cancel()
 		defer close(ch)
-		defer resp.Body.Close()
+		defer drainClose(resp.Body)
 
 		scanner := bufio.NewScanner(resp.Body)
 		// Increase buffer for long lines
@@ -315,20 +752,29 @@ func (h *SubprocessHandle) Generate(ctx context.Context, prompt string, params G
 			}
 
 			var chunk struct {
-				Content string `json:"content"`
-				Stop    bool   `json:"stop"`
+				Content      string `json:"content"`
+				Stop         bool   `json:"stop"`
+				StopType     string `json:"stop_type"`     // "eos", "word", "limit" (newer llama-server)
+				StoppedLimit bool   `json:"stopped_limit"` // older llama-server
+				// Set on the final chunk only.
+				TokensEvaluated int `json:"tokens_evaluated"` // prompt tokens
+				TokensPredicted int `json:"tokens_predicted"` // completion tokens
 			}
 			if err := json.Unmarshal([]byte(jsonData), &chunk); err != nil {
 				continue
 			}
 
+			timer.observe(chunk.Content)
+			tok := domain.Token{Text: chunk.Content, Done: chunk.Stop}
+			if chunk.Stop {
+				tok.FinishReason = completionFinishReason(chunk.StopType, chunk.StoppedLimit)
+				tok.Stats = timer.stats(chunk.TokensEvaluated, chunk.TokensPredicted)
+			}
+
 			select {
 			case <-ctx.Done():
 				return
-			case ch <- domain.Token{
-				Text: chunk.Content,
-				Done: chunk.Stop,
-			}:
+			case ch <- tok:
 			}
 
 			if chunk.Stop {
@@ -340,22 +786,45 @@ func (h *SubprocessHandle) Generate(ctx context.Context, prompt string, params G
 	return ch, nil
 }
 
+// completionFinishReason maps llama-server's /completion stop details to a
+// domain finish reason.
+func completionFinishReason(stopType string, stoppedLimit bool) string {
+	if stopType == "limit" || stoppedLimit {
+		return domain.FinishLength
+	}
+	return domain.FinishStop
+}
+
 // Chat sends a chat completion request to llama-server using the /v1/chat/completions
 // endpoint. This lets llama-server apply the model's native chat template automatically
 // (llama3, chatml, phi3, gemma, mistral, etc).
 func (h *SubprocessHandle) Chat(ctx context.Context, messages []ChatMessage, params GenerateParams) (<-chan domain.Token, error) {
-	h.mu.Lock()
-	closed := h.closed
-	h.mu.Unlock()
-	if closed {
-		return nil, fmt.Errorf("model is closed")
+	ctx, release, err := h.acquire(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	ctx, touch, cancel := params.deadline(ctx, DefaultChatTimeout)
+	streaming := false
+	defer func() {
+		if !streaming {
+			cancel()
+			release()
+		}
+	}()
+	if err := h.checkChat(messages); err != nil {
+		return nil, err
+	}
+	params, err = params.Sanitize()
+	if err != nil {
+		return nil, err
 	}
 
 	body := map[string]interface{}{
-		"messages":    messages,
-		"stream":      true,
-		"temperature": params.Temperature,
-		"top_p":       params.TopP,
+		"messages":       messages,
+		"stream":         true,
+		"stream_options": map[string]bool{"include_usage": true},
+		"temperature":    params.Temperature,
+		"top_p":          params.TopP,
 	}
 	if params.MaxTokens > 0 {
 		body["max_tokens"] = params.MaxTokens
@@ -365,6 +834,9 @@ func (h *SubprocessHandle) Chat(ctx context.Context, messages []ChatMessage, par
 	if len(params.Stop) > 0 {
 		body["stop"] = params.Stop
 	}
+	if params.Seed != 0 {
+		body["seed"] = params.Seed
+	}
 
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
@@ -377,117 +849,266 @@ func (h *SubprocessHandle) Chat(ctx context.Context, messages []ChatMessage, par
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	timer := newStreamTimer()
 	resp, err := h.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("llama-server chat request failed: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusNotFound {
+		drainClose(resp.Body)
+		h.markChatUnsupported()
+		return nil, fmt.Errorf("chat completions: %w", domain.ErrUnsupported)
+	}
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
 		return nil, fmt.Errorf("llama-server chat error %d: %s", resp.StatusCode, string(respBody))
 	}
 
+	resp.Body = touchBody{resp.Body, touch}
 	ch := make(chan domain.Token, 64)
+	streaming = true
 	go func() {
+		defer release()
+		defer cancel()
 		defer close(ch)
-		defer resp.Body.Close()
+		defer drainClose(resp.Body)
 
-		scanner := bufio.NewScanner(resp.Body)
-		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		readChatStream(ctx, resp.Body, ch, timer)
+	}()
 
-		for scanner.Scan() {
-			line := scanner.Text()
-			if !strings.HasPrefix(line, "data: ") {
-				continue
-			}
-			jsonData := strings.TrimPrefix(line, "data: ")
-			if jsonData == "" || jsonData == "[DONE]" {
-				continue
-			}
+	return ch, nil
+}
 
-			// OpenAI-compatible streaming format
-			var chunk struct {
-				Choices []struct {
-					Delta struct {
-						Content string `json:"content"`
-					} `json:"delta"`
-					FinishReason *string `json:"finish_reason"`
-				} `json:"choices"`
-			}
-			if err := json.Unmarshal([]byte(jsonData), &chunk); err != nil {
-				continue
-			}
+// readChatStream forwards an OpenAI-compatible SSE chat completion stream
+// from r to ch. The chunk carrying a finish reason becomes the terminal
+// token, but it is held until [DONE] or EOF so that a trailing usage chunk
+// (stream_options.include_usage) can feed its stats. A stream that ends
+// without a finish reason sends no terminal token.
+func readChatStream(ctx context.Context, r io.Reader, ch chan<- domain.Token, timer *streamTimer) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var final *domain.Token
+	var promptTokens, completionTokens int
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		jsonData := strings.TrimPrefix(line, "data: ")
+		if jsonData == "[DONE]" {
+			break
+		}
+		if jsonData == "" {
+			continue
+		}
 
-			if len(chunk.Choices) > 0 {
-				content := chunk.Choices[0].Delta.Content
-				done := chunk.Choices[0].FinishReason != nil
-
-				if content != "" || done {
-					select {
-					case <-ctx.Done():
-						return
-					case ch <- domain.Token{
-						Text: content,
-						Done: done,
-					}:
-					}
-				}
+		// OpenAI-compatible streaming format
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+				FinishReason *string `json:"finish_reason"`
+			} `json:"choices"`
+			Usage *struct {
+				PromptTokens     int `json:"prompt_tokens"`
+				CompletionTokens int `json:"completion_tokens"`
+			} `json:"usage"`
+		}
+		if err := json.Unmarshal([]byte(jsonData), &chunk); err != nil {
+			continue
+		}
+		if chunk.Usage != nil {
+			promptTokens, completionTokens = chunk.Usage.PromptTokens, chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) == 0 || final != nil {
+			continue
+		}
 
-				if done {
-					return
-				}
-			}
+		content := chunk.Choices[0].Delta.Content
+		timer.observe(content)
+		if fr := chunk.Choices[0].FinishReason; fr != nil {
+			final = &domain.Token{Text: content, Done: true, FinishReason: *fr}
+			continue
 		}
-	}()
+		if content == "" {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case ch <- domain.Token{Text: content}:
+		}
+	}
 
-	return ch, nil
+	if final == nil {
+		return
+	}
+	final.Stats = timer.stats(promptTokens, completionTokens)
+	select {
+	case <-ctx.Done():
+	case ch <- *final:
+	}
 }
 
-// Embed generates embeddings via llama-server /embedding endpoint.
+// Embed generates embeddings via llama-server /embedding endpoint, one
+// request per input, with adaptive concurrency (see aimdLimiter). The first
+// failed request cancels the rest.
 func (h *SubprocessHandle) Embed(ctx context.Context, input []string) ([][]float32, error) {
-	h.mu.Lock()
-	closed := h.closed
-	h.mu.Unlock()
-	if closed {
-		return nil, fmt.Errorf("model is closed")
+	ctx, release, err := h.acquire(ctx, embedTimeout)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
+	limiter := h.embedLimiter()
+	since := h.embedSince
+	if since == nil {
+		since = time.Since
+	}
 	results := make([][]float32, len(input))
+	var (
+		wg       sync.WaitGroup
+		failOnce sync.Once
+		failure  error
+	)
 	for i, text := range input {
-		body, _ := json.Marshal(map[string]interface{}{
-			"content": text,
-		})
-
-		req, err := http.NewRequestWithContext(ctx, "POST", h.addr+"/embedding", strings.NewReader(string(body)))
-		if err != nil {
-			return nil, err
+		token := limiter.acquire()
+		if ctx.Err() != nil {
+			limiter.abandon()
+			break
 		}
-		req.Header.Set("Content-Type", "application/json")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			vec, err := h.embedOne(ctx, text)
+			if err != nil {
+				// A request canceled by the caller, or after another one
+				// failed, says nothing about the server; anything else
+				// (a timeout, a 503) means it is overloaded.
+				if errors.Is(ctx.Err(), context.Canceled) {
+					limiter.abandon()
+				} else {
+					limiter.fail(token)
+				}
+				failOnce.Do(func() {
+					failure = err
+					cancel()
+				})
+				return
+			}
+			limiter.release(token, since(start))
+			results[i] = vec
+		}()
+	}
+	wg.Wait()
 
-		resp, err := h.client.Do(req)
-		if err != nil {
-			return nil, err
-		}
+	if failure != nil {
+		return nil, failure
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
 
-		var result struct {
-			Embedding []float32 `json:"embedding"`
-		}
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			resp.Body.Close()
-			return nil, err
-		}
-		resp.Body.Close()
-		results[i] = result.Embedding
+// embedOne embeds a single input.
+func (h *SubprocessHandle) embedOne(ctx context.Context, text string) ([]float32, error) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"content": text,
+	})
+
+	req, err := http.NewRequestWithContext(ctx, "POST", h.addr+"/embedding", strings.NewReader(string(body)))
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	return results, nil
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Embedding []float32 `json:"embedding"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	drainClose(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return result.Embedding, nil
 }
 
+// embedLimiter returns the handle's embedding limiter, creating one with
+// the default bounds for handles not built by LoadModel.
+func (h *SubprocessHandle) embedLimiter() *aimdLimiter {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.embedLimit == nil {
+		h.embedLimit = newAIMDLimiter(0, 0)
+	}
+	return h.embedLimit
+}
+
+// EmbeddingMode reports whether llama-server was started to serve
+// embeddings.
+func (h *SubprocessHandle) EmbeddingMode() bool { return h.embed }
+
 // MemoryBytes returns approximate memory usage (file size as proxy).
 func (h *SubprocessHandle) MemoryBytes() uint64 { return h.memSize }
 
-// Close kills the llama-server subprocess and frees resources.
+// CacheStats reports KV cache slot occupancy via llama-server's /slots
+// endpoint. Each slot holds one conversation's context; when none are free,
+// llama-server reuses a slot and the evicted conversation loses its cache.
+func (h *SubprocessHandle) CacheStats() (usedSlots, totalSlots int, err error) {
+	h.mu.Lock()
+	closed := h.closed
+	h.mu.Unlock()
+	if closed {
+		return 0, 0, errModelClosed
+	}
+
+	resp, err := h.health.Get(h.addr + "/slots")
+	if err != nil {
+		return 0, 0, fmt.Errorf("query slots: %w", err)
+	}
+	defer drainClose(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		// 501 when llama-server runs with --no-slots.
+		return 0, 0, fmt.Errorf("query slots: llama-server returned %d", resp.StatusCode)
+	}
+
+	// Newer llama-server builds report is_processing; older ones report
+	// state (0 = idle, 1 = processing).
+	var slots []struct {
+		IsProcessing *bool `json:"is_processing"`
+		State        int   `json:"state"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&slots); err != nil {
+		return 0, 0, fmt.Errorf("decode slots: %w", err)
+	}
+	for _, s := range slots {
+		if s.IsProcessing != nil {
+			if *s.IsProcessing {
+				usedSlots++
+			}
+		} else if s.State != 0 {
+			usedSlots++
+		}
+	}
+	return usedSlots, len(slots), nil
+}
+
+// Close kills the llama-server subprocess and frees resources. New calls
+// are rejected immediately; in-flight calls get up to the drain timeout to
+// finish before they are cancelled and the process is killed.
 // Thread-safe: uses mutex to prevent concurrent close races.
 func (h *SubprocessHandle) Close() {
 	h.mu.Lock()
@@ -496,14 +1117,31 @@ func (h *SubprocessHandle) Close() {
 		return
 	}
 	h.closed = true
+	abort := h.abort
 	h.mu.Unlock()
 
+	if abort != nil {
+		timeout := h.drainTimeout
+		if timeout <= 0 {
+			timeout = closeDrainTimeout
+		}
+		drained := make(chan struct{})
+		go func() {
+			h.refs.Wait()
+			close(drained)
+		}()
+		select {
+		case <-drained:
+		case <-time.After(timeout):
+			log.Printf("[engine] %s: in-flight requests still running after %s — aborting", filepath.Base(h.path), timeout)
+			close(abort)
+			<-drained
+		}
+	}
+
 	// Graceful shutdown: try /shutdown endpoint first
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
-	req, _ := http.NewRequestWithContext(ctx, "POST", h.addr+"/shutdown", nil)
-	if req != nil {
-		h.client.Do(req) //nolint:errcheck
+	if resp, err := h.health.Post(h.addr+"/shutdown", "", nil); err == nil {
+		resp.Body.Close()
 	}
 
 	// Force kill the process and wait for it to exit.
@@ -522,6 +1160,12 @@ func (h *SubprocessHandle) Close() {
 			// Process didn't exit, force it
 		}
 	}
+	if h.sockDir != "" {
+		os.RemoveAll(h.sockDir)
+	}
+	if h.servers != nil && h.cmd != nil && h.cmd.Process != nil {
+		h.servers.remove(h.cmd.Process.Pid)
+	}
 }
 
 // ─── Helpers ────────────────────────────────────────────────────────────────
@@ -537,45 +1181,115 @@ func findFreePort() (int, error) {
 	return port, nil
 }
 
+// Timeouts for SubprocessHandle requests. Generation and chat deadlines
+// come from GenerateParams; probes use the health client's timeout.
+const (
+	embedTimeout       = 10 * time.Minute // a whole Embed batch
+	healthCheckTimeout = 2 * time.Second
+)
+
+// closeDrainTimeout bounds how long Close waits for in-flight calls.
+const closeDrainTimeout = 30 * time.Second
+
+// newLlamaTransport returns the HTTP transport shared by a handle's clients.
+// It talks to a single local llama-server, so it keeps enough idle
+// keep-alive connections to absorb a burst of concurrent requests (the
+// default transport keeps only 2 per host and would redial the rest).
+// With a non-empty sockPath every request goes over that Unix domain
+// socket, whatever the URL's host.
+func newLlamaTransport(sockPath string) *http.Transport {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}
+	t := &http.Transport{
+		DialContext:         dialer.DialContext,
+		MaxIdleConns:        64,
+		MaxIdleConnsPerHost: 64,
+		IdleConnTimeout:     90 * time.Second,
+		DisableCompression:  true, // loopback — compression only costs CPU
+	}
+	if sockPath != "" {
+		t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", sockPath)
+		}
+	}
+	return t
+}
+
+// drainClose discards what is left of a response body (up to 64 KB) before
+// closing it, so the connection returns to the idle pool instead of being
+// torn down — streams stop reading at the stop token, before the trailing
+// "[DONE]" and chunk terminator.
+func drainClose(body io.ReadCloser) {
+	io.CopyN(io.Discard, body, 64<<10) //nolint:errcheck
+	body.Close()
+}
+
+// healthBackoff is the poll schedule for waitForServerWithFeedback: start
+// short so small models are detected promptly, then double toward the cap so
+// a large model isn't pelted with health checks during a long load.
+type healthBackoff struct {
+	initial time.Duration
+	max     time.Duration
+}
+
+// defaultHealthBackoff polls at 100ms, 200ms, 400ms, ... capped at 3s.
+var defaultHealthBackoff = healthBackoff{initial: 100 * time.Millisecond, max: 3 * time.Second}
+
+// next returns the interval to use after cur.
+func (b healthBackoff) next(cur time.Duration) time.Duration {
+	if cur <= 0 {
+		return b.initial
+	}
+	cur *= 2
+	if cur > b.max {
+		cur = b.max
+	}
+	return cur
+}
+
 // waitForServerWithFeedback polls /health until ready, with progress feedback,
 // early-exit detection (if llama-server crashes, we detect it immediately), and
 // exponential backoff to avoid hammering the server during model loading.
+// transport is the handle's transport (nil uses http.DefaultTransport).
+func waitForServerWithFeedback(addr string, transport http.RoundTripper, timeout time.Duration, earlyExit <-chan error, stderrBuf *limitedBuffer, progressFn func(string)) error {
+	return pollServerHealth(addr, transport, timeout, defaultHealthBackoff, earlyExit, stderrBuf, progressFn)
+}
+
+// pollServerHealth implements waitForServerWithFeedback with an explicit
+// backoff schedule.
 //
-// DSA: Uses exponential backoff with cap (doubles poll interval up to 2s max),
-// which reduces CPU usage during long loads while remaining responsive.
-func waitForServerWithFeedback(addr string, timeout time.Duration, earlyExit <-chan error, stderrBuf *limitedBuffer, progressFn func(string)) error {
+// DSA: Exponential backoff with cap — O(log(max/initial)) ramp-up, then
+// constant-rate polling. A crash wakes the wait immediately rather than
+// after the current interval.
+func pollServerHealth(addr string, transport http.RoundTripper, timeout time.Duration, backoff healthBackoff, earlyExit <-chan error, stderrBuf *limitedBuffer, progressFn func(string)) error {
 	deadline := time.Now().Add(timeout)
-	client := &http.Client{Timeout: 2 * time.Second}
+	client := &http.Client{Timeout: healthCheckTimeout, Transport: transport}
 	start := time.Now()
 	lastMsg := time.Time{}
 
-	// Exponential backoff: start at 250ms, double each failure, cap at 2s
-	pollInterval := 250 * time.Millisecond
-	maxPollInterval := 2 * time.Second
+	exited := func(err error) error {
+		stderr := strings.TrimSpace(stderrBuf.String())
+		if stderr != "" {
+			return fmt.Errorf("llama-server exited unexpectedly (exit: %v)\n\nOutput:\n%s", err, stderr)
+		}
+		return fmt.Errorf("llama-server exited unexpectedly (exit: %v)", err)
+	}
 
+	var pollInterval time.Duration
 	for time.Now().Before(deadline) {
 		// Check if llama-server exited early (crash)
 		select {
 		case err := <-earlyExit:
-			stderr := strings.TrimSpace(stderrBuf.String())
-			if stderr != "" {
-				return fmt.Errorf("llama-server exited unexpectedly (exit: %v)\n\nOutput:\n%s", err, stderr)
-			}
-			return fmt.Errorf("llama-server exited unexpectedly (exit: %v)", err)
+			return exited(err)
 		default:
 		}
 
 		resp, err := client.Get(addr + "/health")
 		if err == nil {
-			resp.Body.Close()
+			drainClose(resp.Body)
 			if resp.StatusCode == http.StatusOK {
 				return nil
 			}
-			// Server is loading — status 503 means model is being loaded
-			if resp.StatusCode == http.StatusServiceUnavailable {
-				// Reset to short poll during active loading
-				pollInterval = 500 * time.Millisecond
-			}
+			// 503 means the model is still loading — keep backing off.
 		}
 
 		// Show progress every 5 seconds so user knows we're still working
@@ -585,19 +1299,47 @@ func waitForServerWithFeedback(addr string, timeout time.Duration, earlyExit <-c
 			lastMsg = time.Now()
 		}
 
-		time.Sleep(pollInterval)
+		pollInterval = backoff.next(pollInterval)
+		wait := pollInterval
+		if remaining := time.Until(deadline); remaining < wait {
+			wait = remaining
+		}
 
-		// Exponential backoff with cap
-		if pollInterval < maxPollInterval {
-			pollInterval = pollInterval * 2
-			if pollInterval > maxPollInterval {
-				pollInterval = maxPollInterval
-			}
+		timer := time.NewTimer(wait)
+		select {
+		case err := <-earlyExit:
+			timer.Stop()
+			return exited(err)
+		case <-timer.C:
 		}
 	}
 	return fmt.Errorf("server at %s did not become ready within %v", addr, timeout)
 }
 
+// memoryErrorMarkers are lowercase fragments of the messages llama-server
+// and its ggml backends print when model weights or the KV cache do not fit.
+var memoryErrorMarkers = []string{
+	"out of memory",
+	"failed to allocate",
+	"unable to allocate",
+	"cannot allocate memory",
+	"bad_alloc",
+	"cudamalloc failed",
+	"insufficient memory",
+}
+
+// isMemoryError reports whether llama-server's stderr shows that a load
+// failed for lack of memory.
+func isMemoryError(stderr string) bool {
+	stderr = strings.ToLower(stderr)
+	for _, m := range memoryErrorMarkers {
+		if strings.Contains(stderr, m) {
+			return true
+		}
+	}
+	return false
+}
+
 // limitedBuffer is a thread-safe buffer that keeps only the last N bytes.
 // Used to capture llama-server stderr without unbounded memory usage.
 type limitedBuffer struct {
@@ -625,25 +1367,42 @@ func (b *limitedBuffer) String() string {
 	return b.buf.String()
 }
 
-// killOrphanLlamaServers kills any leftover llama-server processes from
-// previous crashed runs. This prevents port/file conflicts.
-func killOrphanLlamaServers() {
-	if runtime.GOOS == "windows" {
-		// On Windows, use taskkill to kill any llama-server.exe processes
-		// that are not part of the current process tree. Silently ignore errors.
-		cmd := exec.Command("taskkill", "/f", "/im", "llama-server.exe")
-		cmd.Stdout = io.Discard
-		cmd.Stderr = io.Discard
-		cmd.Run() // Ignore error — it's fine if no process is found
-	} else {
-		// On Unix, use pkill with -f flag
-		cmd := exec.Command("pkill", "-f", "llama-server")
-		cmd.Stdout = io.Discard
-		cmd.Stderr = io.Discard
-		cmd.Run() // Ignore error
-	}
-	// Small delay to let the OS release ports
-	time.Sleep(500 * time.Millisecond)
+// lineWriter forwards complete lines to out, each with prefix. mu is shared
+// by every lineWriter on the same out so lines from concurrent servers
+// never interleave. A trailing partial line is held until it completes.
+type lineWriter struct {
+	prefix string
+	out    io.Writer
+	mu     *sync.Mutex
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.buf[:i+1])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes any buffered partial line.
+func (w *lineWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.emit(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *lineWriter) emit(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	io.WriteString(w.out, w.prefix)
+	w.out.Write(line)
 }
 
 // coalesce returns the first non-zero value.
@@ -0,0 +1,1136 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Health Poll Backoff Tests ──────────────────────────────────────────────
+
+func TestHealthBackoff_NextDoublesToCap(t *testing.T) {
+	b := healthBackoff{initial: 100 * time.Millisecond, max: 1 * time.Second}
+
+	want := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		1 * time.Second,
+		1 * time.Second,
+	}
+	var cur time.Duration
+	for i, w := range want {
+		cur = b.next(cur)
+		if cur != w {
+			t.Errorf("step %d: interval = %v, want %v", i, cur, w)
+		}
+	}
+}
+
+func TestPollServerHealth_IntervalGrowsUntilReady(t *testing.T) {
+	const notReadyPolls = 5
+
+	var mu sync.Mutex
+	var hits []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits = append(hits, time.Now())
+		n := len(hits)
+		mu.Unlock()
+		if n <= notReadyPolls {
+			w.WriteHeader(http.StatusServiceUnavailable) // still loading
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	backoff := healthBackoff{initial: 10 * time.Millisecond, max: 80 * time.Millisecond}
+	err := pollServerHealth(srv.URL, nil, 5*time.Second, backoff, make(chan error), &limitedBuffer{max: 1024}, nil)
+	if err != nil {
+		t.Fatalf("pollServerHealth() error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(hits) != notReadyPolls+1 {
+		t.Fatalf("health checks = %d, want %d (stop at readiness)", len(hits), notReadyPolls+1)
+	}
+
+	// Expected gaps: 10, 20, 40, 80, 80ms. Each gap must be at least its
+	// scheduled interval, and the later gaps clearly longer than the first.
+	scheduled := []time.Duration{10, 20, 40, 80, 80}
+	for i := 1; i < len(hits); i++ {
+		gap := hits[i].Sub(hits[i-1])
+		if want := scheduled[i-1] * time.Millisecond; gap < want {
+			t.Errorf("gap %d = %v, want >= %v", i, gap, want)
+		}
+	}
+	first := hits[1].Sub(hits[0])
+	last := hits[len(hits)-1].Sub(hits[len(hits)-2])
+	if last <= first {
+		t.Errorf("poll interval did not grow: first gap %v, last gap %v", first, last)
+	}
+}
+
+func TestPollServerHealth_EarlyExitDuringBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	earlyExit := make(chan error, 1)
+	stderr := &limitedBuffer{max: 1024}
+	stderr.Write([]byte("out of memory"))
+
+	// Long interval: the crash must interrupt the wait, not wait it out.
+	backoff := healthBackoff{initial: 10 * time.Second, max: 10 * time.Second}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		earlyExit <- errors.New("exit status 1")
+	}()
+
+	start := time.Now()
+	err := pollServerHealth(srv.URL, nil, time.Minute, backoff, earlyExit, stderr, nil)
+	if err == nil {
+		t.Fatal("expected error on early exit")
+	}
+	if !strings.Contains(err.Error(), "out of memory") {
+		t.Errorf("error should include stderr, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("early exit took %v to detect", elapsed)
+	}
+}
+
+// ─── KV Cache Slot Tests ────────────────────────────────────────────────────
+
+// slotServer stubs llama-server's /slots endpoint with a fixed body.
+func slotServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/slots" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func stubHandle(srv *httptest.Server) *SubprocessHandle {
+	return &SubprocessHandle{addr: srv.URL, client: srv.Client(), health: srv.Client()}
+}
+
+func TestSubprocessHandle_CacheStats(t *testing.T) {
+	srv := slotServer(t, http.StatusOK,
+		`[{"id":0,"is_processing":true},{"id":1,"is_processing":false},{"id":2,"is_processing":true}]`)
+
+	used, total, err := stubHandle(srv).CacheStats()
+	if err != nil {
+		t.Fatalf("CacheStats() error: %v", err)
+	}
+	if used != 2 || total != 3 {
+		t.Errorf("CacheStats() = %d/%d, want 2/3", used, total)
+	}
+}
+
+func TestSubprocessHandle_CacheStats_LegacyState(t *testing.T) {
+	srv := slotServer(t, http.StatusOK, `[{"id":0,"state":1},{"id":1,"state":0}]`)
+
+	used, total, err := stubHandle(srv).CacheStats()
+	if err != nil {
+		t.Fatalf("CacheStats() error: %v", err)
+	}
+	if used != 1 || total != 2 {
+		t.Errorf("CacheStats() = %d/%d, want 1/2", used, total)
+	}
+}
+
+func TestSubprocessHandle_CacheStats_SlotsDisabled(t *testing.T) {
+	srv := slotServer(t, http.StatusNotImplemented, `{"error":"slots endpoint disabled"}`)
+
+	if _, _, err := stubHandle(srv).CacheStats(); err == nil {
+		t.Fatal("CacheStats() with --no-slots: expected error")
+	}
+}
+
+// stubBackend hands out a fixed handle regardless of path.
+type stubBackend struct{ handle ModelHandle }
+
+func (b stubBackend) LoadModel(string, LoadOptions) (ModelHandle, error) { return b.handle, nil }
+func (b stubBackend) Close()                                             {}
+
+func TestPool_EmbedRequiresEmbeddingMode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"embedding":[0.5,0.25]}`)
+	}))
+	defer srv.Close()
+	embed := func(h *SubprocessHandle) ([][]float32, error) {
+		pool := NewPool(stubBackend{handle: h}, 1<<30, func(name string) (string, error) { return name, nil })
+		ph, err := pool.Acquire("llama3", LoadOptions{Embedding: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ph.Release()
+		return ph.Model().Embed(context.Background(), []string{"x"})
+	}
+
+	_, err := embed(stubHandle(srv))
+	if !errors.Is(err, domain.ErrNotEmbeddingModel) {
+		t.Fatalf("Embed on generation handle: err = %v, want ErrNotEmbeddingModel", err)
+	}
+	if !strings.Contains(err.Error(), "LoadOptions.Embedding") {
+		t.Errorf("error %q should say how to load for embeddings", err)
+	}
+
+	h := stubHandle(srv)
+	h.embed = true
+	vecs, err := embed(h)
+	if err != nil || len(vecs) != 1 || len(vecs[0]) != 2 {
+		t.Errorf("Embed on embedding handle = %v, %v; want one vector", vecs, err)
+	}
+}
+
+// modeBackend hands out subprocess handles against srv, started in the
+// embedding mode the load asked for.
+type modeBackend struct{ srv *httptest.Server }
+
+func (b modeBackend) LoadModel(_ string, opts LoadOptions) (ModelHandle, error) {
+	h := stubHandle(b.srv)
+	h.embed = opts.Embedding
+	return h, nil
+}
+func (b modeBackend) Close() {}
+
+func TestPool_EmbedThenChatUsesSeparateReplicas(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"embedding":[0.5,0.25]}`)
+	}))
+	defer srv.Close()
+	pool := NewPool(modeBackend{srv: srv}, 1<<30, func(name string) (string, error) { return name, nil })
+
+	eh, err := pool.Acquire("llama3", LoadOptions{Embedding: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eh.Model().Embed(context.Background(), []string{"x"}); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	eh.Release()
+
+	ch, err := pool.Acquire("llama3", LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ch.Release()
+	if ch.entry == eh.entry {
+		t.Fatal("chat reused the embedding server, which cannot generate")
+	}
+	if r, ok := ch.entry.handle.(EmbeddingReporter); !ok || r.EmbeddingMode() {
+		t.Error("chat acquired a handle started for embeddings")
+	}
+
+	again, err := pool.Acquire("llama3", LoadOptions{Embedding: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer again.Release()
+	if again.entry != eh.entry {
+		t.Error("embedding should reuse the idle embedding replica")
+	}
+	if n := len(pool.models["llama3"]); n != 2 {
+		t.Errorf("loaded replicas = %d, want one per mode", n)
+	}
+}
+
+func TestPool_CacheStats(t *testing.T) {
+	srv := slotServer(t, http.StatusOK, `[{"id":0,"is_processing":true},{"id":1,"is_processing":true}]`)
+	pool := NewPool(stubBackend{handle: stubHandle(srv)}, 1<<30, func(name string) (string, error) {
+		return "/models/" + name + ".gguf", nil
+	})
+
+	if _, _, err := pool.CacheStats("llama3"); !errors.Is(err, domain.ErrModelNotLoaded) {
+		t.Fatalf("CacheStats(unloaded) error = %v, want ErrModelNotLoaded", err)
+	}
+
+	h, err := pool.Acquire("llama3", LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Release()
+
+	used, total, err := pool.CacheStats("llama3")
+	if err != nil {
+		t.Fatalf("CacheStats() error: %v", err)
+	}
+	if used != total {
+		t.Errorf("CacheStats() = %d/%d, want every slot busy", used, total)
+	}
+}
+
+func TestPool_CacheStats_Unsupported(t *testing.T) {
+	pool := newTestPool()
+	h, err := pool.Acquire("llama3", LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Release()
+
+	if _, _, err := pool.CacheStats("llama3"); err == nil {
+		t.Fatal("CacheStats() on mock handle: expected error")
+	}
+}
+
+// ─── Warmup Tests ───────────────────────────────────────────────────────────
+
+// completionServer counts /completion requests and answers with one token.
+func completionServer(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/completion" {
+			http.NotFound(w, r)
+			return
+		}
+		calls.Add(1)
+		io.WriteString(w, "data: {\"content\":\"Hi\",\"stop\":true}\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWarmup_IssuesRequestWhenEnabled(t *testing.T) {
+	var calls atomic.Int32
+	srv := completionServer(t, &calls)
+
+	var msgs []string
+	b := &SubprocessBackend{WarmupTimeout: time.Second}
+	b.SetProgress(func(m string) { msgs = append(msgs, m) })
+	b.warmup(b.newLoadProgress("m.gguf"), stubHandle(srv))
+
+	if calls.Load() != 1 {
+		t.Fatalf("warmup requests = %d, want 1", calls.Load())
+	}
+	if len(msgs) == 0 || !strings.HasPrefix(msgs[len(msgs)-1], "Warmup complete in ") {
+		t.Errorf("progress = %q, want warmup time reported", msgs)
+	}
+}
+
+func TestWarmup_SkippedWhenDisabled(t *testing.T) {
+	var calls atomic.Int32
+	srv := completionServer(t, &calls)
+
+	b := &SubprocessBackend{}
+	b.warmup(b.newLoadProgress("m.gguf"), stubHandle(srv))
+
+	if calls.Load() != 0 {
+		t.Fatalf("warmup requests = %d, want 0 when disabled", calls.Load())
+	}
+}
+
+func TestWarmup_TimeoutDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	var last string
+	b := &SubprocessBackend{WarmupTimeout: 50 * time.Millisecond}
+	b.SetProgress(func(m string) { last = m })
+
+	start := time.Now()
+	b.warmup(b.newLoadProgress("m.gguf"), stubHandle(srv))
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("warmup took %v, want bounded by timeout", elapsed)
+	}
+	if !strings.HasPrefix(last, "Warmup skipped") {
+		t.Errorf("progress = %q, want warmup skipped", last)
+	}
+}
+
+func TestLoadProgress_EventsInOrder(t *testing.T) {
+	var calls atomic.Int32
+	srv := completionServer(t, &calls)
+
+	var events []ProgressEvent
+	var msgs []string
+	b := &SubprocessBackend{WarmupTimeout: time.Second}
+	b.SetProgressEvents(func(ev ProgressEvent) { events = append(events, ev) })
+	b.SetProgress(func(m string) { msgs = append(msgs, m) })
+
+	lp := b.newLoadProgress("/models/llama3.gguf")
+	lp.progress(StageStarting, "Starting llama-server...")
+	lp.progress(StageLoading, "Loading model...")
+	b.warmup(lp, stubHandle(srv))
+	lp.progress(StageReady, "Model loaded — ready!")
+
+	want := []LoadStage{StageStarting, StageLoading, StageWarmup, StageWarmup, StageReady}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, ev := range events {
+		if ev.Stage != want[i] {
+			t.Errorf("event %d stage = %q, want %q", i, ev.Stage, want[i])
+		}
+		if ev.Model != "llama3.gguf" {
+			t.Errorf("event %d model = %q, want llama3.gguf", i, ev.Model)
+		}
+		if ev.Message != msgs[i] {
+			t.Errorf("event %d message = %q, string callback got %q", i, ev.Message, msgs[i])
+		}
+		if i > 0 && (ev.Percent < events[i-1].Percent || ev.Elapsed < events[i-1].Elapsed) {
+			t.Errorf("event %d went backwards: %+v after %+v", i, ev, events[i-1])
+		}
+	}
+	if last := events[len(events)-1]; last.Percent != 100 {
+		t.Errorf("ready percent = %d, want 100", last.Percent)
+	}
+}
+
+func TestLoadProgress_ConcurrentLoadsSerialized(t *testing.T) {
+	const loads, perLoad = 8, 50
+
+	var inFlight atomic.Int32
+	var overlapped atomic.Bool
+	count := 0 // unsynchronized on purpose — the race detector flags unserialized callbacks
+	b := &SubprocessBackend{}
+	b.SetProgressEvents(func(ProgressEvent) {
+		if inFlight.Add(1) > 1 {
+			overlapped.Store(true)
+		}
+		count++
+		inFlight.Add(-1)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < loads; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lp := b.newLoadProgress(fmt.Sprintf("model-%d.gguf", i))
+			for j := 0; j < perLoad; j++ {
+				lp.progress(StageLoading, "Loading model...")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if overlapped.Load() {
+		t.Error("progress callback invoked concurrently")
+	}
+	if count != loads*perLoad {
+		t.Errorf("events = %d, want %d", count, loads*perLoad)
+	}
+}
+
+// streamServer replays SSE lines for whichever endpoint is hit.
+func streamServer(t *testing.T, lines ...string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, l := range lines {
+			io.WriteString(w, "data: "+l+"\n\n")
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// lastToken drains tokens and returns the final one.
+func lastToken(t *testing.T, ch <-chan domain.Token, err error) domain.Token {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var last domain.Token
+	for tok := range ch {
+		last = tok
+	}
+	return last
+}
+
+func TestGenerate_FinishReason(t *testing.T) {
+	tests := []struct {
+		name  string
+		final string
+		want  string
+	}{
+		{"stop sequence", `{"content":"","stop":true,"stop_type":"word"}`, domain.FinishStop},
+		{"eos", `{"content":"","stop":true,"stop_type":"eos"}`, domain.FinishStop},
+		{"max tokens", `{"content":"","stop":true,"stop_type":"limit"}`, domain.FinishLength},
+		{"max tokens (legacy)", `{"content":"","stop":true,"stopped_limit":true}`, domain.FinishLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := streamServer(t, `{"content":"Hi","stop":false}`, tt.final)
+			ch, err := stubHandle(srv).Generate(context.Background(), "Hello", GenerateParams{})
+			tok := lastToken(t, ch, err)
+			if !tok.Done || tok.FinishReason != tt.want {
+				t.Errorf("terminal token = %+v, want done with finish reason %q", tok, tt.want)
+			}
+		})
+	}
+}
+
+func TestChat_FinishReason(t *testing.T) {
+	srv := streamServer(t,
+		`{"choices":[{"delta":{"content":"Hi"},"finish_reason":null}]}`,
+		`{"choices":[{"delta":{},"finish_reason":"length"}]}`,
+	)
+	ch, err := stubHandle(srv).Chat(context.Background(), []ChatMessage{{Role: "user", Content: "Hello"}}, GenerateParams{})
+	tok := lastToken(t, ch, err)
+	if !tok.Done || tok.FinishReason != domain.FinishLength {
+		t.Errorf("terminal token = %+v, want done with finish reason %q", tok, domain.FinishLength)
+	}
+}
+
+// pacedServer streams SSE lines like streamServer, but waits firstDelay
+// before the first line, as llama-server does while processing the prompt.
+func pacedServer(t *testing.T, firstDelay time.Duration, lines ...string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		time.Sleep(firstDelay)
+		for _, l := range lines {
+			io.WriteString(w, "data: "+l+"\n\n")
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGenerate_StreamStats(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	srv := pacedServer(t, delay,
+		`{"content":"Hi","stop":false}`,
+		`{"content":" there","stop":false}`,
+		`{"content":"","stop":true,"stop_type":"eos","tokens_evaluated":12,"tokens_predicted":2}`,
+	)
+	ch, err := stubHandle(srv).Generate(context.Background(), "Hello", GenerateParams{})
+	st := lastToken(t, ch, err).Stats
+	if st == nil {
+		t.Fatal("terminal token carries no stats")
+	}
+	if st.PromptTokens != 12 || st.CompletionTokens != 2 {
+		t.Errorf("tokens = %d prompt / %d completion, want 12 / 2", st.PromptTokens, st.CompletionTokens)
+	}
+	if st.TimeToFirstToken < delay || st.Duration < st.TimeToFirstToken {
+		t.Errorf("TTFT = %v, duration = %v, want TTFT >= %v and duration >= TTFT", st.TimeToFirstToken, st.Duration, delay)
+	}
+	if st.TokensPerSec <= 0 {
+		t.Errorf("TokensPerSec = %v, want > 0", st.TokensPerSec)
+	}
+}
+
+func TestChat_StreamStats(t *testing.T) {
+	tests := []struct {
+		name         string
+		lines        []string
+		wantPrompt   int
+		wantComplete int
+	}{
+		{"counted from chunks", []string{
+			`{"choices":[{"delta":{"content":"a"},"finish_reason":null}]}`,
+			`{"choices":[{"delta":{"content":"b"},"finish_reason":null}]}`,
+			`{"choices":[{"delta":{"content":"c"},"finish_reason":"stop"}]}`,
+			`[DONE]`,
+		}, 0, 3},
+		{"usage on final chunk", []string{
+			`{"choices":[{"delta":{"content":"a"},"finish_reason":null}]}`,
+			`{"choices":[{"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":9,"completion_tokens":4}}`,
+			`[DONE]`,
+		}, 9, 4},
+		{"trailing usage chunk", []string{
+			`{"choices":[{"delta":{"content":"a"},"finish_reason":null}]}`,
+			`{"choices":[{"delta":{},"finish_reason":"stop"}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":7,"completion_tokens":5}}`,
+			`[DONE]`,
+		}, 7, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const delay = 30 * time.Millisecond
+			srv := pacedServer(t, delay, tt.lines...)
+			ch, err := stubHandle(srv).Chat(context.Background(), []ChatMessage{{Role: "user", Content: "Hello"}}, GenerateParams{})
+			tok := lastToken(t, ch, err)
+			if !tok.Done || tok.Stats == nil {
+				t.Fatalf("terminal token = %+v, want done with stats", tok)
+			}
+			if tok.Stats.PromptTokens != tt.wantPrompt || tok.Stats.CompletionTokens != tt.wantComplete {
+				t.Errorf("tokens = %d prompt / %d completion, want %d / %d",
+					tok.Stats.PromptTokens, tok.Stats.CompletionTokens, tt.wantPrompt, tt.wantComplete)
+			}
+			if tok.Stats.TimeToFirstToken < delay {
+				t.Errorf("TTFT = %v, want >= %v", tok.Stats.TimeToFirstToken, delay)
+			}
+		})
+	}
+}
+
+// ─── Capability Tests ───────────────────────────────────────────────────────
+
+// capsServer stubs a llama-server build. props is the /props body ("" for
+// builds without it); chatAPI controls whether the OpenAI-compatible
+// endpoints exist. Chat request bodies are sent on bodies.
+func capsServer(t *testing.T, props string, chatAPI bool, bodies chan<- string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/props" && props != "":
+			io.WriteString(w, props)
+		case r.URL.Path == "/v1/models" && chatAPI:
+			io.WriteString(w, `{"object":"list","data":[]}`)
+		case r.URL.Path == "/v1/chat/completions" && chatAPI:
+			b, _ := io.ReadAll(r.Body)
+			if bodies != nil {
+				bodies <- string(b)
+			}
+			io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"ok\"},\"finish_reason\":\"stop\"}]}\n\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// probedHandle returns a handle for srv with capabilities probed as
+// LoadModel does.
+func probedHandle(srv *httptest.Server) *SubprocessHandle {
+	h := stubHandle(srv)
+	h.caps = probeCapabilities(h.health, h.addr)
+	return h
+}
+
+func TestProbeCapabilities(t *testing.T) {
+	tests := []struct {
+		name    string
+		props   string
+		chatAPI bool
+		want    ServerCapabilities
+	}{
+		{"multimodal build", `{"build_info":"b5500-abc123","modalities":{"vision":true}}`, true,
+			ServerCapabilities{Build: "b5500-abc123", Chat: true, Vision: true}},
+		{"text-only build", `{"build_info":"b4100-def456"}`, true,
+			ServerCapabilities{Build: "b4100-def456", Chat: true}},
+		{"legacy build", "", false, ServerCapabilities{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := probedHandle(capsServer(t, tt.props, tt.chatAPI, nil))
+			got, known := h.Capabilities()
+			if !known || got != tt.want {
+				t.Errorf("Capabilities() = %+v, %t; want %+v, true", got, known, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeCapabilities_Unreachable(t *testing.T) {
+	srv := capsServer(t, "", false, nil)
+	h := stubHandle(srv)
+	srv.Close()
+	if caps := probeCapabilities(h.health, h.addr); caps != nil {
+		t.Errorf("probe of stopped server = %+v, want nil", caps)
+	}
+	if _, known := h.Capabilities(); known {
+		t.Error("unprobed handle should report unknown capabilities")
+	}
+}
+
+func TestChat_RefusedOnLegacyBuild(t *testing.T) {
+	bodies := make(chan string, 1)
+	h := probedHandle(capsServer(t, "", false, bodies))
+
+	_, err := h.Chat(context.Background(), []ChatMessage{{Role: "user", Content: "Hello"}}, GenerateParams{})
+	if !errors.Is(err, domain.ErrUnsupported) {
+		t.Fatalf("Chat() error = %v, want ErrUnsupported", err)
+	}
+	select {
+	case <-bodies:
+		t.Error("Chat should not reach a server without the chat endpoint")
+	default:
+	}
+}
+
+func TestChat_ImagesNeedVision(t *testing.T) {
+	msgs := []ChatMessage{{Role: "user", Content: "What is this?", Images: []string{"data:image/png;base64,iVBORw=="}}}
+
+	textOnly := probedHandle(capsServer(t, `{"build_info":"b4100"}`, true, nil))
+	if _, err := textOnly.Chat(context.Background(), msgs, GenerateParams{}); !errors.Is(err, domain.ErrUnsupported) {
+		t.Errorf("Chat(images) on text-only build: err = %v, want ErrUnsupported", err)
+	}
+	// Text-only chat still works on the same build.
+	ch, err := textOnly.Chat(context.Background(), []ChatMessage{{Role: "user", Content: "hi"}}, GenerateParams{})
+	if tok := lastToken(t, ch, err); tok.Text != "ok" {
+		t.Errorf("text chat = %+v, want ok", tok)
+	}
+
+	bodies := make(chan string, 1)
+	vision := probedHandle(capsServer(t, `{"modalities":{"vision":true}}`, true, bodies))
+	ch, err = vision.Chat(context.Background(), msgs, GenerateParams{})
+	lastToken(t, ch, err)
+
+	var req struct {
+		Messages []struct {
+			Content []struct {
+				Type     string `json:"type"`
+				Text     string `json:"text"`
+				ImageURL struct {
+					URL string `json:"url"`
+				} `json:"image_url"`
+			} `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal([]byte(<-bodies), &req); err != nil {
+		t.Fatalf("decode chat request: %v", err)
+	}
+	parts := req.Messages[0].Content
+	if len(parts) != 2 || parts[0].Text != "What is this?" || parts[1].Type != "image_url" || parts[1].ImageURL.URL != msgs[0].Images[0] {
+		t.Errorf("content parts = %+v, want text then image_url", parts)
+	}
+}
+
+func TestChat_NotFoundMarksUnsupported(t *testing.T) {
+	// Not probed: the 404 itself teaches the handle the endpoint is missing.
+	h := stubHandle(capsServer(t, "", false, nil))
+	if _, err := h.Chat(context.Background(), []ChatMessage{{Role: "user", Content: "hi"}}, GenerateParams{}); !errors.Is(err, domain.ErrUnsupported) {
+		t.Fatalf("Chat() error = %v, want ErrUnsupported", err)
+	}
+	if caps, known := h.Capabilities(); !known || caps.Chat {
+		t.Errorf("Capabilities() = %+v, %t; want chat known unsupported", caps, known)
+	}
+}
+
+func TestPool_Capabilities(t *testing.T) {
+	srv := capsServer(t, `{"build_info":"b5500"}`, true, nil)
+	pool := NewPool(stubBackend{handle: probedHandle(srv)}, 1<<30, func(name string) (string, error) {
+		return "/models/" + name + ".gguf", nil
+	})
+	if _, _, err := pool.Capabilities("llama3"); !errors.Is(err, domain.ErrModelNotLoaded) {
+		t.Fatalf("Capabilities(unloaded) error = %v, want ErrModelNotLoaded", err)
+	}
+	h, err := pool.Acquire("llama3", LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Release()
+
+	caps, known, err := pool.Capabilities("llama3")
+	if err != nil || !known || caps.Build != "b5500" || !caps.Chat {
+		t.Errorf("Capabilities() = %+v, %t, %v; want b5500 with chat", caps, known, err)
+	}
+
+	mock := newTestPool()
+	mh, err := mock.Acquire("llama3", LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mh.Release()
+	if _, known, err := mock.Capabilities("llama3"); err != nil || known {
+		t.Errorf("mock Capabilities() known = %t, err = %v; want unknown", known, err)
+	}
+}
+
+func TestChatMessage_PlainJSON(t *testing.T) {
+	b, err := json.Marshal(ChatMessage{Role: "user", Content: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"role":"user","content":"hi"}` {
+		t.Errorf("json = %s, want plain string content", b)
+	}
+}
+
+func TestGenerate_SanitizesSampling(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		io.WriteString(w, `data: {"content":"","stop":true}`+"\n\n")
+	}))
+	defer srv.Close()
+	h := stubHandle(srv)
+
+	ch, err := h.Generate(context.Background(), "hi", GenerateParams{Temperature: -1, TopP: 3})
+	lastToken(t, ch, err)
+	if body["temperature"] != 0.0 || body["top_p"] != 1.0 {
+		t.Errorf("sent temperature=%v top_p=%v, want 0 / 1", body["temperature"], body["top_p"])
+	}
+
+	_, err = h.Chat(context.Background(), []ChatMessage{{Role: "user", Content: "hi"}}, GenerateParams{TopP: 3, Strict: true})
+	if !errors.Is(err, domain.ErrInvalidSampling) {
+		t.Errorf("strict Chat error = %v, want ErrInvalidSampling", err)
+	}
+}
+
+// slowServer streams one token, waits delay (or for the client to give
+// up), then finishes the completion.
+func slowServer(t *testing.T, delay time.Duration) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `data: {"content":"a","stop":false}`+"\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		io.WriteString(w, `data: {"content":"b","stop":true,"stop_type":"eos"}`+"\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGenerate_RequestTimeout(t *testing.T) {
+	h := stubHandle(slowServer(t, 300*time.Millisecond))
+
+	start := time.Now()
+	ch, err := h.Generate(context.Background(), "hi", GenerateParams{Timeout: 50 * time.Millisecond})
+	if last := lastToken(t, ch, err); last.Done {
+		t.Error("short-timeout stream finished; want it cut off")
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("short-timeout request took %v, want it aborted promptly", elapsed)
+	}
+
+	ch, err = h.Generate(context.Background(), "hi", GenerateParams{Timeout: 5 * time.Second})
+	if last := lastToken(t, ch, err); !last.Done {
+		t.Error("long-timeout stream was cut off; want it to finish")
+	}
+}
+
+func TestGenerateParams_DefaultTimeouts(t *testing.T) {
+	if DefaultChatTimeout >= DefaultGenerateTimeout {
+		t.Errorf("chat default %v should be shorter than generate default %v", DefaultChatTimeout, DefaultGenerateTimeout)
+	}
+
+	// Without a Timeout, a stream that keeps sending outlives the idle
+	// timeout many times over...
+	ctx, touch, cancel := GenerateParams{}.deadline(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	for range 10 {
+		time.Sleep(20 * time.Millisecond)
+		touch()
+	}
+	if ctx.Err() != nil {
+		t.Fatal("stream that kept sending was cut off by the idle timeout")
+	}
+	// ...and fails once the server goes quiet.
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("idle stream never timed out")
+	}
+	if !errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+		t.Errorf("cause = %v, want a deadline error", context.Cause(ctx))
+	}
+
+	// An explicit Timeout bounds the whole call, however busy the stream.
+	ctx, touch, cancel = GenerateParams{Timeout: 50 * time.Millisecond}.deadline(context.Background(), time.Hour)
+	defer cancel()
+	touch()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("explicit timeout never expired")
+	}
+}
+
+// countingServer streams a short completion (with the trailing "[DONE]" that
+// llama-server sends after the stop chunk) and counts new TCP connections.
+// Each request blocks until gate is closed.
+func countingServer(t *testing.T, gate func() <-chan struct{}, inflight *atomic.Int32) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		inflight.Add(1)
+		<-gate()
+		io.WriteString(w, `data: {"content":"ok","stop":false}`+"\n\n")
+		io.WriteString(w, `data: {"content":"","stop":true,"stop_type":"eos"}`+"\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv, &conns
+}
+
+func TestSubprocessHandle_ReusesConnections(t *testing.T) {
+	const burst = 8
+	var (
+		mu       sync.Mutex
+		gateCh   chan struct{}
+		inflight atomic.Int32
+	)
+	gate := func() <-chan struct{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return gateCh
+	}
+	srv, conns := countingServer(t, gate, &inflight)
+	transport := newLlamaTransport("")
+	h := &SubprocessHandle{
+		addr:   srv.URL,
+		client: &http.Client{Timeout: 5 * time.Second, Transport: transport},
+		health: &http.Client{Timeout: healthCheckTimeout, Transport: transport},
+	}
+
+	// Sequential generations share one connection.
+	mu.Lock()
+	gateCh = make(chan struct{})
+	close(gateCh)
+	mu.Unlock()
+	for i := 0; i < 5; i++ {
+		ch, err := h.Generate(context.Background(), "hi", GenerateParams{})
+		lastToken(t, ch, err)
+	}
+	if got := conns.Load(); got != 1 {
+		t.Fatalf("5 sequential generations opened %d connections, want 1", got)
+	}
+
+	// Two bursts of concurrent generations: the second reuses the first's
+	// connections rather than redialing.
+	runBurst := func() {
+		mu.Lock()
+		gateCh = make(chan struct{})
+		mu.Unlock()
+		inflight.Store(0)
+
+		var wg sync.WaitGroup
+		errCh := make(chan error, burst)
+		for i := 0; i < burst; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ch, err := h.Generate(context.Background(), "hi", GenerateParams{})
+				if err != nil {
+					errCh <- err
+					return
+				}
+				for range ch {
+				}
+			}()
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for inflight.Load() < burst && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		mu.Lock()
+		close(gateCh)
+		mu.Unlock()
+		wg.Wait()
+		close(errCh)
+		for err := range errCh {
+			t.Errorf("concurrent stream: %v", err)
+		}
+	}
+
+	runBurst()
+	afterFirst := conns.Load()
+	if afterFirst != burst {
+		t.Fatalf("first burst: %d connections total, want %d", afterFirst, burst)
+	}
+	runBurst()
+	if got := conns.Load(); got != afterFirst {
+		t.Errorf("second burst opened %d new connections, want 0", got-afterFirst)
+	}
+}
+
+// ─── Close Draining ─────────────────────────────────────────────────────────
+
+// pausedServer streams one token, then waits for resume (or the client to
+// disconnect) before finishing the completion.
+func pausedServer(t *testing.T, resume <-chan struct{}) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `data: {"content":"Hi","stop":false}`+"\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-resume:
+		case <-r.Context().Done():
+			return
+		}
+		io.WriteString(w, `data: {"content":" there","stop":true,"stop_type":"eos"}`+"\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// closeRequested waits until Close has marked the handle closed.
+func closeRequested(t *testing.T, h *SubprocessHandle) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		h.mu.Lock()
+		closed := h.closed
+		h.mu.Unlock()
+		if closed {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("Close never marked the handle closed")
+}
+
+func TestSubprocessHandle_CloseWaitsForInFlightStream(t *testing.T) {
+	resume := make(chan struct{})
+	h := stubHandle(pausedServer(t, resume))
+
+	ch, err := h.Generate(context.Background(), "hi", GenerateParams{})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if tok := <-ch; tok.Text != "Hi" {
+		t.Fatalf("first token = %q, want Hi", tok.Text)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		h.Close()
+		close(closed)
+	}()
+	closeRequested(t, h)
+
+	if _, err := h.Generate(context.Background(), "hi", GenerateParams{}); !errors.Is(err, errModelClosed) {
+		t.Errorf("Generate after Close: err = %v, want errModelClosed", err)
+	}
+	if _, err := h.Embed(context.Background(), []string{"x"}); !errors.Is(err, errModelClosed) {
+		t.Errorf("Embed after Close: err = %v, want errModelClosed", err)
+	}
+	select {
+	case <-closed:
+		t.Fatal("Close returned while a stream was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(resume)
+	var last domain.Token
+	for tok := range ch {
+		last = tok
+	}
+	if !last.Done || last.Text != " there" {
+		t.Errorf("last token = %+v, want completed stream", last)
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return after the stream drained")
+	}
+}
+
+func TestSubprocessHandle_CloseAbortsAfterDrainTimeout(t *testing.T) {
+	h := stubHandle(pausedServer(t, nil)) // never resumes
+	h.drainTimeout = 50 * time.Millisecond
+
+	ch, err := h.Generate(context.Background(), "hi", GenerateParams{})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	<-ch
+
+	start := time.Now()
+	h.Close()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Close took %s", elapsed)
+	}
+
+	select {
+	case tok, ok := <-ch:
+		if ok {
+			t.Errorf("aborted stream delivered %+v, want closed channel", tok)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream was not aborted")
+	}
+}
+
+func TestSubprocessHandle_CloseIdempotent(t *testing.T) {
+	h := stubHandle(pausedServer(t, nil))
+	h.Close()
+	h.Close()
+	if _, err := h.Chat(context.Background(), nil, GenerateParams{}); !errors.Is(err, errModelClosed) {
+		t.Errorf("Chat after Close: err = %v, want errModelClosed", err)
+	}
+}
+
+// ─── Log Level & Capture ────────────────────────────────────────────────────
+
+func TestServerArgs_LogLevel(t *testing.T) {
+	tests := []struct {
+		level string
+		want  []string // trailing args
+	}{
+		{"", []string{"--n-gpu-layers", "99"}},
+		{"error", []string{"--log-verbosity", "1"}},
+		{"Debug", []string{"--log-verbosity", "4"}},
+		{"verbose", []string{"--verbose"}},
+	}
+	for _, tt := range tests {
+		args, err := serverArgs("/m.gguf", nil, LoadOptions{NumGPULayers: -1, LogLevel: tt.level})
+		if err != nil {
+			t.Fatalf("%q: %v", tt.level, err)
+		}
+		if got := args[len(args)-len(tt.want):]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: args end with %v, want %v", tt.level, got, tt.want)
+		}
+	}
+
+	if _, err := serverArgs("/m.gguf", nil, LoadOptions{LogLevel: "chatty"}); err == nil {
+		t.Error("unknown log level should be rejected")
+	}
+}
+
+func TestServerArgs_Embedding(t *testing.T) {
+	has := func(args []string) bool { return slices.Contains(args, "--embedding") }
+	if args, _ := serverArgs("/m.gguf", nil, LoadOptions{}); has(args) {
+		t.Errorf("args %v: --embedding without LoadOptions.Embedding", args)
+	}
+	if args, _ := serverArgs("/m.gguf", nil, LoadOptions{Embedding: true}); !has(args) {
+		t.Errorf("args %v: want --embedding", args)
+	}
+}
+
+func TestLineWriter_PrefixesCompleteLines(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	a := &lineWriter{prefix: "[a] ", out: &out, mu: &mu}
+	b := &lineWriter{prefix: "[b] ", out: &out, mu: &mu}
+
+	a.Write([]byte("loading mo"))
+	b.Write([]byte("ready\n"))
+	a.Write([]byte("del\nsecond\npart"))
+	a.Flush()
+
+	want := "[b] ready\n[a] loading model\n[a] second\n[a] part\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
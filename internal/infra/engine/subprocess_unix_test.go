@@ -0,0 +1,143 @@
+//go:build !windows
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// unixSocketServer serves a fake llama-server on a Unix socket and returns
+// the socket directory and path.
+func unixSocketServer(t *testing.T) (dir, sockPath string) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "tutu-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	sockPath = filepath.Join(dir, "llama-server.sock")
+
+	l, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatalf("listen on unix socket: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/completion", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "data: {\"content\":\"Hi\",\"stop\":false}\n\n")
+		io.WriteString(w, "data: {\"content\":\" there\",\"stop\":true,\"stop_type\":\"eos\"}\n\n")
+	})
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+	return dir, sockPath
+}
+
+func TestUnixSocket_GenerateOverSocket(t *testing.T) {
+	dir, sockPath := unixSocketServer(t)
+	h := &SubprocessHandle{
+		addr:    "http://llama-server",
+		sockDir: dir,
+		client:  &http.Client{Timeout: 5 * time.Second, Transport: newLlamaTransport(sockPath)},
+		health:  &http.Client{Timeout: healthCheckTimeout, Transport: newLlamaTransport(sockPath)},
+	}
+
+	ch, err := h.Generate(context.Background(), "Hello", GenerateParams{MaxTokens: 2})
+	if err != nil {
+		t.Fatalf("Generate over socket: %v", err)
+	}
+	var text string
+	for tok := range ch {
+		text += tok.Text
+	}
+	if text != "Hi there" {
+		t.Errorf("generated %q, want %q", text, "Hi there")
+	}
+
+	h.Close()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("socket dir should be removed on Close, stat err = %v", err)
+	}
+}
+
+func TestUnixSocket_HealthPoll(t *testing.T) {
+	_, sockPath := unixSocketServer(t)
+	backoff := healthBackoff{initial: time.Millisecond, max: 10 * time.Millisecond}
+
+	err := pollServerHealth("http://llama-server", newLlamaTransport(sockPath), 5*time.Second, backoff, make(chan error), &limitedBuffer{max: 1024}, nil)
+	if err != nil {
+		t.Fatalf("pollServerHealth over socket: %v", err)
+	}
+}
+
+func TestLoadModel_StreamsServerLogs(t *testing.T) {
+	dir := t.TempDir()
+	// A fake llama-server that logs its arguments and exits.
+	script := filepath.Join(dir, "llama-server")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"args: $*\" >&2\nprintf 'no newline' >&2\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	model := filepath.Join(dir, "tiny.gguf")
+	if err := os.WriteFile(model, []byte("GGUF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var sink bytes.Buffer
+	b := &SubprocessBackend{llamaServerPath: script}
+	b.SetLogLevel("debug")
+	b.SetLogOutput(&sink)
+
+	if _, err := b.LoadModel(model, LoadOptions{NumGPULayers: -1}); err == nil {
+		t.Fatal("LoadModel should fail when llama-server exits")
+	}
+	got := sink.String()
+	if !strings.Contains(got, "[llama-server tiny.gguf] args: ") || !strings.Contains(got, "--log-verbosity 4") {
+		t.Errorf("sink = %q, want prefixed log line with --log-verbosity 4", got)
+	}
+	if !strings.HasSuffix(got, "[llama-server tiny.gguf] no newline\n") {
+		t.Errorf("sink = %q, want trailing partial line flushed", got)
+	}
+}
+
+func TestLoadModel_MemoryFailureDetected(t *testing.T) {
+	dir := t.TempDir()
+	model := filepath.Join(dir, "tiny.gguf")
+	if err := os.WriteFile(model, []byte("GGUF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	load := func(stderr string) error {
+		t.Helper()
+		script := filepath.Join(dir, "llama-server")
+		body := "#!/bin/sh\necho '" + stderr + "' >&2\nexit 1\n"
+		if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		_, err := (&SubprocessBackend{llamaServerPath: script}).LoadModel(model, LoadOptions{})
+		if err == nil {
+			t.Fatal("LoadModel should fail when llama-server exits")
+		}
+		return err
+	}
+
+	err := load("ggml_backend_cpu_buffer_type_alloc_buffer: failed to allocate buffer of size 7338917888")
+	if !errors.Is(err, domain.ErrInsufficientMemory) {
+		t.Errorf("allocation failure = %v, want ErrInsufficientMemory", err)
+	}
+	if err := load("error: unknown model architecture: 'foo'"); errors.Is(err, domain.ErrInsufficientMemory) {
+		t.Errorf("architecture failure = %v, should not be a memory error", err)
+	}
+}
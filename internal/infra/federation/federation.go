@@ -472,6 +472,35 @@ func (r *Registry) CanShareCapacity(nodeID string) bool {
 	return fed.SharingPolicy != ShareNothing
 }
 
+// CanExecute reports whether a task belonging to federation taskFedID
+// (empty for a public task) may run on nodeID. A federated task never runs
+// on another federation's nodes; it may run on public nodes only when its
+// federation shares capacity and does not require data sovereignty. Public
+// tasks run on federated nodes only as CanShareCapacity allows.
+func (r *Registry) CanExecute(taskFedID, nodeID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nodeFedID, nodeFederated := r.nodeIndex[nodeID]
+
+	if taskFedID == "" {
+		if !nodeFederated {
+			return true
+		}
+		fed := r.federations[nodeFedID]
+		return fed.Status == FedActive && fed.SharingPolicy != ShareNothing
+	}
+
+	fed, ok := r.federations[taskFedID]
+	if !ok || fed.Status == FedDissolved {
+		return false
+	}
+	if nodeFederated {
+		return nodeFedID == taskFedID
+	}
+	return fed.Status == FedActive && fed.SharingPolicy != ShareNothing && !fed.DataSovereignty
+}
+
 // RevenueShare calculates the org and platform split for a task.
 // Returns (orgCredits, platformCredits).
 func (r *Registry) RevenueShare(nodeID string, totalCredits int64) (int64, int64) {
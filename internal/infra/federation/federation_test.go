@@ -312,6 +312,72 @@ func TestCanShareCapacity(t *testing.T) {
 
 // ─── Revenue Share Tests ────────────────────────────────────────────────────
 
+func TestCanExecute_SameFederation(t *testing.T) {
+	r := newTestRegistry(t)
+	fed, _ := r.CreateFederation("Acme Corp", "node-acme-admin")
+	r.JoinFederation(fed.ID, "node-acme-1")
+
+	if !r.CanExecute(fed.ID, "node-acme-1") {
+		t.Error("federated task should run on a member node")
+	}
+	if !r.CanExecute(fed.ID, "node-acme-admin") {
+		t.Error("federated task should run on the admin node")
+	}
+}
+
+func TestCanExecute_CrossFederationBlocked(t *testing.T) {
+	r := newTestRegistry(t)
+	acme, _ := r.CreateFederation("Acme Corp", "node-acme")
+	globex, _ := r.CreateFederation("Globex", "node-globex")
+
+	// Sharing policies never open another federation's private nodes.
+	for _, fed := range []*Federation{acme, globex} {
+		r.federations[fed.ID].DataSovereignty = false
+		r.SetSharingPolicy(fed.ID, ShareAll)
+	}
+
+	if r.CanExecute(acme.ID, "node-globex") {
+		t.Error("acme task must not run on a globex node")
+	}
+	if r.CanExecute(globex.ID, "node-acme") {
+		t.Error("globex task must not run on an acme node")
+	}
+	if r.CanExecute("fed-unknown", "node-public") {
+		t.Error("task from an unknown federation must not run anywhere")
+	}
+}
+
+func TestCanExecute_PublicSharing(t *testing.T) {
+	r := newTestRegistry(t)
+	fed, _ := r.CreateFederation("Acme Corp", "node-acme")
+
+	// Sovereign federation (default) keeps its tasks in-house.
+	if r.CanExecute(fed.ID, "node-public") {
+		t.Error("sovereign federation task must not run on a public node")
+	}
+
+	// Non-sovereign federation that shares capacity may use public nodes.
+	r.federations[fed.ID].DataSovereignty = false
+	if !r.CanExecute(fed.ID, "node-public") {
+		t.Error("sharing federation task should run on a public node")
+	}
+
+	// Public tasks run on federated nodes only while the federation shares.
+	if !r.CanExecute("", "node-acme") {
+		t.Error("public task should run on a sharing federation's node")
+	}
+	r.SetSharingPolicy(fed.ID, ShareNothing)
+	if r.CanExecute(fed.ID, "node-public") {
+		t.Error("ShareNothing federation task must not run on a public node")
+	}
+	if r.CanExecute("", "node-acme") {
+		t.Error("public task must not run on a ShareNothing federation's node")
+	}
+	if !r.CanExecute("", "node-public") {
+		t.Error("public task should run on a public node")
+	}
+}
+
 func TestRevenueShare(t *testing.T) {
 	r := newTestRegistry(t)
 	r.CreateFederation("TestCorp", "node-admin")
@@ -46,6 +46,13 @@ type Config struct {
 
 	// MinSupplyDemandRatio: below this, there's a contribution deficit.
 	MinSupplyDemandRatio float64
+
+	// MaxInflationRate is the inflation guardrail bound, in percent of
+	// circulating credits per day; see SetInflationGuardrail. 0 disables it.
+	MaxInflationRate float64
+
+	// InflationWindow is the span of snapshots the guardrail averages over.
+	InflationWindow time.Duration
 }
 
 // DefaultConfig returns sensible defaults for flywheel tracking.
@@ -56,6 +63,8 @@ func DefaultConfig() Config {
 		SustainabilityThreshold: 50.0,
 		MinViralCoefficient:     0.8,
 		MinSupplyDemandRatio:    0.8,
+		MaxInflationRate:        2.0,
+		InflationWindow:         24 * time.Hour,
 	}
 }
 
@@ -81,6 +90,12 @@ type Tracker struct {
 	prevWeekNodes      int64
 	prevWeekInferences int64
 
+	// Per-contributor activity for cohort retention (nodeID → activity)
+	nodes map[string]*nodeActivity
+
+	// Inflation guardrail hook; nil = disabled
+	onInflation InflationFunc
+
 	// Injectable clock
 	now func() time.Time
 }
@@ -90,6 +105,7 @@ func NewTracker(cfg Config) *Tracker {
 	return &Tracker{
 		config:    cfg,
 		snapshots: make([]domain.FlywheelSnapshot, cfg.MaxSnapshots),
+		nodes:     make(map[string]*nodeActivity),
 		now:       time.Now,
 	}
 }
@@ -194,16 +210,17 @@ func (t *Tracker) IsSustainable() bool {
 	return t.Health().IsSustainable()
 }
 
-// TakeSnapshot records the current state for historical tracking.
+// TakeSnapshot records the current state for historical tracking, then
+// checks the inflation guardrail.
 func (t *Tracker) TakeSnapshot() {
 	t.mu.Lock()
-	defer t.mu.Unlock()
-
 	snap := domain.FlywheelSnapshot{
 		Timestamp:   t.now(),
 		Nodes:       t.current.TotalContributors,
 		Inferences:  t.current.InferencesPerDay,
 		Credits:     t.current.CreditsInCirculation,
+		Earned:      t.current.CreditsEarnedToday,
+		Spent:       t.current.CreditsSpentToday,
 		Revenue:     t.current.EnterpriseRevenue,
 		HealthIndex: t.computeNetworkEffectIndex(),
 	}
@@ -213,6 +230,14 @@ func (t *Tracker) TakeSnapshot() {
 	if t.snapIdx == 0 {
 		t.snapFull = true
 	}
+
+	alert, fire := t.checkInflationLocked()
+	notify := t.onInflation
+	t.mu.Unlock()
+
+	if fire && notify != nil {
+		notify(alert)
+	}
 }
 
 // Snapshots returns all recorded snapshots in chronological order.
@@ -242,6 +267,224 @@ func (t *Tracker) MarkWeekBoundary() {
 	t.prevWeekInferences = t.current.InferencesPerDay
 }
 
+// ═══════════════════════════════════════════════════════════════════════════
+// Inflation Guardrail
+// ═══════════════════════════════════════════════════════════════════════════
+
+// EarningRateParam is the governable parameter the guardrail suggests
+// adjusting. The tracker never changes it — that takes a governance vote.
+const EarningRateParam = "earning_rate_base"
+
+// InflationAlert is raised when credit inflation exceeds the configured bound.
+type InflationAlert struct {
+	Rate   float64       // measured inflation, % of circulating credits per day
+	Bound  float64       // Config.MaxInflationRate
+	Window time.Duration // span the rate was averaged over
+
+	// Multiplier is the suggested factor for ParamKey that would bring
+	// inflation back to Bound at the window's average spending.
+	ParamKey   string
+	Multiplier float64
+}
+
+// InflationFunc receives guardrail alerts. It is called without the
+// tracker lock held, so it may call back into the Tracker.
+type InflationFunc func(InflationAlert)
+
+// SetInflationGuardrail registers fn to be called from TakeSnapshot while
+// inflation over Config.InflationWindow exceeds Config.MaxInflationRate.
+// nil disables the hook.
+func (t *Tracker) SetInflationGuardrail(fn InflationFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onInflation = fn
+}
+
+// InflationRate returns credit inflation over the last window as a percent
+// of circulating credits per day: the average daily credits earned minus
+// spent across the window's days, over the latest circulating supply.
+// Snapshots record running totals for the day, so each UTC day contributes
+// its last snapshot in the window. The current day is still running and is
+// left out while earlier days are in the window; alone, its totals count
+// for the part of the day elapsed at its snapshot. Negative values mean
+// credits are being spent down. Without snapshots in the window the
+// current figures are used.
+func (t *Tracker) InflationRate(window time.Duration) float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	rate, _, _ := t.inflationLocked(window)
+	return rate
+}
+
+// inflationLocked returns the inflation rate over window with the average
+// daily earned and spent credits it was computed from. Caller must hold t.mu.
+func (t *Tracker) inflationLocked(window time.Duration) (rate, earned, spent float64) {
+	n := t.snapIdx
+	if t.snapFull {
+		n = t.config.MaxSnapshots
+	}
+	now := t.now()
+	since := now.Add(-window)
+	today := now.UTC().Truncate(24 * time.Hour)
+	var latest domain.FlywheelSnapshot
+	days := make(map[time.Time]domain.FlywheelSnapshot) // UTC day → last snapshot
+	for _, s := range t.snapshots[:n] {
+		if s.Timestamp.Before(since) {
+			continue
+		}
+		day := s.Timestamp.UTC().Truncate(24 * time.Hour)
+		if last, ok := days[day]; !ok || s.Timestamp.After(last.Timestamp) {
+			days[day] = s
+		}
+		if s.Timestamp.After(latest.Timestamp) {
+			latest = s
+		}
+	}
+	circulating := latest.Credits
+	var count float64
+	if s, ok := days[today]; ok && len(days) == 1 {
+		earned, spent = float64(s.Earned), float64(s.Spent)
+		count = dayElapsed(s.Timestamp)
+	} else {
+		for day, s := range days {
+			if day.Equal(today) {
+				continue
+			}
+			earned += float64(s.Earned)
+			spent += float64(s.Spent)
+			count++
+		}
+	}
+	if len(days) == 0 {
+		earned = float64(t.current.CreditsEarnedToday)
+		spent = float64(t.current.CreditsSpentToday)
+		circulating = t.current.CreditsInCirculation
+		count = dayElapsed(now)
+	}
+	if count <= 0 {
+		return 0, 0, 0
+	}
+	earned /= count
+	spent /= count
+	if circulating <= 0 {
+		return 0, earned, spent
+	}
+	return (earned - spent) / float64(circulating) * 100, earned, spent
+}
+
+// dayElapsed returns the fraction of its UTC day that has passed at t.
+func dayElapsed(t time.Time) float64 {
+	return float64(t.Sub(t.UTC().Truncate(24*time.Hour))) / float64(24*time.Hour)
+}
+
+// checkInflationLocked reports whether the guardrail should fire, and the
+// alert to send. Caller must hold t.mu.
+func (t *Tracker) checkInflationLocked() (InflationAlert, bool) {
+	bound := t.config.MaxInflationRate
+	if bound <= 0 {
+		return InflationAlert{}, false
+	}
+	rate, earned, spent := t.inflationLocked(t.config.InflationWindow)
+	if rate <= bound || earned <= 0 {
+		return InflationAlert{}, false
+	}
+	// Earning that keeps inflation at the bound: spending plus the allowed
+	// daily growth of the supply.
+	target := spent + bound/rate*(earned-spent)
+	return InflationAlert{
+		Rate:       rate,
+		Bound:      bound,
+		Window:     t.config.InflationWindow,
+		ParamKey:   EarningRateParam,
+		Multiplier: math.Round(target/earned*1000) / 1000,
+	}, true
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Cohort Retention
+// ═══════════════════════════════════════════════════════════════════════════
+
+// nodeActivity is the first and most recent activity seen for a contributor.
+type nodeActivity struct {
+	firstSeen  time.Time
+	lastActive time.Time
+}
+
+// RecordNodeActivity notes that a contributor was active at the given time.
+// The first activity recorded for a node places it in its join cohort.
+func (t *Tracker) RecordNodeActivity(nodeID string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	a, ok := t.nodes[nodeID]
+	if !ok {
+		t.nodes[nodeID] = &nodeActivity{firstSeen: at, lastActive: at}
+		return
+	}
+	if at.Before(a.firstSeen) {
+		a.firstSeen = at
+	}
+	if at.After(a.lastActive) {
+		a.lastActive = at
+	}
+}
+
+// CohortRetention returns the 7-day and 30-day retention (%) of the
+// contributors that joined in the week containing joinWeek (weeks start
+// Monday 00:00 UTC). A node is retained at N days if it was active N or more
+// days after it joined. Each rate only counts nodes that joined at least N
+// days ago, and is 0 until some have. Returns domain.ErrEmptyCohort if no
+// node joined that week.
+func (t *Tracker) CohortRetention(joinWeek time.Time) (d7, d30 float64, err error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	start := weekStart(joinWeek)
+	end := start.AddDate(0, 0, 7)
+	now := t.now()
+
+	var members int
+	var eligible7, retained7, eligible30, retained30 int
+	for _, a := range t.nodes {
+		if a.firstSeen.Before(start) || !a.firstSeen.Before(end) {
+			continue
+		}
+		members++
+		active := a.lastActive.Sub(a.firstSeen)
+		if age := now.Sub(a.firstSeen); age >= 7*24*time.Hour {
+			eligible7++
+			if active >= 7*24*time.Hour {
+				retained7++
+			}
+			if age >= 30*24*time.Hour {
+				eligible30++
+				if active >= 30*24*time.Hour {
+					retained30++
+				}
+			}
+		}
+	}
+	if members == 0 {
+		return 0, 0, domain.ErrEmptyCohort
+	}
+	return percent(retained7, eligible7), percent(retained30, eligible30), nil
+}
+
+// weekStart returns Monday 00:00 UTC of the week containing t.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// percent returns n/of as a percentage, or 0 when of is 0.
+func percent(n, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return float64(n) / float64(of) * 100
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // Network Effect Index Computation
 // ═══════════════════════════════════════════════════════════════════════════
@@ -1,8 +1,12 @@
 package flywheel
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // fixedTime returns a deterministic time for testing.
@@ -312,3 +316,208 @@ func TestGateCheck(t *testing.T) {
 		t.Fatalf("expected 1.3 viral k, got %f", viralK)
 	}
 }
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Inflation Guardrail
+// ═══════════════════════════════════════════════════════════════════════════
+
+// economyTracker returns a tracker with a settable clock, set an hour
+// before midnight UTC, and a guardrail that collects its alerts.
+func economyTracker(bound float64) (tr *Tracker, now *time.Time, alerts *[]InflationAlert) {
+	cfg := DefaultConfig()
+	cfg.MaxInflationRate = bound
+	tr = NewTracker(cfg)
+	clock := fixedTime().Add(11 * time.Hour)
+	tr.now = func() time.Time { return clock }
+	var got []InflationAlert
+	tr.SetInflationGuardrail(func(a InflationAlert) { got = append(got, a) })
+	return tr, &clock, &got
+}
+
+// snapshotEconomy takes daily snapshots of the same daily earn/spend.
+func snapshotEconomy(tr *Tracker, now *time.Time, days int, circulating, earned, spent int64) {
+	for i := 0; i < days; i++ {
+		tr.UpdateEconomy(circulating, earned, spent, 0)
+		tr.TakeSnapshot()
+		*now = now.Add(24 * time.Hour)
+	}
+}
+
+func TestInflationRate_EarningHeavyFiresGuardrail(t *testing.T) {
+	tr, now, alerts := economyTracker(2.0)
+	snapshotEconomy(tr, now, 4, 100_000, 5_000, 2_000)
+
+	if got := tr.InflationRate(24 * time.Hour); got != 3.0 {
+		t.Fatalf("InflationRate() = %v, want 3 (%% of supply per day)", got)
+	}
+	if len(*alerts) != 4 {
+		t.Fatalf("guardrail fired %d times, want once per snapshot (4)", len(*alerts))
+	}
+	a := (*alerts)[3]
+	if a.Rate != 3.0 || a.Bound != 2.0 || a.ParamKey != EarningRateParam {
+		t.Errorf("alert = %+v", a)
+	}
+	// Earning 4,000/day against 2,000 spent grows the supply 2%/day.
+	if a.Multiplier != 0.8 {
+		t.Errorf("Multiplier = %v, want 0.8", a.Multiplier)
+	}
+}
+
+func TestInflationRate_BalancedDoesNotFire(t *testing.T) {
+	tr, now, alerts := economyTracker(2.0)
+	snapshotEconomy(tr, now, 4, 100_000, 3_000, 3_000)
+
+	if got := tr.InflationRate(24 * time.Hour); got != 0 {
+		t.Errorf("InflationRate() = %v, want 0", got)
+	}
+	// A modest surplus stays under the bound.
+	snapshotEconomy(tr, now, 2, 100_000, 4_500, 3_000)
+	if len(*alerts) != 0 {
+		t.Errorf("guardrail fired %d times, want 0", len(*alerts))
+	}
+}
+
+func TestInflationRate_Window(t *testing.T) {
+	tr, now, _ := economyTracker(0)
+	snapshotEconomy(tr, now, 2, 100_000, 10_000, 0)
+	*now = now.Add(48 * time.Hour)
+	snapshotEconomy(tr, now, 2, 100_000, 1_000, 3_000)
+
+	if got := tr.InflationRate(24 * time.Hour); got != -2.0 {
+		t.Errorf("InflationRate(24h) = %v, want -2 (recent snapshots only)", got)
+	}
+	if got := tr.InflationRate(7 * 24 * time.Hour); got != 4.0 {
+		t.Errorf("InflationRate(7d) = %v, want 4 (all snapshots)", got)
+	}
+}
+
+func TestInflationRate_IntradayTotals(t *testing.T) {
+	tr, now, _ := economyTracker(0)
+	*now = fixedTime()
+	// The day's running totals climb through the day; only the last counts.
+	for _, earned := range []int64{1_000, 2_000, 3_000, 4_000} {
+		tr.UpdateEconomy(100_000, earned, earned/4, 0)
+		tr.TakeSnapshot()
+		*now = now.Add(time.Hour)
+	}
+	*now = now.Add(24 * time.Hour)
+	if got := tr.InflationRate(48 * time.Hour); got != 3.0 {
+		t.Errorf("InflationRate() = %v, want 3 (4,000 earned - 1,000 spent)", got)
+	}
+}
+
+func TestInflationRate_PartialDay(t *testing.T) {
+	tr, now, _ := economyTracker(0)
+	snapshotEconomy(tr, now, 1, 100_000, 2_000, 1_000)
+
+	// This morning's running totals are not a full day's: left out.
+	*now = fixedTime().Add(24*time.Hour - 6*time.Hour) // 06:00 the next day
+	tr.UpdateEconomy(100_000, 3_000, 0, 0)
+	tr.TakeSnapshot()
+	if got := tr.InflationRate(48 * time.Hour); got != 1.0 {
+		t.Errorf("InflationRate() = %v, want 1 (yesterday only)", got)
+	}
+
+	// Alone, the partial day counts for the quarter day elapsed.
+	if got := tr.InflationRate(time.Hour); got != 12.0 {
+		t.Errorf("InflationRate(1h) = %v, want 12 (3,000 earned by 06:00)", got)
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Cohort Retention
+// ═══════════════════════════════════════════════════════════════════════════
+
+// seedCohort records n nodes joining on joined; the first active7 stay
+// active for 7+ days and the first active30 of those for 30+ days.
+func seedCohort(tr *Tracker, prefix string, joined time.Time, n, active7, active30 int) {
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("%s-%d", prefix, i)
+		tr.RecordNodeActivity(id, joined.Add(time.Duration(i)*time.Hour))
+		tr.RecordNodeActivity(id, joined.Add(24*time.Hour)) // active on day one
+		switch {
+		case i < active30:
+			tr.RecordNodeActivity(id, joined.AddDate(0, 0, 31))
+		case i < active7:
+			tr.RecordNodeActivity(id, joined.AddDate(0, 0, 8))
+		}
+	}
+}
+
+func TestCohortRetention_CompareCohorts(t *testing.T) {
+	tr := NewTracker(DefaultConfig())
+	now := time.Date(2025, 8, 20, 12, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+
+	older := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC) // Monday
+	newer := time.Date(2025, 6, 16, 9, 0, 0, 0, time.UTC)
+	seedCohort(tr, "old", older, 10, 6, 3)
+	seedCohort(tr, "new", newer, 4, 3, 2)
+
+	d7, d30, err := tr.CohortRetention(older)
+	if err != nil {
+		t.Fatalf("CohortRetention(older): %v", err)
+	}
+	if d7 != 60 || d30 != 30 {
+		t.Errorf("older cohort = %.1f%%/%.1f%%, want 60%%/30%%", d7, d30)
+	}
+
+	// Any time within the week selects the same cohort.
+	d7, d30, err = tr.CohortRetention(newer.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatalf("CohortRetention(newer): %v", err)
+	}
+	if d7 != 75 || d30 != 50 {
+		t.Errorf("newer cohort = %.1f%%/%.1f%%, want 75%%/50%%", d7, d30)
+	}
+}
+
+func TestCohortRetention_ImmatureCohort(t *testing.T) {
+	tr := NewTracker(DefaultConfig())
+	tr.now = fixedTime // Tuesday 2025-07-01
+
+	joined := time.Date(2025, 6, 16, 9, 0, 0, 0, time.UTC) // 15 days earlier
+	seedCohort(tr, "n", joined, 5, 5, 0)
+
+	d7, d30, err := tr.CohortRetention(joined)
+	if err != nil {
+		t.Fatalf("CohortRetention: %v", err)
+	}
+	if d7 != 100 {
+		t.Errorf("d7 = %.1f, want 100", d7)
+	}
+	if d30 != 0 {
+		t.Errorf("d30 = %.1f, want 0 — no node is 30 days old yet", d30)
+	}
+}
+
+func TestCohortRetention_EmptyCohort(t *testing.T) {
+	tr := NewTracker(DefaultConfig())
+	tr.now = fixedTime
+	tr.RecordNodeActivity("node-1", time.Date(2025, 6, 9, 10, 0, 0, 0, time.UTC))
+
+	// Sunday 2025-06-08 belongs to the previous week.
+	_, _, err := tr.CohortRetention(time.Date(2025, 6, 8, 23, 0, 0, 0, time.UTC))
+	if !errors.Is(err, domain.ErrEmptyCohort) {
+		t.Errorf("err = %v, want ErrEmptyCohort", err)
+	}
+}
+
+func TestRecordNodeActivity_OutOfOrder(t *testing.T) {
+	tr := NewTracker(DefaultConfig())
+	now := time.Date(2025, 8, 20, 12, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+
+	joined := time.Date(2025, 6, 3, 0, 0, 0, 0, time.UTC)
+	// A late-arriving earlier event moves the node's join date back.
+	tr.RecordNodeActivity("node-1", joined.AddDate(0, 0, 10))
+	tr.RecordNodeActivity("node-1", joined)
+
+	d7, _, err := tr.CohortRetention(joined)
+	if err != nil {
+		t.Fatalf("CohortRetention: %v", err)
+	}
+	if d7 != 100 {
+		t.Errorf("d7 = %.1f, want 100", d7)
+	}
+}
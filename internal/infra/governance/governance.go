@@ -9,12 +9,21 @@
 package governance
 
 import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"log"
+	"maps"
+	"math/rand"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // ─── Constants ──────────────────────────────────────────────────────────────
@@ -32,6 +41,18 @@ const (
 
 	// MaxActiveProposals limits concurrent proposals.
 	MaxActiveProposals = 50
+
+	// DefaultVetoDelay is the cooling-off period after a veto-eligible
+	// proposal passes, during which any council member may veto it.
+	DefaultVetoDelay = 48 * time.Hour
+
+	// DefaultDraftTTL is how long a draft may wait to be opened before
+	// CleanupStaleDrafts expires it.
+	DefaultDraftTTL = 14 * 24 * time.Hour
+
+	// ResolveInterval is how often Run resolves expired proposals and
+	// stale drafts.
+	ResolveInterval = time.Minute
 )
 
 // ─── Types ──────────────────────────────────────────────────────────────────
@@ -40,13 +61,15 @@ const (
 type ProposalStatus int
 
 const (
-	PropDraft     ProposalStatus = iota // Created but not yet open
-	PropActive                          // Open for voting
-	PropPassed                          // Quorum met + majority approved
-	PropRejected                        // Quorum met + majority rejected
-	PropExpired                         // Voting period ended without quorum
-	PropExecuted                        // Passed and auto-applied
-	PropCancelled                       // Cancelled by author
+	PropDraft      ProposalStatus = iota // Created but not yet open
+	PropActive                           // Open for voting
+	PropPassed                           // Quorum met + majority approved
+	PropRejected                         // Quorum met + majority rejected
+	PropExpired                          // Voting period ended without quorum, or a draft was never opened
+	PropExecuted                         // Passed and auto-applied
+	PropCancelled                        // Cancelled by author
+	PropVetoWindow                       // Passed, awaiting the veto cooling-off period
+	PropVetoed                           // Blocked by a council veto
 )
 
 // String returns a human-readable status.
@@ -66,6 +89,10 @@ func (s ProposalStatus) String() string {
 		return "EXECUTED"
 	case PropCancelled:
 		return "CANCELLED"
+	case PropVetoWindow:
+		return "VETO_WINDOW"
+	case PropVetoed:
+		return "VETOED"
 	default:
 		return "UNKNOWN"
 	}
@@ -109,9 +136,30 @@ type VoteChoice int
 const (
 	VoteFor     VoteChoice = iota // Support the proposal
 	VoteAgainst                   // Oppose the proposal
-	VoteAbstain                   // Counted for quorum but not for/against
+	VoteAbstain                   // Counted for quorum; see AbstainMode for approval
+)
+
+// AbstainMode controls how abstentions affect a proposal's approval
+// percentage. Abstentions always count toward quorum.
+type AbstainMode int
+
+const (
+	AbstainIgnore    AbstainMode = iota // Approval = For / (For + Against)
+	AbstainAsAgainst                    // Approval = For / (For + Against + Abstain)
 )
 
+// String returns the mode name.
+func (m AbstainMode) String() string {
+	switch m {
+	case AbstainIgnore:
+		return "IGNORE"
+	case AbstainAsAgainst:
+		return "AS_AGAINST"
+	default:
+		return "UNKNOWN"
+	}
+}
+
 // Proposal is a governance proposal that nodes vote on.
 type Proposal struct {
 	ID          string           `json:"id"`
@@ -126,6 +174,16 @@ type Proposal struct {
 	OpenedAt    time.Time        `json:"opened_at"`  // When voting opened
 	ClosedAt    time.Time        `json:"closed_at"`  // When voting closed
 	ExpiresAt   time.Time        `json:"expires_at"` // Voting deadline
+
+	VetoDeadline time.Time `json:"veto_deadline,omitempty"` // End of the veto window
+	VetoedBy     string    `json:"vetoed_by,omitempty"`     // Council node that vetoed
+	SupersededBy string    `json:"superseded_by,omitempty"` // Conflicting proposal that won (see ResolveExpired)
+
+	// Advisory panel drawn by AssignPanel, with the seed and eligible nodes
+	// that drew it so anyone can repeat the draw with DrawPanel.
+	Panel         []string         `json:"panel,omitempty"`
+	PanelSeed     int64            `json:"panel_seed,omitempty"`
+	PanelEligible map[string]int64 `json:"panel_eligible,omitempty"`
 }
 
 // Vote records a single node's vote, weighted by their credit balance.
@@ -139,15 +197,18 @@ type Vote struct {
 
 // VoteTally summarizes the current state of voting on a proposal.
 type VoteTally struct {
-	ProposalID    string  `json:"proposal_id"`
-	ForWeight     int64   `json:"for_weight"`
-	AgainstWeight int64   `json:"against_weight"`
-	AbstainWeight int64   `json:"abstain_weight"`
-	TotalWeight   int64   `json:"total_weight"`  // Sum of all votes
-	QuorumWeight  int64   `json:"quorum_weight"` // Required for quorum
-	VoterCount    int     `json:"voter_count"`
-	QuorumReached bool    `json:"quorum_reached"`
-	ApprovalPct   float64 `json:"approval_pct"` // For / (For + Against)
+	ProposalID    string `json:"proposal_id"`
+	ForWeight     int64  `json:"for_weight"`
+	AgainstWeight int64  `json:"against_weight"`
+	AbstainWeight int64  `json:"abstain_weight"`
+	TotalWeight   int64  `json:"total_weight"`  // Sum of all votes
+	QuorumWeight  int64  `json:"quorum_weight"` // Required for quorum
+	VoterCount    int    `json:"voter_count"`
+	// DelegatedWeight is the part of TotalWeight cast on behalf of
+	// delegators through their delegate's vote.
+	DelegatedWeight int64   `json:"delegated_weight"`
+	QuorumReached   bool    `json:"quorum_reached"`
+	ApprovalPct     float64 `json:"approval_pct"` // For / (For + Against), see AbstainMode
 }
 
 // GovernanceStats provides an overview of governance activity.
@@ -158,6 +219,7 @@ type GovernanceStats struct {
 	RejectedProposals int `json:"rejected_proposals"`
 	ExpiredProposals  int `json:"expired_proposals"`
 	ExecutedProposals int `json:"executed_proposals"`
+	VetoedProposals   int `json:"vetoed_proposals"`
 	TotalVotesCast    int `json:"total_votes_cast"`
 }
 
@@ -168,6 +230,27 @@ type EngineConfig struct {
 	QuorumPct      int           // % of total credits needed to vote (default 30)
 	VotingDuration time.Duration // How long polls stay open
 	MinCredits     int64         // Minimum credits to create a proposal
+
+	// MinReputation gates spam-prone categories: authors must have at least
+	// this reputation score (0.0–1.0) in addition to MinCredits.
+	// Categories not listed are open to any author.
+	MinReputation map[ProposalCategory]float64
+
+	// VetoDelay is the cooling-off period for veto-eligible proposals
+	// (see SetVetoPolicy). Zero uses DefaultVetoDelay.
+	VetoDelay time.Duration
+
+	// AbstainMode decides whether abstentions count against passage.
+	// The zero value, AbstainIgnore, leaves them out of approval.
+	AbstainMode AbstainMode
+
+	// DraftTTL is how long a draft may stay unopened before
+	// CleanupStaleDrafts expires it. Zero uses DefaultDraftTTL.
+	DraftTTL time.Duration
+
+	// PanelWeighted makes SelectPanel draw nodes in proportion to their
+	// weight (e.g. credits); otherwise every eligible node is equally likely.
+	PanelWeighted bool
 }
 
 // DefaultEngineConfig returns Phase 5 defaults.
@@ -176,9 +259,47 @@ func DefaultEngineConfig() EngineConfig {
 		QuorumPct:      DefaultQuorumPct,
 		VotingDuration: DefaultVotingDuration,
 		MinCredits:     MinProposalCredits,
+		MinReputation: map[ProposalCategory]float64{
+			CatSLAPricing: 0.6,
+			CatSecurity:   0.7,
+		},
+		VetoDelay: DefaultVetoDelay,
+		DraftTTL:  DefaultDraftTTL,
 	}
 }
 
+// ReputationProvider returns a node's current reputation score (0.0–1.0).
+// ok is false if the node is unknown.
+type ReputationProvider func(nodeID string) (score float64, ok bool)
+
+// VetoEligibility reports whether changes to a parameter key may be vetoed
+// by a single council member.
+type VetoEligibility func(paramKey string) bool
+
+// CouncilMembership reports whether a node currently holds a council seat.
+type CouncilMembership func(nodeID string) bool
+
+// Executor applies a proposal whose veto window elapsed without a veto.
+// approvalPct is the final approval percentage (0–100). It runs with the
+// engine lock held and must not call back into the Engine.
+type Executor func(p Proposal, approvalPct float64) error
+
+// CreditProvider returns a node's current credit balance. It weights votes
+// cast on a delegator's behalf.
+type CreditProvider func(nodeID string) int64
+
+// Delegation lends a node's voting weight to another node.
+type Delegation struct {
+	From      string    `json:"from"`       // Delegating node
+	To        string    `json:"to"`         // Node voting on From's behalf
+	ExpiresAt time.Time `json:"expires_at"` // Zero means no expiry
+}
+
+// activeAt reports whether the delegation is still in force at t.
+func (d *Delegation) activeAt(t time.Time) bool {
+	return d.ExpiresAt.IsZero() || t.Before(d.ExpiresAt)
+}
+
 // ─── Engine ─────────────────────────────────────────────────────────────────
 
 // Engine implements the governance system.
@@ -189,6 +310,13 @@ type Engine struct {
 	proposals    map[string]*Proposal        // proposalID → Proposal
 	votes        map[string]map[string]*Vote // proposalID → nodeID → Vote
 	totalCredits int64                       // Total credits in network (for quorum calc)
+	reputation   ReputationProvider          // Author reputation for gated categories
+	credits      CreditProvider              // Delegator weight for delegated votes
+	vetoable     VetoEligibility             // Params whose changes enter a veto window
+	council      CouncilMembership           // Who may veto
+	executor     Executor                    // Applies proposals after the veto window
+	delegations  map[string]*Delegation      // delegator nodeID → Delegation
+	panels       []PanelDraw                 // every SelectPanel draw, oldest first
 
 	// now is a function that returns the current time — injectable for testing.
 	now func() time.Time
@@ -197,10 +325,11 @@ type Engine struct {
 // NewEngine creates a governance engine.
 func NewEngine(cfg EngineConfig) *Engine {
 	return &Engine{
-		config:    cfg,
-		proposals: make(map[string]*Proposal),
-		votes:     make(map[string]map[string]*Vote),
-		now:       time.Now,
+		config:      cfg,
+		proposals:   make(map[string]*Proposal),
+		votes:       make(map[string]map[string]*Vote),
+		delegations: make(map[string]*Delegation),
+		now:         time.Now,
 	}
 }
 
@@ -212,10 +341,47 @@ func (e *Engine) SetTotalCredits(total int64) {
 	e.totalCredits = total
 }
 
+// SetReputationProvider injects the source of author reputation scores used
+// to gate categories listed in EngineConfig.MinReputation. Without a provider,
+// gated categories reject every author.
+func (e *Engine) SetReputationProvider(p ReputationProvider) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.reputation = p
+}
+
+// SetCreditProvider injects the source of delegator credit balances.
+// Without a provider, delegations carry no weight.
+func (e *Engine) SetCreditProvider(p CreditProvider) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.credits = p
+}
+
+// SetVetoPolicy enables council vetoes: a passing proposal whose ParamKey is
+// vetoable enters a VetoDelay window during which any council member may
+// block it. Without a policy, no proposal can be vetoed.
+func (e *Engine) SetVetoPolicy(vetoable VetoEligibility, council CouncilMembership) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.vetoable = vetoable
+	e.council = council
+}
+
+// SetExecutor sets the hook that applies proposals once their veto window
+// elapses. Without one they become PASSED, to be applied and marked via
+// MarkExecuted like any other passed proposal.
+func (e *Engine) SetExecutor(fn Executor) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.executor = fn
+}
+
 // ─── Proposal Lifecycle ─────────────────────────────────────────────────────
 
 // CreateProposal creates a new governance proposal.
 // authorCredits is the author's current credit balance — must meet minimum.
+// Gated categories additionally require a minimum author reputation.
 func (e *Engine) CreateProposal(title, description string, category ProposalCategory, author string, authorCredits int64, paramKey, paramValue string) (*Proposal, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -230,6 +396,16 @@ func (e *Engine) CreateProposal(title, description string, category ProposalCate
 	if authorCredits < e.config.MinCredits {
 		return nil, fmt.Errorf("need at least %d credits to propose (have %d)", e.config.MinCredits, authorCredits)
 	}
+	if minRep, gated := e.config.MinReputation[category]; gated {
+		var score float64
+		if e.reputation != nil {
+			score, _ = e.reputation(author)
+		}
+		if score < minRep {
+			return nil, fmt.Errorf("%w: %s proposals need reputation %.2f (have %.2f)",
+				domain.ErrReputationTooLow, category, minRep, score)
+		}
+	}
 
 	// Check active proposal limit
 	activeCount := 0
@@ -315,15 +491,54 @@ func (e *Engine) GetProposal(propID string) (*Proposal, error) {
 	return prop, nil
 }
 
+// ProposalFilter selects proposals for SearchProposals. Zero-valued fields
+// match everything; set fields combine with AND.
+type ProposalFilter struct {
+	Status        *ProposalStatus
+	Category      *ProposalCategory
+	Author        string    // exact node ID
+	Text          string    // case-insensitive substring of title or description
+	CreatedAfter  time.Time // inclusive
+	CreatedBefore time.Time // exclusive
+}
+
+// matches reports whether p satisfies every set field of f. text must be
+// f.Text already lower-cased.
+func (f ProposalFilter) matches(p *Proposal, text string) bool {
+	switch {
+	case f.Status != nil && p.Status != *f.Status:
+		return false
+	case f.Category != nil && p.Category != *f.Category:
+		return false
+	case f.Author != "" && p.Author != f.Author:
+		return false
+	case !f.CreatedAfter.IsZero() && p.CreatedAt.Before(f.CreatedAfter):
+		return false
+	case !f.CreatedBefore.IsZero() && !p.CreatedAt.Before(f.CreatedBefore):
+		return false
+	}
+	if text == "" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(p.Title), text) ||
+		strings.Contains(strings.ToLower(p.Description), text)
+}
+
 // ListProposals returns proposals filtered by status.
 // Pass nil to get all proposals.
 func (e *Engine) ListProposals(status *ProposalStatus) []*Proposal {
+	return e.SearchProposals(ProposalFilter{Status: status})
+}
+
+// SearchProposals returns the proposals matching filter, newest first.
+func (e *Engine) SearchProposals(filter ProposalFilter) []*Proposal {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
+	text := strings.ToLower(strings.TrimSpace(filter.Text))
 	result := make([]*Proposal, 0)
 	for _, p := range e.proposals {
-		if status == nil || p.Status == *status {
+		if filter.matches(p, text) {
 			result = append(result, p)
 		}
 	}
@@ -380,6 +595,58 @@ func (e *Engine) CastVote(propID, nodeID string, choice VoteChoice, weight int64
 	return nil
 }
 
+// ─── Delegation ─────────────────────────────────────────────────────────────
+
+// Delegate lends from's voting weight to to until revoked.
+func (e *Engine) Delegate(from, to string) error {
+	return e.DelegateUntil(from, to, time.Time{})
+}
+
+// DelegateUntil lends from's voting weight to to until expiry; a zero expiry
+// never lapses. Delegation is not transitive — weight only follows to's own
+// direct vote. A new delegation replaces any existing one from the same node.
+func (e *Engine) DelegateUntil(from, to string, expiry time.Time) error {
+	if from == "" || to == "" {
+		return errors.New("delegation requires both delegator and delegate")
+	}
+	if from == to {
+		return errors.New("cannot delegate to self")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !expiry.IsZero() && !expiry.After(e.now()) {
+		return errors.New("delegation expiry must be in the future")
+	}
+	e.delegations[from] = &Delegation{From: from, To: to, ExpiresAt: expiry}
+	return nil
+}
+
+// Undelegate revokes from's delegation immediately. Reports whether a
+// delegation existed.
+func (e *Engine) Undelegate(from string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	_, ok := e.delegations[from]
+	delete(e.delegations, from)
+	return ok
+}
+
+// DelegationOf returns from's delegation, or nil if none is set.
+func (e *Engine) DelegationOf(from string) *Delegation {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	d, ok := e.delegations[from]
+	if !ok {
+		return nil
+	}
+	cp := *d
+	return &cp
+}
+
 // Tally computes the current vote counts for a proposal.
 func (e *Engine) Tally(propID string) (*VoteTally, error) {
 	e.mu.RLock()
@@ -412,6 +679,7 @@ func (e *Engine) tallyLocked(propID string) *VoteTally {
 		}
 		tally.TotalWeight += v.Weight
 	}
+	e.addDelegatedLocked(tally, propID, votes)
 
 	// Quorum calculation: 30% of total network credits
 	if e.totalCredits > 0 {
@@ -419,8 +687,12 @@ func (e *Engine) tallyLocked(propID string) *VoteTally {
 	}
 	tally.QuorumReached = tally.TotalWeight >= tally.QuorumWeight
 
-	// Approval percentage (For / (For + Against)), excluding abstentions
+	// Approval percentage: For / (For + Against), with abstentions added
+	// to the denominator under AbstainAsAgainst
 	decided := tally.ForWeight + tally.AgainstWeight
+	if e.config.AbstainMode == AbstainAsAgainst {
+		decided += tally.AbstainWeight
+	}
 	if decided > 0 {
 		tally.ApprovalPct = float64(tally.ForWeight) / float64(decided) * 100
 	}
@@ -428,19 +700,69 @@ func (e *Engine) tallyLocked(propID string) *VoteTally {
 	return tally
 }
 
+// addDelegatedLocked adds the weight of delegators whose delegate voted
+// directly on propID. Delegations are evaluated at the current time, capped
+// at the voting deadline, so an expired delegation no longer counts. A
+// delegator's own vote overrides its delegation for this proposal only.
+func (e *Engine) addDelegatedLocked(tally *VoteTally, propID string, votes map[string]*Vote) {
+	if e.credits == nil {
+		return
+	}
+	at := e.now()
+	if prop, ok := e.proposals[propID]; ok && !prop.ExpiresAt.IsZero() && at.After(prop.ExpiresAt) {
+		at = prop.ExpiresAt
+	}
+
+	for from, d := range e.delegations {
+		if _, voted := votes[from]; voted || !d.activeAt(at) {
+			continue
+		}
+		v, ok := votes[d.To]
+		if !ok {
+			continue
+		}
+		weight := e.credits(from)
+		if weight <= 0 {
+			continue
+		}
+		switch v.Choice {
+		case VoteFor:
+			tally.ForWeight += weight
+		case VoteAgainst:
+			tally.AgainstWeight += weight
+		case VoteAbstain:
+			tally.AbstainWeight += weight
+		}
+		tally.TotalWeight += weight
+		tally.DelegatedWeight += weight
+	}
+}
+
 // ─── Resolution ─────────────────────────────────────────────────────────────
 
 // ResolveExpired checks all active proposals and closes those past deadline.
 // Call this periodically (e.g. every hour).
 // Returns list of proposals that changed state.
+//
+// Passing proposals that target the same ParamKey conflict: only the one
+// with the highest approval goes ahead, and the others are rejected with
+// SupersededBy set. Proposals still in their veto window have not been
+// applied yet, so they compete with newly passed ones too.
 func (e *Engine) ResolveExpired() []*Proposal {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
 	now := e.now()
-	var changed []*Proposal
+	var changed, passed []*Proposal
 
 	for propID, prop := range e.proposals {
+		if prop.Status == PropVetoWindow {
+			if !now.Before(prop.VetoDeadline) {
+				e.executeLocked(prop)
+				changed = append(changed, prop)
+			}
+			continue
+		}
 		if prop.Status != PropActive {
 			continue
 		}
@@ -455,7 +777,8 @@ func (e *Engine) ResolveExpired() []*Proposal {
 		if !tally.QuorumReached {
 			prop.Status = PropExpired
 		} else if tally.ApprovalPct > 50 {
-			prop.Status = PropPassed
+			passed = append(passed, prop) // status set once conflicts are settled
+			continue
 		} else {
 			prop.Status = PropRejected
 		}
@@ -463,7 +786,208 @@ func (e *Engine) ResolveExpired() []*Proposal {
 		changed = append(changed, prop)
 	}
 
-	return changed
+	winners, superseded := e.settleConflictsLocked(passed)
+	for _, prop := range winners {
+		prop.Status = PropPassed
+		if e.vetoable != nil && e.vetoable(prop.ParamKey) {
+			prop.Status = PropVetoWindow
+			prop.VetoDeadline = now.Add(e.vetoDelay())
+		}
+	}
+	changed = append(changed, winners...)
+	return append(changed, superseded...)
+}
+
+// CleanupStaleDrafts expires drafts created more than DraftTTL before now
+// that were never opened, freeing their MaxActiveProposals slots. Returns
+// the expired proposals, oldest first.
+func (e *Engine) CleanupStaleDrafts(now time.Time) []*Proposal {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	cutoff := now.Add(-e.draftTTL())
+	var expired []*Proposal
+	for _, prop := range e.proposals {
+		if prop.Status != PropDraft || !prop.CreatedAt.Before(cutoff) {
+			continue
+		}
+		prop.Status = PropExpired
+		prop.ClosedAt = now
+		expired = append(expired, prop)
+	}
+	sortProposals(expired)
+	return expired
+}
+
+// Run resolves expired proposals (settling conflicts between them) and
+// expires stale drafts every interval until ctx is done. Call in a
+// goroutine.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.resolve()
+		}
+	}
+}
+
+// resolve runs one ResolveExpired and CleanupStaleDrafts pass.
+func (e *Engine) resolve() {
+	for _, prop := range e.ResolveExpired() {
+		log.Printf("[governance] proposal %s is now %s", prop.ID, prop.Status)
+	}
+	if expired := e.CleanupStaleDrafts(e.now()); len(expired) > 0 {
+		log.Printf("[governance] expired %d stale draft(s)", len(expired))
+	}
+}
+
+// draftTTL returns the configured draft lifetime.
+func (e *Engine) draftTTL() time.Duration {
+	if e.config.DraftTTL > 0 {
+		return e.config.DraftTTL
+	}
+	return DefaultDraftTTL
+}
+
+// ConflictingProposals returns the other active proposals targeting the
+// same ParamKey as propID, oldest first. Proposals without a ParamKey never
+// conflict.
+func (e *Engine) ConflictingProposals(propID string) []*Proposal {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	prop, ok := e.proposals[propID]
+	if !ok || prop.ParamKey == "" {
+		return nil
+	}
+	var result []*Proposal
+	for _, p := range e.proposals {
+		if p.ID != propID && p.Status == PropActive && p.ParamKey == prop.ParamKey {
+			result = append(result, p)
+		}
+	}
+	sortProposals(result)
+	return result
+}
+
+// settleConflictsLocked picks, for each ParamKey, the winner among the newly
+// passed proposals and those still in their veto window. Losers are marked
+// rejected; superseded lists every loser, winners the passed proposals that
+// go ahead. Caller must hold the lock.
+func (e *Engine) settleConflictsLocked(passed []*Proposal) (winners, superseded []*Proposal) {
+	sortProposals(passed)
+	contenders := make(map[string][]*Proposal)
+	for _, p := range passed {
+		if p.ParamKey == "" {
+			winners = append(winners, p)
+			continue
+		}
+		contenders[p.ParamKey] = append(contenders[p.ParamKey], p)
+	}
+	if len(contenders) == 0 {
+		return winners, nil
+	}
+	for _, p := range e.proposals {
+		if p.Status == PropVetoWindow && contenders[p.ParamKey] != nil {
+			contenders[p.ParamKey] = append(contenders[p.ParamKey], p)
+		}
+	}
+
+	for _, p := range passed {
+		group := contenders[p.ParamKey]
+		if group == nil {
+			continue // key already settled
+		}
+		delete(contenders, p.ParamKey)
+
+		sortProposals(group)
+		best, bestPct := group[0], e.tallyLocked(group[0].ID).ApprovalPct
+		for _, c := range group[1:] {
+			if pct := e.tallyLocked(c.ID).ApprovalPct; pct > bestPct {
+				best, bestPct = c, pct
+			}
+		}
+		for _, c := range group {
+			if c == best {
+				continue
+			}
+			c.Status = PropRejected
+			c.SupersededBy = best.ID
+			superseded = append(superseded, c)
+		}
+		if best.Status == PropActive {
+			winners = append(winners, best)
+		}
+	}
+	return winners, superseded
+}
+
+// sortProposals orders proposals oldest first, by ID on equal times, so
+// ties in approval go to the earlier proposal.
+func sortProposals(props []*Proposal) {
+	sort.Slice(props, func(i, j int) bool {
+		if !props[i].CreatedAt.Equal(props[j].CreatedAt) {
+			return props[i].CreatedAt.Before(props[j].CreatedAt)
+		}
+		return props[i].ID < props[j].ID
+	})
+}
+
+// ─── Veto ───────────────────────────────────────────────────────────────────
+
+// Veto blocks a proposal during its veto window. councilNodeID must hold a
+// council seat; a single veto suffices.
+func (e *Engine) Veto(propID, councilNodeID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	prop, ok := e.proposals[propID]
+	if !ok {
+		return fmt.Errorf("proposal %s not found", propID)
+	}
+	if prop.Status != PropVetoWindow {
+		return fmt.Errorf("proposal %s is %s, expected VETO_WINDOW", propID, prop.Status)
+	}
+	if !e.now().Before(prop.VetoDeadline) {
+		return errors.New("veto window has closed")
+	}
+	if e.council == nil || !e.council(councilNodeID) {
+		return fmt.Errorf("node %s is not a council member", councilNodeID)
+	}
+
+	prop.Status = PropVetoed
+	prop.VetoedBy = councilNodeID
+	prop.ClosedAt = e.now()
+	return nil
+}
+
+// executeLocked applies a proposal whose veto window elapsed. Without an
+// executor, or if it fails, the proposal is left PASSED for manual
+// application. Caller must hold the lock.
+func (e *Engine) executeLocked(prop *Proposal) {
+	prop.Status = PropPassed
+	if e.executor == nil {
+		return
+	}
+	tally := e.tallyLocked(prop.ID)
+	if err := e.executor(*prop, tally.ApprovalPct); err != nil {
+		log.Printf("[governance] execute %s: %v", prop.ID, err)
+		return
+	}
+	prop.Status = PropExecuted
+}
+
+// vetoDelay returns the configured veto window length.
+func (e *Engine) vetoDelay() time.Duration {
+	if e.config.VetoDelay > 0 {
+		return e.config.VetoDelay
+	}
+	return DefaultVetoDelay
 }
 
 // MarkExecuted marks a passed proposal as executed (config applied).
@@ -483,6 +1007,120 @@ func (e *Engine) MarkExecuted(propID string) error {
 	return nil
 }
 
+// ─── Sortition ──────────────────────────────────────────────────────────────
+
+// PanelDraw records one sortition draw with everything needed to audit it:
+// DrawPanel(Seed, Size, Eligible) reproduces Panel.
+type PanelDraw struct {
+	Seed     int64            `json:"seed"`
+	Size     int              `json:"size"`
+	Eligible map[string]int64 `json:"eligible"` // nodeID → weight at draw time
+	Panel    []string         `json:"panel"`
+	DrawnAt  time.Time        `json:"drawn_at"`
+}
+
+// SelectPanel draws a citizen advisory panel of up to size nodes from
+// eligibleNodes (nodeID → weight) by lot. Each draw gets a fresh random
+// seed, so nobody can predict or steer a panel ahead of time; the seed,
+// the eligible nodes and the panel are recorded (see Panels), and DrawPanel
+// with the same seed and inputs reproduces the panel for audit.
+func (e *Engine) SelectPanel(size int, eligibleNodes map[string]int64) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.selectPanelLocked(size, eligibleNodes).Panel)
+}
+
+// AssignPanel draws a panel as SelectPanel does to review a proposal, and
+// records the panel, its seed and the eligible nodes on the proposal.
+func (e *Engine) AssignPanel(propID string, size int, eligibleNodes map[string]int64) ([]string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	prop, ok := e.proposals[propID]
+	if !ok {
+		return nil, fmt.Errorf("proposal %s not found", propID)
+	}
+	draw := e.selectPanelLocked(size, eligibleNodes)
+	prop.Panel = slices.Clone(draw.Panel)
+	prop.PanelSeed = draw.Seed
+	prop.PanelEligible = maps.Clone(draw.Eligible)
+	return slices.Clone(draw.Panel), nil
+}
+
+// Panels returns every SelectPanel and AssignPanel draw, oldest first.
+func (e *Engine) Panels() []PanelDraw {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	out := make([]PanelDraw, len(e.panels))
+	for i, d := range e.panels {
+		d.Eligible = maps.Clone(d.Eligible)
+		d.Panel = slices.Clone(d.Panel)
+		out[i] = d
+	}
+	return out
+}
+
+// selectPanelLocked draws a panel with a fresh random seed and records the
+// draw. Caller must hold e.mu.
+func (e *Engine) selectPanelLocked(size int, eligibleNodes map[string]int64) PanelDraw {
+	var buf [8]byte
+	cryptorand.Read(buf[:]) // never fails: a broken source crashes the program
+	seed := int64(binary.LittleEndian.Uint64(buf[:]))
+	draw := PanelDraw{
+		Seed:     seed,
+		Size:     size,
+		Eligible: maps.Clone(eligibleNodes),
+		Panel:    e.DrawPanel(seed, size, eligibleNodes),
+		DrawnAt:  e.now(),
+	}
+	e.panels = append(e.panels, draw)
+	return draw
+}
+
+// DrawPanel draws up to size nodes from eligibleNodes (nodeID → weight) by
+// lot. Nodes with a non-positive weight are not eligible. The draw depends
+// only on the inputs, so a recorded panel can be audited by repeating it.
+// Returns the panel in draw order; if fewer than size nodes are eligible,
+// all of them are drawn.
+func (e *Engine) DrawPanel(seed int64, size int, eligibleNodes map[string]int64) []string {
+	if size <= 0 {
+		return nil
+	}
+	candidates := make([]string, 0, len(eligibleNodes))
+	for id, w := range eligibleNodes {
+		if w > 0 {
+			candidates = append(candidates, id)
+		}
+	}
+	// Map order is random; draw from a fixed order so the seed decides.
+	sort.Strings(candidates)
+
+	weight := func(id string) float64 {
+		if e.config.PanelWeighted {
+			return float64(eligibleNodes[id])
+		}
+		return 1
+	}
+	r := rand.New(rand.NewSource(seed))
+	panel := make([]string, 0, min(size, len(candidates)))
+	for len(panel) < size && len(candidates) > 0 {
+		var total float64
+		for _, id := range candidates {
+			total += weight(id)
+		}
+		pick := len(candidates) - 1 // guards against float rounding at the end
+		target := r.Float64() * total
+		for i, id := range candidates {
+			if target -= weight(id); target < 0 {
+				pick = i
+				break
+			}
+		}
+		panel = append(panel, candidates[pick])
+		candidates = append(candidates[:pick], candidates[pick+1:]...)
+	}
+	return panel
+}
+
 // ─── Statistics ─────────────────────────────────────────────────────────────
 
 // Stats returns aggregate governance metrics.
@@ -497,7 +1135,7 @@ func (e *Engine) Stats() GovernanceStats {
 		switch p.Status {
 		case PropActive, PropDraft:
 			stats.ActiveProposals++
-		case PropPassed:
+		case PropPassed, PropVetoWindow:
 			stats.PassedProposals++
 		case PropRejected:
 			stats.RejectedProposals++
@@ -505,6 +1143,8 @@ func (e *Engine) Stats() GovernanceStats {
 			stats.ExpiredProposals++
 		case PropExecuted:
 			stats.ExecutedProposals++
+		case PropVetoed:
+			stats.VetoedProposals++
 		}
 	}
 
@@ -521,3 +1161,22 @@ func (e *Engine) ProposalCount() int {
 	defer e.mu.RUnlock()
 	return len(e.proposals)
 }
+
+// Participation returns how many proposals nodeID has voted on and how many
+// it has authored. A changed vote counts once.
+func (e *Engine) Participation(nodeID string) (votesCast, proposalsAuthored int) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, voters := range e.votes {
+		if _, ok := voters[nodeID]; ok {
+			votesCast++
+		}
+	}
+	for _, p := range e.proposals {
+		if p.Author == nodeID {
+			proposalsAuthored++
+		}
+	}
+	return votesCast, proposalsAuthored
+}
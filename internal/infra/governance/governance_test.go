@@ -1,8 +1,16 @@
 package governance
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // ─── Helpers ────────────────────────────────────────────────────────────────
@@ -99,6 +107,39 @@ func TestCreateProposal_EmptyAuthor(t *testing.T) {
 	}
 }
 
+func TestCreateProposal_ReputationGatedCategory(t *testing.T) {
+	e := newTestEngine(t)
+	scores := map[string]float64{"node-newbie": 0.45, "node-veteran": 0.85}
+	e.SetReputationProvider(func(nodeID string) (float64, bool) {
+		s, ok := scores[nodeID]
+		return s, ok
+	})
+
+	// Low-reputation author is blocked from the gated SLA pricing category...
+	_, err := e.CreateProposal("Cut realtime price", "desc", CatSLAPricing, "node-newbie", 500, "", "")
+	if !errors.Is(err, domain.ErrReputationTooLow) {
+		t.Fatalf("gated category: err = %v, want ErrReputationTooLow", err)
+	}
+
+	// ...but may still propose in an open category.
+	if _, err := e.CreateProposal("Tweak param", "desc", CatNetworkParam, "node-newbie", 500, "", ""); err != nil {
+		t.Fatalf("open category: unexpected error: %v", err)
+	}
+
+	// A reputable author passes the gate.
+	if _, err := e.CreateProposal("Cut realtime price", "desc", CatSLAPricing, "node-veteran", 500, "", ""); err != nil {
+		t.Fatalf("reputable author: unexpected error: %v", err)
+	}
+}
+
+func TestCreateProposal_GatedCategoryWithoutProvider(t *testing.T) {
+	e := newTestEngine(t)
+	_, err := e.CreateProposal("Harden TLS", "desc", CatSecurity, "node-1", 500, "", "")
+	if !errors.Is(err, domain.ErrReputationTooLow) {
+		t.Fatalf("err = %v, want ErrReputationTooLow", err)
+	}
+}
+
 func TestOpenProposal(t *testing.T) {
 	e := newTestEngine(t)
 	prop, _ := e.CreateProposal("Test", "desc", CatNetworkParam, "node-1", 500, "", "")
@@ -257,6 +298,121 @@ func TestCastVote_Expired(t *testing.T) {
 	}
 }
 
+// ─── Delegation ─────────────────────────────────────────────────────────────
+
+// staticCredits returns a CreditProvider backed by a fixed balance map.
+func staticCredits(balances map[string]int64) CreditProvider {
+	return func(nodeID string) int64 { return balances[nodeID] }
+}
+
+func TestDelegation_AddsWeightToDelegateVote(t *testing.T) {
+	e := newTestEngine(t)
+	e.SetCreditProvider(staticCredits(map[string]int64{"node-a": 700}))
+	prop := createAndOpenProposal(t, e, "Delegated")
+
+	if err := e.Delegate("node-a", "node-b"); err != nil {
+		t.Fatalf("Delegate failed: %v", err)
+	}
+	e.CastVote(prop.ID, "node-b", VoteFor, 300)
+
+	tally, _ := e.Tally(prop.ID)
+	if tally.ForWeight != 1000 {
+		t.Errorf("for = %d, want 1000 (300 direct + 700 delegated)", tally.ForWeight)
+	}
+	if tally.DelegatedWeight != 700 {
+		t.Errorf("delegated = %d, want 700", tally.DelegatedWeight)
+	}
+	if tally.VoterCount != 1 {
+		t.Errorf("voters = %d, want 1 direct voter", tally.VoterCount)
+	}
+}
+
+func TestDelegation_ExpiredNoLongerCounts(t *testing.T) {
+	e := newTestEngine(t)
+	e.now = fixedTime(2025, 1, 1)
+	e.SetCreditProvider(staticCredits(map[string]int64{"node-a": 700}))
+	prop := createAndOpenProposal(t, e, "Expiring Delegation")
+
+	expiry := time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC)
+	if err := e.DelegateUntil("node-a", "node-b", expiry); err != nil {
+		t.Fatalf("DelegateUntil failed: %v", err)
+	}
+	e.CastVote(prop.ID, "node-b", VoteFor, 300)
+
+	if tally, _ := e.Tally(prop.ID); tally.ForWeight != 1000 {
+		t.Fatalf("for before expiry = %d, want 1000", tally.ForWeight)
+	}
+
+	e.now = fixedTime(2025, 1, 4) // past delegation expiry, before voting deadline
+	tally, _ := e.Tally(prop.ID)
+	if tally.ForWeight != 300 {
+		t.Errorf("for after expiry = %d, want 300", tally.ForWeight)
+	}
+	if tally.DelegatedWeight != 0 {
+		t.Errorf("delegated after expiry = %d, want 0", tally.DelegatedWeight)
+	}
+}
+
+func TestDelegation_DirectVoteOverridesForThatProposalOnly(t *testing.T) {
+	e := newTestEngine(t)
+	e.now = tickingClock()
+	e.SetCreditProvider(staticCredits(map[string]int64{"node-a": 700}))
+	first := createAndOpenProposal(t, e, "First")
+	second := createAndOpenProposal(t, e, "Second")
+
+	e.Delegate("node-a", "node-b")
+	e.CastVote(first.ID, "node-b", VoteFor, 300)
+	e.CastVote(second.ID, "node-b", VoteFor, 300)
+	e.CastVote(first.ID, "node-a", VoteAgainst, 700) // direct override
+
+	t1, _ := e.Tally(first.ID)
+	if t1.ForWeight != 300 || t1.AgainstWeight != 700 {
+		t.Errorf("first: for=%d against=%d, want 300/700", t1.ForWeight, t1.AgainstWeight)
+	}
+	if t1.DelegatedWeight != 0 {
+		t.Errorf("first: delegated = %d, want 0 after override", t1.DelegatedWeight)
+	}
+
+	t2, _ := e.Tally(second.ID)
+	if t2.ForWeight != 1000 {
+		t.Errorf("second: for = %d, want 1000 — delegation should still apply", t2.ForWeight)
+	}
+	if e.DelegationOf("node-a") == nil {
+		t.Error("delegation removed by direct vote, want intact")
+	}
+}
+
+func TestUndelegate(t *testing.T) {
+	e := newTestEngine(t)
+	e.SetCreditProvider(staticCredits(map[string]int64{"node-a": 700}))
+	prop := createAndOpenProposal(t, e, "Revoked")
+
+	e.Delegate("node-a", "node-b")
+	e.CastVote(prop.ID, "node-b", VoteFor, 300)
+
+	if !e.Undelegate("node-a") {
+		t.Fatal("Undelegate = false, want true for existing delegation")
+	}
+	if tally, _ := e.Tally(prop.ID); tally.ForWeight != 300 {
+		t.Errorf("for after undelegate = %d, want 300", tally.ForWeight)
+	}
+	if e.Undelegate("node-a") {
+		t.Error("second Undelegate = true, want false")
+	}
+}
+
+func TestDelegate_Invalid(t *testing.T) {
+	e := newTestEngine(t)
+	e.now = fixedTime(2025, 1, 2)
+
+	if err := e.Delegate("node-a", "node-a"); err == nil {
+		t.Error("expected error delegating to self")
+	}
+	if err := e.DelegateUntil("node-a", "node-b", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("expected error for expiry in the past")
+	}
+}
+
 // ─── Quorum + Approval Tests ───────────────────────────────────────────────
 
 func TestTally_QuorumReached(t *testing.T) {
@@ -350,6 +506,147 @@ func TestResolveExpired_NoQuorum(t *testing.T) {
 	}
 }
 
+func TestResolveExpired_AbstainMode(t *testing.T) {
+	// 3000 for, 2000 against, 2000 abstain: 60% approval when abstentions
+	// are ignored, 3000/7000 ≈ 43% when they count against.
+	tests := []struct {
+		mode     AbstainMode
+		want     ProposalStatus
+		approval float64
+	}{
+		{AbstainIgnore, PropPassed, 60},
+		{AbstainAsAgainst, PropRejected, 3000.0 / 7000 * 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			cfg := DefaultEngineConfig()
+			cfg.AbstainMode = tt.mode
+			e := NewEngine(cfg)
+			e.SetTotalCredits(10000)
+			e.now = fixedTime(2025, 1, 1)
+
+			prop := createAndOpenProposal(t, e, "Abstain Test")
+			e.CastVote(prop.ID, "node-1", VoteFor, 3000)
+			e.CastVote(prop.ID, "node-2", VoteAgainst, 2000)
+			e.CastVote(prop.ID, "node-3", VoteAbstain, 2000)
+
+			tally, _ := e.Tally(prop.ID)
+			if !tally.QuorumReached {
+				t.Error("abstentions should count toward quorum")
+			}
+			if diff := tally.ApprovalPct - tt.approval; diff > 0.001 || diff < -0.001 {
+				t.Errorf("ApprovalPct = %.3f, want %.3f", tally.ApprovalPct, tt.approval)
+			}
+
+			e.now = fixedTime(2025, 1, 10)
+			changed := e.ResolveExpired()
+			if len(changed) != 1 || changed[0].Status != tt.want {
+				t.Fatalf("resolved = %+v, want status %v", changed, tt.want)
+			}
+		})
+	}
+}
+
+// openParamProposal creates and opens a proposal setting key to value.
+func openParamProposal(t *testing.T, e *Engine, key, value string) *Proposal {
+	t.Helper()
+	prop, err := e.CreateProposal("Set "+key+" to "+value, "test description", CatNetworkParam, "node-author", 500, key, value)
+	if err != nil {
+		t.Fatalf("CreateProposal failed: %v", err)
+	}
+	if err := e.OpenProposal(prop.ID); err != nil {
+		t.Fatalf("OpenProposal(%s) failed: %v", prop.ID, err)
+	}
+	return prop
+}
+
+func TestConflictingProposals(t *testing.T) {
+	e := newTestEngine(t)
+	e.now = tickingClock()
+
+	low := openParamProposal(t, e, "earning_rate_base", "1.5")
+	high := openParamProposal(t, e, "earning_rate_base", "2.0")
+	other := openParamProposal(t, e, "max_model_size", "70B")
+
+	got := e.ConflictingProposals(low.ID)
+	if len(got) != 1 || got[0].ID != high.ID {
+		t.Errorf("ConflictingProposals(low) = %v, want [%s]", got, high.ID)
+	}
+	if got := e.ConflictingProposals(other.ID); len(got) != 0 {
+		t.Errorf("ConflictingProposals(other) = %v, want none", got)
+	}
+	if got := e.ConflictingProposals("missing"); got != nil {
+		t.Errorf("ConflictingProposals(missing) = %v, want nil", got)
+	}
+}
+
+func TestResolveExpired_ConflictingProposalsSingleWinner(t *testing.T) {
+	e := newTestEngine(t)
+	e.now = tickingClock()
+
+	low := openParamProposal(t, e, "earning_rate_base", "1.5")
+	high := openParamProposal(t, e, "earning_rate_base", "2.0")
+	e.CastVote(low.ID, "node-1", VoteFor, 3000)
+	e.CastVote(low.ID, "node-2", VoteAgainst, 2000) // 60%
+	e.CastVote(high.ID, "node-1", VoteFor, 4000)
+	e.CastVote(high.ID, "node-2", VoteAgainst, 1000) // 80%
+
+	e.now = fixedTime(2025, 1, 10)
+	if changed := e.ResolveExpired(); len(changed) != 2 {
+		t.Fatalf("changed = %d proposals, want 2", len(changed))
+	}
+	if got, _ := e.GetProposal(high.ID); got.Status != PropPassed {
+		t.Errorf("higher-approval proposal = %v, want PASSED", got.Status)
+	}
+	got, _ := e.GetProposal(low.ID)
+	if got.Status != PropRejected || got.SupersededBy != high.ID {
+		t.Errorf("lower-approval proposal = %v superseded by %q, want REJECTED by %s", got.Status, got.SupersededBy, high.ID)
+	}
+}
+
+func TestResolveExpired_ConflictWithVetoWindow(t *testing.T) {
+	cfg := DefaultEngineConfig()
+	cfg.VetoDelay = 14 * 24 * time.Hour
+	e := NewEngine(cfg)
+	e.SetTotalCredits(10000)
+	e.SetVetoPolicy(func(string) bool { return true }, func(string) bool { return false })
+	var applied []string
+	e.SetExecutor(func(p Proposal, _ float64) error { applied = append(applied, p.ParamValue); return nil })
+
+	e.now = fixedTime(2025, 1, 1)
+	pending := openParamProposal(t, e, "earning_rate_base", "2.0")
+	e.CastVote(pending.ID, "node-1", VoteFor, 4000)
+	e.now = fixedTime(2025, 1, 5)
+	later := openParamProposal(t, e, "earning_rate_base", "1.5")
+	e.CastVote(later.ID, "node-1", VoteFor, 3000)
+	e.CastVote(later.ID, "node-2", VoteAgainst, 1000)
+
+	e.now = fixedTime(2025, 1, 9)
+	e.ResolveExpired()
+	if got, _ := e.GetProposal(pending.ID); got.Status != PropVetoWindow {
+		t.Fatalf("first proposal = %v, want VETO_WINDOW", got.Status)
+	}
+
+	// The later, weaker proposal passes while the first is unapplied.
+	e.now = fixedTime(2025, 1, 13)
+	e.ResolveExpired()
+	if got, _ := e.GetProposal(later.ID); got.Status != PropRejected || got.SupersededBy != pending.ID {
+		t.Errorf("later proposal = %v superseded by %q, want REJECTED by %s", got.Status, got.SupersededBy, pending.ID)
+	}
+
+	e.now = fixedTime(2025, 1, 30)
+	e.ResolveExpired()
+	if len(applied) != 1 || applied[0] != "2.0" {
+		t.Errorf("applied = %v, want only the winner", applied)
+	}
+}
+
+func TestDefaultEngineConfig_IgnoresAbstentions(t *testing.T) {
+	if m := DefaultEngineConfig().AbstainMode; m != AbstainIgnore {
+		t.Errorf("default AbstainMode = %v, want IGNORE", m)
+	}
+}
+
 func TestMarkExecuted(t *testing.T) {
 	e := newTestEngine(t)
 	e.SetTotalCredits(10000)
@@ -380,6 +677,158 @@ func TestMarkExecuted_NotPassed(t *testing.T) {
 	}
 }
 
+// ─── Veto ───────────────────────────────────────────────────────────────────
+
+// passIntoVetoWindow passes a veto-eligible proposal and resolves it into
+// its veto window. Only "node-council" holds a council seat.
+func passIntoVetoWindow(t *testing.T, e *Engine) *Proposal {
+	t.Helper()
+	e.SetVetoPolicy(
+		func(key string) bool { return key == "test.key" },
+		func(nodeID string) bool { return nodeID == "node-council" },
+	)
+	e.now = fixedTime(2025, 1, 1)
+	prop := createAndOpenProposal(t, e, "Protected Change")
+	e.CastVote(prop.ID, "node-1", VoteFor, 5000)
+
+	e.now = fixedTime(2025, 1, 10)
+	e.ResolveExpired()
+	got, _ := e.GetProposal(prop.ID)
+	if got.Status != PropVetoWindow {
+		t.Fatalf("status = %v, want VETO_WINDOW", got.Status)
+	}
+	if want := e.now().Add(DefaultVetoDelay); !got.VetoDeadline.Equal(want) {
+		t.Fatalf("VetoDeadline = %v, want %v", got.VetoDeadline, want)
+	}
+	return got
+}
+
+func TestVeto_BlocksExecution(t *testing.T) {
+	e := newTestEngine(t)
+	executed := 0
+	e.SetExecutor(func(Proposal, float64) error { executed++; return nil })
+	prop := passIntoVetoWindow(t, e)
+
+	if err := e.Veto(prop.ID, "node-council"); err != nil {
+		t.Fatalf("Veto: %v", err)
+	}
+	got, _ := e.GetProposal(prop.ID)
+	if got.Status != PropVetoed || got.VetoedBy != "node-council" {
+		t.Fatalf("status = %v by %q, want VETOED by node-council", got.Status, got.VetoedBy)
+	}
+
+	e.now = fixedTime(2025, 1, 20)
+	if changed := e.ResolveExpired(); len(changed) != 0 {
+		t.Errorf("vetoed proposal should not resolve again, got %d changes", len(changed))
+	}
+	if executed != 0 {
+		t.Errorf("executor ran %d times for a vetoed proposal", executed)
+	}
+	if err := e.MarkExecuted(prop.ID); err == nil {
+		t.Error("vetoed proposal should not be executable")
+	}
+	if stats := e.Stats(); stats.VetoedProposals != 1 {
+		t.Errorf("VetoedProposals = %d, want 1", stats.VetoedProposals)
+	}
+}
+
+func TestVeto_ExecutesAfterDelay(t *testing.T) {
+	e := newTestEngine(t)
+	var applied []string
+	e.SetExecutor(func(p Proposal, approvalPct float64) error {
+		if approvalPct != 100 {
+			t.Errorf("approvalPct = %v, want 100", approvalPct)
+		}
+		applied = append(applied, p.ParamValue)
+		return nil
+	})
+	prop := passIntoVetoWindow(t, e)
+
+	// Still inside the window — nothing happens.
+	e.now = fixedTime(2025, 1, 11)
+	e.ResolveExpired()
+	if got, _ := e.GetProposal(prop.ID); got.Status != PropVetoWindow {
+		t.Fatalf("status = %v, want VETO_WINDOW before deadline", got.Status)
+	}
+
+	e.now = fixedTime(2025, 1, 13)
+	changed := e.ResolveExpired()
+	if len(changed) != 1 || changed[0].Status != PropExecuted {
+		t.Fatalf("changed = %v, want one EXECUTED proposal", changed)
+	}
+	if len(applied) != 1 || applied[0] != "new-value" {
+		t.Errorf("executor applied %v, want [new-value]", applied)
+	}
+	if err := e.Veto(prop.ID, "node-council"); err == nil {
+		t.Error("veto after execution should fail")
+	}
+}
+
+func TestVeto_NoExecutorLeavesPassed(t *testing.T) {
+	e := newTestEngine(t)
+	prop := passIntoVetoWindow(t, e)
+
+	e.now = fixedTime(2025, 1, 13)
+	e.ResolveExpired()
+	got, _ := e.GetProposal(prop.ID)
+	if got.Status != PropPassed {
+		t.Fatalf("status = %v, want PASSED", got.Status)
+	}
+	if err := e.MarkExecuted(prop.ID); err != nil {
+		t.Errorf("MarkExecuted after veto window: %v", err)
+	}
+}
+
+func TestVeto_FailedExecutorLeavesPassed(t *testing.T) {
+	e := newTestEngine(t)
+	e.SetExecutor(func(Proposal, float64) error { return errors.New("apply failed") })
+	prop := passIntoVetoWindow(t, e)
+
+	e.now = fixedTime(2025, 1, 13)
+	e.ResolveExpired()
+	if got, _ := e.GetProposal(prop.ID); got.Status != PropPassed {
+		t.Errorf("status = %v, want PASSED after executor failure", got.Status)
+	}
+}
+
+func TestVeto_Rejections(t *testing.T) {
+	e := newTestEngine(t)
+	prop := passIntoVetoWindow(t, e)
+
+	if err := e.Veto(prop.ID, "node-1"); err == nil {
+		t.Error("non-council veto should fail")
+	}
+	if err := e.Veto("prop-missing", "node-council"); err == nil {
+		t.Error("veto of unknown proposal should fail")
+	}
+
+	e.now = fixedTime(2025, 1, 12) // exactly at the deadline
+	if err := e.Veto(prop.ID, "node-council"); err == nil {
+		t.Error("veto at the deadline should fail")
+	}
+}
+
+func TestVeto_IneligibleParamPassesDirectly(t *testing.T) {
+	e := newTestEngine(t)
+	e.SetVetoPolicy(
+		func(string) bool { return false },
+		func(string) bool { return true },
+	)
+	e.now = fixedTime(2025, 1, 1)
+	prop := createAndOpenProposal(t, e, "Ordinary Change")
+	e.CastVote(prop.ID, "node-1", VoteFor, 5000)
+
+	e.now = fixedTime(2025, 1, 10)
+	e.ResolveExpired()
+	got, _ := e.GetProposal(prop.ID)
+	if got.Status != PropPassed {
+		t.Fatalf("status = %v, want PASSED", got.Status)
+	}
+	if err := e.Veto(prop.ID, "node-council"); err == nil {
+		t.Error("veto outside a veto window should fail")
+	}
+}
+
 // ─── List + Stats Tests ────────────────────────────────────────────────────
 
 func TestListProposals(t *testing.T) {
@@ -400,6 +849,109 @@ func TestListProposals(t *testing.T) {
 	}
 }
 
+// seedSearchProposals creates proposals one hour apart, oldest first.
+func seedSearchProposals(t *testing.T, e *Engine) []*Proposal {
+	t.Helper()
+	e.SetReputationProvider(func(string) (float64, bool) { return 1, true })
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	seed := []struct {
+		title, desc string
+		cat         ProposalCategory
+		author      string
+	}{
+		{"Raise earning rate", "Increase credits per token", CatEarningRate, "node-1"},
+		{"Cut spot pricing", "Lower SLA pricing for spot tier", CatSLAPricing, "node-1"},
+		{"Earning rate floor", "Guarantee a minimum EARNING multiplier", CatEarningRate, "node-2"},
+		{"Bigger gossip fanout", "Network tuning", CatNetworkParam, "node-1"},
+		{"Earning rate cap", "Cap the multiplier", CatEarningRate, "node-1"},
+	}
+	props := make([]*Proposal, len(seed))
+	for i, s := range seed {
+		at := base.Add(time.Duration(i) * time.Hour)
+		e.now = func() time.Time { return at }
+		p, err := e.CreateProposal(s.title, s.desc, s.cat, s.author, 500, "", "")
+		if err != nil {
+			t.Fatalf("CreateProposal(%q): %v", s.title, err)
+		}
+		props[i] = p
+	}
+	return props
+}
+
+func proposalTitles(props []*Proposal) []string {
+	titles := make([]string, len(props))
+	for i, p := range props {
+		titles[i] = p.Title
+	}
+	return titles
+}
+
+func TestSearchProposals_CategoryAndAuthor(t *testing.T) {
+	e := newTestEngine(t)
+	seedSearchProposals(t, e)
+
+	cat := CatEarningRate
+	got := proposalTitles(e.SearchProposals(ProposalFilter{Category: &cat, Author: "node-1"}))
+	want := []string{"Earning rate cap", "Raise earning rate"} // newest first
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("category+author = %v, want %v", got, want)
+	}
+
+	if got := e.SearchProposals(ProposalFilter{Category: &cat, Author: "node-9"}); len(got) != 0 {
+		t.Errorf("unknown author matched %v", proposalTitles(got))
+	}
+}
+
+func TestSearchProposals_Text(t *testing.T) {
+	e := newTestEngine(t)
+	seedSearchProposals(t, e)
+
+	// Matches titles and descriptions, case-insensitively.
+	got := proposalTitles(e.SearchProposals(ProposalFilter{Text: "earning"}))
+	want := []string{"Earning rate cap", "Earning rate floor", "Raise earning rate"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("text search = %v, want %v", got, want)
+	}
+
+	got = proposalTitles(e.SearchProposals(ProposalFilter{Text: "PRICING"}))
+	if len(got) != 1 || got[0] != "Cut spot pricing" {
+		t.Errorf("description search = %v, want [Cut spot pricing]", got)
+	}
+
+	// Text combines with other fields.
+	got = proposalTitles(e.SearchProposals(ProposalFilter{Text: "multiplier", Author: "node-2"}))
+	if len(got) != 1 || got[0] != "Earning rate floor" {
+		t.Errorf("text+author = %v, want [Earning rate floor]", got)
+	}
+}
+
+func TestSearchProposals_DateRangeAndStatus(t *testing.T) {
+	e := newTestEngine(t)
+	props := seedSearchProposals(t, e)
+
+	got := proposalTitles(e.SearchProposals(ProposalFilter{
+		CreatedAfter:  props[1].CreatedAt,
+		CreatedBefore: props[3].CreatedAt,
+	}))
+	want := []string{"Earning rate floor", "Cut spot pricing"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("date range = %v, want %v", got, want)
+	}
+
+	if err := e.OpenProposal(props[4].ID); err != nil {
+		t.Fatal(err)
+	}
+	active := PropActive
+	got = proposalTitles(e.SearchProposals(ProposalFilter{Status: &active, Text: "earning"}))
+	if len(got) != 1 || got[0] != "Earning rate cap" {
+		t.Errorf("status+text = %v, want [Earning rate cap]", got)
+	}
+
+	if got := e.SearchProposals(ProposalFilter{}); len(got) != len(props) {
+		t.Errorf("empty filter returned %d proposals, want %d", len(got), len(props))
+	}
+}
+
 func TestStats(t *testing.T) {
 	e := newTestEngine(t)
 	e.now = tickingClock()
@@ -441,6 +993,8 @@ func TestProposalStatusString(t *testing.T) {
 		{PropExpired, "EXPIRED"},
 		{PropExecuted, "EXECUTED"},
 		{PropCancelled, "CANCELLED"},
+		{PropVetoWindow, "VETO_WINDOW"},
+		{PropVetoed, "VETOED"},
 		{ProposalStatus(99), "UNKNOWN"},
 	}
 	for _, tt := range tests {
@@ -491,3 +1045,225 @@ func TestCreateProposal_MaxActive(t *testing.T) {
 		t.Fatal("expected error for exceeding max active proposals")
 	}
 }
+
+func TestCleanupStaleDrafts(t *testing.T) {
+	e := newTestEngine(t)
+
+	e.now = fixedTime(2025, 1, 1)
+	stale, err := e.CreateProposal("Stale", "desc", CatNetworkParam, "node-1", 500, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.now = fixedTime(2025, 1, 2)
+	opened := createAndOpenProposal(t, e, "Opened")
+	e.now = fixedTime(2025, 1, 20)
+	recent, err := e.CreateProposal("Recent", "desc", CatNetworkParam, "node-1", 500, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := e.now().Add(24 * time.Hour) // 21 days after the stale draft, 1 after the recent one
+	expired := e.CleanupStaleDrafts(now)
+	if len(expired) != 1 || expired[0].ID != stale.ID {
+		t.Fatalf("CleanupStaleDrafts() = %v, want only the stale draft", expired)
+	}
+	if stale.Status != PropExpired || !stale.ClosedAt.Equal(now) {
+		t.Errorf("stale draft = %s closed %v, want EXPIRED at %v", stale.Status, stale.ClosedAt, now)
+	}
+	if recent.Status != PropDraft {
+		t.Errorf("recent draft = %s, want DRAFT", recent.Status)
+	}
+	if opened.Status != PropActive {
+		t.Errorf("opened proposal = %s, want ACTIVE", opened.Status)
+	}
+	if again := e.CleanupStaleDrafts(now); len(again) != 0 {
+		t.Errorf("second CleanupStaleDrafts() = %v, want nothing", again)
+	}
+}
+
+func TestCleanupStaleDrafts_FreesActiveSlots(t *testing.T) {
+	cfg := DefaultEngineConfig()
+	cfg.DraftTTL = time.Hour
+	e := NewEngine(cfg)
+	e.SetTotalCredits(10000)
+	e.now = tickingClock()
+
+	for i := 0; i < MaxActiveProposals; i++ {
+		if _, err := e.CreateProposal("Test", "desc", CatNetworkParam, "node-1", 500, "", ""); err != nil {
+			t.Fatalf("proposal %d failed: %v", i, err)
+		}
+	}
+	if _, err := e.CreateProposal("Full", "desc", CatNetworkParam, "node-1", 500, "", ""); err == nil {
+		t.Fatal("expected error while the drafts fill every slot")
+	}
+
+	if n := len(e.CleanupStaleDrafts(e.now().Add(2 * time.Hour))); n != MaxActiveProposals {
+		t.Fatalf("expired %d drafts, want %d", n, MaxActiveProposals)
+	}
+	if _, err := e.CreateProposal("Fresh", "desc", CatNetworkParam, "node-1", 500, "", ""); err != nil {
+		t.Errorf("CreateProposal after cleanup: %v", err)
+	}
+}
+
+func TestRun_ResolvesExpiredAndStaleDrafts(t *testing.T) {
+	e := newTestEngine(t)
+	e.now = fixedTime(2025, 1, 1)
+	if _, err := e.CreateProposal("Stale", "desc", CatNetworkParam, "node-1", 500, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	e.now = fixedTime(2025, 1, 2)
+	createAndOpenProposal(t, e, "Unvoted") // no votes: expires without quorum
+	e.now = fixedTime(2025, 1, 20)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Run(ctx, 5*time.Millisecond)
+
+	expired := PropExpired
+	deadline := time.Now().Add(2 * time.Second)
+	for len(e.ListProposals(&expired)) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Run expired %d proposals, want 2", len(e.ListProposals(&expired)))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestParticipation(t *testing.T) {
+	e := newTestEngine(t)
+	e.now = tickingClock()
+
+	p1 := createAndOpenProposal(t, e, "first")
+	p2 := createAndOpenProposal(t, e, "second")
+
+	e.CastVote(p1.ID, "node-voter", VoteFor, 100)
+	e.CastVote(p1.ID, "node-voter", VoteAgainst, 100) // changed vote counts once
+	e.CastVote(p2.ID, "node-voter", VoteFor, 100)
+	e.CastVote(p2.ID, "node-author", VoteFor, 100)
+
+	votes, authored := e.Participation("node-voter")
+	if votes != 2 || authored != 0 {
+		t.Errorf("Participation(node-voter) = %d, %d; want 2, 0", votes, authored)
+	}
+	votes, authored = e.Participation("node-author")
+	if votes != 1 || authored != 2 {
+		t.Errorf("Participation(node-author) = %d, %d; want 1, 2", votes, authored)
+	}
+	votes, authored = e.Participation("node-idle")
+	if votes != 0 || authored != 0 {
+		t.Errorf("Participation(node-idle) = %d, %d; want 0, 0", votes, authored)
+	}
+}
+
+// ─── Sortition ──────────────────────────────────────────────────────────────
+
+func panelEngine(weighted bool) *Engine {
+	cfg := DefaultEngineConfig()
+	cfg.PanelWeighted = weighted
+	return NewEngine(cfg)
+}
+
+func TestDrawPanel_Reproducible(t *testing.T) {
+	eligible := make(map[string]int64)
+	for i := range 20 {
+		eligible[fmt.Sprintf("node-%02d", i)] = int64(100 * (i + 1))
+	}
+	for _, weighted := range []bool{false, true} {
+		first := panelEngine(weighted).DrawPanel(42, 5, eligible)
+		if len(first) != 5 {
+			t.Fatalf("weighted=%v: panel = %v, want 5 members", weighted, first)
+		}
+		for range 3 {
+			if again := panelEngine(weighted).DrawPanel(42, 5, eligible); !slices.Equal(again, first) {
+				t.Errorf("weighted=%v: panel = %v, want %v for the same seed", weighted, again, first)
+			}
+		}
+		if other := panelEngine(weighted).DrawPanel(7, 5, eligible); slices.Equal(other, first) {
+			t.Errorf("weighted=%v: seeds 42 and 7 drew the same panel %v", weighted, first)
+		}
+	}
+}
+
+func TestDrawPanel_RespectsEligibility(t *testing.T) {
+	eligible := map[string]int64{"node-a": 500, "node-b": 300, "node-zero": 0, "node-neg": -10}
+	for _, weighted := range []bool{false, true} {
+		panel := panelEngine(weighted).DrawPanel(1, 10, eligible)
+		slices.Sort(panel)
+		if want := []string{"node-a", "node-b"}; !slices.Equal(panel, want) {
+			t.Errorf("weighted=%v: panel = %v, want only %v", weighted, panel, want)
+		}
+	}
+	if panel := panelEngine(false).DrawPanel(1, 0, eligible); panel != nil {
+		t.Errorf("DrawPanel(size 0) = %v, want nil", panel)
+	}
+}
+
+func TestDrawPanel_WeightedFavoursHeavyNodes(t *testing.T) {
+	eligible := map[string]int64{"whale": 1_000_000, "minnow": 1}
+	whaleFirst := 0
+	for seed := range int64(50) {
+		if panelEngine(true).DrawPanel(seed, 1, eligible)[0] == "whale" {
+			whaleFirst++
+		}
+	}
+	if whaleFirst < 49 {
+		t.Errorf("whale drawn in %d/50 weighted draws, want nearly all", whaleFirst)
+	}
+}
+
+func TestSelectPanel_RecordsDraw(t *testing.T) {
+	e := panelEngine(true)
+	eligible := make(map[string]int64)
+	for i := range 20 {
+		eligible[fmt.Sprintf("node-%02d", i)] = int64(100 * (i + 1))
+	}
+
+	first := e.SelectPanel(5, eligible)
+	second := e.SelectPanel(5, eligible)
+	eligible["node-00"] = 0 // later changes must not rewrite the record
+
+	draws := e.Panels()
+	if len(draws) != 2 {
+		t.Fatalf("Panels() = %d draws, want 2", len(draws))
+	}
+	for i, panel := range [][]string{first, second} {
+		d := draws[i]
+		if !slices.Equal(d.Panel, panel) || d.Size != 5 {
+			t.Errorf("draw %d = %+v, want panel %v of size 5", i, d, panel)
+		}
+		if d.Eligible["node-00"] != 100 {
+			t.Errorf("draw %d eligibility = %v, want the snapshot at draw time", i, d.Eligible)
+		}
+		if replay := e.DrawPanel(d.Seed, d.Size, d.Eligible); !slices.Equal(replay, panel) {
+			t.Errorf("replaying draw %d drew %v, want %v", i, replay, panel)
+		}
+	}
+	if draws[0].Seed == draws[1].Seed {
+		t.Errorf("two draws shared seed %d; want a fresh seed per draw", draws[0].Seed)
+	}
+}
+
+func TestAssignPanel_RecordsOnProposal(t *testing.T) {
+	e := panelEngine(true)
+	eligible := map[string]int64{"node-a": 100, "node-b": 200, "node-c": 300}
+	prop, err := e.CreateProposal("Panel review", "advisory", CatNetworkParam, "node-a", 500, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	panel, err := e.AssignPanel(prop.ID, 2, eligible)
+	if err != nil {
+		t.Fatalf("AssignPanel() error: %v", err)
+	}
+	got, _ := e.GetProposal(prop.ID)
+	if !slices.Equal(got.Panel, panel) || !maps.Equal(got.PanelEligible, eligible) {
+		t.Errorf("recorded panel = %v from %v, want %v from %v", got.Panel, got.PanelEligible, panel, eligible)
+	}
+	if replay := e.DrawPanel(got.PanelSeed, 2, got.PanelEligible); !slices.Equal(replay, panel) {
+		t.Errorf("replaying seed %d drew %v, want %v", got.PanelSeed, replay, panel)
+	}
+
+	if _, err := e.AssignPanel("missing", 2, eligible); err == nil {
+		t.Error("AssignPanel(missing proposal) should fail")
+	}
+}
@@ -297,6 +297,14 @@ func (qm *QuarantineManager) RecordVerificationFailure(nodeID string) *Quarantin
 	return qm.quarantineLocked(nodeID, QuarantineVerificationFail)
 }
 
+// Quarantine places a node in quarantine for reason, with the same
+// duration and ban escalation as automatic quarantines.
+func (qm *QuarantineManager) Quarantine(nodeID string, reason QuarantineReason) *QuarantineRecord {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+	return qm.quarantineLocked(nodeID, reason)
+}
+
 // IsQuarantined checks if a node is currently quarantined.
 func (qm *QuarantineManager) IsQuarantined(nodeID string) bool {
 	qm.mu.Lock()
@@ -266,6 +266,26 @@ func TestQuarantine_FailureThresholdTriggers(t *testing.T) {
 	}
 }
 
+func TestQuarantine_ExplicitReason(t *testing.T) {
+	clock := time.Now()
+	qm := newTestQM(t, func() time.Time { return clock })
+
+	rec := qm.Quarantine("node-1", QuarantineAnomaly)
+	if rec.Reason != QuarantineAnomaly {
+		t.Errorf("Reason = %q, want %q", rec.Reason, QuarantineAnomaly)
+	}
+	if got := rec.ExpiresAt.Sub(rec.StartedAt); got != time.Hour {
+		t.Errorf("anomaly quarantine lasts %v, want 1h", got)
+	}
+	if !qm.IsQuarantined("node-1") {
+		t.Error("node should be quarantined")
+	}
+	qm.Release("node-1")
+	if qm.IsQuarantined("node-1") {
+		t.Error("node should be released")
+	}
+}
+
 func TestQuarantine_FailureCountReset(t *testing.T) {
 	clock := time.Now()
 	qm := newTestQM(t, func() time.Time { return clock })
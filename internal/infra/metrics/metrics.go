@@ -4,8 +4,12 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/tutu-network/tutu/internal/infra/selfheal"
 )
 
 // ─── Inference ──────────────────────────────────────────────────────────────
@@ -167,3 +171,54 @@ var GossipConvergenceTime = promauto.NewHistogram(prometheus.HistogramOpts{
 	Help:      "Time for gossip membership convergence.",
 	Buckets:   []float64{0.1, 0.5, 1, 2, 5, 10, 30},
 })
+
+// ─── Self-Healing ───────────────────────────────────────────────────────────
+
+// SelfHealActiveIncidents tracks active incidents by lifecycle state.
+var SelfHealActiveIncidents = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "tutu",
+	Name:      "selfheal_active_incidents",
+	Help:      "Active self-healing incidents by state.",
+}, []string{"state"})
+
+// SelfHealResolved tracks incidents resolved autonomously.
+var SelfHealResolved = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "selfheal_resolved_total",
+	Help:      "Total incidents resolved autonomously, by failure type.",
+}, []string{"failure_type"})
+
+// SelfHealEscalated tracks incidents escalated to humans.
+var SelfHealEscalated = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "selfheal_escalated_total",
+	Help:      "Total incidents escalated to humans, by failure type.",
+}, []string{"failure_type"})
+
+// SelfHealMTTR tracks time from detection to resolution.
+var SelfHealMTTR = promauto.NewHistogram(prometheus.HistogramOpts{
+	Namespace: "tutu",
+	Name:      "selfheal_mttr_seconds",
+	Help:      "Time from incident detection to autonomous resolution.",
+	Buckets:   []float64{5, 15, 30, 60, 120, 300, 600, 1800},
+})
+
+// SelfHealRecorder exports self-healing lifecycle metrics to Prometheus.
+// Install it as selfheal.Config.Recorder.
+type SelfHealRecorder struct{}
+
+// SetActiveIncidents implements selfheal.Recorder.
+func (SelfHealRecorder) SetActiveIncidents(state selfheal.IncidentState, count int) {
+	SelfHealActiveIncidents.WithLabelValues(state.String()).Set(float64(count))
+}
+
+// IncidentResolved implements selfheal.Recorder.
+func (SelfHealRecorder) IncidentResolved(failure selfheal.FailureType, mttr time.Duration) {
+	SelfHealResolved.WithLabelValues(string(failure)).Inc()
+	SelfHealMTTR.Observe(mttr.Seconds())
+}
+
+// IncidentEscalated implements selfheal.Recorder.
+func (SelfHealRecorder) IncidentEscalated(failure selfheal.FailureType) {
+	SelfHealEscalated.WithLabelValues(string(failure)).Inc()
+}
@@ -2,8 +2,11 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/tutu-network/tutu/internal/infra/selfheal"
 )
 
 func TestInferenceLatency_Registered(t *testing.T) {
@@ -187,3 +190,26 @@ func TestHeartbeatLatency(t *testing.T) {
 		t.Error("tutu_heartbeat_latency_seconds not found")
 	}
 }
+
+func TestSelfHealRecorder(t *testing.T) {
+	var r SelfHealRecorder
+	r.SetActiveIncidents(selfheal.StateIsolating, 2)
+	r.IncidentResolved(selfheal.FailDiskFull, 90*time.Second)
+	r.IncidentEscalated(selfheal.FailGPUError)
+
+	families, _ := prometheus.DefaultGatherer.Gather()
+	names := make(map[string]bool)
+	for _, f := range families {
+		names[f.GetName()] = true
+	}
+	for _, want := range []string{
+		"tutu_selfheal_active_incidents",
+		"tutu_selfheal_resolved_total",
+		"tutu_selfheal_escalated_total",
+		"tutu_selfheal_mttr_seconds",
+	} {
+		if !names[want] {
+			t.Errorf("%s not found in gathered metrics", want)
+		}
+	}
+}
@@ -92,12 +92,18 @@ func NewTopologyManager(cfg Config) *TopologyManager {
 	}
 }
 
-// RegisterContinent adds or updates a continent in the topology.
+// RegisterContinent adds or updates a continent in the topology. A mesh
+// carrying an invalid link (see domain.ContinentLink.Validate) is refused.
 // This is called by the gossip protocol when new continent data arrives.
 func (tm *TopologyManager) RegisterContinent(mesh *domain.ContinentMesh) error {
 	if !mesh.Continent.IsValid() {
 		return fmt.Errorf("invalid continent: %q", mesh.Continent)
 	}
+	for _, link := range mesh.Links {
+		if err := link.Validate(); err != nil {
+			return err
+		}
+	}
 
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
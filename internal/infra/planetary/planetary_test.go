@@ -1,6 +1,7 @@
 package planetary
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -59,6 +60,20 @@ func TestRegisterContinent_InvalidContinent(t *testing.T) {
 	}
 }
 
+func TestRegisterContinent_InvalidLink(t *testing.T) {
+	tm := NewTopologyManager(DefaultConfig())
+	mesh := &domain.ContinentMesh{
+		Continent: domain.ContinentEurope,
+		Links:     []domain.ContinentLink{{From: domain.ContinentEurope, To: domain.ContinentAsia, LatencyMs: -1}},
+	}
+	if err := tm.RegisterContinent(mesh); !errors.Is(err, domain.ErrInvalidContinentLink) {
+		t.Fatalf("RegisterContinent(negative latency) = %v, want ErrInvalidContinentLink", err)
+	}
+	if tm.ContinentCount() != 0 {
+		t.Error("a refused mesh should not be registered")
+	}
+}
+
 func TestRemoveContinent(t *testing.T) {
 	tm := NewTopologyManager(DefaultConfig())
 	tm.now = fixedTime
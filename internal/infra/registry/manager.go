@@ -1,6 +1,8 @@
 package registry
 
 import (
+	"bufio"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -9,12 +11,15 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
 	"github.com/tutu-network/tutu/internal/infra/catalog"
 	"github.com/tutu-network/tutu/internal/infra/dsa"
+	"github.com/tutu-network/tutu/internal/infra/resource"
 	"github.com/tutu-network/tutu/internal/infra/sqlite"
 )
 
@@ -25,8 +30,18 @@ import (
 type Manager struct {
 	dir         string // Root models directory (contains blobs/ and manifests/)
 	db          *sqlite.DB
-	urlOverride string          // If set, use this base URL instead of HuggingFace (for testing)
-	bloom       *dsa.BloomFilter // DSA: O(1) probabilistic model existence check
+	urlOverride string                 // If set, download from this registry base URL instead of HuggingFace
+	bloom       *dsa.BloomFilter       // DSA: O(1) probabilistic model existence check
+	diskSpace   resource.DiskSpaceFunc // free-space probe for the pre-download check
+
+	verifyMu sync.Mutex
+	verified map[string]blobStamp // blob path → file state when it last hashed clean
+}
+
+// blobStamp identifies a blob file's contents without rehashing it.
+type blobStamp struct {
+	size    int64
+	modTime time.Time
 }
 
 // NewManager creates a Manager rooted at dir.
@@ -34,8 +49,10 @@ type Manager struct {
 // seeded from existing DB entries to avoid cold-start misses.
 func NewManager(dir string, db *sqlite.DB) *Manager {
 	mgr := &Manager{
-		dir: dir,
-		db:  db,
+		dir:       dir,
+		db:        db,
+		diskSpace: resource.FreeDiskSpace,
+		verified:  make(map[string]blobStamp),
 		bloom: dsa.NewBloomFilter(dsa.BloomConfig{
 			ExpectedItems: 500,
 			FPRate:        0.001, // 0.1% false positive rate
@@ -52,8 +69,14 @@ func NewManager(dir string, db *sqlite.DB) *Manager {
 	return mgr
 }
 
+// SetRegistryURL points downloads at a model registry instead of
+// HuggingFace: a model file is fetched from <url>/<file> and, when the
+// registry publishes one, checked against <url>/<file>.sha256. Models not
+// in the built-in catalog are pulled as <url>/<name>.gguf.
+func (m *Manager) SetRegistryURL(url string) { m.urlOverride = strings.TrimSuffix(url, "/") }
+
 // SetTestURL sets a URL override for testing (downloads go to this URL instead of HuggingFace).
-func (m *Manager) SetTestURL(url string) { m.urlOverride = url }
+func (m *Manager) SetTestURL(url string) { m.SetRegistryURL(url) }
 
 // Init ensures the directory structure exists.
 func (m *Manager) Init() error {
@@ -91,6 +114,10 @@ func (m *Manager) ManifestPath(ref domain.ModelRef) string {
 // definitely does not exist (zero false negatives). On "maybe yes",
 // falls through to a DB lookup for confirmation.
 func (m *Manager) HasLocal(ref domain.ModelRef) (bool, error) {
+	ref, err := m.resolveAlias(ref)
+	if err != nil {
+		return false, err
+	}
 	// DSA fast path: Bloom filter O(1) check
 	if !m.bloom.Contains(ref.String()) {
 		return false, nil // Definitely not present
@@ -104,15 +131,24 @@ func (m *Manager) HasLocal(ref domain.ModelRef) (bool, error) {
 }
 
 // Resolve returns the path to the primary weights blob for a model.
-// This is used by the engine pool to load a model.
+// name may be an alias (see AddAlias). This is used by the engine pool
+// to load a model.
 func (m *Manager) Resolve(name string) (string, error) {
-	ref := ParseRef(name)
+	requested := ParseRef(name)
+	ref, err := m.resolveAlias(requested)
+	if err != nil {
+		return "", err
+	}
 
 	info, err := m.db.GetModel(ref.String())
 	if err != nil {
 		return "", fmt.Errorf("query model %s: %w", ref, err)
 	}
 	if info == nil {
+		if ref != requested {
+			return "", fmt.Errorf("alias %s points to %s, which is not installed: %w",
+				requested, ref, domain.ErrModelNotFound)
+		}
 		return "", domain.ErrModelNotFound
 	}
 
@@ -173,9 +209,12 @@ func (m *Manager) Remove(name string) error {
 	return m.db.DeleteModel(ref.String())
 }
 
-// Show returns detailed info about a model.
+// Show returns detailed info about a model. name may be an alias.
 func (m *Manager) Show(name string) (*domain.ModelInfo, error) {
-	ref := ParseRef(name)
+	ref, err := m.resolveAlias(ParseRef(name))
+	if err != nil {
+		return nil, err
+	}
 	info, err := m.db.GetModel(ref.String())
 	if err != nil {
 		return nil, err
@@ -186,10 +225,249 @@ func (m *Manager) Show(name string) (*domain.ModelInfo, error) {
 	return info, nil
 }
 
-// Pull downloads a real GGUF model from HuggingFace.
-// It streams the file to disk with progress reporting and creates
-// the manifest + DB entry once download completes.
-func (m *Manager) Pull(name string, progress func(status string, pct float64)) error {
+// ─── Integrity ──────────────────────────────────────────────────────────────
+
+// VerifyBlob checks that path, the weights file Resolve returned for name,
+// still hashes to the digest recorded in name's manifest when it was pulled.
+// A clean result is cached until the file's size or modification time
+// changes, so each blob is hashed once rather than on every load. Returns
+// an error wrapping domain.ErrModelCorrupted on mismatch; a path that is not
+// a layer of name's manifest has no recorded digest and is not checked.
+func (m *Manager) VerifyBlob(name, path string) error {
+	ref, err := m.resolveAlias(ParseRef(name))
+	if err != nil {
+		return err
+	}
+	manifest, err := m.loadManifest(ref)
+	if err != nil {
+		return err
+	}
+	want := ""
+	for _, layer := range manifest.Layers {
+		if m.BlobPath(layer.Digest) == path {
+			want = strings.TrimPrefix(layer.Digest, "sha256:")
+			break
+		}
+	}
+	if want == "" {
+		return nil
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("blob missing for %s: %w", ref, domain.ErrModelCorrupted)
+	}
+	stamp := blobStamp{size: stat.Size(), modTime: stat.ModTime()}
+	m.verifyMu.Lock()
+	clean := m.verified[path] == stamp
+	m.verifyMu.Unlock()
+	if clean {
+		return nil
+	}
+
+	got, err := hashFile(path)
+	if err != nil {
+		return fmt.Errorf("hash %s: %w", ref, err)
+	}
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("%w: %s has sha256 %s, want %s — run 'tutu rm %s' and pull it again",
+			domain.ErrModelCorrupted, ref, got, want, ref)
+	}
+	m.verifyMu.Lock()
+	m.verified[path] = stamp
+	m.verifyMu.Unlock()
+	return nil
+}
+
+// ─── Quantization Variants ──────────────────────────────────────────────────
+
+// SmallerVariants lists the installed quantizations of name that are smaller
+// than name's own, largest (closest in quality) first, as references Resolve
+// accepts (e.g. "llama3:Q4_K_M"). name may be an alias. A model whose
+// quantization is unknown has no smaller variants.
+func (m *Manager) SmallerVariants(name string) ([]string, error) {
+	ref, err := m.resolveAlias(ParseRef(name))
+	if err != nil {
+		return nil, err
+	}
+	quant := ref.Quant()
+	if quant == "" {
+		info, err := m.db.GetModel(ref.String())
+		if err != nil {
+			return nil, fmt.Errorf("query model %s: %w", ref, err)
+		}
+		if info != nil {
+			quant = info.Quantization
+		}
+	}
+	bits := domain.QuantizationBits(quant)
+	if bits == 0 {
+		return nil, nil
+	}
+
+	installed, err := m.db.ListModels()
+	if err != nil {
+		return nil, err
+	}
+	type variant struct {
+		ref  string
+		bits float64
+	}
+	var smaller []variant
+	for _, info := range installed {
+		r := ParseRef(info.Name)
+		if r.Name != ref.Name {
+			continue
+		}
+		q := r.Quant()
+		if q == "" {
+			q = info.Quantization
+		}
+		if b := domain.QuantizationBits(q); b > 0 && b < bits {
+			smaller = append(smaller, variant{info.Name, b})
+		}
+	}
+	sort.SliceStable(smaller, func(i, j int) bool { return smaller[i].bits > smaller[j].bits })
+
+	refs := make([]string, len(smaller))
+	for i, v := range smaller {
+		refs[i] = v.ref
+	}
+	return refs, nil
+}
+
+// ─── Catalog ────────────────────────────────────────────────────────────────
+
+// Catalog describes the installed models with the hardware each variant
+// needs, so clients and schedulers can match models to capable nodes.
+// Variants of the same name and parameter count are grouped, smallest
+// first. Requirements come from the parameter count and quantization, or
+// from the file size when either is unknown.
+func (m *Manager) Catalog() ([]domain.CatalogModel, error) {
+	installed, err := m.db.ListModels()
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ name, params string }
+	groups := make(map[key]*domain.CatalogModel)
+	var order []key
+	for _, info := range installed {
+		ref := ParseRef(info.Name)
+		quant := ref.Quant()
+		if quant == "" && domain.IsKnownQuantization(info.Quantization) {
+			quant = strings.ToUpper(info.Quantization)
+		}
+		params := info.Parameters
+		if _, err := domain.ParseParameterCount(params); err != nil {
+			params = ""
+		}
+		req, err := domain.EstimateRequirements(params, quant)
+		if err != nil {
+			req = domain.RequirementsForWeights(info.SizeBytes)
+		}
+
+		k := key{ref.Name, params}
+		g, ok := groups[k]
+		if !ok {
+			g = &domain.CatalogModel{Name: ref.Name, Family: info.Family, Parameters: params}
+			groups[k] = g
+			order = append(order, k)
+		}
+		g.Variants = append(g.Variants, domain.CatalogVariant{
+			Name:              info.Name,
+			Quantization:      quant,
+			SizeBytes:         info.SizeBytes,
+			ModelRequirements: req,
+		})
+	}
+
+	models := make([]domain.CatalogModel, 0, len(order))
+	for _, k := range order {
+		g := groups[k]
+		sort.SliceStable(g.Variants, func(i, j int) bool { return g.Variants[i].VRAMBytes < g.Variants[j].VRAMBytes })
+		models = append(models, *g)
+	}
+	sort.SliceStable(models, func(i, j int) bool {
+		if models[i].Name != models[j].Name {
+			return models[i].Name < models[j].Name
+		}
+		return models[i].Variants[0].VRAMBytes < models[j].Variants[0].VRAMBytes
+	})
+	return models, nil
+}
+
+// ─── Aliases ────────────────────────────────────────────────────────────────
+
+// AddAlias maps a friendly name (e.g. "llama3") to an installed model
+// (e.g. "llama-3.2-7b:Q4_K_M"). Aliases are persisted in SQLite and
+// consulted by Resolve, Show, and HasLocal. An alias may not shadow an
+// installed model, and re-adding an alias repoints it.
+func (m *Manager) AddAlias(alias, canonicalRef string) error {
+	if strings.TrimSpace(alias) == "" {
+		return fmt.Errorf("alias name is required")
+	}
+	aliasRef := ParseRef(alias)
+	target := ParseRef(canonicalRef)
+
+	if existing, err := m.db.GetModel(aliasRef.String()); err != nil {
+		return err
+	} else if existing != nil {
+		return fmt.Errorf("alias %s would shadow an installed model", aliasRef)
+	}
+	info, err := m.db.GetModel(target.String())
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		return fmt.Errorf("alias target %s: %w", target, domain.ErrModelNotFound)
+	}
+	return m.db.SetModelAlias(aliasRef.String(), target.String())
+}
+
+// RemoveAlias deletes an alias. The target model is untouched.
+func (m *Manager) RemoveAlias(alias string) error {
+	ok, err := m.db.DeleteModelAlias(ParseRef(alias).String())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("alias %s not found", ParseRef(alias))
+	}
+	return nil
+}
+
+// Aliases returns all aliases as alias → canonical model reference.
+func (m *Manager) Aliases() (map[string]string, error) {
+	return m.db.ListModelAliases()
+}
+
+// resolveAlias returns the canonical ref if ref names an alias, or ref
+// unchanged otherwise. Installed models take precedence over aliases.
+func (m *Manager) resolveAlias(ref domain.ModelRef) (domain.ModelRef, error) {
+	if m.bloom.Contains(ref.String()) {
+		if info, err := m.db.GetModel(ref.String()); err != nil {
+			return ref, err
+		} else if info != nil {
+			return ref, nil
+		}
+	}
+	target, err := m.db.GetModelAlias(ref.String())
+	if err != nil {
+		return ref, fmt.Errorf("lookup alias %s: %w", ref, err)
+	}
+	if target == "" {
+		return ref, nil
+	}
+	return ParseRef(target), nil
+}
+
+// Pull downloads a real GGUF model from HuggingFace or the configured
+// registry. It streams the file to disk with progress reporting, resumes a
+// partial download left by an earlier attempt, verifies the SHA-256 when
+// one is published, and creates the manifest + DB entry once the download
+// completes. Cancelling ctx keeps the partial file for the next attempt.
+func (m *Manager) Pull(ctx context.Context, name string, progress func(status string, pct float64)) error {
 	ref := ParseRef(name)
 
 	if err := m.Init(); err != nil {
@@ -219,7 +497,7 @@ func (m *Manager) Pull(name string, progress func(status string, pct float64)) e
 		entry = catalog.Lookup(ref.Name)
 	}
 	if entry == nil {
-		// Unknown model: if we have a URL override (test mode), create a synthetic entry
+		// Unknown model: a configured registry may still host it
 		if m.urlOverride != "" {
 			entry = &catalog.ModelEntry{
 				Name:         ref.Name,
@@ -257,8 +535,13 @@ func (m *Manager) Pull(name string, progress func(status string, pct float64)) e
 		startByte = stat.Size()
 	}
 
+	expected, err := m.expectedDigest(ctx, entry, url)
+	if err != nil {
+		return err
+	}
+
 	// HTTP request with Range header for resume
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
 	}
@@ -289,6 +572,15 @@ func (m *Manager) Pull(name string, progress func(status string, pct float64)) e
 		totalSize = resp.ContentLength + startByte
 	}
 
+	// Refuse to start if the rest of the file cannot fit on disk
+	remaining := totalSize
+	if resp.StatusCode == http.StatusPartialContent {
+		remaining -= startByte
+	}
+	if err := m.diskSpace.Require(filepath.Dir(tmpPath), uint64(max(remaining, 0))); err != nil {
+		return fmt.Errorf("pull %s: %w", ref, err)
+	}
+
 	// Open file for writing (append if resuming)
 	flags := os.O_CREATE | os.O_WRONLY
 	if startByte > 0 && resp.StatusCode == http.StatusPartialContent {
@@ -343,6 +635,11 @@ func (m *Manager) Pull(name string, progress func(status string, pct float64)) e
 	if progress != nil {
 		progress("verifying download", 99)
 	}
+	if expected != "" && !strings.EqualFold(digest, expected) {
+		// A corrupt partial cannot be resumed — start over next time.
+		os.Remove(tmpPath)
+		return fmt.Errorf("%w: %s has sha256 %s, want %s", domain.ErrModelCorrupted, ref, digest, expected)
+	}
 
 	// Move to final content-addressed location
 	blobPath := m.BlobPath(fullDigest)
@@ -405,6 +702,48 @@ func (m *Manager) Pull(name string, progress func(status string, pct float64)) e
 	return nil
 }
 
+// expectedDigest returns the hex SHA-256 a download must match, or "" if
+// none is known. A catalog entry may pin one; otherwise a configured
+// registry may publish it as a sha256sum-style sidecar at <url>.sha256.
+// A missing or unparseable sidecar means the registry publishes no
+// checksum — the sidecar guards against corruption, not tampering.
+func (m *Manager) expectedDigest(ctx context.Context, entry *catalog.ModelEntry, url string) (string, error) {
+	if entry.SHA256 != "" {
+		return entry.SHA256, nil
+	}
+	if m.urlOverride == "" {
+		return "", nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url+".sha256", nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "TuTu/0.1.0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch checksum: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil
+	}
+
+	// "<hex>  <file>" — the digest is the first field.
+	sc := bufio.NewScanner(io.LimitReader(resp.Body, 4096))
+	if !sc.Scan() {
+		return "", nil
+	}
+	fields := strings.Fields(sc.Text())
+	if len(fields) == 0 {
+		return "", nil
+	}
+	if sum, err := hex.DecodeString(fields[0]); err != nil || len(sum) != sha256.Size {
+		return "", nil
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
 // hashFile computes SHA256 of a file on disk.
 func hashFile(path string) (string, error) {
 	f, err := os.Open(path)
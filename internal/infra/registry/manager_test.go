@@ -1,11 +1,17 @@
 package registry
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
 	"github.com/tutu-network/tutu/internal/infra/sqlite"
@@ -92,7 +98,7 @@ func TestManager_Pull(t *testing.T) {
 
 	var lastStatus string
 	var lastPct float64
-	err := mgr.Pull("llama3", func(status string, pct float64) {
+	err := mgr.Pull(context.Background(), "llama3", func(status string, pct float64) {
 		lastStatus = status
 		lastPct = pct
 	})
@@ -112,13 +118,13 @@ func TestManager_Pull_AlreadyExists(t *testing.T) {
 	mgr := newTestManager(t)
 
 	// Pull once
-	if err := mgr.Pull("llama3", nil); err != nil {
+	if err := mgr.Pull(context.Background(), "llama3", nil); err != nil {
 		t.Fatalf("first Pull() error: %v", err)
 	}
 
 	// Pull again — should be a no-op
 	var gotStatus string
-	err := mgr.Pull("llama3", func(status string, pct float64) {
+	err := mgr.Pull(context.Background(), "llama3", func(status string, pct float64) {
 		gotStatus = status
 	})
 	if err != nil {
@@ -129,6 +135,180 @@ func TestManager_Pull_AlreadyExists(t *testing.T) {
 	}
 }
 
+// registryServer serves a GGUF file at /<name>.gguf (with Range support)
+// and, if sum is non-empty, its checksum sidecar at /<name>.gguf.sha256.
+// Range headers received are appended to ranges.
+func registryServer(t *testing.T, name string, data []byte, sum string, ranges *[]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + name + ".gguf":
+			if rg := r.Header.Get("Range"); rg != "" && ranges != nil {
+				*ranges = append(*ranges, rg)
+			}
+			http.ServeContent(w, r, name+".gguf", time.Time{}, bytes.NewReader(data))
+		case "/" + name + ".gguf.sha256":
+			if sum == "" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprintf(w, "%s  %s.gguf\n", sum, name)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func fakeGGUF() []byte {
+	return append([]byte("GGUF\x03\x00\x00\x00"), bytes.Repeat([]byte("weights-"), 512)...)
+}
+
+func TestManager_Pull_FromRegistry(t *testing.T) {
+	mgr := newTestManager(t)
+	data := fakeGGUF()
+	srv := registryServer(t, "custom-model", data, computeSHA256(data), nil)
+	mgr.SetRegistryURL(srv.URL + "/")
+
+	if err := mgr.Pull(context.Background(), "custom-model", nil); err != nil {
+		t.Fatalf("Pull() error: %v", err)
+	}
+
+	path, err := mgr.Resolve("custom-model")
+	if err != nil {
+		t.Fatalf("Resolve() after pull: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read blob: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("resolved blob does not match the served file")
+	}
+	info, err := mgr.Show("custom-model")
+	if err != nil {
+		t.Fatalf("Show() error: %v", err)
+	}
+	if info.Digest != "sha256:"+computeSHA256(data) {
+		t.Errorf("Digest = %q, want sha256 of served file", info.Digest)
+	}
+}
+
+func TestManager_Pull_ChecksumMismatch(t *testing.T) {
+	mgr := newTestManager(t)
+	srv := registryServer(t, "custom-model", fakeGGUF(), computeSHA256([]byte("something else")), nil)
+	mgr.SetRegistryURL(srv.URL)
+
+	err := mgr.Pull(context.Background(), "custom-model", nil)
+	if !errors.Is(err, domain.ErrModelCorrupted) {
+		t.Fatalf("Pull() error = %v, want ErrModelCorrupted", err)
+	}
+	if ok, _ := mgr.HasLocal(ParseRef("custom-model")); ok {
+		t.Error("corrupt download should not be registered")
+	}
+	tmp := filepath.Join(mgr.dir, "blobs", ".download-custom-model.tmp")
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Errorf("corrupt partial should be removed, stat err = %v", err)
+	}
+}
+
+func TestManager_Pull_Resumes(t *testing.T) {
+	mgr := newTestManager(t)
+	data := fakeGGUF()
+	var ranges []string
+	srv := registryServer(t, "custom-model", data, computeSHA256(data), &ranges)
+	mgr.SetRegistryURL(srv.URL)
+
+	// A previous attempt left the first half on disk.
+	if err := mgr.Init(); err != nil {
+		t.Fatal(err)
+	}
+	half := len(data) / 2
+	tmp := filepath.Join(mgr.dir, "blobs", ".download-custom-model.tmp")
+	if err := os.WriteFile(tmp, data[:half], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mgr.Pull(context.Background(), "custom-model", nil); err != nil {
+		t.Fatalf("Pull() error: %v", err)
+	}
+	if want := fmt.Sprintf("bytes=%d-", half); len(ranges) != 1 || ranges[0] != want {
+		t.Errorf("Range requests = %v, want [%s]", ranges, want)
+	}
+	path, err := mgr.Resolve("custom-model")
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, data) {
+		t.Error("resumed blob does not match the served file")
+	}
+}
+
+func TestManager_Pull_Cancelled(t *testing.T) {
+	mgr := newTestManager(t)
+	srv := registryServer(t, "custom-model", fakeGGUF(), "", nil)
+	mgr.SetRegistryURL(srv.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := mgr.Pull(ctx, "custom-model", nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Pull() error = %v, want context.Canceled", err)
+	}
+	if ok, _ := mgr.HasLocal(ParseRef("custom-model")); ok {
+		t.Error("cancelled pull should not register the model")
+	}
+}
+
+// fixedFree reports the same free space for every path.
+func fixedFree(n uint64) func(string) (uint64, error) {
+	return func(string) (uint64, error) { return n, nil }
+}
+
+func TestManager_Pull_InsufficientDisk(t *testing.T) {
+	mgr := newTestManager(t)
+	data := fakeGGUF()
+	srv := registryServer(t, "custom-model", data, "", nil)
+	mgr.SetRegistryURL(srv.URL)
+	mgr.diskSpace = fixedFree(uint64(len(data)) - 1)
+
+	err := mgr.Pull(context.Background(), "custom-model", nil)
+	if !errors.Is(err, domain.ErrInsufficientDisk) {
+		t.Fatalf("Pull() error = %v, want ErrInsufficientDisk", err)
+	}
+	if !strings.Contains(err.Error(), "need ") || !strings.Contains(err.Error(), "have ") {
+		t.Errorf("error %q should say how much space is needed and available", err)
+	}
+	tmp := filepath.Join(mgr.dir, "blobs", ".download-custom-model.tmp")
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Errorf("no bytes should be written when space is short, stat err = %v", err)
+	}
+	if ok, _ := mgr.HasLocal(ParseRef("custom-model")); ok {
+		t.Error("aborted pull should not register the model")
+	}
+}
+
+func TestManager_Pull_ResumeNeedsOnlyRemainingSpace(t *testing.T) {
+	mgr := newTestManager(t)
+	data := fakeGGUF()
+	srv := registryServer(t, "custom-model", data, "", nil)
+	mgr.SetRegistryURL(srv.URL)
+
+	if err := mgr.Init(); err != nil {
+		t.Fatal(err)
+	}
+	half := len(data) / 2
+	tmp := filepath.Join(mgr.dir, "blobs", ".download-custom-model.tmp")
+	if err := os.WriteFile(tmp, data[:half], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mgr.diskSpace = fixedFree(uint64(len(data) - half))
+
+	if err := mgr.Pull(context.Background(), "custom-model", nil); err != nil {
+		t.Fatalf("Pull() error: %v", err)
+	}
+}
+
 // ─── HasLocal Tests ─────────────────────────────────────────────────────────
 
 func TestManager_HasLocal(t *testing.T) {
@@ -144,7 +324,7 @@ func TestManager_HasLocal(t *testing.T) {
 	}
 
 	// After pull
-	if err := mgr.Pull("llama3", nil); err != nil {
+	if err := mgr.Pull(context.Background(), "llama3", nil); err != nil {
 		t.Fatalf("Pull() error: %v", err)
 	}
 
@@ -162,7 +342,7 @@ func TestManager_HasLocal(t *testing.T) {
 func TestManager_Resolve(t *testing.T) {
 	mgr := newTestManager(t)
 
-	if err := mgr.Pull("llama3", nil); err != nil {
+	if err := mgr.Pull(context.Background(), "llama3", nil); err != nil {
 		t.Fatalf("Pull() error: %v", err)
 	}
 
@@ -185,6 +365,100 @@ func TestManager_Resolve_NotFound(t *testing.T) {
 	}
 }
 
+// ─── Alias Tests ────────────────────────────────────────────────────────────
+
+func TestManager_Alias_Resolves(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Pull(context.Background(), "llama3", nil); err != nil {
+		t.Fatalf("Pull() error: %v", err)
+	}
+
+	if err := mgr.AddAlias("my-llm", "llama3"); err != nil {
+		t.Fatalf("AddAlias() error: %v", err)
+	}
+
+	want, _ := mgr.Resolve("llama3")
+	got, err := mgr.Resolve("my-llm")
+	if err != nil {
+		t.Fatalf("Resolve(alias) error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Resolve(alias) = %q, want %q", got, want)
+	}
+
+	info, err := mgr.Show("my-llm")
+	if err != nil || info.Name != "llama3" {
+		t.Errorf("Show(alias) = %+v, %v; want llama3", info, err)
+	}
+	if ok, _ := mgr.HasLocal(ParseRef("my-llm")); !ok {
+		t.Error("HasLocal(alias) = false, want true")
+	}
+
+	// Aliases persist: a fresh manager on the same DB still resolves them.
+	fresh := NewManager(mgr.dir, mgr.db)
+	if got, err := fresh.Resolve("my-llm"); err != nil || got != want {
+		t.Errorf("fresh Resolve(alias) = %q, %v; want %q", got, err, want)
+	}
+}
+
+func TestManager_Alias_UnknownTarget(t *testing.T) {
+	mgr := newTestManager(t)
+
+	err := mgr.AddAlias("my-llm", "not-installed")
+	if !errors.Is(err, domain.ErrModelNotFound) {
+		t.Errorf("AddAlias(unknown target) = %v, want ErrModelNotFound", err)
+	}
+}
+
+func TestManager_Alias_Dangling(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Pull(context.Background(), "llama3", nil); err != nil {
+		t.Fatalf("Pull() error: %v", err)
+	}
+	if err := mgr.AddAlias("my-llm", "llama3"); err != nil {
+		t.Fatalf("AddAlias() error: %v", err)
+	}
+	if err := mgr.Remove("llama3"); err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+
+	_, err := mgr.Resolve("my-llm")
+	if !errors.Is(err, domain.ErrModelNotFound) {
+		t.Fatalf("Resolve(dangling alias) = %v, want ErrModelNotFound", err)
+	}
+	if !strings.Contains(err.Error(), "alias my-llm points to llama3") {
+		t.Errorf("error should name the alias and its target, got: %v", err)
+	}
+}
+
+func TestManager_Alias_CannotShadowModel(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Pull(context.Background(), "llama3", nil); err != nil {
+		t.Fatalf("Pull() error: %v", err)
+	}
+	if err := mgr.AddAlias("llama3", "llama3"); err == nil {
+		t.Error("expected error when alias shadows an installed model")
+	}
+}
+
+func TestManager_RemoveAlias(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Pull(context.Background(), "llama3", nil); err != nil {
+		t.Fatalf("Pull() error: %v", err)
+	}
+	_ = mgr.AddAlias("my-llm", "llama3")
+
+	if err := mgr.RemoveAlias("my-llm"); err != nil {
+		t.Fatalf("RemoveAlias() error: %v", err)
+	}
+	if _, err := mgr.Resolve("my-llm"); err != domain.ErrModelNotFound {
+		t.Errorf("Resolve(removed alias) = %v, want ErrModelNotFound", err)
+	}
+	if err := mgr.RemoveAlias("my-llm"); err == nil {
+		t.Error("expected error removing unknown alias")
+	}
+}
+
 // ─── List Tests ─────────────────────────────────────────────────────────────
 
 func TestManager_List(t *testing.T) {
@@ -201,7 +475,7 @@ func TestManager_List(t *testing.T) {
 
 	// After pulls
 	for _, name := range []string{"llama3", "mistral", "phi3"} {
-		if err := mgr.Pull(name, nil); err != nil {
+		if err := mgr.Pull(context.Background(), name, nil); err != nil {
 			t.Fatalf("Pull(%s) error: %v", name, err)
 		}
 	}
@@ -220,7 +494,7 @@ func TestManager_List(t *testing.T) {
 func TestManager_Show(t *testing.T) {
 	mgr := newTestManager(t)
 
-	if err := mgr.Pull("llama3", nil); err != nil {
+	if err := mgr.Pull(context.Background(), "llama3", nil); err != nil {
 		t.Fatalf("Pull() error: %v", err)
 	}
 
@@ -251,7 +525,7 @@ func TestManager_Show_NotFound(t *testing.T) {
 func TestManager_Remove(t *testing.T) {
 	mgr := newTestManager(t)
 
-	if err := mgr.Pull("llama3", nil); err != nil {
+	if err := mgr.Pull(context.Background(), "llama3", nil); err != nil {
 		t.Fatalf("Pull() error: %v", err)
 	}
 
@@ -325,3 +599,113 @@ func TestManager_ManifestPath_DefaultTag(t *testing.T) {
 		t.Errorf("ManifestPath() = %q, want %q", got, want)
 	}
 }
+
+// ─── Quantization Variant Tests ─────────────────────────────────────────────
+
+func TestManager_SmallerVariants(t *testing.T) {
+	mgr := newTestManager(t)
+	for _, name := range []string{"custom-model:Q2_K", "custom-model:Q8_0", "custom-model:Q4_K_M", "other-model:Q4_0"} {
+		if err := mgr.Pull(context.Background(), name, nil); err != nil {
+			t.Fatalf("Pull(%s) error: %v", name, err)
+		}
+	}
+
+	got, err := mgr.SmallerVariants("custom-model:Q8_0")
+	if err != nil {
+		t.Fatalf("SmallerVariants() error: %v", err)
+	}
+	want := []string{"custom-model:Q4_K_M", "custom-model:Q2_K"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("SmallerVariants(Q8_0) = %v, want %v", got, want)
+	}
+	for _, ref := range got {
+		if _, err := mgr.Resolve(ref); err != nil {
+			t.Errorf("Resolve(%s) error: %v", ref, err)
+		}
+	}
+
+	if got, _ := mgr.SmallerVariants("custom-model:Q2_K"); len(got) != 0 {
+		t.Errorf("SmallerVariants(Q2_K) = %v, want none", got)
+	}
+	if got, _ := mgr.SmallerVariants("custom-model"); len(got) != 0 {
+		t.Errorf("SmallerVariants(untagged, unknown quant) = %v, want none", got)
+	}
+}
+
+// ─── Catalog Tests ──────────────────────────────────────────────────────────
+
+func TestManager_Catalog(t *testing.T) {
+	mgr := newTestManager(t)
+	for _, info := range []domain.ModelInfo{
+		{Name: "llama-70b:Q4_K_M", Family: "llama", Parameters: "70B", Quantization: "Q4_K_M", Format: "gguf"},
+		{Name: "llama-1b:Q8_0", Family: "llama", Parameters: "1B", Quantization: "Q8_0", Format: "gguf"},
+		{Name: "llama-1b:Q4_K_M", Family: "llama", Parameters: "1B", Quantization: "Q4_K_M", Format: "gguf"},
+		{Name: "custom", Parameters: "unknown", Quantization: "unknown", Format: "gguf", SizeBytes: 2 << 30},
+	} {
+		if err := mgr.db.UpsertModel(info); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	models, err := mgr.Catalog()
+	if err != nil {
+		t.Fatalf("Catalog() error: %v", err)
+	}
+	byName := make(map[string]domain.CatalogModel)
+	for _, m := range models {
+		byName[m.Name] = m
+	}
+	if len(byName) != 3 {
+		t.Fatalf("Catalog() = %+v, want custom, llama-1b and llama-70b", models)
+	}
+
+	small := byName["llama-1b"]
+	if len(small.Variants) != 2 || small.Variants[0].Quantization != "Q4_K_M" {
+		t.Fatalf("llama-1b variants = %+v, want Q4_K_M then Q8_0", small.Variants)
+	}
+	q8, q4of70 := small.Variants[1], byName["llama-70b"].Variants[0]
+	if q4of70.VRAMBytes <= q8.VRAMBytes || q4of70.MinRAMBytes <= q8.MinRAMBytes {
+		t.Errorf("70B Q4 needs %d VRAM / %d RAM, want more than 1B Q8's %d / %d",
+			q4of70.VRAMBytes, q4of70.MinRAMBytes, q8.VRAMBytes, q8.MinRAMBytes)
+	}
+	if q4of70.RecommendedTier != domain.HardwareTierHigh || q8.RecommendedTier != domain.HardwareTierLow {
+		t.Errorf("tiers = %s (70B Q4), %s (1B Q8); want high, low", q4of70.RecommendedTier, q8.RecommendedTier)
+	}
+
+	// Without a parameter count, requirements come from the file size.
+	if got := byName["custom"].Variants[0]; got.VRAMBytes <= 2<<30 {
+		t.Errorf("custom VRAM = %d, want more than its 2 GiB of weights", got.VRAMBytes)
+	}
+}
+
+// ─── Integrity Tests ────────────────────────────────────────────────────────
+
+func TestManager_VerifyBlob(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Pull(context.Background(), "llama3", nil); err != nil {
+		t.Fatalf("Pull() error: %v", err)
+	}
+	path, err := mgr.Resolve("llama3")
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+
+	if err := mgr.VerifyBlob("llama3", path); err != nil {
+		t.Fatalf("VerifyBlob(intact) = %v, want nil", err)
+	}
+
+	if err := os.WriteFile(path, []byte("GGUF-bit-rot"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err = mgr.VerifyBlob("llama3", path)
+	if !errors.Is(err, domain.ErrModelCorrupted) {
+		t.Fatalf("VerifyBlob(corrupted) = %v, want ErrModelCorrupted", err)
+	}
+	if !strings.Contains(err.Error(), "tutu rm llama3") {
+		t.Errorf("error %q should tell the user how to recover", err)
+	}
+
+	if err := mgr.VerifyBlob("llama3", filepath.Join(t.TempDir(), "other.gguf")); err != nil {
+		t.Errorf("VerifyBlob(path outside the manifest) = %v, want nil", err)
+	}
+}
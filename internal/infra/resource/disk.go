@@ -0,0 +1,35 @@
+package resource
+
+import (
+	"fmt"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// DiskSpaceFunc reports the bytes available to this user on the
+// filesystem holding path. Downloaders take one so tests can fake it.
+type DiskSpaceFunc func(path string) (uint64, error)
+
+// FreeDiskSpace is the DiskSpaceFunc backed by the operating system.
+func FreeDiskSpace(path string) (uint64, error) {
+	return freeDiskSpace(path)
+}
+
+// Require returns an error wrapping domain.ErrInsufficientDisk if fewer
+// than need bytes are free under path. If free space cannot be determined
+// the check passes: a failed probe should not block a download that would
+// likely succeed.
+func (f DiskSpaceFunc) Require(path string, need uint64) error {
+	if f == nil || need == 0 {
+		return nil
+	}
+	free, err := f(path)
+	if err != nil {
+		return nil
+	}
+	if free < need {
+		return fmt.Errorf("%w: need %s, have %s", domain.ErrInsufficientDisk,
+			domain.HumanSize(int64(need)), domain.HumanSize(int64(free)))
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package resource
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/tutu-network/tutu/internal/domain"
@@ -130,3 +131,35 @@ func TestIdleDetector_Update(t *testing.T) {
 	// Just verify Update doesn't panic
 	d.Update()
 }
+
+// ─── Disk Space Tests ───────────────────────────────────────────────────────
+
+func TestDiskSpaceFunc_Require(t *testing.T) {
+	free := DiskSpaceFunc(func(string) (uint64, error) { return 300 << 20, nil })
+
+	if err := free.Require("/", 200<<20); err != nil {
+		t.Errorf("Require(200MB) with 300MB free = %v, want nil", err)
+	}
+	err := free.Require("/", 1<<30)
+	if !errors.Is(err, domain.ErrInsufficientDisk) {
+		t.Fatalf("Require(1GB) with 300MB free = %v, want ErrInsufficientDisk", err)
+	}
+	if want := "insufficient disk space: need 1.0 GB, have 300.0 MB"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+
+	failing := DiskSpaceFunc(func(string) (uint64, error) { return 0, errors.New("statfs failed") })
+	if err := failing.Require("/", 1<<30); err != nil {
+		t.Errorf("Require() with an unreadable filesystem = %v, want nil", err)
+	}
+}
+
+func TestFreeDiskSpace(t *testing.T) {
+	free, err := FreeDiskSpace(t.TempDir())
+	if err != nil {
+		t.Fatalf("FreeDiskSpace() error: %v", err)
+	}
+	if free == 0 {
+		t.Error("FreeDiskSpace() = 0, want the temp dir's free space")
+	}
+}
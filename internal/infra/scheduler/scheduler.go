@@ -7,16 +7,24 @@
 //   - Back-Pressure: tiered rejection at queue depths 1K/5K/10K
 //   - Preemption: realtime tasks can preempt spot tasks
 //   - Scored Matching: O(K) weighted scoring across candidates after filter
+//   - Persistence: optional SQLite-backed queue that survives restarts
 package scheduler
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
 	"math"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/sqlite"
 )
 
 // ─── Configuration ──────────────────────────────────────────────────────────
@@ -30,6 +38,40 @@ type Config struct {
 	StealBatchSize     int           // how many tasks to steal at once (default: half of peer's queue)
 	StarvationInterval time.Duration // boost priority every N (default 60s)
 	PreemptionEnabled  bool          // allow realtime to preempt spot (default true)
+	RealtimeReserve    float64       // fraction of the queue below BackPressureMedium held for P0 realtime (default 0.10)
+	Bands              int           // number of priority bands; band 0 is realtime, the last is spot (default 5)
+	Concurrency        int           // tasks executed in parallel, used by EstimateWait (default 1)
+	TieBreak           TieBreak      // order among tasks of equal effective priority (default FIFO)
+	MaxRequeues        int           // failed runs re-queued before a task is dead-lettered (default 5)
+
+	// DispatchTimeout is how long a dequeued task may go without being
+	// completed or re-queued before Run stops tracking it: it no longer
+	// counts as running and its spans end as timed out. Its persisted
+	// record stays, so recovery re-queues it. 0 disables it (default 1h).
+	DispatchTimeout time.Duration
+
+	// SLATargets is the queue-wait target per SLA tier, measured from
+	// enqueue to dequeue; the daemon sets each to the tier's MaxLatencyP99.
+	// Tiers without a target are not reported (default: none).
+	SLATargets map[domain.SLATier]time.Duration
+
+	// ReputationBoost is the number of priority classes a task gains when
+	// its submitter's reputation is at least ReputationBoostMin. The boost
+	// never lifts a task into the realtime band. 0 disables it (default).
+	ReputationBoost    int
+	ReputationBoostMin float64 // reputation score (0.0–1.0) that earns the boost (default 0.8)
+
+	// MaxTaskAge caps how long any task waits in queue, independent of
+	// deadlines and starvation aging. Tasks queued longer are handled per
+	// MaxAgePolicy. 0 disables the cap (default).
+	MaxTaskAge   time.Duration
+	MaxAgePolicy MaxAgePolicy // what happens to a task past MaxTaskAge (default MaxAgePromote)
+
+	// TierSlots is the most tasks of each SLA tier that may be queued or
+	// running at once before HasCapacity reports the tier full, so callers
+	// can move work to a tier with room. Tiers without a budget are limited
+	// only by back-pressure (default: none).
+	TierSlots map[domain.SLATier]int
 }
 
 // DefaultConfig returns production scheduler defaults.
@@ -42,6 +84,12 @@ func DefaultConfig() Config {
 		StealBatchSize:     0, // 0 means "half of peer's queue"
 		StarvationInterval: 60 * time.Second,
 		PreemptionEnabled:  true,
+		RealtimeReserve:    0.10,
+		Bands:              DefaultBands,
+		Concurrency:        1,
+		MaxRequeues:        5,
+		DispatchTimeout:    time.Hour,
+		ReputationBoostMin: 0.8,
 	}
 }
 
@@ -55,24 +103,139 @@ const (
 	P4Spot     = 4 // Best-effort / spot pricing
 )
 
-// PriorityLabel returns a human-readable label for a priority class.
+// DefaultBands is the number of priority bands in the classic P0–P4 layout.
+const DefaultBands = 5
+
+// PriorityLabel returns a human-readable label for a priority class in the
+// default five-band layout.
 func PriorityLabel(p int) string {
-	switch p {
-	case P0Realtime:
+	return BandLabel(p, DefaultBands)
+}
+
+// BandLabel returns a human-readable label for band p of a scheduler with
+// the given number of bands. The first band is REALTIME and the last SPOT;
+// the five-band layout names the rest HIGH/NORMAL/LOW, other layouts
+// number them (P1, P2, …).
+func BandLabel(p, bands int) string {
+	switch {
+	case p < 0 || p >= bands:
+		return "UNKNOWN"
+	case p == P0Realtime:
 		return "REALTIME"
-	case P1High:
-		return "HIGH"
-	case P2Normal:
-		return "NORMAL"
-	case P3Low:
-		return "LOW"
-	case P4Spot:
+	case p == bands-1:
 		return "SPOT"
+	case bands == DefaultBands:
+		return [...]string{P1High: "HIGH", P2Normal: "NORMAL", P3Low: "LOW"}[p]
+	default:
+		return fmt.Sprintf("P%d", p)
+	}
+}
+
+// PriorityTier returns the SLA tier a priority class is served under in the
+// default five-band layout.
+func PriorityTier(p int) domain.SLATier {
+	return BandTier(p, DefaultBands)
+}
+
+// BandTier returns the SLA tier band p is served under: the first band is
+// realtime, the last spot, the band above spot batch (when there are at
+// least two middle bands), and the rest standard — so regular user tasks
+// (P2 of five) share the standard tier's latency target.
+func BandTier(p, bands int) domain.SLATier {
+	p = clampBand(p, bands)
+	switch {
+	case p == P0Realtime:
+		return domain.SLARealtime
+	case p == bands-1:
+		return domain.SLASpot
+	case p == bands-2 && bands > 3:
+		return domain.SLABatch
+	default:
+		return domain.SLAStandard
+	}
+}
+
+// ─── Tie-Breaking ───────────────────────────────────────────────────────────
+
+// TieBreak selects which of several tasks with the same effective priority
+// is dequeued first. Tasks that tie under the policy fall back to FIFO.
+type TieBreak int
+
+const (
+	TieBreakFIFO     TieBreak = iota // earliest enqueued first — fairness
+	TieBreakCheapest                 // smallest Task.EstimatedCost first — throughput
+	TieBreakDeadline                 // earliest Task.Deadline first
+)
+
+// String returns a human-readable tie-break policy.
+func (tb TieBreak) String() string {
+	switch tb {
+	case TieBreakFIFO:
+		return "FIFO"
+	case TieBreakCheapest:
+		return "CHEAPEST"
+	case TieBreakDeadline:
+		return "DEADLINE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// precedes reports whether a should be dequeued before b when both have
+// the same effective priority. Tasks without a cost estimate or deadline
+// sort after those with one.
+func (tb TieBreak) precedes(a, b QueuedTask) bool {
+	switch tb {
+	case TieBreakCheapest:
+		ac, bc := a.Task.EstimatedCost, b.Task.EstimatedCost
+		if ac != bc {
+			return bc == 0 || (ac != 0 && ac < bc)
+		}
+	case TieBreakDeadline:
+		ad, bd := a.Task.Deadline, b.Task.Deadline
+		if !ad.Equal(bd) {
+			return bd.IsZero() || (!ad.IsZero() && ad.Before(bd))
+		}
+	}
+	return a.QueuedAt.Before(b.QueuedAt)
+}
+
+// ─── Maximum Task Age ───────────────────────────────────────────────────────
+
+// MaxAgePolicy selects what happens to a task that has waited longer than
+// Config.MaxTaskAge.
+type MaxAgePolicy int
+
+const (
+	MaxAgePromote MaxAgePolicy = iota // force it into the top band, so it runs next
+	MaxAgeDrop                        // move it to the dead letters unrun, failed with ErrTaskAgedOut
+)
+
+// String returns a human-readable max-age policy.
+func (p MaxAgePolicy) String() string {
+	switch p {
+	case MaxAgePromote:
+		return "PROMOTE"
+	case MaxAgeDrop:
+		return "DROP"
 	default:
 		return "UNKNOWN"
 	}
 }
 
+// ParseMaxAgePolicy parses a max-age policy name ("promote" or "drop",
+// case-insensitive). An empty name is MaxAgePromote.
+func ParseMaxAgePolicy(name string) (MaxAgePolicy, error) {
+	switch strings.ToLower(name) {
+	case "", "promote":
+		return MaxAgePromote, nil
+	case "drop":
+		return MaxAgeDrop, nil
+	default:
+		return MaxAgePromote, fmt.Errorf("invalid max age policy %q: want promote or drop", name)
+	}
+}
+
 // ─── Queued Task ────────────────────────────────────────────────────────────
 
 // QueuedTask wraps a domain.Task with scheduling metadata.
@@ -83,17 +246,32 @@ type QueuedTask struct {
 }
 
 // EffectivePriority applies starvation-prevention age boost.
-// Every starvationInterval in queue, priority improves by 1 class.
+// Every starvationInterval in queue, priority improves by 1 class; a
+// non-positive interval disables aging. Re-queued tasks first sink by
+// RequeuePenalty classes.
 func (qt QueuedTask) EffectivePriority(starvationInterval time.Duration) int {
-	age := time.Since(qt.QueuedAt)
-	boost := int(age / starvationInterval)
-	effective := qt.Task.Priority - boost
+	boost := 0
+	if starvationInterval > 0 {
+		boost = int(time.Since(qt.QueuedAt) / starvationInterval)
+	}
+	effective := qt.Task.Priority + RequeuePenalty(qt.Task.Requeues) - boost
 	if effective < 0 {
 		effective = 0
 	}
 	return effective
 }
 
+// RequeuePenalty is the number of priority classes a task loses after
+// requeues failed runs: 2^requeues - 1, so 0, 1, 3, 7, ... A repeatedly
+// failing task sinks below fresh work, but the starvation boost still
+// brings it back eventually.
+func RequeuePenalty(requeues int) int {
+	if requeues <= 0 {
+		return 0
+	}
+	return 1<<min(requeues, 16) - 1
+}
+
 // ─── Back-Pressure Levels ───────────────────────────────────────────────────
 
 // BackPressureLevel indicates load severity.
@@ -130,8 +308,39 @@ type Scheduler struct {
 	mu     sync.Mutex
 	config Config
 
-	// Priority queues — one per priority class (P0–P4)
-	queues [5][]QueuedTask
+	// Priority queues — one per band (P0–P4 by default)
+	queues [][]QueuedTask
+
+	// Optional persistence — nil means in-memory only (set by Recover)
+	db *sqlite.DB
+
+	// Hard eligibility filter applied by RankNodes — nil allows every node
+	nodeFilter NodeFilter
+
+	// Submitter reputation for ReputationBoost — nil boosts no task
+	reputation ReputationSource
+
+	// This node's ID, stamped on enqueued tasks without a SubmitterID
+	localNode string
+
+	// Dequeued tasks awaiting completion, and the subset of those that
+	// were cancelled before the executor started them.
+	dispatched map[string]dispatchedTask
+	cancelled  map[string]bool
+
+	// Tasks that exceeded MaxRequeues or were dropped past MaxTaskAge,
+	// oldest first
+	deadLetters []QueuedTask
+
+	// Moving average of dispatch-to-completion time, for EstimateWait
+	avgCompletion time.Duration
+
+	// Queue-wait histograms per SLA tier, recorded at dequeue
+	waits map[domain.SLATier]*WaitHistogram
+
+	// Optional tracer and the open spans of traced tasks
+	tracer TaskTracer
+	spans  map[string]*taskSpans
 
 	// Stats
 	totalEnqueued  atomic.Int64
@@ -139,21 +348,161 @@ type Scheduler struct {
 	totalRejected  atomic.Int64
 	totalStolen    atomic.Int64
 	totalPreempted atomic.Int64
+	totalCancelled atomic.Int64
+	totalTimedOut  atomic.Int64
+
+	// Tasks past Config.MaxTaskAge, by policy
+	totalAgeForced  atomic.Int64
+	totalAgeDropped atomic.Int64
 }
 
 // NewScheduler creates a new advanced scheduler.
 func NewScheduler(cfg Config) *Scheduler {
-	return &Scheduler{config: cfg}
+	switch {
+	case cfg.Bands <= 0:
+		cfg.Bands = DefaultBands
+	case cfg.Bands < 2:
+		cfg.Bands = 2 // realtime and spot at minimum
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.MaxRequeues <= 0 {
+		cfg.MaxRequeues = 5
+	}
+	return &Scheduler{
+		config:     cfg,
+		queues:     make([][]QueuedTask, cfg.Bands),
+		dispatched: make(map[string]dispatchedTask),
+		cancelled:  make(map[string]bool),
+		waits:      make(map[domain.SLATier]*WaitHistogram),
+		spans:      make(map[string]*taskSpans),
+	}
+}
+
+// Bands returns the number of priority bands.
+func (s *Scheduler) Bands() int { return s.config.Bands }
+
+// PriorityLabel returns the label of band p in this scheduler's layout.
+func (s *Scheduler) PriorityLabel(p int) string {
+	return BandLabel(p, s.config.Bands)
+}
+
+// SetNodeFilter installs a hard eligibility filter applied whenever this
+// scheduler ranks nodes.
+func (s *Scheduler) SetNodeFilter(f NodeFilter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodeFilter = f
+}
+
+// RankNodes ranks candidates like the package-level RankNodes, applying the
+// scheduler's node filter.
+func (s *Scheduler) RankNodes(candidates []NodeCandidate, task domain.Task, taskRegion domain.RegionID) []NodeCandidate {
+	s.mu.Lock()
+	f := s.nodeFilter
+	s.mu.Unlock()
+	if f == nil {
+		return RankNodes(candidates, task, taskRegion)
+	}
+	return RankNodes(candidates, task, taskRegion, f)
+}
+
+// StealEligibility returns a StealableTasks predicate for a thief node: a
+// task is eligible only if its routing allows the node and the scheduler's
+// node filter (e.g. federation isolation) accepts it.
+func (s *Scheduler) StealEligibility(nodeID string) func(QueuedTask) bool {
+	s.mu.Lock()
+	f := s.nodeFilter
+	s.mu.Unlock()
+	return func(qt QueuedTask) bool {
+		if !qt.Routing.AllowsNode(nodeID) {
+			return false
+		}
+		return f == nil || f(qt.Task, nodeID)
+	}
+}
+
+// ReputationSource returns a node's current reputation score (0.0–1.0).
+// ok is false if the node is unknown.
+type ReputationSource func(nodeID string) (score float64, ok bool)
+
+// SetReputationSource injects the submitter reputation used by
+// Config.ReputationBoost. It runs while the scheduler is locked and must
+// not call back into the scheduler.
+func (s *Scheduler) SetReputationSource(src ReputationSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reputation = src
+}
+
+// SetLocalNode sets this node's ID. Enqueue records it as the submitter of
+// tasks that arrive without a SubmitterID, so locally submitted work is
+// boosted by this node's own reputation. Tasks from other nodes enter
+// through EnqueueRemote and keep their own submitter.
+func (s *Scheduler) SetLocalNode(nodeID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.localNode = nodeID
+}
+
+// reputationBoosterLocked returns a function giving the priority classes a
+// task at effective priority eff gains from its submitter's reputation,
+// capped so that aging and boost together never lift it into the realtime
+// band. Each submitter's score is looked up once per booster, so a Dequeue
+// scan calls the ReputationSource once per submitter rather than once per
+// queued task.
+func (s *Scheduler) reputationBoosterLocked() func(qt QueuedTask, eff int) int {
+	if s.config.ReputationBoost <= 0 || s.reputation == nil {
+		return func(QueuedTask, int) int { return 0 }
+	}
+	eligible := make(map[string]bool)
+	return func(qt QueuedTask, eff int) int {
+		id := qt.Task.SubmitterID
+		if id == "" {
+			return 0
+		}
+		ok, seen := eligible[id]
+		if !seen {
+			score, known := s.reputation(id)
+			ok = known && score >= s.config.ReputationBoostMin
+			eligible[id] = ok
+		}
+		if !ok {
+			return 0
+		}
+		return max(0, min(s.config.ReputationBoost, eff-P1High))
+	}
 }
 
 // ─── Enqueue ────────────────────────────────────────────────────────────────
 
-// Enqueue adds a task to the appropriate priority queue.
+// Enqueue adds a locally submitted task to the appropriate priority queue.
 // Returns an error if back-pressure rejects the task.
 func (s *Scheduler) Enqueue(task domain.Task, routing domain.TaskRouting) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if task.SubmitterID == "" {
+		task.SubmitterID = s.localNode
+	}
+	return s.enqueueLocked(task, routing)
+}
+
+// EnqueueRemote adds a task assigned by the network. The task keeps the
+// SubmitterID it arrived with — never this node's — so any reputation
+// boost follows the node that actually submitted it; a task without one
+// earns no boost. Back-pressure applies as for Enqueue.
+func (s *Scheduler) EnqueueRemote(task domain.Task, routing domain.TaskRouting) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enqueueLocked(task, routing)
+}
+
+// enqueueLocked applies back-pressure admission and queues task. Caller
+// must hold s.mu.
+func (s *Scheduler) enqueueLocked(task domain.Task, routing domain.TaskRouting) error {
+	s.dropAgedLocked(time.Now())
 	depth := s.queueDepthLocked()
 	bp := s.backPressureLevelLocked(depth)
 
@@ -168,29 +517,32 @@ func (s *Scheduler) Enqueue(task domain.Task, routing domain.TaskRouting) error
 			return domain.ErrBackPressureMedium
 		}
 	case BPSoft:
-		if task.Priority >= P4Spot {
+		if task.Priority >= s.spotBand() {
 			s.totalRejected.Add(1)
 			return domain.ErrBackPressureSoft
 		}
 	}
 
+	// Lower tiers may not eat into the slots held back for realtime.
+	if task.Priority > P0Realtime && depth >= s.reserveThreshold() {
+		s.totalRejected.Add(1)
+		return domain.ErrRealtimeReserved
+	}
+
 	qt := QueuedTask{
 		Task:     task,
 		QueuedAt: time.Now(),
 		Routing:  routing,
 	}
 
-	// Clamp priority to valid range [0, 4]
-	pClass := task.Priority
-	if pClass < 0 {
-		pClass = 0
-	}
-	if pClass > 4 {
-		pClass = 4
+	if err := s.persistLocked(qt); err != nil {
+		return err
 	}
 
+	pClass := s.band(task.Priority)
 	s.queues[pClass] = append(s.queues[pClass], qt)
 	s.totalEnqueued.Add(1)
+	s.startSpansLocked(qt)
 	return nil
 }
 
@@ -199,20 +551,32 @@ func (s *Scheduler) Enqueue(task domain.Task, routing domain.TaskRouting) error
 // Dequeue removes and returns the highest-priority task.
 // Returns nil if all queues are empty.
 // Uses starvation prevention: tasks waiting longer get priority boosts.
+// Tasks from high-reputation submitters gain Config.ReputationBoost.
+// Tasks older than Config.MaxTaskAge are promoted to the top band or
+// dropped first, per Config.MaxAgePolicy.
 func (s *Scheduler) Dequeue() *QueuedTask {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Scan from highest priority (P0) to lowest (P4).
-	// Within each queue, find the task with the best effective priority.
+	now := time.Now()
+	s.dropAgedLocked(now)
+
+	// Scan from highest priority (P0) to lowest (P4) for the task with the
+	// best effective priority; ties go to the configured TieBreak policy.
 	var bestIdx int = -1
 	var bestQueue int = -1
 	var bestEffective int = math.MaxInt
 
-	for q := 0; q < 5; q++ {
+	boost := s.reputationBoosterLocked()
+	for q := range s.queues {
 		for i, qt := range s.queues[q] {
 			eff := qt.EffectivePriority(s.config.StarvationInterval)
-			if eff < bestEffective {
+			eff -= boost(qt, eff)
+			if s.agedOut(qt, now) {
+				eff = P0Realtime
+			}
+			if eff < bestEffective ||
+				(eff == bestEffective && s.config.TieBreak.precedes(qt, s.queues[bestQueue][bestIdx])) {
 				bestEffective = eff
 				bestIdx = i
 				bestQueue = q
@@ -224,15 +588,243 @@ func (s *Scheduler) Dequeue() *QueuedTask {
 		return nil // all empty
 	}
 
+	// Remove preserving order, so equal-time FIFO ties keep queue order.
 	qt := s.queues[bestQueue][bestIdx]
-	// Remove from queue (swap with last for O(1))
-	last := len(s.queues[bestQueue]) - 1
-	s.queues[bestQueue][bestIdx] = s.queues[bestQueue][last]
-	s.queues[bestQueue] = s.queues[bestQueue][:last]
+	s.queues[bestQueue] = append(s.queues[bestQueue][:bestIdx], s.queues[bestQueue][bestIdx+1:]...)
+
+	if s.db != nil {
+		// Best-effort: on failure the task is simply re-queued on recovery.
+		_ = s.db.MarkQueuedTaskInProgress(qt.Task.ID)
+	}
+	if s.agedOut(qt, now) {
+		s.totalAgeForced.Add(1)
+	}
+	s.dispatched[qt.Task.ID] = dispatchedTask{at: now, tier: BandTier(bestQueue, s.config.Bands)}
+	s.recordWaitLocked(qt, now)
+	s.endWaitSpanLocked(qt.Task.ID)
 
 	return &qt
 }
 
+// agedOut reports whether qt has waited longer than Config.MaxTaskAge.
+func (s *Scheduler) agedOut(qt QueuedTask, now time.Time) bool {
+	return s.config.MaxTaskAge > 0 && now.Sub(qt.QueuedAt) > s.config.MaxTaskAge
+}
+
+// dropAgedLocked moves every task older than Config.MaxTaskAge to the dead
+// letters when the policy is MaxAgeDrop. It runs before the queue is read
+// or admits a task, so dropped tasks never count toward back-pressure.
+// Caller must hold s.mu.
+func (s *Scheduler) dropAgedLocked(now time.Time) {
+	if s.config.MaxAgePolicy != MaxAgeDrop {
+		return
+	}
+	for q := range s.queues {
+		kept := s.queues[q][:0]
+		for _, qt := range s.queues[q] {
+			if !s.agedOut(qt, now) {
+				kept = append(kept, qt)
+				continue
+			}
+			qt.Task.Error = domain.ErrTaskAgedOut.Error()
+			s.deadLetters = append(s.deadLetters, qt)
+			s.dropPersistedLocked(qt.Task.ID)
+			s.endSpansLocked(qt.Task.ID, "aged_out", domain.ErrTaskAgedOut)
+			s.totalAgeDropped.Add(1)
+		}
+		s.queues[q] = kept
+	}
+}
+
+// ─── SLA Compliance ─────────────────────────────────────────────────────────
+
+// WaitBuckets are the upper bounds of the queue-wait histogram buckets. A
+// final overflow bucket counts waits above the last bound.
+var WaitBuckets = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+// WaitHistogram records how long tasks of one SLA tier waited in queue.
+type WaitHistogram struct {
+	Target time.Duration `json:"target"`   // tier's latency target (0 = none)
+	Counts []int64       `json:"counts"`   // per WaitBuckets bound, plus overflow
+	Total  int64         `json:"total"`    // tasks dequeued
+	Met    int64         `json:"met"`      // tasks dequeued within Target
+	Max    time.Duration `json:"max_wait"` // longest wait observed
+	Sum    time.Duration `json:"sum_wait"` // total wait, for the mean
+}
+
+func (h *WaitHistogram) observe(wait time.Duration) {
+	i := sort.Search(len(WaitBuckets), func(i int) bool { return wait <= WaitBuckets[i] })
+	h.Counts[i]++
+	h.Total++
+	if wait <= h.Target {
+		h.Met++
+	}
+	if wait > h.Max {
+		h.Max = wait
+	}
+	h.Sum += wait
+}
+
+// recordWaitLocked adds a dequeued task's queue wait to its tier's
+// histogram. Caller must hold s.mu.
+func (s *Scheduler) recordWaitLocked(qt QueuedTask, now time.Time) {
+	tier := BandTier(qt.Task.Priority, s.config.Bands)
+	h := s.waits[tier]
+	if h == nil {
+		h = &WaitHistogram{
+			Target: s.config.SLATargets[tier],
+			Counts: make([]int64, len(WaitBuckets)+1),
+		}
+		s.waits[tier] = h
+	}
+	wait := now.Sub(qt.QueuedAt)
+	if wait < 0 {
+		wait = 0
+	}
+	h.observe(wait)
+}
+
+// QueueWaitHistograms returns a snapshot of the queue-wait histogram for
+// every tier that has dequeued at least one task.
+func (s *Scheduler) QueueWaitHistograms() map[domain.SLATier]WaitHistogram {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[domain.SLATier]WaitHistogram, len(s.waits))
+	for tier, h := range s.waits {
+		snap := *h
+		snap.Counts = append([]int64(nil), h.Counts...)
+		out[tier] = snap
+	}
+	return out
+}
+
+// SLAComplianceReport returns, per tier with a latency target, the fraction
+// of dequeued tasks whose queue wait met that target. Tiers that have not
+// dequeued any task, or that are best-effort, are omitted.
+func (s *Scheduler) SLAComplianceReport() map[domain.SLATier]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report := make(map[domain.SLATier]float64, len(s.waits))
+	for tier, h := range s.waits {
+		if h.Target <= 0 || h.Total == 0 {
+			continue
+		}
+		report[tier] = float64(h.Met) / float64(h.Total)
+	}
+	return report
+}
+
+// ─── Cancellation ───────────────────────────────────────────────────────────
+
+// Cancel aborts a task that has not started executing. A still-queued task
+// is removed from its queue; a task already handed out by Dequeue is marked
+// cancelled so the executor skips it (see IsCancelled). Either way the task
+// is dropped from the persisted queue. Returns false if the task is unknown
+// or already completed.
+func (s *Scheduler) Cancel(taskID string) (canceled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for q := range s.queues {
+		for i, qt := range s.queues[q] {
+			if qt.Task.ID != taskID {
+				continue
+			}
+			s.queues[q] = append(s.queues[q][:i], s.queues[q][i+1:]...)
+			s.dropPersistedLocked(taskID)
+			s.endSpansLocked(taskID, "cancelled", domain.ErrTaskCancelled)
+			s.totalCancelled.Add(1)
+			return true
+		}
+	}
+
+	if _, ok := s.dispatched[taskID]; !ok || s.cancelled[taskID] {
+		return false
+	}
+	s.cancelled[taskID] = true
+	s.dropPersistedLocked(taskID)
+	s.totalCancelled.Add(1)
+	return true
+}
+
+// IsCancelled reports whether a dequeued task was cancelled before it
+// started. Executors check this immediately before running a task and,
+// if true, skip it and call MarkTaskCompleted to release it.
+func (s *Scheduler) IsCancelled(taskID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cancelled[taskID]
+}
+
+// dropPersistedLocked removes a task from the durable store so it is not
+// re-queued on recovery. Caller must hold s.mu.
+func (s *Scheduler) dropPersistedLocked(taskID string) {
+	if s.db != nil {
+		// Best-effort: a stale row only resurrects the task after a crash.
+		_ = s.db.DeleteQueuedTask(taskID)
+	}
+}
+
+// ─── Requeue & Dead Letters ─────────────────────────────────────────────────
+
+// Requeue puts a dequeued task whose run failed back in queue with one more
+// requeue counted, which lowers its effective priority (see RequeuePenalty).
+// Once the task has already been re-queued MaxRequeues times it is moved to
+// the dead letters instead and domain.ErrTaskDeadLettered is returned. A
+// task cancelled while running is released, not re-queued.
+func (s *Scheduler) Requeue(qt QueuedTask, cause error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := qt.Task.ID
+	delete(s.dispatched, id)
+	if s.cancelled[id] {
+		delete(s.cancelled, id)
+		s.endSpansLocked(id, "cancelled", domain.ErrTaskCancelled)
+		return domain.ErrTaskCancelled
+	}
+	if cause != nil {
+		qt.Task.Error = cause.Error()
+	}
+
+	if qt.Task.Requeues >= s.config.MaxRequeues {
+		s.deadLetters = append(s.deadLetters, qt)
+		s.dropPersistedLocked(id)
+		s.endSpansLocked(id, "dead_lettered", domain.ErrTaskDeadLettered)
+		return fmt.Errorf("task %s after %d requeues: %w", id, qt.Task.Requeues, domain.ErrTaskDeadLettered)
+	}
+
+	qt.Task.Requeues++
+	qt.QueuedAt = time.Now()
+	if err := s.persistLocked(qt); err != nil {
+		return err
+	}
+	pClass := s.band(qt.Task.Priority)
+	s.queues[pClass] = append(s.queues[pClass], qt)
+	s.restartWaitSpanLocked(id)
+	return nil
+}
+
+// DeadLetters returns the tasks that exceeded MaxRequeues or, under
+// MaxAgeDrop, MaxTaskAge, oldest first. A task's Error says why.
+func (s *Scheduler) DeadLetters() []QueuedTask {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.deadLetters)
+}
+
 // ─── Preemption ─────────────────────────────────────────────────────────────
 
 // Preempt checks if a realtime task should preempt a running spot task.
@@ -245,11 +837,11 @@ func (s *Scheduler) Preempt(realtimeTask domain.Task, runningTasks []domain.Task
 		return nil // only realtime can preempt
 	}
 
-	// Find the lowest-priority running task (prefer P4 spot tasks).
+	// Find the lowest-priority running task in the spot band.
 	var victim *domain.Task
 	for i := range runningTasks {
 		t := &runningTasks[i]
-		if t.Priority >= P4Spot && !t.IsTerminal() {
+		if t.Priority >= s.spotBand() && !t.IsTerminal() {
 			if victim == nil || t.Priority > victim.Priority {
 				victim = t
 			}
@@ -267,7 +859,10 @@ func (s *Scheduler) Preempt(realtimeTask domain.Task, runningTasks []domain.Task
 // StealableTasks returns tasks that can be stolen by an idle peer.
 // Takes from the TOP (oldest) of queues — FIFO for thieves.
 // Returns up to half the queue depth (or StealBatchSize if configured).
-func (s *Scheduler) StealableTasks(maxCount int) []QueuedTask {
+// Only tasks accepted by eligible are handed over; the rest keep their
+// place in the queue. A nil eligible accepts every task. eligible runs under
+// the scheduler lock and must not call back into the scheduler.
+func (s *Scheduler) StealableTasks(maxCount int, eligible func(QueuedTask) bool) []QueuedTask {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -280,15 +875,26 @@ func (s *Scheduler) StealableTasks(maxCount int) []QueuedTask {
 	}
 
 	stolen := make([]QueuedTask, 0, maxCount)
-	// Steal from lowest priority first (P4 → P0)
-	for q := 4; q >= 0 && len(stolen) < maxCount; q-- {
-		canTake := maxCount - len(stolen)
-		if canTake > len(s.queues[q]) {
-			canTake = len(s.queues[q])
-		}
+	// Steal from lowest priority first (spot → realtime)
+	for q := len(s.queues) - 1; q >= 0 && len(stolen) < maxCount; q-- {
 		// Take from the front (oldest = FIFO for thieves)
-		stolen = append(stolen, s.queues[q][:canTake]...)
-		s.queues[q] = s.queues[q][canTake:]
+		kept := s.queues[q][:0]
+		for _, qt := range s.queues[q] {
+			if len(stolen) < maxCount && (eligible == nil || eligible(qt)) {
+				stolen = append(stolen, qt)
+				s.endSpansLocked(qt.Task.ID, "stolen", nil)
+			} else {
+				kept = append(kept, qt)
+			}
+		}
+		s.queues[q] = kept
+	}
+
+	if s.db != nil {
+		// Stolen tasks are now owned by the thief's queue.
+		for _, qt := range stolen {
+			_ = s.db.DeleteQueuedTask(qt.Task.ID)
+		}
 	}
 
 	s.totalStolen.Add(int64(len(stolen)))
@@ -300,16 +906,192 @@ func (s *Scheduler) ImportStolenTasks(tasks []QueuedTask) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	for _, qt := range tasks {
-		pClass := qt.Task.Priority
-		if pClass < 0 {
-			pClass = 0
+		_ = s.persistLocked(qt) // best-effort: the task is already accepted
+		pClass := s.band(qt.Task.Priority)
+		s.queues[pClass] = append(s.queues[pClass], qt)
+		s.totalEnqueued.Add(1)
+	}
+}
+
+// ─── Persistence ────────────────────────────────────────────────────────────
+
+// Recover attaches db as the scheduler's durable queue store and reloads
+// every persisted task. Tasks that were dequeued but never completed
+// (in-progress at shutdown) are re-queued. Call once at startup, before
+// the scheduler accepts work. Returns the number of tasks recovered.
+func (s *Scheduler) Recover(db *sqlite.DB) (int, error) {
+	records, err := db.ListQueuedTasks()
+	if err != nil {
+		return 0, fmt.Errorf("list queued tasks: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.db = db
+
+	recovered := 0
+	for _, rec := range records {
+		var qt QueuedTask
+		if err := json.Unmarshal([]byte(rec.TaskJSON), &qt.Task); err != nil {
+			return recovered, fmt.Errorf("decode queued task %s: %w", rec.TaskID, err)
+		}
+		if err := json.Unmarshal([]byte(rec.RoutingJSON), &qt.Routing); err != nil {
+			return recovered, fmt.Errorf("decode routing for task %s: %w", rec.TaskID, err)
 		}
-		if pClass > 4 {
-			pClass = 4
+		qt.QueuedAt = rec.QueuedAt
+
+		if rec.State == sqlite.QueueStateInProgress {
+			if err := db.UpsertQueuedTask(rec); err != nil {
+				return recovered, fmt.Errorf("requeue task %s: %w", rec.TaskID, err)
+			}
 		}
+
+		pClass := s.band(qt.Task.Priority)
 		s.queues[pClass] = append(s.queues[pClass], qt)
-		s.totalEnqueued.Add(1)
+		recovered++
+	}
+	return recovered, nil
+}
+
+// MarkTaskCompleted records completion and removes the task from the
+// persisted queue (if persistence is enabled). Releasing a cancelled task
+// does not count toward TotalCompleted.
+func (s *Scheduler) MarkTaskCompleted(taskID string) error {
+	s.mu.Lock()
+	cancelled := s.cancelled[taskID]
+	if d, ok := s.dispatched[taskID]; ok && !cancelled {
+		s.observeCompletionLocked(time.Since(d.at))
+	}
+	delete(s.dispatched, taskID)
+	delete(s.cancelled, taskID)
+	if cancelled {
+		s.endSpansLocked(taskID, "cancelled", domain.ErrTaskCancelled)
+	} else {
+		s.endSpansLocked(taskID, "completed", nil)
+	}
+	db := s.db
+	s.mu.Unlock()
+
+	if !cancelled {
+		s.totalCompleted.Add(1)
+	}
+	if db == nil {
+		return nil
+	}
+	return db.DeleteQueuedTask(taskID)
+}
+
+// ─── Dispatch Timeout ───────────────────────────────────────────────────────
+
+// SweepInterval is how often Run releases timed-out dispatched tasks.
+const SweepInterval = time.Minute
+
+// Run releases tasks dispatched longer than Config.DispatchTimeout every
+// interval until ctx is done. Call in a goroutine.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := s.releaseTimedOut(time.Now()); n > 0 {
+				log.Printf("[scheduler] released %d task(s) dispatched longer than %s", n, s.config.DispatchTimeout)
+			}
+		}
+	}
+}
+
+// releaseTimedOut stops tracking every task dispatched longer than
+// Config.DispatchTimeout at now, ending its spans, and returns how many
+// were released. A later MarkTaskCompleted or Requeue still works.
+func (s *Scheduler) releaseTimedOut(now time.Time) int {
+	timeout := s.config.DispatchTimeout
+	if timeout <= 0 {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, d := range s.dispatched {
+		if now.Sub(d.at) <= timeout {
+			continue
+		}
+		delete(s.dispatched, id)
+		delete(s.cancelled, id)
+		s.endSpansLocked(id, "timed_out", domain.ErrTaskTimedOut)
+		s.totalTimedOut.Add(1)
+		n++
 	}
+	return n
+}
+
+// ─── Wait Estimation ────────────────────────────────────────────────────────
+
+// completionAlpha weights the newest sample in the completion-time average.
+const completionAlpha = 0.2
+
+// observeCompletionLocked folds one dispatch-to-completion time into the
+// moving average. Caller must hold s.mu.
+func (s *Scheduler) observeCompletionLocked(d time.Duration) {
+	if s.avgCompletion == 0 {
+		s.avgCompletion = d
+		return
+	}
+	s.avgCompletion += time.Duration(completionAlpha * float64(d-s.avgCompletion))
+}
+
+// EstimateWait returns the expected queue wait for a task enqueued now at
+// the given priority: the queued tasks of equal or higher priority, plus
+// those running, must drain through Config.Concurrency workers at the
+// recent average completion time. Returns zero when nothing is ahead and a
+// worker is free, or before any completion has been observed. Starvation
+// boosts are not modelled.
+func (s *Scheduler) EstimateWait(priority int) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ahead := 0
+	for q := 0; q <= s.band(priority); q++ {
+		ahead += len(s.queues[q])
+	}
+	workers := s.config.Concurrency
+	// Our task starts once all but workers-1 of the tasks ahead and running
+	// have finished.
+	mustFinish := ahead + len(s.dispatched) - workers + 1
+	if mustFinish <= 0 {
+		return 0
+	}
+	return time.Duration(mustFinish) * s.avgCompletion / time.Duration(workers)
+}
+
+// persistLocked writes a queued task to the durable store. No-op when
+// persistence is disabled. Caller must hold s.mu.
+func (s *Scheduler) persistLocked(qt QueuedTask) error {
+	if s.db == nil {
+		return nil
+	}
+	taskJSON, err := json.Marshal(qt.Task)
+	if err != nil {
+		return fmt.Errorf("encode queued task: %w", err)
+	}
+	routingJSON, err := json.Marshal(qt.Routing)
+	if err != nil {
+		return fmt.Errorf("encode task routing: %w", err)
+	}
+	err = s.db.UpsertQueuedTask(sqlite.QueuedTaskRecord{
+		TaskID:      qt.Task.ID,
+		Priority:    s.band(qt.Task.Priority),
+		TaskJSON:    string(taskJSON),
+		RoutingJSON: string(routingJSON),
+		QueuedAt:    qt.QueuedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("persist queued task: %w", err)
+	}
+	return nil
 }
 
 // ─── Stats & Inspection ─────────────────────────────────────────────────────
@@ -318,23 +1100,32 @@ func (s *Scheduler) ImportStolenTasks(tasks []QueuedTask) {
 type Stats struct {
 	QueueDepth     int               `json:"queue_depth"`
 	BackPressure   BackPressureLevel `json:"back_pressure"`
-	QueueByClass   [5]int            `json:"queue_by_class"`
+	QueueByClass   []int             `json:"queue_by_class"`
 	TotalEnqueued  int64             `json:"total_enqueued"`
 	TotalCompleted int64             `json:"total_completed"`
 	TotalRejected  int64             `json:"total_rejected"`
 	TotalStolen    int64             `json:"total_stolen"`
 	TotalPreempted int64             `json:"total_preempted"`
+	TotalCancelled int64             `json:"total_cancelled"`
+	TotalTimedOut  int64             `json:"total_timed_out"` // released after Config.DispatchTimeout
+	AvgCompletion  time.Duration     `json:"avg_completion"`  // moving average, dispatch to completion
+
+	// Tasks that hit Config.MaxTaskAge: promoted and run, or dropped
+	TotalAgeForced  int64 `json:"total_age_forced"`
+	TotalAgeDropped int64 `json:"total_age_dropped"`
 }
 
 // Stats returns current scheduler statistics.
 func (s *Scheduler) Stats() Stats {
 	s.mu.Lock()
+	s.dropAgedLocked(time.Now())
 	depth := s.queueDepthLocked()
 	bp := s.backPressureLevelLocked(depth)
-	var byClass [5]int
-	for i := 0; i < 5; i++ {
+	byClass := make([]int, len(s.queues))
+	for i := range s.queues {
 		byClass[i] = len(s.queues[i])
 	}
+	avg := s.avgCompletion
 	s.mu.Unlock()
 
 	return Stats{
@@ -346,6 +1137,12 @@ func (s *Scheduler) Stats() Stats {
 		TotalRejected:  s.totalRejected.Load(),
 		TotalStolen:    s.totalStolen.Load(),
 		TotalPreempted: s.totalPreempted.Load(),
+		TotalCancelled: s.totalCancelled.Load(),
+		TotalTimedOut:  s.totalTimedOut.Load(),
+		AvgCompletion:  avg,
+
+		TotalAgeForced:  s.totalAgeForced.Load(),
+		TotalAgeDropped: s.totalAgeDropped.Load(),
 	}
 }
 
@@ -353,6 +1150,7 @@ func (s *Scheduler) Stats() Stats {
 func (s *Scheduler) QueueDepth() int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.dropAgedLocked(time.Now())
 	return s.queueDepthLocked()
 }
 
@@ -360,24 +1158,133 @@ func (s *Scheduler) QueueDepth() int {
 func (s *Scheduler) BackPressureLevel() BackPressureLevel {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.dropAgedLocked(time.Now())
 	return s.backPressureLevelLocked(s.queueDepthLocked())
 }
 
-// MarkCompleted records that a task has been completed.
+// HasCapacity reports whether tier can take another task right now. A tier
+// that has used its Config.TierSlots budget is full; otherwise the task
+// must pass back-pressure admission by the same rules Enqueue applies:
+// hard pressure admits nothing, medium only realtime, soft everything but
+// spot, and the realtime reserve is kept for realtime.
+func (s *Scheduler) HasCapacity(tier domain.SLATier) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropAgedLocked(time.Now())
+	if slots := s.config.TierSlots[tier]; slots > 0 && s.tierLoadLocked(tier) >= slots {
+		return false
+	}
+	depth := s.queueDepthLocked()
+	realtime := tier == domain.SLARealtime
+	switch s.backPressureLevelLocked(depth) {
+	case BPHard:
+		return false
+	case BPMedium:
+		return realtime
+	case BPSoft:
+		if tier == domain.SLASpot {
+			return false
+		}
+	}
+	return realtime || depth < s.reserveThreshold()
+}
+
+// tierLoadLocked counts the queued and running tasks of tier. Caller must
+// hold s.mu.
+func (s *Scheduler) tierLoadLocked(tier domain.SLATier) int {
+	n := 0
+	for b := range s.queues {
+		if BandTier(b, s.config.Bands) == tier {
+			n += len(s.queues[b])
+		}
+	}
+	for _, d := range s.dispatched {
+		if d.tier == tier {
+			n++
+		}
+	}
+	return n
+}
+
+// MarkCompleted counts a completed task without naming it. It releases
+// no dispatched task, so the task keeps counting toward EstimateWait and
+// its persisted record and spans stay open.
+//
+// Deprecated: use MarkTaskCompleted, which releases the task that actually
+// finished.
 func (s *Scheduler) MarkCompleted() {
 	s.totalCompleted.Add(1)
 }
 
 // ─── Internal ───────────────────────────────────────────────────────────────
 
+// dispatchedTask is a dequeued task awaiting completion.
+type dispatchedTask struct {
+	at   time.Time      // when it was dequeued
+	tier domain.SLATier // tier of the band it was dequeued from
+}
+
+// clampBand clamps a task priority to a valid band index [0, bands-1].
+func clampBand(p, bands int) int {
+	if p < 0 {
+		return 0
+	}
+	if p > bands-1 {
+		return bands - 1
+	}
+	return p
+}
+
+// band returns the queue index for a task priority.
+func (s *Scheduler) band(p int) int {
+	return clampBand(p, s.config.Bands)
+}
+
+// spotBand is the lowest-priority band: first shed under soft
+// back-pressure and the only one realtime tasks preempt.
+func (s *Scheduler) spotBand() int {
+	return s.config.Bands - 1
+}
+
 func (s *Scheduler) queueDepthLocked() int {
 	total := 0
-	for i := 0; i < 5; i++ {
+	for i := range s.queues {
 		total += len(s.queues[i])
 	}
 	return total
 }
 
+// admitCeiling returns the queue depth from which back-pressure refuses
+// every task but realtime: BackPressureMedium, or BackPressureHard when
+// medium is unset or above it.
+func (s *Scheduler) admitCeiling() int {
+	if m := s.config.BackPressureMedium; m > 0 && m < s.config.BackPressureHard {
+		return m
+	}
+	return s.config.BackPressureHard
+}
+
+// reservedSlots returns how many queue slots below the admission ceiling
+// only P0 realtime tasks may fill. The reserve is carved out below the
+// ceiling, not the hard limit, since slots above the ceiling already
+// belong to realtime.
+func (s *Scheduler) reservedSlots() int {
+	frac := s.config.RealtimeReserve
+	if frac <= 0 {
+		return 0
+	}
+	if frac > 1 {
+		frac = 1
+	}
+	return int(math.Ceil(frac * float64(s.admitCeiling())))
+}
+
+// reserveThreshold returns the queue depth from which tasks other than
+// realtime are refused to keep the reserve free.
+func (s *Scheduler) reserveThreshold() int {
+	return s.admitCeiling() - s.reservedSlots()
+}
+
 func (s *Scheduler) backPressureLevelLocked(depth int) BackPressureLevel {
 	switch {
 	case depth >= s.config.BackPressureHard:
@@ -404,10 +1311,26 @@ type NodeCandidate struct {
 	CreditRate   float64 // cost per task
 	GPUAvailable bool
 	VRAMGB       float64
+
+	// MaxConcurrent is how many tasks the node advertises it can run at
+	// once; 0 means it advertises no limit and only CurrentLoad counts.
+	// AvailableSlots is how many of those are free.
+	MaxConcurrent  int
+	AvailableSlots int
+}
+
+// slotHeadroom returns the free fraction of the node's advertised task
+// slots, or 1 if it advertises none.
+func (n NodeCandidate) slotHeadroom() float64 {
+	if n.MaxConcurrent <= 0 {
+		return 1
+	}
+	return min(max(float64(n.AvailableSlots)/float64(n.MaxConcurrent), 0), 1)
 }
 
 // ScoreNode computes the weighted match score for a node to execute a task.
-// Higher score = better match. Score of 0 means node is disqualified.
+// Higher score = better match. Score of 0 means node is disqualified: a
+// fine-tune without a GPU, or a node with no free task slots.
 //
 // Weights (Architecture Part IX):
 //
@@ -419,6 +1342,9 @@ func ScoreNode(node NodeCandidate, task domain.Task, taskRegion domain.RegionID)
 	if task.Type == domain.TaskFineTune && !node.GPUAvailable {
 		return 0 // hard disqualification
 	}
+	if node.MaxConcurrent > 0 && node.AvailableSlots <= 0 {
+		return 0 // every advertised slot is taken
+	}
 
 	// Reputation [0, 1]
 	rep := node.Reputation
@@ -432,11 +1358,12 @@ func ScoreNode(node NodeCandidate, task domain.Task, taskRegion domain.RegionID)
 		loc = 1.0 / (1.0 + float64(latMs)/100.0)
 	}
 
-	// Availability (inverse of load)
+	// Availability (inverse of load, capped by free slots)
 	avail := 1.0 - node.CurrentLoad
 	if avail < 0 {
 		avail = 0
 	}
+	avail = min(avail, node.slotHeadroom())
 
 	// Latency score
 	lat := 1.0 / (1.0 + node.LatencyMs/100.0)
@@ -454,15 +1381,27 @@ func ScoreNode(node NodeCandidate, task domain.Task, taskRegion domain.RegionID)
 		0.10*lat + 0.15*cache + 0.05*cost
 }
 
+// NodeFilter is a hard eligibility check applied before scoring. Returning
+// false disqualifies the node regardless of its score (e.g. federation
+// isolation).
+type NodeFilter func(task domain.Task, nodeID string) bool
+
 // RankNodes scores and sorts candidates. Returns sorted best-first.
-func RankNodes(candidates []NodeCandidate, task domain.Task, taskRegion domain.RegionID) []NodeCandidate {
+// Candidates rejected by any filter are dropped.
+func RankNodes(candidates []NodeCandidate, task domain.Task, taskRegion domain.RegionID, filters ...NodeFilter) []NodeCandidate {
 	type scored struct {
 		node  NodeCandidate
 		score float64
 	}
 
 	all := make([]scored, 0, len(candidates))
+candidates:
 	for _, c := range candidates {
+		for _, allow := range filters {
+			if !allow(task, c.NodeID) {
+				continue candidates
+			}
+		}
 		s := ScoreNode(c, task, taskRegion)
 		if s > 0 {
 			all = append(all, scored{node: c, score: s})
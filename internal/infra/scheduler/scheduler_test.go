@@ -1,10 +1,17 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/observability"
+	"github.com/tutu-network/tutu/internal/infra/sqlite"
 )
 
 // ═══════════════════════════════════════════════════════════════════════════
@@ -58,6 +65,48 @@ func TestPriorityLabel(t *testing.T) {
 	}
 }
 
+func TestBandLabel(t *testing.T) {
+	tests := []struct {
+		p, bands int
+		want     string
+	}{
+		{0, 3, "REALTIME"},
+		{1, 3, "P1"},
+		{2, 3, "SPOT"},
+		{3, 3, "UNKNOWN"},
+		{0, 8, "REALTIME"},
+		{5, 8, "P5"},
+		{7, 8, "SPOT"},
+		{-1, 8, "UNKNOWN"},
+		{P3Low, DefaultBands, "LOW"},
+	}
+	for _, tt := range tests {
+		if got := BandLabel(tt.p, tt.bands); got != tt.want {
+			t.Errorf("BandLabel(%d, %d) = %q, want %q", tt.p, tt.bands, got, tt.want)
+		}
+	}
+}
+
+func TestBandTier(t *testing.T) {
+	tests := []struct {
+		p, bands int
+		want     domain.SLATier
+	}{
+		{0, 3, domain.SLARealtime},
+		{1, 3, domain.SLAStandard},
+		{2, 3, domain.SLASpot},
+		{5, 8, domain.SLAStandard},
+		{6, 8, domain.SLABatch},
+		{7, 8, domain.SLASpot},
+		{99, 8, domain.SLASpot},
+	}
+	for _, tt := range tests {
+		if got := BandTier(tt.p, tt.bands); got != tt.want {
+			t.Errorf("BandTier(%d, %d) = %q, want %q", tt.p, tt.bands, got, tt.want)
+		}
+	}
+}
+
 // ─── Enqueue / Dequeue ──────────────────────────────────────────────────────
 
 func TestScheduler_Enqueue_Dequeue(t *testing.T) {
@@ -112,6 +161,56 @@ func TestScheduler_PriorityOrdering(t *testing.T) {
 	}
 }
 
+func TestScheduler_TieBreak(t *testing.T) {
+	base := time.Now().Add(time.Hour)
+	// Same priority band; enqueued in this order.
+	tasks := []domain.Task{
+		{ID: "a", Priority: P2Normal, EstimatedCost: 50},
+		{ID: "b", Priority: P2Normal, EstimatedCost: 10, Deadline: base.Add(3 * time.Minute)},
+		{ID: "c", Priority: P2Normal, Deadline: base.Add(time.Minute)},
+		{ID: "d", Priority: P2Normal, EstimatedCost: 10, Deadline: base.Add(2 * time.Minute)},
+	}
+	tests := []struct {
+		policy TieBreak
+		want   string
+	}{
+		{TieBreakFIFO, "abcd"},
+		{TieBreakCheapest, "bdac"}, // unknown cost last; equal costs FIFO
+		{TieBreakDeadline, "cdba"}, // no deadline last
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.TieBreak = tt.policy
+			s := NewScheduler(cfg)
+			for _, task := range tasks {
+				if err := s.Enqueue(task, domain.TaskRouting{}); err != nil {
+					t.Fatalf("Enqueue(%s) error: %v", task.ID, err)
+				}
+			}
+			var got string
+			for qt := s.Dequeue(); qt != nil; qt = s.Dequeue() {
+				got += qt.Task.ID
+			}
+			if got != tt.want {
+				t.Errorf("dequeue order = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_TieBreak_PriorityFirst(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TieBreak = TieBreakCheapest
+	s := NewScheduler(cfg)
+	s.Enqueue(domain.Task{ID: "cheap-low", Priority: P3Low, EstimatedCost: 1}, domain.TaskRouting{})
+	s.Enqueue(domain.Task{ID: "costly-high", Priority: P1High, EstimatedCost: 1000}, domain.TaskRouting{})
+
+	if got := s.Dequeue(); got.Task.ID != "costly-high" {
+		t.Errorf("first = %q, want costly-high: tie-breaks apply only within a priority", got.Task.ID)
+	}
+}
+
 // ─── Back-Pressure ──────────────────────────────────────────────────────────
 
 func TestScheduler_BackPressure_Soft(t *testing.T) {
@@ -189,6 +288,135 @@ func TestScheduler_BackPressure_Hard(t *testing.T) {
 	}
 }
 
+func TestScheduler_HasCapacity(t *testing.T) {
+	s := newSmallScheduler(t) // soft=5 medium=10 hard=15
+	fill := func(n int) {
+		for i := 0; i < n; i++ {
+			task := domain.Task{ID: "fill", Priority: P0Realtime, Status: domain.TaskQueued, Type: domain.TaskInference}
+			if err := s.Enqueue(task, domain.TaskRouting{}); err != nil {
+				t.Fatalf("Enqueue fill #%d error: %v", i, err)
+			}
+		}
+	}
+	check := func(level string, want map[domain.SLATier]bool) {
+		t.Helper()
+		for tier, ok := range want {
+			if got := s.HasCapacity(tier); got != ok {
+				t.Errorf("%s: HasCapacity(%s) = %v, want %v", level, tier, got, ok)
+			}
+		}
+	}
+
+	check("idle", map[domain.SLATier]bool{domain.SLARealtime: true, domain.SLAStandard: true, domain.SLASpot: true})
+	fill(5)
+	check("soft", map[domain.SLATier]bool{domain.SLARealtime: true, domain.SLABatch: true, domain.SLASpot: false})
+	fill(5)
+	check("medium", map[domain.SLATier]bool{domain.SLARealtime: true, domain.SLAStandard: false})
+	fill(5)
+	check("hard", map[domain.SLATier]bool{domain.SLARealtime: false})
+}
+
+func TestScheduler_HasCapacity_TierSlots(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TierSlots = map[domain.SLATier]int{domain.SLARealtime: 2}
+	s := NewScheduler(cfg)
+	for _, id := range []string{"rt-1", "rt-2"} {
+		s.Enqueue(domain.Task{ID: id, Priority: P0Realtime, Status: domain.TaskQueued, Type: domain.TaskInference}, domain.TaskRouting{})
+	}
+
+	if s.HasCapacity(domain.SLARealtime) {
+		t.Error("realtime with both slots queued should be full")
+	}
+	if !s.HasCapacity(domain.SLAStandard) {
+		t.Error("standard has no budget and low back-pressure, so it should have room")
+	}
+
+	qt := s.Dequeue() // running tasks still hold their slot
+	if s.HasCapacity(domain.SLARealtime) {
+		t.Error("a dispatched realtime task should still count against the budget")
+	}
+	s.MarkTaskCompleted(qt.Task.ID)
+	if !s.HasCapacity(domain.SLARealtime) {
+		t.Error("completing a realtime task should free its slot")
+	}
+}
+
+func TestScheduler_RealtimeReserve(t *testing.T) {
+	s := NewScheduler(Config{
+		MaxQueueDepth:      20,
+		BackPressureSoft:   5,
+		BackPressureMedium: 20,
+		BackPressureHard:   20,
+		StarvationInterval: 100 * time.Millisecond,
+		RealtimeReserve:    0.25, // 5 of 20 slots held for realtime
+	})
+
+	for i := 0; i < 15; i++ {
+		task := domain.Task{ID: fmt.Sprintf("batch-%d", i), Priority: P3Low, Status: domain.TaskQueued, Type: domain.TaskInference}
+		if err := s.Enqueue(task, domain.TaskRouting{}); err != nil {
+			t.Fatalf("Enqueue batch #%d error: %v", i, err)
+		}
+	}
+
+	// Depth 15 is below medium (20), but batch and high tiers now hit the reserve.
+	if s.BackPressureLevel() != BPSoft {
+		t.Fatalf("BackPressureLevel = %v, want BPSoft", s.BackPressureLevel())
+	}
+	for _, p := range []int{P1High, P3Low} {
+		task := domain.Task{ID: "over", Priority: p, Status: domain.TaskQueued, Type: domain.TaskInference}
+		if err := s.Enqueue(task, domain.TaskRouting{}); err != domain.ErrRealtimeReserved {
+			t.Errorf("Enqueue(P%d) = %v, want ErrRealtimeReserved", p, err)
+		}
+	}
+
+	// Every reserved slot still admits realtime.
+	for i := 0; i < 5; i++ {
+		rt := domain.Task{ID: fmt.Sprintf("rt-%d", i), Priority: P0Realtime, Status: domain.TaskQueued, Type: domain.TaskInference}
+		if err := s.Enqueue(rt, domain.TaskRouting{}); err != nil {
+			t.Fatalf("Enqueue(P0) #%d into reserve: %v", i, err)
+		}
+	}
+	rt := domain.Task{ID: "rt-over", Priority: P0Realtime, Status: domain.TaskQueued, Type: domain.TaskInference}
+	if err := s.Enqueue(rt, domain.TaskRouting{}); err != domain.ErrBackPressureHard {
+		t.Errorf("Enqueue(P0) past hard limit = %v, want ErrBackPressureHard", err)
+	}
+	if got := s.Stats().TotalRejected; got != 3 {
+		t.Errorf("TotalRejected = %d, want 3", got)
+	}
+}
+
+func TestScheduler_RealtimeReserveSlots(t *testing.T) {
+	s := newSmallScheduler(t) // no reserve configured
+	if got := s.reservedSlots(); got != 0 {
+		t.Errorf("reservedSlots() = %d, want 0", got)
+	}
+	if got := NewScheduler(DefaultConfig()).reservedSlots(); got != 500 {
+		t.Errorf("default reservedSlots() = %d, want 500", got)
+	}
+}
+
+func TestScheduler_RealtimeReserve_Defaults(t *testing.T) {
+	s := NewScheduler(DefaultConfig()) // medium 5000, reserve 10%
+	for i := 0; i < 4_500; i++ {
+		task := domain.Task{ID: fmt.Sprintf("std-%d", i), Priority: P2Normal, Status: domain.TaskQueued, Type: domain.TaskInference}
+		if err := s.Enqueue(task, domain.TaskRouting{}); err != nil {
+			t.Fatalf("Enqueue standard #%d error: %v", i, err)
+		}
+	}
+
+	over := domain.Task{ID: "over", Priority: P1High, Status: domain.TaskQueued, Type: domain.TaskInference}
+	if err := s.Enqueue(over, domain.TaskRouting{}); err != domain.ErrRealtimeReserved {
+		t.Fatalf("Enqueue(P1) into the reserve = %v, want ErrRealtimeReserved", err)
+	}
+	if s.HasCapacity(domain.SLAStandard) {
+		t.Error("HasCapacity(standard) inside the reserve should be false")
+	}
+	rt := domain.Task{ID: "rt", Priority: P0Realtime, Status: domain.TaskQueued, Type: domain.TaskInference}
+	if err := s.Enqueue(rt, domain.TaskRouting{}); err != nil {
+		t.Errorf("Enqueue(P0) into the reserve: %v", err)
+	}
+}
+
 // ─── BackPressureLevel String ───────────────────────────────────────────────
 
 func TestBackPressureLevel_String(t *testing.T) {
@@ -221,7 +449,7 @@ func TestScheduler_WorkStealing(t *testing.T) {
 		}
 	}
 
-	stolen := s.StealableTasks(3)
+	stolen := s.StealableTasks(3, nil)
 	if len(stolen) != 3 {
 		t.Fatalf("StealableTasks(3) = %d, want 3", len(stolen))
 	}
@@ -242,7 +470,7 @@ func TestScheduler_WorkStealing_StealsLowestPriorityFirst(t *testing.T) {
 		s.Enqueue(task, domain.TaskRouting{})
 	}
 
-	stolen := s.StealableTasks(4)
+	stolen := s.StealableTasks(4, nil)
 	if len(stolen) != 4 {
 		t.Fatalf("StealableTasks(4) = %d, want 4", len(stolen))
 	}
@@ -265,12 +493,55 @@ func TestScheduler_WorkStealing_AutoHalf(t *testing.T) {
 		s.Enqueue(task, domain.TaskRouting{})
 	}
 	// maxCount=0 → steal half
-	stolen := s.StealableTasks(0)
+	stolen := s.StealableTasks(0, nil)
 	if len(stolen) != 5 {
 		t.Errorf("StealableTasks(0) = %d, want 5 (half of 10)", len(stolen))
 	}
 }
 
+func TestScheduler_WorkStealing_SkipsFederationPinned(t *testing.T) {
+	s := newTestScheduler(t)
+	s.SetNodeFilter(func(task domain.Task, nodeID string) bool {
+		// The thief is outside federation fed-a.
+		return task.FederationID == ""
+	})
+	for i := 0; i < 3; i++ {
+		s.Enqueue(domain.Task{ID: fmt.Sprintf("pinned-%d", i), FederationID: "fed-a", Priority: P4Spot, Status: domain.TaskQueued, Type: domain.TaskInference}, domain.TaskRouting{})
+		s.Enqueue(domain.Task{ID: fmt.Sprintf("public-%d", i), Priority: P4Spot, Status: domain.TaskQueued, Type: domain.TaskInference}, domain.TaskRouting{})
+	}
+
+	stolen := s.StealableTasks(6, s.StealEligibility("thief"))
+	if len(stolen) != 3 {
+		t.Fatalf("stole %d tasks, want 3 public", len(stolen))
+	}
+	for _, st := range stolen {
+		if st.Task.FederationID != "" {
+			t.Errorf("stole federation-pinned task %s", st.Task.ID)
+		}
+	}
+	if s.QueueDepth() != 3 {
+		t.Errorf("QueueDepth() = %d, want 3 pinned tasks left", s.QueueDepth())
+	}
+	if next := s.Dequeue(); next == nil || next.Task.ID != "pinned-0" {
+		t.Errorf("pinned tasks should keep FIFO order, got %+v", next)
+	}
+}
+
+func TestScheduler_WorkStealing_RespectsNodeAffinity(t *testing.T) {
+	s := newTestScheduler(t)
+	s.Enqueue(domain.Task{ID: "whitelisted", Priority: P3Low, Status: domain.TaskQueued, Type: domain.TaskInference},
+		domain.TaskRouting{NodeWhitelist: []string{"node-home"}})
+	s.Enqueue(domain.Task{ID: "blacklisted", Priority: P3Low, Status: domain.TaskQueued, Type: domain.TaskInference},
+		domain.TaskRouting{NodeBlacklist: []string{"thief"}})
+	s.Enqueue(domain.Task{ID: "open", Priority: P3Low, Status: domain.TaskQueued, Type: domain.TaskInference},
+		domain.TaskRouting{})
+
+	stolen := s.StealableTasks(3, s.StealEligibility("thief"))
+	if len(stolen) != 1 || stolen[0].Task.ID != "open" {
+		t.Fatalf("stolen = %+v, want only the open task", stolen)
+	}
+}
+
 func TestScheduler_ImportStolenTasks(t *testing.T) {
 	s := newTestScheduler(t)
 	tasks := []QueuedTask{
@@ -283,6 +554,334 @@ func TestScheduler_ImportStolenTasks(t *testing.T) {
 	}
 }
 
+// ─── Configurable Bands ─────────────────────────────────────────────────────
+
+func newBandScheduler(t *testing.T, bands int) *Scheduler {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Bands = bands
+	return NewScheduler(cfg)
+}
+
+func TestScheduler_Bands_DefaultIsFive(t *testing.T) {
+	s := NewScheduler(Config{BackPressureHard: 10, BackPressureMedium: 10, BackPressureSoft: 10, StarvationInterval: time.Minute})
+	if s.Bands() != DefaultBands {
+		t.Errorf("Bands() = %d, want %d", s.Bands(), DefaultBands)
+	}
+	if got := len(s.Stats().QueueByClass); got != DefaultBands {
+		t.Errorf("len(QueueByClass) = %d, want %d", got, DefaultBands)
+	}
+}
+
+func TestScheduler_ThreeBands_OrderingAndLabels(t *testing.T) {
+	s := newBandScheduler(t, 3)
+	// Priorities beyond the last band clamp into it.
+	for _, p := range []int{7, 2, 1, 0} {
+		task := domain.Task{ID: fmt.Sprintf("p%d", p), Priority: p, Status: domain.TaskQueued}
+		if err := s.Enqueue(task, domain.TaskRouting{}); err != nil {
+			t.Fatalf("Enqueue(p%d): %v", p, err)
+		}
+	}
+	if got := s.Stats().QueueByClass; len(got) != 3 || got[2] != 2 {
+		t.Errorf("QueueByClass = %v, want 3 bands with 2 in spot", got)
+	}
+
+	want := []string{"p0", "p1"}
+	for _, id := range want {
+		if got := s.Dequeue(); got == nil || got.Task.ID != id {
+			t.Fatalf("Dequeue() = %v, want %s", got, id)
+		}
+	}
+	if got := s.Dequeue(); got == nil || (got.Task.ID != "p2" && got.Task.ID != "p7") {
+		t.Fatalf("Dequeue() = %v, want a spot task", got)
+	}
+
+	labels := []string{s.PriorityLabel(0), s.PriorityLabel(1), s.PriorityLabel(2)}
+	if labels[0] != "REALTIME" || labels[1] != "P1" || labels[2] != "SPOT" {
+		t.Errorf("labels = %v, want [REALTIME P1 SPOT]", labels)
+	}
+}
+
+func TestScheduler_EightBands_OrderingAndLabels(t *testing.T) {
+	s := newBandScheduler(t, 8)
+	for p := 7; p >= 0; p-- {
+		task := domain.Task{ID: fmt.Sprintf("p%d", p), Priority: p, Status: domain.TaskQueued}
+		if err := s.Enqueue(task, domain.TaskRouting{}); err != nil {
+			t.Fatalf("Enqueue(p%d): %v", p, err)
+		}
+	}
+	for p := 0; p < 8; p++ {
+		got := s.Dequeue()
+		if got == nil || got.Task.ID != fmt.Sprintf("p%d", p) {
+			t.Fatalf("Dequeue() #%d = %v, want p%d", p, got, p)
+		}
+	}
+	if s.PriorityLabel(4) != "P4" || s.PriorityLabel(7) != "SPOT" {
+		t.Errorf("labels = %q, %q; want P4, SPOT", s.PriorityLabel(4), s.PriorityLabel(7))
+	}
+}
+
+func TestScheduler_EightBands_SoftBackPressureShedsLastBand(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Bands = 8
+	cfg.BackPressureSoft = 2
+	cfg.BackPressureMedium = 100
+	cfg.BackPressureHard = 200
+	s := NewScheduler(cfg)
+	for i := 0; i < 2; i++ {
+		s.Enqueue(domain.Task{ID: fmt.Sprintf("fill-%d", i), Priority: 3}, domain.TaskRouting{})
+	}
+
+	// P4 is an ordinary band with eight bands — only P7 is shed.
+	if err := s.Enqueue(domain.Task{ID: "p4", Priority: 4}, domain.TaskRouting{}); err != nil {
+		t.Errorf("P4 under soft back-pressure: %v, want accepted", err)
+	}
+	if err := s.Enqueue(domain.Task{ID: "p7", Priority: 7}, domain.TaskRouting{}); err != domain.ErrBackPressureSoft {
+		t.Errorf("P7 under soft back-pressure: err = %v, want ErrBackPressureSoft", err)
+	}
+}
+
+func TestScheduler_EightBands_PreemptsOnlyLastBand(t *testing.T) {
+	s := newBandScheduler(t, 8)
+	rt := domain.Task{ID: "rt", Priority: P0Realtime}
+	running := []domain.Task{
+		{ID: "p4", Priority: 4, Status: domain.TaskExecuting},
+		{ID: "p6", Priority: 6, Status: domain.TaskExecuting},
+	}
+	if v := s.Preempt(rt, running); v != nil {
+		t.Errorf("Preempt() = %s, want nil with no P7 task running", v.ID)
+	}
+	running = append(running, domain.Task{ID: "p7", Priority: 7, Status: domain.TaskExecuting})
+	if v := s.Preempt(rt, running); v == nil || v.ID != "p7" {
+		t.Errorf("Preempt() = %v, want p7", v)
+	}
+
+	three := newBandScheduler(t, 3)
+	if v := three.Preempt(rt, running); v == nil {
+		t.Error("3-band scheduler should preempt tasks at or below its spot band")
+	}
+}
+
+// ─── SLA Compliance ─────────────────────────────────────────────────────────
+
+func TestPriorityTier(t *testing.T) {
+	tests := []struct {
+		in   int
+		want domain.SLATier
+	}{
+		{P0Realtime, domain.SLARealtime},
+		{P1High, domain.SLAStandard},
+		{P2Normal, domain.SLAStandard},
+		{P3Low, domain.SLABatch},
+		{P4Spot, domain.SLASpot},
+		{99, domain.SLASpot},
+	}
+	for _, tt := range tests {
+		if got := PriorityTier(tt.in); got != tt.want {
+			t.Errorf("PriorityTier(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// newSLAScheduler returns a default scheduler with the MCP tiers' latency
+// targets.
+func newSLAScheduler(t *testing.T) *Scheduler {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.SLATargets = map[domain.SLATier]time.Duration{
+		domain.SLARealtime: 200 * time.Millisecond,
+		domain.SLAStandard: 2 * time.Second,
+		domain.SLABatch:    30 * time.Second,
+	}
+	return NewScheduler(cfg)
+}
+
+func TestScheduler_SLAComplianceReport(t *testing.T) {
+	s := newSLAScheduler(t)
+	now := time.Now()
+	var tasks []QueuedTask
+	// 3 realtime tasks within 200ms, 1 that waited a full second.
+	for i, waited := range []time.Duration{0, 0, 0, time.Second} {
+		tasks = append(tasks, QueuedTask{
+			Task:     domain.Task{ID: fmt.Sprintf("rt-%d", i), Priority: P0Realtime, Status: domain.TaskQueued},
+			QueuedAt: now.Add(-waited),
+		})
+	}
+	// Standard task well inside its 2s target; spot tasks have no target.
+	tasks = append(tasks,
+		QueuedTask{Task: domain.Task{ID: "std", Priority: P1High, Status: domain.TaskQueued}, QueuedAt: now},
+		QueuedTask{Task: domain.Task{ID: "spot", Priority: P4Spot, Status: domain.TaskQueued}, QueuedAt: now.Add(-time.Hour)},
+	)
+	s.ImportStolenTasks(tasks)
+
+	if got := s.SLAComplianceReport(); len(got) != 0 {
+		t.Errorf("report before any dequeue = %v, want empty", got)
+	}
+	for s.Dequeue() != nil {
+	}
+
+	report := s.SLAComplianceReport()
+	if got := report[domain.SLARealtime]; got != 0.75 {
+		t.Errorf("realtime compliance = %v, want 0.75", got)
+	}
+	if got := report[domain.SLAStandard]; got != 1 {
+		t.Errorf("standard compliance = %v, want 1", got)
+	}
+	if _, ok := report[domain.SLASpot]; ok {
+		t.Error("best-effort spot tier should not be reported")
+	}
+
+	// Another late realtime task lowers compliance further.
+	s.ImportStolenTasks([]QueuedTask{{
+		Task:     domain.Task{ID: "rt-late", Priority: P0Realtime, Status: domain.TaskQueued},
+		QueuedAt: time.Now().Add(-500 * time.Millisecond),
+	}})
+	s.Dequeue()
+	if got := s.SLAComplianceReport()[domain.SLARealtime]; got != 0.6 {
+		t.Errorf("realtime compliance after late task = %v, want 0.6", got)
+	}
+}
+
+func TestScheduler_QueueWaitHistograms(t *testing.T) {
+	s := newSLAScheduler(t)
+	s.ImportStolenTasks([]QueuedTask{
+		{Task: domain.Task{ID: "a", Priority: P0Realtime}, QueuedAt: time.Now()},
+		{Task: domain.Task{ID: "b", Priority: P0Realtime}, QueuedAt: time.Now().Add(-2 * time.Hour)},
+	})
+	s.Dequeue()
+	s.Dequeue()
+
+	h, ok := s.QueueWaitHistograms()[domain.SLARealtime]
+	if !ok {
+		t.Fatal("no realtime histogram")
+	}
+	if h.Total != 2 || h.Met != 1 {
+		t.Errorf("Total/Met = %d/%d, want 2/1", h.Total, h.Met)
+	}
+	if h.Target != 200*time.Millisecond {
+		t.Errorf("Target = %v, want 200ms", h.Target)
+	}
+	if len(h.Counts) != len(WaitBuckets)+1 {
+		t.Fatalf("len(Counts) = %d, want %d", len(h.Counts), len(WaitBuckets)+1)
+	}
+	if h.Counts[0] != 1 {
+		t.Errorf("first bucket = %d, want 1", h.Counts[0])
+	}
+	if h.Counts[len(WaitBuckets)] != 1 {
+		t.Errorf("overflow bucket = %d, want 1", h.Counts[len(WaitBuckets)])
+	}
+	if h.Max < 2*time.Hour {
+		t.Errorf("Max = %v, want >= 2h", h.Max)
+	}
+}
+
+// ─── Wait Estimation ────────────────────────────────────────────────────────
+
+// enqueueN adds n tasks at the given priority.
+func enqueueN(t *testing.T, s *Scheduler, prefix string, priority, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		task := domain.Task{ID: fmt.Sprintf("%s-%d", prefix, i), Priority: priority}
+		if err := s.Enqueue(task, domain.TaskRouting{}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+}
+
+func TestEstimateWait_EmptyQueue(t *testing.T) {
+	s := newTestScheduler(t)
+	s.avgCompletion = 2 * time.Second
+	if got := s.EstimateWait(P2Normal); got != 0 {
+		t.Errorf("EstimateWait() on empty queue = %v, want 0", got)
+	}
+}
+
+func TestEstimateWait_ProportionalToTasksAhead(t *testing.T) {
+	s := newTestScheduler(t)
+	s.avgCompletion = 2 * time.Second
+	enqueueN(t, s, "normal", P2Normal, 5)
+
+	tests := []struct {
+		priority int
+		want     time.Duration
+	}{
+		{P0Realtime, 0},              // nothing ahead of realtime
+		{P2Normal, 10 * time.Second}, // behind 5 equal-priority tasks
+		{P4Spot, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := s.EstimateWait(tt.priority); got != tt.want {
+			t.Errorf("EstimateWait(P%d) = %v, want %v", tt.priority, got, tt.want)
+		}
+	}
+
+	enqueueN(t, s, "high", P1High, 3)
+	if got := s.EstimateWait(P2Normal); got != 16*time.Second {
+		t.Errorf("EstimateWait(P2) behind 8 tasks = %v, want 16s", got)
+	}
+	if got := s.EstimateWait(P1High); got != 6*time.Second {
+		t.Errorf("EstimateWait(P1) behind 3 tasks = %v, want 6s", got)
+	}
+}
+
+func TestEstimateWait_RunningTasksAndConcurrency(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Concurrency = 4
+	s := NewScheduler(cfg)
+	s.avgCompletion = 2 * time.Second
+
+	enqueueN(t, s, "t", P2Normal, 6)
+	var running []string
+	for i := 0; i < 4; i++ {
+		running = append(running, s.Dequeue().Task.ID) // four running, two queued
+	}
+	// Workers are all busy: 4 running + 2 queued - 3 = 3 completions needed,
+	// at four completions per 2s.
+	if got := s.EstimateWait(P2Normal); got != 1500*time.Millisecond {
+		t.Errorf("EstimateWait() = %v, want 1.5s", got)
+	}
+
+	s.MarkTaskCompleted(running[0])
+	s.MarkTaskCompleted(running[1])
+	s.avgCompletion = 2 * time.Second // reseed after real completions
+	for s.Dequeue() != nil {
+	}
+	// Four running, nothing queued: a realtime task still waits for a slot.
+	if got := s.EstimateWait(P0Realtime); got != 500*time.Millisecond {
+		t.Errorf("EstimateWait() with full workers = %v, want 500ms", got)
+	}
+}
+
+func TestEstimateWait_TracksCompletionTime(t *testing.T) {
+	s := newTestScheduler(t)
+	enqueueN(t, s, "t", P2Normal, 2)
+	if got := s.EstimateWait(P2Normal); got != 0 {
+		t.Errorf("EstimateWait() before any completion = %v, want 0", got)
+	}
+
+	qt := s.Dequeue()
+	time.Sleep(5 * time.Millisecond)
+	if err := s.MarkTaskCompleted(qt.Task.ID); err != nil {
+		t.Fatal(err)
+	}
+	avg := s.Stats().AvgCompletion
+	if avg < 5*time.Millisecond {
+		t.Fatalf("AvgCompletion = %v, want at least 5ms", avg)
+	}
+	if got := s.EstimateWait(P2Normal); got != avg {
+		t.Errorf("EstimateWait() behind one task = %v, want %v", got, avg)
+	}
+
+	// Cancelled tasks don't skew the average.
+	qt = s.Dequeue()
+	s.Cancel(qt.Task.ID)
+	time.Sleep(20 * time.Millisecond)
+	s.MarkTaskCompleted(qt.Task.ID)
+	if got := s.Stats().AvgCompletion; got != avg {
+		t.Errorf("AvgCompletion after cancelled task = %v, want unchanged %v", got, avg)
+	}
+}
+
 // ─── Preemption ─────────────────────────────────────────────────────────────
 
 func TestScheduler_Preempt_RealtimePreemptsSpot(t *testing.T) {
@@ -358,6 +957,228 @@ func TestQueuedTask_EffectivePriority_FloorAtZero(t *testing.T) {
 	}
 }
 
+// ─── Reputation Boost ───────────────────────────────────────────────────────
+
+func newReputationScheduler(boost int) *Scheduler {
+	cfg := DefaultConfig()
+	cfg.ReputationBoost = boost
+	s := NewScheduler(cfg)
+	scores := map[string]float64{"trusted": 0.95, "newcomer": 0.3}
+	s.SetReputationSource(func(nodeID string) (float64, bool) {
+		score, ok := scores[nodeID]
+		return score, ok
+	})
+	return s
+}
+
+func submittedBy(id, submitter string, priority int) domain.Task {
+	return domain.Task{ID: id, Type: domain.TaskInference, Status: domain.TaskQueued, Priority: priority, SubmitterID: submitter}
+}
+
+func TestScheduler_ReputationBoost(t *testing.T) {
+	s := newReputationScheduler(1)
+	s.Enqueue(submittedBy("low-rep", "newcomer", P2Normal), domain.TaskRouting{})
+	s.Enqueue(submittedBy("high-rep", "trusted", P2Normal), domain.TaskRouting{})
+
+	if qt := s.Dequeue(); qt == nil || qt.Task.ID != "high-rep" {
+		t.Fatalf("Dequeue() = %+v, want the high-reputation submitter's task first", qt)
+	}
+	if qt := s.Dequeue(); qt == nil || qt.Task.ID != "low-rep" {
+		t.Errorf("Dequeue() = %+v, want low-rep", qt)
+	}
+}
+
+func TestScheduler_ReputationBoost_DefaultOff(t *testing.T) {
+	s := newReputationScheduler(0)
+	s.Enqueue(submittedBy("low-rep", "newcomer", P2Normal), domain.TaskRouting{})
+	s.Enqueue(submittedBy("high-rep", "trusted", P2Normal), domain.TaskRouting{})
+
+	if qt := s.Dequeue(); qt == nil || qt.Task.ID != "low-rep" {
+		t.Errorf("Dequeue() = %+v, want FIFO order without a boost", qt)
+	}
+}
+
+func TestScheduler_ReputationBoost_LocalSubmitterLookedUpOnce(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ReputationBoost = 1
+	s := NewScheduler(cfg)
+	lookups := map[string]int{}
+	s.SetReputationSource(func(nodeID string) (float64, bool) {
+		lookups[nodeID]++
+		return map[string]float64{"node-self": 0.9}[nodeID], nodeID == "node-self"
+	})
+	s.SetLocalNode("node-self")
+
+	for i := range 5 {
+		s.Enqueue(submittedBy(fmt.Sprintf("peer-%d", i), "newcomer", P2Normal), domain.TaskRouting{})
+	}
+	s.Enqueue(domain.Task{ID: "local", Type: domain.TaskInference, Status: domain.TaskQueued, Priority: P2Normal}, domain.TaskRouting{})
+
+	qt := s.Dequeue()
+	if qt == nil || qt.Task.ID != "local" || qt.Task.SubmitterID != "node-self" {
+		t.Fatalf("Dequeue() = %+v, want the local task stamped with node-self", qt)
+	}
+	if want := map[string]int{"newcomer": 1, "node-self": 1}; !reflect.DeepEqual(lookups, want) {
+		t.Errorf("reputation lookups = %v, want one per submitter", lookups)
+	}
+}
+
+func TestScheduler_ReputationBoost_NeverCrossesRealtime(t *testing.T) {
+	s := newReputationScheduler(3)
+	s.Enqueue(submittedBy("boosted", "trusted", P2Normal), domain.TaskRouting{})
+	s.Enqueue(submittedBy("realtime", "newcomer", P0Realtime), domain.TaskRouting{})
+	s.Enqueue(submittedBy("high", "newcomer", P1High), domain.TaskRouting{})
+
+	var order []string
+	for qt := s.Dequeue(); qt != nil; qt = s.Dequeue() {
+		order = append(order, qt.Task.ID)
+	}
+	// A boost of 3 stops at P1: the boosted task ties with "high" and wins
+	// on FIFO, but realtime still goes first.
+	if want := []string{"realtime", "boosted", "high"}; !slices.Equal(order, want) {
+		t.Errorf("dequeue order = %v, want %v", order, want)
+	}
+}
+
+func TestScheduler_ReputationBoost_CappedAfterAging(t *testing.T) {
+	s := newReputationScheduler(3)
+	// One starvation interval has already aged this P2 task to P1, so the
+	// boost has nothing left to give without reaching realtime.
+	s.ImportStolenTasks([]QueuedTask{{
+		Task:     submittedBy("aged", "trusted", P2Normal),
+		QueuedAt: time.Now().Add(-61 * time.Second),
+	}})
+	s.Enqueue(submittedBy("realtime", "newcomer", P0Realtime), domain.TaskRouting{})
+
+	if qt := s.Dequeue(); qt == nil || qt.Task.ID != "realtime" {
+		t.Fatalf("Dequeue() = %+v, want realtime ahead of the aged, boosted task", qt)
+	}
+}
+
+func TestScheduler_EnqueueRemote_KeepsSubmitter(t *testing.T) {
+	s := newReputationScheduler(1)
+	s.SetLocalNode("node-self") // unknown reputation: local work is not boosted
+
+	s.Enqueue(domain.Task{ID: "local", Type: domain.TaskInference, Status: domain.TaskQueued, Priority: P2Normal}, domain.TaskRouting{})
+	s.EnqueueRemote(domain.Task{ID: "anonymous", Type: domain.TaskInference, Status: domain.TaskQueued, Priority: P2Normal}, domain.TaskRouting{})
+	s.EnqueueRemote(submittedBy("remote", "trusted", P2Normal), domain.TaskRouting{})
+
+	submitters := map[string]string{}
+	var order []string
+	for qt := s.Dequeue(); qt != nil; qt = s.Dequeue() {
+		order = append(order, qt.Task.ID)
+		submitters[qt.Task.ID] = qt.Task.SubmitterID
+	}
+	if want := []string{"remote", "local", "anonymous"}; !slices.Equal(order, want) {
+		t.Errorf("dequeue order = %v, want %v", order, want)
+	}
+	if want := map[string]string{"local": "node-self", "anonymous": "", "remote": "trusted"}; !reflect.DeepEqual(submitters, want) {
+		t.Errorf("submitters = %v, want %v", submitters, want)
+	}
+}
+
+// ─── Maximum Task Age ───────────────────────────────────────────────────────
+
+// newAgeCapScheduler returns a scheduler with starvation aging disabled, a
+// one-minute MaxTaskAge, and a queue holding a fresh realtime task, a fresh
+// high task, and a spot task queued an hour ago.
+func newAgeCapScheduler(t *testing.T, policy MaxAgePolicy) *Scheduler {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.StarvationInterval = 0
+	cfg.MaxTaskAge = time.Minute
+	cfg.MaxAgePolicy = policy
+	s := NewScheduler(cfg)
+	now := time.Now()
+	s.ImportStolenTasks([]QueuedTask{
+		{Task: domain.Task{ID: "high", Priority: P1High, Status: domain.TaskQueued}, QueuedAt: now},
+		{Task: domain.Task{ID: "stale-spot", Priority: P4Spot, Status: domain.TaskQueued}, QueuedAt: now.Add(-time.Hour)},
+		{Task: domain.Task{ID: "realtime", Priority: P0Realtime, Status: domain.TaskQueued}, QueuedAt: now},
+	})
+	return s
+}
+
+func drainIDs(s *Scheduler) []string {
+	var ids []string
+	for qt := s.Dequeue(); qt != nil; qt = s.Dequeue() {
+		ids = append(ids, qt.Task.ID)
+	}
+	return ids
+}
+
+func TestScheduler_MaxTaskAge_Promote(t *testing.T) {
+	s := newAgeCapScheduler(t, MaxAgePromote)
+
+	// The stale spot task joins the top band and wins the FIFO tie there.
+	if want, got := []string{"stale-spot", "realtime", "high"}, drainIDs(s); !slices.Equal(got, want) {
+		t.Errorf("dequeue order = %v, want %v", got, want)
+	}
+	st := s.Stats()
+	if st.TotalAgeForced != 1 || st.TotalAgeDropped != 0 {
+		t.Errorf("forced/dropped = %d/%d, want 1/0", st.TotalAgeForced, st.TotalAgeDropped)
+	}
+}
+
+func TestScheduler_MaxTaskAge_Drop(t *testing.T) {
+	s := newAgeCapScheduler(t, MaxAgeDrop)
+
+	if want, got := []string{"realtime", "high"}, drainIDs(s); !slices.Equal(got, want) {
+		t.Errorf("dequeue order = %v, want %v without the stale task", got, want)
+	}
+	st := s.Stats()
+	if st.TotalAgeDropped != 1 || st.TotalAgeForced != 0 {
+		t.Errorf("forced/dropped = %d/%d, want 0/1", st.TotalAgeForced, st.TotalAgeDropped)
+	}
+	if st.QueueDepth != 0 {
+		t.Errorf("QueueDepth = %d, want 0", st.QueueDepth)
+	}
+	dead := s.DeadLetters()
+	if len(dead) != 1 || dead[0].Task.ID != "stale-spot" || dead[0].Task.Error != domain.ErrTaskAgedOut.Error() {
+		t.Errorf("DeadLetters() = %+v, want the stale task failed as aged out", dead)
+	}
+}
+
+func TestScheduler_MaxTaskAge_DropBeforeDequeue(t *testing.T) {
+	s := newAgeCapScheduler(t, MaxAgeDrop)
+
+	// The stale task stops counting toward depth without a Dequeue.
+	if got := s.QueueDepth(); got != 2 {
+		t.Errorf("QueueDepth() = %d, want 2 without the stale task", got)
+	}
+	if dead := s.DeadLetters(); len(dead) != 1 || dead[0].Task.ID != "stale-spot" {
+		t.Errorf("DeadLetters() = %+v, want the stale task", dead)
+	}
+}
+
+func TestScheduler_MaxTaskAge_DefaultOff(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.StarvationInterval = 0
+	s := NewScheduler(cfg)
+	s.ImportStolenTasks([]QueuedTask{
+		{Task: domain.Task{ID: "stale-spot", Priority: P4Spot, Status: domain.TaskQueued}, QueuedAt: time.Now().Add(-time.Hour)},
+		{Task: domain.Task{ID: "high", Priority: P1High, Status: domain.TaskQueued}, QueuedAt: time.Now()},
+	})
+
+	// With aging disabled too, nothing lifts the stale task.
+	if want, got := []string{"high", "stale-spot"}, drainIDs(s); !slices.Equal(got, want) {
+		t.Errorf("dequeue order = %v, want %v", got, want)
+	}
+	if st := s.Stats(); st.TotalAgeForced != 0 || st.TotalAgeDropped != 0 {
+		t.Errorf("forced/dropped = %d/%d, want 0/0", st.TotalAgeForced, st.TotalAgeDropped)
+	}
+}
+
+func TestParseMaxAgePolicy(t *testing.T) {
+	for name, want := range map[string]MaxAgePolicy{"": MaxAgePromote, "promote": MaxAgePromote, "DROP": MaxAgeDrop} {
+		if got, err := ParseMaxAgePolicy(name); err != nil || got != want {
+			t.Errorf("ParseMaxAgePolicy(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseMaxAgePolicy("discard"); err == nil {
+		t.Error("ParseMaxAgePolicy(discard) should fail")
+	}
+}
+
 // ─── Node Scoring ───────────────────────────────────────────────────────────
 
 func TestScoreNode_DisqualifiesNoGPU_ForFineTune(t *testing.T) {
@@ -424,6 +1245,41 @@ func TestScoreNode_CacheBonus(t *testing.T) {
 	}
 }
 
+func TestScoreNode_AvailableSlots(t *testing.T) {
+	task := domain.Task{Type: domain.TaskInference}
+	full := NodeCandidate{
+		NodeID: "full", Region: domain.RegionUSEast, Reputation: 0.95, HasModelHot: true,
+		GPUAvailable: true, MaxConcurrent: 4, AvailableSlots: 0,
+	}
+	half := NodeCandidate{
+		NodeID: "half", Region: domain.RegionUSEast, Reputation: 0.6, HasModelHot: true,
+		GPUAvailable: true, MaxConcurrent: 4, AvailableSlots: 2,
+	}
+
+	fullScore, halfScore := ScoreNode(full, task, domain.RegionUSEast), ScoreNode(half, task, domain.RegionUSEast)
+	if fullScore != 0 {
+		t.Errorf("fully loaded node scored %f, want 0 (disqualified)", fullScore)
+	}
+	if halfScore <= fullScore {
+		t.Errorf("half-loaded mid-reputation node (%f) should beat the full high-reputation node (%f)", halfScore, fullScore)
+	}
+	if ranked := RankNodes([]NodeCandidate{full, half}, task, domain.RegionUSEast); len(ranked) != 1 || ranked[0].NodeID != "half" {
+		t.Errorf("RankNodes() = %v, want only half", ranked)
+	}
+
+	// More headroom scores higher; a node advertising no slots is not capped.
+	roomy := half
+	roomy.AvailableSlots = 3
+	if ScoreNode(roomy, task, domain.RegionUSEast) <= halfScore {
+		t.Error("node with 3 free slots should beat one with 2")
+	}
+	unadvertised := half
+	unadvertised.MaxConcurrent, unadvertised.AvailableSlots = 0, 0
+	if ScoreNode(unadvertised, task, domain.RegionUSEast) <= halfScore {
+		t.Error("node without a slot advertisement should be scored on CurrentLoad alone")
+	}
+}
+
 func TestRankNodes(t *testing.T) {
 	candidates := []NodeCandidate{
 		{NodeID: "bad", Region: domain.RegionAPSouth, Reputation: 0.2, CurrentLoad: 0.9, GPUAvailable: true},
@@ -442,12 +1298,35 @@ func TestRankNodes(t *testing.T) {
 
 // ─── Stats ──────────────────────────────────────────────────────────────────
 
+func TestRankNodes_FilterIsHard(t *testing.T) {
+	candidates := []NodeCandidate{
+		{NodeID: "best", Region: domain.RegionUSEast, Reputation: 1.0, HasModelHot: true},
+		{NodeID: "ok", Region: domain.RegionUSEast, Reputation: 0.5},
+	}
+	task := domain.Task{Type: domain.TaskInference, FederationID: "fed-acme"}
+	onlyOK := func(task domain.Task, nodeID string) bool { return nodeID == "ok" }
+
+	ranked := RankNodes(candidates, task, domain.RegionUSEast, onlyOK)
+	if len(ranked) != 1 || ranked[0].NodeID != "ok" {
+		t.Fatalf("RankNodes(filtered) = %v, want only ok", ranked)
+	}
+
+	s := newTestScheduler(t)
+	if got := s.RankNodes(candidates, task, domain.RegionUSEast); len(got) != 2 {
+		t.Errorf("Scheduler.RankNodes() without filter = %d nodes, want 2", len(got))
+	}
+	s.SetNodeFilter(onlyOK)
+	if got := s.RankNodes(candidates, task, domain.RegionUSEast); len(got) != 1 || got[0].NodeID != "ok" {
+		t.Errorf("Scheduler.RankNodes() with filter = %v, want only ok", got)
+	}
+}
+
 func TestScheduler_Stats(t *testing.T) {
 	s := newTestScheduler(t)
 	task := domain.Task{ID: "t", Priority: P2Normal, Status: domain.TaskQueued, Type: domain.TaskInference}
 	s.Enqueue(task, domain.TaskRouting{})
 	s.Dequeue()
-	s.MarkCompleted()
+	s.MarkTaskCompleted("t")
 
 	stats := s.Stats()
 	if stats.TotalEnqueued != 1 {
@@ -457,3 +1336,417 @@ func TestScheduler_Stats(t *testing.T) {
 		t.Errorf("TotalCompleted = %d, want 1", stats.TotalCompleted)
 	}
 }
+
+func TestScheduler_MarkCompletedOnlyCounts(t *testing.T) {
+	s := newTestScheduler(t)
+	for _, id := range []string{"first", "second"} {
+		s.Enqueue(domain.Task{ID: id, Priority: P2Normal, Status: domain.TaskQueued, Type: domain.TaskInference}, domain.TaskRouting{})
+		s.Dequeue()
+	}
+
+	s.MarkCompleted()
+	for _, id := range []string{"first", "second"} {
+		if _, running := s.dispatched[id]; !running {
+			t.Errorf("MarkCompleted() released %s without being told it finished", id)
+		}
+	}
+	if got := s.Stats().TotalCompleted; got != 1 {
+		t.Errorf("TotalCompleted = %d, want 1", got)
+	}
+}
+
+// ─── Persistence ────────────────────────────────────────────────────────────
+
+func openQueueDB(t *testing.T, dir string) *sqlite.DB {
+	t.Helper()
+	db, err := sqlite.Open(dir)
+	if err != nil {
+		t.Fatalf("sqlite.Open() error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestScheduler_Recover_AfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	db := openQueueDB(t, dir)
+
+	s := newTestScheduler(t)
+	if n, err := s.Recover(db); err != nil || n != 0 {
+		t.Fatalf("Recover() on empty DB = %d, %v; want 0, nil", n, err)
+	}
+
+	routing := domain.TaskRouting{RegionAffinity: []domain.RegionID{domain.RegionEUWest}}
+	for _, tk := range []domain.Task{
+		{ID: "spot", Type: domain.TaskInference, Status: domain.TaskQueued, Priority: P4Spot},
+		{ID: "normal", Type: domain.TaskInference, Status: domain.TaskQueued, Priority: P2Normal},
+		{ID: "realtime", Type: domain.TaskInference, Status: domain.TaskQueued, Priority: P0Realtime},
+		{ID: "high", Type: domain.TaskEmbedding, Status: domain.TaskQueued, Priority: P1High},
+	} {
+		if err := s.Enqueue(tk, routing); err != nil {
+			t.Fatalf("Enqueue(%s) error: %v", tk.ID, err)
+		}
+	}
+
+	// Dequeue one (in-progress at "crash") and complete nothing.
+	if got := s.Dequeue(); got == nil || got.Task.ID != "realtime" {
+		t.Fatalf("Dequeue() = %v, want realtime", got)
+	}
+
+	// Simulate restart: fresh scheduler, reopened DB.
+	db.Close()
+	db2 := openQueueDB(t, dir)
+	s2 := newTestScheduler(t)
+	n, err := s2.Recover(db2)
+	if err != nil {
+		t.Fatalf("Recover() error: %v", err)
+	}
+	if n != 4 {
+		t.Fatalf("Recover() = %d, want 4 (in-progress task re-queued)", n)
+	}
+
+	want := []string{"realtime", "high", "normal", "spot"}
+	for _, id := range want {
+		got := s2.Dequeue()
+		if got == nil {
+			t.Fatalf("Dequeue() = nil, want %s", id)
+		}
+		if got.Task.ID != id {
+			t.Errorf("Dequeue() = %s, want %s", got.Task.ID, id)
+		}
+		if got.Routing.PreferredRegion() != domain.RegionEUWest {
+			t.Errorf("%s routing lost across restart", id)
+		}
+	}
+}
+
+func TestScheduler_MarkTaskCompleted_RemovesFromStore(t *testing.T) {
+	dir := t.TempDir()
+	db := openQueueDB(t, dir)
+
+	s := newTestScheduler(t)
+	if _, err := s.Recover(db); err != nil {
+		t.Fatal(err)
+	}
+	s.Enqueue(domain.Task{ID: "done", Priority: P2Normal, Type: domain.TaskInference}, domain.TaskRouting{})
+	s.Enqueue(domain.Task{ID: "pending", Priority: P2Normal, Type: domain.TaskInference}, domain.TaskRouting{})
+
+	got := s.Dequeue()
+	if err := s.MarkTaskCompleted(got.Task.ID); err != nil {
+		t.Fatalf("MarkTaskCompleted() error: %v", err)
+	}
+	if s.Stats().TotalCompleted != 1 {
+		t.Errorf("TotalCompleted = %d, want 1", s.Stats().TotalCompleted)
+	}
+
+	s2 := newTestScheduler(t)
+	n, err := s2.Recover(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("Recover() = %d, want 1", n)
+	}
+	if left := s2.Dequeue(); left.Task.ID == got.Task.ID {
+		t.Errorf("completed task %s was recovered", got.Task.ID)
+	}
+}
+
+func TestScheduler_MarkTaskCompleted_NoStore(t *testing.T) {
+	s := newTestScheduler(t)
+	if err := s.MarkTaskCompleted("t"); err != nil {
+		t.Errorf("MarkTaskCompleted() without store error: %v", err)
+	}
+}
+
+func TestScheduler_Cancel_QueuedTask(t *testing.T) {
+	dir := t.TempDir()
+	db := openQueueDB(t, dir)
+
+	s := newTestScheduler(t)
+	if _, err := s.Recover(db); err != nil {
+		t.Fatal(err)
+	}
+	s.Enqueue(domain.Task{ID: "keep", Priority: P2Normal, Type: domain.TaskInference}, domain.TaskRouting{})
+	s.Enqueue(domain.Task{ID: "drop", Priority: P2Normal, Type: domain.TaskInference}, domain.TaskRouting{})
+
+	if !s.Cancel("drop") {
+		t.Fatal("Cancel(queued) = false, want true")
+	}
+	if s.QueueDepth() != 1 {
+		t.Errorf("QueueDepth() = %d, want 1", s.QueueDepth())
+	}
+	if s.Cancel("drop") {
+		t.Error("second Cancel() = true, want false")
+	}
+	if s.Stats().TotalCancelled != 1 {
+		t.Errorf("TotalCancelled = %d, want 1", s.Stats().TotalCancelled)
+	}
+
+	if got := s.Dequeue(); got == nil || got.Task.ID != "keep" {
+		t.Fatalf("Dequeue() = %v, want keep", got)
+	}
+	if s.IsCancelled("keep") {
+		t.Error("IsCancelled(keep) = true, want false")
+	}
+
+	// The cancelled task must not come back after a restart.
+	s2 := newTestScheduler(t)
+	n, err := s2.Recover(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("Recover() = %d, want 1", n)
+	}
+	if got := s2.Dequeue(); got.Task.ID != "keep" {
+		t.Errorf("recovered %s, want keep", got.Task.ID)
+	}
+}
+
+func TestScheduler_Cancel_DequeuedTask(t *testing.T) {
+	s := newTestScheduler(t)
+	s.Enqueue(domain.Task{ID: "t1", Priority: P2Normal, Type: domain.TaskInference}, domain.TaskRouting{})
+
+	got := s.Dequeue()
+	if got == nil {
+		t.Fatal("Dequeue() = nil")
+	}
+
+	// The client cancels after dispatch but before the executor starts.
+	if !s.Cancel("t1") {
+		t.Fatal("Cancel(dequeued) = false, want true")
+	}
+	if !s.IsCancelled(got.Task.ID) {
+		t.Fatal("executor did not see cancellation")
+	}
+
+	if err := s.MarkTaskCompleted(got.Task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if s.IsCancelled("t1") {
+		t.Error("cancellation not cleared after release")
+	}
+	if s.Stats().TotalCompleted != 0 {
+		t.Errorf("TotalCompleted = %d, want 0 for cancelled task", s.Stats().TotalCompleted)
+	}
+	if s.Cancel("t1") {
+		t.Error("Cancel() after release = true, want false")
+	}
+}
+
+// failRun dequeues the next task and reports its run as failed.
+func failRun(t *testing.T, s *Scheduler) (*QueuedTask, error) {
+	t.Helper()
+	qt := s.Dequeue()
+	if qt == nil {
+		t.Fatal("Dequeue() = nil")
+	}
+	return qt, s.Requeue(*qt, errors.New("executor crashed"))
+}
+
+func TestRequeuePenalty(t *testing.T) {
+	for requeues, want := range []int{0, 1, 3, 7, 15} {
+		if got := RequeuePenalty(requeues); got != want {
+			t.Errorf("RequeuePenalty(%d) = %d, want %d", requeues, got, want)
+		}
+	}
+}
+
+func TestScheduler_Requeue_SinksInPriority(t *testing.T) {
+	s := newTestScheduler(t)
+	s.Enqueue(domain.Task{ID: "flaky", Priority: P1High}, domain.TaskRouting{})
+	for i := 0; i < 3; i++ {
+		if _, err := failRun(t, s); err != nil {
+			t.Fatalf("Requeue #%d error: %v", i+1, err)
+		}
+	}
+
+	// P1 + 7 classes of penalty now ranks below fresh spot work.
+	s.Enqueue(domain.Task{ID: "spot", Priority: P4Spot}, domain.TaskRouting{})
+	s.Enqueue(domain.Task{ID: "normal", Priority: P2Normal}, domain.TaskRouting{})
+	var order []string
+	for qt := s.Dequeue(); qt != nil; qt = s.Dequeue() {
+		order = append(order, qt.Task.ID)
+		if qt.Task.ID == "flaky" && (qt.Task.Requeues != 3 || qt.Task.Error != "executor crashed") {
+			t.Errorf("flaky task = %+v, want 3 requeues and the last error", qt.Task)
+		}
+	}
+	if got := fmt.Sprint(order); got != "[normal spot flaky]" {
+		t.Errorf("dequeue order = %s, want [normal spot flaky]", got)
+	}
+}
+
+func TestScheduler_Requeue_DeadLetters(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxRequeues = 2
+	s := NewScheduler(cfg)
+	s.Enqueue(domain.Task{ID: "doomed", Priority: P2Normal}, domain.TaskRouting{})
+
+	for i := 0; i < 2; i++ {
+		if _, err := failRun(t, s); err != nil {
+			t.Fatalf("Requeue #%d error: %v", i+1, err)
+		}
+	}
+	if _, err := failRun(t, s); !errors.Is(err, domain.ErrTaskDeadLettered) {
+		t.Fatalf("Requeue past the max = %v, want ErrTaskDeadLettered", err)
+	}
+
+	if s.QueueDepth() != 0 {
+		t.Errorf("QueueDepth() = %d, want 0", s.QueueDepth())
+	}
+	dead := s.DeadLetters()
+	if len(dead) != 1 || dead[0].Task.ID != "doomed" || dead[0].Task.Requeues != 2 {
+		t.Fatalf("DeadLetters() = %+v, want the doomed task after 2 requeues", dead)
+	}
+}
+
+func TestScheduler_Requeue_CancelledTaskReleased(t *testing.T) {
+	s := newTestScheduler(t)
+	s.Enqueue(domain.Task{ID: "t1", Priority: P2Normal}, domain.TaskRouting{})
+	qt := s.Dequeue()
+	s.Cancel("t1")
+
+	if err := s.Requeue(*qt, nil); !errors.Is(err, domain.ErrTaskCancelled) {
+		t.Errorf("Requeue(cancelled) = %v, want ErrTaskCancelled", err)
+	}
+	if s.QueueDepth() != 0 || s.IsCancelled("t1") {
+		t.Error("cancelled task should be released, not re-queued")
+	}
+}
+
+func TestScheduler_Cancel_Unknown(t *testing.T) {
+	s := newTestScheduler(t)
+	if s.Cancel("nope") {
+		t.Error("Cancel(unknown) = true, want false")
+	}
+}
+
+// ─── Tracing ────────────────────────────────────────────────────────────────
+
+func newTracedScheduler(t *testing.T) (*Scheduler, *observability.Tracer) {
+	t.Helper()
+	tracer := observability.NewTracer(observability.DefaultTracerConfig())
+	s := newTestScheduler(t)
+	s.SetTracer(tracer)
+	return s, tracer
+}
+
+// spansByOp indexes recorded spans by operation name.
+func spansByOp(tracer *observability.Tracer) map[string]observability.Span {
+	out := make(map[string]observability.Span)
+	for _, sp := range tracer.Spans(0) {
+		out[sp.Operation] = sp
+	}
+	return out
+}
+
+func TestScheduler_Tracing_CompletedTask(t *testing.T) {
+	s, tracer := newTracedScheduler(t)
+	routing := domain.TaskRouting{RegionAffinity: []domain.RegionID{"eu-west"}}
+	if err := s.Enqueue(domain.Task{ID: "t1", Type: domain.TaskInference, Priority: P1High}, routing); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond) // queue wait
+	if s.Dequeue() == nil {
+		t.Fatal("Dequeue() = nil")
+	}
+	if tracer.SpanCount() != 1 {
+		t.Fatalf("spans after dequeue = %d, want only the queue-wait span", tracer.SpanCount())
+	}
+
+	// Downstream execution joins the task's trace.
+	exec := tracer.StartSpan(s.TraceContext("t1"), "executor.run", nil)
+	time.Sleep(20 * time.Millisecond)
+	tracer.EndSpan(exec, nil)
+	if err := s.MarkTaskCompleted("t1"); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := spansByOp(tracer)
+	task, wait, run := spans[SpanTask], spans[SpanQueueWait], spans["executor.run"]
+	if task.SpanID == "" || wait.SpanID == "" {
+		t.Fatalf("spans = %+v, want task and queue-wait spans", spans)
+	}
+	if wait.TraceID != task.TraceID || wait.ParentID != task.SpanID {
+		t.Error("queue-wait span should be a child of the task span")
+	}
+	if run.TraceID != task.TraceID || run.ParentID != task.SpanID {
+		t.Error("execution span should join the task's trace")
+	}
+	if wait.Duration < 20*time.Millisecond {
+		t.Errorf("queue wait = %v, want ≥ 20ms", wait.Duration)
+	}
+	if task.Duration < wait.Duration+20*time.Millisecond {
+		t.Errorf("task span = %v, want ≥ wait (%v) + execution", task.Duration, wait.Duration)
+	}
+	if task.StartTime.After(wait.StartTime) || task.EndTime.Before(wait.EndTime) {
+		t.Error("task span should enclose the queue-wait span")
+	}
+	want := map[string]string{
+		"task.id": "t1", "task.type": string(domain.TaskInference), "priority": "1",
+		"band": "HIGH", "sla.tier": string(domain.SLAStandard), "region": "eu-west", "outcome": "completed",
+	}
+	for k, v := range want {
+		if task.Attrs[k] != v {
+			t.Errorf("attr %s = %q, want %q", k, task.Attrs[k], v)
+		}
+	}
+	if task.Status != observability.SpanOK {
+		t.Errorf("status = %v, want OK", task.Status)
+	}
+	if ctx := s.TraceContext("t1"); ctx != context.Background() {
+		t.Error("TraceContext should be empty once the task finished")
+	}
+}
+
+func TestScheduler_Tracing_CancelledTask(t *testing.T) {
+	s, tracer := newTracedScheduler(t)
+	s.Enqueue(domain.Task{ID: "t1", Priority: P2Normal}, domain.TaskRouting{})
+	s.Cancel("t1")
+
+	spans := spansByOp(tracer)
+	for _, op := range []string{SpanTask, SpanQueueWait} {
+		if sp := spans[op]; sp.Status != observability.SpanError {
+			t.Errorf("%s status = %v, want error for a cancelled task", op, sp.Status)
+		}
+	}
+	if got := spans[SpanTask].Attrs["outcome"]; got != "cancelled" {
+		t.Errorf("outcome = %q, want cancelled", got)
+	}
+}
+
+func TestScheduler_Tracing_TimedOutTask(t *testing.T) {
+	s, tracer := newTracedScheduler(t)
+	s.Enqueue(domain.Task{ID: "t1", Priority: P2Normal}, domain.TaskRouting{})
+	s.Dequeue()
+
+	if n := s.releaseTimedOut(time.Now()); n != 0 {
+		t.Fatalf("released %d tasks before the timeout, want 0", n)
+	}
+	if n := s.releaseTimedOut(time.Now().Add(2 * time.Hour)); n != 1 {
+		t.Fatalf("released %d tasks after the timeout, want 1", n)
+	}
+	task := spansByOp(tracer)[SpanTask]
+	if task.Status != observability.SpanError || task.Attrs["outcome"] != "timed_out" {
+		t.Errorf("task span = %v/%q, want error/timed_out", task.Status, task.Attrs["outcome"])
+	}
+	if st := s.Stats(); st.TotalTimedOut != 1 {
+		t.Errorf("TotalTimedOut = %d, want 1", st.TotalTimedOut)
+	}
+	if got := s.tierLoadLocked(domain.SLAStandard); got != 0 {
+		t.Errorf("standard load = %d, want the timed-out task no longer running", got)
+	}
+}
+
+func TestScheduler_Tracing_Optional(t *testing.T) {
+	s := newTestScheduler(t)
+	s.Enqueue(domain.Task{ID: "t1", Priority: P2Normal}, domain.TaskRouting{})
+	s.Dequeue()
+	if err := s.MarkTaskCompleted("t1"); err != nil {
+		t.Fatal(err)
+	}
+	if ctx := s.TraceContext("t1"); ctx != context.Background() {
+		t.Error("untraced task should have an empty trace context")
+	}
+}
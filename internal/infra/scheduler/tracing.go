@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/tutu-network/tutu/internal/infra/observability"
+)
+
+// ─── Tracing ────────────────────────────────────────────────────────────────
+// With a tracer attached, every enqueued task gets a "scheduler.task" span
+// covering enqueue to completion, and a child "scheduler.queue_wait" span
+// covering enqueue to dequeue. Executors continue the trace through
+// TraceContext. A task that is never completed or re-queued has its spans
+// ended by Run once Config.DispatchTimeout passes.
+
+// TaskTracer records task spans. *observability.Tracer implements it.
+type TaskTracer interface {
+	StartSpan(ctx context.Context, operation string, attrs map[string]string) *observability.Span
+	EndSpan(span *observability.Span, err error)
+}
+
+// Span operation names.
+const (
+	SpanTask      = "scheduler.task"
+	SpanQueueWait = "scheduler.queue_wait"
+)
+
+// taskSpans are the open spans of one task and the tracer that started them.
+type taskSpans struct {
+	tracer TaskTracer
+	task   *observability.Span
+	wait   *observability.Span // nil once dequeued
+}
+
+// SetTracer attaches a tracer for task spans. nil disables tracing for
+// tasks enqueued afterwards; tasks already traced still finish their spans.
+func (s *Scheduler) SetTracer(t TaskTracer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tracer = t
+}
+
+// TraceContext returns a context carrying the task span's trace and span
+// IDs, so downstream execution spans join the task's trace. Returns
+// context.Background() for untraced or finished tasks.
+func (s *Scheduler) TraceContext(taskID string) context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sp, ok := s.spans[taskID]
+	if !ok {
+		return context.Background()
+	}
+	return spanContext(sp.task)
+}
+
+func spanContext(span *observability.Span) context.Context {
+	ctx := observability.WithTraceID(context.Background(), span.TraceID)
+	return observability.WithSpanID(ctx, span.SpanID)
+}
+
+// startSpansLocked opens the task and queue-wait spans for a newly queued
+// task. Caller must hold s.mu.
+func (s *Scheduler) startSpansLocked(qt QueuedTask) {
+	if s.tracer == nil {
+		return
+	}
+	band := s.band(qt.Task.Priority)
+	attrs := map[string]string{
+		"task.id":   qt.Task.ID,
+		"task.type": string(qt.Task.Type),
+		"priority":  strconv.Itoa(band),
+		"band":      s.PriorityLabel(band),
+		"sla.tier":  string(BandTier(band, s.config.Bands)),
+	}
+	if region := qt.Routing.PreferredRegion(); region != "" {
+		attrs["region"] = string(region)
+	}
+	task := s.tracer.StartSpan(context.Background(), SpanTask, attrs)
+	wait := s.tracer.StartSpan(spanContext(task), SpanQueueWait, map[string]string{"task.id": qt.Task.ID})
+	s.spans[qt.Task.ID] = &taskSpans{tracer: s.tracer, task: task, wait: wait}
+}
+
+// endWaitSpanLocked closes a task's queue-wait span at dequeue. Caller must
+// hold s.mu.
+func (s *Scheduler) endWaitSpanLocked(taskID string) {
+	sp, ok := s.spans[taskID]
+	if !ok || sp.wait == nil {
+		return
+	}
+	sp.tracer.EndSpan(sp.wait, nil)
+	sp.wait = nil
+}
+
+// restartWaitSpanLocked opens a fresh queue-wait span for a re-queued task
+// under its still-open task span. Caller must hold s.mu.
+func (s *Scheduler) restartWaitSpanLocked(taskID string) {
+	sp, ok := s.spans[taskID]
+	if !ok || sp.wait != nil {
+		return
+	}
+	sp.wait = sp.tracer.StartSpan(spanContext(sp.task), SpanQueueWait, map[string]string{"task.id": taskID})
+}
+
+// endSpansLocked closes all of a task's open spans, recording how the task
+// left this scheduler ("completed", "cancelled", "stolen", "timed_out");
+// err marks the spans failed. Caller must hold s.mu.
+func (s *Scheduler) endSpansLocked(taskID, outcome string, err error) {
+	sp, ok := s.spans[taskID]
+	if !ok {
+		return
+	}
+	if sp.task.Attrs == nil {
+		sp.task.Attrs = make(map[string]string)
+	}
+	sp.task.Attrs["outcome"] = outcome
+	if sp.wait != nil {
+		sp.tracer.EndSpan(sp.wait, err)
+	}
+	sp.tracer.EndSpan(sp.task, err)
+	delete(s.spans, taskID)
+}
@@ -31,9 +31,18 @@
 package selfheal
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/healing"
 )
 
 // ─── Configuration ──────────────────────────────────────────────────────────
@@ -52,14 +61,82 @@ type Config struct {
 	// to confirm recovery before escalating.
 	VerificationTimeout time.Duration
 
+	// VerificationProbes is how many consecutive healthy verification
+	// probes an incident needs before it is resolved, so a node that
+	// recovers only briefly is not closed out. RunVerification spaces
+	// probes VerificationInterval apart. Values below 1 mean 1.
+	VerificationProbes   int
+	VerificationInterval time.Duration
+
 	// IncidentTTL is how long resolved/escalated incidents are retained.
 	IncidentTTL time.Duration
 
 	// MaxActiveIncidents caps concurrent incidents to prevent cascading.
 	MaxActiveIncidents int
 
+	// DetectLimitPerNode and DetectLimitGlobal cap how many Detect calls are
+	// processed per DetectWindow, for one node and across all nodes, so a
+	// runaway detector cannot flood the mesh. Excess calls are dropped and
+	// counted in MeshStats.DroppedDetections. 0 = unlimited.
+	DetectLimitPerNode int
+	DetectLimitGlobal  int
+	DetectWindow       time.Duration
+
 	// Now is an injectable clock for testing.
 	Now func() time.Time
+
+	// Recorder receives incident lifecycle metrics. nil disables metrics.
+	Recorder Recorder
+
+	// Executor runs runbook actions for RunRemediation. nil leaves action
+	// execution to the caller (Remediate + RecordActionComplete).
+	Executor ActionExecutor
+
+	// Prober runs the health checks for RunVerification. nil leaves
+	// verification to the caller (Verify).
+	Prober HealthProber
+
+	// Quarantine isolates nodes: Isolate quarantines the incident's node
+	// and a healthy Verify releases it. nil makes isolation a state label
+	// only.
+	Quarantine Quarantiner
+}
+
+// Quarantiner places nodes in and out of quarantine.
+// *healing.QuarantineManager implements it. Methods are called with the
+// mesh lock held and must not call back into the Mesh.
+type Quarantiner interface {
+	Quarantine(nodeID string, reason healing.QuarantineReason) *healing.QuarantineRecord
+	Release(nodeID string)
+}
+
+// ActionExecutor carries out runbook actions against a node, so the Mesh can
+// drive remediation itself instead of relying on the caller to report back.
+type ActionExecutor interface {
+	// Execute performs one runbook action on nodeID. A non-nil error fails
+	// the current remediation attempt.
+	Execute(ctx context.Context, action RunbookAction, nodeID string) error
+}
+
+// HealthProber checks whether a remediated node is healthy again, e.g. by
+// sending it a known-good test task.
+type HealthProber interface {
+	// Probe runs one health check against nodeID. A non-nil error is an
+	// unhealthy result.
+	Probe(ctx context.Context, nodeID string) error
+}
+
+// Recorder receives self-healing metrics, keeping this package free of a
+// Prometheus dependency. Methods are called with the mesh lock held and
+// must not call back into the Mesh.
+type Recorder interface {
+	// SetActiveIncidents reports how many active incidents are in state.
+	// Called for every non-terminal state after each transition.
+	SetActiveIncidents(state IncidentState, count int)
+	// IncidentResolved records a resolution and its time to recovery.
+	IncidentResolved(failure FailureType, mttr time.Duration)
+	// IncidentEscalated records an incident handed to humans.
+	IncidentEscalated(failure FailureType)
 }
 
 // DefaultConfig returns production defaults.
@@ -68,8 +145,13 @@ func DefaultConfig() Config {
 		MaxRemediationAttempts: 3,
 		IsolationTimeout:       2 * time.Minute,
 		VerificationTimeout:    1 * time.Minute,
+		VerificationProbes:     1,
+		VerificationInterval:   10 * time.Second,
 		IncidentTTL:            24 * time.Hour,
 		MaxActiveIncidents:     100,
+		DetectLimitPerNode:     10,
+		DetectLimitGlobal:      1000,
+		DetectWindow:           time.Second,
 		Now:                    time.Now,
 	}
 }
@@ -129,6 +211,20 @@ const (
 	FailHeartbeatLost   FailureType = "HEARTBEAT_LOST"    // Node stopped sending heartbeats
 )
 
+// quarantineReason maps a failure to the quarantine reason recorded when
+// its node is isolated. Failing tasks and corrupt models make results
+// untrustworthy; resource and connectivity failures are anomalies.
+func quarantineReason(f FailureType) healing.QuarantineReason {
+	switch f {
+	case FailHighErrorRate:
+		return healing.QuarantineTaskFailures
+	case FailModelCorrupt:
+		return healing.QuarantineVerificationFail
+	default:
+		return healing.QuarantineAnomaly
+	}
+}
+
 // ─── Runbook ────────────────────────────────────────────────────────────────
 
 // RunbookAction is a single step in a remediation runbook.
@@ -227,21 +323,43 @@ func DefaultRunbooks() map[FailureType]Runbook {
 
 // Incident represents a single detected problem and its resolution lifecycle.
 type Incident struct {
-	ID              string        // unique incident ID
-	NodeID          string        // affected node
-	FailureType     FailureType   // what went wrong
-	State           IncidentState // current lifecycle state
-	Attempts        int           // remediation attempts so far
-	DrainedTasks    int           // how many tasks were migrated
-	DetectedAt      time.Time     // when detected
-	IsolatedAt      time.Time     // when isolated
-	RemediatedAt    time.Time     // when remediation was attempted
-	VerifiedAt      time.Time     // when verification completed
-	ResolvedAt      time.Time     // when resolved or escalated
-	CurrentAction   string        // which runbook step is executing
-	ActionsComplete []string      // completed action names
-	Error           string        // last error message (if escalated)
-	MTTR            time.Duration // mean time to recovery (detection → resolution)
+	ID              string          // unique incident ID
+	NodeID          string          // affected node
+	FailureType     FailureType     // what went wrong
+	State           IncidentState   // current lifecycle state
+	Attempts        int             // remediation attempts so far
+	DrainedTasks    int             // how many tasks were migrated
+	DetectedAt      time.Time       // when detected
+	IsolatedAt      time.Time       // when isolated
+	RemediatedAt    time.Time       // when remediation was attempted
+	VerifiedAt      time.Time       // when verification completed
+	ProbesPassed    int             // healthy probes so far in the current verification
+	ResolvedAt      time.Time       // when resolved or escalated
+	CurrentAction   string          // which runbook step is executing
+	ActionsComplete []string        // completed action names
+	Error           string          // last error message (if escalated)
+	MTTR            time.Duration   // mean time to recovery (detection → resolution)
+	Quarantined     bool            // node quarantined by Isolate, not yet released
+	Timeline        []TimelineEvent // ordered lifecycle events for post-mortems
+	Notes           []IncidentNote  // operator notes for the next responder (see Annotate)
+}
+
+// IncidentNote is a timestamped operator note on an incident.
+type IncidentNote struct {
+	At   time.Time `json:"at"`
+	Text string    `json:"text"`
+}
+
+// TimelineEvent is a single timestamped step in an incident's lifecycle.
+type TimelineEvent struct {
+	At     time.Time `json:"at"`
+	Event  string    `json:"event"`            // DETECTED, ISOLATED, REMEDIATING, ACTION, ACTION_FAILED, VERIFIED, RESOLVED, ESCALATED
+	Detail string    `json:"detail,omitempty"` // action name, attempt number, error
+}
+
+// record appends a timeline event. Caller must hold the mesh lock.
+func (inc *Incident) record(at time.Time, event, detail string) {
+	inc.Timeline = append(inc.Timeline, TimelineEvent{At: at, Event: event, Detail: detail})
 }
 
 // ─── Self-Healing Mesh ──────────────────────────────────────────────────────
@@ -267,6 +385,16 @@ type Mesh struct {
 	totalMTTR    time.Duration
 	resolvedCnt  int64
 	escalatedCnt int64
+
+	// Most recent resolved MTTRs (ring buffer) for percentile stats.
+	mttrSamples []time.Duration
+	mttrIdx     int
+
+	// Detection rate limiting (see Config.DetectLimitPerNode).
+	windowStart   time.Time
+	globalDetects int
+	nodeDetects   map[string]int // nodeID → detections this window
+	droppedCnt    int64
 }
 
 // NewMesh creates a new autonomous self-healing mesh.
@@ -280,12 +408,18 @@ func NewMesh(cfg Config) *Mesh {
 	if cfg.VerificationTimeout <= 0 {
 		cfg.VerificationTimeout = 1 * time.Minute
 	}
+	if cfg.VerificationProbes < 1 {
+		cfg.VerificationProbes = 1
+	}
 	if cfg.IncidentTTL <= 0 {
 		cfg.IncidentTTL = 24 * time.Hour
 	}
 	if cfg.MaxActiveIncidents <= 0 {
 		cfg.MaxActiveIncidents = 100
 	}
+	if cfg.DetectWindow <= 0 {
+		cfg.DetectWindow = time.Second
+	}
 	if cfg.Now == nil {
 		cfg.Now = time.Now
 	}
@@ -296,6 +430,7 @@ func NewMesh(cfg Config) *Mesh {
 		resolved:      make([]*Incident, 10_000),
 		rCap:          10_000,
 		nodeIncidents: make(map[string]string),
+		nodeDetects:   make(map[string]int),
 	}
 }
 
@@ -304,23 +439,31 @@ func NewMesh(cfg Config) *Mesh {
 // Detect creates a new incident for a detected failure on a node.
 // If the node already has an active incident, returns it instead of creating a duplicate.
 // Returns the incident and true if it's newly created.
+//
+// Detections over the rate limit are dropped: the node's active incident is
+// returned if it has one, else nil.
 func (m *Mesh) Detect(nodeID string, failureType FailureType) (*Incident, bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	now := m.cfg.Now()
+	allowed := m.allowDetectLocked(nodeID, now)
+
 	// Check for existing active incident on this node.
 	if existingID, ok := m.nodeIncidents[nodeID]; ok {
 		if inc, found := m.active[existingID]; found {
 			return inc, false
 		}
 	}
+	if !allowed {
+		return nil, false
+	}
 
 	// Check active incident cap.
 	if len(m.active) >= m.cfg.MaxActiveIncidents {
 		return nil, false
 	}
 
-	now := m.cfg.Now()
 	m.idSeq++
 	id := fmt.Sprintf("INC-%06d", m.idSeq)
 
@@ -331,12 +474,33 @@ func (m *Mesh) Detect(nodeID string, failureType FailureType) (*Incident, bool)
 		State:       StateDetected,
 		DetectedAt:  now,
 	}
+	inc.record(now, "DETECTED", string(failureType))
 
 	m.active[id] = inc
 	m.nodeIncidents[nodeID] = id
+	m.reportActiveLocked()
 	return inc, true
 }
 
+// allowDetectLocked charges one detection on nodeID against the current
+// window's limits, reporting false (and counting a drop) when either limit
+// is spent. Windows are aligned to DetectWindow. Caller must hold m.mu.
+func (m *Mesh) allowDetectLocked(nodeID string, now time.Time) bool {
+	if start := now.Truncate(m.cfg.DetectWindow); !start.Equal(m.windowStart) {
+		m.windowStart = start
+		m.globalDetects = 0
+		clear(m.nodeDetects)
+	}
+	if (m.cfg.DetectLimitGlobal > 0 && m.globalDetects >= m.cfg.DetectLimitGlobal) ||
+		(m.cfg.DetectLimitPerNode > 0 && m.nodeDetects[nodeID] >= m.cfg.DetectLimitPerNode) {
+		m.droppedCnt++
+		return false
+	}
+	m.globalDetects++
+	m.nodeDetects[nodeID]++
+	return true
+}
+
 // ─── Core: Isolate ──────────────────────────────────────────────────────────
 
 // Isolate transitions an incident from Detected → Isolating, optionally
@@ -356,6 +520,15 @@ func (m *Mesh) Isolate(incidentID string, drainedTasks int) error {
 	inc.State = StateIsolating
 	inc.IsolatedAt = m.cfg.Now()
 	inc.DrainedTasks = drainedTasks
+	detail := fmt.Sprintf("drained %d tasks", drainedTasks)
+	if q := m.cfg.Quarantine; q != nil {
+		reason := quarantineReason(inc.FailureType)
+		q.Quarantine(inc.NodeID, reason)
+		inc.Quarantined = true
+		detail += fmt.Sprintf(", quarantined (%s)", reason)
+	}
+	inc.record(inc.IsolatedAt, "ISOLATED", detail)
+	m.reportActiveLocked()
 	return nil
 }
 
@@ -364,7 +537,7 @@ func (m *Mesh) Isolate(incidentID string, drainedTasks int) error {
 // Remediate transitions an incident from Isolating → Remediating.
 // It looks up the runbook for the failure type and begins executing it.
 // Returns the runbook actions to execute. The caller should execute them
-// and then call Verify().
+// and then call Verify(), or let RunRemediation execute them instead.
 func (m *Mesh) Remediate(incidentID string) ([]RunbookAction, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -384,6 +557,7 @@ func (m *Mesh) Remediate(incidentID string) ([]RunbookAction, error) {
 		inc.Error = "no runbook for failure type: " + string(inc.FailureType)
 		inc.ResolvedAt = m.cfg.Now()
 		inc.MTTR = inc.ResolvedAt.Sub(inc.DetectedAt)
+		inc.record(inc.ResolvedAt, "ESCALATED", inc.Error)
 		m.finalizeLocked(inc)
 		return nil, fmt.Errorf("no runbook for %s — escalated", inc.FailureType)
 	}
@@ -391,6 +565,8 @@ func (m *Mesh) Remediate(incidentID string) ([]RunbookAction, error) {
 	inc.State = StateRemediating
 	inc.RemediatedAt = m.cfg.Now()
 	inc.Attempts++
+	inc.record(inc.RemediatedAt, "REMEDIATING", fmt.Sprintf("attempt %d", inc.Attempts))
+	m.reportActiveLocked()
 
 	return rb.Actions, nil
 }
@@ -406,13 +582,17 @@ func (m *Mesh) RecordActionComplete(incidentID, actionName string) error {
 	}
 	inc.ActionsComplete = append(inc.ActionsComplete, actionName)
 	inc.CurrentAction = actionName
+	inc.record(m.cfg.Now(), "ACTION", actionName)
 	return nil
 }
 
 // ─── Core: Verify ───────────────────────────────────────────────────────────
 
-// Verify transitions from Remediating → Verifying, then checks if the
-// problem is actually fixed. Pass `healthy=true` if verification succeeded.
+// Verify records one verification probe result, moving the incident from
+// Remediating to Verifying. Pass `healthy=true` if the probe succeeded. The
+// incident is resolved once VerificationProbes healthy probes have been
+// recorded in a row; until then it stays in Verifying. An unhealthy probe
+// fails the remediation attempt, which is retried or escalated.
 func (m *Mesh) Verify(incidentID string, healthy bool) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -421,40 +601,223 @@ func (m *Mesh) Verify(incidentID string, healthy bool) error {
 	if !ok {
 		return fmt.Errorf("incident %s not found", incidentID)
 	}
-	if inc.State != StateRemediating {
-		return fmt.Errorf("incident %s in state %s, expected REMEDIATING", incidentID, inc.State)
+	if inc.State != StateRemediating && inc.State != StateVerifying {
+		return fmt.Errorf("incident %s in state %s, expected REMEDIATING or VERIFYING", incidentID, inc.State)
 	}
+	m.verifyLocked(inc, healthy, "")
+	return nil
+}
 
+// verifyLocked applies one probe result; cause, if set, explains an
+// unhealthy one. Must be called with m.mu held.
+func (m *Mesh) verifyLocked(inc *Incident, healthy bool, cause string) {
 	now := m.cfg.Now()
+	if inc.State == StateRemediating {
+		inc.ProbesPassed = 0
+	}
 	inc.State = StateVerifying
 	inc.VerifiedAt = now
+	inc.record(now, "VERIFIED", fmt.Sprintf("healthy=%t", healthy))
 
 	if healthy {
+		inc.ProbesPassed++
+		if inc.ProbesPassed < m.cfg.VerificationProbes {
+			m.reportActiveLocked()
+			return
+		}
 		// Fix worked — resolve!
 		inc.State = StateResolved
 		inc.ResolvedAt = now
 		inc.MTTR = now.Sub(inc.DetectedAt)
-		m.totalMTTR += inc.MTTR
-		m.resolvedCnt++
+		inc.record(now, "RESOLVED", "")
+		if inc.Quarantined && m.cfg.Quarantine != nil {
+			m.cfg.Quarantine.Release(inc.NodeID)
+			inc.Quarantined = false
+		}
+		m.recordMTTRLocked(inc.MTTR)
 		m.finalizeLocked(inc)
-		return nil
+		return
 	}
 
 	// Fix didn't work — retry or escalate.
+	if cause == "" && inc.ProbesPassed > 0 {
+		cause = fmt.Sprintf("verification probe %d failed", inc.ProbesPassed+1)
+	}
+	inc.ProbesPassed = 0
+	m.failAttemptLocked(inc, now, cause)
+}
+
+// failAttemptLocked ends a failed remediation attempt: the incident returns
+// to Isolating for another attempt, or is escalated once attempts are
+// exhausted. cause, if set, is appended to the escalation error.
+// Must be called with m.mu held.
+func (m *Mesh) failAttemptLocked(inc *Incident, now time.Time, cause string) {
 	if inc.Attempts >= m.cfg.MaxRemediationAttempts {
 		inc.State = StateEscalated
 		inc.ResolvedAt = now
 		inc.Error = fmt.Sprintf("exhausted %d remediation attempts", inc.Attempts)
+		if cause != "" {
+			inc.Error += " (last: " + cause + ")"
+		}
 		inc.MTTR = now.Sub(inc.DetectedAt)
+		inc.record(now, "ESCALATED", inc.Error)
 		m.escalatedCnt++
 		m.finalizeLocked(inc)
-		return nil
+		return
 	}
 
 	// Return to isolating for another attempt.
 	inc.State = StateIsolating
 	inc.IsolatedAt = now
-	return nil
+	m.reportActiveLocked()
+}
+
+// ─── Core: Automated Remediation ───────────────────────────────────────────
+
+// RunRemediation drives an isolated incident through its runbook using the
+// configured ActionExecutor. Actions run in order; each success is recorded
+// as with RecordActionComplete. A failed action fails the attempt, and the
+// runbook is retried from the top until MaxRemediationAttempts, after which
+// the incident is escalated.
+//
+// On success the incident is left in Remediating, awaiting Verify with the
+// result of a health check. Returns an error if no executor is configured,
+// the incident cannot be remediated, it was escalated, or ctx is done.
+func (m *Mesh) RunRemediation(ctx context.Context, incidentID string) error {
+	exec := m.cfg.Executor
+	if exec == nil {
+		return fmt.Errorf("no action executor configured")
+	}
+
+	for {
+		actions, err := m.Remediate(incidentID)
+		if err != nil {
+			return err
+		}
+
+		retry, err := m.runActions(ctx, exec, incidentID, actions)
+		if err != nil || !retry {
+			return err
+		}
+	}
+}
+
+// runActions executes one remediation attempt. retry reports that an action
+// failed and the incident went back to Isolating for another attempt; err is
+// set if the incident was escalated, vanished, or ctx was cancelled.
+func (m *Mesh) runActions(ctx context.Context, exec ActionExecutor, incidentID string, actions []RunbookAction) (retry bool, err error) {
+	m.mu.RLock()
+	inc, ok := m.active[incidentID]
+	var nodeID string
+	if ok {
+		nodeID = inc.NodeID
+	}
+	m.mu.RUnlock()
+	if !ok {
+		return false, fmt.Errorf("incident %s not found", incidentID)
+	}
+
+	for _, action := range actions {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		// Execute without the lock — actions may be slow (drains, restarts).
+		execErr := exec.Execute(ctx, action, nodeID)
+
+		m.mu.Lock()
+		inc, ok := m.active[incidentID]
+		if !ok || inc.State != StateRemediating {
+			m.mu.Unlock()
+			return false, fmt.Errorf("incident %s no longer remediating", incidentID)
+		}
+		now := m.cfg.Now()
+		if execErr != nil {
+			cause := fmt.Sprintf("%s: %v", action.Name, execErr)
+			inc.record(now, "ACTION_FAILED", cause)
+			m.failAttemptLocked(inc, now, cause)
+			escalated, reason := inc.State == StateEscalated, inc.Error
+			m.mu.Unlock()
+			if escalated {
+				return false, fmt.Errorf("incident %s escalated: %s", incidentID, reason)
+			}
+			return true, nil
+		}
+		inc.ActionsComplete = append(inc.ActionsComplete, action.Name)
+		inc.CurrentAction = action.Name
+		inc.record(now, "ACTION", action.Name)
+		m.mu.Unlock()
+	}
+	return false, nil
+}
+
+// ─── Core: Automated Verification ──────────────────────────────────────────
+
+// RunVerification checks a remediated incident with the configured
+// HealthProber, running up to VerificationProbes probes VerificationInterval
+// apart and recording each as with Verify. Returns nil once the incident is
+// resolved. The first unhealthy probe fails the remediation attempt: the
+// incident goes back to Isolating for another RunRemediation, or is
+// escalated, and an error describing the failure is returned. Also returns
+// an error if no prober is configured, the incident is not awaiting
+// verification, or ctx is done.
+func (m *Mesh) RunVerification(ctx context.Context, incidentID string) error {
+	prober := m.cfg.Prober
+	if prober == nil {
+		return fmt.Errorf("no health prober configured")
+	}
+
+	for probe := 1; ; probe++ {
+		m.mu.RLock()
+		inc, ok := m.active[incidentID]
+		var nodeID string
+		var state IncidentState
+		if ok {
+			nodeID, state = inc.NodeID, inc.State
+		}
+		m.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("incident %s not found", incidentID)
+		}
+		if state != StateRemediating && state != StateVerifying {
+			return fmt.Errorf("incident %s in state %s, expected REMEDIATING or VERIFYING", incidentID, state)
+		}
+
+		if probe > 1 && m.cfg.VerificationInterval > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(m.cfg.VerificationInterval):
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// Probe without the lock — a test task may take a while.
+		probeErr := prober.Probe(ctx, nodeID)
+
+		m.mu.Lock()
+		inc, ok = m.active[incidentID]
+		if !ok || (inc.State != StateRemediating && inc.State != StateVerifying) {
+			m.mu.Unlock()
+			return fmt.Errorf("incident %s no longer awaiting verification", incidentID)
+		}
+		cause := ""
+		if probeErr != nil {
+			cause = fmt.Sprintf("verification probe %d: %v", probe, probeErr)
+		}
+		m.verifyLocked(inc, probeErr == nil, cause)
+		state, reason := inc.State, inc.Error
+		m.mu.Unlock()
+
+		switch state {
+		case StateResolved:
+			return nil
+		case StateEscalated:
+			return fmt.Errorf("incident %s escalated: %s", incidentID, reason)
+		case StateIsolating:
+			return fmt.Errorf("incident %s failed %s", incidentID, cause)
+		}
+	}
 }
 
 // finalizeLocked moves an incident from active to resolved history.
@@ -469,6 +832,34 @@ func (m *Mesh) finalizeLocked(inc *Incident) {
 		m.rIdx = 0
 		m.rFull = true
 	}
+
+	if rec := m.cfg.Recorder; rec != nil {
+		switch inc.State {
+		case StateResolved:
+			rec.IncidentResolved(inc.FailureType, inc.MTTR)
+		case StateEscalated:
+			rec.IncidentEscalated(inc.FailureType)
+		}
+	}
+	m.reportActiveLocked()
+}
+
+// reportActiveLocked publishes active incident counts for every
+// non-terminal state. Must be called with m.mu held.
+func (m *Mesh) reportActiveLocked() {
+	rec := m.cfg.Recorder
+	if rec == nil {
+		return
+	}
+	var counts [StateResolved]int
+	for _, inc := range m.active {
+		if inc.State < StateResolved {
+			counts[inc.State]++
+		}
+	}
+	for state, n := range counts {
+		rec.SetActiveIncidents(IncidentState(state), n)
+	}
 }
 
 // ─── Escalate (manual) ─────────────────────────────────────────────────────
@@ -488,6 +879,7 @@ func (m *Mesh) Escalate(incidentID, reason string) error {
 	inc.Error = reason
 	inc.ResolvedAt = now
 	inc.MTTR = now.Sub(inc.DetectedAt)
+	inc.record(now, "ESCALATED", reason)
 	m.escalatedCnt++
 	m.finalizeLocked(inc)
 	return nil
@@ -535,12 +927,57 @@ func (m *Mesh) ActiveIncidentCount() int {
 	return len(m.active)
 }
 
-// GetIncident returns an active incident by ID.
+// GetIncident returns an incident by ID: an active one, or a resolved or
+// escalated one still within IncidentTTL.
 func (m *Mesh) GetIncident(id string) (*Incident, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	inc, ok := m.active[id]
-	return inc, ok
+	return m.lookupLocked(id)
+}
+
+// lookupLocked finds an active incident, or a terminal one resolved within
+// IncidentTTL. Must be called with m.mu held.
+func (m *Mesh) lookupLocked(id string) (*Incident, bool) {
+	if inc, ok := m.active[id]; ok {
+		return inc, true
+	}
+	cutoff := m.cfg.Now().Add(-m.cfg.IncidentTTL)
+	count := m.rIdx
+	if m.rFull {
+		count = m.rCap
+	}
+	idx := m.rIdx
+	for i := 0; i < count; i++ {
+		idx--
+		if idx < 0 {
+			idx = m.rCap - 1
+		}
+		if inc := m.resolved[idx]; inc.ID == id {
+			return inc, inc.ResolvedAt.After(cutoff)
+		}
+	}
+	return nil, false
+}
+
+// Annotate appends a timestamped operator note to an incident so the next
+// responder sees what was already investigated. Active incidents accept
+// notes, as do resolved or escalated ones within IncidentTTL; notes carry
+// over into history and exports.
+func (m *Mesh) Annotate(incidentID, note string) error {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return fmt.Errorf("note for incident %s is empty", incidentID)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inc, ok := m.lookupLocked(incidentID)
+	if !ok {
+		return fmt.Errorf("incident %s not found", incidentID)
+	}
+	inc.Notes = append(inc.Notes, IncidentNote{At: m.cfg.Now(), Text: note})
+	return nil
 }
 
 // NodeHasActiveIncident returns true if the given node has an active incident.
@@ -559,8 +996,12 @@ type MeshStats struct {
 	TotalResolved      int64         // total successfully resolved
 	TotalEscalated     int64         // total escalated to humans
 	AvgMTTR            time.Duration // average mean time to recovery
+	P50MTTR            time.Duration // median MTTR over recent resolutions
+	P90MTTR            time.Duration // 90th percentile MTTR over recent resolutions
+	P99MTTR            time.Duration // 99th percentile MTTR over recent resolutions
 	ResolutionRate     float64       // resolved / (resolved + escalated) × 100
 	RegisteredRunbooks int           // number of runbooks available
+	DroppedDetections  int64         // Detect calls dropped by the rate limit
 }
 
 // Stats returns current self-healing statistics.
@@ -579,13 +1020,18 @@ func (m *Mesh) Stats() MeshStats {
 		resRate = float64(m.resolvedCnt) / float64(total) * 100.0
 	}
 
+	sorted := m.sortedMTTRLocked()
 	return MeshStats{
 		ActiveIncidents:    len(m.active),
 		TotalResolved:      m.resolvedCnt,
 		TotalEscalated:     m.escalatedCnt,
 		AvgMTTR:            avgMTTR,
+		P50MTTR:            mttrPercentile(sorted, 50),
+		P90MTTR:            mttrPercentile(sorted, 90),
+		P99MTTR:            mttrPercentile(sorted, 99),
 		ResolutionRate:     resRate,
 		RegisteredRunbooks: len(m.runbooks),
+		DroppedDetections:  m.droppedCnt,
 	}
 }
 
@@ -602,6 +1048,55 @@ func (m *Mesh) GatePassed(maxMTTR time.Duration, minResolutionPct float64) bool
 	return st.AvgMTTR <= maxMTTR && st.ResolutionRate >= minResolutionPct
 }
 
+// GatePassedPercentile is GatePassed judged on the pct-th percentile MTTR
+// (e.g. 99) instead of the average, so a few slow recoveries fail the gate
+// even when fast ones pull the mean under maxMTTR.
+func (m *Mesh) GatePassedPercentile(pct float64, maxMTTR time.Duration, minResolutionPct float64) bool {
+	m.mu.RLock()
+	sorted := m.sortedMTTRLocked()
+	m.mu.RUnlock()
+
+	st := m.Stats()
+	if st.TotalResolved == 0 {
+		return false
+	}
+	return mttrPercentile(sorted, pct) <= maxMTTR && st.ResolutionRate >= minResolutionPct
+}
+
+// maxMTTRSamples bounds the resolved MTTRs kept for percentile stats.
+const maxMTTRSamples = 1024
+
+// recordMTTRLocked counts a resolved incident's MTTR.
+// Must be called with m.mu held.
+func (m *Mesh) recordMTTRLocked(mttr time.Duration) {
+	m.totalMTTR += mttr
+	m.resolvedCnt++
+	if len(m.mttrSamples) < maxMTTRSamples {
+		m.mttrSamples = append(m.mttrSamples, mttr)
+		return
+	}
+	m.mttrSamples[m.mttrIdx] = mttr
+	m.mttrIdx = (m.mttrIdx + 1) % maxMTTRSamples
+}
+
+// sortedMTTRLocked returns a sorted copy of the MTTR samples.
+// Must be called with m.mu held.
+func (m *Mesh) sortedMTTRLocked() []time.Duration {
+	sorted := slices.Clone(m.mttrSamples)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// mttrPercentile returns the nearest-rank pct-th percentile of sorted,
+// or 0 if it is empty.
+func mttrPercentile(sorted []time.Duration, pct float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(pct / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
 // ─── Recent History ─────────────────────────────────────────────────────────
 
 // ResolvedIncidents returns the most recent N resolved/escalated incidents.
@@ -634,6 +1129,150 @@ func (m *Mesh) ResolvedIncidents(limit int) []*Incident {
 	return result
 }
 
+// ─── Post-Mortem Export ─────────────────────────────────────────────────────
+
+// IncidentRecord is the serialized form of a terminal incident, one per
+// line in an export stream.
+type IncidentRecord struct {
+	ID              string          `json:"id"`
+	NodeID          string          `json:"node_id"`
+	FailureType     FailureType     `json:"failure_type"`
+	State           string          `json:"state"`
+	Attempts        int             `json:"attempts"`
+	DrainedTasks    int             `json:"drained_tasks"`
+	DetectedAt      time.Time       `json:"detected_at"`
+	IsolatedAt      time.Time       `json:"isolated_at"`
+	RemediatedAt    time.Time       `json:"remediated_at"`
+	VerifiedAt      time.Time       `json:"verified_at"`
+	ResolvedAt      time.Time       `json:"resolved_at"`
+	ActionsComplete []string        `json:"actions_complete,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	MTTRMs          int64           `json:"mttr_ms"`
+	Timeline        []TimelineEvent `json:"timeline"`
+	Notes           []IncidentNote  `json:"notes,omitempty"`
+}
+
+// ExportIncidents writes every resolved/escalated incident in history to w
+// as JSON lines, oldest first.
+func (m *Mesh) ExportIncidents(w io.Writer) error {
+	m.mu.RLock()
+	history := m.historyLocked()
+	m.mu.RUnlock()
+
+	enc := json.NewEncoder(w)
+	for _, inc := range history {
+		rec := IncidentRecord{
+			ID:              inc.ID,
+			NodeID:          inc.NodeID,
+			FailureType:     inc.FailureType,
+			State:           inc.State.String(),
+			Attempts:        inc.Attempts,
+			DrainedTasks:    inc.DrainedTasks,
+			DetectedAt:      inc.DetectedAt,
+			IsolatedAt:      inc.IsolatedAt,
+			RemediatedAt:    inc.RemediatedAt,
+			VerifiedAt:      inc.VerifiedAt,
+			ResolvedAt:      inc.ResolvedAt,
+			ActionsComplete: inc.ActionsComplete,
+			Error:           inc.Error,
+			MTTRMs:          inc.MTTR.Milliseconds(),
+			Timeline:        inc.Timeline,
+			Notes:           inc.Notes,
+		}
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("encode incident %s: %w", inc.ID, err)
+		}
+	}
+	return nil
+}
+
+// ImportIncidents reloads incident history previously written by
+// ExportIncidents. Imported incidents count toward MTTR and resolution
+// statistics. Returns the number of incidents imported.
+func (m *Mesh) ImportIncidents(r io.Reader) (int, error) {
+	var imported []*Incident
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var rec IncidentRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+		state, ok := parseTerminalState(rec.State)
+		if !ok {
+			return 0, fmt.Errorf("line %d: incident %s has non-terminal state %q", line, rec.ID, rec.State)
+		}
+		imported = append(imported, &Incident{
+			ID:              rec.ID,
+			NodeID:          rec.NodeID,
+			FailureType:     rec.FailureType,
+			State:           state,
+			Attempts:        rec.Attempts,
+			DrainedTasks:    rec.DrainedTasks,
+			DetectedAt:      rec.DetectedAt,
+			IsolatedAt:      rec.IsolatedAt,
+			RemediatedAt:    rec.RemediatedAt,
+			VerifiedAt:      rec.VerifiedAt,
+			ResolvedAt:      rec.ResolvedAt,
+			ActionsComplete: rec.ActionsComplete,
+			Error:           rec.Error,
+			MTTR:            time.Duration(rec.MTTRMs) * time.Millisecond,
+			Timeline:        rec.Timeline,
+			Notes:           rec.Notes,
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, inc := range imported {
+		m.resolved[m.rIdx] = inc
+		m.rIdx++
+		if m.rIdx >= m.rCap {
+			m.rIdx = 0
+			m.rFull = true
+		}
+		if inc.State == StateResolved {
+			m.recordMTTRLocked(inc.MTTR)
+		} else {
+			m.escalatedCnt++
+		}
+		// Keep new incident IDs from colliding with imported ones.
+		var seq int64
+		if _, err := fmt.Sscanf(inc.ID, "INC-%d", &seq); err == nil && seq > m.idSeq {
+			m.idSeq = seq
+		}
+	}
+	return len(imported), nil
+}
+
+// historyLocked returns the resolved ring buffer contents, oldest first.
+// Must be called with m.mu held.
+func (m *Mesh) historyLocked() []*Incident {
+	if !m.rFull {
+		return append([]*Incident(nil), m.resolved[:m.rIdx]...)
+	}
+	out := make([]*Incident, 0, m.rCap)
+	out = append(out, m.resolved[m.rIdx:]...)
+	return append(out, m.resolved[:m.rIdx]...)
+}
+
+func parseTerminalState(s string) (IncidentState, bool) {
+	switch s {
+	case StateResolved.String():
+		return StateResolved, true
+	case StateEscalated.String():
+		return StateEscalated, true
+	default:
+		return 0, false
+	}
+}
+
 // Reset clears all incidents and statistics.
 func (m *Mesh) Reset() {
 	m.mu.Lock()
@@ -647,5 +1286,12 @@ func (m *Mesh) Reset() {
 	m.nodeIncidents = make(map[string]string)
 	m.totalMTTR = 0
 	m.resolvedCnt = 0
+	m.mttrSamples = nil
+	m.mttrIdx = 0
 	m.escalatedCnt = 0
+	m.windowStart = time.Time{}
+	m.globalDetects = 0
+	clear(m.nodeDetects)
+	m.droppedCnt = 0
+	m.reportActiveLocked()
 }
@@ -1,8 +1,15 @@
 package selfheal
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/healing"
 )
 
 // ─── Helpers ────────────────────────────────────────────────────────────────
@@ -95,6 +102,74 @@ func TestDetect_MaxActiveIncidents(t *testing.T) {
 	}
 }
 
+func TestDetect_PerNodeRateLimit(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := base
+	cfg := testConfig(base)
+	cfg.Now = func() time.Time { return now }
+	cfg.DetectLimitPerNode = 3
+	cfg.DetectWindow = time.Second
+	m := NewMesh(cfg)
+
+	first, isNew := m.Detect("node-1", FailHighErrorRate)
+	if !isNew {
+		t.Fatal("first detection should create an incident")
+	}
+	for i := 0; i < 50; i++ {
+		inc, isNew := m.Detect("node-1", FailCPUOverload)
+		if isNew || inc != first {
+			t.Fatalf("detection %d = (%v, %v), want the existing incident", i, inc, isNew)
+		}
+	}
+	if got := m.Stats().DroppedDetections; got != 48 {
+		t.Errorf("DroppedDetections = %d, want 48 (50 over a limit of 3)", got)
+	}
+
+	// The throttled flood does not disturb the first incident.
+	if err := m.Isolate(first.ID, 2); err != nil {
+		t.Fatalf("Isolate() error: %v", err)
+	}
+	if inc, _ := m.GetIncident(first.ID); inc.State != StateIsolating {
+		t.Errorf("state = %v, want Isolating", inc.State)
+	}
+
+	// Other nodes have their own budget.
+	if _, isNew := m.Detect("node-2", FailDiskFull); !isNew {
+		t.Error("node-2 should not be throttled by node-1's detections")
+	}
+}
+
+func TestDetect_GlobalRateLimit(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := base
+	cfg := testConfig(base)
+	cfg.Now = func() time.Time { return now }
+	cfg.DetectLimitGlobal = 5
+	cfg.DetectWindow = time.Second
+	m := NewMesh(cfg)
+
+	created := 0
+	for i := 0; i < 10; i++ {
+		if _, isNew := m.Detect(fmt.Sprintf("node-%d", i), FailHeartbeatLost); isNew {
+			created++
+		}
+	}
+	if created != 5 {
+		t.Errorf("created %d incidents, want 5", created)
+	}
+	if inc, isNew := m.Detect("node-9", FailHeartbeatLost); inc != nil || isNew {
+		t.Errorf("throttled detection on a node without an incident = (%v, %v), want (nil, false)", inc, isNew)
+	}
+	if got := m.Stats().DroppedDetections; got != 6 {
+		t.Errorf("DroppedDetections = %d, want 6", got)
+	}
+
+	now = now.Add(time.Second)
+	if _, isNew := m.Detect("node-9", FailHeartbeatLost); !isNew {
+		t.Error("detection in the next window should go through")
+	}
+}
+
 func TestIsolate_TransitionsState(t *testing.T) {
 	m := NewMesh(DefaultConfig())
 	inc, _ := m.Detect("node-1", FailHighErrorRate)
@@ -123,6 +198,83 @@ func TestIsolate_WrongState(t *testing.T) {
 	}
 }
 
+func TestIsolate_QuarantinesNode(t *testing.T) {
+	qm := healing.NewQuarantineManager(healing.DefaultQuarantineConfig())
+	cfg := DefaultConfig()
+	cfg.Quarantine = qm
+	m := NewMesh(cfg)
+
+	inc, _ := m.Detect("node-1", FailGPUError)
+	if qm.IsQuarantined("node-1") {
+		t.Fatal("node quarantined before isolation")
+	}
+	if err := m.Isolate(inc.ID, 0); err != nil {
+		t.Fatalf("Isolate failed: %v", err)
+	}
+	rec := qm.ActiveQuarantine("node-1")
+	if rec == nil {
+		t.Fatal("isolated node should be quarantined")
+	}
+	if rec.Reason != healing.QuarantineAnomaly {
+		t.Errorf("reason = %q, want %q", rec.Reason, healing.QuarantineAnomaly)
+	}
+	if !inc.Quarantined {
+		t.Error("incident should record the quarantine")
+	}
+
+	m.Remediate(inc.ID)
+	if err := m.Verify(inc.ID, true); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if qm.IsQuarantined("node-1") {
+		t.Error("resolving the incident should release the quarantine")
+	}
+	if inc.Quarantined {
+		t.Error("incident should record the release")
+	}
+}
+
+func TestIsolate_QuarantineReasonByFailure(t *testing.T) {
+	tests := []struct {
+		failure FailureType
+		want    healing.QuarantineReason
+	}{
+		{FailHighErrorRate, healing.QuarantineTaskFailures},
+		{FailModelCorrupt, healing.QuarantineVerificationFail},
+		{FailHeartbeatLost, healing.QuarantineAnomaly},
+	}
+	for _, tt := range tests {
+		qm := healing.NewQuarantineManager(healing.DefaultQuarantineConfig())
+		cfg := DefaultConfig()
+		cfg.Quarantine = qm
+		m := NewMesh(cfg)
+		inc, _ := m.Detect("node-1", tt.failure)
+		m.Isolate(inc.ID, 0)
+		if rec := qm.ActiveQuarantine("node-1"); rec == nil || rec.Reason != tt.want {
+			t.Errorf("%s: quarantine = %+v, want reason %q", tt.failure, rec, tt.want)
+		}
+	}
+}
+
+func TestVerify_UnhealthyKeepsQuarantine(t *testing.T) {
+	qm := healing.NewQuarantineManager(healing.DefaultQuarantineConfig())
+	cfg := DefaultConfig()
+	cfg.MaxRemediationAttempts = 1
+	cfg.Quarantine = qm
+	m := NewMesh(cfg)
+
+	inc, _ := m.Detect("node-1", FailHighErrorRate)
+	m.Isolate(inc.ID, 0)
+	m.Remediate(inc.ID)
+	m.Verify(inc.ID, false)
+	if inc.State != StateEscalated {
+		t.Fatalf("state = %s, want ESCALATED", inc.State)
+	}
+	if !qm.IsQuarantined("node-1") {
+		t.Error("escalated node should stay quarantined")
+	}
+}
+
 func TestRemediate_ReturnsRunbookActions(t *testing.T) {
 	m := NewMesh(DefaultConfig())
 	inc, _ := m.Detect("node-1", FailHighErrorRate)
@@ -224,6 +376,215 @@ func TestRecordActionComplete(t *testing.T) {
 	}
 }
 
+// stubExecutor fails the named action for its first failTimes executions.
+type stubExecutor struct {
+	failAction string
+	failTimes  int
+	executed   []string
+}
+
+func (e *stubExecutor) Execute(_ context.Context, action RunbookAction, nodeID string) error {
+	e.executed = append(e.executed, action.Name)
+	if action.Name == e.failAction && e.failTimes > 0 {
+		e.failTimes--
+		return errors.New("node unreachable")
+	}
+	return nil
+}
+
+func TestRunRemediation_AllActionsSucceed(t *testing.T) {
+	cfg := testConfig(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	exec := &stubExecutor{}
+	cfg.Executor = exec
+	m := NewMesh(cfg)
+
+	inc, _ := m.Detect("node-1", FailHighErrorRate)
+	m.Isolate(inc.ID, 0)
+	if err := m.RunRemediation(context.Background(), inc.ID); err != nil {
+		t.Fatalf("RunRemediation: %v", err)
+	}
+
+	want := len(DefaultRunbooks()[FailHighErrorRate].Actions)
+	if len(inc.ActionsComplete) != want || len(exec.executed) != want {
+		t.Errorf("completed %d / executed %d actions, want %d", len(inc.ActionsComplete), len(exec.executed), want)
+	}
+	if inc.State != StateRemediating {
+		t.Fatalf("state = %s, want REMEDIATING awaiting verification", inc.State)
+	}
+	if err := m.Verify(inc.ID, true); err != nil || inc.State != StateResolved {
+		t.Errorf("verify: err=%v state=%s, want RESOLVED", err, inc.State)
+	}
+}
+
+func TestRunRemediation_FailedActionRetries(t *testing.T) {
+	cfg := testConfig(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	actions := DefaultRunbooks()[FailHighErrorRate].Actions
+	exec := &stubExecutor{failAction: actions[1].Name, failTimes: 1}
+	cfg.Executor = exec
+	m := NewMesh(cfg)
+
+	inc, _ := m.Detect("node-1", FailHighErrorRate)
+	m.Isolate(inc.ID, 0)
+	if err := m.RunRemediation(context.Background(), inc.ID); err != nil {
+		t.Fatalf("RunRemediation: %v", err)
+	}
+
+	if inc.Attempts != 2 {
+		t.Errorf("attempts = %d, want 2 (one failed, one retried)", inc.Attempts)
+	}
+	if inc.State != StateRemediating {
+		t.Errorf("state = %s, want REMEDIATING after successful retry", inc.State)
+	}
+	failed := 0
+	for _, ev := range inc.Timeline {
+		if ev.Event == "ACTION_FAILED" {
+			failed++
+			if !strings.Contains(ev.Detail, actions[1].Name) {
+				t.Errorf("ACTION_FAILED detail = %q, want action name", ev.Detail)
+			}
+		}
+	}
+	if failed != 1 {
+		t.Errorf("ACTION_FAILED events = %d, want 1", failed)
+	}
+}
+
+func TestRunRemediation_PersistentFailureEscalates(t *testing.T) {
+	cfg := testConfig(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	cfg.MaxRemediationAttempts = 2
+	actions := DefaultRunbooks()[FailHighErrorRate].Actions
+	exec := &stubExecutor{failAction: actions[0].Name, failTimes: 100}
+	cfg.Executor = exec
+	m := NewMesh(cfg)
+
+	inc, _ := m.Detect("node-1", FailHighErrorRate)
+	m.Isolate(inc.ID, 0)
+	if err := m.RunRemediation(context.Background(), inc.ID); err == nil {
+		t.Fatal("expected escalation error")
+	}
+
+	if inc.State != StateEscalated {
+		t.Fatalf("state = %s, want ESCALATED", inc.State)
+	}
+	if !strings.Contains(inc.Error, actions[0].Name) {
+		t.Errorf("error = %q, want failing action named", inc.Error)
+	}
+	if len(exec.executed) != 2 {
+		t.Errorf("executed %v, want only the failing first action twice", exec.executed)
+	}
+	if m.NodeHasActiveIncident("node-1") {
+		t.Error("escalated incident should no longer be active")
+	}
+}
+
+func TestRunRemediation_NoExecutor(t *testing.T) {
+	m := NewMesh(DefaultConfig())
+	inc, _ := m.Detect("node-1", FailHighErrorRate)
+	m.Isolate(inc.ID, 0)
+	if err := m.RunRemediation(context.Background(), inc.ID); err == nil {
+		t.Fatal("expected error without executor")
+	}
+	if inc.State != StateIsolating {
+		t.Errorf("state = %s, want untouched ISOLATING", inc.State)
+	}
+}
+
+// scriptedProber returns its results in order, then healthy.
+type scriptedProber struct {
+	results []error
+	probes  int
+}
+
+func (p *scriptedProber) Probe(context.Context, string) error {
+	p.probes++
+	if len(p.results) == 0 {
+		return nil
+	}
+	err := p.results[0]
+	p.results = p.results[1:]
+	return err
+}
+
+func TestRunVerification_RequiresEveryProbe(t *testing.T) {
+	cfg := testConfig(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	cfg.Executor = &stubExecutor{}
+	cfg.VerificationProbes = 2
+	prober := &scriptedProber{results: []error{nil, errors.New("test task timed out")}}
+	cfg.Prober = prober
+	m := NewMesh(cfg)
+	ctx := context.Background()
+
+	inc, _ := m.Detect("node-1", FailHighErrorRate)
+	m.Isolate(inc.ID, 0)
+	if err := m.RunRemediation(ctx, inc.ID); err != nil {
+		t.Fatalf("RunRemediation: %v", err)
+	}
+
+	// First probe passes, the required second fails: not resolved.
+	err := m.RunVerification(ctx, inc.ID)
+	if err == nil || !strings.Contains(err.Error(), "probe 2") {
+		t.Fatalf("RunVerification = %v, want probe 2 failure", err)
+	}
+	if inc.State != StateIsolating || !m.NodeHasActiveIncident("node-1") {
+		t.Fatalf("state = %s, want ISOLATING for another remediation attempt", inc.State)
+	}
+	if prober.probes != 2 {
+		t.Errorf("probes = %d, want 2", prober.probes)
+	}
+
+	// Remediation continues; this time both probes pass.
+	if err := m.RunRemediation(ctx, inc.ID); err != nil {
+		t.Fatalf("second RunRemediation: %v", err)
+	}
+	if err := m.RunVerification(ctx, inc.ID); err != nil {
+		t.Fatalf("second RunVerification: %v", err)
+	}
+	if inc.State != StateResolved || inc.Attempts != 2 {
+		t.Errorf("state = %s after %d attempts, want RESOLVED after 2", inc.State, inc.Attempts)
+	}
+	if prober.probes != 4 {
+		t.Errorf("probes = %d, want 4", prober.probes)
+	}
+}
+
+func TestVerify_AccumulatesProbes(t *testing.T) {
+	cfg := testConfig(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	cfg.VerificationProbes = 3
+	cfg.MaxRemediationAttempts = 1
+	m := NewMesh(cfg)
+
+	inc, _ := m.Detect("node-1", FailHighErrorRate)
+	m.Isolate(inc.ID, 0)
+	m.Remediate(inc.ID)
+	for i := 1; i <= 2; i++ {
+		if err := m.Verify(inc.ID, true); err != nil {
+			t.Fatalf("Verify %d: %v", i, err)
+		}
+		if inc.State != StateVerifying || inc.ProbesPassed != i {
+			t.Fatalf("after probe %d: state=%s passed=%d, want VERIFYING with %d", i, inc.State, inc.ProbesPassed, i)
+		}
+	}
+
+	// A late failure with no attempts left escalates instead of resolving.
+	m.Verify(inc.ID, false)
+	if inc.State != StateEscalated || !strings.Contains(inc.Error, "probe 3") {
+		t.Errorf("state=%s error=%q, want ESCALATED citing probe 3", inc.State, inc.Error)
+	}
+}
+
+func TestRunVerification_NoProber(t *testing.T) {
+	m := NewMesh(DefaultConfig())
+	inc, _ := m.Detect("node-1", FailHighErrorRate)
+	m.Isolate(inc.ID, 0)
+	m.Remediate(inc.ID)
+	if err := m.RunVerification(context.Background(), inc.ID); err == nil {
+		t.Fatal("expected error without prober")
+	}
+	if inc.State != StateRemediating {
+		t.Errorf("state = %s, want untouched REMEDIATING", inc.State)
+	}
+}
+
 func TestEscalate_Manual(t *testing.T) {
 	m := NewMesh(DefaultConfig())
 	inc, _ := m.Detect("node-1", FailHighErrorRate)
@@ -292,6 +653,88 @@ func TestFullLifecycle_MTTR(t *testing.T) {
 	}
 }
 
+// fakeRecorder captures Recorder calls.
+type fakeRecorder struct {
+	active    map[IncidentState]int
+	resolved  map[FailureType]int
+	escalated map[FailureType]int
+	mttrs     []time.Duration
+}
+
+func newFakeRecorder() *fakeRecorder {
+	return &fakeRecorder{
+		active:    make(map[IncidentState]int),
+		resolved:  make(map[FailureType]int),
+		escalated: make(map[FailureType]int),
+	}
+}
+
+func (r *fakeRecorder) SetActiveIncidents(state IncidentState, count int) { r.active[state] = count }
+func (r *fakeRecorder) IncidentEscalated(f FailureType)                   { r.escalated[f]++ }
+func (r *fakeRecorder) IncidentResolved(f FailureType, mttr time.Duration) {
+	r.resolved[f]++
+	r.mttrs = append(r.mttrs, mttr)
+}
+
+func TestRecorder_DetectToResolve(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	rec := newFakeRecorder()
+	cfg := testConfig(base)
+	cfg.Recorder = rec
+	m := NewMesh(cfg)
+
+	inc, _ := m.Detect("node-1", FailDiskFull)
+	if rec.active[StateDetected] != 1 {
+		t.Errorf("active[DETECTED] = %d, want 1", rec.active[StateDetected])
+	}
+
+	m.Isolate(inc.ID, 2)
+	if rec.active[StateDetected] != 0 || rec.active[StateIsolating] != 1 {
+		t.Errorf("after isolate: active = %v", rec.active)
+	}
+
+	m.Remediate(inc.ID)
+	if rec.active[StateIsolating] != 0 || rec.active[StateRemediating] != 1 {
+		t.Errorf("after remediate: active = %v", rec.active)
+	}
+
+	m.Verify(inc.ID, true)
+	for state, n := range rec.active {
+		if n != 0 {
+			t.Errorf("after resolve: active[%s] = %d, want 0", state, n)
+		}
+	}
+	if rec.resolved[FailDiskFull] != 1 {
+		t.Errorf("resolved[DISK_FULL] = %d, want 1", rec.resolved[FailDiskFull])
+	}
+	if len(rec.mttrs) != 1 || rec.mttrs[0] != 90*time.Second {
+		t.Errorf("MTTR observations = %v, want [1m30s]", rec.mttrs)
+	}
+	if len(rec.escalated) != 0 {
+		t.Errorf("escalated = %v, want none", rec.escalated)
+	}
+}
+
+func TestRecorder_Escalation(t *testing.T) {
+	rec := newFakeRecorder()
+	cfg := DefaultConfig()
+	cfg.Recorder = rec
+	m := NewMesh(cfg)
+
+	inc, _ := m.Detect("node-1", FailGPUError)
+	m.Escalate(inc.ID, "operator requested")
+
+	if rec.escalated[FailGPUError] != 1 {
+		t.Errorf("escalated[GPU_ERROR] = %d, want 1", rec.escalated[FailGPUError])
+	}
+	if len(rec.mttrs) != 0 {
+		t.Errorf("escalations must not observe MTTR, got %v", rec.mttrs)
+	}
+	if rec.active[StateDetected] != 0 {
+		t.Errorf("active[DETECTED] = %d, want 0", rec.active[StateDetected])
+	}
+}
+
 func TestGatePassed(t *testing.T) {
 	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	m := NewMesh(testConfig(base))
@@ -315,6 +758,80 @@ func TestGatePassed(t *testing.T) {
 	}
 }
 
+func TestStats_MTTRPercentiles(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(now)
+	cfg.Now = func() time.Time { return now }
+	m := NewMesh(cfg)
+
+	// 95 one-minute recoveries and 5 that take two hours.
+	for i := range 100 {
+		mttr := time.Minute
+		if i%20 == 19 {
+			mttr = 2 * time.Hour
+		}
+		inc, _ := m.Detect(fmt.Sprintf("node-%d", i), FailDiskFull)
+		m.Isolate(inc.ID, 0)
+		m.Remediate(inc.ID)
+		now = now.Add(mttr)
+		m.Verify(inc.ID, true)
+	}
+
+	st := m.Stats()
+	if st.AvgMTTR <= 5*time.Minute {
+		t.Fatalf("AvgMTTR = %v, want the slow tail to skew it past 5m", st.AvgMTTR)
+	}
+	if st.P50MTTR != time.Minute || st.P90MTTR != time.Minute {
+		t.Errorf("P50/P90 = %v/%v, want 1m", st.P50MTTR, st.P90MTTR)
+	}
+	if st.P99MTTR != 2*time.Hour {
+		t.Errorf("P99MTTR = %v, want 2h", st.P99MTTR)
+	}
+
+	if !m.GatePassedPercentile(90, 5*time.Minute, 95) {
+		t.Error("P90 gate should pass: 90% of recoveries took a minute")
+	}
+	if m.GatePassedPercentile(99, 5*time.Minute, 95) {
+		t.Error("P99 gate should fail on the two-hour tail")
+	}
+	if m.GatePassed(5*time.Minute, 95) {
+		t.Error("average gate should fail on the skewed mean")
+	}
+
+	m.Reset()
+	if st := m.Stats(); st.P99MTTR != 0 {
+		t.Errorf("P99MTTR after Reset = %v, want 0", st.P99MTTR)
+	}
+}
+
+func TestStats_MTTRSamplesBounded(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(now)
+	cfg.Now = func() time.Time { return now }
+	m := NewMesh(cfg)
+
+	// Early slow recoveries age out of the sample once enough fast ones follow.
+	for i := range maxMTTRSamples + 10 {
+		mttr := time.Minute
+		if i < 10 {
+			mttr = time.Hour
+		}
+		inc, _ := m.Detect(fmt.Sprintf("node-%d", i), FailDiskFull)
+		m.Isolate(inc.ID, 0)
+		m.Remediate(inc.ID)
+		now = now.Add(mttr)
+		m.Verify(inc.ID, true)
+	}
+
+	st := m.Stats()
+	if st.P99MTTR != time.Minute {
+		t.Errorf("P99MTTR = %v, want 1m once the slow samples are evicted", st.P99MTTR)
+	}
+	if st.AvgMTTR <= time.Minute {
+		t.Errorf("AvgMTTR = %v, want it to still count every resolution", st.AvgMTTR)
+	}
+}
+
 func TestNodeHasActiveIncident(t *testing.T) {
 	m := NewMesh(DefaultConfig())
 
@@ -348,6 +865,65 @@ func TestGetIncident(t *testing.T) {
 	}
 }
 
+func TestAnnotate_PersistsThroughResolution(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := NewMesh(testConfig(base))
+
+	inc, _ := m.Detect("node-1", FailGPUError)
+	if err := m.Annotate(inc.ID, "  driver reset did not help; check PCIe link  "); err != nil {
+		t.Fatalf("Annotate(active) failed: %v", err)
+	}
+	m.Escalate(inc.ID, "hardware fault")
+
+	if err := m.Annotate(inc.ID, "RMA filed"); err != nil {
+		t.Fatalf("Annotate(escalated) failed: %v", err)
+	}
+
+	resolved := m.ResolvedIncidents(1)
+	if len(resolved) != 1 || resolved[0].ID != inc.ID {
+		t.Fatalf("ResolvedIncidents = %v, want %s", resolved, inc.ID)
+	}
+	notes := resolved[0].Notes
+	if len(notes) != 2 {
+		t.Fatalf("notes = %+v, want 2", notes)
+	}
+	if notes[0].Text != "driver reset did not help; check PCIe link" {
+		t.Errorf("note text = %q, want it trimmed", notes[0].Text)
+	}
+	if notes[1].Text != "RMA filed" || !notes[1].At.After(notes[0].At) {
+		t.Errorf("second note = %+v, want later RMA note", notes[1])
+	}
+
+	got, ok := m.GetIncident(inc.ID)
+	if !ok || len(got.Notes) != 2 {
+		t.Errorf("GetIncident should return the escalated incident with its notes, got %v, %v", got, ok)
+	}
+}
+
+func TestAnnotate_Rejects(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(now)
+	cfg.Now = func() time.Time { return now }
+	m := NewMesh(cfg)
+
+	inc, _ := m.Detect("node-1", FailHighErrorRate)
+	if err := m.Annotate(inc.ID, "   "); err == nil {
+		t.Error("Annotate should reject an empty note")
+	}
+	if err := m.Annotate("INC-999999", "note"); err == nil {
+		t.Error("Annotate should reject an unknown incident")
+	}
+
+	m.Escalate(inc.ID, "paged")
+	now = now.Add(cfg.IncidentTTL + time.Minute)
+	if err := m.Annotate(inc.ID, "too late"); err == nil {
+		t.Error("Annotate should reject incidents resolved beyond IncidentTTL")
+	}
+	if _, ok := m.GetIncident(inc.ID); ok {
+		t.Error("GetIncident should not return incidents resolved beyond IncidentTTL")
+	}
+}
+
 func TestResolvedIncidents_RingBuffer(t *testing.T) {
 	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	m := NewMesh(testConfig(base))
@@ -417,3 +993,100 @@ func TestReset(t *testing.T) {
 		t.Error("expected 0 resolved and escalated after reset")
 	}
 }
+
+// ─── Post-Mortem Export ─────────────────────────────────────────────────────
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := NewMesh(testConfig(base))
+
+	inc, _ := m.Detect("node-1", FailHighErrorRate)
+	m.Isolate(inc.ID, 4)
+	m.Remediate(inc.ID)
+	m.RecordActionComplete(inc.ID, "drain_tasks")
+	m.RecordActionComplete(inc.ID, "quarantine_node")
+	m.Verify(inc.ID, true)
+
+	esc, _ := m.Detect("node-2", FailGPUError)
+	m.Escalate(esc.ID, "operator override")
+	m.Annotate(esc.ID, "replaced GPU")
+
+	var buf bytes.Buffer
+	if err := m.ExportIncidents(&buf); err != nil {
+		t.Fatalf("ExportIncidents failed: %v", err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Fatalf("exported %d lines, want 2", lines)
+	}
+
+	restored := NewMesh(testConfig(base))
+	n, err := restored.ImportIncidents(&buf)
+	if err != nil {
+		t.Fatalf("ImportIncidents failed: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("imported %d, want 2", n)
+	}
+
+	history := restored.ResolvedIncidents(10)
+	if len(history) != 2 {
+		t.Fatalf("history = %d, want 2", len(history))
+	}
+	got := history[1] // newest-first: the resolved incident is older
+	if got.ID != inc.ID || got.State != StateResolved {
+		t.Fatalf("got %s/%s, want %s/RESOLVED", got.ID, got.State, inc.ID)
+	}
+	if !got.DetectedAt.Equal(inc.DetectedAt) || !got.IsolatedAt.Equal(inc.IsolatedAt) ||
+		!got.RemediatedAt.Equal(inc.RemediatedAt) || !got.VerifiedAt.Equal(inc.VerifiedAt) ||
+		!got.ResolvedAt.Equal(inc.ResolvedAt) {
+		t.Error("lifecycle timestamps did not survive round-trip")
+	}
+	if got.MTTR != inc.MTTR {
+		t.Errorf("MTTR = %v, want %v", got.MTTR, inc.MTTR)
+	}
+	if got.DrainedTasks != 4 {
+		t.Errorf("drained = %d, want 4", got.DrainedTasks)
+	}
+	if len(got.ActionsComplete) != 2 {
+		t.Errorf("actions = %v, want 2 entries", got.ActionsComplete)
+	}
+
+	wantEvents := []string{"DETECTED", "ISOLATED", "REMEDIATING", "ACTION", "ACTION", "VERIFIED", "RESOLVED"}
+	if len(got.Timeline) != len(wantEvents) {
+		t.Fatalf("timeline = %d events, want %d", len(got.Timeline), len(wantEvents))
+	}
+	for i, ev := range wantEvents {
+		if got.Timeline[i].Event != ev {
+			t.Errorf("timeline[%d] = %s, want %s", i, got.Timeline[i].Event, ev)
+		}
+	}
+	if got.Timeline[3].Detail != "drain_tasks" {
+		t.Errorf("action detail = %q, want drain_tasks", got.Timeline[3].Detail)
+	}
+
+	if history[0].State != StateEscalated || history[0].Error != "operator override" {
+		t.Errorf("escalated incident = %s/%q", history[0].State, history[0].Error)
+	}
+	if len(history[0].Notes) != 1 || history[0].Notes[0].Text != "replaced GPU" {
+		t.Errorf("notes = %+v, want the operator note", history[0].Notes)
+	}
+
+	st := restored.Stats()
+	if st.TotalResolved != 1 || st.TotalEscalated != 1 {
+		t.Errorf("stats resolved=%d escalated=%d, want 1/1", st.TotalResolved, st.TotalEscalated)
+	}
+
+	// New incidents must not reuse imported IDs.
+	fresh, _ := restored.Detect("node-3", FailDiskFull)
+	if fresh.ID == inc.ID || fresh.ID == esc.ID {
+		t.Errorf("new incident reused imported ID %s", fresh.ID)
+	}
+}
+
+func TestImportIncidents_RejectsActiveState(t *testing.T) {
+	m := NewMesh(DefaultConfig())
+	_, err := m.ImportIncidents(strings.NewReader(`{"id":"INC-000001","state":"REMEDIATING"}` + "\n"))
+	if err == nil {
+		t.Error("should reject non-terminal incidents")
+	}
+}
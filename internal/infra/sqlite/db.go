@@ -16,7 +16,15 @@ import (
 
 // DB wraps a SQLite connection with WAL mode and migrations.
 type DB struct {
-	db *sql.DB
+	db   querier // the connection, or the open transaction inside Tx
+	conn *sql.DB
+}
+
+// querier is what repository methods need from *sql.DB and *sql.Tx.
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
 }
 
 // Open creates or opens the SQLite database at dir/state.db.
@@ -43,7 +51,7 @@ func Open(dir string) (*DB, error) {
 	db.SetMaxOpenConns(1) // SQLite is single-writer
 	db.SetMaxIdleConns(1)
 
-	d := &DB{db: db}
+	d := &DB{db: db, conn: db}
 	if err := d.migrate(); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("migrate: %w", err)
@@ -54,12 +62,31 @@ func Open(dir string) (*DB, error) {
 
 // Close cleanly shuts down the database.
 func (d *DB) Close() error {
-	return d.db.Close()
+	return d.conn.Close()
 }
 
 // Ping checks database connectivity.
 func (d *DB) Ping() error {
-	return d.db.Ping()
+	return d.conn.Ping()
+}
+
+// Tx runs fn against a DB bound to a single transaction, committing if fn
+// returns nil and rolling back otherwise. fn must only use the DB it is
+// given — the connection is held until Tx returns. Calling Tx on a
+// transaction-bound DB joins the outer transaction.
+func (d *DB) Tx(fn func(tx *DB) error) error {
+	if _, ok := d.db.(*sql.Tx); ok {
+		return fn(d)
+	}
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	if err := fn(&DB{db: tx, conn: d.conn}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
 // migrate runs idempotent schema migrations.
@@ -83,6 +110,12 @@ func (d *DB) migrate() error {
 			value TEXT NOT NULL
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_models_used ON models(last_used)`,
+		// Friendly model names ("llama3" → "llama-3.2-7b:Q4_K_M")
+		`CREATE TABLE IF NOT EXISTS model_aliases (
+			alias      TEXT PRIMARY KEY,
+			target     TEXT NOT NULL,
+			created_at INTEGER NOT NULL
+		)`,
 
 		// Phase 1: Credit ledger (double-entry bookkeeping — Architecture Part X)
 		`CREATE TABLE IF NOT EXISTS credit_ledger (
@@ -155,6 +188,12 @@ func (d *DB) migrate() error {
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_quests_expires ON quests(expires_at)`,
 
+		// Quests whose XP and credit rewards have been granted
+		`CREATE TABLE IF NOT EXISTS quest_rewards (
+			quest_id    TEXT PRIMARY KEY,
+			rewarded_at INTEGER NOT NULL
+		)`,
+
 		// Notification log (policy: max 1/day, quiet hours)
 		`CREATE TABLE IF NOT EXISTS notifications (
 			id         INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -301,6 +340,57 @@ func (d *DB) TouchModel(name string) error {
 	return err
 }
 
+// ─── Model Aliases ──────────────────────────────────────────────────────────
+
+// SetModelAlias creates or repoints an alias to a canonical model name.
+func (d *DB) SetModelAlias(alias, target string) error {
+	_, err := d.db.Exec(
+		`INSERT INTO model_aliases (alias, target, created_at) VALUES (?, ?, ?)
+		 ON CONFLICT(alias) DO UPDATE SET target=excluded.target`,
+		alias, target, time.Now().Unix(),
+	)
+	return err
+}
+
+// GetModelAlias returns the canonical model name for alias, or "" if none.
+func (d *DB) GetModelAlias(alias string) (string, error) {
+	var target string
+	err := d.db.QueryRow(`SELECT target FROM model_aliases WHERE alias = ?`, alias).Scan(&target)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return target, err
+}
+
+// DeleteModelAlias removes an alias. Returns false if it did not exist.
+func (d *DB) DeleteModelAlias(alias string) (bool, error) {
+	result, err := d.db.Exec(`DELETE FROM model_aliases WHERE alias = ?`, alias)
+	if err != nil {
+		return false, err
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// ListModelAliases returns all aliases as alias → canonical name.
+func (d *DB) ListModelAliases() (map[string]string, error) {
+	rows, err := d.db.Query(`SELECT alias, target FROM model_aliases`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := make(map[string]string)
+	for rows.Next() {
+		var alias, target string
+		if err := rows.Scan(&alias, &target); err != nil {
+			return nil, err
+		}
+		aliases[alias] = target
+	}
+	return aliases, rows.Err()
+}
+
 // ─── Node Info ──────────────────────────────────────────────────────────────
 
 // SetNodeInfo stores a key-value pair in node_info.
@@ -177,6 +177,44 @@ func (d *DB) CompleteQuest(id string) error {
 	return err
 }
 
+// MarkQuestRewarded records that a quest's rewards were granted. It returns
+// false if they already had been, so callers grant each reward only once.
+func (d *DB) MarkQuestRewarded(id string, at time.Time) (bool, error) {
+	result, err := d.db.Exec(
+		`INSERT OR IGNORE INTO quest_rewards (quest_id, rewarded_at) VALUES (?, ?)`,
+		id, at.Unix(),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// ListUnrewardedQuests returns completed quests whose rewards have not
+// been granted.
+func (d *DB) ListUnrewardedQuests() ([]domain.Quest, error) {
+	rows, err := d.db.Query(
+		`SELECT id, type, description, target, progress, reward_xp, reward_credits, expires_at, completed
+		 FROM quests WHERE completed = 1
+		 AND id NOT IN (SELECT quest_id FROM quest_rewards) ORDER BY expires_at ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quests []domain.Quest
+	for rows.Next() {
+		q, err := scanQuestRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		quests = append(quests, *q)
+	}
+	return quests, rows.Err()
+}
+
 // DeleteExpiredQuests removes quests that expired before the given time.
 func (d *DB) DeleteExpiredQuests(before time.Time) (int64, error) {
 	result, err := d.db.Exec(
@@ -1,6 +1,6 @@
 // Phase 3 SQLite schema and operations.
-// Persistence for regions, scheduler stats, circuit breakers, quarantines,
-// and earnings reports.
+// Persistence for regions, scheduler stats, the scheduler task queue,
+// circuit breakers, quarantines, and earnings reports.
 package sqlite
 
 import (
@@ -74,6 +74,18 @@ func Phase3Migrations() []string {
 			snapshot_at     TEXT NOT NULL DEFAULT (datetime('now'))
 		)`,
 
+		// Scheduler task queue — survives daemon restarts.
+		// state is 'queued' or 'in_progress'; completed tasks are deleted.
+		`CREATE TABLE IF NOT EXISTS task_queue (
+			task_id      TEXT PRIMARY KEY,
+			priority     INTEGER NOT NULL DEFAULT 0,
+			state        TEXT NOT NULL DEFAULT 'queued',
+			task_json    TEXT NOT NULL,
+			routing_json TEXT NOT NULL DEFAULT '{}',
+			queued_at    INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_task_queue_order ON task_queue(priority, queued_at)`,
+
 		// Model popularity tracking
 		`CREATE TABLE IF NOT EXISTS model_popularity (
 			model_name     TEXT PRIMARY KEY,
@@ -351,3 +363,76 @@ func (db *DB) InsertSchedulerSnapshot(queueDepth, backPressure int, totalEnqueue
 	`, queueDepth, backPressure, totalEnqueued, totalCompleted, totalRejected, totalStolen, totalPreempted)
 	return err
 }
+
+// ─── Task Queue Operations ──────────────────────────────────────────────────
+
+// Task queue states.
+const (
+	QueueStateQueued     = "queued"
+	QueueStateInProgress = "in_progress"
+)
+
+// QueuedTaskRecord is a persisted scheduler queue entry.
+// Task and routing payloads are stored as opaque JSON.
+type QueuedTaskRecord struct {
+	TaskID      string
+	Priority    int
+	State       string
+	TaskJSON    string
+	RoutingJSON string
+	QueuedAt    time.Time
+}
+
+// UpsertQueuedTask inserts or replaces a task in the queue with state 'queued'.
+func (db *DB) UpsertQueuedTask(rec QueuedTaskRecord) error {
+	_, err := db.db.Exec(`
+		INSERT INTO task_queue (task_id, priority, state, task_json, routing_json, queued_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(task_id) DO UPDATE SET
+			priority     = excluded.priority,
+			state        = excluded.state,
+			task_json    = excluded.task_json,
+			routing_json = excluded.routing_json,
+			queued_at    = excluded.queued_at
+	`, rec.TaskID, rec.Priority, QueueStateQueued, rec.TaskJSON, rec.RoutingJSON, rec.QueuedAt.UnixNano())
+	return err
+}
+
+// MarkQueuedTaskInProgress flags a queued task as handed to an executor.
+func (db *DB) MarkQueuedTaskInProgress(taskID string) error {
+	_, err := db.db.Exec(`
+		UPDATE task_queue SET state = ? WHERE task_id = ?
+	`, QueueStateInProgress, taskID)
+	return err
+}
+
+// DeleteQueuedTask removes a task from the persisted queue.
+func (db *DB) DeleteQueuedTask(taskID string) error {
+	_, err := db.db.Exec(`DELETE FROM task_queue WHERE task_id = ?`, taskID)
+	return err
+}
+
+// ListQueuedTasks returns all persisted queue entries ordered by
+// priority class, then by enqueue time (oldest first).
+func (db *DB) ListQueuedTasks() ([]QueuedTaskRecord, error) {
+	rows, err := db.db.Query(`
+		SELECT task_id, priority, state, task_json, routing_json, queued_at
+		FROM task_queue ORDER BY priority ASC, queued_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []QueuedTaskRecord
+	for rows.Next() {
+		var r QueuedTaskRecord
+		var queuedAt int64
+		if err := rows.Scan(&r.TaskID, &r.Priority, &r.State, &r.TaskJSON, &r.RoutingJSON, &queuedAt); err != nil {
+			return nil, err
+		}
+		r.QueuedAt = time.Unix(0, queuedAt)
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
@@ -305,3 +305,44 @@ func TestPhase3_InsertSchedulerSnapshot(t *testing.T) {
 		t.Fatalf("InsertSchedulerSnapshot() second error: %v", err)
 	}
 }
+
+// ─── Task Queue ─────────────────────────────────────────────────────────────
+
+func TestPhase3_TaskQueue_Lifecycle(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now()
+
+	recs := []QueuedTaskRecord{
+		{TaskID: "b", Priority: 2, TaskJSON: `{"id":"b"}`, RoutingJSON: `{}`, QueuedAt: now},
+		{TaskID: "a", Priority: 0, TaskJSON: `{"id":"a"}`, RoutingJSON: `{}`, QueuedAt: now.Add(time.Second)},
+		{TaskID: "c", Priority: 2, TaskJSON: `{"id":"c"}`, RoutingJSON: `{}`, QueuedAt: now.Add(2 * time.Second)},
+	}
+	for _, r := range recs {
+		if err := db.UpsertQueuedTask(r); err != nil {
+			t.Fatalf("UpsertQueuedTask(%s) error: %v", r.TaskID, err)
+		}
+	}
+	if err := db.MarkQueuedTaskInProgress("b"); err != nil {
+		t.Fatalf("MarkQueuedTaskInProgress() error: %v", err)
+	}
+	if err := db.DeleteQueuedTask("c"); err != nil {
+		t.Fatalf("DeleteQueuedTask() error: %v", err)
+	}
+
+	got, err := db.ListQueuedTasks()
+	if err != nil {
+		t.Fatalf("ListQueuedTasks() error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ListQueuedTasks() = %d, want 2", len(got))
+	}
+	if got[0].TaskID != "a" || got[1].TaskID != "b" {
+		t.Errorf("order = [%s %s], want [a b]", got[0].TaskID, got[1].TaskID)
+	}
+	if got[1].State != QueueStateInProgress {
+		t.Errorf("b state = %q, want %q", got[1].State, QueueStateInProgress)
+	}
+	if !got[0].QueuedAt.Equal(recs[1].QueuedAt) {
+		t.Errorf("QueuedAt = %v, want %v", got[0].QueuedAt, recs[1].QueuedAt)
+	}
+}
@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -196,6 +197,39 @@ func TestDeleteModel_NotFound(t *testing.T) {
 	}
 }
 
+// ─── Model Aliases ──────────────────────────────────────────────────────────
+
+func TestModelAlias_CRUD(t *testing.T) {
+	db := newTestDB(t)
+
+	if got, err := db.GetModelAlias("llama3:latest"); err != nil || got != "" {
+		t.Fatalf("GetModelAlias(missing) = %q, %v; want empty", got, err)
+	}
+
+	if err := db.SetModelAlias("llama3:latest", "llama-3.2-7b:Q4_K_M"); err != nil {
+		t.Fatalf("SetModelAlias() error: %v", err)
+	}
+	// Repoint
+	if err := db.SetModelAlias("llama3:latest", "llama-3.2-7b:Q8_0"); err != nil {
+		t.Fatalf("SetModelAlias(repoint) error: %v", err)
+	}
+	if got, _ := db.GetModelAlias("llama3:latest"); got != "llama-3.2-7b:Q8_0" {
+		t.Errorf("GetModelAlias() = %q, want llama-3.2-7b:Q8_0", got)
+	}
+
+	all, err := db.ListModelAliases()
+	if err != nil || len(all) != 1 {
+		t.Fatalf("ListModelAliases() = %v, %v; want 1 alias", all, err)
+	}
+
+	if ok, err := db.DeleteModelAlias("llama3:latest"); err != nil || !ok {
+		t.Fatalf("DeleteModelAlias() = %v, %v; want true", ok, err)
+	}
+	if ok, _ := db.DeleteModelAlias("llama3:latest"); ok {
+		t.Error("DeleteModelAlias(missing) = true, want false")
+	}
+}
+
 func TestTouchModel(t *testing.T) {
 	db := newTestDB(t)
 
@@ -272,3 +306,41 @@ func TestNodeInfo_NotFound(t *testing.T) {
 		t.Errorf("GetNodeInfo(missing) = %q, want empty", got)
 	}
 }
+
+// ─── Transactions ───────────────────────────────────────────────────────────
+
+func TestTx_CommitsAndRollsBack(t *testing.T) {
+	db := newTestDB(t)
+
+	err := db.Tx(func(tx *DB) error {
+		return tx.SetNodeInfo("committed", "yes")
+	})
+	if err != nil {
+		t.Fatalf("Tx() error: %v", err)
+	}
+
+	errBoom := errors.New("boom")
+	err = db.Tx(func(tx *DB) error {
+		if err := tx.SetNodeInfo("rolled_back", "yes"); err != nil {
+			return err
+		}
+		return tx.Tx(func(inner *DB) error { // nested call joins the outer transaction
+			if err := inner.SetNodeInfo("inner", "yes"); err != nil {
+				return err
+			}
+			return errBoom
+		})
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Tx() error = %v, want boom", err)
+	}
+
+	if got, _ := db.GetNodeInfo("committed"); got != "yes" {
+		t.Errorf("committed = %q, want yes", got)
+	}
+	for _, key := range []string{"rolled_back", "inner"} {
+		if got, _ := db.GetNodeInfo(key); got != "" {
+			t.Errorf("%s = %q, want rolled back", key, got)
+		}
+	}
+}
@@ -13,7 +13,10 @@
 package universal
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"sort"
 	"sync"
 	"time"
 
@@ -36,12 +39,22 @@ type Config struct {
 	// EducationDomains are recognized academic email domains.
 	EducationDomains []string
 
+	// EducationReminderLead is how long before ExpiresAt a re-verification
+	// reminder is sent. Zero disables reminders.
+	EducationReminderLead time.Duration
+
 	// GracePeriodMinutes: extra time given after quota exhaustion
 	// before requests are hard-rejected (allows finishing current work).
 	GracePeriodMinutes int
 
 	// DefaultTier is the tier assigned to new/anonymous users.
 	DefaultTier domain.AccessTier
+
+	// UpgradeThreshold is how many times a user must exhaust their daily
+	// quota within UpgradeWindow before SuggestUpgrade recommends a higher
+	// tier. Zero disables suggestions.
+	UpgradeThreshold int
+	UpgradeWindow    time.Duration
 }
 
 // DefaultConfig returns the architecture-specified tier settings.
@@ -53,8 +66,11 @@ func DefaultConfig() Config {
 			".edu", ".ac.uk", ".edu.au", ".ac.jp", ".edu.cn",
 			".edu.br", ".ac.in", ".edu.sg", ".ac.nz", ".edu.za",
 		},
-		GracePeriodMinutes: 5,
-		DefaultTier:        domain.AccessTierFree,
+		EducationReminderLead: 30 * 24 * time.Hour,
+		GracePeriodMinutes:    5,
+		DefaultTier:           domain.AccessTierFree,
+		UpgradeThreshold:      3,
+		UpgradeWindow:         7 * 24 * time.Hour,
 	}
 }
 
@@ -74,6 +90,16 @@ type AccessManager struct {
 
 	// Education verifications (userID → verification)
 	eduVerifications map[string]*domain.EducationVerification
+	// Users already reminded about their current verification's expiry
+	eduReminded map[string]bool
+	// Called once per verification as it nears expiry
+	reminder ReminderFunc
+
+	// Quota exhaustion times within UpgradeWindow (userID → times), the
+	// users' contact emails, and the hook told about upgrade suggestions
+	exhaustions   map[string][]time.Time
+	emails        map[string]string
+	upgradeNotify UpgradeFunc
 
 	// Aggregate statistics
 	totalFreeInferences       int64
@@ -91,6 +117,9 @@ func NewAccessManager(cfg Config) *AccessManager {
 		config:           cfg,
 		usage:            make(map[string]*domain.TierUsage),
 		eduVerifications: make(map[string]*domain.EducationVerification),
+		eduReminded:      make(map[string]bool),
+		exhaustions:      make(map[string][]time.Time),
+		emails:           make(map[string]string),
 		now:              time.Now,
 	}
 }
@@ -123,16 +152,30 @@ func (am *AccessManager) CheckAccess(userID string) error {
 }
 
 // RecordInference increments the usage counter for a user.
-// Call this AFTER a successful inference.
+// Call this AFTER a successful inference. The inference that uses up the
+// daily quota counts towards upgrade suggestions (see SuggestUpgrade).
 func (am *AccessManager) RecordInference(userID string, tokensUsed int64) {
 	am.mu.Lock()
-	defer am.mu.Unlock()
 
 	tier := am.userTier(userID)
 	usage := am.getOrCreateUsageLocked(userID, tier)
 	usage.InferencesToday++
 	usage.TokensToday += tokensUsed
 
+	var (
+		notify    UpgradeFunc
+		suggested domain.AccessTier
+		reason    string
+	)
+	if limit := am.config.Quotas[tier].MaxInferencesPerDay; limit > 0 && usage.InferencesToday == limit {
+		now := am.now()
+		am.exhaustions[userID] = append(am.recentExhaustionsLocked(userID, now), now)
+		var ok bool
+		if suggested, reason, ok = am.suggestLocked(userID, now); ok {
+			notify = am.upgradeNotify
+		}
+	}
+
 	// Update aggregate stats
 	switch tier {
 	case domain.AccessTierFree:
@@ -144,6 +187,12 @@ func (am *AccessManager) RecordInference(userID string, tokensUsed int64) {
 	case domain.AccessTierEnterprise:
 		am.totalEnterpriseInferences++
 	}
+	am.mu.Unlock()
+
+	// Notify outside the lock so the hook may call back into the manager.
+	if notify != nil {
+		notify(userID, suggested, reason)
+	}
 }
 
 // GetUsage returns the current usage for a user.
@@ -152,7 +201,9 @@ func (am *AccessManager) GetUsage(userID string) domain.TierUsage {
 	defer am.mu.RUnlock()
 
 	tier := am.userTier(userID)
-	return *am.getOrCreateUsage(userID, tier)
+	usage := *am.getOrCreateUsage(userID, tier)
+	usage.Tier = tier
+	return usage
 }
 
 // RemainingQuota returns how many inferences a user has left today.
@@ -179,6 +230,7 @@ func (am *AccessManager) SetUserTier(userID string, tier domain.AccessTier) erro
 	am.mu.Lock()
 	defer am.mu.Unlock()
 
+	delete(am.exhaustions, userID) // history under the old tier no longer applies
 	if usage, ok := am.usage[userID]; ok {
 		usage.Tier = tier
 	} else {
@@ -191,17 +243,37 @@ func (am *AccessManager) SetUserTier(userID string, tier domain.AccessTier) erro
 	return nil
 }
 
+// ChangeTier moves a user to a new tier, prorating today's remaining quota
+// (see domain.ProrateQuota). Unlike SetUserTier, usage already consumed
+// today counts against the new tier.
+func (am *AccessManager) ChangeTier(userID string, newTier domain.AccessTier) error {
+	if !newTier.IsValid() {
+		return fmt.Errorf("invalid tier: %q", newTier)
+	}
+
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	oldTier := am.userTier(userID)
+	from, ok := am.config.Quotas[oldTier]
+	if !ok {
+		return fmt.Errorf("unknown tier: %q", oldTier)
+	}
+	to, ok := am.config.Quotas[newTier]
+	if !ok {
+		return fmt.Errorf("unknown tier: %q", newTier)
+	}
+	to.Tier = newTier
+
+	usage := am.getOrCreateUsageLocked(userID, oldTier)
+	*usage = domain.ProrateQuota(*usage, from, to, am.now())
+	delete(am.exhaustions, userID)
+	return nil
+}
+
 // VerifyEducation records a successful education tier verification.
 func (am *AccessManager) VerifyEducation(userID, institution, email string) error {
-	// Validate email domain
-	validDomain := false
-	for _, d := range am.config.EducationDomains {
-		if len(email) > len(d) && email[len(email)-len(d):] == d {
-			validDomain = true
-			break
-		}
-	}
-	if !validDomain {
+	if !am.isEducationEmail(email) {
 		return domain.ErrEduTierUnverified
 	}
 
@@ -218,9 +290,12 @@ func (am *AccessManager) VerifyEducation(userID, institution, email string) erro
 		ExpiresAt:   now.AddDate(1, 0, 0), // 1 year
 	}
 
+	delete(am.eduReminded, userID)
+
 	// Upgrade tier
 	if usage, ok := am.usage[userID]; ok {
 		usage.Tier = domain.AccessTierEducation
+		delete(am.exhaustions, userID)
 	}
 
 	return nil
@@ -232,11 +307,183 @@ func (am *AccessManager) IsEducationVerified(userID string) bool {
 	defer am.mu.RUnlock()
 
 	if ev, ok := am.eduVerifications[userID]; ok {
-		return ev.IsVerified()
+		return ev.IsVerifiedAt(am.now())
 	}
 	return false
 }
 
+// ═══════════════════════════════════════════════════════════════════════════
+// Education Re-verification
+// ═══════════════════════════════════════════════════════════════════════════
+
+// ReminderFunc is notified when a user's education verification is about to
+// expire, so the user can be prompted to re-verify.
+type ReminderFunc func(ev domain.EducationVerification)
+
+// SetReminder sets the hook called by SweepVerifications for verifications
+// entering the Config.EducationReminderLead window.
+func (am *AccessManager) SetReminder(fn ReminderFunc) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.reminder = fn
+}
+
+// ExpiringVerifications returns active verifications that expire within the
+// given duration, soonest first.
+func (am *AccessManager) ExpiringVerifications(within time.Duration) []domain.EducationVerification {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return am.expiringLocked(am.now(), within)
+}
+
+// expiringLocked lists active verifications expiring before now+within
+// (caller must hold at least RLock).
+func (am *AccessManager) expiringLocked(now time.Time, within time.Duration) []domain.EducationVerification {
+	deadline := now.Add(within)
+	var result []domain.EducationVerification
+	for _, ev := range am.eduVerifications {
+		if ev.IsVerifiedAt(now) && !ev.ExpiresAt.After(deadline) {
+			result = append(result, *ev)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ExpiresAt.Before(result[j].ExpiresAt)
+	})
+	return result
+}
+
+// SweepVerifications sends a reminder for each verification entering the
+// reminder window (once per verification), and marks lapsed verifications
+// expired, downgrading their users from the education tier to free.
+// Call this periodically (e.g. daily alongside ResetDailyQuotas).
+func (am *AccessManager) SweepVerifications() (reminded, downgraded int) {
+	am.mu.Lock()
+	now := am.now()
+
+	for userID, ev := range am.eduVerifications {
+		if ev.Status != "verified" || ev.IsVerifiedAt(now) {
+			continue
+		}
+		ev.Status = "expired"
+		delete(am.eduReminded, userID)
+		if usage, ok := am.usage[userID]; ok && usage.Tier == domain.AccessTierEducation {
+			usage.Tier = domain.AccessTierFree
+			downgraded++
+		}
+	}
+
+	var due []domain.EducationVerification
+	if am.config.EducationReminderLead > 0 {
+		for _, ev := range am.expiringLocked(now, am.config.EducationReminderLead) {
+			if !am.eduReminded[ev.UserID] {
+				am.eduReminded[ev.UserID] = true
+				due = append(due, ev)
+			}
+		}
+	}
+	fn := am.reminder
+	am.mu.Unlock()
+
+	// Notify outside the lock so the hook may call back into the manager.
+	if fn != nil {
+		for _, ev := range due {
+			fn(ev)
+		}
+	}
+	return len(due), downgraded
+}
+
+// SweepInterval is how often Run sweeps verifications.
+const SweepInterval = time.Hour
+
+// Run calls SweepVerifications every interval until ctx is done. Call in
+// a goroutine.
+func (am *AccessManager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if reminded, downgraded := am.SweepVerifications(); reminded+downgraded > 0 {
+				log.Printf("[access] sent %d re-verification reminder(s), downgraded %d lapsed education user(s)", reminded, downgraded)
+			}
+		}
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Upgrade Suggestions
+// ═══════════════════════════════════════════════════════════════════════════
+
+// UpgradeFunc is notified when a user exhausts their quota often enough to
+// be suggested a higher tier (see SuggestUpgrade).
+type UpgradeFunc func(userID string, suggested domain.AccessTier, reason string)
+
+// SetUpgradeNotifier sets the hook called by RecordInference each time a
+// user exhausts their quota while an upgrade suggestion applies.
+func (am *AccessManager) SetUpgradeNotifier(fn UpgradeFunc) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.upgradeNotify = fn
+}
+
+// SetUserEmail records a user's contact email, used to spot users eligible
+// for the education tier.
+func (am *AccessManager) SetUserEmail(userID, email string) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.emails[userID] = email
+}
+
+// SuggestUpgrade recommends the next tier up for a user who exhausted their
+// daily quota at least Config.UpgradeThreshold times within
+// Config.UpgradeWindow. Free users with an academic email are pointed to
+// the education tier instead of pro. ok is false when no upgrade applies.
+func (am *AccessManager) SuggestUpgrade(userID string) (suggested domain.AccessTier, reason string, ok bool) {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return am.suggestLocked(userID, am.now())
+}
+
+// suggestLocked implements SuggestUpgrade (caller must hold at least RLock).
+func (am *AccessManager) suggestLocked(userID string, now time.Time) (domain.AccessTier, string, bool) {
+	if am.config.UpgradeThreshold <= 0 {
+		return "", "", false
+	}
+	n := len(am.recentExhaustionsLocked(userID, now))
+	if n < am.config.UpgradeThreshold {
+		return "", "", false
+	}
+	days := max(1, int(am.config.UpgradeWindow/(24*time.Hour)))
+	why := fmt.Sprintf("daily quota exhausted %d times in the last %d days", n, days)
+
+	switch am.userTier(userID) {
+	case domain.AccessTierFree:
+		if am.isEducationEmail(am.emails[userID]) {
+			return domain.AccessTierEducation, why + "; your academic email qualifies for the unlimited education tier", true
+		}
+		return domain.AccessTierPro, why, true
+	case domain.AccessTierPro:
+		return domain.AccessTierEnterprise, why, true
+	default:
+		return "", "", false // education and enterprise are unlimited
+	}
+}
+
+// recentExhaustionsLocked returns the user's quota exhaustions within
+// UpgradeWindow of now (caller must hold at least RLock).
+func (am *AccessManager) recentExhaustionsLocked(userID string, now time.Time) []time.Time {
+	times := am.exhaustions[userID]
+	cutoff := now.Add(-am.config.UpgradeWindow)
+	for len(times) > 0 && !times[0].After(cutoff) {
+		times = times[1:]
+	}
+	return times
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // Daily Reset
 // ═══════════════════════════════════════════════════════════════════════════
@@ -308,11 +555,19 @@ func (am *AccessManager) GetStats() Stats {
 // ═══════════════════════════════════════════════════════════════════════════
 
 // userTier returns the user's current tier (caller must hold at least RLock).
+// An education tier granted by a verification that has since lapsed counts
+// as free, even before SweepVerifications records the downgrade.
 func (am *AccessManager) userTier(userID string) domain.AccessTier {
-	if usage, ok := am.usage[userID]; ok {
-		return usage.Tier
+	usage, ok := am.usage[userID]
+	if !ok {
+		return am.config.DefaultTier
 	}
-	return am.config.DefaultTier
+	if usage.Tier == domain.AccessTierEducation {
+		if ev, ok := am.eduVerifications[userID]; ok && !ev.IsVerifiedAt(am.now()) {
+			return domain.AccessTierFree
+		}
+	}
+	return usage.Tier
 }
 
 // getOrCreateUsage returns usage for a user, creating if needed (RLock held).
@@ -342,6 +597,17 @@ func (am *AccessManager) getOrCreateUsageLocked(userID string, tier domain.Acces
 	return usage
 }
 
+// isEducationEmail reports whether email ends in a recognized academic
+// domain.
+func (am *AccessManager) isEducationEmail(email string) bool {
+	for _, d := range am.config.EducationDomains {
+		if len(email) > len(d) && email[len(email)-len(d):] == d {
+			return true
+		}
+	}
+	return false
+}
+
 // nextMidnightUTC returns the next midnight UTC time.
 func (am *AccessManager) nextMidnightUTC() time.Time {
 	now := am.now().UTC()
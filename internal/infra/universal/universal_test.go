@@ -208,6 +208,85 @@ func TestSetUserTier_UpdateExisting(t *testing.T) {
 // Education Verification Tests
 // ═══════════════════════════════════════════════════════════════════════════
 
+func TestChangeTier_UpgradeFreeToProMidDay(t *testing.T) {
+	am := NewAccessManager(DefaultConfig())
+	am.now = fixedTime // noon — half the day remains
+
+	for i := 0; i < 40; i++ {
+		am.RecordInference("user-1", 10)
+	}
+	if err := am.ChangeTier("user-1", domain.AccessTierPro); err != nil {
+		t.Fatalf("ChangeTier: %v", err)
+	}
+
+	usage := am.GetUsage("user-1")
+	if usage.Tier != domain.AccessTierPro {
+		t.Errorf("tier = %q, want pro", usage.Tier)
+	}
+	// 50 (half-day free) + 5000 (half-day pro) - 40 used.
+	if got := am.RemainingQuota("user-1"); got != 5010 {
+		t.Errorf("RemainingQuota() = %d, want 5010", got)
+	}
+	if err := am.CheckAccess("user-1"); err != nil {
+		t.Errorf("CheckAccess after upgrade: %v", err)
+	}
+}
+
+func TestChangeTier_DowngradeProToFreeWithPartialUsage(t *testing.T) {
+	am := NewAccessManager(DefaultConfig())
+	am.now = fixedTime
+	am.SetUserTier("user-1", domain.AccessTierPro)
+
+	for i := 0; i < 5020; i++ {
+		am.RecordInference("user-1", 1)
+	}
+	if err := am.ChangeTier("user-1", domain.AccessTierFree); err != nil {
+		t.Fatalf("ChangeTier: %v", err)
+	}
+	// 5000 (half-day pro) + 50 (half-day free) - 5020 used.
+	if got := am.RemainingQuota("user-1"); got != 30 {
+		t.Errorf("RemainingQuota() = %d, want 30", got)
+	}
+
+	for i := 0; i < 30; i++ {
+		am.RecordInference("user-1", 1)
+	}
+	if err := am.CheckAccess("user-1"); err != domain.ErrFreeTierExhausted {
+		t.Errorf("CheckAccess after prorated quota used = %v, want ErrFreeTierExhausted", err)
+	}
+}
+
+func TestChangeTier_UnlimitedToLimited(t *testing.T) {
+	am := NewAccessManager(DefaultConfig())
+	am.now = fixedTime
+	am.SetUserTier("user-1", domain.AccessTierEnterprise)
+	for i := 0; i < 20000; i++ {
+		am.RecordInference("user-1", 1)
+	}
+
+	if err := am.ChangeTier("user-1", domain.AccessTierPro); err != nil {
+		t.Fatalf("ChangeTier: %v", err)
+	}
+	// Unlimited usage is not charged; pro's rate applies to the rest of the day.
+	if got := am.RemainingQuota("user-1"); got != 5000 {
+		t.Errorf("RemainingQuota() = %d, want 5000", got)
+	}
+
+	if err := am.ChangeTier("user-1", domain.AccessTierEnterprise); err != nil {
+		t.Fatalf("ChangeTier back: %v", err)
+	}
+	if got := am.RemainingQuota("user-1"); got != -1 {
+		t.Errorf("RemainingQuota() = %d, want -1 (unlimited)", got)
+	}
+}
+
+func TestChangeTier_InvalidTier(t *testing.T) {
+	am := NewAccessManager(DefaultConfig())
+	if err := am.ChangeTier("user-1", "platinum"); err == nil {
+		t.Error("expected error for invalid tier")
+	}
+}
+
 func TestVerifyEducation_ValidDomain(t *testing.T) {
 	am := NewAccessManager(DefaultConfig())
 	am.now = fixedTime
@@ -241,6 +320,142 @@ func TestIsEducationVerified_NotVerified(t *testing.T) {
 	}
 }
 
+// ═══════════════════════════════════════════════════════════════════════════
+// Education Re-verification Tests
+// ═══════════════════════════════════════════════════════════════════════════
+
+func TestExpiringVerifications_WithinWindow(t *testing.T) {
+	am := NewAccessManager(DefaultConfig())
+	am.now = fixedTime
+	am.VerifyEducation("student-1", "MIT", "alice@mit.edu")
+
+	// Eleven months later the yearly verification expires within 60 days.
+	am.now = func() time.Time { return fixedTime().AddDate(0, 11, 0) }
+
+	expiring := am.ExpiringVerifications(60 * 24 * time.Hour)
+	if len(expiring) != 1 || expiring[0].UserID != "student-1" {
+		t.Fatalf("expected student-1 expiring, got %+v", expiring)
+	}
+	if got := am.ExpiringVerifications(7 * 24 * time.Hour); len(got) != 0 {
+		t.Fatalf("expected none expiring within 7 days, got %+v", got)
+	}
+}
+
+func TestSweepVerifications_RemindsOnce(t *testing.T) {
+	am := NewAccessManager(DefaultConfig())
+	am.now = fixedTime
+	am.VerifyEducation("student-1", "MIT", "alice@mit.edu")
+
+	var reminders []string
+	am.SetReminder(func(ev domain.EducationVerification) {
+		reminders = append(reminders, ev.UserID)
+	})
+
+	if reminded, _ := am.SweepVerifications(); reminded != 0 {
+		t.Fatalf("expected no reminder a year out, got %d", reminded)
+	}
+
+	am.now = func() time.Time { return fixedTime().AddDate(0, 11, 15) }
+	am.SweepVerifications()
+	am.SweepVerifications()
+	if len(reminders) != 1 || reminders[0] != "student-1" {
+		t.Fatalf("expected exactly one reminder for student-1, got %v", reminders)
+	}
+}
+
+func TestSweepVerifications_ExpiredDowngradesToFree(t *testing.T) {
+	am := NewAccessManager(DefaultConfig())
+	am.now = fixedTime
+	am.SetUserTier("student-1", domain.AccessTierFree)
+	am.VerifyEducation("student-1", "MIT", "alice@mit.edu")
+
+	if tier := am.GetUsage("student-1").Tier; tier != domain.AccessTierEducation {
+		t.Fatalf("expected education tier after verification, got %q", tier)
+	}
+
+	am.now = func() time.Time { return fixedTime().AddDate(1, 0, 1) }
+	if _, downgraded := am.SweepVerifications(); downgraded != 1 {
+		t.Fatalf("expected 1 downgrade, got %d", downgraded)
+	}
+	if tier := am.GetUsage("student-1").Tier; tier != domain.AccessTierFree {
+		t.Fatalf("expected free tier after expiry, got %q", tier)
+	}
+	if am.IsEducationVerified("student-1") {
+		t.Fatal("expected verification to have lapsed")
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Upgrade Suggestion Tests
+// ═══════════════════════════════════════════════════════════════════════════
+
+// exhaustDaily uses up userID's free quota on each of days consecutive
+// days, starting from day offset from of fixedTime.
+func exhaustDaily(am *AccessManager, userID string, from, days int) {
+	for d := from; d < from+days; d++ {
+		day := fixedTime().AddDate(0, 0, d)
+		am.now = func() time.Time { return day }
+		am.ResetDailyQuotas()
+		for i := 0; i < 100; i++ {
+			am.RecordInference(userID, 10)
+		}
+	}
+}
+
+func TestSuggestUpgrade_ExhaustedFreeUserGetsPro(t *testing.T) {
+	am := NewAccessManager(DefaultConfig())
+	var notified []domain.AccessTier
+	am.SetUpgradeNotifier(func(userID string, tier domain.AccessTier, reason string) {
+		notified = append(notified, tier)
+	})
+
+	exhaustDaily(am, "user-1", 0, 2)
+	if _, _, ok := am.SuggestUpgrade("user-1"); ok {
+		t.Fatal("expected no suggestion below the threshold")
+	}
+
+	exhaustDaily(am, "user-1", 2, 1)
+	tier, reason, ok := am.SuggestUpgrade("user-1")
+	if !ok || tier != domain.AccessTierPro {
+		t.Fatalf("SuggestUpgrade = %q, %v; want pro", tier, ok)
+	}
+	if reason == "" {
+		t.Error("expected a reason for the suggestion")
+	}
+	if len(notified) != 1 || notified[0] != domain.AccessTierPro {
+		t.Errorf("notifications = %v, want one pro suggestion", notified)
+	}
+
+	// Once the exhaustions age out of the window, the suggestion lapses.
+	am.now = func() time.Time { return fixedTime().AddDate(0, 0, 30) }
+	if _, _, ok := am.SuggestUpgrade("user-1"); ok {
+		t.Error("expected suggestion to lapse after the window")
+	}
+}
+
+func TestSuggestUpgrade_AcademicEmailGetsEducation(t *testing.T) {
+	am := NewAccessManager(DefaultConfig())
+	am.SetUserEmail("student", "ada@cs.ox.ac.uk")
+
+	exhaustDaily(am, "student", 0, 3)
+	tier, _, ok := am.SuggestUpgrade("student")
+	if !ok || tier != domain.AccessTierEducation {
+		t.Fatalf("SuggestUpgrade = %q, %v; want education", tier, ok)
+	}
+}
+
+func TestSuggestUpgrade_ClearedByTierChange(t *testing.T) {
+	am := NewAccessManager(DefaultConfig())
+	exhaustDaily(am, "user-1", 0, 3)
+
+	if err := am.SetUserTier("user-1", domain.AccessTierPro); err != nil {
+		t.Fatal(err)
+	}
+	if tier, _, ok := am.SuggestUpgrade("user-1"); ok {
+		t.Errorf("fresh pro user suggested %q; free-tier history should not count", tier)
+	}
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // Daily Reset Tests
 // ═══════════════════════════════════════════════════════════════════════════
@@ -295,3 +510,21 @@ func TestGetStats_TierCounts(t *testing.T) {
 		t.Fatalf("expected 1 enterprise user, got %d", stats.EnterpriseUsers)
 	}
 }
+
+func TestUserTier_LapsedEducationIsFreeBeforeSweep(t *testing.T) {
+	am := NewAccessManager(DefaultConfig())
+	am.now = fixedTime
+	am.SetUserTier("student-1", domain.AccessTierFree)
+	am.VerifyEducation("student-1", "MIT", "alice@mit.edu")
+
+	am.now = func() time.Time { return fixedTime().AddDate(1, 0, 1) }
+	if tier := am.GetUsage("student-1").Tier; tier != domain.AccessTierFree {
+		t.Fatalf("expected free tier once verification lapsed, got %q", tier)
+	}
+	for i := 0; i < 100; i++ {
+		am.RecordInference("student-1", 10)
+	}
+	if err := am.CheckAccess("student-1"); err != domain.ErrFreeTierExhausted {
+		t.Fatalf("expected free quota to apply after lapse, got %v", err)
+	}
+}
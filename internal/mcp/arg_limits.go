@@ -0,0 +1,83 @@
+package mcp
+
+import "fmt"
+
+// ─── Tool Argument Limits ───────────────────────────────────────────────────
+// tools/call arguments are size-checked before a handler unmarshals them, so
+// a multi-megabyte prompt is refused without being decoded. Interactive
+// tools get tight limits; tutu_batch_process, which carries many prompts,
+// gets the most room — still well under DefaultMaxBodyBytes, so the tool
+// limit, not the transport's body cap, is what refuses an oversized batch.
+// Params larger than every tool's limit are refused before being decoded
+// at all.
+
+// DefaultToolArgsLimit is the argument limit for tools without their own.
+const DefaultToolArgsLimit = 64 << 10
+
+// defaultToolArgsLimits are the built-in per-tool limits in bytes.
+var defaultToolArgsLimits = map[string]int{
+	"tutu_inference":     256 << 10,
+	"tutu_embed":         256 << 10,
+	"tutu_batch_process": 512 << 10,
+	"tutu_fine_tune":     16 << 10,
+}
+
+// SetToolArgsLimit sets the maximum size in bytes of tool's arguments.
+// n <= 0 restores the built-in limit. Call before serving requests.
+func (g *Gateway) SetToolArgsLimit(tool string, n int) {
+	if n <= 0 {
+		delete(g.argLimits, tool)
+		return
+	}
+	g.argLimits[tool] = n
+}
+
+// ToolArgsLimit returns the maximum size in bytes of tool's arguments.
+func (g *Gateway) ToolArgsLimit(tool string) int {
+	if n, ok := g.argLimits[tool]; ok {
+		return n
+	}
+	if n, ok := defaultToolArgsLimits[tool]; ok {
+		return n
+	}
+	return DefaultToolArgsLimit
+}
+
+// maxToolArgsLimit returns the largest argument limit of any tool.
+func (g *Gateway) maxToolArgsLimit() int {
+	limit := DefaultToolArgsLimit
+	for _, n := range defaultToolArgsLimits {
+		limit = max(limit, n)
+	}
+	for _, n := range g.argLimits {
+		limit = max(limit, n)
+	}
+	return limit
+}
+
+// paramsEnvelope is room in raw tools/call params for the tool name and
+// _meta around the arguments.
+const paramsEnvelope = 4 << 10
+
+// checkParamsSize returns an InvalidParams response when raw tools/call
+// params are too large to hold any tool's arguments, or nil. It runs on
+// the raw message, so such params are never decoded.
+func (g *Gateway) checkParamsSize(id any, params []byte) *Response {
+	limit := g.maxToolArgsLimit() + paramsEnvelope
+	if len(params) <= limit {
+		return nil
+	}
+	resp := NewInvalidParams(id, fmt.Sprintf("tools/call params are %d bytes, limit is %d", len(params), limit))
+	return &resp
+}
+
+// checkArgsSize returns an InvalidParams response naming the limit when
+// args exceed tool's limit, or nil.
+func (g *Gateway) checkArgsSize(id any, tool string, args []byte) *Response {
+	limit := g.ToolArgsLimit(tool)
+	if len(args) <= limit {
+		return nil
+	}
+	resp := NewInvalidParams(id, fmt.Sprintf("%s arguments are %d bytes, limit is %d", tool, len(args), limit))
+	return &resp
+}
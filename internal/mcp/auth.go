@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ─── Authentication ─────────────────────────────────────────────────────────
+// API keys identify the client a request is metered against. Keys arrive as
+// "Authorization: Bearer <key>" or "X-API-Key: <key>" and are resolved to a
+// client ID by a pluggable KeyStore.
+
+// AnonymousClientID is the client ID metered for unauthenticated requests
+// when anonymous access is allowed.
+const AnonymousClientID = "anonymous"
+
+// KeyStore resolves API keys to client IDs.
+type KeyStore interface {
+	// ClientID returns the client that owns apiKey, or ok=false if the key
+	// is unknown or revoked.
+	ClientID(apiKey string) (clientID string, ok bool)
+}
+
+// StaticKeyStore is a fixed API key → client ID table.
+type StaticKeyStore map[string]string
+
+// ClientID implements KeyStore.
+func (s StaticKeyStore) ClientID(apiKey string) (string, bool) {
+	id, ok := s[apiKey]
+	return id, ok && id != ""
+}
+
+// apiKeyFromRequest extracts the API key from the request headers.
+func apiKeyFromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if key, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(key)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// authenticate resolves the request's client ID. With no key store
+// configured every request is anonymous. A missing key is anonymous only
+// when allowed; a presented key must always be valid.
+func (t *Transport) authenticate(r *http.Request) (clientID string, ok bool) {
+	t.mu.RLock()
+	keys, allowAnon := t.keys, t.allowAnonymous
+	t.mu.RUnlock()
+
+	if keys == nil {
+		return AnonymousClientID, true
+	}
+	key := apiKeyFromRequest(r)
+	if key == "" {
+		return AnonymousClientID, allowAnon
+	}
+	return keys.ClientID(key)
+}
+
+// SetKeyStore enables API-key authentication: requests must carry a key
+// known to keys (see SetAllowAnonymous). nil disables authentication.
+func (t *Transport) SetKeyStore(keys KeyStore) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.keys = keys
+}
+
+// SetAllowAnonymous lets requests without an API key through as
+// AnonymousClientID — for local use. Requests with an invalid key are
+// still rejected.
+func (t *Transport) SetAllowAnonymous(allow bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.allowAnonymous = allow
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Tier Downgrades ────────────────────────────────────────────────────────
+// When a call's SLA tier is at capacity, the governed domain.DowngradePolicy
+// decides whether it is served on a lower tier (metered at that tier's
+// price) or rejected with CodeServerBusy. With Notify set, the client gets a
+// notifications/message warning naming both tiers; otherwise the downgrade
+// is silent.
+
+// CapacityCheck reports whether tier can take another call.
+type CapacityCheck func(tier domain.SLATier) bool
+
+// DowngradePolicySource returns the policy currently in force.
+type DowngradePolicySource func() domain.DowngradePolicy
+
+// SetCapacityCheck installs the per-tier capacity check consulted before
+// serving inference and batch calls. nil treats every tier as available.
+// Call before serving requests.
+func (g *Gateway) SetCapacityCheck(check CapacityCheck) {
+	g.capacity = check
+}
+
+// SetDowngradePolicy installs the source of the downgrade policy, read on
+// every call that finds its tier full so governance changes apply at once.
+// nil denies every downgrade. Call before serving requests.
+func (g *Gateway) SetDowngradePolicy(policy DowngradePolicySource) {
+	g.downgrade = policy
+}
+
+// tierDowngrade is the data of the warning sent for a downgraded call.
+type tierDowngrade struct {
+	Requested domain.SLATier `json:"requested_tier"`
+	Served    domain.SLATier `json:"served_tier"`
+	Reason    string         `json:"reason"`
+}
+
+// admitTier returns the tier a tool call on tier is served on, or a
+// CodeServerBusy response when the tier is full and policy forbids a
+// downgrade.
+func (g *Gateway) admitTier(id any, tool string, tier domain.SLATier, notify NotifyFunc) (domain.SLATier, *Response) {
+	if g.capacity == nil || g.capacity(tier) {
+		return tier, nil
+	}
+	var policy domain.DowngradePolicy
+	if g.downgrade != nil {
+		policy = g.downgrade()
+	}
+	served, err := policy.Resolve(tier)
+	if err == nil && !g.capacity(served) {
+		err = fmt.Errorf("%s: %w", served, domain.ErrSLACapacity)
+	}
+	if err != nil {
+		detail := err.Error()
+		if !policy.Allow {
+			detail += " and network policy forbids downgrades"
+		}
+		resp := NewServerBusy(id, detail)
+		return "", &resp
+	}
+	if policy.Notify {
+		notifyLog(notify, "warning", tool, tierDowngrade{Requested: tier, Served: served, Reason: "requested tier is at capacity"})
+	}
+	return served, nil
+}
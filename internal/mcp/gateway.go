@@ -1,9 +1,13 @@
 package mcp
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/tutu-network/tutu/internal/domain"
 )
@@ -11,7 +15,7 @@ import (
 // ─── MCP Gateway ────────────────────────────────────────────────────────────
 // Architecture Part XII: Enterprise-grade MCP endpoint.
 // Protocol: MCP 2025-03-26 — initialize, tools/list, tools/call,
-// resources/list, resources/read
+// resources/list, resources/read, resources/templates/list
 //
 // The Gateway is the entry point for all MCP JSON-RPC 2.0 requests.
 // It routes to tool handlers, manages SLA, and meters usage.
@@ -22,28 +26,131 @@ const (
 	ServerVersion      = "0.3.0"
 )
 
+// ServerIdentity is what the gateway reports about itself in the
+// initialize result. White-label deployments override it via SetIdentity.
+type ServerIdentity struct {
+	Name         string
+	Version      string
+	Instructions string // Optional usage hints for the client's model
+}
+
 // Gateway is the MCP server that handles JSON-RPC 2.0 requests.
 type Gateway struct {
 	sla       *SLAEngine
 	meter     *Meter
 	tools     []domain.MCPTool
 	resources []domain.MCPResource
-}
+	templates []domain.MCPResourceTemplate
+	identity  ServerIdentity
+	batch     PromptRunner
+	models    ModelLister
+	catalog   ModelCatalog
+	cache     *resourceCache
+	metrics   *methodMetrics
+	argLimits map[string]int // per-tool overrides; see SetToolArgsLimit
+	status    StatusProviders
+	streamer  InferenceStreamer
+	capacity  CapacityCheck
+	downgrade DowngradePolicySource
+	limits    SessionLimitsSource
+	rate      *rateLimiter
+}
+
+// ModelLister returns the locally installed models for tutu://models.
+type ModelLister func() ([]domain.ModelInfo, error)
+
+// ModelCatalog returns the installed models with their hardware
+// requirements for tutu://models.
+type ModelCatalog func() ([]domain.CatalogModel, error)
+
+// PromptRunner runs one batch prompt against model and returns its output
+// and output token count. An error fails only that prompt.
+type PromptRunner func(model, prompt string) (output string, outputToks int, err error)
 
 // NewGateway creates a fully configured MCP Gateway.
 func NewGateway(sla *SLAEngine, meter *Meter) *Gateway {
 	g := &Gateway{
-		sla:   sla,
-		meter: meter,
+		sla:       sla,
+		meter:     meter,
+		identity:  ServerIdentity{Name: ServerName, Version: ServerVersion},
+		batch:     stubPrompt,
+		cache:     newResourceCache(DefaultResourceTTL),
+		metrics:   newMethodMetrics(DefaultSlowMethodThreshold),
+		argLimits: make(map[string]int),
+		rate:      newRateLimiter(),
 	}
 	g.tools = g.defineTools()
 	g.resources = g.defineResources()
+	g.templates = g.defineResourceTemplates()
 	return g
 }
 
+// SetIdentity overrides the server name, version, and instructions returned
+// by initialize. Empty Name or Version keep the built-in defaults.
+// Call before serving requests.
+func (g *Gateway) SetIdentity(id ServerIdentity) {
+	if id.Name == "" {
+		id.Name = ServerName
+	}
+	if id.Version == "" {
+		id.Version = ServerVersion
+	}
+	g.identity = id
+}
+
+// Identity returns the server identity reported to clients.
+func (g *Gateway) Identity() ServerIdentity { return g.identity }
+
+// SetModelLister backs the tutu://models resource with the local model
+// registry, so pulled models show up once the cached read expires (or is
+// invalidated). nil restores the built-in sample list. Call before serving
+// requests.
+func (g *Gateway) SetModelLister(list ModelLister) {
+	g.models = list
+	g.cache.invalidate("tutu://models")
+}
+
+// SetModelCatalog backs the tutu://models resource with a model catalog,
+// which adds per-variant hardware requirements to the installed list. It
+// takes precedence over SetModelLister; nil falls back to the lister.
+// Call before serving requests.
+func (g *Gateway) SetModelCatalog(catalog ModelCatalog) {
+	g.catalog = catalog
+	g.cache.invalidate("tutu://models")
+}
+
+// SetBatchRunner replaces the runner used for tutu_batch_process prompts.
+// nil restores the built-in stub. Call before serving requests.
+func (g *Gateway) SetBatchRunner(run PromptRunner) {
+	if run == nil {
+		run = stubPrompt
+	}
+	g.batch = run
+}
+
 // HandleRequest is the main dispatch for a JSON-RPC 2.0 request.
 // It returns a Response for requests, or nil for notifications.
 func (g *Gateway) HandleRequest(raw []byte) *Response {
+	return g.HandleRequestNotify(raw, nil)
+}
+
+// HandleRequestNotify is HandleRequest with a channel back to the client:
+// progress notifications for requests carrying a progressToken are
+// delivered through notify while the request runs.
+func (g *Gateway) HandleRequestNotify(raw []byte, notify NotifyFunc) *Response {
+	return g.HandleClientRequest(raw, AnonymousClientID, notify)
+}
+
+// HandleClientRequest is HandleRequestNotify for an authenticated client:
+// tool usage is metered against clientID.
+func (g *Gateway) HandleClientRequest(raw []byte, clientID string, notify NotifyFunc) *Response {
+	return g.HandleClientRequestContext(context.Background(), raw, clientID, notify)
+}
+
+// HandleClientRequestContext is HandleClientRequest bound to ctx, typically
+// the HTTP request's: a streamed inference stops generating once ctx is
+// done, e.g. because the client disconnected.
+func (g *Gateway) HandleClientRequestContext(ctx context.Context, raw []byte, clientID string, notify NotifyFunc) *Response {
 	req, errResp := ParseRequest(raw)
 	if errResp != nil {
 		return errResp
@@ -55,26 +162,41 @@ func (g *Gateway) HandleRequest(raw []byte) *Response {
 		return nil
 	}
 
-	resp := g.dispatch(req)
+	resp := g.dispatch(ctx, req, clientID, notify)
 	return &resp
 }
 
-// dispatch routes a request to the appropriate handler.
-func (g *Gateway) dispatch(req Request) Response {
+// dispatch routes a request and records its timing under the method name.
+func (g *Gateway) dispatch(ctx context.Context, req Request, clientID string, notify NotifyFunc) Response {
+	start := g.metrics.now()
+	resp := g.route(ctx, req, clientID, notify)
+
+	method := req.Method
+	if resp.Error != nil && resp.Error.Code == CodeMethodNotFound {
+		method = unknownMethod
+	}
+	g.metrics.record(method, g.metrics.now().Sub(start), resp.Error != nil)
+	return resp
+}
+
+// route sends a request to the appropriate handler.
+func (g *Gateway) route(ctx context.Context, req Request, clientID string, notify NotifyFunc) Response {
 	switch req.Method {
 	case "initialize":
-		return g.handleInitialize(req)
+		return g.handleInitialize(req, clientID)
 	case "notifications/initialized":
 		// Client acknowledgment — no response needed for requests with id
 		return g.ack(req.ID)
 	case "tools/list":
-		return g.handleToolsList(req)
+		return g.handleToolsList(req, clientID)
 	case "tools/call":
-		return g.handleToolsCall(req)
+		return g.handleToolsCall(ctx, req, clientID, notify)
 	case "resources/list":
 		return g.handleResourcesList(req)
 	case "resources/read":
 		return g.handleResourcesRead(req)
+	case "resources/templates/list":
+		return g.handleResourceTemplatesList(req)
 	case "ping":
 		return g.ack(req.ID)
 	default:
@@ -98,6 +220,7 @@ type initializeResult struct {
 	ProtocolVersion string       `json:"protocolVersion"`
 	ServerInfo      serverInfo   `json:"serverInfo"`
 	Capabilities    capabilities `json:"capabilities"`
+	Instructions    string       `json:"instructions,omitempty"`
 }
 
 type serverInfo struct {
@@ -106,9 +229,10 @@ type serverInfo struct {
 }
 
 type capabilities struct {
-	Tools     *toolsCap     `json:"tools,omitempty"`
-	Resources *resourcesCap `json:"resources,omitempty"`
-	Logging   *struct{}     `json:"logging,omitempty"`
+	Tools        *toolsCap      `json:"tools,omitempty"`
+	Resources    *resourcesCap  `json:"resources,omitempty"`
+	Logging      *struct{}      `json:"logging,omitempty"`
+	Experimental map[string]any `json:"experimental,omitempty"` // non-standard: tutu/limits
 }
 
 type toolsCap struct {
@@ -118,9 +242,10 @@ type toolsCap struct {
 type resourcesCap struct {
 	Subscribe   bool `json:"subscribe"`
 	ListChanged bool `json:"listChanged"`
+	Templates   bool `json:"templates"` // serves resources/templates/list
 }
 
-func (g *Gateway) handleInitialize(req Request) Response {
+func (g *Gateway) handleInitialize(req Request, clientID string) Response {
 	var params initializeParams
 	if req.Params != nil {
 		if err := json.Unmarshal(req.Params, &params); err != nil {
@@ -134,14 +259,18 @@ func (g *Gateway) handleInitialize(req Request) Response {
 	result := initializeResult{
 		ProtocolVersion: MCPProtocolVersion,
 		ServerInfo: serverInfo{
-			Name:    ServerName,
-			Version: ServerVersion,
+			Name:    g.identity.Name,
+			Version: g.identity.Version,
 		},
 		Capabilities: capabilities{
 			Tools:     &toolsCap{ListChanged: true},
-			Resources: &resourcesCap{Subscribe: true, ListChanged: true},
+			Resources: &resourcesCap{Subscribe: true, ListChanged: true, Templates: true},
 			Logging:   &struct{}{},
 		},
+		Instructions: g.identity.Instructions,
+	}
+	if g.limits != nil {
+		result.Capabilities.Experimental = map[string]any{limitsCapability: g.SessionLimits(clientID)}
 	}
 
 	resp, err := NewResult(req.ID, result)
@@ -157,8 +286,17 @@ type toolsListResult struct {
 	Tools []domain.MCPTool `json:"tools"`
 }
 
-func (g *Gateway) handleToolsList(req Request) Response {
-	result := toolsListResult{Tools: g.tools}
+func (g *Gateway) handleToolsList(req Request, clientID string) Response {
+	tools := g.tools
+	if g.limits != nil {
+		tools = make([]domain.MCPTool, 0, len(g.tools))
+		for _, tool := range g.tools {
+			if g.toolAllowed(clientID, tool.Name) {
+				tools = append(tools, tool)
+			}
+		}
+	}
+	result := toolsListResult{Tools: tools}
 	resp, err := NewResult(req.ID, result)
 	if err != nil {
 		return NewInternalError(req.ID, err.Error())
@@ -171,6 +309,7 @@ func (g *Gateway) handleToolsList(req Request) Response {
 type toolsCallParams struct {
 	Name      string          `json:"name"`
 	Arguments json.RawMessage `json:"arguments"`
+	Meta      *requestMeta    `json:"_meta,omitempty"`
 }
 
 type toolsCallResult struct {
@@ -178,26 +317,100 @@ type toolsCallResult struct {
 	IsError bool           `json:"isError,omitempty"`
 }
 
+// Content block types a tool result may carry.
+const (
+	contentText     = "text"
+	contentImage    = "image"
+	contentResource = "resource"
+)
+
+// contentBlock is one item of a tool result. Which fields are set depends on
+// Type: text → Text; image → Data (base64) + MimeType; resource → Resource.
 type contentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+	Type     string                     `json:"type"`
+	Text     string                     `json:"text,omitempty"`
+	Data     string                     `json:"data,omitempty"`
+	MimeType string                     `json:"mimeType,omitempty"`
+	Resource *domain.MCPResourceContent `json:"resource,omitempty"`
+}
+
+func textContent(text string) contentBlock {
+	return contentBlock{Type: contentText, Text: text}
+}
+
+// imageContent base64-encodes raw image bytes into an image block.
+func imageContent(data []byte, mimeType string) contentBlock {
+	return contentBlock{Type: contentImage, Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+}
+
+// resourceContent embeds a resource (e.g. a generated file) by URI.
+func resourceContent(res domain.MCPResourceContent) contentBlock {
+	return contentBlock{Type: contentResource, Resource: &res}
+}
+
+// validate checks that the block carries exactly the fields its type needs.
+func (c contentBlock) validate() error {
+	switch c.Type {
+	case contentText:
+		if c.Data != "" || c.MimeType != "" || c.Resource != nil {
+			return errors.New("text content must only set text")
+		}
+	case contentImage:
+		if c.Text != "" || c.Resource != nil {
+			return errors.New("image content must only set data and mimeType")
+		}
+		if !strings.HasPrefix(c.MimeType, "image/") {
+			return fmt.Errorf("image content has invalid mimeType %q", c.MimeType)
+		}
+		if c.Data == "" {
+			return errors.New("image content has no data")
+		}
+		if _, err := base64.StdEncoding.DecodeString(c.Data); err != nil {
+			return fmt.Errorf("image content data is not base64: %w", err)
+		}
+	case contentResource:
+		if c.Text != "" || c.Data != "" || c.MimeType != "" {
+			return errors.New("resource content must only set resource")
+		}
+		if c.Resource == nil || c.Resource.URI == "" {
+			return errors.New("resource content requires a uri")
+		}
+	default:
+		return fmt.Errorf("unknown content type %q", c.Type)
+	}
+	return nil
 }
 
-func (g *Gateway) handleToolsCall(req Request) Response {
+func (g *Gateway) handleToolsCall(ctx context.Context, req Request, clientID string, notify NotifyFunc) Response {
+	if resp := g.checkParamsSize(req.ID, req.Params); resp != nil {
+		return *resp
+	}
 	var params toolsCallParams
 	if err := json.Unmarshal(req.Params, &params); err != nil {
 		return NewInvalidParams(req.ID, "invalid tools/call params")
 	}
+	if resp := g.checkToolAllowed(req.ID, clientID, params.Name); resp != nil {
+		return *resp
+	}
+	if resp := g.checkRate(req.ID, clientID); resp != nil {
+		return *resp
+	}
+	if resp := g.checkArgsSize(req.ID, params.Name, params.Arguments); resp != nil {
+		return *resp
+	}
+	progress := newProgressReporter(params.Meta, notify)
 
 	switch params.Name {
 	case "tutu_inference":
-		return g.callInference(req.ID, params.Arguments)
+		return g.callInference(ctx, req.ID, clientID, params.Arguments, progress, notify)
 	case "tutu_embed":
-		return g.callEmbed(req.ID, params.Arguments)
+		return g.callEmbed(req.ID, clientID, params.Arguments)
 	case "tutu_batch_process":
-		return g.callBatch(req.ID, params.Arguments)
+		return g.callBatch(req.ID, clientID, params.Arguments, progress, notify)
 	case "tutu_fine_tune":
-		return g.callFineTune(req.ID, params.Arguments)
+		return g.callFineTune(req.ID, clientID, params.Arguments, progress)
+	case "tutu_status":
+		return g.callStatus(req.ID)
 	default:
 		return NewInvalidParams(req.ID, fmt.Sprintf("unknown tool: %s", params.Name))
 	}
@@ -205,7 +418,7 @@ func (g *Gateway) handleToolsCall(req Request) Response {
 
 // ─── Tool Handlers (Phase 2: Stubs that validate & meter) ───────────────────
 
-func (g *Gateway) callInference(id any, args json.RawMessage) Response {
+func (g *Gateway) callInference(ctx context.Context, id any, clientID string, args json.RawMessage, progress progressReporter, notify NotifyFunc) Response {
 	var p domain.InferenceParams
 	if err := json.Unmarshal(args, &p); err != nil {
 		return NewInvalidParams(id, "invalid inference params")
@@ -213,25 +426,42 @@ func (g *Gateway) callInference(id any, args json.RawMessage) Response {
 	if p.Model == "" {
 		return NewInvalidParams(id, "model is required")
 	}
-	if p.Prompt == "" {
-		return NewInvalidParams(id, "prompt is required")
+	if p.Prompt == "" && len(p.Messages) == 0 {
+		return NewInvalidParams(id, "prompt or messages is required")
+	}
+	if len(p.Messages) > 0 {
+		if p.Prompt != "" {
+			return NewInvalidParams(id, "give either prompt or messages, not both")
+		}
+		if err := domain.ValidateConversation(p.Messages); err != nil {
+			return NewInvalidParams(id, err.Error())
+		}
 	}
 
-	tier := p.Priority
-	if tier == "" {
-		tier = domain.SLAStandard
+	tier := g.callTier(clientID, p.Priority, g.defaultTier(clientID, domain.SLAStandard))
+	tier, busy := g.admitTier(id, "tutu_inference", tier, notify)
+	if busy != nil {
+		return *busy
+	}
+
+	inputChars := len(p.Prompt)
+	for _, m := range p.Messages {
+		inputChars += len(m.Content)
+	}
+	inputToks := inputChars / 4 // ~4 chars per token
+	if p.Stream && g.streamer != nil {
+		return g.streamInference(ctx, id, clientID, p, tier, inputToks, progress, notify)
 	}
 
 	// Phase 2 stub: simulate inference and meter usage
-	inputToks := len(p.Prompt) / 4 // ~4 chars per token
-	outputToks := 50               // stub output length
-	g.meter.Record("stub-client", "tutu_inference", p.Model, inputToks, outputToks, 42, tier)
+	outputToks := 50 // stub output length
+	g.meter.Record(clientID, "tutu_inference", p.Model, inputToks, outputToks, 42, tier)
 
 	text := fmt.Sprintf("Inference accepted: model=%s tokens=%d tier=%s", p.Model, inputToks, tier)
 	return g.toolResult(id, text)
 }
 
-func (g *Gateway) callEmbed(id any, args json.RawMessage) Response {
+func (g *Gateway) callEmbed(id any, clientID string, args json.RawMessage) Response {
 	var p domain.EmbedParams
 	if err := json.Unmarshal(args, &p); err != nil {
 		return NewInvalidParams(id, "invalid embed params")
@@ -247,13 +477,14 @@ func (g *Gateway) callEmbed(id any, args json.RawMessage) Response {
 	for _, inp := range p.Inputs {
 		totalToks += len(inp) / 4
 	}
-	g.meter.Record("stub-client", "tutu_embed", p.Model, totalToks, 0, 15, domain.SLAStandard)
+	tier := g.defaultTier(clientID, domain.SLAStandard)
+	g.meter.Record(clientID, "tutu_embed", p.Model, totalToks, 0, 15, tier)
 
 	text := fmt.Sprintf("Embedding accepted: model=%s inputs=%d tokens=%d", p.Model, len(p.Inputs), totalToks)
 	return g.toolResult(id, text)
 }
 
-func (g *Gateway) callBatch(id any, args json.RawMessage) Response {
+func (g *Gateway) callBatch(id any, clientID string, args json.RawMessage, progress progressReporter, notify NotifyFunc) Response {
 	var p domain.BatchParams
 	if err := json.Unmarshal(args, &p); err != nil {
 		return NewInvalidParams(id, "invalid batch params")
@@ -265,22 +496,56 @@ func (g *Gateway) callBatch(id any, args json.RawMessage) Response {
 		return NewInvalidParams(id, "prompts must not be empty")
 	}
 
-	tier := p.Tier
-	if tier == "" {
-		tier = domain.SLABatch
+	tier := g.callTier(clientID, p.Tier, domain.SLABatch)
+	tier, busy := g.admitTier(id, "tutu_batch_process", tier, notify)
+	if busy != nil {
+		return *busy
+	}
+
+	result := domain.BatchResult{
+		Model:   p.Model,
+		Tier:    tier,
+		Results: make([]domain.BatchItemResult, len(p.Prompts)),
+	}
+	inputToks, outputToks := 0, 0
+	for i, pr := range p.Prompts {
+		item := domain.BatchItemResult{Index: i, InputTokens: len(pr) / 4}
+		out, toks, err := g.batch(p.Model, pr)
+		if err != nil {
+			item.Status = domain.BatchItemError
+			item.Error = err.Error()
+			result.Failed++
+		} else {
+			item.Status = domain.BatchItemOK
+			item.Output = out
+			item.OutputTokens = toks
+			result.Succeeded++
+			// Only prompts that produced output are billed.
+			inputToks += item.InputTokens
+			outputToks += toks
+		}
+		result.Results[i] = item
+		progress.Step(i+1, len(p.Prompts), "prompts processed")
 	}
+	g.meter.Record(clientID, "tutu_batch_process", p.Model, inputToks, outputToks, 200, tier)
 
-	totalToks := 0
-	for _, pr := range p.Prompts {
-		totalToks += len(pr) / 4
+	data, err := json.Marshal(result)
+	if err != nil {
+		return NewInternalError(id, err.Error())
 	}
-	g.meter.Record("stub-client", "tutu_batch_process", p.Model, totalToks, totalToks, 200, tier)
+	return g.toolResult(id, string(data))
+}
 
-	text := fmt.Sprintf("Batch accepted: model=%s prompts=%d tier=%s", p.Model, len(p.Prompts), tier)
-	return g.toolResult(id, text)
+// stubPrompt is the Phase 2 batch runner: it echoes a synthetic completion
+// and rejects blank prompts.
+func stubPrompt(model, prompt string) (string, int, error) {
+	if strings.TrimSpace(prompt) == "" {
+		return "", 0, errors.New("prompt is empty")
+	}
+	return fmt.Sprintf("Batch completion: model=%s", model), len(prompt) / 4, nil
 }
 
-func (g *Gateway) callFineTune(id any, args json.RawMessage) Response {
+func (g *Gateway) callFineTune(id any, clientID string, args json.RawMessage, progress progressReporter) Response {
 	var p domain.FineTuneParams
 	if err := json.Unmarshal(args, &p); err != nil {
 		return NewInvalidParams(id, "invalid fine_tune params")
@@ -294,8 +559,12 @@ func (g *Gateway) callFineTune(id any, args json.RawMessage) Response {
 	if p.Epochs <= 0 {
 		p.Epochs = 3
 	}
+	// Training runs after the call returns and its steps are not tracked
+	// here, so the client only learns the job was queued: indeterminate
+	// progress, with no total.
+	progress.Report(0, 0, fmt.Sprintf("fine-tune queued: %d epochs on %s", p.Epochs, p.BaseModel))
 
-	g.meter.Record("stub-client", "tutu_fine_tune", p.BaseModel, 0, 0, 0, domain.SLABatch)
+	g.meter.Record(clientID, "tutu_fine_tune", p.BaseModel, 0, 0, 0, g.callTier(clientID, "", domain.SLABatch))
 
 	text := fmt.Sprintf("Fine-tune accepted: base=%s dataset=%s epochs=%d lora=%v",
 		p.BaseModel, p.DatasetURI, p.Epochs, p.LoRA)
@@ -317,6 +586,21 @@ func (g *Gateway) handleResourcesList(req Request) Response {
 	return resp
 }
 
+// ─── resources/templates/list ───────────────────────────────────────────────
+
+type resourceTemplatesListResult struct {
+	ResourceTemplates []domain.MCPResourceTemplate `json:"resourceTemplates"`
+}
+
+func (g *Gateway) handleResourceTemplatesList(req Request) Response {
+	result := resourceTemplatesListResult{ResourceTemplates: g.templates}
+	resp, err := NewResult(req.ID, result)
+	if err != nil {
+		return NewInternalError(req.ID, err.Error())
+	}
+	return resp
+}
+
 // ─── resources/read ─────────────────────────────────────────────────────────
 
 type resourcesReadParams struct {
@@ -333,19 +617,43 @@ func (g *Gateway) handleResourcesRead(req Request) Response {
 		return NewInvalidParams(req.ID, "invalid resources/read params")
 	}
 
+	var compute func() ([]domain.MCPResourceContent, error)
 	switch params.URI {
 	case "tutu://capacity":
-		return g.readCapacity(req.ID)
+		compute = g.readCapacity
 	case "tutu://models":
-		return g.readModels(req.ID)
-	case "tutu://regions/global":
-		return g.readRegions(req.ID)
+		compute = g.readModels
 	default:
-		return NewInvalidParams(req.ID, fmt.Sprintf("unknown resource: %s", params.URI))
+		id, ok := strings.CutPrefix(params.URI, regionURIPrefix)
+		if !ok || !(id == globalRegion || domain.RegionID(id).IsValid()) {
+			return NewInvalidParams(req.ID, fmt.Sprintf("unknown resource: %s", params.URI))
+		}
+		compute = func() ([]domain.MCPResourceContent, error) { return g.readRegion(id) }
+	}
+
+	contents, err := g.cache.get(params.URI, compute)
+	if err != nil {
+		return NewInternalError(req.ID, err.Error())
+	}
+	resp, err := NewResult(req.ID, resourcesReadResult{Contents: contents})
+	if err != nil {
+		return NewInternalError(req.ID, err.Error())
 	}
+	return resp
 }
 
-func (g *Gateway) readCapacity(id any) Response {
+// jsonContent renders v as the single JSON content block of uri.
+func jsonContent(uri string, v any) ([]domain.MCPResourceContent, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return []domain.MCPResourceContent{
+		{URI: uri, MimeType: "application/json", Text: string(data)},
+	}, nil
+}
+
+func (g *Gateway) readCapacity() ([]domain.MCPResourceContent, error) {
 	// Phase 2 stub — returns synthetic capacity data
 	capacity := map[string]any{
 		"total_nodes":       1,
@@ -355,68 +663,110 @@ func (g *Gateway) readCapacity(id any) Response {
 		"queued_tasks":      0,
 		"active_tasks":      0,
 	}
-	data, _ := json.Marshal(capacity)
-	result := resourcesReadResult{
-		Contents: []domain.MCPResourceContent{
-			{URI: "tutu://capacity", MimeType: "application/json", Text: string(data)},
-		},
-	}
-	resp, err := NewResult(id, result)
-	if err != nil {
-		return NewInternalError(id, err.Error())
-	}
-	return resp
+	return jsonContent("tutu://capacity", capacity)
 }
 
-func (g *Gateway) readModels(id any) Response {
-	// Phase 2 stub — returns synthetic model list
-	models := []map[string]any{
-		{"name": "llama-3.2-1b", "parameters": "1B", "quantizations": []string{"Q4_K_M", "Q8_0"}},
-		{"name": "llama-3.2-7b", "parameters": "7B", "quantizations": []string{"Q4_K_M", "Q5_K_M", "Q8_0"}},
-		{"name": "llama-3.2-70b", "parameters": "70B", "quantizations": []string{"Q4_K_M"}},
-	}
-	data, _ := json.Marshal(models)
-	result := resourcesReadResult{
-		Contents: []domain.MCPResourceContent{
-			{URI: "tutu://models", MimeType: "application/json", Text: string(data)},
-		},
+func (g *Gateway) readModels() ([]domain.MCPResourceContent, error) {
+	if g.catalog != nil {
+		models, err := g.catalog()
+		if err != nil {
+			return nil, fmt.Errorf("model catalog: %w", err)
+		}
+		if models == nil {
+			models = []domain.CatalogModel{}
+		}
+		return jsonContent("tutu://models", models)
 	}
-	resp, err := NewResult(id, result)
-	if err != nil {
-		return NewInternalError(id, err.Error())
+	if g.models != nil {
+		installed, err := g.models()
+		if err != nil {
+			return nil, fmt.Errorf("list models: %w", err)
+		}
+		if installed == nil {
+			installed = []domain.ModelInfo{}
+		}
+		return jsonContent("tutu://models", installed)
+	}
+	return jsonContent("tutu://models", stubCatalog())
+}
+
+// stubCatalog is the Phase 2 synthetic model list, with requirements
+// estimated from each variant's parameter count and quantization.
+func stubCatalog() []domain.CatalogModel {
+	samples := []struct {
+		name, params string
+		quants       []string
+	}{
+		{"llama-3.2-1b", "1B", []string{"Q4_K_M", "Q8_0"}},
+		{"llama-3.2-7b", "7B", []string{"Q4_K_M", "Q5_K_M", "Q8_0"}},
+		{"llama-3.2-70b", "70B", []string{"Q4_K_M"}},
+	}
+	models := make([]domain.CatalogModel, len(samples))
+	for i, m := range samples {
+		models[i] = domain.CatalogModel{Name: m.name, Family: "llama", Parameters: m.params}
+		for _, q := range m.quants {
+			req, _ := domain.EstimateRequirements(m.params, q) // samples are well-formed
+			models[i].Variants = append(models[i].Variants, domain.CatalogVariant{
+				Name:              m.name + ":" + q,
+				Quantization:      q,
+				ModelRequirements: req,
+			})
+		}
 	}
-	return resp
+	return models
 }
 
-func (g *Gateway) readRegions(id any) Response {
+// regionURIPrefix is the fixed part of the tutu://regions/{id} template.
+const regionURIPrefix = "tutu://regions/"
+
+// globalRegion is the {id} that aggregates every region.
+const globalRegion = "global"
+
+// readRegion serves tutu://regions/{id}: stats for one region, or for all
+// regions when id is "global".
+func (g *Gateway) readRegion(id string) ([]domain.MCPResourceContent, error) {
 	// Phase 2 stub — returns synthetic region stats
-	regions := map[string]any{
-		"regions": []map[string]any{
-			{"region": "us-east", "nodes": 0, "vram_gb": 0, "avg_latency_ms": 0},
-			{"region": "eu-west", "nodes": 0, "vram_gb": 0, "avg_latency_ms": 0},
-			{"region": "ap-south", "nodes": 0, "vram_gb": 0, "avg_latency_ms": 0},
-		},
-		"total_regions": 3,
+	var stats []map[string]any
+	for _, r := range domain.AllRegions() {
+		if id == globalRegion || id == string(r) {
+			stats = append(stats, map[string]any{"region": r, "nodes": 0, "vram_gb": 0, "avg_latency_ms": 0})
+		}
 	}
-	data, _ := json.Marshal(regions)
-	result := resourcesReadResult{
-		Contents: []domain.MCPResourceContent{
-			{URI: "tutu://regions/global", MimeType: "application/json", Text: string(data)},
-		},
+	if id != globalRegion {
+		return jsonContent(regionURIPrefix+id, stats[0])
 	}
-	resp, err := NewResult(id, result)
-	if err != nil {
-		return NewInternalError(id, err.Error())
+	regions := map[string]any{
+		"regions":       stats,
+		"total_regions": len(stats),
 	}
-	return resp
+	return jsonContent(regionURIPrefix+id, regions)
 }
 
 // ─── Helpers ────────────────────────────────────────────────────────────────
 
 func (g *Gateway) toolResult(id any, text string) Response {
-	result := toolsCallResult{
-		Content: []contentBlock{{Type: "text", Text: text}},
+	return g.toolResultContent(id, textContent(text))
+}
+
+// toolResultContent builds a tool result from one or more content blocks,
+// rejecting any block whose shape does not match its type.
+func (g *Gateway) toolResultContent(id any, blocks ...contentBlock) Response {
+	return g.contentResult(id, false, blocks)
+}
+
+// toolErrorContent is toolResultContent for a failed call: the result is
+// marked isError so clients don't mistake it for normal output.
+func (g *Gateway) toolErrorContent(id any, blocks ...contentBlock) Response {
+	return g.contentResult(id, true, blocks)
+}
+
+func (g *Gateway) contentResult(id any, isError bool, blocks []contentBlock) Response {
+	for i, b := range blocks {
+		if err := b.validate(); err != nil {
+			return NewInternalError(id, fmt.Sprintf("content[%d]: %v", i, err))
+		}
 	}
+	result := toolsCallResult{Content: blocks, IsError: isError}
 	resp, err := NewResult(id, result)
 	if err != nil {
 		return NewInternalError(id, err.Error())
@@ -431,6 +781,13 @@ func (g *Gateway) ack(id any) Response {
 
 func (g *Gateway) handleNotification(req Request) {
 	log.Printf("[mcp] notification: %s", req.Method)
+	if req.Method == "notifications/resources/updated" {
+		var params resourcesReadParams // {"uri": ...}; absent = all
+		if req.Params != nil {
+			json.Unmarshal(req.Params, &params) //nolint:errcheck
+		}
+		g.cache.invalidate(params.URI)
+	}
 }
 
 // ─── Tool & Resource Definitions ────────────────────────────────────────────
@@ -444,12 +801,13 @@ func (g *Gateway) defineTools() []domain.MCPTool {
 				Type: "object",
 				Properties: map[string]domain.MCPSchemaProperty{
 					"model":      {Type: "string", Description: "Model name (e.g., llama-3.2-70b)"},
-					"prompt":     {Type: "string", Description: "Input prompt"},
+					"prompt":     {Type: "string", Description: "Input prompt (or give messages)"},
+					"messages":   {Type: "array", Description: "Chat conversation of {role, content} messages, ending with a user turn (instead of prompt)"},
 					"stream":     {Type: "boolean", Description: "Enable token streaming", Default: false},
 					"priority":   {Type: "string", Description: "SLA tier", Enum: []string{"realtime", "standard", "batch", "spot"}, Default: "standard"},
 					"max_tokens": {Type: "integer", Description: "Maximum tokens to generate", Default: 2048},
 				},
-				Required: []string{"model", "prompt"},
+				Required: []string{"model"},
 			},
 		},
 		{
@@ -466,7 +824,7 @@ func (g *Gateway) defineTools() []domain.MCPTool {
 		},
 		{
 			Name:        "tutu_batch_process",
-			Description: "Process multiple prompts in batch with configurable SLA tier.",
+			Description: "Process multiple prompts in batch with configurable SLA tier. Returns a per-prompt status in input order; failed prompts do not fail the batch.",
 			InputSchema: domain.MCPToolInputSchema{
 				Type: "object",
 				Properties: map[string]domain.MCPSchemaProperty{
@@ -491,6 +849,15 @@ func (g *Gateway) defineTools() []domain.MCPTool {
 				Required: []string{"base_model", "dataset_uri"},
 			},
 		},
+		{
+			Name:        "tutu_status",
+			Description: "Live gateway health: open sessions, loaded models, scheduler queue depth, SLA compliance, and active self-heal incidents.",
+			InputSchema: domain.MCPToolInputSchema{
+				Type:       "object",
+				Properties: map[string]domain.MCPSchemaProperty{},
+				Required:   []string{},
+			},
+		},
 	}
 }
 
@@ -505,7 +872,7 @@ func (g *Gateway) defineResources() []domain.MCPResource {
 		{
 			URI:         "tutu://models",
 			Name:        "Available Models",
-			Description: "Models available on the network with quantizations",
+			Description: "Models available on the network with quantizations and hardware requirements",
 			MimeType:    "application/json",
 		},
 		{
@@ -516,3 +883,25 @@ func (g *Gateway) defineResources() []domain.MCPResource {
 		},
 	}
 }
+
+func (g *Gateway) defineResourceTemplates() []domain.MCPResourceTemplate {
+	ids := []string{globalRegion}
+	for _, r := range domain.AllRegions() {
+		ids = append(ids, string(r))
+	}
+	return []domain.MCPResourceTemplate{
+		{
+			URITemplate: regionURIPrefix + "{id}",
+			Name:        "Region Stats",
+			Description: "Node statistics for one geographic region",
+			MimeType:    "application/json",
+			Parameters: []domain.MCPTemplateParameter{
+				{
+					Name:        "id",
+					Description: "Region ID, or \"global\" for every region",
+					Values:      ids,
+				},
+			},
+		},
+	}
+}
@@ -18,6 +18,10 @@ import (
 const JSONRPCVersion = "2.0"
 
 // Request is a JSON-RPC 2.0 request object.
+//
+// A decoded ID is the json.RawMessage it arrived as (nil when absent or
+// null), so responses echo it byte-for-byte. Decoding into any would turn
+// integers into float64 and lose precision beyond 2^53.
 type Request struct {
 	JSONRPC string          `json:"jsonrpc"`
 	ID      any             `json:"id"` // string | int | null
@@ -25,10 +29,41 @@ type Request struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 }
 
+// UnmarshalJSON decodes the request, keeping ID in its original encoding.
+func (r *Request) UnmarshalJSON(data []byte) error {
+	type request Request
+	var aux struct {
+		request
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*r = Request(aux.request)
+	r.ID = nil
+	if len(aux.ID) > 0 && string(aux.ID) != "null" {
+		r.ID = aux.ID
+	}
+	return nil
+}
+
+// validID reports whether id is a string or number, as JSON-RPC requires.
+func validID(id any) bool {
+	raw, ok := id.(json.RawMessage)
+	if !ok || len(raw) == 0 {
+		return true
+	}
+	switch raw[0] {
+	case '"', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return true
+	}
+	return false
+}
+
 // Response is a JSON-RPC 2.0 response object.
 type Response struct {
 	JSONRPC string          `json:"jsonrpc"`
-	ID      any             `json:"id"`
+	ID      any             `json:"id"` // echoes Request.ID
 	Result  json.RawMessage `json:"result,omitempty"`
 	Error   *RPCError       `json:"error,omitempty"`
 }
@@ -67,6 +102,11 @@ const (
 	CodeContentTooLarge  = -32801 // Content exceeds maximum size
 )
 
+// Implementation-defined server error codes (-32000 to -32099).
+const (
+	CodeServerBusy = -32000 // Server cannot accept the request now; retry later
+)
+
 // NewParseError creates a parse error response.
 func NewParseError(id any) Response {
 	return errResponse(id, CodeParseError, "Parse error")
@@ -92,6 +132,11 @@ func NewInternalError(id any, detail string) Response {
 	return errResponse(id, CodeInternalError, fmt.Sprintf("Internal error: %s", detail))
 }
 
+// NewServerBusy creates a server-busy error response.
+func NewServerBusy(id any, detail string) Response {
+	return errResponse(id, CodeServerBusy, fmt.Sprintf("Server busy: %s", detail))
+}
+
 // NewResult creates a successful response with the given result.
 func NewResult(id any, result any) (Response, error) {
 	data, err := json.Marshal(result)
@@ -113,6 +158,10 @@ func ParseRequest(raw []byte) (Request, *Response) {
 		resp := NewParseError(nil)
 		return Request{}, &resp
 	}
+	if !validID(req.ID) {
+		resp := NewInvalidRequest(nil)
+		return Request{}, &resp
+	}
 	if req.JSONRPC != JSONRPCVersion {
 		resp := NewInvalidRequest(req.ID)
 		return Request{}, &resp
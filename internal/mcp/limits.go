@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Session Limits ─────────────────────────────────────────────────────────
+// Clients self-regulate better when they know their limits before the first
+// call. When limits are configured, initialize reports the client's limits
+// under capabilities.experimental["tutu/limits"] (MCP reserves experimental
+// for non-standard capabilities, so other clients ignore it).
+//
+// The gateway enforces the tool allow-list (tools/list hides other tools and
+// tools/call refuses them), the rate limit (tools/call above it is refused
+// as busy) and the tier (calls run and are metered at most at the client's
+// tier); the transport enforces the body size.
+
+// DefaultMaxBodyBytes is the largest JSON-RPC request body the transport
+// accepts when no session limit overrides it.
+const DefaultMaxBodyBytes = 1 << 20
+
+// limitsCapability is the experimental capability key carrying SessionLimits.
+const limitsCapability = "tutu/limits"
+
+// SessionLimits are the limits a client's session runs under.
+type SessionLimits struct {
+	Tier         domain.SLATier `json:"tier,omitempty"`         // highest tier the client's calls run at; "" = any
+	RateLimitRPM int            `json:"rateLimitRpm,omitempty"` // tool calls per minute; 0 = unlimited
+	MaxBodyBytes int64          `json:"maxBodyBytes"`           // largest request body; 0 = DefaultMaxBodyBytes
+	AllowedTools []string       `json:"allowedTools,omitempty"` // tools the client may call; empty = all
+}
+
+// SessionLimitsSource returns the limits for clientID.
+type SessionLimitsSource func(clientID string) SessionLimits
+
+// SetSessionLimits installs the per-client session limits reported at
+// initialize and enforced on tool calls. nil reports nothing, allows every
+// tool and applies no rate limit. Call before serving requests.
+func (g *Gateway) SetSessionLimits(limits SessionLimitsSource) {
+	g.limits = limits
+}
+
+// SessionLimits returns the limits clientID's session runs under, with
+// defaults filled in.
+func (g *Gateway) SessionLimits(clientID string) SessionLimits {
+	var l SessionLimits
+	if g.limits != nil {
+		l = g.limits(clientID)
+	}
+	if l.MaxBodyBytes <= 0 {
+		l.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	return l
+}
+
+// toolAllowed reports whether clientID may call tool.
+func (g *Gateway) toolAllowed(clientID, tool string) bool {
+	if g.limits == nil {
+		return true
+	}
+	allowed := g.limits(clientID).AllowedTools
+	return len(allowed) == 0 || slices.Contains(allowed, tool)
+}
+
+// checkToolAllowed returns an InvalidParams response when clientID may not
+// call tool, or nil.
+func (g *Gateway) checkToolAllowed(id any, clientID, tool string) *Response {
+	if g.toolAllowed(clientID, tool) {
+		return nil
+	}
+	resp := NewInvalidParams(id, fmt.Sprintf("tool %s is not allowed for this client", tool))
+	return &resp
+}
+
+// callTier returns the tier a tool call runs and is metered at: requested,
+// or fallback when the call names none, capped at the client's tier.
+func (g *Gateway) callTier(clientID string, requested, fallback domain.SLATier) domain.SLATier {
+	tier := requested
+	if tier == "" {
+		tier = fallback
+	}
+	if limit := g.SessionLimits(clientID).Tier; limit.Rank() > 0 && tier.Rank() > limit.Rank() {
+		return limit
+	}
+	return tier
+}
+
+// defaultTier returns the tier for a call that names none: the client's
+// tier when one is configured, otherwise fallback.
+func (g *Gateway) defaultTier(clientID string, fallback domain.SLATier) domain.SLATier {
+	if tier := g.SessionLimits(clientID).Tier; tier.Rank() > 0 {
+		return tier
+	}
+	return fallback
+}
+
+// checkRate returns a ServerBusy response when clientID has used up its
+// per-minute tool call budget, or nil.
+func (g *Gateway) checkRate(id any, clientID string) *Response {
+	rpm := g.SessionLimits(clientID).RateLimitRPM
+	if rpm <= 0 || g.rate.allow(clientID, rpm) {
+		return nil
+	}
+	resp := NewServerBusy(id, fmt.Sprintf("rate limit of %d tool calls per minute exceeded", rpm))
+	return &resp
+}
+
+// ─── Rate Limiter ───────────────────────────────────────────────────────────
+
+// rateLimiter is a token bucket per client: each bucket holds up to a
+// minute's worth of calls and refills continuously at rpm per minute, so
+// a client may burst its whole budget and then proceeds at the steady rate.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+	now     func() time.Time
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: make(map[string]*rateBucket), now: time.Now}
+}
+
+// allow takes one token from clientID's bucket, reporting false when it
+// is empty.
+func (r *rateLimiter) allow(clientID string, rpm int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	limit := float64(rpm)
+	b, ok := r.buckets[clientID]
+	if !ok {
+		b = &rateBucket{tokens: limit, last: now}
+		r.buckets[clientID] = b
+	}
+	b.tokens = min(limit, b.tokens+now.Sub(b.last).Minutes()*limit)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
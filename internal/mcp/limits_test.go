@@ -0,0 +1,214 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// limitedTransport serves a gateway where client "acme" may only call
+// tutu_status and tutu_embed at 120 requests per minute with 4 KiB bodies,
+// and every other client gets the defaults.
+func limitedTransport(t *testing.T) *Transport {
+	t.Helper()
+	gw := newTestGateway(t)
+	gw.SetSessionLimits(func(clientID string) SessionLimits {
+		if clientID != "acme" {
+			return SessionLimits{}
+		}
+		return SessionLimits{
+			RateLimitRPM: 120,
+			MaxBodyBytes: 4 << 10,
+			AllowedTools: []string{"tutu_status", "tutu_embed"},
+		}
+	})
+	tr := NewTransport(gw)
+	tr.SetKeyStore(StaticKeyStore{"key-acme": "acme", "key-globex": "globex"})
+	return tr
+}
+
+func postAs(tr *Transport, apiKey string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(string(body)))
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	w := httptest.NewRecorder()
+	tr.ServeHTTP(w, req)
+	return w
+}
+
+// initializeLimits runs initialize as apiKey and returns the advertised
+// tutu/limits capability, or nil if there is none.
+func initializeLimits(t *testing.T, tr *Transport, apiKey string) *SessionLimits {
+	t.Helper()
+	w := postAs(tr, apiKey, rpcRequest("initialize", map[string]any{"protocolVersion": MCPProtocolVersion}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("initialize: status = %d, want 200", w.Code)
+	}
+	var resp struct {
+		Result struct {
+			Capabilities struct {
+				Experimental map[string]*SessionLimits `json:"experimental"`
+			} `json:"capabilities"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode initialize: %v", err)
+	}
+	return resp.Result.Capabilities.Experimental[limitsCapability]
+}
+
+func TestGateway_Initialize_LimitHints(t *testing.T) {
+	tr := limitedTransport(t)
+
+	got := initializeLimits(t, tr, "key-acme")
+	if got == nil {
+		t.Fatal("initialize should advertise tutu/limits")
+	}
+	if got.RateLimitRPM != 120 || got.MaxBodyBytes != 4<<10 {
+		t.Errorf("limits = %+v, want 120 rpm and 4096-byte bodies", got)
+	}
+	if !slices.Equal(got.AllowedTools, []string{"tutu_status", "tutu_embed"}) {
+		t.Errorf("allowedTools = %v, want [tutu_status tutu_embed]", got.AllowedTools)
+	}
+
+	other := initializeLimits(t, tr, "key-globex")
+	if other == nil {
+		t.Fatal("every client should get tutu/limits once limits are configured")
+	}
+	if other.MaxBodyBytes != DefaultMaxBodyBytes || len(other.AllowedTools) != 0 || other.RateLimitRPM != 0 {
+		t.Errorf("unrestricted limits = %+v, want the default body size only", other)
+	}
+}
+
+func TestGateway_Initialize_NoLimitHintsByDefault(t *testing.T) {
+	resp := newTestGateway(t).HandleRequest(rpcRequest("initialize", nil))
+	if resp == nil || resp.Error != nil {
+		t.Fatalf("initialize failed: %+v", resp)
+	}
+	if strings.Contains(string(resp.Result), `"experimental"`) {
+		t.Errorf("initialize without limits should not advertise them: %s", resp.Result)
+	}
+}
+
+func TestGateway_AllowedToolsEnforced(t *testing.T) {
+	tr := limitedTransport(t)
+
+	w := postAs(tr, "key-acme", rpcRequest("tools/list", nil))
+	var list struct {
+		Result toolsListResult `json:"result"`
+	}
+	json.NewDecoder(w.Body).Decode(&list)
+	var names []string
+	for _, tool := range list.Result.Tools {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"tutu_embed", "tutu_status"}) {
+		t.Errorf("tools/list = %v, want only the allowed tools", names)
+	}
+
+	call := rpcRequest("tools/call", toolsCallParams{
+		Name:      "tutu_inference",
+		Arguments: mustMarshal(domain.InferenceParams{Model: "llama-7b", Prompt: "hi"}),
+	})
+	var resp Response
+	json.NewDecoder(postAs(tr, "key-acme", call).Body).Decode(&resp)
+	if resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+		t.Errorf("disallowed tool: error = %+v, want InvalidParams", resp.Error)
+	}
+
+	resp = Response{}
+	json.NewDecoder(postAs(tr, "key-globex", call).Body).Decode(&resp)
+	if resp.Error != nil {
+		t.Errorf("unrestricted client: unexpected error %v", resp.Error)
+	}
+}
+
+func TestTransport_MaxBodyBytes(t *testing.T) {
+	tr := limitedTransport(t)
+	call := rpcRequest("tools/call", toolsCallParams{
+		Name:      "tutu_embed",
+		Arguments: mustMarshal(domain.EmbedParams{Model: "nomic-embed", Inputs: []string{strings.Repeat("x", 8<<10)}}),
+	})
+
+	if w := postAs(tr, "key-acme", call); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized body: status = %d, want 413", w.Code)
+	}
+	if w := postAs(tr, "key-globex", call); w.Code != http.StatusOK {
+		t.Errorf("body under the default limit: status = %d, want 200", w.Code)
+	}
+}
+
+func TestGateway_RateLimitEnforced(t *testing.T) {
+	gw := newTestGateway(t)
+	gw.SetSessionLimits(func(clientID string) SessionLimits {
+		if clientID == "acme" {
+			return SessionLimits{RateLimitRPM: 2}
+		}
+		return SessionLimits{}
+	})
+	now := time.Unix(1_700_000_000, 0)
+	gw.rate.now = func() time.Time { return now }
+
+	call := rpcRequest("tools/call", toolsCallParams{Name: "tutu_status"})
+	for i := range 2 {
+		if resp := gw.HandleClientRequest(call, "acme", nil); resp.Error != nil {
+			t.Fatalf("call %d within the limit: %v", i+1, resp.Error)
+		}
+	}
+	resp := gw.HandleClientRequest(call, "acme", nil)
+	if resp.Error == nil || resp.Error.Code != CodeServerBusy {
+		t.Fatalf("call over the limit: error = %+v, want ServerBusy", resp.Error)
+	}
+	if resp := gw.HandleClientRequest(call, "globex", nil); resp.Error != nil {
+		t.Errorf("another client's calls should not be limited: %v", resp.Error)
+	}
+
+	now = now.Add(30 * time.Second) // refills one call at 2 rpm
+	if resp := gw.HandleClientRequest(call, "acme", nil); resp.Error != nil {
+		t.Errorf("call after refill: %v", resp.Error)
+	}
+	if resp := gw.HandleClientRequest(call, "acme", nil); resp.Error == nil {
+		t.Error("second call after a half-minute refill should be refused")
+	}
+}
+
+func TestGateway_ClientTierMetered(t *testing.T) {
+	gw := newTestGateway(t)
+	gw.SetSessionLimits(func(clientID string) SessionLimits {
+		if clientID == "acme" {
+			return SessionLimits{Tier: domain.SLABatch}
+		}
+		return SessionLimits{}
+	})
+
+	infer := func(clientID string, tier domain.SLATier) domain.SLATier {
+		t.Helper()
+		call := rpcRequest("tools/call", toolsCallParams{
+			Name:      "tutu_inference",
+			Arguments: mustMarshal(domain.InferenceParams{Model: "llama-7b", Prompt: "hi", Priority: tier}),
+		})
+		if resp := gw.HandleClientRequest(call, clientID, nil); resp.Error != nil {
+			t.Fatalf("inference as %s: %v", clientID, resp.Error)
+		}
+		return gw.meter.RecentRecords(1)[0].Tier
+	}
+
+	if got := infer("acme", ""); got != domain.SLABatch {
+		t.Errorf("default tier = %s, want the client's tier batch", got)
+	}
+	if got := infer("acme", domain.SLARealtime); got != domain.SLABatch {
+		t.Errorf("requested realtime = %s, want it capped at batch", got)
+	}
+	if got := infer("acme", domain.SLASpot); got != domain.SLASpot {
+		t.Errorf("requested spot = %s, want spot", got)
+	}
+	if got := infer("globex", ""); got != domain.SLAStandard {
+		t.Errorf("untiered client default = %s, want standard", got)
+	}
+}
@@ -1,16 +1,21 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/scheduler"
 )
 
 // ─── Test Helpers ───────────────────────────────────────────────────────────
@@ -88,6 +93,53 @@ func TestParseRequest_MissingMethod(t *testing.T) {
 	}
 }
 
+func TestResponse_EchoesIDExactly(t *testing.T) {
+	gw := newTestGateway(t)
+	for _, id := range []string{
+		`99999999999999`,
+		`9007199254740993`, // 2^53+1 — not representable as float64
+		`-42`,
+		`"req-001"`,
+		`"\u00e9t\u00e9"`, // escapes are not re-encoded
+	} {
+		t.Run(id, func(t *testing.T) {
+			for _, method := range []string{"ping", "no/such/method"} {
+				resp := gw.HandleRequest([]byte(`{"jsonrpc":"2.0","id":` + id + `,"method":"` + method + `"}`))
+				if resp == nil {
+					t.Fatalf("%s: no response", method)
+				}
+				data, err := json.Marshal(resp)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var echoed struct {
+					ID json.RawMessage `json:"id"`
+				}
+				if err := json.Unmarshal(data, &echoed); err != nil {
+					t.Fatal(err)
+				}
+				if string(echoed.ID) != id {
+					t.Errorf("%s: echoed id = %s, want %s", method, echoed.ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRequest_IDTypes(t *testing.T) {
+	req, errResp := ParseRequest([]byte(`{"jsonrpc":"2.0","id":null,"method":"ping"}`))
+	if errResp != nil || req.ID != nil {
+		t.Errorf("null id: req.ID = %v, err = %v; want nil id", req.ID, errResp)
+	}
+
+	for _, id := range []string{`{"a":1}`, `[1]`, `true`} {
+		_, errResp := ParseRequest([]byte(`{"jsonrpc":"2.0","id":` + id + `,"method":"ping"}`))
+		if errResp == nil || errResp.Error.Code != CodeInvalidRequest {
+			t.Errorf("id %s: err = %v, want invalid request", id, errResp)
+		}
+	}
+}
+
 func TestNewResult(t *testing.T) {
 	resp, err := NewResult(1, map[string]string{"hello": "world"})
 	if err != nil {
@@ -228,6 +280,33 @@ func TestSLAEngine_CostMicro_Spot(t *testing.T) {
 	}
 }
 
+func TestSLAEngine_PenaltyMicro(t *testing.T) {
+	e := NewSLAEngine()
+	tests := []struct {
+		name    string
+		tier    domain.SLATier
+		latency time.Duration
+		want    int64
+	}{
+		{"realtime on time", domain.SLARealtime, 150 * time.Millisecond, 0},
+		{"realtime at budget", domain.SLARealtime, 200 * time.Millisecond, 0},
+		{"realtime 50% over", domain.SLARealtime, 300 * time.Millisecond, -500},
+		{"realtime 2x budget", domain.SLARealtime, 400 * time.Millisecond, -1000},
+		{"realtime capped", domain.SLARealtime, time.Minute, -4000},
+		{"standard 2x budget", domain.SLAStandard, 4 * time.Second, -250},
+		{"batch on time", domain.SLABatch, 20 * time.Second, 0},
+		{"spot never", domain.SLASpot, time.Hour, 0},
+		{"unknown tier", domain.SLATier("gold"), time.Hour, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.PenaltyMicro(tt.tier, tt.latency); got != tt.want {
+				t.Errorf("PenaltyMicro(%s, %v) = %d, want %d", tt.tier, tt.latency, got, tt.want)
+			}
+		})
+	}
+}
+
 // ─── Meter Tests ────────────────────────────────────────────────────────────
 
 func TestMeter_Record(t *testing.T) {
@@ -276,6 +355,72 @@ func TestMeter_ClientSummary_Unknown(t *testing.T) {
 	}
 }
 
+func TestMeter_CostBreakdown(t *testing.T) {
+	sla := NewSLAEngine()
+	m := NewMeter(sla)
+
+	m.Record("client-1", "tutu_inference", "llama-7b", 100, 50, 42, domain.SLAStandard)
+	m.Record("client-1", "tutu_inference", "qwen-14b", 400, 300, 60, domain.SLARealtime)
+	m.Record("client-1", "tutu_embed", "embed-v2", 200, 0, 15, domain.SLAStandard)
+	m.Record("client-1", "tutu_embed", "llama-7b", 50, 0, 5, domain.SLASpot)
+	m.Record("client-2", "tutu_inference", "llama-7b", 999, 999, 80, domain.SLARealtime)
+	if _, err := m.RecordTierChange("client-1", "tutu_inference", "llama-7b", 100, 200, 50, 30, domain.SLASpot, domain.SLARealtime); err != nil {
+		t.Fatal(err)
+	}
+
+	byTool, byModel := m.CostBreakdown("client-1")
+
+	wantInference := sla.CostMicro(domain.SLAStandard, 100, 50) +
+		sla.CostMicro(domain.SLARealtime, 400, 300) +
+		sla.CostMicro(domain.SLASpot, 100, 50) +
+		sla.CostMicro(domain.SLARealtime, 0, 150)
+	if byTool["tutu_inference"] != wantInference {
+		t.Errorf("tutu_inference = %d, want %d", byTool["tutu_inference"], wantInference)
+	}
+	wantEmbedModel := sla.CostMicro(domain.SLAStandard, 200, 0)
+	if byModel["embed-v2"] != wantEmbedModel {
+		t.Errorf("embed-v2 = %d, want %d", byModel["embed-v2"], wantEmbedModel)
+	}
+	if len(byTool) != 2 || len(byModel) != 3 {
+		t.Errorf("tools = %v, models = %v; want 2 tools, 3 models", byTool, byModel)
+	}
+
+	var toolSum, modelSum int64
+	for _, c := range byTool {
+		toolSum += c
+	}
+	for _, c := range byModel {
+		modelSum += c
+	}
+	var total int64
+	for _, r := range m.RecentRecords(m.TotalRecords()) {
+		if r.ClientID == "client-1" {
+			total += r.CostMicro
+		}
+	}
+	if toolSum != total || modelSum != total {
+		t.Errorf("breakdown sums tool=%d model=%d, want total %d", toolSum, modelSum, total)
+	}
+	if got := m.ClientSummary("client-1").TotalCost; got != float64(total)/1_000_000 {
+		t.Errorf("summary cost = %v, want %v", got, float64(total)/1_000_000)
+	}
+
+	// Returned maps are copies.
+	byTool["tutu_inference"] = 0
+	if again, _ := m.CostBreakdown("client-1"); again["tutu_inference"] != wantInference {
+		t.Error("mutating the returned map changed the meter")
+	}
+}
+
+func TestMeter_CostBreakdown_Unknown(t *testing.T) {
+	m := NewMeter(NewSLAEngine())
+
+	byTool, byModel := m.CostBreakdown("nonexistent")
+	if byTool == nil || byModel == nil || len(byTool) != 0 || len(byModel) != 0 {
+		t.Errorf("unknown client breakdown = %v / %v, want empty maps", byTool, byModel)
+	}
+}
+
 func TestMeter_RecentRecords(t *testing.T) {
 	sla := NewSLAEngine()
 	m := NewMeter(sla)
@@ -308,6 +453,99 @@ func TestMeter_RecentRecords_MoreThanAvailable(t *testing.T) {
 	}
 }
 
+func TestMeter_RecordTimestamp(t *testing.T) {
+	m := NewMeter(NewSLAEngine())
+	at := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	m.Now = func() time.Time { return at }
+
+	rec := m.Record("c1", "tutu_inference", "m1", 10, 5, 1, domain.SLASpot)
+	if !rec.Timestamp.Equal(at) {
+		t.Errorf("timestamp = %v, want %v", rec.Timestamp, at)
+	}
+	if got := m.RecentRecords(1)[0].Timestamp; !got.Equal(at) {
+		t.Errorf("stored timestamp = %v, want %v", got, at)
+	}
+}
+
+func TestMeter_RecentRecords_SortedByTime(t *testing.T) {
+	m := NewMeter(NewSLAEngine())
+	base := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	// Insert out of order: clock for each record is set explicitly.
+	for _, r := range []struct {
+		model  string
+		offset time.Duration
+	}{
+		{"t2", 2 * time.Second},
+		{"t0", 0},
+		{"t3", 3 * time.Second},
+		{"t1a", 1 * time.Second},
+		{"t1b", 1 * time.Second}, // same time as t1a, inserted later
+	} {
+		at := base.Add(r.offset)
+		m.Now = func() time.Time { return at }
+		m.Record("c1", "tutu_inference", r.model, 1, 1, 1, domain.SLASpot)
+	}
+
+	recent := m.RecentRecords(5)
+	want := []string{"t3", "t2", "t1b", "t1a", "t0"}
+	for i, w := range want {
+		if recent[i].Model != w {
+			t.Errorf("recent[%d] = %q, want %q", i, recent[i].Model, w)
+		}
+	}
+}
+
+func TestMeter_RecordTierChange_RepricesAfterUpgrade(t *testing.T) {
+	sla := NewSLAEngine()
+	m := NewMeter(sla)
+
+	recs, err := m.RecordTierChange("c1", "tutu_inference", "llama-7b", 1000, 2000, 500, 90, domain.SLASpot, domain.SLARealtime)
+	if err != nil {
+		t.Fatalf("RecordTierChange() error: %v", err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("records = %d, want 2", len(recs))
+	}
+
+	spot, realtime := recs[0], recs[1]
+	if spot.Tier != domain.SLASpot || spot.InputToks != 1000 || spot.OutputToks != 500 {
+		t.Errorf("first = %+v, want spot 1000 in / 500 out", spot)
+	}
+	if realtime.Tier != domain.SLARealtime || realtime.OutputToks != 1500 {
+		t.Errorf("second = %+v, want realtime 1500 out", realtime)
+	}
+	if spot.CostMicro != sla.CostMicro(domain.SLASpot, 1000, 500) {
+		t.Errorf("spot cost = %d", spot.CostMicro)
+	}
+	if realtime.CostMicro != sla.CostMicro(domain.SLARealtime, 0, 1500) {
+		t.Errorf("realtime cost = %d", realtime.CostMicro)
+	}
+
+	s := m.ClientSummary("c1")
+	if s.TotalCalls != 1 {
+		t.Errorf("calls = %d, want 1 (split records are one call)", s.TotalCalls)
+	}
+	if s.TotalInput != 1000 || s.TotalOutput != 2000 {
+		t.Errorf("tokens = %d/%d, want 1000/2000", s.TotalInput, s.TotalOutput)
+	}
+	if m.TotalRecords() != 2 {
+		t.Errorf("TotalRecords() = %d, want 2", m.TotalRecords())
+	}
+}
+
+func TestMeter_RecordTierChange_RejectsDowngrade(t *testing.T) {
+	m := NewMeter(NewSLAEngine())
+
+	_, err := m.RecordTierChange("c1", "tutu_inference", "llama-7b", 100, 100, 50, 10, domain.SLARealtime, domain.SLASpot)
+	if !errors.Is(err, domain.ErrSLADowngrade) {
+		t.Fatalf("error = %v, want ErrSLADowngrade", err)
+	}
+	if m.TotalRecords() != 0 {
+		t.Errorf("TotalRecords() = %d, want 0 after rejection", m.TotalRecords())
+	}
+}
+
 func TestMeter_Reset(t *testing.T) {
 	sla := NewSLAEngine()
 	m := NewMeter(sla)
@@ -324,6 +562,36 @@ func TestMeter_Reset(t *testing.T) {
 	}
 }
 
+func TestMeter_Record_LatencyPenalty(t *testing.T) {
+	sla := NewSLAEngine()
+	m := NewMeter(sla)
+	full := sla.CostMicro(domain.SLARealtime, 100_000, 50_000) // 300000 µ$
+
+	onTime := m.Record("client-1", "tutu_inference", "llama-7b", 100_000, 50_000, 120, domain.SLARealtime)
+	if onTime.PenaltyMicro != 0 || onTime.CostMicro != full {
+		t.Errorf("on-time call: penalty %d, cost %d; want 0, %d", onTime.PenaltyMicro, onTime.CostMicro, full)
+	}
+
+	slow := m.Record("client-1", "tutu_inference", "llama-7b", 100_000, 50_000, 400, domain.SLARealtime)
+	if slow.PenaltyMicro != -1000 || slow.CostMicro != full-1000 {
+		t.Errorf("slow call: penalty %d, cost %d; want -1000, %d", slow.PenaltyMicro, slow.CostMicro, full-1000)
+	}
+	if got, want := m.ClientSummary("client-1").TotalCost, float64(2*full-1000)/1e6; got != want {
+		t.Errorf("total cost = %v, want %v", got, want)
+	}
+
+	// The credit never exceeds what the call cost.
+	tiny := m.Record("client-2", "tutu_inference", "llama-7b", 10, 5, 5000, domain.SLARealtime)
+	if tiny.CostMicro != 0 || tiny.PenaltyMicro != -sla.CostMicro(domain.SLARealtime, 10, 5) {
+		t.Errorf("tiny slow call: penalty %d, cost %d; want credit capped at the charge", tiny.PenaltyMicro, tiny.CostMicro)
+	}
+
+	spot := m.Record("client-3", "tutu_inference", "llama-7b", 100_000, 50_000, 60_000, domain.SLASpot)
+	if spot.PenaltyMicro != 0 {
+		t.Errorf("spot call penalty = %d, want 0", spot.PenaltyMicro)
+	}
+}
+
 // ─── Gateway Tests ──────────────────────────────────────────────────────────
 
 func TestGateway_Initialize(t *testing.T) {
@@ -354,6 +622,53 @@ func TestGateway_Initialize(t *testing.T) {
 	}
 	if result.Capabilities.Resources == nil {
 		t.Error("expected resources capability")
+	} else if !result.Capabilities.Resources.Templates {
+		t.Error("expected resources capability to advertise templates")
+	}
+}
+
+func TestGateway_Initialize_CustomIdentity(t *testing.T) {
+	gw := newTestGateway(t)
+	gw.SetIdentity(ServerIdentity{
+		Name:         "acme-ai",
+		Version:      "2.1.0",
+		Instructions: "Use the inference tool for chat.",
+	})
+
+	resp := gw.HandleRequest(rpcRequest("initialize", map[string]any{"protocolVersion": "2025-03-26"}))
+	if resp == nil || resp.Error != nil {
+		t.Fatalf("initialize failed: %+v", resp)
+	}
+
+	var result initializeResult
+	json.Unmarshal(resp.Result, &result)
+	if result.ServerInfo.Name != "acme-ai" || result.ServerInfo.Version != "2.1.0" {
+		t.Errorf("serverInfo = %+v, want acme-ai 2.1.0", result.ServerInfo)
+	}
+	if result.Instructions != "Use the inference tool for chat." {
+		t.Errorf("instructions = %q", result.Instructions)
+	}
+}
+
+func TestGateway_Initialize_DefaultIdentity(t *testing.T) {
+	gw := newTestGateway(t)
+	gw.SetIdentity(ServerIdentity{Version: "9.9.9"})
+
+	resp := gw.HandleRequest(rpcRequest("initialize", nil))
+	if resp == nil || resp.Error != nil {
+		t.Fatalf("initialize failed: %+v", resp)
+	}
+	if strings.Contains(string(resp.Result), `"instructions"`) {
+		t.Errorf("empty instructions should be omitted: %s", resp.Result)
+	}
+
+	var result initializeResult
+	json.Unmarshal(resp.Result, &result)
+	if result.ServerInfo.Name != ServerName {
+		t.Errorf("name = %q, want default %q", result.ServerInfo.Name, ServerName)
+	}
+	if result.ServerInfo.Version != "9.9.9" {
+		t.Errorf("version = %q, want 9.9.9", result.ServerInfo.Version)
 	}
 }
 
@@ -397,15 +712,15 @@ func TestGateway_ToolsList(t *testing.T) {
 
 	var result toolsListResult
 	json.Unmarshal(resp.Result, &result)
-	if len(result.Tools) != 4 {
-		t.Fatalf("expected 4 tools, got %d", len(result.Tools))
+	if len(result.Tools) != 5 {
+		t.Fatalf("expected 5 tools, got %d", len(result.Tools))
 	}
 
 	names := make(map[string]bool)
 	for _, tool := range result.Tools {
 		names[tool.Name] = true
 	}
-	for _, expected := range []string{"tutu_inference", "tutu_embed", "tutu_batch_process", "tutu_fine_tune"} {
+	for _, expected := range []string{"tutu_inference", "tutu_embed", "tutu_batch_process", "tutu_fine_tune", "tutu_status"} {
 		if !names[expected] {
 			t.Errorf("missing tool: %s", expected)
 		}
@@ -428,6 +743,71 @@ func TestGateway_ResourcesList(t *testing.T) {
 	}
 }
 
+func TestGateway_ResourceTemplatesList(t *testing.T) {
+	gw := newTestGateway(t)
+	resp := gw.HandleRequest(rpcRequest("resources/templates/list", nil))
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+
+	var result resourceTemplatesListResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	var region *domain.MCPResourceTemplate
+	for i, tmpl := range result.ResourceTemplates {
+		if tmpl.URITemplate == "tutu://regions/{id}" {
+			region = &result.ResourceTemplates[i]
+		}
+	}
+	if region == nil {
+		t.Fatalf("templates = %+v, want tutu://regions/{id}", result.ResourceTemplates)
+	}
+	if region.Name == "" || region.Description == "" || region.MimeType != "application/json" {
+		t.Errorf("region template incomplete: %+v", region)
+	}
+	if len(region.Parameters) != 1 || region.Parameters[0].Name != "id" || region.Parameters[0].Description == "" {
+		t.Fatalf("parameters = %+v, want a documented id", region.Parameters)
+	}
+	for _, want := range []string{"global", "us-east", "eu-west", "ap-south"} {
+		if !slices.Contains(region.Parameters[0].Values, want) {
+			t.Errorf("id values %v missing %q", region.Parameters[0].Values, want)
+		}
+	}
+
+	// Templates are not concrete resources.
+	var list resourcesListResult
+	json.Unmarshal(gw.HandleRequest(rpcRequest("resources/list", nil)).Result, &list)
+	for _, r := range list.Resources {
+		if strings.Contains(r.URI, "{") {
+			t.Errorf("resources/list includes template %q", r.URI)
+		}
+	}
+}
+
+func TestGateway_ResourcesRead_RegionTemplate(t *testing.T) {
+	gw := newTestGateway(t)
+
+	resp := gw.HandleRequest(rpcRequest("resources/read", resourcesReadParams{URI: "tutu://regions/eu-west"}))
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	var result resourcesReadResult
+	json.Unmarshal(resp.Result, &result)
+	if len(result.Contents) != 1 || result.Contents[0].URI != "tutu://regions/eu-west" {
+		t.Fatalf("contents = %+v", result.Contents)
+	}
+	var stats map[string]any
+	json.Unmarshal([]byte(result.Contents[0].Text), &stats)
+	if stats["region"] != "eu-west" {
+		t.Errorf("region stats = %v, want eu-west", stats)
+	}
+
+	if resp := gw.HandleRequest(rpcRequest("resources/read", resourcesReadParams{URI: "tutu://regions/mars"})); resp.Error == nil {
+		t.Error("expected error for unknown region")
+	}
+}
+
 func TestGateway_ToolsCall_Inference(t *testing.T) {
 	gw := newTestGateway(t)
 	raw := rpcRequest("tools/call", toolsCallParams{
@@ -457,6 +837,159 @@ func TestGateway_ToolsCall_Inference(t *testing.T) {
 	}
 }
 
+// realtimeFullGateway is a gateway whose realtime tier has no capacity,
+// governed by policy.
+func realtimeFullGateway(t *testing.T, policy domain.DowngradePolicy) *Gateway {
+	t.Helper()
+	gw := newTestGateway(t)
+	gw.SetCapacityCheck(func(tier domain.SLATier) bool { return tier != domain.SLARealtime })
+	gw.SetDowngradePolicy(func() domain.DowngradePolicy { return policy })
+	return gw
+}
+
+func realtimeInference() []byte {
+	return rpcRequest("tools/call", toolsCallParams{
+		Name:      "tutu_inference",
+		Arguments: mustMarshal(domain.InferenceParams{Model: "llama-3.2-7b", Prompt: "Hello", Priority: domain.SLARealtime}),
+	})
+}
+
+func TestGateway_Downgrade_AllowedToStandard(t *testing.T) {
+	gw := realtimeFullGateway(t, domain.DowngradePolicy{Allow: true, Target: domain.SLAStandard, Notify: true})
+
+	var notes []Notification
+	resp := gw.HandleRequestNotify(realtimeInference(), func(n Notification) { notes = append(notes, n) })
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	if recs := gw.meter.RecentRecords(1); len(recs) != 1 || recs[0].Tier != domain.SLAStandard {
+		t.Errorf("metered = %+v, want the call billed at standard", recs)
+	}
+
+	if len(notes) != 1 || notes[0].Method != "notifications/message" {
+		t.Fatalf("notifications = %+v, want one downgrade warning", notes)
+	}
+	var msg struct {
+		Level string        `json:"level"`
+		Data  tierDowngrade `json:"data"`
+	}
+	json.Unmarshal(notes[0].Params, &msg)
+	if msg.Level != "warning" || msg.Data.Requested != domain.SLARealtime || msg.Data.Served != domain.SLAStandard {
+		t.Errorf("warning = %+v, want realtime → standard", msg)
+	}
+
+	// Without Notify the downgrade is silent.
+	gw = realtimeFullGateway(t, domain.DowngradePolicy{Allow: true, Target: domain.SLAStandard})
+	notes = nil
+	gw.HandleRequestNotify(realtimeInference(), func(n Notification) { notes = append(notes, n) })
+	if len(notes) != 0 {
+		t.Errorf("notifications = %+v, want none without Notify", notes)
+	}
+}
+
+func TestGateway_Downgrade_Denied(t *testing.T) {
+	gw := realtimeFullGateway(t, domain.DowngradePolicy{})
+
+	resp := gw.HandleRequest(realtimeInference())
+	if resp.Error == nil || resp.Error.Code != CodeServerBusy {
+		t.Fatalf("error = %v, want CodeServerBusy", resp.Error)
+	}
+	if !strings.Contains(resp.Error.Message, "forbids downgrades") {
+		t.Errorf("message = %q, want the policy named", resp.Error.Message)
+	}
+	if n := gw.meter.TotalRecords(); n != 0 {
+		t.Errorf("metered %d calls, want none for a rejected call", n)
+	}
+
+	// Tiers with capacity are unaffected.
+	standard := rpcRequest("tools/call", toolsCallParams{
+		Name:      "tutu_inference",
+		Arguments: mustMarshal(domain.InferenceParams{Model: "llama-3.2-7b", Prompt: "Hello"}),
+	})
+	if resp := gw.HandleRequest(standard); resp.Error != nil {
+		t.Errorf("standard call error: %v", resp.Error)
+	}
+}
+
+func TestGateway_Downgrade_SchedulerCapacity(t *testing.T) {
+	cfg := scheduler.DefaultConfig()
+	cfg.TierSlots = map[domain.SLATier]int{domain.SLARealtime: 1}
+	sched := scheduler.NewScheduler(cfg)
+	sched.Enqueue(domain.Task{ID: "rt", Priority: scheduler.P0Realtime, Status: domain.TaskQueued, Type: domain.TaskInference}, domain.TaskRouting{})
+
+	gw := newTestGateway(t)
+	gw.SetCapacityCheck(sched.HasCapacity)
+	gw.SetDowngradePolicy(func() domain.DowngradePolicy {
+		return domain.DowngradePolicy{Allow: true, Target: domain.SLAStandard}
+	})
+
+	if resp := gw.HandleRequest(realtimeInference()); resp.Error != nil {
+		t.Fatalf("downgrade from a full realtime tier: %v", resp.Error)
+	}
+	if recs := gw.meter.RecentRecords(1); len(recs) != 1 || recs[0].Tier != domain.SLAStandard {
+		t.Errorf("metered = %+v, want the call served at standard", recs)
+	}
+}
+
+func TestGateway_ToolResult_MultiContent(t *testing.T) {
+	gw := newTestGateway(t)
+	png := []byte{0x89, 'P', 'N', 'G'}
+
+	resp := gw.toolResultContent(1,
+		textContent("Here is your image"),
+		imageContent(png, "image/png"),
+		resourceContent(domain.MCPResourceContent{URI: "tutu://artifacts/img-1", MimeType: "image/png"}),
+	)
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+
+	var raw struct {
+		Content []map[string]any `json:"content"`
+	}
+	if err := json.Unmarshal(resp.Result, &raw); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(raw.Content) != 3 {
+		t.Fatalf("content blocks = %d, want 3", len(raw.Content))
+	}
+
+	text, img, res := raw.Content[0], raw.Content[1], raw.Content[2]
+	if text["type"] != "text" || text["text"] != "Here is your image" || len(text) != 2 {
+		t.Errorf("text block = %v", text)
+	}
+	if img["type"] != "image" || img["mimeType"] != "image/png" || img["data"] != "iVBORw==" || len(img) != 3 {
+		t.Errorf("image block = %v", img)
+	}
+	embedded, _ := res["resource"].(map[string]any)
+	if res["type"] != "resource" || embedded["uri"] != "tutu://artifacts/img-1" || len(res) != 2 {
+		t.Errorf("resource block = %v", res)
+	}
+}
+
+func TestGateway_ToolResult_InvalidContent(t *testing.T) {
+	gw := newTestGateway(t)
+	tests := []struct {
+		name  string
+		block contentBlock
+	}{
+		{"image without mime", contentBlock{Type: "image", Data: "iVBORw=="}},
+		{"image bad base64", contentBlock{Type: "image", Data: "not base64!", MimeType: "image/png"}},
+		{"image with text", contentBlock{Type: "image", Data: "iVBORw==", MimeType: "image/png", Text: "x"}},
+		{"resource without uri", contentBlock{Type: "resource", Resource: &domain.MCPResourceContent{}}},
+		{"text with data", contentBlock{Type: "text", Text: "x", Data: "iVBORw=="}},
+		{"unknown type", contentBlock{Type: "audio"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := gw.toolResultContent(1, textContent("ok"), tt.block)
+			if resp.Error == nil || resp.Error.Code != CodeInternalError {
+				t.Errorf("expected internal error, got %+v", resp.Error)
+			}
+		})
+	}
+}
+
 func TestGateway_ToolsCall_Inference_MissingModel(t *testing.T) {
 	gw := newTestGateway(t)
 	raw := rpcRequest("tools/call", toolsCallParams{
@@ -486,6 +1019,35 @@ func TestGateway_ToolsCall_Inference_MissingPrompt(t *testing.T) {
 	}
 }
 
+func TestGateway_ToolsCall_Inference_Messages(t *testing.T) {
+	gw := newTestGateway(t)
+	call := func(msgs []domain.ChatMessage) *Response {
+		return gw.HandleRequest(rpcRequest("tools/call", toolsCallParams{
+			Name:      "tutu_inference",
+			Arguments: mustMarshal(domain.InferenceParams{Model: "llama-7b", Messages: msgs}),
+		}))
+	}
+
+	resp := call([]domain.ChatMessage{
+		{Role: domain.RoleSystem, Content: "be brief"},
+		{Role: domain.RoleUser, Content: "Hello, world!"},
+	})
+	if resp.Error != nil {
+		t.Fatalf("valid conversation rejected: %v", resp.Error)
+	}
+
+	resp = call([]domain.ChatMessage{
+		{Role: domain.RoleUser, Content: "Hello"},
+		{Role: domain.RoleAssistant, Content: "Hi"},
+	})
+	if resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+		t.Fatalf("conversation ending with the assistant: error = %v, want invalid params", resp.Error)
+	}
+	if !strings.Contains(resp.Error.Message, "invalid chat conversation") {
+		t.Errorf("error message = %q", resp.Error.Message)
+	}
+}
+
 func TestGateway_ToolsCall_Embed(t *testing.T) {
 	gw := newTestGateway(t)
 	raw := rpcRequest("tools/call", toolsCallParams{
@@ -535,33 +1097,525 @@ func TestGateway_ToolsCall_Batch(t *testing.T) {
 	}
 }
 
-func TestGateway_ToolsCall_FineTune(t *testing.T) {
-	gw := newTestGateway(t)
-	raw := rpcRequest("tools/call", toolsCallParams{
-		Name: "tutu_fine_tune",
-		Arguments: mustMarshal(domain.FineTuneParams{
-			BaseModel:  "llama-7b",
-			DatasetURI: "s3://my-bucket/data.jsonl",
-			Epochs:     5,
-			LoRA:       true,
-		}),
-	})
-
-	resp := gw.HandleRequest(raw)
+// callStatusTool invokes tutu_status and decodes its embedded JSON resource.
+func callStatusTool(t *testing.T, gw *Gateway) domain.GatewayStatus {
+	t.Helper()
+	resp := gw.HandleRequest(rpcRequest("tools/call", toolsCallParams{Name: "tutu_status"}))
 	if resp.Error != nil {
-		t.Fatalf("unexpected error: %v", resp.Error)
+		t.Fatalf("tutu_status error: %v", resp.Error)
 	}
-
 	var result toolsCallResult
-	json.Unmarshal(resp.Result, &result)
-	if !strings.Contains(result.Content[0].Text, "lora=true") {
-		t.Error("response should mention LoRA")
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Content) != 1 || result.Content[0].Resource == nil {
+		t.Fatalf("content = %+v, want one resource block", result.Content)
 	}
+	res := result.Content[0].Resource
+	if res.URI != "tutu://status" || res.MimeType != "application/json" {
+		t.Errorf("resource = %s (%s), want tutu://status as JSON", res.URI, res.MimeType)
+	}
+	var st domain.GatewayStatus
+	if err := json.Unmarshal([]byte(res.Text), &st); err != nil {
+		t.Fatalf("decode status %q: %v", res.Text, err)
+	}
+	return st
 }
 
-func TestGateway_ToolsCall_UnknownTool(t *testing.T) {
+func TestGateway_ToolsCall_Status(t *testing.T) {
 	gw := newTestGateway(t)
-	raw := rpcRequest("tools/call", toolsCallParams{
+
+	st := callStatusTool(t, gw)
+	if st.Sessions != 0 || st.QueueDepth != 0 || st.ActiveIncidents != 0 || len(st.ActiveModels) != 0 || st.SLACompliance != 1 {
+		t.Errorf("status without providers = %+v, want zeros and full compliance", st)
+	}
+
+	sessions, queued, incidents := 2, 7, 1
+	models := []string{"phi3", "llama3"}
+	gw.SetStatusProviders(StatusProviders{
+		Sessions:        func() int { return sessions },
+		ActiveModels:    func() []string { return slices.Clone(models) },
+		QueueDepth:      func() int { return queued },
+		ActiveIncidents: func() int { return incidents },
+	})
+	gw.meter.Record("c1", "tutu_inference", "llama3", 10, 10, 100, domain.SLARealtime) // within 200ms
+	gw.meter.Record("c1", "tutu_inference", "llama3", 10, 10, 500, domain.SLARealtime) // late
+	gw.meter.Record("c1", "tutu_inference", "llama3", 10, 10, 9000, domain.SLASpot)    // no target
+
+	st = callStatusTool(t, gw)
+	want := domain.GatewayStatus{Sessions: 2, ActiveModels: []string{"llama3", "phi3"}, QueueDepth: 7, SLACompliance: 0.5, ActiveIncidents: 1}
+	if !reflect.DeepEqual(st, want) {
+		t.Errorf("status = %+v, want %+v", st, want)
+	}
+
+	// Figures are read live on every call.
+	sessions, queued, incidents = 0, 3, 0
+	models = nil
+	st = callStatusTool(t, gw)
+	want = domain.GatewayStatus{ActiveModels: []string{}, QueueDepth: 3, SLACompliance: 0.5}
+	if !reflect.DeepEqual(st, want) {
+		t.Errorf("status after change = %+v, want %+v", st, want)
+	}
+}
+
+// argsOfSize returns inference arguments exactly n bytes long.
+func argsOfSize(t *testing.T, n int) json.RawMessage {
+	t.Helper()
+	base := mustMarshal(domain.InferenceParams{Model: "llama-7b", Prompt: ""})
+	if n < len(base) {
+		t.Fatalf("size %d is below the %d-byte minimum", n, len(base))
+	}
+	return mustMarshal(domain.InferenceParams{Model: "llama-7b", Prompt: strings.Repeat("x", n-len(base))})
+}
+
+func TestGateway_ToolsCall_ArgsSizeLimit(t *testing.T) {
+	gw := newTestGateway(t)
+	limit := gw.ToolArgsLimit("tutu_inference")
+	if batch := gw.ToolArgsLimit("tutu_batch_process"); limit >= batch {
+		t.Errorf("inference limit %d should be below batch limit %d", limit, batch)
+	}
+
+	call := func(args json.RawMessage) *Response {
+		return gw.HandleRequest(rpcRequest("tools/call", toolsCallParams{Name: "tutu_inference", Arguments: args}))
+	}
+	if resp := call(argsOfSize(t, limit)); resp.Error != nil {
+		t.Fatalf("arguments at the limit rejected: %v", resp.Error)
+	}
+	metered := gw.meter.TotalRecords()
+
+	resp := call(argsOfSize(t, limit+1))
+	if resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+		t.Fatalf("error = %+v, want InvalidParams", resp.Error)
+	}
+	if !strings.Contains(resp.Error.Message, fmt.Sprint(limit)) {
+		t.Errorf("message %q should state the limit %d", resp.Error.Message, limit)
+	}
+	if gw.meter.TotalRecords() != metered {
+		t.Error("oversized call reached the inference handler")
+	}
+}
+
+func TestGateway_ToolsCall_OversizedParamsRefusedRaw(t *testing.T) {
+	gw := newTestGateway(t)
+	if batch := gw.ToolArgsLimit("tutu_batch_process"); batch >= DefaultMaxBodyBytes {
+		t.Errorf("batch limit %d should be below the %d-byte body cap", batch, DefaultMaxBodyBytes)
+	}
+
+	pad := strings.Repeat("x", gw.maxToolArgsLimit()+paramsEnvelope)
+	raw := json.RawMessage(`{"name":"tutu_status","arguments":{"pad":"` + pad + `"}}`)
+	resp := gw.HandleRequest(rpcRequestRaw("tools/call", raw))
+	if resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+		t.Fatalf("error = %+v, want InvalidParams", resp.Error)
+	}
+	if !strings.Contains(resp.Error.Message, "tools/call params are") {
+		t.Errorf("message %q, want the raw params refused before decoding", resp.Error.Message)
+	}
+}
+
+func TestGateway_SetToolArgsLimit(t *testing.T) {
+	gw := newTestGateway(t)
+	ran := 0
+	gw.SetBatchRunner(func(model, prompt string) (string, int, error) {
+		ran++
+		return "ok", 1, nil
+	})
+	gw.SetToolArgsLimit("tutu_batch_process", 64)
+
+	args := mustMarshal(domain.BatchParams{Model: "llama-7b", Prompts: []string{strings.Repeat("p", 64)}})
+	resp := gw.HandleRequest(rpcRequest("tools/call", toolsCallParams{Name: "tutu_batch_process", Arguments: args}))
+	if resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+		t.Fatalf("error = %+v, want InvalidParams", resp.Error)
+	}
+	if ran != 0 {
+		t.Errorf("batch runner ran %d times, want 0", ran)
+	}
+
+	gw.SetToolArgsLimit("tutu_batch_process", 0)
+	if got := gw.ToolArgsLimit("tutu_batch_process"); got != defaultToolArgsLimits["tutu_batch_process"] {
+		t.Errorf("limit after reset = %d, want the built-in %d", got, defaultToolArgsLimits["tutu_batch_process"])
+	}
+	if got := gw.ToolArgsLimit("unknown_tool"); got != DefaultToolArgsLimit {
+		t.Errorf("limit for a tool without one = %d, want %d", got, DefaultToolArgsLimit)
+	}
+}
+
+// batchResult decodes the BatchResult carried in a batch tool response.
+func batchResult(t *testing.T, resp *Response) domain.BatchResult {
+	t.Helper()
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	var result toolsCallResult
+	json.Unmarshal(resp.Result, &result)
+	var br domain.BatchResult
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &br); err != nil {
+		t.Fatalf("decode batch result: %v", err)
+	}
+	return br
+}
+
+func TestGateway_ToolsCall_Batch_PartialFailure(t *testing.T) {
+	gw := newTestGateway(t)
+	gw.SetBatchRunner(func(model, prompt string) (string, int, error) {
+		if prompt == "bad" {
+			return "", 0, errors.New("context length exceeded")
+		}
+		return "out:" + prompt, 3, nil
+	})
+	prompts := []string{"first", "bad", "third", "fourth"}
+	resp := gw.HandleRequest(rpcRequest("tools/call", toolsCallParams{
+		Name:      "tutu_batch_process",
+		Arguments: mustMarshal(domain.BatchParams{Model: "llama-7b", Prompts: prompts}),
+	}))
+
+	br := batchResult(t, resp)
+	if len(br.Results) != len(prompts) {
+		t.Fatalf("results = %d, want %d", len(br.Results), len(prompts))
+	}
+	for i, item := range br.Results {
+		if item.Index != i {
+			t.Errorf("results[%d].Index = %d", i, item.Index)
+		}
+		if prompts[i] == "bad" {
+			if item.Status != domain.BatchItemError || item.Error != "context length exceeded" || item.Output != "" {
+				t.Errorf("results[%d] = %+v, want error", i, item)
+			}
+			continue
+		}
+		if item.Status != domain.BatchItemOK || item.Output != "out:"+prompts[i] || item.OutputTokens != 3 {
+			t.Errorf("results[%d] = %+v, want ok output for %q", i, item, prompts[i])
+		}
+	}
+	if br.Succeeded != 3 || br.Failed != 1 {
+		t.Errorf("succeeded/failed = %d/%d, want 3/1", br.Succeeded, br.Failed)
+	}
+	if got := br.FailedIndices(); len(got) != 1 || got[0] != 1 {
+		t.Errorf("FailedIndices() = %v, want [1]", got)
+	}
+
+	// Only the successful prompts are billed.
+	rec := gw.meter.RecentRecords(1)[0]
+	if rec.OutputToks != 9 {
+		t.Errorf("metered output tokens = %d, want 9", rec.OutputToks)
+	}
+}
+
+func TestGateway_ToolsCall_Batch_DefaultRunnerRejectsBlankPrompt(t *testing.T) {
+	gw := newTestGateway(t)
+	resp := gw.HandleRequest(rpcRequest("tools/call", toolsCallParams{
+		Name:      "tutu_batch_process",
+		Arguments: mustMarshal(domain.BatchParams{Model: "llama-7b", Prompts: []string{"hello there", "  "}}),
+	}))
+
+	br := batchResult(t, resp)
+	if br.Model != "llama-7b" || br.Tier != domain.SLABatch {
+		t.Errorf("model/tier = %s/%s, want llama-7b/batch", br.Model, br.Tier)
+	}
+	if br.Results[0].Status != domain.BatchItemOK || br.Results[1].Status != domain.BatchItemError {
+		t.Errorf("statuses = %s, %s; want ok, error", br.Results[0].Status, br.Results[1].Status)
+	}
+}
+
+func TestGateway_ToolsCall_Batch_Progress(t *testing.T) {
+	gw := newTestGateway(t)
+	raw := rpcRequest("tools/call", toolsCallParams{
+		Name: "tutu_batch_process",
+		Arguments: mustMarshal(domain.BatchParams{
+			Model:   "llama-7b",
+			Prompts: []string{"p1", "p2", "p3", "p4"},
+		}),
+		Meta: &requestMeta{ProgressToken: "batch-1"},
+	})
+
+	var notes []Notification
+	resp := gw.HandleRequestNotify(raw, func(n Notification) { notes = append(notes, n) })
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+
+	if len(notes) != 4 {
+		t.Fatalf("progress notifications = %d, want 4", len(notes))
+	}
+	for i, n := range notes {
+		if n.Method != "notifications/progress" {
+			t.Errorf("method = %q, want notifications/progress", n.Method)
+		}
+		var p progressParams
+		json.Unmarshal(n.Params, &p)
+		if p.ProgressToken != "batch-1" {
+			t.Errorf("progressToken = %v, want batch-1", p.ProgressToken)
+		}
+		if p.Progress != i+1 || p.Total != 4 {
+			t.Errorf("notification %d: progress %d/%d, want %d/4", i, p.Progress, p.Total, i+1)
+		}
+		if want := fmt.Sprintf("%d/4 prompts processed", i+1); p.Message != want {
+			t.Errorf("message = %q, want %q", p.Message, want)
+		}
+	}
+}
+
+func TestGateway_ToolsCall_Batch_NoProgressWithoutToken(t *testing.T) {
+	gw := newTestGateway(t)
+	raw := rpcRequest("tools/call", toolsCallParams{
+		Name: "tutu_batch_process",
+		Arguments: mustMarshal(domain.BatchParams{
+			Model:   "llama-7b",
+			Prompts: []string{"p1", "p2"},
+		}),
+	})
+
+	notified := 0
+	gw.HandleRequestNotify(raw, func(Notification) { notified++ })
+	if notified != 0 {
+		t.Errorf("notifications = %d, want 0 without progressToken", notified)
+	}
+}
+
+func TestGateway_ToolsCall_Batch_ProgressThinned(t *testing.T) {
+	gw := newTestGateway(t)
+	prompts := make([]string, 1000)
+	for i := range prompts {
+		prompts[i] = "prompt"
+	}
+	raw := rpcRequest("tools/call", toolsCallParams{
+		Name:      "tutu_batch_process",
+		Arguments: mustMarshal(domain.BatchParams{Model: "llama-7b", Prompts: prompts}),
+		Meta:      &requestMeta{ProgressToken: 7},
+	})
+
+	var last progressParams
+	count := 0
+	gw.HandleRequestNotify(raw, func(n Notification) {
+		count++
+		json.Unmarshal(n.Params, &last)
+	})
+	if count > maxProgressUpdates {
+		t.Errorf("notifications = %d, want <= %d", count, maxProgressUpdates)
+	}
+	if last.Progress != 1000 || last.Total != 1000 {
+		t.Errorf("final progress = %d/%d, want 1000/1000", last.Progress, last.Total)
+	}
+}
+
+// tokenStreamer returns a streamer replaying toks, then closing the channel.
+func tokenStreamer(toks ...domain.Token) InferenceStreamer {
+	return func(context.Context, domain.InferenceParams) (<-chan domain.Token, error) {
+		ch := make(chan domain.Token, len(toks))
+		for _, tok := range toks {
+			ch <- tok
+		}
+		close(ch)
+		return ch, nil
+	}
+}
+
+func callStreamedInference(t *testing.T, gw *Gateway) (toolsCallResult, []Notification) {
+	t.Helper()
+	raw := rpcRequest("tools/call", toolsCallParams{
+		Name:      "tutu_inference",
+		Arguments: mustMarshal(domain.InferenceParams{Model: "llama-7b", Prompt: "hi", Stream: true}),
+		Meta:      &requestMeta{ProgressToken: "s-1"},
+	})
+	var notes []Notification
+	resp := gw.HandleRequestNotify(raw, func(n Notification) { notes = append(notes, n) })
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	var result toolsCallResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	return result, notes
+}
+
+func TestGateway_ToolsCall_InferenceStream(t *testing.T) {
+	gw := newTestGateway(t)
+	gw.SetInferenceStreamer(tokenStreamer(
+		domain.Token{Text: "Hello"},
+		domain.Token{Text: " world"},
+		domain.Token{Done: true, FinishReason: domain.FinishStop},
+	))
+
+	result, notes := callStreamedInference(t, gw)
+	if result.IsError {
+		t.Error("isError set on a completed stream")
+	}
+	if len(result.Content) != 1 || result.Content[0].Text != "Hello world" {
+		t.Errorf("content = %+v, want the streamed text", result.Content)
+	}
+	if len(notes) != 2 {
+		t.Fatalf("notifications = %d, want one progress update per chunk", len(notes))
+	}
+	for _, n := range notes {
+		if n.Method != "notifications/progress" {
+			t.Errorf("method = %q, want notifications/progress", n.Method)
+		}
+	}
+}
+
+func TestGateway_ToolsCall_InferenceStream_SmallerQuant(t *testing.T) {
+	gw := newTestGateway(t)
+	gw.SetInferenceStreamer(tokenStreamer(
+		domain.Token{Text: "Hello"},
+		domain.Token{Done: true, FinishReason: domain.FinishStop, Model: "llama-7b:Q4_K_M"},
+	))
+
+	result, notes := callStreamedInference(t, gw)
+	if result.IsError {
+		t.Error("isError set on a completed stream")
+	}
+	if len(result.Content) != 2 || result.Content[0].Text != "Hello" || !strings.Contains(result.Content[1].Text, "served by llama-7b:Q4_K_M") {
+		t.Fatalf("content = %+v, want the text then the serving model", result.Content)
+	}
+	last := notes[len(notes)-1]
+	var msg struct {
+		Level string            `json:"level"`
+		Data  modelSubstitution `json:"data"`
+	}
+	json.Unmarshal(last.Params, &msg)
+	if last.Method != "notifications/message" || msg.Level != "warning" || msg.Data.Requested != "llama-7b" || msg.Data.Served != "llama-7b:Q4_K_M" {
+		t.Errorf("substitution notification = %s %+v", last.Method, msg)
+	}
+}
+
+func TestGateway_ToolsCall_InferenceStream_BackendDies(t *testing.T) {
+	gw := newTestGateway(t)
+	// The backend sends two chunks and then closes without a Done token.
+	gw.SetInferenceStreamer(tokenStreamer(domain.Token{Text: "Hello"}, domain.Token{Text: " wor"}))
+
+	result, notes := callStreamedInference(t, gw)
+	if !result.IsError {
+		t.Fatal("isError not set on an interrupted stream")
+	}
+	if len(result.Content) != 2 || result.Content[0].Text != "Hello wor" {
+		t.Fatalf("content = %+v, want partial text then the reason", result.Content)
+	}
+	if !strings.Contains(result.Content[1].Text, "stream interrupted after 2 tokens") {
+		t.Errorf("reason = %q", result.Content[1].Text)
+	}
+
+	if len(notes) != 3 {
+		t.Fatalf("notifications = %d, want 2 progress + 1 error", len(notes))
+	}
+	last := notes[len(notes)-1]
+	if last.Method != "notifications/message" {
+		t.Fatalf("terminator method = %q, want notifications/message", last.Method)
+	}
+	var msg struct {
+		Level string      `json:"level"`
+		Data  streamAbort `json:"data"`
+	}
+	json.Unmarshal(last.Params, &msg)
+	if msg.Level != "error" || msg.Data.Model != "llama-7b" || msg.Data.OutputTokens != 2 || msg.Data.Error == "" {
+		t.Errorf("error notification = %+v", msg)
+	}
+}
+
+func TestGateway_ToolsCall_InferenceStream_StartFails(t *testing.T) {
+	gw := newTestGateway(t)
+	gw.SetInferenceStreamer(func(context.Context, domain.InferenceParams) (<-chan domain.Token, error) {
+		return nil, errors.New("no backend for llama-7b")
+	})
+
+	result, notes := callStreamedInference(t, gw)
+	if !result.IsError || len(result.Content) != 1 || !strings.Contains(result.Content[0].Text, "no backend") {
+		t.Errorf("result = %+v, want an isError result with the cause", result)
+	}
+	if len(notes) != 1 || notes[0].Method != "notifications/message" {
+		t.Errorf("notifications = %+v, want one error message", notes)
+	}
+}
+
+func TestGateway_ToolsCall_InferenceStream_ClientGone(t *testing.T) {
+	gw := newTestGateway(t)
+	// The backend sends one chunk and then generates until canceled.
+	gw.SetInferenceStreamer(func(ctx context.Context, _ domain.InferenceParams) (<-chan domain.Token, error) {
+		ch := make(chan domain.Token, 1)
+		ch <- domain.Token{Text: "Hello"}
+		go func() {
+			<-ctx.Done()
+			close(ch)
+		}()
+		return ch, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	raw := rpcRequest("tools/call", toolsCallParams{
+		Name:      "tutu_inference",
+		Arguments: mustMarshal(domain.InferenceParams{Model: "llama-7b", Prompt: "hi", Stream: true}),
+		Meta:      &requestMeta{ProgressToken: "s-1"},
+	})
+	// The client hangs up once the first chunk arrives.
+	resp := gw.HandleClientRequestContext(ctx, raw, AnonymousClientID, func(n Notification) {
+		if n.Method == "notifications/progress" {
+			cancel()
+		}
+	})
+	var result toolsCallResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError || len(result.Content) != 2 || !strings.Contains(result.Content[1].Text, "canceled") {
+		t.Errorf("result = %+v, want an interrupted stream naming the cancellation", result)
+	}
+}
+
+func TestGateway_ToolsCall_FineTune(t *testing.T) {
+	gw := newTestGateway(t)
+	raw := rpcRequest("tools/call", toolsCallParams{
+		Name: "tutu_fine_tune",
+		Arguments: mustMarshal(domain.FineTuneParams{
+			BaseModel:  "llama-7b",
+			DatasetURI: "s3://my-bucket/data.jsonl",
+			Epochs:     5,
+			LoRA:       true,
+		}),
+	})
+
+	resp := gw.HandleRequest(raw)
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+
+	var result toolsCallResult
+	json.Unmarshal(resp.Result, &result)
+	if !strings.Contains(result.Content[0].Text, "lora=true") {
+		t.Error("response should mention LoRA")
+	}
+}
+
+func TestGateway_ToolsCall_FineTune_IndeterminateProgress(t *testing.T) {
+	gw := newTestGateway(t)
+	raw := rpcRequest("tools/call", toolsCallParams{
+		Name: "tutu_fine_tune",
+		Arguments: mustMarshal(domain.FineTuneParams{
+			BaseModel:  "llama-7b",
+			DatasetURI: "s3://my-bucket/data.jsonl",
+			Epochs:     5,
+		}),
+		Meta: &requestMeta{ProgressToken: "ft-1"},
+	})
+
+	var notes []Notification
+	if resp := gw.HandleRequestNotify(raw, func(n Notification) { notes = append(notes, n) }); resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	if len(notes) != 1 {
+		t.Fatalf("progress notifications = %d, want 1", len(notes))
+	}
+	var p map[string]any
+	json.Unmarshal(notes[0].Params, &p)
+	if _, ok := p["total"]; ok || p["progress"] != float64(0) {
+		t.Errorf("progress = %v, want indeterminate (no total, no epochs claimed done)", p)
+	}
+	if msg, _ := p["message"].(string); !strings.Contains(msg, "queued") {
+		t.Errorf("message = %q, want it to say the job was queued", msg)
+	}
+}
+
+func TestGateway_ToolsCall_UnknownTool(t *testing.T) {
+	gw := newTestGateway(t)
+	raw := rpcRequest("tools/call", toolsCallParams{
 		Name:      "unknown_tool",
 		Arguments: mustMarshal(map[string]string{}),
 	})
@@ -610,6 +1664,193 @@ func TestGateway_ResourcesRead_Models(t *testing.T) {
 	}
 }
 
+func TestGateway_ResourcesRead_Models_FromRegistry(t *testing.T) {
+	gw := newTestGateway(t)
+	installed := []domain.ModelInfo{{Name: "tinyllama:latest", Format: "gguf", SizeBytes: 1024}}
+	gw.SetModelLister(func() ([]domain.ModelInfo, error) { return installed, nil })
+
+	read := func() []domain.ModelInfo {
+		t.Helper()
+		resp := gw.HandleRequest(rpcRequest("resources/read", resourcesReadParams{URI: "tutu://models"}))
+		if resp.Error != nil {
+			t.Fatalf("unexpected error: %v", resp.Error)
+		}
+		var result resourcesReadResult
+		json.Unmarshal(resp.Result, &result)
+		var models []domain.ModelInfo
+		if err := json.Unmarshal([]byte(result.Contents[0].Text), &models); err != nil {
+			t.Fatalf("decode models: %v", err)
+		}
+		return models
+	}
+
+	if got := read(); len(got) != 1 || got[0].Name != "tinyllama:latest" {
+		t.Errorf("models = %+v, want tinyllama:latest", got)
+	}
+
+	// A newly pulled model shows up once the cached read is invalidated.
+	installed = append(installed, domain.ModelInfo{Name: "phi3:latest", Format: "gguf"})
+	gw.InvalidateResource("tutu://models")
+	if got := read(); len(got) != 2 || got[1].Name != "phi3:latest" {
+		t.Errorf("models after pull = %+v, want phi3:latest added", got)
+	}
+
+	gw.SetModelLister(func() ([]domain.ModelInfo, error) { return nil, errors.New("db closed") })
+	resp := gw.HandleRequest(rpcRequest("resources/read", resourcesReadParams{URI: "tutu://models"}))
+	if resp.Error == nil {
+		t.Error("lister failure should surface as an error")
+	}
+}
+
+func readCatalog(t *testing.T, gw *Gateway) []domain.CatalogModel {
+	t.Helper()
+	var models []domain.CatalogModel
+	if err := json.Unmarshal([]byte(readModelsText(t, gw)), &models); err != nil {
+		t.Fatalf("decode catalog: %v", err)
+	}
+	return models
+}
+
+func TestGateway_ResourcesRead_Models_Requirements(t *testing.T) {
+	gw := newTestGateway(t)
+	variants := make(map[string]domain.CatalogVariant)
+	for _, m := range readCatalog(t, gw) {
+		for _, v := range m.Variants {
+			variants[v.Name] = v
+		}
+	}
+	big, small := variants["llama-3.2-70b:Q4_K_M"], variants["llama-3.2-1b:Q8_0"]
+	if small.VRAMBytes == 0 || big.VRAMBytes <= small.VRAMBytes || big.MinRAMBytes <= small.MinRAMBytes {
+		t.Errorf("70B Q4 = %+v, want higher requirements than 1B Q8 = %+v", big, small)
+	}
+	if big.RecommendedTier != domain.HardwareTierHigh {
+		t.Errorf("70B Q4 tier = %q, want high", big.RecommendedTier)
+	}
+}
+
+func TestGateway_ResourcesRead_Models_FromCatalog(t *testing.T) {
+	gw := newTestGateway(t)
+	gw.SetModelLister(func() ([]domain.ModelInfo, error) { return nil, errors.New("lister should not be used") })
+	req, _ := domain.EstimateRequirements("8B", "Q4_K_M")
+	gw.SetModelCatalog(func() ([]domain.CatalogModel, error) {
+		return []domain.CatalogModel{{
+			Name:       "llama3",
+			Parameters: "8B",
+			Variants:   []domain.CatalogVariant{{Name: "llama3:8b", Quantization: "Q4_K_M", ModelRequirements: req}},
+		}}, nil
+	})
+
+	got := readCatalog(t, gw)
+	if len(got) != 1 || len(got[0].Variants) != 1 || got[0].Variants[0].ModelRequirements != req {
+		t.Errorf("catalog = %+v, want llama3:8b with its requirements", got)
+	}
+}
+
+// countingModels returns a model lister that counts its calls.
+func countingModels(calls *int) ModelLister {
+	return func() ([]domain.ModelInfo, error) {
+		*calls++
+		return []domain.ModelInfo{{Name: fmt.Sprintf("model-v%d", *calls)}}, nil
+	}
+}
+
+func readModelsText(t *testing.T, gw *Gateway) string {
+	t.Helper()
+	resp := gw.HandleRequest(rpcRequest("resources/read", resourcesReadParams{URI: "tutu://models"}))
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+	var result resourcesReadResult
+	json.Unmarshal(resp.Result, &result)
+	return result.Contents[0].Text
+}
+
+func TestGateway_ResourcesRead_CachedWithinTTL(t *testing.T) {
+	gw := newTestGateway(t)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	gw.cache.now = func() time.Time { return now }
+	calls := 0
+	gw.SetModelLister(countingModels(&calls))
+	gw.SetResourceTTL("tutu://models", 10*time.Second)
+
+	first := readModelsText(t, gw)
+	now = now.Add(9 * time.Second)
+	if second := readModelsText(t, gw); second != first || calls != 1 {
+		t.Errorf("read within TTL: calls = %d, want 1 (text %q vs %q)", calls, second, first)
+	}
+
+	now = now.Add(2 * time.Second)
+	if third := readModelsText(t, gw); third == first || calls != 2 {
+		t.Errorf("read after expiry: calls = %d, want 2", calls)
+	}
+}
+
+func TestGateway_ResourcesRead_TTLZeroDisablesCache(t *testing.T) {
+	gw := newTestGateway(t)
+	calls := 0
+	gw.SetModelLister(countingModels(&calls))
+	gw.SetResourceTTL("tutu://models", 0)
+
+	readModelsText(t, gw)
+	readModelsText(t, gw)
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2 with caching disabled", calls)
+	}
+}
+
+func TestGateway_ResourcesRead_InvalidatedByUpdateNotification(t *testing.T) {
+	gw := newTestGateway(t)
+	calls := 0
+	gw.SetModelLister(countingModels(&calls))
+
+	readModelsText(t, gw)
+	readModelsText(t, gw)
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1 before invalidation", calls)
+	}
+
+	// Updating another resource leaves the models cache alone.
+	gw.HandleRequest([]byte(`{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"tutu://capacity"}}`))
+	readModelsText(t, gw)
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 after unrelated update", calls)
+	}
+
+	gw.HandleRequest([]byte(`{"jsonrpc":"2.0","method":"notifications/resources/updated","params":{"uri":"tutu://models"}}`))
+	readModelsText(t, gw)
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2 after models update", calls)
+	}
+
+	// No uri invalidates everything.
+	gw.HandleRequest([]byte(`{"jsonrpc":"2.0","method":"notifications/resources/updated"}`))
+	readModelsText(t, gw)
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3 after blanket update", calls)
+	}
+}
+
+func TestGateway_ResourcesRead_ErrorsNotCached(t *testing.T) {
+	gw := newTestGateway(t)
+	fail := true
+	calls := 0
+	gw.SetModelLister(func() ([]domain.ModelInfo, error) {
+		calls++
+		if fail {
+			return nil, errors.New("db busy")
+		}
+		return nil, nil
+	})
+
+	if resp := gw.HandleRequest(rpcRequest("resources/read", resourcesReadParams{URI: "tutu://models"})); resp.Error == nil {
+		t.Fatal("expected error")
+	}
+	fail = false
+	if got := readModelsText(t, gw); got != "[]" || calls != 2 {
+		t.Errorf("after recovery: text = %q, calls = %d; want [] and 2", got, calls)
+	}
+}
+
 func TestGateway_ResourcesRead_UnknownURI(t *testing.T) {
 	gw := newTestGateway(t)
 	raw := rpcRequest("resources/read", resourcesReadParams{URI: "tutu://unknown"})
@@ -654,6 +1895,74 @@ func TestGateway_Inference_MetersUsage(t *testing.T) {
 	}
 }
 
+func TestGateway_MethodStats_Accumulate(t *testing.T) {
+	gw := newTestGateway(t)
+	gw.HandleRequest(rpcRequest("ping", nil))
+	gw.HandleRequest(rpcRequest("ping", nil))
+	gw.HandleRequest(rpcRequest("tools/list", nil))
+	gw.HandleRequest(rpcRequest("resources/read", map[string]string{"uri": "tutu://capacity"}))
+	gw.HandleRequest(rpcRequest("resources/read", map[string]string{"uri": "tutu://nope"}))
+	gw.HandleRequest(rpcRequest("made/up", nil))
+	gw.HandleRequest(rpcRequest("also/made/up", nil))
+
+	stats := gw.MethodStats()
+	if s := stats["ping"]; s.Count != 2 || s.Errors != 0 {
+		t.Errorf("ping = %+v, want 2 requests, 0 errors", s)
+	}
+	if s := stats["tools/list"]; s.Count != 1 {
+		t.Errorf("tools/list count = %d, want 1", s.Count)
+	}
+	read := stats["resources/read"]
+	if read.Count != 2 || read.Errors != 1 || read.ErrorRate() != 0.5 {
+		t.Errorf("resources/read = %+v (error rate %v), want 2 requests, 1 error", read, read.ErrorRate())
+	}
+	if read.TotalDuration < read.MaxDuration || read.MeanDuration() > read.MaxDuration {
+		t.Errorf("resources/read durations inconsistent: %+v", read)
+	}
+	if s := stats[unknownMethod]; s.Count != 2 || s.Errors != 2 {
+		t.Errorf("unknown methods = %+v, want 2 failed requests in one bucket", s)
+	}
+	if _, ok := stats["made/up"]; ok {
+		t.Error("unknown method names should not get their own entry")
+	}
+}
+
+func TestGateway_MethodStats_SlowLog(t *testing.T) {
+	gw := newTestGateway(t)
+	var logged []string
+	gw.metrics.slowLog = func(method string, took time.Duration) { logged = append(logged, method) }
+	gw.SetSlowThreshold(20 * time.Millisecond)
+	gw.SetBatchRunner(func(model, prompt string) (string, int, error) {
+		time.Sleep(30 * time.Millisecond)
+		return "ok", 1, nil
+	})
+
+	gw.HandleRequest(rpcRequest("ping", nil))
+	if len(logged) != 0 {
+		t.Fatalf("fast request logged as slow: %v", logged)
+	}
+
+	gw.HandleRequest(rpcRequest("tools/call", toolsCallParams{
+		Name:      "tutu_batch_process",
+		Arguments: mustMarshal(domain.BatchParams{Model: "llama-7b", Prompts: []string{"p1"}}),
+	}))
+	if len(logged) != 1 || logged[0] != "tools/call" {
+		t.Errorf("slow log = %v, want [tools/call]", logged)
+	}
+	if s := gw.MethodStats()["tools/call"]; s.MaxDuration < 30*time.Millisecond {
+		t.Errorf("tools/call max duration = %v, want ≥ 30ms", s.MaxDuration)
+	}
+
+	gw.SetSlowThreshold(0)
+	gw.HandleRequest(rpcRequest("tools/call", toolsCallParams{
+		Name:      "tutu_batch_process",
+		Arguments: mustMarshal(domain.BatchParams{Model: "llama-7b", Prompts: []string{"p1"}}),
+	}))
+	if len(logged) != 1 {
+		t.Errorf("threshold 0 should disable slow logging, got %v", logged)
+	}
+}
+
 // ─── Transport Tests ────────────────────────────────────────────────────────
 
 func TestTransport_Post_Initialize(t *testing.T) {
@@ -711,6 +2020,91 @@ func TestTransport_Post_ToolsCall(t *testing.T) {
 	}
 }
 
+// authedToolsCall posts a tutu_inference call, applying setHeader to the
+// request, and returns the recorder.
+func authedToolsCall(tr *Transport, setHeader func(h http.Header)) *httptest.ResponseRecorder {
+	body := rpcRequest("tools/call", toolsCallParams{
+		Name:      "tutu_inference",
+		Arguments: mustMarshal(domain.InferenceParams{Model: "llama-7b", Prompt: "test prompt"}),
+	})
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(string(body)))
+	if setHeader != nil {
+		setHeader(req.Header)
+	}
+	w := httptest.NewRecorder()
+	tr.ServeHTTP(w, req)
+	return w
+}
+
+func TestTransport_Auth_ValidKeyAttributesUsage(t *testing.T) {
+	meter := NewMeter(NewSLAEngine())
+	tr := NewTransport(NewGateway(NewSLAEngine(), meter))
+	tr.SetKeyStore(StaticKeyStore{"key-acme": "acme", "key-globex": "globex"})
+
+	if w := authedToolsCall(tr, func(h http.Header) { h.Set("Authorization", "Bearer key-acme") }); w.Code != http.StatusOK {
+		t.Fatalf("bearer key: status = %d, want 200", w.Code)
+	}
+	if w := authedToolsCall(tr, func(h http.Header) { h.Set("X-API-Key", "key-globex") }); w.Code != http.StatusOK {
+		t.Fatalf("X-API-Key: status = %d, want 200", w.Code)
+	}
+
+	for _, client := range []string{"acme", "globex"} {
+		if got := meter.ClientSummary(client).TotalCalls; got != 1 {
+			t.Errorf("%s calls = %d, want 1", client, got)
+		}
+	}
+	if got := meter.ClientSummary(AnonymousClientID).TotalCalls; got != 0 {
+		t.Errorf("anonymous calls = %d, want 0", got)
+	}
+}
+
+func TestTransport_Auth_Rejected(t *testing.T) {
+	meter := NewMeter(NewSLAEngine())
+	tr := NewTransport(NewGateway(NewSLAEngine(), meter))
+	tr.SetKeyStore(StaticKeyStore{"key-acme": "acme"})
+
+	tests := []struct {
+		name      string
+		setHeader func(h http.Header)
+	}{
+		{"invalid key", func(h http.Header) { h.Set("Authorization", "Bearer wrong") }},
+		{"missing key", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := authedToolsCall(tr, tt.setHeader)
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", w.Code)
+			}
+			if w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected WWW-Authenticate header")
+			}
+		})
+	}
+	if meter.TotalRecords() != 0 {
+		t.Errorf("rejected requests were metered: %d records", meter.TotalRecords())
+	}
+}
+
+func TestTransport_Auth_AllowAnonymous(t *testing.T) {
+	meter := NewMeter(NewSLAEngine())
+	tr := NewTransport(NewGateway(NewSLAEngine(), meter))
+	tr.SetKeyStore(StaticKeyStore{"key-acme": "acme"})
+	tr.SetAllowAnonymous(true)
+
+	if w := authedToolsCall(tr, nil); w.Code != http.StatusOK {
+		t.Fatalf("anonymous: status = %d, want 200", w.Code)
+	}
+	if got := meter.ClientSummary(AnonymousClientID).TotalCalls; got != 1 {
+		t.Errorf("anonymous calls = %d, want 1", got)
+	}
+
+	// A presented key must still be valid.
+	if w := authedToolsCall(tr, func(h http.Header) { h.Set("X-API-Key", "wrong") }); w.Code != http.StatusUnauthorized {
+		t.Errorf("invalid key with anonymous allowed: status = %d, want 401", w.Code)
+	}
+}
+
 func TestTransport_Post_EmptyBody(t *testing.T) {
 	gw := newTestGateway(t)
 	tr := NewTransport(gw)
@@ -768,6 +2162,104 @@ func TestTransport_Delete_Session(t *testing.T) {
 	}
 }
 
+// postInitialize sends an initialize request and returns the recorder.
+func postInitialize(tr *Transport) *httptest.ResponseRecorder {
+	body := rpcRequest("initialize", map[string]any{
+		"protocolVersion": "2025-03-26",
+		"clientInfo":      map[string]string{"name": "test"},
+	})
+	w := httptest.NewRecorder()
+	tr.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(string(body))))
+	return w
+}
+
+func TestTransport_MaxSessions(t *testing.T) {
+	tr := NewTransport(newTestGateway(t))
+	tr.SetMaxSessions(2)
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		w := postInitialize(tr)
+		if w.Code != http.StatusOK {
+			t.Fatalf("initialize %d: status = %d, want 200", i, w.Code)
+		}
+		ids = append(ids, w.Header().Get("Mcp-Session-Id"))
+	}
+
+	w := postInitialize(tr)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("initialize over cap: status = %d, want 503", w.Code)
+	}
+	var resp Response
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Error == nil || resp.Error.Code != CodeServerBusy {
+		t.Errorf("error = %+v, want code %d", resp.Error, CodeServerBusy)
+	}
+	if got := tr.Stats(); got.Sessions != 2 || got.MaxSessions != 2 {
+		t.Errorf("Stats() = %+v, want 2/2", got)
+	}
+
+	// Requests on existing sessions are unaffected.
+	if w := authedToolsCall(tr, func(h http.Header) { h.Set("Mcp-Session-Id", ids[0]) }); w.Code != http.StatusOK {
+		t.Errorf("tools/call at cap: status = %d, want 200", w.Code)
+	}
+
+	del := httptest.NewRequest(http.MethodDelete, "/mcp", nil)
+	del.Header.Set("Mcp-Session-Id", ids[0])
+	tr.ServeHTTP(httptest.NewRecorder(), del)
+
+	if w := postInitialize(tr); w.Code != http.StatusOK {
+		t.Errorf("initialize after delete: status = %d, want 200", w.Code)
+	}
+	if tr.SessionCount() != 2 {
+		t.Errorf("sessions = %d, want 2", tr.SessionCount())
+	}
+}
+
+func TestTransport_FailedInitializeTakesNoSlot(t *testing.T) {
+	tr := NewTransport(newTestGateway(t))
+	tr.SetMaxSessions(1)
+
+	body := rpcRequestRaw("initialize", json.RawMessage(`"not an object"`))
+	w := httptest.NewRecorder()
+	tr.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(string(body))))
+	if tr.SessionCount() != 0 {
+		t.Fatalf("sessions = %d after a failed initialize, want 0", tr.SessionCount())
+	}
+	if w := postInitialize(tr); w.Code != http.StatusOK {
+		t.Errorf("initialize after a failed one: status = %d, want 200", w.Code)
+	}
+}
+
+func TestTransport_ReapsIdleSessions(t *testing.T) {
+	tr := NewTransport(newTestGateway(t))
+	tr.SetMaxSessions(2)
+	tr.SetSessionTTL(time.Minute)
+
+	idle := postInitialize(tr).Header().Get("Mcp-Session-Id")
+	streaming := postInitialize(tr).Header().Get("Mcp-Session-Id")
+	tr.mu.Lock()
+	tr.sessions[streaming].streams = 1 // an open SSE stream keeps it alive
+	tr.mu.Unlock()
+
+	tr.reapIdle(time.Now().Add(30 * time.Second))
+	if tr.SessionCount() != 2 {
+		t.Fatalf("sessions = %d before the TTL, want 2", tr.SessionCount())
+	}
+
+	tr.reapIdle(time.Now().Add(2 * time.Minute))
+	tr.mu.RLock()
+	_, idleOpen := tr.sessions[idle]
+	_, streamOpen := tr.sessions[streaming]
+	tr.mu.RUnlock()
+	if idleOpen || !streamOpen {
+		t.Errorf("after TTL: idle open = %v, streaming open = %v; want false, true", idleOpen, streamOpen)
+	}
+	if w := postInitialize(tr); w.Code != http.StatusOK {
+		t.Errorf("initialize after reaping: status = %d, want 200", w.Code)
+	}
+}
+
 func TestTransport_Delete_UnknownSession(t *testing.T) {
 	gw := newTestGateway(t)
 	tr := NewTransport(gw)
@@ -860,6 +2352,57 @@ func TestTransport_Notify(t *testing.T) {
 	}
 }
 
+func TestTransport_ToolsCall_ProgressOverSSE(t *testing.T) {
+	gw := newTestGateway(t)
+	tr := NewTransport(gw)
+
+	// Create session
+	body := rpcRequest("initialize", map[string]any{
+		"protocolVersion": "2025-03-26",
+		"clientInfo":      map[string]string{"name": "test"},
+	})
+	initReq := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(string(body)))
+	initW := httptest.NewRecorder()
+	tr.ServeHTTP(initW, initReq)
+	sessionID := initW.Header().Get("Mcp-Session-Id")
+
+	// Batch call with a progress token on that session
+	body = rpcRequest("tools/call", toolsCallParams{
+		Name: "tutu_batch_process",
+		Arguments: mustMarshal(domain.BatchParams{
+			Model:   "llama-7b",
+			Prompts: []string{"a", "b", "c"},
+		}),
+		Meta: &requestMeta{ProgressToken: "tok"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(string(body)))
+	req.Header.Set("Mcp-Session-Id", sessionID)
+	w := httptest.NewRecorder()
+	tr.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	// Progress updates are queued on the session's SSE channel, in order
+	tr.mu.RLock()
+	sess := tr.sessions[sessionID]
+	tr.mu.RUnlock()
+	for i := 1; i <= 3; i++ {
+		select {
+		case msg := <-sess.notify:
+			var n Notification
+			json.Unmarshal(msg, &n)
+			var p progressParams
+			json.Unmarshal(n.Params, &p)
+			if n.Method != "notifications/progress" || p.Progress != i || p.Total != 3 {
+				t.Errorf("update %d = %s", i, msg)
+			}
+		default:
+			t.Fatalf("missing progress update %d", i)
+		}
+	}
+}
+
 func TestTransport_Notify_UnknownSession(t *testing.T) {
 	gw := newTestGateway(t)
 	tr := NewTransport(gw)
@@ -911,8 +2454,8 @@ func TestIntegration_FullMCPFlow(t *testing.T) {
 	respBody, _ := io.ReadAll(toolsResp.Body)
 	json.Unmarshal(respBody, &toolsResult)
 	toolsResp.Body.Close()
-	if len(toolsResult.Result.Tools) != 4 {
-		t.Fatalf("expected 4 tools, got %d", len(toolsResult.Result.Tools))
+	if len(toolsResult.Result.Tools) != 5 {
+		t.Fatalf("expected 5 tools, got %d", len(toolsResult.Result.Tools))
 	}
 
 	// 3. Call inference tool
@@ -966,7 +2509,8 @@ func TestToolSchemas_HaveRequiredFields(t *testing.T) {
 			if tool.InputSchema.Type != "object" {
 				t.Errorf("schema type = %q, want object", tool.InputSchema.Type)
 			}
-			if len(tool.InputSchema.Required) == 0 {
+			// Argument-free tools such as tutu_status need no required fields.
+			if len(tool.InputSchema.Properties) > 0 && len(tool.InputSchema.Required) == 0 {
 				t.Error("expected required fields")
 			}
 			for _, req := range tool.InputSchema.Required {
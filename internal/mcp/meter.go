@@ -1,6 +1,8 @@
 package mcp
 
 import (
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -16,9 +18,12 @@ import (
 type Meter struct {
 	mu      sync.Mutex
 	sla     *SLAEngine
-	records []domain.UsageRecord
+	records []domain.UsageRecord // ordered by Timestamp, ties in insertion order
 	// byClient indexes total tokens per client for fast summary.
 	byClient map[string]*clientAccum
+
+	// Now stamps each record — injectable for testing (default time.Now).
+	Now func() time.Time
 }
 
 // clientAccum accumulates per-client token and cost totals.
@@ -27,6 +32,9 @@ type clientAccum struct {
 	TotalInput  int64
 	TotalOutput int64
 	TotalCost   int64 // microdollars
+
+	CostByTool  map[string]int64 // microdollars per tool
+	CostByModel map[string]int64 // microdollars per model
 }
 
 // NewMeter creates a usage meter with the given SLA engine for pricing.
@@ -35,13 +43,13 @@ func NewMeter(sla *SLAEngine) *Meter {
 		sla:      sla,
 		records:  make([]domain.UsageRecord, 0, 256),
 		byClient: make(map[string]*clientAccum),
+		Now:      time.Now,
 	}
 }
 
-// Record logs a usage event. Cost is calculated from the SLA tier pricing.
+// Record logs a usage event. Cost is calculated from the SLA tier pricing,
+// less any latency penalty the tier owes for a slow call.
 func (m *Meter) Record(clientID, tool, model string, inputToks, outputToks int, latencyMs int64, tier domain.SLATier) domain.UsageRecord {
-	cost := m.sla.CostMicro(tier, inputToks, outputToks)
-
 	rec := domain.UsageRecord{
 		ClientID:   clientID,
 		Tool:       tool,
@@ -50,25 +58,120 @@ func (m *Meter) Record(clientID, tool, model string, inputToks, outputToks int,
 		OutputToks: outputToks,
 		LatencyMs:  latencyMs,
 		Tier:       tier,
-		CostMicro:  cost,
-		Timestamp:  time.Now(),
+		CostMicro:  m.sla.CostMicro(tier, inputToks, outputToks),
+		Timestamp:  m.Now(),
 	}
+	m.applyPenalty(&rec)
 
 	m.mu.Lock()
-	m.records = append(m.records, rec)
+	m.insertLocked(rec)
+	m.accumulateLocked(rec, true)
+	m.mu.Unlock()
 
-	acc, ok := m.byClient[clientID]
-	if !ok {
-		acc = &clientAccum{}
-		m.byClient[clientID] = acc
+	return rec
+}
+
+// RecordTierChange logs a call whose session moved from tier from to tier
+// to after splitAt output tokens. The call is split into two records, each
+// priced at its own tier; it still counts as a single call. Latency is
+// attributed to the second record, which completes the call. Returns
+// domain.ErrSLADowngrade if the transition is not an upgrade.
+func (m *Meter) RecordTierChange(clientID, tool, model string, inputToks, outputToks, splitAt int, latencyMs int64, from, to domain.SLATier) ([]domain.UsageRecord, error) {
+	if !from.CanUpgradeTo(to) {
+		return nil, fmt.Errorf("%s → %s: %w", from, to, domain.ErrSLADowngrade)
 	}
-	acc.TotalCalls++
-	acc.TotalInput += int64(inputToks)
-	acc.TotalOutput += int64(outputToks)
-	acc.TotalCost += cost
+
+	full := domain.UsageRecord{
+		ClientID:   clientID,
+		Tool:       tool,
+		Model:      model,
+		InputToks:  inputToks,
+		OutputToks: outputToks,
+		Tier:       from,
+		Timestamp:  m.Now(),
+	}
+	before, after := full.SplitAt(splitAt, to)
+	before.CostMicro = m.sla.CostMicro(before.Tier, before.InputToks, before.OutputToks)
+	after.CostMicro = m.sla.CostMicro(after.Tier, after.InputToks, after.OutputToks)
+	after.LatencyMs = latencyMs
+	m.applyPenalty(&after)
+
+	m.mu.Lock()
+	m.insertLocked(before)
+	m.insertLocked(after)
+	m.accumulateLocked(before, true)
+	m.accumulateLocked(after, false)
 	m.mu.Unlock()
 
-	return rec
+	return []domain.UsageRecord{before, after}, nil
+}
+
+// applyPenalty credits rec for a missed latency target. The credit is capped
+// at the call's charge, so the net cost never goes below zero.
+func (m *Meter) applyPenalty(rec *domain.UsageRecord) {
+	penalty := m.sla.PenaltyMicro(rec.Tier, time.Duration(rec.LatencyMs)*time.Millisecond)
+	if penalty < -rec.CostMicro {
+		penalty = -rec.CostMicro
+	}
+	rec.PenaltyMicro = penalty
+	rec.CostMicro += penalty
+}
+
+// insertLocked adds rec, keeping records time-ordered. Clocks almost always
+// move forward, so the search usually lands at the end; equal timestamps
+// keep insertion order. Caller must hold m.mu.
+func (m *Meter) insertLocked(rec domain.UsageRecord) {
+	i := sort.Search(len(m.records), func(i int) bool {
+		return m.records[i].Timestamp.After(rec.Timestamp)
+	})
+	m.records = append(m.records, domain.UsageRecord{})
+	copy(m.records[i+1:], m.records[i:])
+	m.records[i] = rec
+}
+
+// accumulateLocked adds rec to its client's totals; newCall counts it as a
+// separate call. Caller must hold m.mu.
+func (m *Meter) accumulateLocked(rec domain.UsageRecord, newCall bool) {
+	acc, ok := m.byClient[rec.ClientID]
+	if !ok {
+		acc = &clientAccum{
+			CostByTool:  make(map[string]int64),
+			CostByModel: make(map[string]int64),
+		}
+		m.byClient[rec.ClientID] = acc
+	}
+	if newCall {
+		acc.TotalCalls++
+	}
+	acc.TotalInput += int64(rec.InputToks)
+	acc.TotalOutput += int64(rec.OutputToks)
+	acc.TotalCost += rec.CostMicro
+	acc.CostByTool[rec.Tool] += rec.CostMicro
+	acc.CostByModel[rec.Model] += rec.CostMicro
+}
+
+// SLACompliance returns the fraction of recorded calls that finished within
+// their tier's latency target. Best-effort tiers have no target and are not
+// counted; with nothing to count, compliance is 1.
+func (m *Meter) SLACompliance() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counted, met := 0, 0
+	for _, rec := range m.records {
+		target := m.sla.ConfigFor(rec.Tier).MaxLatencyP99
+		if target <= 0 {
+			continue
+		}
+		counted++
+		if time.Duration(rec.LatencyMs)*time.Millisecond <= target {
+			met++
+		}
+	}
+	if counted == 0 {
+		return 1
+	}
+	return float64(met) / float64(counted)
 }
 
 // ClientSummary returns aggregated usage for a single client.
@@ -90,6 +193,28 @@ func (m *Meter) ClientSummary(clientID string) domain.ClientUsageSummary {
 	}
 }
 
+// CostBreakdown returns a client's spend in microdollars per tool and per
+// model. Each map sums to the client's total cost. The maps are copies and
+// are empty (non-nil) for unknown clients.
+func (m *Meter) CostBreakdown(clientID string) (byTool map[string]int64, byModel map[string]int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	byTool = make(map[string]int64)
+	byModel = make(map[string]int64)
+	acc, ok := m.byClient[clientID]
+	if !ok {
+		return byTool, byModel
+	}
+	for tool, cost := range acc.CostByTool {
+		byTool[tool] = cost
+	}
+	for model, cost := range acc.CostByModel {
+		byModel[model] = cost
+	}
+	return byTool, byModel
+}
+
 // TotalRecords returns the total number of usage records.
 func (m *Meter) TotalRecords() int {
 	m.mu.Lock()
@@ -97,7 +222,9 @@ func (m *Meter) TotalRecords() int {
 	return len(m.records)
 }
 
-// RecentRecords returns the last n usage records (most recent first).
+// RecentRecords returns the n most recent usage records by timestamp
+// (most recent first). Records with equal timestamps are returned
+// latest-inserted first.
 func (m *Meter) RecentRecords(n int) []domain.UsageRecord {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// ─── Method Metrics ─────────────────────────────────────────────────────────
+// Every dispatched request is timed per JSON-RPC method so slow or failing
+// methods stand out. Requests slower than the slow threshold are logged.
+
+// DefaultSlowMethodThreshold is how long a request may take before it is
+// logged as slow, unless overridden with Gateway.SetSlowThreshold.
+const DefaultSlowMethodThreshold = 10 * time.Second
+
+// unknownMethod buckets requests for methods the gateway does not serve,
+// so arbitrary client-chosen names cannot grow the stats table.
+const unknownMethod = "(unknown)"
+
+// MethodStats summarizes the requests seen for one method.
+type MethodStats struct {
+	Count         int64         `json:"count"`
+	Errors        int64         `json:"errors"`
+	TotalDuration time.Duration `json:"total_duration_ns"`
+	MaxDuration   time.Duration `json:"max_duration_ns"`
+}
+
+// MeanDuration returns the average request duration.
+func (s MethodStats) MeanDuration() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Count)
+}
+
+// ErrorRate returns the fraction of requests that returned an error (0–1).
+func (s MethodStats) ErrorRate() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Count)
+}
+
+// methodMetrics accumulates MethodStats per method.
+type methodMetrics struct {
+	mu      sync.Mutex
+	stats   map[string]*MethodStats
+	slow    time.Duration // 0 = slow logging off
+	now     func() time.Time
+	slowLog func(method string, took time.Duration)
+}
+
+func newMethodMetrics(slow time.Duration) *methodMetrics {
+	return &methodMetrics{
+		stats: make(map[string]*MethodStats),
+		slow:  slow,
+		now:   time.Now,
+		slowLog: func(method string, took time.Duration) {
+			log.Printf("[mcp] slow request: %s took %s", method, took.Round(time.Millisecond))
+		},
+	}
+}
+
+// record adds one request to method's stats and logs it if it was slow.
+func (m *methodMetrics) record(method string, took time.Duration, failed bool) {
+	m.mu.Lock()
+	s, ok := m.stats[method]
+	if !ok {
+		s = &MethodStats{}
+		m.stats[method] = s
+	}
+	s.Count++
+	s.TotalDuration += took
+	if took > s.MaxDuration {
+		s.MaxDuration = took
+	}
+	if failed {
+		s.Errors++
+	}
+	slow := m.slow > 0 && took >= m.slow
+	m.mu.Unlock()
+
+	if slow {
+		m.slowLog(method, took)
+	}
+}
+
+func (m *methodMetrics) snapshot() map[string]MethodStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]MethodStats, len(m.stats))
+	for method, s := range m.stats {
+		out[method] = *s
+	}
+	return out
+}
+
+func (m *methodMetrics) setSlow(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.slow = d
+}
+
+// MethodStats returns a snapshot of per-method request counts, durations,
+// and errors, keyed by JSON-RPC method name.
+func (g *Gateway) MethodStats() map[string]MethodStats {
+	return g.metrics.snapshot()
+}
+
+// SetSlowThreshold sets how long a request may take before it is logged as
+// slow. Zero or less disables slow-request logging.
+func (g *Gateway) SetSlowThreshold(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	g.metrics.setSlow(d)
+}
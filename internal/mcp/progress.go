@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ─── Progress Notifications ─────────────────────────────────────────────────
+// MCP 2025-03-26: a request may carry params._meta.progressToken. While the
+// request runs, the server sends notifications/progress carrying that token
+// so the client can show feedback for long operations (batch, fine-tune).
+// Without a token, no progress is sent.
+
+// maxProgressUpdates bounds how many notifications one operation emits,
+// so a 10k-prompt batch doesn't flood the session's SSE buffer.
+const maxProgressUpdates = 20
+
+// NotifyFunc delivers a server-initiated notification to the requesting
+// client (the Transport routes it to the session's SSE stream).
+type NotifyFunc func(Notification)
+
+// requestMeta is the MCP _meta object attached to request params.
+type requestMeta struct {
+	ProgressToken any `json:"progressToken,omitempty"` // string | int
+}
+
+// progressParams is the payload of notifications/progress.
+type progressParams struct {
+	ProgressToken any    `json:"progressToken"`
+	Progress      int    `json:"progress"`
+	Total         int    `json:"total,omitempty"`
+	Message       string `json:"message,omitempty"`
+}
+
+// progressReporter emits progress for one request. The zero value (or one
+// without a token or notifier) is a no-op.
+type progressReporter struct {
+	token  any
+	notify NotifyFunc
+}
+
+func newProgressReporter(meta *requestMeta, notify NotifyFunc) progressReporter {
+	if meta == nil || meta.ProgressToken == nil || notify == nil {
+		return progressReporter{}
+	}
+	return progressReporter{token: meta.ProgressToken, notify: notify}
+}
+
+// Report sends a progress update of done/total with a human-readable message.
+// A total of 0 is omitted, marking the progress indeterminate.
+func (p progressReporter) Report(done, total int, message string) {
+	if p.notify == nil {
+		return
+	}
+	params, err := json.Marshal(progressParams{
+		ProgressToken: p.token,
+		Progress:      done,
+		Total:         total,
+		Message:       message,
+	})
+	if err != nil {
+		return
+	}
+	p.notify(Notification{
+		JSONRPC: JSONRPCVersion,
+		Method:  "notifications/progress",
+		Params:  params,
+	})
+}
+
+// Step reports item i (1-based) of total, thinned to at most
+// maxProgressUpdates notifications. The final item is always reported.
+func (p progressReporter) Step(i, total int, unit string) {
+	stride := max(1, total/maxProgressUpdates)
+	if i%stride != 0 && i != total {
+		return
+	}
+	p.Report(i, total, fmt.Sprintf("%d/%d %s", i, total, unit))
+}
+
+// logMessageParams is the payload of notifications/message.
+type logMessageParams struct {
+	Level  string `json:"level"`
+	Logger string `json:"logger,omitempty"`
+	Data   any    `json:"data"`
+}
+
+// notifyLog sends the client a notifications/message at level (e.g.
+// "warning", "error"). A nil notify is a no-op.
+func notifyLog(notify NotifyFunc, level, logger string, data any) {
+	if notify == nil {
+		return
+	}
+	params, err := json.Marshal(logMessageParams{Level: level, Logger: logger, Data: data})
+	if err != nil {
+		return
+	}
+	notify(Notification{
+		JSONRPC: JSONRPCVersion,
+		Method:  "notifications/message",
+		Params:  params,
+	})
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Resource Cache ─────────────────────────────────────────────────────────
+// resources/read results are cached per URI so a dashboard polling every
+// second does not recompute (or, with live data sources, hammer the
+// subsystems behind) each resource. Entries expire after the resource's TTL
+// or when notifications/resources/updated names the URI.
+
+// DefaultResourceTTL is how long a resource read is served from cache
+// unless overridden with Gateway.SetResourceTTL.
+const DefaultResourceTTL = 2 * time.Second
+
+type cachedResource struct {
+	contents  []domain.MCPResourceContent
+	expiresAt time.Time
+}
+
+// resourceCache holds the most recent contents of each resource.
+type resourceCache struct {
+	mu         sync.Mutex
+	defaultTTL time.Duration
+	ttl        map[string]time.Duration // per-URI overrides
+	entries    map[string]cachedResource
+	now        func() time.Time
+}
+
+func newResourceCache(defaultTTL time.Duration) *resourceCache {
+	return &resourceCache{
+		defaultTTL: defaultTTL,
+		ttl:        make(map[string]time.Duration),
+		entries:    make(map[string]cachedResource),
+		now:        time.Now,
+	}
+}
+
+// ttlFor returns the TTL for uri. Caller must hold c.mu.
+func (c *resourceCache) ttlFor(uri string) time.Duration {
+	if ttl, ok := c.ttl[uri]; ok {
+		return ttl
+	}
+	return c.defaultTTL
+}
+
+// get returns the cached contents of uri, or compute's result — cached for
+// the URI's TTL — on a miss. Errors are never cached.
+func (c *resourceCache) get(uri string, compute func() ([]domain.MCPResourceContent, error)) ([]domain.MCPResourceContent, error) {
+	c.mu.Lock()
+	ttl := c.ttlFor(uri)
+	if e, ok := c.entries[uri]; ok && c.now().Before(e.expiresAt) {
+		c.mu.Unlock()
+		return e.contents, nil
+	}
+	c.mu.Unlock()
+
+	// Compute without the lock: a slow source must not block other URIs.
+	contents, err := compute()
+	if err != nil || ttl <= 0 {
+		return contents, err
+	}
+	c.mu.Lock()
+	c.entries[uri] = cachedResource{contents: contents, expiresAt: c.now().Add(ttl)}
+	c.mu.Unlock()
+	return contents, nil
+}
+
+// setTTL overrides the TTL for uri; 0 disables caching it.
+func (c *resourceCache) setTTL(uri string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl[uri] = ttl
+	delete(c.entries, uri)
+}
+
+// invalidate drops the cached contents of uri, or of every resource when
+// uri is empty.
+func (c *resourceCache) invalidate(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if uri == "" {
+		c.entries = make(map[string]cachedResource)
+		return
+	}
+	delete(c.entries, uri)
+}
+
+// SetResourceTTL sets how long reads of uri are served from cache.
+// A TTL of 0 disables caching for that resource.
+func (g *Gateway) SetResourceTTL(uri string, ttl time.Duration) {
+	g.cache.setTTL(uri, ttl)
+}
+
+// InvalidateResource drops the cached contents of uri (all resources if
+// uri is empty) so the next read recomputes it.
+func (g *Gateway) InvalidateResource(uri string) {
+	g.cache.invalidate(uri)
+}
@@ -28,6 +28,8 @@ func NewSLAEngine() *SLAEngine {
 				Priority:        255,
 				MaxConcurrent:   100,
 				RateLimitRPM:    600,
+
+				LatencyCreditMicro: 1000,
 			},
 			domain.SLAStandard: {
 				Tier:            domain.SLAStandard,
@@ -38,6 +40,8 @@ func NewSLAEngine() *SLAEngine {
 				Priority:        128,
 				MaxConcurrent:   50,
 				RateLimitRPM:    300,
+
+				LatencyCreditMicro: 250,
 			},
 			domain.SLABatch: {
 				Tier:            domain.SLABatch,
@@ -48,6 +52,8 @@ func NewSLAEngine() *SLAEngine {
 				Priority:        64,
 				MaxConcurrent:   20,
 				RateLimitRPM:    60,
+
+				LatencyCreditMicro: 50,
 			},
 			domain.SLASpot: {
 				Tier:            domain.SLASpot,
@@ -88,6 +94,27 @@ func (e *SLAEngine) CostMicro(tier domain.SLATier, inputToks, outputToks int) in
 	return int64(cfg.PricePerMTokens * float64(totalToks))
 }
 
+// maxPenaltyOverrun caps how many multiples of MaxLatencyP99 a late call
+// is credited for, so one stalled request cannot earn an unbounded credit.
+const maxPenaltyOverrun = 4
+
+// PenaltyMicro returns the SLA credit, in microdollars, owed for a call on
+// tier that took actualLatency. The credit is negative (it reduces cost),
+// zero for calls within MaxLatencyP99, and grows linearly with the overrun
+// up to maxPenaltyOverrun multiples of the budget. Best-effort tiers (no
+// latency target) never accrue penalties.
+func (e *SLAEngine) PenaltyMicro(tier domain.SLATier, actualLatency time.Duration) int64 {
+	cfg, ok := e.tiers[tier]
+	if !ok || cfg.MaxLatencyP99 <= 0 || cfg.LatencyCreditMicro <= 0 || actualLatency <= cfg.MaxLatencyP99 {
+		return 0
+	}
+	overrun := float64(actualLatency-cfg.MaxLatencyP99) / float64(cfg.MaxLatencyP99)
+	if overrun > maxPenaltyOverrun {
+		overrun = maxPenaltyOverrun
+	}
+	return -int64(float64(cfg.LatencyCreditMicro) * overrun)
+}
+
 // AllTiers returns all SLA configurations in priority order (highest first).
 func (e *SLAEngine) AllTiers() []domain.SLAConfig {
 	return []domain.SLAConfig{
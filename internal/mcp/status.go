@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"sort"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Gateway Status ─────────────────────────────────────────────────────────
+// tutu_status lets clients (and agents) check health conversationally. SLA
+// compliance comes from the gateway's own meter; everything else is read
+// from providers the daemon injects, at call time.
+
+// StatusProviders supply the live figures reported by tutu_status. A nil
+// provider reports zero (no models, for ActiveModels).
+type StatusProviders struct {
+	Sessions        func() int      // open MCP sessions
+	MaxSessions     func() int      // session cap; 0 = unlimited
+	ActiveModels    func() []string // loaded model names
+	QueueDepth      func() int      // tasks waiting in the scheduler
+	ActiveIncidents func() int      // unresolved self-heal incidents
+}
+
+// SetStatusProviders sets where tutu_status reads its figures. Call before
+// serving requests.
+func (g *Gateway) SetStatusProviders(p StatusProviders) {
+	g.status = p
+}
+
+// Status returns the current gateway health snapshot.
+func (g *Gateway) Status() domain.GatewayStatus {
+	st := domain.GatewayStatus{
+		ActiveModels:  []string{},
+		SLACompliance: g.meter.SLACompliance(),
+	}
+	if g.status.Sessions != nil {
+		st.Sessions = g.status.Sessions()
+	}
+	if g.status.MaxSessions != nil {
+		st.MaxSessions = g.status.MaxSessions()
+	}
+	if g.status.ActiveModels != nil {
+		if models := g.status.ActiveModels(); models != nil {
+			st.ActiveModels = models
+		}
+		sort.Strings(st.ActiveModels)
+	}
+	if g.status.QueueDepth != nil {
+		st.QueueDepth = g.status.QueueDepth()
+	}
+	if g.status.ActiveIncidents != nil {
+		st.ActiveIncidents = g.status.ActiveIncidents()
+	}
+	return st
+}
+
+// callStatus returns Status as an embedded tutu://status JSON resource.
+func (g *Gateway) callStatus(id any) Response {
+	contents, err := jsonContent("tutu://status", g.Status())
+	if err != nil {
+		return NewInternalError(id, err.Error())
+	}
+	return g.toolResultContent(id, resourceContent(contents[0]))
+}
@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Streaming Inference ────────────────────────────────────────────────────
+// tutu_inference with stream=true runs through an InferenceStreamer. Text
+// chunks go to the client as notifications/progress while the call runs
+// (when it carries a progressToken) and the tool result holds the full text.
+//
+// A backend that dies mid-stream closes its channel without a Done token.
+// The gateway then sends notifications/message at level "error" and returns
+// the partial text with isError set, so the client can tell an interrupted
+// stream from a finished one.
+//
+// When another model served the stream (a smaller quantization loaded for
+// lack of memory), the gateway sends notifications/message at level
+// "warning", meters the served model and notes it in the tool result.
+
+// InferenceStreamer starts a streamed completion for p. The channel ends
+// with a Done token; closing it without one means the stream was cut short.
+// Generation must stop, and the channel close, once ctx is done.
+type InferenceStreamer func(ctx context.Context, p domain.InferenceParams) (<-chan domain.Token, error)
+
+// SetInferenceStreamer routes streamed tutu_inference calls to stream. nil
+// keeps every call on the built-in stub. Call before serving requests.
+func (g *Gateway) SetInferenceStreamer(stream InferenceStreamer) {
+	g.streamer = stream
+}
+
+// modelSubstitution is the data of the warning sent when another model
+// served the stream.
+type modelSubstitution struct {
+	Requested string `json:"requested"`
+	Served    string `json:"served"`
+}
+
+// streamAbort is the data of the error notification sent for an
+// interrupted stream.
+type streamAbort struct {
+	Error        string `json:"error"`
+	Model        string `json:"model"`
+	OutputTokens int    `json:"output_tokens"` // tokens delivered before the abort
+}
+
+func (g *Gateway) streamInference(ctx context.Context, id any, clientID string, p domain.InferenceParams, tier domain.SLATier, inputToks int, progress progressReporter, notify NotifyFunc) Response {
+	start := time.Now()
+	ch, err := g.streamer(ctx, p)
+	if err != nil {
+		return g.abortStream(id, p.Model, "", 0, err.Error(), notify)
+	}
+
+	var sb strings.Builder
+	var final *domain.Token
+	chunks := 0
+	for tok := range ch {
+		sb.WriteString(tok.Text)
+		if tok.Text != "" {
+			chunks++
+			progress.Report(chunks, 0, tok.Text)
+		}
+		if tok.Done {
+			final = &tok
+		}
+	}
+
+	outputToks := chunks
+	if final != nil && final.Stats != nil && final.Stats.CompletionTokens > 0 {
+		outputToks = final.Stats.CompletionTokens
+	}
+	served := p.Model
+	if final != nil && final.Model != "" {
+		served = final.Model
+	}
+	g.meter.Record(clientID, "tutu_inference", served, inputToks, outputToks, time.Since(start).Milliseconds(), tier)
+
+	if final == nil {
+		reason := "backend stopped before the model finished"
+		if ctx.Err() != nil {
+			reason = "request canceled: " + ctx.Err().Error()
+		}
+		return g.abortStream(id, p.Model, sb.String(), chunks, reason, notify)
+	}
+	if served != p.Model {
+		notifyLog(notify, "warning", "tutu_inference", modelSubstitution{Requested: p.Model, Served: served})
+		return g.toolResultContent(id, textContent(sb.String()),
+			textContent(fmt.Sprintf("served by %s: not enough memory for %s", served, p.Model)))
+	}
+	return g.toolResult(id, sb.String())
+}
+
+// abortStream reports an interrupted stream: an error-level
+// notifications/message to the session, and an isError result carrying
+// whatever text arrived before the failure.
+func (g *Gateway) abortStream(id any, model, partial string, chunks int, reason string, notify NotifyFunc) Response {
+	notifyLog(notify, "error", "tutu_inference", streamAbort{Error: reason, Model: model, OutputTokens: chunks})
+
+	var blocks []contentBlock
+	if partial != "" {
+		blocks = append(blocks, textContent(partial))
+	}
+	blocks = append(blocks, textContent(fmt.Sprintf("stream interrupted after %d tokens: %s", chunks, reason)))
+	return g.toolErrorContent(id, blocks...)
+}
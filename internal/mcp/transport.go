@@ -1,12 +1,14 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -20,36 +22,121 @@ import (
 //
 // Sessions are tracked via Mcp-Session-Id header.
 // The transport is stateless per request — each POST is independent.
+// Clients that leave without DELETE are reaped once idle past the session
+// TTL (see IdleReaper), so abandoned sessions never hold the session cap.
+
+// DefaultSessionTTL is how long a session may go unused, with no open SSE
+// stream, before IdleReaper closes it.
+const DefaultSessionTTL = 30 * time.Minute
 
 // Transport provides the HTTP handlers for the MCP protocol.
 type Transport struct {
 	gateway  *Gateway
 	mu       sync.RWMutex
 	sessions map[string]*session
+
+	keys           KeyStore // nil = authentication disabled
+	allowAnonymous bool     // admit requests without a key (see SetAllowAnonymous)
+	maxSessions    int      // 0 = unlimited (see SetMaxSessions)
+	sessionTTL     time.Duration
 }
 
 // session tracks a connected MCP client session.
 type session struct {
-	ID        string
+	ID         string
 	ClientName string
 	// SSE channel for server-initiated notifications
 	notify chan []byte
 	done   chan struct{}
+
+	lastSeen time.Time // last request on the session; guarded by Transport.mu
+	streams  int       // open SSE streams; guarded by Transport.mu
 }
 
 // NewTransport creates a new Streamable HTTP transport.
 func NewTransport(gateway *Gateway) *Transport {
 	return &Transport{
-		gateway:  gateway,
-		sessions: make(map[string]*session),
+		gateway:    gateway,
+		sessions:   make(map[string]*session),
+		sessionTTL: DefaultSessionTTL,
+	}
+}
+
+// SetMaxSessions caps the number of open sessions. Once the cap is reached,
+// initialize is refused with HTTP 503 until a session is deleted. n <= 0
+// removes the cap.
+func (t *Transport) SetMaxSessions(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.maxSessions = max(n, 0)
+}
+
+// SetSessionTTL sets how long an idle session survives before IdleReaper
+// closes it. d <= 0 restores DefaultSessionTTL. Call before serving
+// requests.
+func (t *Transport) SetSessionTTL(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if d <= 0 {
+		d = DefaultSessionTTL
+	}
+	t.sessionTTL = d
+}
+
+// IdleReaper closes sessions idle past the session TTL until ctx is done.
+// Call in a goroutine.
+func (t *Transport) IdleReaper(ctx context.Context) {
+	t.mu.RLock()
+	interval := max(t.sessionTTL/4, time.Second)
+	t.mu.RUnlock()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.reapIdle(time.Now())
+		}
+	}
+}
+
+// reapIdle closes every session without an open SSE stream that has seen
+// no request for longer than the session TTL at now.
+func (t *Transport) reapIdle(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, sess := range t.sessions {
+		if sess.streams == 0 && now.Sub(sess.lastSeen) > t.sessionTTL {
+			close(sess.done)
+			delete(t.sessions, id)
+			log.Printf("[mcp/transport] session expired: %s", id)
+		}
+	}
+}
+
+// touch marks sessionID as in use at now, if it is open.
+func (t *Transport) touch(sessionID string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if sess, ok := t.sessions[sessionID]; ok {
+		sess.lastSeen = now
 	}
 }
 
 // ServeHTTP implements http.Handler — the single MCP endpoint.
 func (t *Transport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := t.authenticate(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="tutu-mcp"`)
+		http.Error(w, "Invalid or missing API key", http.StatusUnauthorized)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodPost:
-		t.handlePost(w, r)
+		t.handlePost(w, r, clientID)
 	case http.MethodGet:
 		t.handleSSE(w, r)
 	case http.MethodDelete:
@@ -59,54 +146,63 @@ func (t *Transport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handlePost processes a JSON-RPC 2.0 request.
-func (t *Transport) handlePost(w http.ResponseWriter, r *http.Request) {
-	// Read request body
-	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20)) // 1 MB limit
+// handlePost processes a JSON-RPC 2.0 request on behalf of clientID.
+func (t *Transport) handlePost(w http.ResponseWriter, r *http.Request, clientID string) {
+	// Read request body, up to the client's session limit
+	limit := t.gateway.SessionLimits(clientID).MaxBodyBytes
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
 	if err != nil {
 		http.Error(w, "Failed to read request body", http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close()
+	if int64(len(body)) > limit {
+		http.Error(w, fmt.Sprintf("Request body exceeds %d bytes", limit), http.StatusRequestEntityTooLarge)
+		return
+	}
 
 	if len(body) == 0 {
 		http.Error(w, "Empty request body", http.StatusBadRequest)
 		return
 	}
 
-	// Dispatch to gateway
-	resp := t.gateway.HandleRequest(body)
+	sessionID := r.Header.Get("Mcp-Session-Id")
+	if sessionID == "" {
+		sessionID = uuid.New().String()
+	}
+
+	// A full transport turns initialize away before the gateway does any
+	// work; the session itself opens only once initialize succeeds.
+	initID, initialize := parseInitialize(body)
+	if initialize && !t.hasRoom(sessionID) {
+		t.refuseSession(w, initID)
+		return
+	}
+	t.touch(sessionID, time.Now())
+
+	// Dispatch to gateway — progress notifications go to the session's SSE stream
+	var notify NotifyFunc
+	if id := r.Header.Get("Mcp-Session-Id"); id != "" {
+		notify = func(n Notification) {
+			if err := t.Notify(id, n); err != nil {
+				log.Printf("[mcp/transport] drop %s: %v", n.Method, err)
+			}
+		}
+	}
+	resp := t.gateway.HandleClientRequestContext(r.Context(), body, clientID, notify)
+	if initialize && resp != nil && resp.Error == nil && !t.openSession(sessionID) {
+		t.refuseSession(w, initID)
+		return
+	}
 
 	// Notifications return no response — 202 Accepted
 	if resp == nil {
 		// Ensure session header on notifications too
-		sessionID := r.Header.Get("Mcp-Session-Id")
-		if sessionID == "" {
-			sessionID = uuid.New().String()
-		}
 		w.Header().Set("Mcp-Session-Id", sessionID)
 		w.WriteHeader(http.StatusAccepted)
 		return
 	}
 
-	// Check if this is an initialize response — assign session
-	sessionID := r.Header.Get("Mcp-Session-Id")
-	if sessionID == "" {
-		sessionID = uuid.New().String()
-	}
-
-	// Track session on initialize
-	if isInitializeResponse(body) {
-		t.mu.Lock()
-		t.sessions[sessionID] = &session{
-			ID:     sessionID,
-			notify: make(chan []byte, 32),
-			done:   make(chan struct{}),
-		}
-		t.mu.Unlock()
-		log.Printf("[mcp/transport] new session: %s", sessionID)
-	}
-
 	// Write response
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Mcp-Session-Id", sessionID)
@@ -119,6 +215,50 @@ func (t *Transport) handlePost(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
+// hasRoom reports whether a session with sessionID may be opened.
+func (t *Transport) hasRoom(sessionID string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.hasRoomLocked(sessionID)
+}
+
+func (t *Transport) hasRoomLocked(sessionID string) bool {
+	_, exists := t.sessions[sessionID]
+	return exists || t.maxSessions == 0 || len(t.sessions) < t.maxSessions
+}
+
+// refuseSession answers initialize with 503 because the session cap is
+// reached.
+func (t *Transport) refuseSession(w http.ResponseWriter, id any) {
+	log.Printf("[mcp/transport] session limit reached, refusing initialize")
+	data, _ := json.Marshal(NewServerBusy(id, "session limit reached"))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	w.Write(data)
+}
+
+// openSession tracks a session after a successful initialize, replacing
+// any session with the same ID. Returns false if the session cap is
+// reached.
+func (t *Transport) openSession(sessionID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.hasRoomLocked(sessionID) {
+		return false
+	}
+	if old, ok := t.sessions[sessionID]; ok {
+		close(old.done)
+	}
+	t.sessions[sessionID] = &session{
+		ID:       sessionID,
+		notify:   make(chan []byte, 32),
+		done:     make(chan struct{}),
+		lastSeen: time.Now(),
+	}
+	log.Printf("[mcp/transport] new session: %s", sessionID)
+	return true
+}
+
 // handleSSE opens a Server-Sent Events stream for server-initiated notifications.
 func (t *Transport) handleSSE(w http.ResponseWriter, r *http.Request) {
 	sessionID := r.Header.Get("Mcp-Session-Id")
@@ -127,13 +267,22 @@ func (t *Transport) handleSSE(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	t.mu.RLock()
+	t.mu.Lock()
 	sess, ok := t.sessions[sessionID]
-	t.mu.RUnlock()
+	if ok {
+		sess.streams++
+	}
+	t.mu.Unlock()
 	if !ok {
 		http.Error(w, "Unknown session", http.StatusNotFound)
 		return
 	}
+	defer func() {
+		t.mu.Lock()
+		sess.streams--
+		sess.lastSeen = time.Now()
+		t.mu.Unlock()
+	}()
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -214,13 +363,28 @@ func (t *Transport) SessionCount() int {
 	return len(t.sessions)
 }
 
-// isInitializeResponse checks if the request was an initialize call.
-func isInitializeResponse(body []byte) bool {
+// TransportStats reports session occupancy.
+type TransportStats struct {
+	Sessions    int `json:"sessions"`
+	MaxSessions int `json:"max_sessions"` // 0 = unlimited
+}
+
+// Stats returns the current and maximum session counts.
+func (t *Transport) Stats() TransportStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return TransportStats{Sessions: len(t.sessions), MaxSessions: t.maxSessions}
+}
+
+// parseInitialize reports whether body is an initialize request and
+// returns its JSON-RPC id.
+func parseInitialize(body []byte) (id any, ok bool) {
 	var req struct {
+		ID     any    `json:"id"`
 		Method string `json:"method"`
 	}
 	if err := json.Unmarshal(body, &req); err != nil {
-		return false
+		return nil, false
 	}
-	return req.Method == "initialize"
+	return req.ID, req.Method == "initialize"
 }